@@ -2,14 +2,21 @@ package checkregistry
 
 import (
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/alertrulecheck"
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/authchecks"
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/configchecks"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/contactpointcheck"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/dashboardcheck"
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/datasourcecheck"
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/instancechecks"
 	"github.com/grafana/grafana/apps/advisor/pkg/app/checks/plugincheck"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/repo"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/managedplugins"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginchecker"
@@ -36,6 +43,10 @@ type Service struct {
 	managedPlugins        managedplugins.Manager
 	provisionedPlugins    provisionedplugins.Manager
 	ssoSettingsSvc        ssosettings.Service
+	dashboardSvc          dashboards.DashboardService
+	libraryElementSvc     libraryelements.Service
+	alertRuleStore        *ngstore.DBstore
+	alertNG               *ngalert.AlertNG
 	GrafanaVersion        string
 	cfg                   *setting.Cfg
 }
@@ -45,7 +56,8 @@ func ProvideService(datasourceSvc datasources.DataSourceService, pluginStore plu
 	updateChecker pluginchecker.PluginUpdateChecker,
 	pluginRepo repo.Service, pluginPreinstall pluginchecker.Preinstall, managedPlugins managedplugins.Manager,
 	provisionedPlugins provisionedplugins.Manager, ssoSettingsSvc ssosettings.Service, cfg *setting.Cfg,
-	pluginErrorResolver plugins.ErrorResolver,
+	pluginErrorResolver plugins.ErrorResolver, dashboardSvc dashboards.DashboardService,
+	libraryElementSvc libraryelements.Service, alertRuleStore *ngstore.DBstore, alertNG *ngalert.AlertNG,
 ) *Service {
 	return &Service{
 		datasourceSvc:         datasourceSvc,
@@ -59,13 +71,17 @@ func ProvideService(datasourceSvc datasources.DataSourceService, pluginStore plu
 		managedPlugins:        managedPlugins,
 		provisionedPlugins:    provisionedPlugins,
 		ssoSettingsSvc:        ssoSettingsSvc,
+		dashboardSvc:          dashboardSvc,
+		libraryElementSvc:     libraryElementSvc,
+		alertRuleStore:        alertRuleStore,
+		alertNG:               alertNG,
 		GrafanaVersion:        cfg.BuildVersion,
 		cfg:                   cfg,
 	}
 }
 
 func (s *Service) Checks() []checks.Check {
-	return []checks.Check{
+	res := []checks.Check{
 		datasourcecheck.New(
 			s.datasourceSvc,
 			s.pluginStore,
@@ -86,7 +102,16 @@ func (s *Service) Checks() []checks.Check {
 		authchecks.New(s.ssoSettingsSvc),
 		configchecks.New(s.cfg),
 		instancechecks.New(s.cfg),
+		dashboardcheck.New(s.dashboardSvc, s.datasourceSvc, s.libraryElementSvc),
 	}
+	// Alerting checks are only available when unified alerting is enabled
+	if s.alertNG != nil && !s.alertNG.IsDisabled() {
+		res = append(res,
+			alertrulecheck.New(s.alertRuleStore, s.alertNG.InstanceStore),
+			contactpointcheck.New(&contactPointStatusGetter{moa: s.alertNG.MultiOrgAlertmanager}),
+		)
+	}
+	return res
 }
 
 // AdvisorAppConfig is the configuration received from Grafana to run the app
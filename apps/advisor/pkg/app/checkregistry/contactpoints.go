@@ -0,0 +1,46 @@
+package checkregistry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+// contactPointStatusGetter reads the status of the contact points from the Alertmanager of the organization.
+type contactPointStatusGetter struct {
+	moa *notifier.MultiOrgAlertmanager
+}
+
+func (g *contactPointStatusGetter) GetContactPointStatuses(ctx context.Context, orgID int64) ([]checks.ContactPointStatus, error) {
+	am, err := g.moa.AlertmanagerFor(orgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) || errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			// Nothing to check until the Alertmanager of the organization is running
+			return nil, nil
+		}
+		return nil, err
+	}
+	receivers, err := am.GetReceivers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]checks.ContactPointStatus, 0, len(receivers))
+	for _, receiver := range receivers {
+		status := checks.ContactPointStatus{
+			Name:         receiver.Name,
+			Integrations: make([]checks.IntegrationStatus, 0, len(receiver.Integrations)),
+		}
+		for _, integration := range receiver.Integrations {
+			status.Integrations = append(status.Integrations, checks.IntegrationStatus{
+				Name:              integration.Name,
+				LastNotifyAttempt: time.Time(integration.LastNotifyAttempt),
+				LastNotifyError:   integration.LastNotifyAttemptError,
+			})
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
@@ -0,0 +1,130 @@
+package alertrulecheck
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	CheckID          = "alertrule"
+	ErrorStateStepID = "error-state"
+	NoDataStepID     = "no-data"
+
+	// stuckThreshold is how long an alert rule has to stay in the same state before it's reported
+	stuckThreshold = 24 * time.Hour
+)
+
+type check struct {
+	AlertRuleSvc     checks.AlertRuleGetter
+	AlertInstanceSvc checks.AlertInstanceGetter
+	instances        map[string][]*ngmodels.AlertInstance
+	now              func() time.Time
+}
+
+func New(
+	alertRuleSvc checks.AlertRuleGetter,
+	alertInstanceSvc checks.AlertInstanceGetter,
+) checks.Check {
+	return &check{
+		AlertRuleSvc:     alertRuleSvc,
+		AlertInstanceSvc: alertInstanceSvc,
+		instances:        make(map[string][]*ngmodels.AlertInstance),
+		now:              time.Now,
+	}
+}
+
+func (c *check) ID() string {
+	return CheckID
+}
+
+func (c *check) Name() string {
+	return "alert rule"
+}
+
+func (c *check) Items(ctx context.Context) ([]any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := c.AlertRuleSvc.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID: requester.GetOrgID(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]any, len(rules))
+	for i, rule := range rules {
+		res[i] = rule
+	}
+	return res, nil
+}
+
+func (c *check) Item(ctx context.Context, id string) (any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := c.AlertRuleSvc.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{
+		UID:   id,
+		OrgID: requester.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			// The alert rule does not exist, skip the check
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Init loads the state of the instances of every alert rule of the organization at once,
+// so the steps don't query it for each rule.
+func (c *check) Init(ctx context.Context) error {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return err
+	}
+	instances, err := c.AlertInstanceSvc.ListAlertInstances(ctx, &ngmodels.ListAlertInstancesQuery{
+		RuleOrgID: requester.GetOrgID(),
+	})
+	if err != nil {
+		return err
+	}
+	c.instances = make(map[string][]*ngmodels.AlertInstance)
+	for _, instance := range instances {
+		c.instances[instance.RuleUID] = append(c.instances[instance.RuleUID], instance)
+	}
+	return nil
+}
+
+func (c *check) Steps() []checks.Step {
+	return []checks.Step{
+		&stuckStateStep{
+			id:          ErrorStateStepID,
+			title:       "Error state check",
+			description: "Checks if an alert rule has failed to evaluate for more than a day.",
+			resolution:  "Go to the alert rule and fix its queries or the data source they use, the error of the last evaluation is shown in the details.",
+			state:       ngmodels.InstanceStateError,
+			severity:    advisor.CheckReportFailureSeverityHigh,
+			instances:   c.instances,
+			now:         c.now,
+		},
+		&stuckStateStep{
+			id:          NoDataStepID,
+			title:       "No data check",
+			description: "Checks if the queries of an alert rule have returned no data for more than a day.",
+			resolution:  "Go to the alert rule and check that its queries still match existing series, or configure how it should handle no data.",
+			state:       ngmodels.InstanceStateNoData,
+			severity:    advisor.CheckReportFailureSeverityLow,
+			instances:   c.instances,
+			now:         c.now,
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package alertrulecheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// runChecks executes all steps for all items and returns the failures
+func runChecks(check *check) ([]advisor.CheckReportFailure, error) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{})
+	err := check.Init(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := check.Items(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []advisor.CheckReportFailure{}
+	for _, step := range check.Steps() {
+		for _, item := range items {
+			stepFailures, err := step.Run(ctx, logging.DefaultLogger, &advisor.CheckSpec{}, item)
+			if err != nil {
+				return nil, err
+			}
+			failures = append(failures, stepFailures...)
+		}
+	}
+	return failures, nil
+}
+
+func newCheck(rules []*ngmodels.AlertRule, instances []*ngmodels.AlertInstance) *check {
+	c := New(&MockAlertRuleSvc{rules: rules}, &MockAlertInstanceSvc{instances: instances}).(*check)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func instance(ruleUID string, state ngmodels.InstanceStateType, since time.Duration, lastError string) *ngmodels.AlertInstance {
+	return &ngmodels.AlertInstance{
+		AlertInstanceKey:  ngmodels.AlertInstanceKey{RuleUID: ruleUID},
+		CurrentState:      state,
+		CurrentStateSince: now.Add(-since),
+		LastError:         lastError,
+	}
+}
+
+func TestCheck_Run(t *testing.T) {
+	t.Run("should return no failures when rules are healthy", func(t *testing.T) {
+		c := newCheck(
+			[]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1"}},
+			[]*ngmodels.AlertInstance{instance("rule-1", ngmodels.InstanceStateNormal, 72*time.Hour, "")},
+		)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("should ignore rules that errored recently", func(t *testing.T) {
+		c := newCheck(
+			[]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1"}},
+			[]*ngmodels.AlertInstance{instance("rule-1", ngmodels.InstanceStateError, time.Hour, "timeout")},
+		)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("should report rules that have errored for days", func(t *testing.T) {
+		c := newCheck(
+			[]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1"}, {UID: "rule-2", Title: "Rule 2"}},
+			[]*ngmodels.AlertInstance{
+				instance("rule-1", ngmodels.InstanceStateError, 48*time.Hour, "data source not found"),
+				instance("rule-2", ngmodels.InstanceStateNormal, 48*time.Hour, ""),
+			},
+		)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, ErrorStateStepID, failures[0].StepID)
+		assert.Equal(t, "rule-1", failures[0].ItemID)
+		assert.Equal(t, advisor.CheckReportFailureSeverityHigh, failures[0].Severity)
+		assert.Equal(t, "/alerting/grafana/rule-1/view", failures[0].Links[0].Url)
+		require.NotNil(t, failures[0].MoreInfo)
+		assert.Contains(t, *failures[0].MoreInfo, "data source not found")
+	})
+
+	t.Run("should report rules without data for days", func(t *testing.T) {
+		c := newCheck(
+			[]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1"}},
+			[]*ngmodels.AlertInstance{instance("rule-1", ngmodels.InstanceStateNoData, 30*time.Hour, "")},
+		)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, NoDataStepID, failures[0].StepID)
+		assert.Equal(t, advisor.CheckReportFailureSeverityLow, failures[0].Severity)
+	})
+
+	t.Run("should ignore paused rules", func(t *testing.T) {
+		c := newCheck(
+			[]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1", IsPaused: true}},
+			[]*ngmodels.AlertInstance{instance("rule-1", ngmodels.InstanceStateError, 48*time.Hour, "timeout")},
+		)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+}
+
+func TestCheck_Item(t *testing.T) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{})
+	c := newCheck([]*ngmodels.AlertRule{{UID: "rule-1", Title: "Rule 1"}}, nil)
+
+	item, err := c.Item(ctx, "rule-1")
+	require.NoError(t, err)
+	assert.Equal(t, "rule-1", item.(*ngmodels.AlertRule).UID)
+
+	item, err = c.Item(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+}
+
+type MockAlertRuleSvc struct {
+	rules []*ngmodels.AlertRule
+}
+
+func (m *MockAlertRuleSvc) ListAlertRules(context.Context, *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error) {
+	return m.rules, nil
+}
+
+func (m *MockAlertRuleSvc) GetAlertRuleByUID(_ context.Context, query *ngmodels.GetAlertRuleByUIDQuery) (*ngmodels.AlertRule, error) {
+	for _, rule := range m.rules {
+		if rule.UID == query.UID {
+			return rule, nil
+		}
+	}
+	return nil, ngmodels.ErrAlertRuleNotFound
+}
+
+type MockAlertInstanceSvc struct {
+	instances []*ngmodels.AlertInstance
+}
+
+func (m *MockAlertInstanceSvc) ListAlertInstances(context.Context, *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	return m.instances, nil
+}
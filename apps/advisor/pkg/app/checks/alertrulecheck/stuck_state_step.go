@@ -0,0 +1,85 @@
+package alertrulecheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// stuckStateStep reports alert rules with instances that have been in the given state for longer than
+// stuckThreshold.
+type stuckStateStep struct {
+	id          string
+	title       string
+	description string
+	resolution  string
+	state       ngmodels.InstanceStateType
+	severity    advisor.CheckReportFailureSeverity
+	instances   map[string][]*ngmodels.AlertInstance
+	now         func() time.Time
+}
+
+func (s *stuckStateStep) ID() string {
+	return s.id
+}
+
+func (s *stuckStateStep) Title() string {
+	return s.title
+}
+
+func (s *stuckStateStep) Description() string {
+	return s.description
+}
+
+func (s *stuckStateStep) Resolution() string {
+	return s.resolution
+}
+
+func (s *stuckStateStep) Run(ctx context.Context, log logging.Logger, obj *advisor.CheckSpec, i any) ([]advisor.CheckReportFailure, error) {
+	rule, ok := i.(*ngmodels.AlertRule)
+	if !ok {
+		return nil, fmt.Errorf("invalid item type %T", i)
+	}
+	if rule.IsPaused {
+		return nil, nil
+	}
+
+	var since time.Time
+	lastError := ""
+	for _, instance := range s.instances[rule.UID] {
+		if instance.CurrentState != s.state {
+			continue
+		}
+		if since.IsZero() || instance.CurrentStateSince.Before(since) {
+			since = instance.CurrentStateSince
+			lastError = instance.LastError
+		}
+	}
+	if since.IsZero() || s.now().Sub(since) < stuckThreshold {
+		return nil, nil
+	}
+
+	moreInfo := []string{fmt.Sprintf("State: %s since %s", s.state, since.UTC().Format(time.RFC3339))}
+	if lastError != "" {
+		moreInfo = append(moreInfo, fmt.Sprintf("Last error: %s", lastError))
+	}
+	return []advisor.CheckReportFailure{checks.NewCheckReportFailureWithMoreInfo(
+		s.severity,
+		s.ID(),
+		rule.Title,
+		rule.UID,
+		[]advisor.CheckErrorLink{
+			{
+				Message: "View alert rule",
+				Url:     fmt.Sprintf("/alerting/grafana/%s/view", rule.UID),
+			},
+		},
+		strings.Join(moreInfo, "\n"),
+	)}, nil
+}
@@ -0,0 +1,75 @@
+package contactpointcheck
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+const (
+	CheckID                  = "contactpoint"
+	FailedNotificationStepID = "failed-notification"
+)
+
+type check struct {
+	ContactPointSvc checks.ContactPointStatusGetter
+}
+
+func New(contactPointSvc checks.ContactPointStatusGetter) checks.Check {
+	return &check{
+		ContactPointSvc: contactPointSvc,
+	}
+}
+
+func (c *check) ID() string {
+	return CheckID
+}
+
+func (c *check) Name() string {
+	return "contact point"
+}
+
+func (c *check) Items(ctx context.Context) ([]any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contactPoints, err := c.ContactPointSvc.GetContactPointStatuses(ctx, requester.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]any, len(contactPoints))
+	for i := range contactPoints {
+		res[i] = &contactPoints[i]
+	}
+	return res, nil
+}
+
+func (c *check) Item(ctx context.Context, id string) (any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contactPoints, err := c.ContactPointSvc.GetContactPointStatuses(ctx, requester.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	for i := range contactPoints {
+		if contactPoints[i].Name == id {
+			return &contactPoints[i], nil
+		}
+	}
+	// The contact point does not exist, skip the check
+	return nil, nil
+}
+
+func (c *check) Init(ctx context.Context) error {
+	return nil
+}
+
+func (c *check) Steps() []checks.Step {
+	return []checks.Step{
+		&failedNotificationStep{},
+	}
+}
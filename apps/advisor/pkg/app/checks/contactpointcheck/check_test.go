@@ -0,0 +1,78 @@
+package contactpointcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_Run(t *testing.T) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{})
+	lastAttempt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	c := New(&MockContactPointSvc{contactPoints: []checks.ContactPointStatus{
+		{
+			Name: "healthy",
+			Integrations: []checks.IntegrationStatus{
+				{Name: "email", LastNotifyAttempt: lastAttempt},
+			},
+		},
+		{
+			Name: "broken",
+			Integrations: []checks.IntegrationStatus{
+				{Name: "email", LastNotifyAttempt: lastAttempt},
+				{Name: "slack", LastNotifyAttempt: lastAttempt, LastNotifyError: "invalid_auth"},
+			},
+		},
+	}})
+
+	require.NoError(t, c.Init(ctx))
+	items, err := c.Items(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	failures := []advisor.CheckReportFailure{}
+	for _, step := range c.Steps() {
+		for _, item := range items {
+			stepFailures, err := step.Run(ctx, logging.DefaultLogger, &advisor.CheckSpec{}, item)
+			require.NoError(t, err)
+			failures = append(failures, stepFailures...)
+		}
+	}
+
+	require.Len(t, failures, 1)
+	assert.Equal(t, FailedNotificationStepID, failures[0].StepID)
+	assert.Equal(t, "broken", failures[0].ItemID)
+	assert.Equal(t, "/alerting/notifications/receivers/YnJva2Vu/edit", failures[0].Links[0].Url)
+	require.NotNil(t, failures[0].MoreInfo)
+	assert.Equal(t, "slack (last attempt at 2025-06-01T12:00:00Z): invalid_auth", *failures[0].MoreInfo)
+}
+
+func TestCheck_Item(t *testing.T) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{})
+	c := New(&MockContactPointSvc{contactPoints: []checks.ContactPointStatus{{Name: "email"}}})
+
+	item, err := c.Item(ctx, "email")
+	require.NoError(t, err)
+	assert.Equal(t, "email", item.(*checks.ContactPointStatus).Name)
+
+	item, err = c.Item(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, item)
+}
+
+type MockContactPointSvc struct {
+	contactPoints []checks.ContactPointStatus
+}
+
+func (m *MockContactPointSvc) GetContactPointStatuses(context.Context, int64) ([]checks.ContactPointStatus, error) {
+	return m.contactPoints, nil
+}
@@ -0,0 +1,65 @@
+package contactpointcheck
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type failedNotificationStep struct{}
+
+func (s *failedNotificationStep) ID() string {
+	return FailedNotificationStepID
+}
+
+func (s *failedNotificationStep) Title() string {
+	return "Failed notification check"
+}
+
+func (s *failedNotificationStep) Description() string {
+	return "Checks if the last notification sent by a contact point failed."
+}
+
+func (s *failedNotificationStep) Resolution() string {
+	return "Go to the contact point, fix the settings of the failing integrations and use the test button to verify them."
+}
+
+func (s *failedNotificationStep) Run(ctx context.Context, log logging.Logger, obj *advisor.CheckSpec, i any) ([]advisor.CheckReportFailure, error) {
+	contactPoint, ok := i.(*checks.ContactPointStatus)
+	if !ok {
+		return nil, fmt.Errorf("invalid item type %T", i)
+	}
+
+	errs := make([]string, 0)
+	for _, integration := range contactPoint.Integrations {
+		if integration.LastNotifyError == "" {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("%s (last attempt at %s): %s",
+			integration.Name, integration.LastNotifyAttempt.UTC().Format(time.RFC3339), integration.LastNotifyError))
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+
+	return []advisor.CheckReportFailure{checks.NewCheckReportFailureWithMoreInfo(
+		advisor.CheckReportFailureSeverityHigh,
+		s.ID(),
+		contactPoint.Name,
+		contactPoint.Name,
+		[]advisor.CheckErrorLink{
+			{
+				Message: "Fix me",
+				Url:     fmt.Sprintf("/alerting/notifications/receivers/%s/edit", url.PathEscape(ngmodels.NameToUid(contactPoint.Name))),
+			},
+		},
+		strings.Join(errs, "\n"),
+	)}, nil
+}
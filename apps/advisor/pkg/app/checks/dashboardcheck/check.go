@@ -0,0 +1,116 @@
+package dashboardcheck
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	CheckID                   = "dashboard"
+	MissingDatasourceStepID   = "missing-datasource"
+	MissingLibraryPanelStepID = "missing-library-panel"
+	ExpensiveQueryStepID      = "expensive-query"
+)
+
+type check struct {
+	DashboardSvc      checks.DashboardGetter
+	DatasourceSvc     checks.DataSourceGetter
+	LibraryElementSvc checks.LibraryElementGetter
+	datasources       map[string]bool
+}
+
+func New(
+	dashboardSvc checks.DashboardGetter,
+	datasourceSvc checks.DataSourceGetter,
+	libraryElementSvc checks.LibraryElementGetter,
+) checks.Check {
+	return &check{
+		DashboardSvc:      dashboardSvc,
+		DatasourceSvc:     datasourceSvc,
+		LibraryElementSvc: libraryElementSvc,
+		datasources:       make(map[string]bool),
+	}
+}
+
+func (c *check) ID() string {
+	return CheckID
+}
+
+func (c *check) Name() string {
+	return "dashboard"
+}
+
+func (c *check) Items(ctx context.Context) ([]any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dashs, err := c.DashboardSvc.GetAllDashboardsByOrgId(ctx, requester.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]any, 0, len(dashs))
+	for _, dash := range dashs {
+		if dash.IsFolder {
+			continue
+		}
+		res = append(res, dash)
+	}
+	return res, nil
+}
+
+func (c *check) Item(ctx context.Context, id string) (any, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dash, err := c.DashboardSvc.GetDashboard(ctx, &dashboards.GetDashboardQuery{
+		UID:   id,
+		OrgID: requester.GetOrgID(),
+	})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			// The dashboard does not exist, skip the check
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dash, nil
+}
+
+// Init indexes the data sources of the organization by UID and name, dashboards reference them by either.
+func (c *check) Init(ctx context.Context) error {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return err
+	}
+	dss, err := c.DatasourceSvc.GetDataSources(ctx, &datasources.GetDataSourcesQuery{
+		OrgID: requester.GetOrgID(),
+	})
+	if err != nil {
+		return err
+	}
+	c.datasources = make(map[string]bool, 2*len(dss))
+	for _, ds := range dss {
+		c.datasources[ds.UID] = true
+		c.datasources[ds.Name] = true
+	}
+	return nil
+}
+
+func (c *check) Steps() []checks.Step {
+	return []checks.Step{
+		&missingDatasourceStep{
+			datasources: c.datasources,
+		},
+		&missingLibraryPanelStep{
+			LibraryElementSvc: c.LibraryElementSvc,
+		},
+		&expensiveQueryStep{},
+	}
+}
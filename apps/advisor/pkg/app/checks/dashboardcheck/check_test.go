@@ -0,0 +1,215 @@
+package dashboardcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runChecks executes all steps for all items and returns the failures
+func runChecks(check *check) ([]advisor.CheckReportFailure, error) {
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{})
+	err := check.Init(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := check.Items(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []advisor.CheckReportFailure{}
+	for _, step := range check.Steps() {
+		for _, item := range items {
+			stepFailures, err := step.Run(ctx, logging.DefaultLogger, &advisor.CheckSpec{}, item)
+			if err != nil {
+				return nil, err
+			}
+			failures = append(failures, stepFailures...)
+		}
+	}
+	return failures, nil
+}
+
+func newCheck(dashs ...*dashboards.Dashboard) *check {
+	return New(
+		&MockDashboardSvc{dashboards: dashs},
+		&MockDatasourceSvc{dss: []*datasources.DataSource{
+			{UID: "prom-uid", Name: "Prometheus", Type: "prometheus"},
+			{UID: "mysql-uid", Name: "MySQL", Type: "mysql"},
+		}},
+		&MockLibraryElementSvc{uids: map[string]bool{"lib-1": true}},
+	).(*check)
+}
+
+func dashboard(t *testing.T, uid, data string) *dashboards.Dashboard {
+	t.Helper()
+	json, err := simplejson.NewJson([]byte(data))
+	require.NoError(t, err)
+	return &dashboards.Dashboard{UID: uid, Title: uid, Data: json}
+}
+
+func TestCheck_Run(t *testing.T) {
+	t.Run("should return no failures for a healthy dashboard", func(t *testing.T) {
+		c := newCheck(dashboard(t, "healthy", `{
+			"panels": [
+				{"id": 1, "title": "CPU", "datasource": {"uid": "prom-uid", "type": "prometheus"},
+				 "targets": [{"refId": "A", "expr": "rate(cpu[5m])"}]},
+				{"id": 2, "title": "Legacy", "datasource": "Prometheus"},
+				{"id": 3, "title": "Variable", "datasource": "${ds}"},
+				{"id": 4, "title": "Mixed", "datasource": {"uid": "-- Mixed --"},
+				 "targets": [{"refId": "A", "datasource": {"uid": "mysql-uid"}, "rawSql": "SELECT * FROM t WHERE $__timeFilter(ts)"},
+				             {"refId": "B", "datasource": {"uid": "__expr__", "type": "__expr__"}}]},
+				{"id": 5, "title": "Library", "libraryPanel": {"uid": "lib-1", "name": "Shared"}}
+			],
+			"templating": {"list": [{"name": "ds", "type": "datasource", "query": "prometheus"}]},
+			"annotations": {"list": [{"name": "Annotations & Alerts", "datasource": {"type": "grafana", "uid": "-- Grafana --"}}]}
+		}`))
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("should report deleted data sources", func(t *testing.T) {
+		c := newCheck(dashboard(t, "broken", `{
+			"panels": [
+				{"type": "row", "panels": [
+					{"id": 1, "title": "CPU", "datasource": {"uid": "deleted-uid", "type": "prometheus"},
+					 "targets": [{"refId": "A", "datasource": {"uid": "deleted-uid", "type": "prometheus"}}]}
+				]}
+			],
+			"templating": {"list": [{"name": "host", "type": "query", "datasource": "Old Graphite"}]}
+		}`))
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, MissingDatasourceStepID, failures[0].StepID)
+		assert.Equal(t, "broken", failures[0].ItemID)
+		assert.Equal(t, "/d/broken", failures[0].Links[0].Url)
+		require.NotNil(t, failures[0].MoreInfo)
+		assert.Equal(t, "Old Graphite: used by variable \"host\"\ndeleted-uid: used by panel \"CPU\"", *failures[0].MoreInfo)
+	})
+
+	t.Run("should report deleted library panels", func(t *testing.T) {
+		c := newCheck(dashboard(t, "library", `{
+			"panels": [
+				{"id": 1, "title": "Kept", "libraryPanel": {"uid": "lib-1", "name": "Shared"}},
+				{"id": 2, "title": "Gone", "libraryPanel": {"uid": "lib-2", "name": "Deleted"}}
+			]
+		}`))
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, MissingLibraryPanelStepID, failures[0].StepID)
+		require.NotNil(t, failures[0].MoreInfo)
+		assert.Equal(t, "panel \"Gone\": library panel \"Deleted\"", *failures[0].MoreInfo)
+	})
+
+	t.Run("should report expensive queries", func(t *testing.T) {
+		c := newCheck(dashboard(t, "expensive", `{
+			"panels": [
+				{"id": 1, "title": "Yearly", "datasource": {"uid": "prom-uid"},
+				 "targets": [{"refId": "A", "expr": "max_over_time(up[30d])"}, {"refId": "B", "expr": "rate(up[$__range])"}]},
+				{"id": 2, "title": "Table", "datasource": {"uid": "mysql-uid"},
+				 "targets": [{"refId": "A", "rawSql": "SELECT * FROM events"}]}
+			]
+		}`))
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		require.Len(t, failures, 1)
+		assert.Equal(t, ExpensiveQueryStepID, failures[0].StepID)
+		assert.Equal(t, advisor.CheckReportFailureSeverityLow, failures[0].Severity)
+		require.NotNil(t, failures[0].MoreInfo)
+		assert.Equal(t, "panel \"Yearly\" query A: range window of 30 days\n"+
+			"panel \"Table\" query A: SQL query not limited to the time range", *failures[0].MoreInfo)
+	})
+
+	t.Run("should skip folders", func(t *testing.T) {
+		folder := dashboard(t, "folder", `{"panels": [{"id": 1, "datasource": {"uid": "deleted-uid"}}]}`)
+		folder.IsFolder = true
+		c := newCheck(folder)
+
+		failures, err := runChecks(c)
+		require.NoError(t, err)
+		assert.Empty(t, failures)
+	})
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected time.Duration
+		ok       bool
+	}{
+		{in: "5m", expected: 5 * time.Minute, ok: true},
+		{in: "1h30m", expected: 90 * time.Minute, ok: true},
+		{in: "2w", expected: 14 * 24 * time.Hour, ok: true},
+		{in: "500ms", expected: 500 * time.Millisecond, ok: true},
+		{in: "5x", ok: false},
+		{in: "m", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, ok := parseDuration(tt.in)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, d)
+			}
+		})
+	}
+}
+
+type MockDashboardSvc struct {
+	dashboards []*dashboards.Dashboard
+}
+
+func (m *MockDashboardSvc) GetAllDashboardsByOrgId(context.Context, int64) ([]*dashboards.Dashboard, error) {
+	return m.dashboards, nil
+}
+
+func (m *MockDashboardSvc) GetDashboard(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	for _, dash := range m.dashboards {
+		if dash.UID == query.UID {
+			return dash, nil
+		}
+	}
+	return nil, dashboards.ErrDashboardNotFound
+}
+
+type MockDatasourceSvc struct {
+	dss []*datasources.DataSource
+}
+
+func (m *MockDatasourceSvc) GetDataSources(context.Context, *datasources.GetDataSourcesQuery) ([]*datasources.DataSource, error) {
+	return m.dss, nil
+}
+
+func (m *MockDatasourceSvc) GetDataSource(context.Context, *datasources.GetDataSourceQuery) (*datasources.DataSource, error) {
+	return nil, datasources.ErrDataSourceNotFound
+}
+
+type MockLibraryElementSvc struct {
+	uids map[string]bool
+}
+
+func (m *MockLibraryElementSvc) GetElement(_ context.Context, _ identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error) {
+	if !m.uids[cmd.UID] {
+		return model.LibraryElementDTO{}, model.ErrLibraryElementNotFound
+	}
+	return model.LibraryElementDTO{UID: cmd.UID}, nil
+}
@@ -0,0 +1,140 @@
+package dashboardcheck
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+const (
+	// maxQueriesPerPanel is the number of queries above which a panel is reported, every query is a request
+	// to the data source each time the dashboard refreshes
+	maxQueriesPerPanel = 20
+	// maxRangeWindow is the longest range selector a PromQL query can use before it is reported
+	maxRangeWindow = 7 * 24 * time.Hour
+)
+
+var (
+	rangeSelectorRegex = regexp.MustCompile(`\[([0-9a-z]+)(?::[0-9a-z]*)?\]`)
+	durationPartRegex  = regexp.MustCompile(`([0-9]+)(ms|s|m|h|d|w|y)`)
+	// sqlTimeMacroRegex matches the macros limiting a SQL query to the time range of the dashboard
+	sqlTimeMacroRegex = regexp.MustCompile(`\$__(timeFilter|timeFrom|timeTo|unixEpochFilter|unixEpochFrom|unixEpochTo|unixEpochNanoFilter|unixEpochNanoFrom|unixEpochNanoTo)\b`)
+)
+
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+type expensiveQueryStep struct{}
+
+func (s *expensiveQueryStep) ID() string {
+	return ExpensiveQueryStepID
+}
+
+func (s *expensiveQueryStep) Title() string {
+	return "Expensive query check"
+}
+
+func (s *expensiveQueryStep) Description() string {
+	return "Checks if a dashboard has panels with queries that are likely to be very expensive for their data sources."
+}
+
+func (s *expensiveQueryStep) Resolution() string {
+	return "Reduce the number of queries of the panels listed in the details, shorten their range windows, " +
+		"and limit SQL queries to the time range of the dashboard with the $__timeFilter macro."
+}
+
+func (s *expensiveQueryStep) Run(ctx context.Context, log logging.Logger, obj *advisor.CheckSpec, i any) ([]advisor.CheckReportFailure, error) {
+	dash, ok := i.(*dashboards.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("invalid item type %T", i)
+	}
+
+	issues := make([]string, 0)
+	for _, panel := range panels(dash.Data) {
+		where := panelName(panel)
+		targets := panel.Get("targets").MustArray()
+		if len(targets) > maxQueriesPerPanel {
+			issues = append(issues, fmt.Sprintf("%s: %d queries", where, len(targets)))
+		}
+		for _, obj := range targets {
+			target := simplejson.NewFromAny(obj)
+			refID := target.Get("refId").MustString()
+			if window, ok := longestRangeWindow(target.Get("expr").MustString()); ok && window > maxRangeWindow {
+				issues = append(issues, fmt.Sprintf("%s query %s: range window of %s", where, refID, formatDays(window)))
+			}
+			if sql := target.Get("rawSql").MustString(); strings.TrimSpace(sql) != "" && !sqlTimeMacroRegex.MatchString(sql) {
+				issues = append(issues, fmt.Sprintf("%s query %s: SQL query not limited to the time range", where, refID))
+			}
+		}
+	}
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	return []advisor.CheckReportFailure{checks.NewCheckReportFailureWithMoreInfo(
+		advisor.CheckReportFailureSeverityLow,
+		s.ID(),
+		dash.Title,
+		dash.UID,
+		[]advisor.CheckErrorLink{
+			{
+				Message: "Fix me",
+				Url:     fmt.Sprintf("/d/%s", dash.UID),
+			},
+		},
+		strings.Join(issues, "\n"),
+	)}, nil
+}
+
+// longestRangeWindow returns the longest range selector or subquery range of a PromQL expression.
+// Ranges using variables such as $__range are ignored.
+func longestRangeWindow(expr string) (time.Duration, bool) {
+	var longest time.Duration
+	found := false
+	for _, match := range rangeSelectorRegex.FindAllStringSubmatch(expr, -1) {
+		d, ok := parseDuration(match[1])
+		if !ok {
+			continue
+		}
+		found = true
+		longest = max(longest, d)
+	}
+	return longest, found
+}
+
+// parseDuration parses a Prometheus duration such as 1h30m.
+func parseDuration(s string) (time.Duration, bool) {
+	parts := durationPartRegex.FindAllStringSubmatch(s, -1)
+	length := 0
+	var d time.Duration
+	for _, part := range parts {
+		n, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		d += time.Duration(n) * durationUnits[part[2]]
+		length += len(part[0])
+	}
+	return d, len(parts) > 0 && length == len(s)
+}
+
+func formatDays(d time.Duration) string {
+	days := d.Hours() / 24
+	return strconv.FormatFloat(days, 'f', -1, 64) + " days"
+}
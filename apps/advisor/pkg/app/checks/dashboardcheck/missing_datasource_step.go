@@ -0,0 +1,137 @@
+package dashboardcheck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+// builtinDatasources are the references to data sources that are not stored in the organization:
+// the mixed, dashboard, Grafana and expression data sources.
+var builtinDatasources = map[string]bool{
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+	"-- Grafana --":   true,
+	"grafana":         true,
+	"__expr__":        true,
+	"__ml__":          true,
+	"default":         true,
+}
+
+type missingDatasourceStep struct {
+	datasources map[string]bool
+}
+
+func (s *missingDatasourceStep) ID() string {
+	return MissingDatasourceStepID
+}
+
+func (s *missingDatasourceStep) Title() string {
+	return "Missing data source check"
+}
+
+func (s *missingDatasourceStep) Description() string {
+	return "Checks if a dashboard references data sources that do not exist."
+}
+
+func (s *missingDatasourceStep) Resolution() string {
+	return "Edit the panels and variables listed in the details to use an existing data source, or restore the deleted data source."
+}
+
+func (s *missingDatasourceStep) Run(ctx context.Context, log logging.Logger, obj *advisor.CheckSpec, i any) ([]advisor.CheckReportFailure, error) {
+	dash, ok := i.(*dashboards.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("invalid item type %T", i)
+	}
+
+	missing := make(map[string][]string)
+	addRef := func(where string, ref any) {
+		if name := datasourceRef(ref); name != "" && !s.datasources[name] {
+			missing[name] = append(missing[name], where)
+		}
+	}
+
+	for _, panel := range panels(dash.Data) {
+		where := panelName(panel)
+		addRef(where, panel.Get("datasource").Interface())
+		for _, target := range panel.Get("targets").MustArray() {
+			addRef(where, simplejson.NewFromAny(target).Get("datasource").Interface())
+		}
+	}
+	if dash.Data != nil {
+		for _, obj := range dash.Data.GetPath("templating", "list").MustArray() {
+			variable := simplejson.NewFromAny(obj)
+			addRef(fmt.Sprintf("variable %q", variable.Get("name").MustString()), variable.Get("datasource").Interface())
+		}
+		for _, obj := range dash.Data.GetPath("annotations", "list").MustArray() {
+			annotation := simplejson.NewFromAny(obj)
+			addRef(fmt.Sprintf("annotation %q", annotation.Get("name").MustString()), annotation.Get("datasource").Interface())
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	moreInfo := make([]string, 0, len(names))
+	for _, name := range names {
+		moreInfo = append(moreInfo, fmt.Sprintf("%s: used by %s", name, strings.Join(dedup(missing[name]), ", ")))
+	}
+
+	return []advisor.CheckReportFailure{checks.NewCheckReportFailureWithMoreInfo(
+		advisor.CheckReportFailureSeverityHigh,
+		s.ID(),
+		dash.Title,
+		dash.UID,
+		[]advisor.CheckErrorLink{
+			{
+				Message: "Fix me",
+				Url:     fmt.Sprintf("/d/%s", dash.UID),
+			},
+		},
+		strings.Join(moreInfo, "\n"),
+	)}, nil
+}
+
+// datasourceRef returns the UID or name of the data source referenced by a panel, query or variable, or an
+// empty string when it references the default data source, a built-in one or a template variable.
+// Old dashboards reference data sources by name, newer ones by an object with the UID and type.
+func datasourceRef(ref any) string {
+	var name string
+	switch ds := ref.(type) {
+	case string:
+		name = ds
+	case map[string]any:
+		name, _ = ds["uid"].(string)
+		if t, _ := ds["type"].(string); t == "datasource" || t == "__expr__" {
+			return ""
+		}
+	}
+	if name == "" || builtinDatasources[name] || strings.Contains(name, "$") {
+		return ""
+	}
+	return name
+}
+
+func dedup(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			res = append(res, v)
+		}
+	}
+	return res
+}
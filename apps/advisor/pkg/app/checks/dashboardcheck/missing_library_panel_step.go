@@ -0,0 +1,82 @@
+package dashboardcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	advisor "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/apps/advisor/pkg/app/checks"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+)
+
+type missingLibraryPanelStep struct {
+	LibraryElementSvc checks.LibraryElementGetter
+}
+
+func (s *missingLibraryPanelStep) ID() string {
+	return MissingLibraryPanelStepID
+}
+
+func (s *missingLibraryPanelStep) Title() string {
+	return "Missing library panel check"
+}
+
+func (s *missingLibraryPanelStep) Description() string {
+	return "Checks if a dashboard uses library panels that have been deleted."
+}
+
+func (s *missingLibraryPanelStep) Resolution() string {
+	return "Remove the panels listed in the details from the dashboard or replace them with an existing library panel."
+}
+
+func (s *missingLibraryPanelStep) Run(ctx context.Context, log logging.Logger, obj *advisor.CheckSpec, i any) ([]advisor.CheckReportFailure, error) {
+	dash, ok := i.(*dashboards.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("invalid item type %T", i)
+	}
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := make([]string, 0)
+	for _, panel := range panels(dash.Data) {
+		uid := panel.GetPath("libraryPanel", "uid").MustString()
+		if uid == "" {
+			continue
+		}
+		_, err := s.LibraryElementSvc.GetElement(ctx, requester, model.GetLibraryElementCommand{UID: uid})
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, model.ErrLibraryElementNotFound) {
+			// Unable to check the library panel, skip it
+			log.Error("Failed to get library panel", "dashboard_uid", dash.UID, "library_panel_uid", uid, "error", err)
+			continue
+		}
+		name := panel.GetPath("libraryPanel", "name").MustString(uid)
+		missing = append(missing, fmt.Sprintf("%s: library panel %q", panelName(panel), name))
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	return []advisor.CheckReportFailure{checks.NewCheckReportFailureWithMoreInfo(
+		advisor.CheckReportFailureSeverityHigh,
+		s.ID(),
+		dash.Title,
+		dash.UID,
+		[]advisor.CheckErrorLink{
+			{
+				Message: "Fix me",
+				Url:     fmt.Sprintf("/d/%s", dash.UID),
+			},
+		},
+		strings.Join(missing, "\n"),
+	)}, nil
+}
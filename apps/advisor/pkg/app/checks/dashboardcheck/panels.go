@@ -0,0 +1,37 @@
+package dashboardcheck
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// panels returns the panels of a dashboard, including the panels of collapsed rows.
+// Dashboards using the v2 schema have no panels array and return none.
+func panels(dashboard *simplejson.Json) []*simplejson.Json {
+	if dashboard == nil {
+		return nil
+	}
+	result := make([]*simplejson.Json, 0)
+	var walk func(list []any)
+	walk = func(list []any) {
+		for _, obj := range list {
+			panel := simplejson.NewFromAny(obj)
+			if panel.Get("type").MustString() == "row" {
+				walk(panel.Get("panels").MustArray())
+				continue
+			}
+			result = append(result, panel)
+		}
+	}
+	walk(dashboard.Get("panels").MustArray())
+	return result
+}
+
+// panelName returns a name identifying the panel in the details of a report.
+func panelName(panel *simplejson.Json) string {
+	if title := panel.Get("title").MustString(); title != "" {
+		return fmt.Sprintf("panel %q", title)
+	}
+	return fmt.Sprintf("panel %d", panel.Get("id").MustInt64())
+}
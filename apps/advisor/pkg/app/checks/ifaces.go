@@ -2,12 +2,17 @@ package checks
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-app-sdk/logging"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	advisorv0alpha1 "github.com/grafana/grafana/apps/advisor/pkg/apis/advisor/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/plugins/repo"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 // Check returns metadata about the check being executed and the list of Steps
@@ -62,3 +67,53 @@ type DataSourceGetter interface {
 type HealthChecker interface {
 	CheckHealth(ctx context.Context, ds *datasources.DataSource) (*backend.CheckHealthResult, error)
 }
+
+// AlertRuleGetter is a minimal interface for retrieving alert rules.
+// It contains only the ListAlertRules and GetAlertRuleByUID methods used by alertrulecheck.
+type AlertRuleGetter interface {
+	// ListAlertRules gets the alert rules matching the query.
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+	// GetAlertRuleByUID gets an alert rule.
+	GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAlertRuleByUIDQuery) (*ngmodels.AlertRule, error)
+}
+
+// AlertInstanceGetter is a minimal interface for retrieving the state of the instances of alert rules.
+type AlertInstanceGetter interface {
+	// ListAlertInstances gets the alert instances matching the query.
+	ListAlertInstances(ctx context.Context, query *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error)
+}
+
+// ContactPointStatusGetter is a minimal interface for retrieving the notification status of the contact points
+// of an organization.
+type ContactPointStatusGetter interface {
+	// GetContactPointStatuses gets the status of the contact points of the organization.
+	GetContactPointStatuses(ctx context.Context, orgID int64) ([]ContactPointStatus, error)
+}
+
+// ContactPointStatus is the status of a contact point and its integrations.
+type ContactPointStatus struct {
+	Name         string
+	Integrations []IntegrationStatus
+}
+
+// IntegrationStatus is the result of the last notification attempt of an integration of a contact point.
+type IntegrationStatus struct {
+	Name              string
+	LastNotifyAttempt time.Time
+	LastNotifyError   string
+}
+
+// DashboardGetter is a minimal interface for retrieving dashboards.
+// It contains only the GetAllDashboardsByOrgId and GetDashboard methods used by dashboardcheck.
+type DashboardGetter interface {
+	// GetAllDashboardsByOrgId gets the dashboards of the organization.
+	GetAllDashboardsByOrgId(ctx context.Context, orgID int64) ([]*dashboards.Dashboard, error)
+	// GetDashboard gets a dashboard.
+	GetDashboard(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error)
+}
+
+// LibraryElementGetter is a minimal interface for retrieving library elements.
+type LibraryElementGetter interface {
+	// GetElement gets a library element.
+	GetElement(ctx context.Context, signedInUser identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error)
+}
@@ -96,6 +96,8 @@ type DashboardHit struct {
 	Score float64 `json:"score,omitempty"`
 	// Explain the score (if possible)
 	Explain *common.Unstructured `json:"explain,omitzero,omitempty"`
+	// The matching text fragments for each field (when highlighting is requested)
+	Highlight map[string][]string `json:"highlight,omitempty"`
 }
 
 func (DashboardHit) OpenAPIModelName() string {
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/grafana/authlib/types"
@@ -304,7 +306,7 @@ func handleCheckRoute(
 				dsLogger.Error("Unsupported datasource type", "type", ds.Type)
 				w.WriteHeader(http.StatusBadRequest)
 				return json.NewEncoder(w).Encode(map[string]string{
-					"error": fmt.Sprintf("datasource type '%s' is not supported (currently only 'prometheus' is supported)", ds.Type),
+					"error": fmt.Sprintf("datasource type '%s' is not supported (supported types: %s)", ds.Type, supportedTypes(validators)),
 					"code":  "datasource_unsupported_type",
 				})
 			}
@@ -327,12 +329,19 @@ func handleCheckRoute(
 				Timeout:   30 * time.Second,
 			}
 
+			// Datasource settings, e.g. the index pattern of Elasticsearch datasources
+			var jsonData map[string]interface{}
+			if ds.JsonData != nil {
+				jsonData = ds.JsonData.MustMap()
+			}
+
 			validatorReq.Datasources = append(validatorReq.Datasources, validator.Datasource{
 				UID:        dsMapping.UID,
 				Type:       dsMapping.Type,
 				Name:       name,
 				URL:        ds.URL,
 				HTTPClient: httpClient, // Pass authenticated client
+				JSONData:   jsonData,
 			})
 
 			dsLogger.Debug("Datasource configured successfully for validation")
@@ -430,6 +439,16 @@ func getOrgIDFromNamespace(namespace string) (int64, error) {
 	return info.OrgID, nil
 }
 
+// supportedTypes returns the sorted, comma separated datasource types that have a validator.
+func supportedTypes(validators map[string]validator.DatasourceValidator) string {
+	types := make([]string, 0, len(validators))
+	for dsType := range validators {
+		types = append(types, dsType)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ", ")
+}
+
 func GetKinds() map[schema.GroupVersion][]resource.Kind {
 	return map[schema.GroupVersion][]resource.Kind{}
 }
@@ -0,0 +1,110 @@
+package validator
+
+import "sort"
+
+// ParsedQuery holds the entities extracted from a single query, or the error
+// that prevented extracting them.
+type ParsedQuery struct {
+	Metrics []string
+	Err     error
+}
+
+// BuildValidationResult scores parsed queries against the entities available in a
+// datasource. parsed must have one entry per query, in the same order.
+// Scoring matches the Prometheus validator: queries that failed to parse score 0,
+// queries without entities score 1, and the overall score is found/total entities.
+func BuildValidationResult(queries []Query, parsed []ParsedQuery, available map[string]bool) *ValidationResult {
+	unique := make(map[string]bool)
+	checkedCount := 0
+	for _, p := range parsed {
+		if p.Err != nil {
+			continue
+		}
+		checkedCount++
+		for _, metric := range p.Metrics {
+			unique[metric] = true
+		}
+	}
+
+	missingSet := make(map[string]bool)
+	for metric := range unique {
+		if !available[metric] {
+			missingSet[metric] = true
+		}
+	}
+
+	breakdown := make([]QueryResult, 0, len(queries))
+	for i, query := range queries {
+		queryResult := QueryResult{
+			PanelTitle: query.PanelTitle,
+			PanelID:    query.PanelID,
+			QueryRefID: query.RefID,
+		}
+
+		if parsed[i].Err != nil {
+			errMsg := parsed[i].Err.Error()
+			queryResult.ParseError = &errMsg
+			queryResult.MissingMetrics = []string{}
+			breakdown = append(breakdown, queryResult)
+			continue
+		}
+
+		queryMissing := make([]string, 0)
+		for _, metric := range parsed[i].Metrics {
+			if missingSet[metric] {
+				queryMissing = append(queryMissing, metric)
+			}
+		}
+
+		queryResult.TotalMetrics = len(parsed[i].Metrics)
+		queryResult.FoundMetrics = queryResult.TotalMetrics - len(queryMissing)
+		queryResult.MissingMetrics = queryMissing
+		queryResult.CompatibilityScore = 1.0
+		if queryResult.TotalMetrics > 0 {
+			queryResult.CompatibilityScore = float64(queryResult.FoundMetrics) / float64(queryResult.TotalMetrics)
+		}
+		breakdown = append(breakdown, queryResult)
+	}
+
+	missingMetrics := make([]string, 0, len(missingSet))
+	for metric := range missingSet {
+		missingMetrics = append(missingMetrics, metric)
+	}
+	sort.Strings(missingMetrics)
+
+	foundCount := len(unique) - len(missingSet)
+
+	return &ValidationResult{
+		TotalQueries:   len(queries),
+		CheckedQueries: checkedCount,
+		QueryBreakdown: breakdown,
+		CompatibilityResult: CompatibilityResult{
+			TotalMetrics:       len(unique),
+			FoundMetrics:       foundCount,
+			MissingMetrics:     missingMetrics,
+			CompatibilityScore: overallScore(len(queries), checkedCount, len(unique), foundCount),
+		},
+	}
+}
+
+// overallScore returns a compatibility score between 0.0 and 1.0.
+// When no entities were extracted, it distinguishes "nothing to validate" (1.0)
+// from "every query failed to parse" (0.0).
+func overallScore(totalQueries, checkedQueries, totalMetrics, foundMetrics int) float64 {
+	if totalMetrics > 0 {
+		return float64(foundMetrics) / float64(totalMetrics)
+	}
+	if totalQueries == 0 || checkedQueries > 0 {
+		return 1.0
+	}
+	return 0.0
+}
+
+// ToSet converts a list of entity names into a set for O(1) lookup.
+func ToSet(metrics []string) map[string]bool {
+	set := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		set[metric] = true
+	}
+	return set
+}
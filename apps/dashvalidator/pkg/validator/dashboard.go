@@ -49,7 +49,7 @@ func ValidateDashboardCompatibility(ctx context.Context, req DashboardCompatibil
 	}
 
 	// Step 2: Group queries by datasource UID (with variable resolution for MVP)
-	queriesByDatasource := groupQueriesByDatasource(queries, singleDatasource.UID, req.DashboardJSON)
+	queriesByDatasource := groupQueriesByDatasource(queries, singleDatasource.UID, singleDatasource.Type, req.DashboardJSON)
 
 	// Step 3: Validate each datasource
 	var totalCompatibility float64
@@ -218,6 +218,7 @@ func extractQueriesFromPanel(panel map[string]interface{}) []DashboardQuery {
 			QueryText:     queryText,
 			PanelTitle:    panelTitle,
 			PanelID:       panelID,
+			Target:        target,
 		}
 
 		queries = append(queries, query)
@@ -378,9 +379,9 @@ func extractVariableName(varRef string) string {
 	return ""
 }
 
-// isPrometheusVariable checks if a variable reference points to a Prometheus datasource
+// isDatasourceVariable checks if a variable reference points to a datasource of the given plugin type
 // Looks in dashboard.__inputs for the datasource type
-func isPrometheusVariable(varRef string, dashboardJSON map[string]interface{}) bool {
+func isDatasourceVariable(varRef string, pluginID string, dashboardJSON map[string]interface{}) bool {
 	if !isVariableReference(varRef) {
 		return false
 	}
@@ -393,7 +394,7 @@ func isPrometheusVariable(varRef string, dashboardJSON map[string]interface{}) b
 	// Look for __inputs array in dashboard
 	inputs, hasInputs := dashboardJSON["__inputs"].([]interface{})
 	if !hasInputs {
-		// No __inputs, assume it might be Prometheus (MVP: single datasource)
+		// No __inputs, assume it points to the validated datasource (MVP: single datasource)
 		// This is a fallback for dashboards without explicit __inputs
 		return true
 	}
@@ -415,32 +416,32 @@ func isPrometheusVariable(varRef string, dashboardJSON map[string]interface{}) b
 			if inputName == varName ||
 				strings.EqualFold(inputName, varName) ||
 				strings.Contains(strings.ToLower(varName), strings.ToLower(inputName)) {
-				// Check if it's a datasource input with prometheus plugin
-				if inputType == "datasource" && inputPluginID == "prometheus" {
+				// Check if it's a datasource input of the expected plugin
+				if inputType == "datasource" && inputPluginID == pluginID {
 					return true
 				}
 			}
 		}
 	}
 
-	// Not found or not Prometheus
+	// Not found or another plugin
 	return false
 }
 
 // resolveDatasourceUID resolves a datasource UID, handling variable references (MVP: single datasource)
-// For MVP, all Prometheus variables resolve to the single datasource UID
-func resolveDatasourceUID(uid string, singleDatasourceUID string, dashboardJSON map[string]interface{}) string {
+// For MVP, all variables of the datasource type resolve to the single datasource UID
+func resolveDatasourceUID(uid string, singleDatasourceUID string, singleDatasourceType string, dashboardJSON map[string]interface{}) string {
 	// If not a variable, return as-is (concrete UID)
 	if !isVariableReference(uid) {
 		return uid
 	}
 
-	// Check if it's a Prometheus variable
-	if isPrometheusVariable(uid, dashboardJSON) {
+	// Check if it's a variable of the validated datasource type
+	if isDatasourceVariable(uid, singleDatasourceType, dashboardJSON) {
 		return singleDatasourceUID
 	}
 
-	// Non-Prometheus variable, return as-is (will be ignored in grouping)
+	// Variable of another datasource type, return as-is (will be ignored in grouping)
 	return uid
 }
 
@@ -486,16 +487,17 @@ func getIntValue(m map[string]interface{}, key string, defaultValue int) int {
 
 // DashboardQuery represents a query extracted from a dashboard panel
 type DashboardQuery struct {
-	DatasourceUID string // Which datasource this query belongs to
-	RefID         string // Query reference ID
-	QueryText     string // The actual query
-	PanelTitle    string // Panel title
-	PanelID       int    // Panel ID
+	DatasourceUID string                 // Which datasource this query belongs to
+	RefID         string                 // Query reference ID
+	QueryText     string                 // The actual query
+	PanelTitle    string                 // Panel title
+	PanelID       int                    // Panel ID
+	Target        map[string]interface{} // Raw target the query was extracted from
 }
 
 // groupQueriesByDatasource groups dashboard queries by their datasource UID
-// For MVP: resolves Prometheus template variables to the single datasource UID
-func groupQueriesByDatasource(queries []DashboardQuery, singleDatasourceUID string, dashboardJSON map[string]interface{}) map[string][]Query {
+// For MVP: resolves template variables of the datasource type to the single datasource UID
+func groupQueriesByDatasource(queries []DashboardQuery, singleDatasourceUID string, singleDatasourceType string, dashboardJSON map[string]interface{}) map[string][]Query {
 	grouped := make(map[string][]Query)
 
 	for _, dq := range queries {
@@ -504,10 +506,11 @@ func groupQueriesByDatasource(queries []DashboardQuery, singleDatasourceUID stri
 			QueryText:  dq.QueryText,
 			PanelTitle: dq.PanelTitle,
 			PanelID:    dq.PanelID,
+			Target:     dq.Target,
 		}
 
 		// Resolve datasource UID (handles both concrete UIDs and variables)
-		resolvedUID := resolveDatasourceUID(dq.DatasourceUID, singleDatasourceUID, dashboardJSON)
+		resolvedUID := resolveDatasourceUID(dq.DatasourceUID, singleDatasourceUID, singleDatasourceType, dashboardJSON)
 
 		// Only add to grouping if we got a valid resolved UID
 		if resolvedUID != "" {
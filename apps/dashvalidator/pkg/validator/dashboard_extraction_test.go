@@ -210,6 +210,11 @@ func TestExtractQueriesFromPanel(t *testing.T) {
 					QueryText:     "rate(cpu[5m])",
 					PanelTitle:    "CPU Usage",
 					PanelID:       42,
+					Target: map[string]interface{}{
+						"refId":      "A",
+						"expr":       "rate(cpu[5m])",
+						"datasource": "prom-main",
+					},
 				},
 			},
 		},
@@ -238,6 +243,11 @@ func TestExtractQueriesFromPanel(t *testing.T) {
 					QueryText:     "up",
 					PanelTitle:    "Metrics",
 					PanelID:       10,
+					Target: map[string]interface{}{
+						"refId":      "A",
+						"expr":       "up",
+						"datasource": "prom-1",
+					},
 				},
 				{
 					DatasourceUID: "prom-1",
@@ -245,6 +255,11 @@ func TestExtractQueriesFromPanel(t *testing.T) {
 					QueryText:     "down",
 					PanelTitle:    "Metrics",
 					PanelID:       10,
+					Target: map[string]interface{}{
+						"refId":      "B",
+						"expr":       "down",
+						"datasource": "prom-1",
+					},
 				},
 			},
 		},
@@ -315,6 +330,11 @@ func TestExtractQueriesFromPanel(t *testing.T) {
 					QueryText:     "test_metric",
 					PanelTitle:    "Custom Title",
 					PanelID:       999,
+					Target: map[string]interface{}{
+						"refId":      "Z",
+						"expr":       "test_metric",
+						"datasource": "ds-abc",
+					},
 				},
 			},
 		},
@@ -338,6 +358,11 @@ func TestExtractQueriesFromPanel(t *testing.T) {
 					QueryText:     "metric",
 					PanelTitle:    "Float ID Panel",
 					PanelID:       123,
+					Target: map[string]interface{}{
+						"refId":      "A",
+						"expr":       "metric",
+						"datasource": "ds-1",
+					},
 				},
 			},
 		},
@@ -0,0 +1,89 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+)
+
+// Fetcher fetches the mapped fields of an Elasticsearch index pattern
+type Fetcher struct{}
+
+// NewFetcher creates a new Elasticsearch mapping fetcher
+func NewFetcher() *Fetcher {
+	return &Fetcher{}
+}
+
+// indexMappings represents the mappings of one index in a _mapping response
+type indexMappings struct {
+	Mappings map[string]interface{} `json:"mappings"`
+}
+
+// FetchMetrics queries Elasticsearch to get the fields of all indices matching
+// an index pattern. indexURL is the datasource URL followed by the index
+// pattern, e.g. http://es:9200/logs-*.
+// Nested objects and multi-fields are returned with dotted names (user.id, message.keyword).
+// The provided HTTP client should have proper authentication configured.
+func (f *Fetcher) FetchMetrics(ctx context.Context, indexURL string, client *http.Client) ([]string, error) {
+	endpoint, err := validator.JoinURL(indexURL, "_mapping")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp map[string]indexMappings
+	if err := validator.FetchJSON(ctx, client, endpoint, &resp); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool)
+	for _, index := range resp {
+		if properties, ok := index.Mappings["properties"].(map[string]interface{}); ok {
+			flattenProperties("", properties, fields)
+			continue
+		}
+		// Elasticsearch < 7 nests the properties under the mapping type
+		for _, typeMapping := range index.Mappings {
+			if m, ok := typeMapping.(map[string]interface{}); ok {
+				if properties, ok := m["properties"].(map[string]interface{}); ok {
+					flattenProperties("", properties, fields)
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// flattenProperties adds the dotted names of all the fields of a mapping to fields
+func flattenProperties(prefix string, properties map[string]interface{}, fields map[string]bool) {
+	for name, p := range properties {
+		property, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		fullName := prefix + name
+		// object fields only hold sub-fields and cannot be queried directly
+		if nested, ok := property["properties"].(map[string]interface{}); ok {
+			flattenProperties(fullName+".", nested, fields)
+			if property["type"] != "nested" {
+				continue
+			}
+		}
+		fields[fullName] = true
+
+		if multiFields, ok := property["fields"].(map[string]interface{}); ok {
+			for subName := range multiFields {
+				fields[fullName+"."+subName] = true
+			}
+		}
+	}
+}
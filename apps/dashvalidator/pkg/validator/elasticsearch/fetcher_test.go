@@ -0,0 +1,63 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchMetrics_Success_FlattensMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/logs-*/_mapping", r.URL.Path)
+
+		_, _ = w.Write([]byte(`{
+			"logs-2024.05.01": {"mappings": {"properties": {
+				"@timestamp": {"type": "date"},
+				"message": {"type": "text", "fields": {"keyword": {"type": "keyword"}}},
+				"user": {"properties": {"id": {"type": "keyword"}, "name": {"type": "text"}}},
+				"tags": {"type": "nested", "properties": {"key": {"type": "keyword"}}}
+			}}},
+			"logs-2024.05.02": {"mappings": {"properties": {
+				"latency": {"type": "long"}
+			}}}
+		}`))
+	}))
+	defer server.Close()
+
+	fields, err := NewFetcher().FetchMetrics(context.Background(), server.URL+"/logs-*", server.Client())
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"@timestamp", "latency", "message", "message.keyword", "tags", "tags.key", "user.id", "user.name"}, fields)
+}
+
+func TestFetchMetrics_LegacyTypedMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"logs": {"mappings": {"doc": {"properties": {"status": {"type": "integer"}}}}}}`))
+	}))
+	defer server.Close()
+
+	fields, err := NewFetcher().FetchMetrics(context.Background(), server.URL+"/logs", server.Client())
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"status"}, fields)
+}
+
+func TestFetchMetrics_IndexNotFound_ReturnsAPIUnavailableError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"type":"index_not_found_exception"}}`))
+	}))
+	defer server.Close()
+
+	_, err := NewFetcher().FetchMetrics(context.Background(), server.URL+"/missing", server.Client())
+
+	require.Error(t, err)
+	validationErr := validator.GetValidationError(err)
+	require.NotNil(t, validationErr)
+	require.Equal(t, validator.ErrCodeAPIUnavailable, validationErr.Code)
+}
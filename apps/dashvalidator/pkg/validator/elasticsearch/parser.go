@@ -0,0 +1,185 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Parser extracts field names from Lucene queries used by Elasticsearch panels
+type Parser struct{}
+
+// NewParser creates a new Lucene query parser
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// ExtractMetrics parses a Lucene query and extracts the fields it filters on.
+// For example: `status:500 AND _exists_:user.id` returns ["status", "user.id"]
+// Metadata fields (_id, _index, ...), wildcard field names and template
+// variables are skipped since they cannot be checked against the mapping.
+func (p *Parser) ExtractMetrics(query string) ([]string, error) {
+	fields := make(map[string]bool)
+
+	i := 0
+	for i < len(query) {
+		ch := query[i]
+		switch {
+		case ch == '"':
+			end, err := skipQuoted(query, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case ch == '\\':
+			i += 2
+		case isTermChar(ch):
+			start := i
+			for i < len(query) && (isTermChar(query[i]) || query[i] == '\\') {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(query) || query[i] != ':' {
+				continue
+			}
+			field := strings.TrimLeft(query[start:i], "+-!")
+			i++ // skip ':'
+
+			if field == "_exists_" {
+				valueStart := i
+				for i < len(query) && isTermChar(query[i]) {
+					i++
+				}
+				field = query[valueStart:i]
+			} else {
+				end, err := skipValue(query, i)
+				if err != nil {
+					return nil, err
+				}
+				i = end
+			}
+			if isCheckableField(field) {
+				fields[field] = true
+			}
+		default:
+			i++
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// skipQuoted returns the position after the closing quote of the phrase starting at start
+func skipQuoted(query string, start int) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("failed to parse Lucene query: unterminated phrase starting at position %d", start)
+}
+
+// skipValue returns the position after the value of a field starting at start.
+// Grouped values (field:(a OR b)) are not skipped since they hold no fields.
+func skipValue(query string, start int) (int, error) {
+	if start >= len(query) {
+		return start, nil
+	}
+
+	switch query[start] {
+	case '"':
+		return skipQuoted(query, start)
+	case '[', '{':
+		if end := strings.IndexAny(query[start:], "]}"); end != -1 {
+			return start + end + 1, nil
+		}
+		return 0, fmt.Errorf("failed to parse Lucene query: unterminated range starting at position %d", start)
+	case '/':
+		for i := start + 1; i < len(query); i++ {
+			switch query[i] {
+			case '\\':
+				i++
+			case '/':
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("failed to parse Lucene query: unterminated regular expression starting at position %d", start)
+	case '(':
+		return start, nil
+	}
+
+	i := start
+	for i < len(query) && (isTermChar(query[i]) || query[i] == ':' || query[i] == '\\') {
+		if query[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	return i, nil
+}
+
+// isTermChar reports whether ch can be part of an unquoted Lucene term.
+// ':' is excluded so field names can be split from their values.
+func isTermChar(ch byte) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', ':', '"', '(', ')', '[', ']', '{', '}', '^', '~', '\\':
+		return false
+	}
+	return true
+}
+
+// isCheckableField reports whether a field can be looked up in the index mapping
+func isCheckableField(field string) bool {
+	if field == "" || strings.HasPrefix(field, "_") {
+		return false
+	}
+	return !strings.ContainsAny(field, "*?$")
+}
+
+// fieldsFromTarget returns the fields referenced by the metric and bucket
+// aggregations of an Elasticsearch query model.
+func fieldsFromTarget(target map[string]interface{}) []string {
+	fields := make(map[string]bool)
+
+	addField := func(v interface{}) {
+		field, ok := v.(string)
+		if ok && isCheckableField(field) && field != "select field" {
+			fields[field] = true
+		}
+	}
+
+	addField(target["timeField"])
+	for _, key := range []string{"metrics", "bucketAggs"} {
+		aggs, _ := target[key].([]interface{})
+		for _, a := range aggs {
+			agg, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			// count and raw document metrics have no field
+			if agg["type"] == "count" || agg["type"] == "raw_data" || agg["type"] == "raw_document" || agg["type"] == "logs" {
+				continue
+			}
+			addField(agg["field"])
+		}
+	}
+
+	result := make([]string, 0, len(fields))
+	for field := range fields {
+		result = append(result, field)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,128 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMetrics(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		name          string
+		query         string
+		expected      []string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:     "match all",
+			query:    "*",
+			expected: []string{},
+		},
+		{
+			name:     "free text without fields",
+			query:    "error AND timeout",
+			expected: []string{},
+		},
+		{
+			name:     "single field",
+			query:    "status:500",
+			expected: []string{"status"},
+		},
+		{
+			name:     "boolean operators and prefixes",
+			query:    `status:500 AND -level:debug OR +host.name:"web 1"`,
+			expected: []string{"host.name", "level", "status"},
+		},
+		{
+			name:     "exists query",
+			query:    "_exists_:user.id",
+			expected: []string{"user.id"},
+		},
+		{
+			name:     "ranges and values with colons",
+			query:    `@timestamp:[now-1h TO now] AND time:12:30:00 AND latency:>=100`,
+			expected: []string{"@timestamp", "latency", "time"},
+		},
+		{
+			name:     "regular expression value",
+			query:    `path:/api\/v[0-9]+/ AND method:GET`,
+			expected: []string{"method", "path"},
+		},
+		{
+			name:     "grouped values",
+			query:    `level:(error OR warn) AND service:$service`,
+			expected: []string{"level", "service"},
+		},
+		{
+			name:     "metadata, wildcard and variable fields are skipped",
+			query:    `_id:abc AND labels.*:x AND $field:value`,
+			expected: []string{},
+		},
+		{
+			name:     "colon inside phrase is not a field",
+			query:    `message:"error: connection refused"`,
+			expected: []string{"message"},
+		},
+		{
+			name:          "unterminated phrase",
+			query:         `message:"error`,
+			expectError:   true,
+			errorContains: "unterminated phrase",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := parser.ExtractMetrics(tt.query)
+			if tt.expectError {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, fields)
+		})
+	}
+}
+
+func TestFieldsFromTarget(t *testing.T) {
+	target := map[string]interface{}{
+		"timeField": "@timestamp",
+		"metrics": []interface{}{
+			map[string]interface{}{"type": "count", "id": "1"},
+			map[string]interface{}{"type": "avg", "field": "latency"},
+			map[string]interface{}{"type": "max", "field": "select field"},
+		},
+		"bucketAggs": []interface{}{
+			map[string]interface{}{"type": "terms", "field": "host.keyword"},
+			map[string]interface{}{"type": "date_histogram", "field": "@timestamp"},
+			map[string]interface{}{"type": "terms", "field": "$group_by"},
+		},
+	}
+
+	require.Equal(t, []string{"@timestamp", "host.keyword", "latency"}, fieldsFromTarget(target))
+	require.Empty(t, fieldsFromTarget(nil))
+}
+
+func TestIndexPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonData map[string]interface{}
+		expected string
+	}{
+		{"plain index", map[string]interface{}{"index": "logs-*"}, "logs-*"},
+		{"no interval", map[string]interface{}{"index": "[logs-]YYYY.MM.DD", "interval": "none"}, "[logs-]YYYY.MM.DD"},
+		{"daily pattern", map[string]interface{}{"index": "[logs-]YYYY.MM.DD", "interval": "Daily"}, "logs-*"},
+		{"literal suffix", map[string]interface{}{"index": "YYYY.MM-[app]", "interval": "Monthly"}, "*app"},
+		{"missing index", map[string]interface{}{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, indexPattern(tt.jsonData))
+		})
+	}
+}
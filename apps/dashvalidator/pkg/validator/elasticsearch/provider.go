@@ -0,0 +1,40 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+)
+
+// ElasticsearchProvider implements cache.MetricsProvider for Elasticsearch datasources.
+// It wraps the Fetcher and returns results with a configurable TTL.
+type ElasticsearchProvider struct {
+	fetcher validator.MetricsFetcher
+	ttl     time.Duration
+}
+
+// NewElasticsearchProvider creates a new ElasticsearchProvider with the given TTL.
+func NewElasticsearchProvider(ttl time.Duration) *ElasticsearchProvider {
+	return &ElasticsearchProvider{
+		fetcher: NewFetcher(),
+		ttl:     ttl,
+	}
+}
+
+// GetMetrics implements cache.MetricsProvider.
+// datasourceURL must include the index pattern of the datasource (see Validator).
+func (p *ElasticsearchProvider) GetMetrics(ctx context.Context, datasourceUID, datasourceURL string,
+	client *http.Client) (*cache.MetricsResult, error) {
+	fields, err := p.fetcher.FetchMetrics(ctx, datasourceURL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache.MetricsResult{
+		Metrics: fields,
+		TTL:     p.ttl,
+	}, nil
+}
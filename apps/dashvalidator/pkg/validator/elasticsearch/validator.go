@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// Validator implements validator.DatasourceValidator for Elasticsearch datasources.
+// The entities checked are the fields used by the Lucene query and by the
+// metric and bucket aggregations of each panel query.
+type Validator struct {
+	parser validator.MetricExtractor
+	cache  *cache.MetricsCache
+}
+
+// evaluate at compile time that Validator implements DatasourceValidator interface
+var _ validator.DatasourceValidator = (*Validator)(nil)
+
+// NewValidator creates a new Elasticsearch validator.
+// The metricsCache parameter is required - pass nil will cause a panic.
+func NewValidator(mc *cache.MetricsCache) *Validator {
+	if mc == nil {
+		panic("metricsCache cannot be nil")
+	}
+	return &Validator{
+		parser: NewParser(),
+		cache:  mc,
+	}
+}
+
+// ValidateQueries validates Elasticsearch queries against the mapping of the datasource index.
+func (v *Validator) ValidateQueries(ctx context.Context, queries []validator.Query, datasource validator.Datasource) (*validator.ValidationResult, error) {
+	parsed := make([]validator.ParsedQuery, len(queries))
+	for i, query := range queries {
+		fields, err := v.parser.ExtractMetrics(query.QueryText)
+		if err == nil {
+			fields = mergeFields(fields, fieldsFromTarget(query.Target))
+		}
+		parsed[i] = validator.ParsedQuery{Metrics: fields, Err: err}
+	}
+
+	index := indexPattern(datasource.JSONData)
+	if index == "" {
+		return nil, validator.NewValidationError(
+			validator.ErrCodeDatasourceConfig,
+			"Elasticsearch datasource has no index configured",
+			http.StatusBadRequest,
+		).WithDetail("datasourceUID", datasource.UID)
+	}
+
+	indexURL, err := validator.JoinURL(datasource.URL, index)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := v.cache.GetMetrics(ctx, datasources.DS_ES, datasource.UID, indexURL, datasource.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch mapping from Elasticsearch: %w", err)
+	}
+
+	return validator.BuildValidationResult(queries, parsed, validator.ToSet(available)), nil
+}
+
+// indexPattern returns the index pattern of the datasource as an Elasticsearch
+// multi-target expression. Time-based patterns such as [logs-]YYYY.MM.DD are
+// converted to wildcards (logs-*) so every matching index is covered.
+func indexPattern(jsonData map[string]interface{}) string {
+	index, _ := jsonData["index"].(string)
+	interval, _ := jsonData["interval"].(string)
+	if index == "" || interval == "" || interval == "none" {
+		return index
+	}
+
+	var b strings.Builder
+	inLiteral := false
+	for _, ch := range index {
+		switch {
+		case ch == '[':
+			inLiteral = true
+		case ch == ']':
+			inLiteral = false
+		case inLiteral:
+			b.WriteRune(ch)
+		case !strings.HasSuffix(b.String(), "*"):
+			b.WriteRune('*')
+		}
+	}
+	return b.String()
+}
+
+// mergeFields returns the union of two field lists, keeping their order
+func mergeFields(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, fields := range [][]string{a, b} {
+		for _, field := range fields {
+			if !seen[field] {
+				seen[field] = true
+				merged = append(merged, field)
+			}
+		}
+	}
+	return merged
+}
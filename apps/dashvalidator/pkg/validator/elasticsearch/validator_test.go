@@ -0,0 +1,76 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidator() *Validator {
+	metricsCache := cache.NewMetricsCache()
+	metricsCache.RegisterProvider("elasticsearch", NewElasticsearchProvider(cache.DefaultMetricsCacheTTL))
+	return NewValidator(metricsCache)
+}
+
+func TestValidateQueries_ReportsMissingFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/logs-*/_mapping", r.URL.Path)
+		_, _ = w.Write([]byte(`{"logs-1": {"mappings": {"properties": {
+			"@timestamp": {"type": "date"},
+			"status": {"type": "integer"},
+			"host": {"type": "text", "fields": {"keyword": {"type": "keyword"}}}
+		}}}}`))
+	}))
+	defer server.Close()
+
+	ds := validator.Datasource{
+		UID:        "es-uid",
+		Type:       "elasticsearch",
+		URL:        server.URL,
+		HTTPClient: server.Client(),
+		JSONData:   map[string]interface{}{"index": "[logs-]YYYY.MM.DD", "interval": "Daily"},
+	}
+	queries := []validator.Query{
+		{
+			RefID:     "A",
+			QueryText: "status:500",
+			Target: map[string]interface{}{
+				"timeField":  "@timestamp",
+				"bucketAggs": []interface{}{map[string]interface{}{"type": "terms", "field": "host.keyword"}},
+			},
+		},
+		{
+			RefID:     "B",
+			QueryText: "region:eu",
+			Target: map[string]interface{}{
+				"metrics": []interface{}{map[string]interface{}{"type": "avg", "field": "latency"}},
+			},
+		},
+	}
+
+	result, err := newTestValidator().ValidateQueries(context.Background(), queries, ds)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, result.CheckedQueries)
+	require.Equal(t, 5, result.TotalMetrics)
+	require.Equal(t, 3, result.FoundMetrics)
+	require.Equal(t, []string{"latency", "region"}, result.MissingMetrics)
+	require.Equal(t, 1.0, result.QueryBreakdown[0].CompatibilityScore)
+	require.Equal(t, 0.0, result.QueryBreakdown[1].CompatibilityScore)
+}
+
+func TestValidateQueries_NoIndex_ReturnsConfigError(t *testing.T) {
+	ds := validator.Datasource{UID: "es-uid", Type: "elasticsearch", URL: "http://localhost:9200"}
+
+	_, err := newTestValidator().ValidateQueries(context.Background(), []validator.Query{{RefID: "A", QueryText: "*"}}, ds)
+
+	require.Error(t, err)
+	validationErr := validator.GetValidationError(err)
+	require.NotNil(t, validationErr)
+	require.Equal(t, validator.ErrCodeDatasourceConfig, validationErr.Code)
+}
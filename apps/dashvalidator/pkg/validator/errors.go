@@ -113,7 +113,7 @@ func NewDatasourceUnreachableError(uid string, url string, cause error) *Validat
 func NewAPIUnavailableError(statusCode int, responseBody string, cause error) *ValidationError {
 	return NewValidationError(
 		ErrCodeAPIUnavailable,
-		fmt.Sprintf("Prometheus API returned status %d", statusCode),
+		fmt.Sprintf("datasource API returned status %d", statusCode),
 		http.StatusBadGateway,
 	).WithDetail("upstreamStatus", statusCode).
 		WithDetail("responseBody", responseBody).
@@ -124,7 +124,7 @@ func NewAPIUnavailableError(statusCode int, responseBody string, cause error) *V
 func NewAPIInvalidResponseError(message string, cause error) *ValidationError {
 	return NewValidationError(
 		ErrCodeAPIInvalidResponse,
-		fmt.Sprintf("Prometheus API returned invalid response: %s", message),
+		fmt.Sprintf("datasource API returned invalid response: %s", message),
 		http.StatusBadGateway,
 	).WithCause(cause)
 }
@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// JoinURL appends an API path to a datasource URL, keeping any path the
+// datasource URL already has (e.g. a proxy prefix).
+func JoinURL(datasourceURL string, elem ...string) (string, error) {
+	baseURL, err := url.Parse(datasourceURL)
+	if err != nil {
+		return "", NewValidationError(
+			ErrCodeDatasourceConfig,
+			"invalid datasource URL",
+			http.StatusBadRequest,
+		).WithCause(err).WithDetail("url", datasourceURL)
+	}
+
+	baseURL.Path = path.Join(append([]string{baseURL.Path}, elem...)...)
+	return baseURL.String(), nil
+}
+
+// FetchJSON sends a GET request to a datasource API endpoint and decodes the JSON
+// response into out. HTTP failures are mapped to ValidationErrors the same way for
+// every datasource type.
+func FetchJSON(ctx context.Context, client *http.Client, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return NewValidationError(
+			ErrCodeInternal,
+			"failed to create HTTP request",
+			http.StatusInternalServerError,
+		).WithCause(err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "timeout") {
+			return NewAPITimeoutError(endpoint, err)
+		}
+		return NewDatasourceUnreachableError("", endpoint, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		body = []byte("<unable to read response body>")
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return NewDatasourceAuthError("", resp.StatusCode).
+			WithDetail("url", endpoint).
+			WithDetail("responseBody", string(body))
+	case http.StatusNotFound:
+		return NewAPIUnavailableError(
+			resp.StatusCode,
+			string(body),
+			fmt.Errorf("endpoint not found - the datasource may not support this API"),
+		).WithDetail("url", endpoint)
+	case http.StatusTooManyRequests:
+		return NewValidationError(
+			ErrCodeAPIRateLimit,
+			"datasource API rate limit exceeded",
+			http.StatusTooManyRequests,
+		).WithDetail("url", endpoint).WithDetail("responseBody", string(body))
+	default:
+		return NewAPIUnavailableError(resp.StatusCode, string(body), nil).
+			WithDetail("url", endpoint)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return NewAPIInvalidResponseError(
+			"response is not valid JSON",
+			err,
+		).WithDetail("url", endpoint).WithDetail("responseBody", string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+)
+
+// Fetcher fetches available label names from a Loki datasource
+type Fetcher struct{}
+
+// NewFetcher creates a new Loki labels fetcher
+func NewFetcher() *Fetcher {
+	return &Fetcher{}
+}
+
+// labelsResponse represents the Loki labels API response structure
+type labelsResponse struct {
+	Status string   `json:"status"`
+	Data   []string `json:"data"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// FetchMetrics queries Loki to get all available label names.
+// It uses the /loki/api/v1/labels endpoint, which covers Loki's default
+// lookback window (the last 6 hours unless configured otherwise).
+// The provided HTTP client should have proper authentication configured.
+func (f *Fetcher) FetchMetrics(ctx context.Context, datasourceURL string, client *http.Client) ([]string, error) {
+	endpoint, err := validator.JoinURL(datasourceURL, "loki/api/v1/labels")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp labelsResponse
+	if err := validator.FetchJSON(ctx, client, endpoint, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Status != "success" {
+		errorMsg := resp.Error
+		if errorMsg == "" {
+			errorMsg = "unknown error"
+		}
+		return nil, validator.NewAPIInvalidResponseError(
+			fmt.Sprintf("Loki API returned error status: %s", errorMsg),
+			nil,
+		).WithDetail("url", endpoint).WithDetail("lokiError", errorMsg)
+	}
+
+	if resp.Data == nil {
+		// Loki omits data when no streams were ingested in the lookback window
+		return []string{}, nil
+	}
+
+	return resp.Data, nil
+}
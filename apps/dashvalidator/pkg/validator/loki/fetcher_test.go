@@ -0,0 +1,84 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchMetrics_Success_ReturnsLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		require.Equal(t, "/loki/api/v1/labels", r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","data":["app","env","namespace"]}`))
+	}))
+	defer server.Close()
+
+	labels, err := NewFetcher().FetchMetrics(context.Background(), server.URL, server.Client())
+
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"app", "env", "namespace"}, labels)
+}
+
+func TestFetchMetrics_NoData_ReturnsEmptyList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer server.Close()
+
+	labels, err := NewFetcher().FetchMetrics(context.Background(), server.URL, server.Client())
+
+	require.NoError(t, err)
+	require.Empty(t, labels)
+}
+
+func TestFetchMetrics_HTTPStatusCodes_ReturnsExpectedError(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		expectedCode validator.ErrorCode
+	}{
+		{"unauthorized", http.StatusUnauthorized, validator.ErrCodeDatasourceAuth},
+		{"forbidden", http.StatusForbidden, validator.ErrCodeDatasourceAuth},
+		{"not found", http.StatusNotFound, validator.ErrCodeAPIUnavailable},
+		{"rate limited", http.StatusTooManyRequests, validator.ErrCodeAPIRateLimit},
+		{"bad gateway", http.StatusBadGateway, validator.ErrCodeAPIUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			_, err := NewFetcher().FetchMetrics(context.Background(), server.URL, server.Client())
+
+			require.Error(t, err)
+			validationErr := validator.GetValidationError(err)
+			require.NotNil(t, validationErr)
+			require.Equal(t, tt.expectedCode, validationErr.Code)
+		})
+	}
+}
+
+func TestFetchMetrics_StatusError_ReturnsInvalidResponseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"error","error":"too many streams"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewFetcher().FetchMetrics(context.Background(), server.URL, server.Client())
+
+	require.Error(t, err)
+	validationErr := validator.GetValidationError(err)
+	require.NotNil(t, validationErr)
+	require.Equal(t, validator.ErrCodeAPIInvalidResponse, validationErr.Code)
+	require.Equal(t, "too many streams", validationErr.Details["lokiError"])
+}
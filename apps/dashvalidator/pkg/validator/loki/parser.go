@@ -0,0 +1,99 @@
+package loki
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Parser extracts the label names of the stream selectors in LogQL queries
+type Parser struct{}
+
+// NewParser creates a new LogQL parser
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// ExtractMetrics parses a LogQL query and extracts the label names used by its
+// stream selectors. Pipeline stages, line filters and label filters are not
+// checked, since they can reference labels extracted at query time.
+// For example: `sum(rate({app="api", env=~"$env"} |= "error" [5m]))` returns ["app", "env"]
+func (p *Parser) ExtractMetrics(query string) ([]string, error) {
+	selectors, err := findStreamSelectors(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("failed to parse LogQL query: no stream selector found")
+	}
+
+	labels := make(map[string]bool)
+	for _, selector := range selectors {
+		// Template variables only appear in matcher values, which are string
+		// literals, so selectors parse without interpolation
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LogQL stream selector %s: %w", selector, err)
+		}
+		for _, matcher := range matchers {
+			labels[matcher.Name] = true
+		}
+	}
+
+	result := make([]string, 0, len(labels))
+	for label := range labels {
+		result = append(result, label)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// findStreamSelectors returns the {...} stream selectors of a LogQL query,
+// skipping braces inside string literals (e.g. line_format templates).
+func findStreamSelectors(query string) ([]string, error) {
+	var selectors []string
+	start := -1
+	var quote byte
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+
+		if quote != 0 {
+			if ch == '\\' && quote == '"' {
+				i++
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch ch {
+		case '"', '`':
+			quote = ch
+		case '{':
+			if start != -1 {
+				return nil, fmt.Errorf("failed to parse LogQL query: nested '{' at position %d", i)
+			}
+			start = i
+		case '}':
+			if start == -1 {
+				return nil, fmt.Errorf("failed to parse LogQL query: unexpected '}' at position %d", i)
+			}
+			selectors = append(selectors, query[start:i+1])
+			start = -1
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("failed to parse LogQL query: unterminated string")
+	}
+	if start != -1 {
+		return nil, fmt.Errorf("failed to parse LogQL query: unterminated stream selector")
+	}
+
+	return selectors, nil
+}
@@ -0,0 +1,86 @@
+package loki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMetrics(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		name          string
+		query         string
+		expected      []string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:     "single stream selector",
+			query:    `{app="api"}`,
+			expected: []string{"app"},
+		},
+		{
+			name:     "multiple matchers",
+			query:    `{app="api", env!="dev", pod=~"api-.*"}`,
+			expected: []string{"app", "env", "pod"},
+		},
+		{
+			name:     "metric query with line filter and range",
+			query:    `sum by (level) (rate({app="api"} |= "error" [$__auto]))`,
+			expected: []string{"app"},
+		},
+		{
+			name:     "template variables in matcher values",
+			query:    `{namespace=~"$namespace", app="${app}"}`,
+			expected: []string{"app", "namespace"},
+		},
+		{
+			name:     "braces inside line_format are ignored",
+			query:    `{app="api"} | json | line_format "{{.status}} {{.path}}"`,
+			expected: []string{"app"},
+		},
+		{
+			name:     "braces inside backtick strings are ignored",
+			query:    "{app=\"api\"} |~ `\\{\"level\":\"error\"`",
+			expected: []string{"app"},
+		},
+		{
+			name:     "binary operation with two selectors",
+			query:    `count_over_time({app="api"}[5m]) / count_over_time({job="worker"}[5m])`,
+			expected: []string{"app", "job"},
+		},
+		{
+			name:          "no stream selector",
+			query:         `vector(1)`,
+			expectError:   true,
+			errorContains: "no stream selector",
+		},
+		{
+			name:          "unterminated selector",
+			query:         `{app="api"`,
+			expectError:   true,
+			errorContains: "unterminated stream selector",
+		},
+		{
+			name:          "invalid matcher",
+			query:         `{app}`,
+			expectError:   true,
+			errorContains: "failed to parse LogQL stream selector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := parser.ExtractMetrics(tt.query)
+			if tt.expectError {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, labels)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+)
+
+// LokiProvider implements cache.MetricsProvider for Loki datasources.
+// It wraps the Fetcher and returns results with a configurable TTL.
+type LokiProvider struct {
+	fetcher validator.MetricsFetcher
+	ttl     time.Duration
+}
+
+// NewLokiProvider creates a new LokiProvider with the given TTL.
+func NewLokiProvider(ttl time.Duration) *LokiProvider {
+	return &LokiProvider{
+		fetcher: NewFetcher(),
+		ttl:     ttl,
+	}
+}
+
+// GetMetrics implements cache.MetricsProvider.
+// It fetches available label names from Loki and returns them with the provider's TTL.
+func (p *LokiProvider) GetMetrics(ctx context.Context, datasourceUID, datasourceURL string,
+	client *http.Client) (*cache.MetricsResult, error) {
+	labels, err := p.fetcher.FetchMetrics(ctx, datasourceURL, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache.MetricsResult{
+		Metrics: labels,
+		TTL:     p.ttl,
+	}, nil
+}
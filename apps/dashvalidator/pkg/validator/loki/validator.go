@@ -0,0 +1,48 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// Validator implements validator.DatasourceValidator for Loki datasources.
+// The entities checked are the label names used by stream selectors.
+type Validator struct {
+	parser validator.MetricExtractor
+	cache  *cache.MetricsCache
+}
+
+// evaluate at compile time that Validator implements DatasourceValidator interface
+var _ validator.DatasourceValidator = (*Validator)(nil)
+
+// NewValidator creates a new Loki validator.
+// The metricsCache parameter is required - pass nil will cause a panic.
+func NewValidator(mc *cache.MetricsCache) *Validator {
+	if mc == nil {
+		panic("metricsCache cannot be nil")
+	}
+	return &Validator{
+		parser: NewParser(),
+		cache:  mc,
+	}
+}
+
+// ValidateQueries validates LogQL queries against the labels of the datasource.
+func (v *Validator) ValidateQueries(ctx context.Context, queries []validator.Query, datasource validator.Datasource) (*validator.ValidationResult, error) {
+	parsed := make([]validator.ParsedQuery, len(queries))
+	for i, query := range queries {
+		labels, err := v.parser.ExtractMetrics(query.QueryText)
+		parsed[i] = validator.ParsedQuery{Metrics: labels, Err: err}
+	}
+
+	available, err := v.cache.GetMetrics(ctx, datasources.DS_LOKI, datasource.UID, datasource.URL, datasource.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch labels from Loki: %w", err)
+	}
+
+	return validator.BuildValidationResult(queries, parsed, validator.ToSet(available)), nil
+}
@@ -0,0 +1,76 @@
+package loki
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDatasource(t *testing.T, labels string) validator.Datasource {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(labels))
+	}))
+	t.Cleanup(server.Close)
+
+	return validator.Datasource{
+		UID:        "loki-uid",
+		Type:       "loki",
+		URL:        server.URL,
+		HTTPClient: server.Client(),
+	}
+}
+
+func newTestValidator() *Validator {
+	metricsCache := cache.NewMetricsCache()
+	metricsCache.RegisterProvider("loki", NewLokiProvider(cache.DefaultMetricsCacheTTL))
+	return NewValidator(metricsCache)
+}
+
+func TestValidateQueries_ReportsMissingLabels(t *testing.T) {
+	ds := newTestDatasource(t, `{"status":"success","data":["app","namespace"]}`)
+
+	queries := []validator.Query{
+		{RefID: "A", QueryText: `{app="api", namespace="prod"} |= "error"`, PanelTitle: "Errors", PanelID: 1},
+		{RefID: "B", QueryText: `sum(rate({app="api", cluster="eu"}[5m]))`, PanelTitle: "Rate", PanelID: 2},
+		{RefID: "C", QueryText: `{app=`, PanelTitle: "Broken", PanelID: 3},
+	}
+
+	result, err := newTestValidator().ValidateQueries(context.Background(), queries, ds)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, result.TotalQueries)
+	require.Equal(t, 2, result.CheckedQueries)
+	require.Equal(t, 3, result.TotalMetrics)
+	require.Equal(t, 2, result.FoundMetrics)
+	require.Equal(t, []string{"cluster"}, result.MissingMetrics)
+	require.InDelta(t, 2.0/3.0, result.CompatibilityScore, 0.001)
+
+	require.Len(t, result.QueryBreakdown, 3)
+	require.Equal(t, 1.0, result.QueryBreakdown[0].CompatibilityScore)
+	require.Equal(t, []string{"cluster"}, result.QueryBreakdown[1].MissingMetrics)
+	require.InDelta(t, 0.5, result.QueryBreakdown[1].CompatibilityScore, 0.001)
+	require.NotNil(t, result.QueryBreakdown[2].ParseError)
+	require.Equal(t, 0.0, result.QueryBreakdown[2].CompatibilityScore)
+}
+
+func TestValidateQueries_FetchError_ReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	ds := validator.Datasource{UID: "loki-uid", Type: "loki", URL: server.URL, HTTPClient: server.Client()}
+	_, err := newTestValidator().ValidateQueries(context.Background(), []validator.Query{{RefID: "A", QueryText: `{app="api"}`}}, ds)
+
+	require.Error(t, err)
+	var validationErr *validator.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, validator.ErrCodeDatasourceAuth, validationErr.Code)
+}
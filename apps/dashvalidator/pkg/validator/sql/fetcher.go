@@ -0,0 +1,98 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// QueryRunner runs a raw SQL query against a datasource and returns the rows
+// of the result as strings. SQL datasources have no HTTP API to list their
+// schema, so they are queried through Grafana's query service instead.
+type QueryRunner interface {
+	RunQuery(ctx context.Context, dsType, datasourceUID, rawSQL string) ([][]string, error)
+}
+
+// columnsQueries lists the columns visible to the datasource user, per datasource type.
+// Every query returns schema, table and column.
+var columnsQueries = map[string]string{
+	datasources.DS_MYSQL: "SELECT table_schema, table_name, column_name FROM information_schema.columns " +
+		"WHERE table_schema = DATABASE()",
+	datasources.DS_POSTGRES: "SELECT table_schema, table_name, column_name FROM information_schema.columns " +
+		"WHERE table_schema NOT IN ('pg_catalog', 'information_schema')",
+	datasources.DS_MSSQL: "SELECT table_schema, table_name, column_name FROM information_schema.columns",
+}
+
+// SupportedTypes returns the SQL datasource types the validator supports
+func SupportedTypes() []string {
+	types := make([]string, 0, len(columnsQueries))
+	for dsType := range columnsQueries {
+		types = append(types, dsType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Fetcher fetches the tables and columns of a SQL datasource from information_schema
+type Fetcher struct {
+	runner QueryRunner
+	dsType string
+}
+
+// NewFetcher creates a new SQL schema fetcher for the given datasource type
+func NewFetcher(runner QueryRunner, dsType string) *Fetcher {
+	return &Fetcher{
+		runner: runner,
+		dsType: dsType,
+	}
+}
+
+// FetchMetrics returns the tables and columns of the datasource in the formats
+// produced by the Parser: table, schema.table, table.column and schema.table.column.
+func (f *Fetcher) FetchMetrics(ctx context.Context, datasourceUID string) ([]string, error) {
+	query, ok := columnsQueries[f.dsType]
+	if !ok {
+		return nil, validator.NewValidationError(
+			validator.ErrCodeDatasourceWrongType,
+			fmt.Sprintf("datasource type %s is not a supported SQL datasource", f.dsType),
+			http.StatusBadRequest,
+		).WithDetail("datasourceUID", datasourceUID)
+	}
+
+	rows, err := f.runner.RunQuery(ctx, f.dsType, datasourceUID, query)
+	if err != nil {
+		return nil, validator.NewValidationError(
+			validator.ErrCodeAPIUnavailable,
+			"failed to list the tables of the datasource",
+			http.StatusBadGateway,
+		).WithCause(err).WithDetail("datasourceUID", datasourceUID)
+	}
+
+	entities := make(map[string]bool)
+	for _, row := range rows {
+		if len(row) != 3 {
+			return nil, validator.NewAPIInvalidResponseError(
+				fmt.Sprintf("expected 3 columns in information_schema result, got %d", len(row)),
+				nil,
+			).WithDetail("datasourceUID", datasourceUID)
+		}
+		schema, table, column := strings.ToLower(row[0]), strings.ToLower(row[1]), strings.ToLower(row[2])
+		entities[table] = true
+		entities[schema+"."+table] = true
+		entities[table+"."+column] = true
+		entities[schema+"."+table+"."+column] = true
+	}
+
+	result := make([]string, 0, len(entities))
+	for entity := range entities {
+		result = append(result, entity)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
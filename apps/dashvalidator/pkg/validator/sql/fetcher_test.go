@@ -0,0 +1,85 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner implements QueryRunner for testing
+type fakeRunner struct {
+	rows    [][]string
+	err     error
+	queries []string
+}
+
+func (f *fakeRunner) RunQuery(ctx context.Context, dsType, datasourceUID, rawSQL string) ([][]string, error) {
+	f.queries = append(f.queries, rawSQL)
+	return f.rows, f.err
+}
+
+func TestFetchMetrics_ReturnsTablesAndColumns(t *testing.T) {
+	runner := &fakeRunner{rows: [][]string{
+		{"public", "Orders", "id"},
+		{"public", "Orders", "created_at"},
+	}}
+
+	entities, err := NewFetcher(runner, "grafana-postgresql-datasource").FetchMetrics(context.Background(), "pg-uid")
+
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"orders", "orders.created_at", "orders.id",
+		"public.orders", "public.orders.created_at", "public.orders.id",
+	}, entities)
+	require.Len(t, runner.queries, 1)
+	require.Contains(t, runner.queries[0], "information_schema.columns")
+}
+
+func TestFetchMetrics_RunnerError_ReturnsAPIUnavailableError(t *testing.T) {
+	runner := &fakeRunner{err: errors.New("access denied")}
+
+	_, err := NewFetcher(runner, "mysql").FetchMetrics(context.Background(), "mysql-uid")
+
+	require.Error(t, err)
+	validationErr := validator.GetValidationError(err)
+	require.NotNil(t, validationErr)
+	require.Equal(t, validator.ErrCodeAPIUnavailable, validationErr.Code)
+}
+
+func TestFetchMetrics_UnsupportedType_ReturnsWrongTypeError(t *testing.T) {
+	_, err := NewFetcher(&fakeRunner{}, "sqlite").FetchMetrics(context.Background(), "uid")
+
+	require.Error(t, err)
+	validationErr := validator.GetValidationError(err)
+	require.NotNil(t, validationErr)
+	require.Equal(t, validator.ErrCodeDatasourceWrongType, validationErr.Code)
+}
+
+func TestValidateQueries_ReportsMissingTablesAndColumns(t *testing.T) {
+	runner := &fakeRunner{rows: [][]string{
+		{"shop", "orders", "id"},
+		{"shop", "orders", "created_at"},
+	}}
+	metricsCache := cache.NewMetricsCache()
+	metricsCache.RegisterProvider("mysql", NewSQLProvider(runner, "mysql", cache.DefaultMetricsCacheTTL))
+	v := NewValidator(metricsCache, "mysql")
+
+	queries := []validator.Query{
+		{RefID: "A", QueryText: "SELECT $__time(created_at), count(*) FROM orders WHERE $__timeFilter(created_at)"},
+		{RefID: "B", QueryText: "SELECT * FROM shop.orders JOIN customers ON true"},
+		{RefID: "C", QueryText: "SELECT $__time(ts) FROM orders"},
+	}
+
+	result, err := v.ValidateQueries(context.Background(), queries, validator.Datasource{UID: "mysql-uid", Type: "mysql"})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, result.CheckedQueries)
+	require.Equal(t, []string{"customers", "orders.ts"}, result.MissingMetrics)
+	require.Equal(t, 1.0, result.QueryBreakdown[0].CompatibilityScore)
+	require.InDelta(t, 0.5, result.QueryBreakdown[1].CompatibilityScore, 0.001)
+	require.InDelta(t, 0.5, result.QueryBreakdown[2].CompatibilityScore, 0.001)
+}
@@ -0,0 +1,300 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// timeMacroRegex matches the Grafana SQL time macros that take a column, e.g. $__timeFilter(created_at)
+var timeMacroRegex = regexp.MustCompile(`\$__(?:timeFilter|timeGroup|timeGroupAlias|time|timeEpoch|unixEpochFilter|unixEpochNanoFilter|unixEpochGroup|unixEpochGroupAlias)\(\s*([^,)\s]+)`)
+
+// Parser extracts table and column references from SQL queries
+type Parser struct{}
+
+// NewParser creates a new SQL parser
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// ExtractMetrics parses a SQL query and extracts the tables it reads from.
+// Tables are returned as "table" or "schema.table". When the query reads a single
+// table, columns passed to time macros are returned as "table.column" as well.
+// Names are lowercased, since identifiers are compared case-insensitively.
+// For example: "SELECT $__time(ts), v FROM metrics.cpu WHERE $__timeFilter(ts)"
+// returns ["metrics.cpu", "metrics.cpu.ts"].
+func (p *Parser) ExtractMetrics(query string) ([]string, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := findTables(tokens)
+	entities := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		entities[table] = true
+	}
+
+	if len(tables) == 1 {
+		for _, match := range timeMacroRegex.FindAllStringSubmatch(query, -1) {
+			column := unquoteIdentifier(match[1])
+			// skip expressions and qualified columns, which cannot be matched reliably
+			if isPlainIdentifier(column) {
+				entities[tables[0]+"."+strings.ToLower(column)] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(entities))
+	for entity := range entities {
+		result = append(result, entity)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a SQL query into words, quoted identifiers and punctuation,
+// dropping comments and string literals.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("failed to parse SQL query: unterminated comment")
+			}
+			i += end + 4
+		case ch == '\'':
+			end, err := closingQuote(query, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case ch == '"' || ch == '`' || ch == '[':
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			end, err := closingQuote(query, i, closing)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: query[i+1 : end-1]})
+			i = end
+		case isWordChar(ch):
+			start := i
+			for i < len(query) && isWordChar(query[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: query[start:i]})
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(ch)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+// closingQuote returns the position after the quote closing the literal starting at start.
+// A doubled quote inside the literal is an escaped quote.
+func closingQuote(query string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, fmt.Errorf("failed to parse SQL query: unterminated %c at position %d", query[start], start)
+}
+
+func isWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' || ch == '@' || ch == '#' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// findTables returns the tables referenced by FROM and JOIN clauses, skipping
+// subqueries, common table expressions and template variables.
+func findTables(tokens []token) []string {
+	cteNames := findCTENames(tokens)
+	var tables []string
+	seen := make(map[string]bool)
+
+	// subquery[depth] tells whether the parenthesis at that depth holds a query,
+	// so FROM inside function calls like EXTRACT(YEAR FROM ts) is ignored
+	subquery := []bool{true}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind == tokenPunct {
+			switch tok.text {
+			case "(":
+				subquery = append(subquery, i+1 < len(tokens) && (isKeyword(tokens[i+1], "select") || isKeyword(tokens[i+1], "with")))
+			case ")":
+				if len(subquery) > 1 {
+					subquery = subquery[:len(subquery)-1]
+				}
+			}
+			continue
+		}
+		if !subquery[len(subquery)-1] || !(isKeyword(tok, "from") || isKeyword(tok, "join")) {
+			continue
+		}
+
+		// FROM a, b JOIN c: read comma separated table references
+		for j := i + 1; j < len(tokens); {
+			name, next := readTableName(tokens, j)
+			if name != "" && !cteNames[name] && !seen[name] {
+				seen[name] = true
+				tables = append(tables, name)
+			}
+			next = skipAlias(tokens, next)
+			if next < len(tokens) && tokens[next].kind == tokenPunct && tokens[next].text == "," && isKeyword(tok, "from") {
+				j = next + 1
+				continue
+			}
+			i = next - 1
+			break
+		}
+	}
+
+	return tables
+}
+
+// readTableName reads a possibly qualified table name starting at tokens[i].
+// It returns "" for subqueries, table functions and template variables, and the
+// position of the first token after the name.
+func readTableName(tokens []token, i int) (string, int) {
+	var parts []string
+	for i < len(tokens) {
+		tok := tokens[i]
+		if tok.kind == tokenPunct || (tok.kind == tokenWord && isReservedWord(tok.text)) {
+			break
+		}
+		parts = append(parts, tok.text)
+		i++
+		if i < len(tokens) && tokens[i].kind == tokenPunct && tokens[i].text == "." {
+			i++
+			continue
+		}
+		break
+	}
+
+	if len(parts) == 0 {
+		return "", i
+	}
+	// table functions such as generate_series(...) or UNNEST(...)
+	if i < len(tokens) && tokens[i].kind == tokenPunct && tokens[i].text == "(" {
+		return "", i
+	}
+	for _, part := range parts {
+		if strings.HasPrefix(part, "$") || strings.HasPrefix(part, "@") || strings.HasPrefix(part, "#") {
+			return "", i
+		}
+	}
+	// database.schema.table (MSSQL): the database is implied by the datasource
+	if len(parts) > 2 {
+		parts = parts[len(parts)-2:]
+	}
+
+	return strings.ToLower(strings.Join(parts, ".")), i
+}
+
+// skipAlias skips an optional "AS alias" or bare alias after a table reference
+func skipAlias(tokens []token, i int) int {
+	if i < len(tokens) && isKeyword(tokens[i], "as") {
+		i++
+	}
+	if i < len(tokens) && (tokens[i].kind == tokenQuoted || (tokens[i].kind == tokenWord && !isReservedWord(tokens[i].text))) {
+		i++
+	}
+	return i
+}
+
+// findCTENames returns the names defined by WITH name AS (...) clauses
+func findCTENames(tokens []token) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i].kind == tokenPunct || !isKeyword(tokens[i+1], "as") || tokens[i+2].text != "(" {
+			continue
+		}
+		// the name follows WITH (or RECURSIVE) or a comma separating CTEs
+		if i > 0 && (isKeyword(tokens[i-1], "with") || isKeyword(tokens[i-1], "recursive") || tokens[i-1].text == ",") {
+			names[strings.ToLower(tokens[i].text)] = true
+		}
+	}
+	return names
+}
+
+func isKeyword(tok token, keyword string) bool {
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+// reservedWords are the keywords that can follow a table reference
+var reservedWords = map[string]bool{
+	"where": true, "group": true, "order": true, "having": true, "limit": true, "offset": true,
+	"join": true, "inner": true, "left": true, "right": true, "full": true, "outer": true, "cross": true,
+	"natural": true, "on": true, "using": true, "union": true, "except": true, "intersect": true,
+	"select": true, "from": true, "as": true, "with": true, "window": true, "fetch": true, "for": true,
+	"lateral": true, "tablesample": true, "pivot": true, "unpivot": true, "option": true,
+}
+
+func isReservedWord(word string) bool {
+	return reservedWords[strings.ToLower(word)]
+}
+
+// isPlainIdentifier reports whether s is an unqualified column name
+func isPlainIdentifier(s string) bool {
+	if s == "" || strings.HasPrefix(s, "$") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isWordChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// unquoteIdentifier strips the quotes of a "quoted", `quoted` or [quoted] identifier
+func unquoteIdentifier(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"',
+			s[0] == '`' && s[len(s)-1] == '`',
+			s[0] == '[' && s[len(s)-1] == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMetrics(t *testing.T) {
+	parser := NewParser()
+
+	tests := []struct {
+		name          string
+		query         string
+		expected      []string
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:     "single table with time macros",
+			query:    "SELECT $__timeGroupAlias(created_at, $__interval), count(*) FROM orders WHERE $__timeFilter(created_at) GROUP BY 1",
+			expected: []string{"orders", "orders.created_at"},
+		},
+		{
+			name:     "schema qualified and quoted table",
+			query:    `SELECT $__time("ts"), value FROM "metrics"."CPU" WHERE $__timeFilter("ts")`,
+			expected: []string{"metrics.cpu", "metrics.cpu.ts"},
+		},
+		{
+			name:     "joins with aliases",
+			query:    "SELECT o.id, c.name FROM orders o LEFT JOIN customers AS c ON c.id = o.customer_id JOIN `shop`.`items` i USING (id)",
+			expected: []string{"customers", "orders", "shop.items"},
+		},
+		{
+			name:     "comma separated tables",
+			query:    "SELECT * FROM orders o, customers c WHERE o.customer_id = c.id",
+			expected: []string{"customers", "orders"},
+		},
+		{
+			name:     "time macro columns are skipped for multiple tables",
+			query:    "SELECT * FROM orders JOIN customers ON true WHERE $__timeFilter(created_at)",
+			expected: []string{"customers", "orders"},
+		},
+		{
+			name:     "subquery and function FROM",
+			query:    "SELECT EXTRACT(YEAR FROM ts) FROM (SELECT ts FROM events) AS e",
+			expected: []string{"events"},
+		},
+		{
+			name:     "common table expressions are not tables",
+			query:    "WITH recent AS (SELECT * FROM events), totals AS (SELECT count(*) FROM recent) SELECT * FROM totals",
+			expected: []string{"events"},
+		},
+		{
+			name:     "MSSQL database qualified table",
+			query:    "SELECT TOP 10 * FROM [sales].[dbo].[Orders] WITH (NOLOCK)",
+			expected: []string{"dbo.orders"},
+		},
+		{
+			name:     "template variable table and table functions are skipped",
+			query:    "SELECT * FROM $table JOIN generate_series(1, 10) g ON true",
+			expected: []string{},
+		},
+		{
+			name:     "comments and string literals are ignored",
+			query:    "SELECT 'from users' AS x -- FROM accounts\nFROM /* FROM admins */ logins",
+			expected: []string{"logins"},
+		},
+		{
+			name:     "no table",
+			query:    "SELECT 1",
+			expected: []string{},
+		},
+		{
+			name:          "unterminated string",
+			query:         "SELECT * FROM users WHERE name = 'bob",
+			expectError:   true,
+			errorContains: "unterminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities, err := parser.ExtractMetrics(tt.query)
+			if tt.expectError {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, entities)
+		})
+	}
+}
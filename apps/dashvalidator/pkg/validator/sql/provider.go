@@ -0,0 +1,39 @@
+package sql
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+)
+
+// SQLProvider implements cache.MetricsProvider for SQL datasources.
+// It wraps the Fetcher and returns results with a configurable TTL.
+type SQLProvider struct {
+	fetcher *Fetcher
+	ttl     time.Duration
+}
+
+// NewSQLProvider creates a new SQLProvider for the given datasource type with the given TTL.
+func NewSQLProvider(runner QueryRunner, dsType string, ttl time.Duration) *SQLProvider {
+	return &SQLProvider{
+		fetcher: NewFetcher(runner, dsType),
+		ttl:     ttl,
+	}
+}
+
+// GetMetrics implements cache.MetricsProvider.
+// The datasource is queried through the QueryRunner, so the URL and client are not used.
+func (p *SQLProvider) GetMetrics(ctx context.Context, datasourceUID, datasourceURL string,
+	client *http.Client) (*cache.MetricsResult, error) {
+	entities, err := p.fetcher.FetchMetrics(ctx, datasourceUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache.MetricsResult{
+		Metrics: entities,
+		TTL:     p.ttl,
+	}, nil
+}
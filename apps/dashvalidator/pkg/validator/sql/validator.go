@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+)
+
+// Validator implements validator.DatasourceValidator for SQL datasources
+// (MySQL, PostgreSQL and Microsoft SQL Server).
+// The entities checked are the tables of each query, and the columns passed
+// to time macros of single-table queries.
+type Validator struct {
+	parser validator.MetricExtractor
+	cache  *cache.MetricsCache
+	dsType string
+}
+
+// evaluate at compile time that Validator implements DatasourceValidator interface
+var _ validator.DatasourceValidator = (*Validator)(nil)
+
+// NewValidator creates a new SQL validator for the given datasource type.
+// The metricsCache parameter is required - pass nil will cause a panic.
+func NewValidator(mc *cache.MetricsCache, dsType string) *Validator {
+	if mc == nil {
+		panic("metricsCache cannot be nil")
+	}
+	return &Validator{
+		parser: NewParser(),
+		cache:  mc,
+		dsType: dsType,
+	}
+}
+
+// ValidateQueries validates SQL queries against the schema of the datasource.
+func (v *Validator) ValidateQueries(ctx context.Context, queries []validator.Query, datasource validator.Datasource) (*validator.ValidationResult, error) {
+	parsed := make([]validator.ParsedQuery, len(queries))
+	for i, query := range queries {
+		entities, err := v.parser.ExtractMetrics(query.QueryText)
+		parsed[i] = validator.ParsedQuery{Metrics: entities, Err: err}
+	}
+
+	available, err := v.cache.GetMetrics(ctx, v.dsType, datasource.UID, datasource.URL, datasource.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema from %s: %w", v.dsType, err)
+	}
+
+	return validator.BuildValidationResult(queries, parsed, validator.ToSet(available)), nil
+}
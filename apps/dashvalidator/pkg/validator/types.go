@@ -29,19 +29,21 @@ type MetricsFetcher interface {
 
 // Query represents a dashboard query to validate
 type Query struct {
-	RefID      string // Query reference ID (A, B, C, etc.)
-	QueryText  string // The actual query text (PromQL, SQL, etc.)
-	PanelTitle string // Panel title for user-friendly reporting
-	PanelID    int    // Panel ID for reference
+	RefID      string                 // Query reference ID (A, B, C, etc.)
+	QueryText  string                 // The actual query text (PromQL, SQL, etc.)
+	PanelTitle string                 // Panel title for user-friendly reporting
+	PanelID    int                    // Panel ID for reference
+	Target     map[string]interface{} // Raw query model, for entities referenced outside the query text (e.g. Elasticsearch metric fields)
 }
 
 // Datasource contains connection information for a datasource
 type Datasource struct {
-	UID        string       // Datasource UID from dashboard
-	Type       string       // Datasource type (prometheus, mysql, etc.)
-	Name       string       // Datasource name for reporting
-	URL        string       // Datasource URL for API calls
-	HTTPClient *http.Client // Authenticated HTTP client for making requests
+	UID        string                 // Datasource UID from dashboard
+	Type       string                 // Datasource type (prometheus, mysql, etc.)
+	Name       string                 // Datasource name for reporting
+	URL        string                 // Datasource URL for API calls
+	HTTPClient *http.Client           // Authenticated HTTP client for making requests
+	JSONData   map[string]interface{} // Datasource settings (e.g. the Elasticsearch index pattern)
 }
 
 // CompatibilityResult contains the shared metrics compatibility fields
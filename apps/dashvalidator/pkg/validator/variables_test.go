@@ -68,7 +68,7 @@ func TestExtractVariableName(t *testing.T) {
 	}
 }
 
-func TestIsPrometheusVariable(t *testing.T) {
+func TestIsDatasourceVariable(t *testing.T) {
 	// Dashboard with Prometheus __inputs
 	dashboardWithPrometheus := map[string]interface{}{
 		"__inputs": []interface{}{
@@ -99,21 +99,23 @@ func TestIsPrometheusVariable(t *testing.T) {
 	tests := []struct {
 		name      string
 		varRef    string
+		pluginID  string
 		dashboard map[string]interface{}
 		expected  bool
 	}{
-		{"prometheus variable with inputs", "${DS_PROMETHEUS}", dashboardWithPrometheus, true},
-		{"prometheus simple var", "$DS_PROMETHEUS", dashboardWithPrometheus, true},
-		{"mysql variable", "${DS_MYSQL}", dashboardWithMySQL, false},
-		{"not variable", "concrete-uid", dashboardWithPrometheus, false},
-		{"variable without inputs", "${prometheus}", dashboardWithoutInputs, true}, // Fallback to true for MVP
-		{"wrong variable name", "${OTHER}", dashboardWithPrometheus, false},
+		{"prometheus variable with inputs", "${DS_PROMETHEUS}", "prometheus", dashboardWithPrometheus, true},
+		{"prometheus simple var", "$DS_PROMETHEUS", "prometheus", dashboardWithPrometheus, true},
+		{"mysql variable", "${DS_MYSQL}", "prometheus", dashboardWithMySQL, false},
+		{"mysql variable for mysql", "${DS_MYSQL}", "mysql", dashboardWithMySQL, true},
+		{"not variable", "concrete-uid", "prometheus", dashboardWithPrometheus, false},
+		{"variable without inputs", "${prometheus}", "prometheus", dashboardWithoutInputs, true}, // Fallback to true for MVP
+		{"wrong variable name", "${OTHER}", "prometheus", dashboardWithPrometheus, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isPrometheusVariable(tt.varRef, tt.dashboard)
-			require.Equal(t, tt.expected, result, "isPrometheusVariable(%q, dashboard) returned unexpected result", tt.varRef)
+			result := isDatasourceVariable(tt.varRef, tt.pluginID, tt.dashboard)
+			require.Equal(t, tt.expected, result, "isDatasourceVariable(%q, %q, dashboard) returned unexpected result", tt.varRef, tt.pluginID)
 		})
 	}
 }
@@ -144,21 +146,23 @@ func TestResolveDatasourceUID(t *testing.T) {
 	tests := []struct {
 		name        string
 		uid         string
+		dsType      string
 		dashboard   map[string]interface{}
 		expectedUID string
 		description string
 	}{
-		{"concrete uid", "concrete-123", dashboardWithPrometheus, "concrete-123", "should return concrete UID as-is"},
-		{"prometheus variable", "${DS_PROMETHEUS}", dashboardWithPrometheus, singleUID, "should resolve to single datasource UID"},
-		{"prometheus simple var", "$DS_PROMETHEUS", dashboardWithPrometheus, singleUID, "should resolve simple $ syntax"},
-		{"mysql variable", "${DS_MYSQL}", dashboardWithMySQL, "${DS_MYSQL}", "should return non-Prometheus variable as-is"},
-		{"empty uid", "", dashboardWithPrometheus, "", "should return empty string as-is"},
+		{"concrete uid", "concrete-123", "prometheus", dashboardWithPrometheus, "concrete-123", "should return concrete UID as-is"},
+		{"prometheus variable", "${DS_PROMETHEUS}", "prometheus", dashboardWithPrometheus, singleUID, "should resolve to single datasource UID"},
+		{"prometheus simple var", "$DS_PROMETHEUS", "prometheus", dashboardWithPrometheus, singleUID, "should resolve simple $ syntax"},
+		{"mysql variable", "${DS_MYSQL}", "prometheus", dashboardWithMySQL, "${DS_MYSQL}", "should return non-Prometheus variable as-is"},
+		{"mysql variable for mysql datasource", "${DS_MYSQL}", "mysql", dashboardWithMySQL, singleUID, "should resolve variables of the validated datasource type"},
+		{"empty uid", "", "prometheus", dashboardWithPrometheus, "", "should return empty string as-is"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := resolveDatasourceUID(tt.uid, singleUID, tt.dashboard)
-			require.Equal(t, tt.expectedUID, result, "resolveDatasourceUID(%q, %q, dashboard): %s", tt.uid, singleUID, tt.description)
+			result := resolveDatasourceUID(tt.uid, singleUID, tt.dsType, tt.dashboard)
+			require.Equal(t, tt.expectedUID, result, "resolveDatasourceUID(%q, %q, %q, dashboard): %s", tt.uid, singleUID, tt.dsType, tt.description)
 		})
 	}
 }
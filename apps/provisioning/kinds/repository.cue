@@ -21,6 +21,15 @@ repository: {
 					// Path to the local repository
 					path: string
 				}
+				#BucketRepositoryConfig: {
+					// The bucket URL, in the Go CDK format (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `azblob://my-container`).
+					url: string
+					// The account of the bucket credentials: the access key ID for S3, or the storage account name for Azure Blob Storage.
+					// The secret (secret access key, service account key or account key) is the repository token.
+					account?: string
+					// Path is the prefix of the Grafana data in the bucket. If specified, Grafana will ignore any object outside of it.
+					path?: string
+				}
 				#GitHubRepositoryConfig: {
 					// The repository URL (e.g. `https://github.com/example/test`).
 					url?: string
@@ -33,6 +42,10 @@ repository: {
 					// Whether we should show dashboard previews for pull requests.
 					// By default, this is false (i.e. we will not create previews).
 					generateDashboardPreviews?: bool
+					// Whether we should deploy a read-only preview environment for pull requests.
+					// The dashboards changed in the pull request are written to a temporary folder, which is removed
+					// when the pull request is closed or its branch is deleted.
+					generatePreviewEnvironments?: bool
 					// Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository.
 					path?: string
 				}
@@ -84,6 +97,14 @@ repository: {
 					// When non-zero, the sync will run periodically
 					intervalSeconds?: int
 				}
+				#DriftOptions: {
+					// Enabled must be saved as true before any drift check will run
+					enabled: bool
+					// What to do with the resources that drifted
+					policy?: "alert" | "revert" | "export"
+					// When non-zero, the drift check will run periodically
+					intervalSeconds?: int
+				}
 				#ConnectionInfo: {
 					name: string
 				}
@@ -119,6 +140,33 @@ repository: {
 					resource: string
 					count:    int
 				}
+				#DriftedResource: {
+					// Path to the file in the repository
+					path:      string
+					group?:    string
+					resource?: string
+					name?:     string
+					// Why the resource is reported
+					reason: "modifiedInGrafana" | "modifiedInRepository" | "missingInGrafana" | "missingInRepository"
+					// The drift was reconciled by the configured policy
+					reconciled?: bool
+					// Error reconciling or comparing the resource
+					error?: string
+				}
+				#DriftStatus: {
+					// The ID for the job that ran the last drift check
+					job?: string
+					// When the last drift check started
+					checked?: int
+					// The repository ref the resources were compared with
+					ref?: string
+					// The number of resources that drifted
+					count: int
+					// The number of resources that could not be compared
+					failed?: int
+					// The resources that drifted (truncated)
+					resources?: [...#DriftedResource]
+				}
 				#WebhookStatus: {
 					id?:               int
 					url?:              string
@@ -138,8 +186,10 @@ repository: {
 					workflows?: [...string]
 					// Sync settings -- how values are pulled from the repository into grafana
 					sync: #SyncOptions
+					// Drift detection settings -- how changes made outside of the sync are found and reconciled
+					drift?: #DriftOptions
 					// The repository type. When selected oneOf the values below should be non-nil
-					type: "local" | "github" | "git" | "bitbucket" | "gitlab"
+					type: "local" | "github" | "git" | "bitbucket" | "gitlab" | "bucket"
 					// The repository on the local file system.
 					// Mutually exclusive with local | github.
 					local?: #LocalRepositoryConfig
@@ -155,6 +205,9 @@ repository: {
 					// The repository on GitLab.
 					// Mutually exclusive with local | github | git.
 					gitlab?: #GitLabRepositoryConfig
+					// The repository in an object storage bucket.
+					// Mutually exclusive with local | github | git.
+					bucket?: #BucketRepositoryConfig
 					// The connection the repository references.
 					// This means the Repository is interacting with git via a Connection.
 					connection?: #ConnectionInfo
@@ -170,6 +223,8 @@ repository: {
 					stats?: [...#ResourceCount]
 					// Webhook Information (if applicable)
 					webhook?: #WebhookStatus
+					// The result of the last drift check (if enabled)
+					drift?: #DriftStatus
 				}
 			}
 		}
@@ -32,6 +32,10 @@ const (
 	// ConditionTypePullStatus indicates the outcome of the last completed pull operation.
 	// True = last pull succeeded, False = last pull failed (quota exceeded, general error, etc.).
 	ConditionTypePullStatus = "PullStatus"
+
+	// ConditionTypeResourcesInSync indicates whether the stored resources match the repository contents.
+	// True = no drift found by the last check, False = resources drifted or the check failed.
+	ConditionTypeResourcesInSync = "ResourcesInSync"
 )
 
 // Condition reasons for the Ready condition
@@ -71,6 +75,16 @@ const (
 	ReasonResourceInvalid = "ResourceInvalid"
 )
 
+// Condition reasons for the ResourcesInSync condition
+const (
+	// ReasonNoDrift indicates all resources match the repository contents.
+	ReasonNoDrift = "NoDrift"
+	// ReasonDriftDetected indicates some resources differ from the repository contents.
+	ReasonDriftDetected = "DriftDetected"
+	// ReasonDriftReconciled indicates the drift policy reconciled every resource that differed.
+	ReasonDriftReconciled = "DriftReconciled"
+)
+
 // Condition reasons for the Quota condition
 const (
 	// ReasonWithinQuota indicates all quota limits are satisfied.
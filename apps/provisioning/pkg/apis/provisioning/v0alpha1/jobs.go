@@ -61,6 +61,9 @@ const (
 	// JobActionMove moves files in the remote repository
 	JobActionMove JobAction = "move"
 
+	// JobActionDrift compares the repository contents with the stored resources, and reconciles them based on the drift policy.
+	JobActionDrift JobAction = "drift"
+
 	// JobActionFixFolderMetadata is a placeholder job that will eventually regenerate folder metadata files.
 	// Currently a no-op to unblock frontend development.
 	JobActionFixFolderMetadata JobAction = "fixFolderMetadata"
@@ -117,6 +120,9 @@ type JobSpec struct {
 
 	// Options when the action is `fix-folder-metadata`
 	FixFolderMetadata *FixFolderMetadataJobOptions `json:"fixFolderMetadata,omitempty"`
+
+	// Options when the action is `drift`
+	Drift *DriftJobOptions `json:"drift,omitempty"`
 }
 
 func (JobSpec) OpenAPIModelName() string {
@@ -135,12 +141,33 @@ type PullRequestJobOptions struct {
 
 	// URL to the originator (eg, PR URL)
 	URL string `json:"url,omitempty"`
+
+	// Full name of the repository the pull request comes from (eg, owner/repo).
+	// It differs from the repository for pull requests opened from forks.
+	HeadRepository string `json:"headRepository,omitempty"`
+
+	// Deploy the preview environment of the pull request.
+	// Pull requests from forks are only deployed once a maintainer labels or approves them.
+	PreviewEnvironment bool `json:"previewEnvironment,omitempty"`
+
+	// The pull request was closed.
+	// Its preview environment is removed.
+	Closed bool `json:"closed,omitempty"`
 }
 
 func (PullRequestJobOptions) OpenAPIModelName() string {
 	return OpenAPIPrefix + "PullRequestJobOptions"
 }
 
+type DriftJobOptions struct {
+	// Overrides the policy configured in the repository (eg, alert for a dry run)
+	Policy DriftPolicy `json:"policy,omitempty"`
+}
+
+func (DriftJobOptions) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftJobOptions"
+}
+
 type SyncJobOptions struct {
 	// Incremental synchronization for versioned repositories
 	Incremental bool `json:"incremental"`
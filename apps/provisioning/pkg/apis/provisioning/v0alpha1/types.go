@@ -74,6 +74,11 @@ type GitHubRepositoryConfig struct {
 	// By default, this is false (i.e. we will not create previews).
 	GenerateDashboardPreviews bool `json:"generateDashboardPreviews,omitempty"`
 
+	// Whether we should deploy a read-only preview environment for pull requests.
+	// The dashboards changed in the pull request are written to a temporary folder, which is removed
+	// when the pull request is closed or its branch is deleted.
+	GeneratePreviewEnvironments bool `json:"generatePreviewEnvironments,omitempty"`
+
 	// Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository.
 	// This is usually something like `grafana/`. Trailing and leading slash are not required. They are always added when needed.
 	// The path is relative to the root of the repository, regardless of the leading slash.
@@ -141,6 +146,24 @@ func (GitLabRepositoryConfig) OpenAPIModelName() string {
 	return OpenAPIPrefix + "GitLabRepositoryConfig"
 }
 
+type BucketRepositoryConfig struct {
+	// The bucket URL, in the Go CDK format (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `azblob://my-container`).
+	// The credentials are set on the repository, never read from the environment of the Grafana server.
+	URL string `json:"url"`
+
+	// The account of the bucket credentials: the access key ID for S3, or the storage account name for Azure Blob Storage.
+	// The secret (secret access key, service account key or account key) is the repository token.
+	Account string `json:"account,omitempty"`
+
+	// Path is the prefix of the Grafana data in the bucket. If specified, Grafana will ignore any object outside of it.
+	// Trailing and leading slash are not required. They are always added when needed.
+	Path string `json:"path,omitempty"`
+}
+
+func (BucketRepositoryConfig) OpenAPIModelName() string {
+	return OpenAPIPrefix + "BucketRepositoryConfig"
+}
+
 // RepositoryType defines the types of Repository
 // +enum
 type RepositoryType string
@@ -156,6 +179,7 @@ const (
 	GitRepositoryType       RepositoryType = "git"
 	BitbucketRepositoryType RepositoryType = "bitbucket"
 	GitLabRepositoryType    RepositoryType = "gitlab"
+	BucketRepositoryType    RepositoryType = "bucket"
 )
 
 // IsGit returns true if the repository type is git or github
@@ -247,6 +271,10 @@ func (r *Repository) Path() string {
 		if r.Spec.Local != nil {
 			return r.Spec.Local.Path
 		}
+	case BucketRepositoryType:
+		if r.Spec.Bucket != nil {
+			return r.Spec.Bucket.Path
+		}
 	default:
 		return ""
 	}
@@ -277,6 +305,9 @@ type RepositorySpec struct {
 	// Sync settings -- how values are pulled from the repository into grafana
 	Sync SyncOptions `json:"sync"`
 
+	// Drift detection settings -- how changes made outside of the sync are found and reconciled
+	Drift *DriftOptions `json:"drift,omitempty"`
+
 	// The repository type.  When selected oneOf the values below should be non-nil
 	Type RepositoryType `json:"type"`
 
@@ -300,6 +331,10 @@ type RepositorySpec struct {
 	// Mutually exclusive with local | github | git.
 	GitLab *GitLabRepositoryConfig `json:"gitlab,omitempty"`
 
+	// The repository in an object storage bucket.
+	// Mutually exclusive with local | github | git.
+	Bucket *BucketRepositoryConfig `json:"bucket,omitempty"`
+
 	// The connection the repository references.
 	// This means the Repository is interacting with git via a Connection.
 	Connection *ConnectionInfo `json:"connection,omitempty"`
@@ -348,6 +383,45 @@ func (SyncOptions) OpenAPIModelName() string {
 	return OpenAPIPrefix + "SyncOptions"
 }
 
+// DriftPolicy defines what happens to resources that drifted from the repository
+// +enum
+type DriftPolicy string
+
+func (DriftPolicy) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftPolicy"
+}
+
+// DriftPolicy values
+const (
+	// Drift is only reported in the repository status
+	DriftPolicyAlert DriftPolicy = "alert"
+
+	// Resources modified in Grafana are overwritten with the repository contents
+	DriftPolicyRevert DriftPolicy = "revert"
+
+	// Resources modified in Grafana are written back to the repository
+	DriftPolicyExport DriftPolicy = "export"
+)
+
+type DriftOptions struct {
+	// Enabled must be saved as true before any drift check will run
+	Enabled bool `json:"enabled"`
+
+	// What to do with the resources that drifted.
+	// Changes made in the repository are always left to the sync, regardless of the policy.
+	// Defaults to alert.
+	Policy DriftPolicy `json:"policy,omitempty"`
+
+	// The interval between drift checks.
+	// The system defines a default value for this field, which will overwrite the
+	// user-defined one in case the latter is zero or lower than the system-defined one.
+	IntervalSeconds int64 `json:"intervalSeconds,omitempty"`
+}
+
+func (DriftOptions) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftOptions"
+}
+
 // The status of a Repository.
 // This is expected never to be created by a kubectl call or similar, and is expected to rarely (if ever) be edited manually.
 // As such, it is also a little less well structured than the spec, such as conditional-but-ever-present fields.
@@ -388,6 +462,9 @@ type RepositoryStatus struct {
 
 	// Quota contains the configured quota limits for this repository
 	Quota QuotaStatus `json:"quota,omitempty"`
+
+	// The result of the last drift check (if enabled)
+	Drift *DriftStatus `json:"drift,omitempty"`
 }
 
 func (RepositoryStatus) OpenAPIModelName() string {
@@ -425,6 +502,79 @@ func (SyncStatus) OpenAPIModelName() string {
 	return OpenAPIPrefix + "SyncStatus"
 }
 
+type DriftStatus struct {
+	// The ID for the job that ran the last drift check
+	JobID string `json:"job,omitempty"`
+
+	// When the last drift check started
+	Checked int64 `json:"checked,omitempty"`
+
+	// The repository ref the resources were compared with
+	Ref string `json:"ref,omitempty"`
+
+	// The number of resources that drifted
+	Count int `json:"count"`
+
+	// The number of resources that could not be compared.
+	// They are reported as errors of the job.
+	Failed int `json:"failed,omitempty"`
+
+	// The resources that drifted.
+	// The list is truncated when too many resources drifted; use count for the total.
+	// +listType=atomic
+	Resources []DriftedResource `json:"resources,omitempty"`
+}
+
+func (DriftStatus) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftStatus"
+}
+
+// DriftReason describes on which side a resource changed
+// +enum
+type DriftReason string
+
+func (DriftReason) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftReason"
+}
+
+// DriftReason values
+const (
+	// The stored resource was edited without going through the repository
+	DriftReasonModifiedInGrafana DriftReason = "modifiedInGrafana"
+
+	// The file changed in the repository, but was not synced yet
+	DriftReasonModifiedInRepository DriftReason = "modifiedInRepository"
+
+	// The file exists in the repository, but the resource does not
+	DriftReasonMissingInGrafana DriftReason = "missingInGrafana"
+
+	// The resource exists, but the file was removed from the repository
+	DriftReasonMissingInRepository DriftReason = "missingInRepository"
+)
+
+type DriftedResource struct {
+	// Path to the file in the repository
+	Path string `json:"path"`
+
+	// The resource identity (if known)
+	Group    string `json:"group,omitempty"`
+	Resource string `json:"resource,omitempty"`
+	Name     string `json:"name,omitempty"`
+
+	// Why the resource is reported
+	Reason DriftReason `json:"reason"`
+
+	// The drift was reconciled by the configured policy
+	Reconciled bool `json:"reconciled,omitempty"`
+
+	// Error reconciling or comparing the resource
+	Error string `json:"error,omitempty"`
+}
+
+func (DriftedResource) OpenAPIModelName() string {
+	return OpenAPIPrefix + "DriftedResource"
+}
+
 type WebhookStatus struct {
 	ID               int64    `json:"id,omitempty"`
 	URL              string   `json:"url,omitempty"`
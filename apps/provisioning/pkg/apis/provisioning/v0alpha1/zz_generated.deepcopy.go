@@ -60,6 +60,22 @@ func (in *BitbucketRepositoryConfig) DeepCopy() *BitbucketRepositoryConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketRepositoryConfig) DeepCopyInto(out *BucketRepositoryConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketRepositoryConfig.
+func (in *BucketRepositoryConfig) DeepCopy() *BucketRepositoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketRepositoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Connection) DeepCopyInto(out *Connection) {
 	*out = *in
@@ -245,6 +261,75 @@ func (in *DeleteJobOptions) DeepCopy() *DeleteJobOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftJobOptions) DeepCopyInto(out *DriftJobOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftJobOptions.
+func (in *DriftJobOptions) DeepCopy() *DriftJobOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftJobOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftOptions) DeepCopyInto(out *DriftOptions) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftOptions.
+func (in *DriftOptions) DeepCopy() *DriftOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftStatus) DeepCopyInto(out *DriftStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]DriftedResource, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftStatus.
+func (in *DriftStatus) DeepCopy() *DriftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedResource) DeepCopyInto(out *DriftedResource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedResource.
+func (in *DriftedResource) DeepCopy() *DriftedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportJobOptions) DeepCopyInto(out *ExportJobOptions) {
 	*out = *in
@@ -712,6 +797,11 @@ func (in *JobSpec) DeepCopyInto(out *JobSpec) {
 		*out = new(FixFolderMetadataJobOptions)
 		**out = **in
 	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftJobOptions)
+		**out = **in
+	}
 	return
 }
 
@@ -996,6 +1086,11 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 		copy(*out, *in)
 	}
 	out.Sync = in.Sync
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftOptions)
+		**out = **in
+	}
 	if in.Local != nil {
 		in, out := &in.Local, &out.Local
 		*out = new(LocalRepositoryConfig)
@@ -1021,6 +1116,11 @@ func (in *RepositorySpec) DeepCopyInto(out *RepositorySpec) {
 		*out = new(GitLabRepositoryConfig)
 		**out = **in
 	}
+	if in.Bucket != nil {
+		in, out := &in.Bucket, &out.Bucket
+		*out = new(BucketRepositoryConfig)
+		**out = **in
+	}
 	if in.Connection != nil {
 		in, out := &in.Connection, &out.Connection
 		*out = new(ConnectionInfo)
@@ -1070,6 +1170,11 @@ func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
 	}
 	out.Token = in.Token
 	out.Quota = in.Quota
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
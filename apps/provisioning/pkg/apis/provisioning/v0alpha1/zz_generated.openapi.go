@@ -18,6 +18,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		Author{}.OpenAPIModelName():                      schema_pkg_apis_provisioning_v0alpha1_Author(ref),
 		BitbucketConnectionConfig{}.OpenAPIModelName():   schema_pkg_apis_provisioning_v0alpha1_BitbucketConnectionConfig(ref),
 		BitbucketRepositoryConfig{}.OpenAPIModelName():   schema_pkg_apis_provisioning_v0alpha1_BitbucketRepositoryConfig(ref),
+		BucketRepositoryConfig{}.OpenAPIModelName():      schema_pkg_apis_provisioning_v0alpha1_BucketRepositoryConfig(ref),
 		Connection{}.OpenAPIModelName():                  schema_pkg_apis_provisioning_v0alpha1_Connection(ref),
 		ConnectionInfo{}.OpenAPIModelName():              schema_pkg_apis_provisioning_v0alpha1_ConnectionInfo(ref),
 		ConnectionList{}.OpenAPIModelName():              schema_pkg_apis_provisioning_v0alpha1_ConnectionList(ref),
@@ -25,6 +26,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		ConnectionSpec{}.OpenAPIModelName():              schema_pkg_apis_provisioning_v0alpha1_ConnectionSpec(ref),
 		ConnectionStatus{}.OpenAPIModelName():            schema_pkg_apis_provisioning_v0alpha1_ConnectionStatus(ref),
 		DeleteJobOptions{}.OpenAPIModelName():            schema_pkg_apis_provisioning_v0alpha1_DeleteJobOptions(ref),
+		DriftJobOptions{}.OpenAPIModelName():             schema_pkg_apis_provisioning_v0alpha1_DriftJobOptions(ref),
+		DriftOptions{}.OpenAPIModelName():                schema_pkg_apis_provisioning_v0alpha1_DriftOptions(ref),
+		DriftStatus{}.OpenAPIModelName():                 schema_pkg_apis_provisioning_v0alpha1_DriftStatus(ref),
+		DriftedResource{}.OpenAPIModelName():             schema_pkg_apis_provisioning_v0alpha1_DriftedResource(ref),
 		ErrorDetails{}.OpenAPIModelName():                schema_pkg_apis_provisioning_v0alpha1_ErrorDetails(ref),
 		ExportJobOptions{}.OpenAPIModelName():            schema_pkg_apis_provisioning_v0alpha1_ExportJobOptions(ref),
 		ExternalRepository{}.OpenAPIModelName():          schema_pkg_apis_provisioning_v0alpha1_ExternalRepository(ref),
@@ -178,6 +183,41 @@ func schema_pkg_apis_provisioning_v0alpha1_BitbucketRepositoryConfig(ref common.
 	}
 }
 
+func schema_pkg_apis_provisioning_v0alpha1_BucketRepositoryConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The bucket URL, in the Go CDK format (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `azblob://my-container`). The credentials are set on the repository, never read from the environment of the Grafana server.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"account": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The account of the bucket credentials: the access key ID for S3, or the storage account name for Azure Blob Storage. The secret (secret access key, service account key or account key) is the repository token.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the prefix of the Grafana data in the bucket. If specified, Grafana will ignore any object outside of it. Trailing and leading slash are not required. They are always added when needed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"url"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_provisioning_v0alpha1_Connection(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -527,6 +567,195 @@ func schema_pkg_apis_provisioning_v0alpha1_DeleteJobOptions(ref common.Reference
 	}
 }
 
+func schema_pkg_apis_provisioning_v0alpha1_DriftJobOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Overrides the policy configured in the repository (eg, alert for a dry run)\n\nPossible enum values:\n - `\"alert\"` Drift is only reported in the repository status\n - `\"export\"` Resources modified in Grafana are written back to the repository\n - `\"revert\"` Resources modified in Grafana are overwritten with the repository contents",
+							Type:        []string{"string"},
+							Format:      "",
+							Enum:        []interface{}{"alert", "export", "revert"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_provisioning_v0alpha1_DriftOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enabled must be saved as true before any drift check will run",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "What to do with the resources that drifted. Changes made in the repository are always left to the sync, regardless of the policy. Defaults to alert.\n\nPossible enum values:\n - `\"alert\"` Drift is only reported in the repository status\n - `\"export\"` Resources modified in Grafana are written back to the repository\n - `\"revert\"` Resources modified in Grafana are overwritten with the repository contents",
+							Type:        []string{"string"},
+							Format:      "",
+							Enum:        []interface{}{"alert", "export", "revert"},
+						},
+					},
+					"intervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The interval between drift checks. The system defines a default value for this field, which will overwrite the user-defined one in case the latter is zero or lower than the system-defined one.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"enabled"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_provisioning_v0alpha1_DriftStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"job": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The ID for the job that ran the last drift check",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"checked": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the last drift check started",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"ref": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The repository ref the resources were compared with",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"count": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of resources that drifted",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of resources that could not be compared. They are reported as errors of the job.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"resources": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "The resources that drifted. The list is truncated when too many resources drifted; use count for the total.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(DriftedResource{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"count"},
+			},
+		},
+		Dependencies: []string{
+			DriftedResource{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_provisioning_v0alpha1_DriftedResource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path to the file in the repository",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"group": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The resource identity (if known)",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resource": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Why the resource is reported\n\nPossible enum values:\n - `\"missingInGrafana\"` The file exists in the repository, but the resource does not\n - `\"missingInRepository\"` The resource exists, but the file was removed from the repository\n - `\"modifiedInGrafana\"` The stored resource was edited without going through the repository\n - `\"modifiedInRepository\"` The file changed in the repository, but was not synced yet",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+							Enum:        []interface{}{"missingInGrafana", "missingInRepository", "modifiedInGrafana", "modifiedInRepository"},
+						},
+					},
+					"reconciled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The drift was reconciled by the configured policy",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"error": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Error reconciling or comparing the resource",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"path", "reason"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_provisioning_v0alpha1_ErrorDetails(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -879,6 +1108,13 @@ func schema_pkg_apis_provisioning_v0alpha1_GitHubRepositoryConfig(ref common.Ref
 							Format:      "",
 						},
 					},
+					"generatePreviewEnvironments": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether we should deploy a read-only preview environment for pull requests. The dashboards changed in the pull request are written to a temporary folder, which is removed when the pull request is closed or its branch is deleted.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"path": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository. This is usually something like `grafana/`. Trailing and leading slash are not required. They are always added when needed. The path is relative to the root of the repository, regardless of the leading slash.\n\nWhen specifying something like `grafana-`, we will not look for `grafana-*`; we will only look for files under the directory `/grafana-/`. That means `/grafana-example.json` would not be found.",
@@ -1456,11 +1692,11 @@ func schema_pkg_apis_provisioning_v0alpha1_JobSpec(ref common.ReferenceCallback)
 				Properties: map[string]spec.Schema{
 					"action": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Possible enum values:\n - `\"delete\"` deletes files in the remote repository\n - `\"fixFolderMetadata\"` is a placeholder job that will eventually regenerate folder metadata files. Currently a no-op to unblock frontend development.\n - `\"migrate\"` acts like JobActionExport, then JobActionPull. It also tries to preserve the history.\n - `\"move\"` moves files in the remote repository\n - `\"pr\"` adds additional useful information to a PR, such as comments with preview links and rendered images.\n - `\"pull\"` replicates the remote branch in the local copy of the repository.\n - `\"push\"` replicates the local copy of the repository in the remote branch.",
+							Description: "Possible enum values:\n - `\"delete\"` deletes files in the remote repository\n - `\"drift\"` compares the repository contents with the stored resources, and reconciles them based on the drift policy.\n - `\"fixFolderMetadata\"` is a placeholder job that will eventually regenerate folder metadata files. Currently a no-op to unblock frontend development.\n - `\"migrate\"` acts like JobActionExport, then JobActionPull. It also tries to preserve the history.\n - `\"move\"` moves files in the remote repository\n - `\"pr\"` adds additional useful information to a PR, such as comments with preview links and rendered images.\n - `\"pull\"` replicates the remote branch in the local copy of the repository.\n - `\"push\"` replicates the local copy of the repository in the remote branch.",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
-							Enum:        []interface{}{"delete", "fixFolderMetadata", "migrate", "move", "pr", "pull", "push"},
+							Enum:        []interface{}{"delete", "drift", "fixFolderMetadata", "migrate", "move", "pr", "pull", "push"},
 						},
 					},
 					"repository": {
@@ -1512,12 +1748,18 @@ func schema_pkg_apis_provisioning_v0alpha1_JobSpec(ref common.ReferenceCallback)
 							Ref:         ref(FixFolderMetadataJobOptions{}.OpenAPIModelName()),
 						},
 					},
+					"drift": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Options when the action is `drift`",
+							Ref:         ref(DriftJobOptions{}.OpenAPIModelName()),
+						},
+					},
 				},
 				Required: []string{"action"},
 			},
 		},
 		Dependencies: []string{
-			DeleteJobOptions{}.OpenAPIModelName(), ExportJobOptions{}.OpenAPIModelName(), FixFolderMetadataJobOptions{}.OpenAPIModelName(), MigrateJobOptions{}.OpenAPIModelName(), MoveJobOptions{}.OpenAPIModelName(), PullRequestJobOptions{}.OpenAPIModelName(), SyncJobOptions{}.OpenAPIModelName()},
+			DeleteJobOptions{}.OpenAPIModelName(), DriftJobOptions{}.OpenAPIModelName(), ExportJobOptions{}.OpenAPIModelName(), FixFolderMetadataJobOptions{}.OpenAPIModelName(), MigrateJobOptions{}.OpenAPIModelName(), MoveJobOptions{}.OpenAPIModelName(), PullRequestJobOptions{}.OpenAPIModelName(), SyncJobOptions{}.OpenAPIModelName()},
 	}
 }
 
@@ -1787,6 +2029,27 @@ func schema_pkg_apis_provisioning_v0alpha1_PullRequestJobOptions(ref common.Refe
 							Format:      "",
 						},
 					},
+					"headRepository": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Full name of the repository the pull request comes from (eg, owner/repo). It differs from the repository for pull requests opened from forks.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"previewEnvironment": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deploy the preview environment of the pull request. Pull requests from forks are only deployed once a maintainer labels or approves them.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"closed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The pull request was closed. Its preview environment is removed.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -2065,13 +2328,19 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositorySpec(ref common.ReferenceCa
 							Ref:         ref(SyncOptions{}.OpenAPIModelName()),
 						},
 					},
+					"drift": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Drift detection settings -- how changes made outside of the sync are found and reconciled",
+							Ref:         ref(DriftOptions{}.OpenAPIModelName()),
+						},
+					},
 					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The repository type.  When selected oneOf the values below should be non-nil\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
+							Description: "The repository type.  When selected oneOf the values below should be non-nil\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"bucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
-							Enum:        []interface{}{"bitbucket", "git", "github", "gitlab", "local"},
+							Enum:        []interface{}{"bitbucket", "bucket", "git", "github", "gitlab", "local"},
 						},
 					},
 					"local": {
@@ -2104,6 +2373,12 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositorySpec(ref common.ReferenceCa
 							Ref:         ref(GitLabRepositoryConfig{}.OpenAPIModelName()),
 						},
 					},
+					"bucket": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The repository in an object storage bucket. Mutually exclusive with local | github | git.",
+							Ref:         ref(BucketRepositoryConfig{}.OpenAPIModelName()),
+						},
+					},
 					"connection": {
 						SchemaProps: spec.SchemaProps{
 							Description: "The connection the repository references. This means the Repository is interacting with git via a Connection.",
@@ -2115,7 +2390,7 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositorySpec(ref common.ReferenceCa
 			},
 		},
 		Dependencies: []string{
-			BitbucketRepositoryConfig{}.OpenAPIModelName(), ConnectionInfo{}.OpenAPIModelName(), GitHubRepositoryConfig{}.OpenAPIModelName(), GitLabRepositoryConfig{}.OpenAPIModelName(), GitRepositoryConfig{}.OpenAPIModelName(), LocalRepositoryConfig{}.OpenAPIModelName(), SyncOptions{}.OpenAPIModelName()},
+			BitbucketRepositoryConfig{}.OpenAPIModelName(), BucketRepositoryConfig{}.OpenAPIModelName(), ConnectionInfo{}.OpenAPIModelName(), DriftOptions{}.OpenAPIModelName(), GitHubRepositoryConfig{}.OpenAPIModelName(), GitLabRepositoryConfig{}.OpenAPIModelName(), GitRepositoryConfig{}.OpenAPIModelName(), LocalRepositoryConfig{}.OpenAPIModelName(), SyncOptions{}.OpenAPIModelName()},
 	}
 }
 
@@ -2237,12 +2512,18 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositoryStatus(ref common.Reference
 							Ref:         ref(QuotaStatus{}.OpenAPIModelName()),
 						},
 					},
+					"drift": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The result of the last drift check (if enabled)",
+							Ref:         ref(DriftStatus{}.OpenAPIModelName()),
+						},
+					},
 				},
 				Required: []string{"observedGeneration", "health", "sync", "webhook"},
 			},
 		},
 		Dependencies: []string{
-			ErrorDetails{}.OpenAPIModelName(), HealthStatus{}.OpenAPIModelName(), QuotaStatus{}.OpenAPIModelName(), ResourceCount{}.OpenAPIModelName(), SyncStatus{}.OpenAPIModelName(), TokenStatus{}.OpenAPIModelName(), WebhookStatus{}.OpenAPIModelName(), "io.k8s.apimachinery.pkg.apis.meta.v1.Condition"},
+			DriftStatus{}.OpenAPIModelName(), ErrorDetails{}.OpenAPIModelName(), HealthStatus{}.OpenAPIModelName(), QuotaStatus{}.OpenAPIModelName(), ResourceCount{}.OpenAPIModelName(), SyncStatus{}.OpenAPIModelName(), TokenStatus{}.OpenAPIModelName(), WebhookStatus{}.OpenAPIModelName(), "io.k8s.apimachinery.pkg.apis.meta.v1.Condition"},
 	}
 }
 
@@ -2310,11 +2591,11 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositoryView(ref common.ReferenceCa
 					},
 					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The repository type\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
+							Description: "The repository type\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"bucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
-							Enum:        []interface{}{"bitbucket", "git", "github", "gitlab", "local"},
+							Enum:        []interface{}{"bitbucket", "bucket", "git", "github", "gitlab", "local"},
 						},
 					},
 					"target": {
@@ -2433,7 +2714,7 @@ func schema_pkg_apis_provisioning_v0alpha1_RepositoryViewList(ref common.Referen
 										Default: "",
 										Type:    []string{"string"},
 										Format:  "",
-										Enum:    []interface{}{"bitbucket", "git", "github", "gitlab", "local"},
+										Enum:    []interface{}{"bitbucket", "bucket", "git", "github", "gitlab", "local"},
 									},
 								},
 							},
@@ -2716,11 +2997,11 @@ func schema_pkg_apis_provisioning_v0alpha1_ResourceRepositoryInfo(ref common.Ref
 				Properties: map[string]spec.Schema{
 					"type": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The repository type\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
+							Description: "The repository type\n\nPossible enum values:\n - `\"bitbucket\"`\n - `\"bucket\"`\n - `\"git\"`\n - `\"github\"`\n - `\"gitlab\"`\n - `\"local\"`",
 							Default:     "",
 							Type:        []string{"string"},
 							Format:      "",
-							Enum:        []interface{}{"bitbucket", "git", "github", "gitlab", "local"},
+							Enum:        []interface{}{"bitbucket", "bucket", "git", "github", "gitlab", "local"},
 						},
 					},
 					"title": {
@@ -14,6 +14,10 @@ type GitHubRepositoryConfigApplyConfiguration struct {
 	// Whether we should show dashboard previews for pull requests.
 	// By default, this is false (i.e. we will not create previews).
 	GenerateDashboardPreviews *bool `json:"generateDashboardPreviews,omitempty"`
+	// Whether we should deploy a read-only preview environment for pull requests.
+	// The dashboards changed in the pull request are written to a temporary folder, which is removed
+	// when the pull request is closed or its branch is deleted.
+	GeneratePreviewEnvironments *bool `json:"generatePreviewEnvironments,omitempty"`
 	// Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository.
 	// This is usually something like `grafana/`. Trailing and leading slash are not required. They are always added when needed.
 	// The path is relative to the root of the repository, regardless of the leading slash.
@@ -52,6 +56,14 @@ func (b *GitHubRepositoryConfigApplyConfiguration) WithGenerateDashboardPreviews
 	return b
 }
 
+// WithGeneratePreviewEnvironments sets the GeneratePreviewEnvironments field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the GeneratePreviewEnvironments field is set to the value of the last call.
+func (b *GitHubRepositoryConfigApplyConfiguration) WithGeneratePreviewEnvironments(value bool) *GitHubRepositoryConfigApplyConfiguration {
+	b.GeneratePreviewEnvironments = &value
+	return b
+}
+
 // WithPath sets the Path field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Path field is set to the value of the last call.
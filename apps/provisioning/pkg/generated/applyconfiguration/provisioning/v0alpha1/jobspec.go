@@ -29,6 +29,8 @@ type JobSpecApplyConfiguration struct {
 	Move *MoveJobOptionsApplyConfiguration `json:"move,omitempty"`
 	// Options when the action is `fix-folder-metadata`
 	FixFolderMetadata *FixFolderMetadataJobOptionsApplyConfiguration `json:"fixFolderMetadata,omitempty"`
+	// Options when the action is `drift`
+	Drift *DriftJobOptionsApplyConfiguration `json:"drift,omitempty"`
 }
 
 // JobSpecApplyConfiguration constructs a declarative configuration of the JobSpec type for use with
@@ -108,3 +110,11 @@ func (b *JobSpecApplyConfiguration) WithFixFolderMetadata(value *FixFolderMetada
 	b.FixFolderMetadata = value
 	return b
 }
+
+// WithDrift sets the Drift field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Drift field is set to the value of the last call.
+func (b *JobSpecApplyConfiguration) WithDrift(value *DriftJobOptionsApplyConfiguration) *JobSpecApplyConfiguration {
+	b.Drift = value
+	return b
+}
@@ -15,6 +15,15 @@ type PullRequestJobOptionsApplyConfiguration struct {
 	Hash *string `json:"hash,omitempty"`
 	// URL to the originator (eg, PR URL)
 	URL *string `json:"url,omitempty"`
+	// Full name of the repository the pull request comes from (eg, owner/repo).
+	// It differs from the repository for pull requests opened from forks.
+	HeadRepository *string `json:"headRepository,omitempty"`
+	// Deploy the preview environment of the pull request.
+	// Pull requests from forks are only deployed once a maintainer labels or approves them.
+	PreviewEnvironment *bool `json:"previewEnvironment,omitempty"`
+	// The pull request was closed.
+	// Its preview environment is removed.
+	Closed *bool `json:"closed,omitempty"`
 }
 
 // PullRequestJobOptionsApplyConfiguration constructs a declarative configuration of the PullRequestJobOptions type for use with
@@ -54,3 +63,27 @@ func (b *PullRequestJobOptionsApplyConfiguration) WithURL(value string) *PullReq
 	b.URL = &value
 	return b
 }
+
+// WithHeadRepository sets the HeadRepository field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the HeadRepository field is set to the value of the last call.
+func (b *PullRequestJobOptionsApplyConfiguration) WithHeadRepository(value string) *PullRequestJobOptionsApplyConfiguration {
+	b.HeadRepository = &value
+	return b
+}
+
+// WithPreviewEnvironment sets the PreviewEnvironment field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreviewEnvironment field is set to the value of the last call.
+func (b *PullRequestJobOptionsApplyConfiguration) WithPreviewEnvironment(value bool) *PullRequestJobOptionsApplyConfiguration {
+	b.PreviewEnvironment = &value
+	return b
+}
+
+// WithClosed sets the Closed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Closed field is set to the value of the last call.
+func (b *PullRequestJobOptionsApplyConfiguration) WithClosed(value bool) *PullRequestJobOptionsApplyConfiguration {
+	b.Closed = &value
+	return b
+}
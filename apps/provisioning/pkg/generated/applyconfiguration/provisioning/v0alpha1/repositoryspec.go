@@ -21,6 +21,8 @@ type RepositorySpecApplyConfiguration struct {
 	Workflows []provisioningv0alpha1.Workflow `json:"workflows,omitempty"`
 	// Sync settings -- how values are pulled from the repository into grafana
 	Sync *SyncOptionsApplyConfiguration `json:"sync,omitempty"`
+	// Drift detection settings -- how changes made outside of the sync are found and reconciled
+	Drift *DriftOptionsApplyConfiguration `json:"drift,omitempty"`
 	// The repository type.  When selected oneOf the values below should be non-nil
 	Type *provisioningv0alpha1.RepositoryType `json:"type,omitempty"`
 	// The repository on the local file system.
@@ -38,6 +40,9 @@ type RepositorySpecApplyConfiguration struct {
 	// The repository on GitLab.
 	// Mutually exclusive with local | github | git.
 	GitLab *GitLabRepositoryConfigApplyConfiguration `json:"gitlab,omitempty"`
+	// The repository in an object storage bucket.
+	// Mutually exclusive with local | github | git.
+	Bucket *BucketRepositoryConfigApplyConfiguration `json:"bucket,omitempty"`
 	// The connection the repository references.
 	// This means the Repository is interacting with git via a Connection.
 	Connection *ConnectionInfoApplyConfiguration `json:"connection,omitempty"`
@@ -83,6 +88,14 @@ func (b *RepositorySpecApplyConfiguration) WithSync(value *SyncOptionsApplyConfi
 	return b
 }
 
+// WithDrift sets the Drift field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Drift field is set to the value of the last call.
+func (b *RepositorySpecApplyConfiguration) WithDrift(value *DriftOptionsApplyConfiguration) *RepositorySpecApplyConfiguration {
+	b.Drift = value
+	return b
+}
+
 // WithType sets the Type field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Type field is set to the value of the last call.
@@ -131,6 +144,14 @@ func (b *RepositorySpecApplyConfiguration) WithGitLab(value *GitLabRepositoryCon
 	return b
 }
 
+// WithBucket sets the Bucket field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Bucket field is set to the value of the last call.
+func (b *RepositorySpecApplyConfiguration) WithBucket(value *BucketRepositoryConfigApplyConfiguration) *RepositorySpecApplyConfiguration {
+	b.Bucket = value
+	return b
+}
+
 // WithConnection sets the Connection field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the Connection field is set to the value of the last call.
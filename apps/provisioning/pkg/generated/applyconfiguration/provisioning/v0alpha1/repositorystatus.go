@@ -36,6 +36,8 @@ type RepositoryStatusApplyConfiguration struct {
 	DeleteError *string `json:"deleteError,omitempty"`
 	// Quota contains the configured quota limits for this repository
 	Quota *QuotaStatusApplyConfiguration `json:"quota,omitempty"`
+	// The result of the last drift check (if enabled)
+	Drift *DriftStatusApplyConfiguration `json:"drift,omitempty"`
 }
 
 // RepositoryStatusApplyConfiguration constructs a declarative configuration of the RepositoryStatus type for use with
@@ -138,3 +140,11 @@ func (b *RepositoryStatusApplyConfiguration) WithQuota(value *QuotaStatusApplyCo
 	b.Quota = value
 	return b
 }
+
+// WithDrift sets the Drift field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Drift field is set to the value of the last call.
+func (b *RepositoryStatusApplyConfiguration) WithDrift(value *DriftStatusApplyConfiguration) *RepositoryStatusApplyConfiguration {
+	b.Drift = value
+	return b
+}
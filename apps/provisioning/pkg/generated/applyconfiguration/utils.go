@@ -22,6 +22,8 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.BitbucketConnectionConfigApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("BitbucketRepositoryConfig"):
 		return &provisioningv0alpha1.BitbucketRepositoryConfigApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("BucketRepositoryConfig"):
+		return &provisioningv0alpha1.BucketRepositoryConfigApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("Connection"):
 		return &provisioningv0alpha1.ConnectionApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ConnectionInfo"):
@@ -34,6 +36,14 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &provisioningv0alpha1.ConnectionStatusApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("DeleteJobOptions"):
 		return &provisioningv0alpha1.DeleteJobOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("DriftedResource"):
+		return &provisioningv0alpha1.DriftedResourceApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("DriftJobOptions"):
+		return &provisioningv0alpha1.DriftJobOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("DriftOptions"):
+		return &provisioningv0alpha1.DriftOptionsApplyConfiguration{}
+	case v0alpha1.SchemeGroupVersion.WithKind("DriftStatus"):
+		return &provisioningv0alpha1.DriftStatusApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ErrorDetails"):
 		return &provisioningv0alpha1.ErrorDetailsApplyConfiguration{}
 	case v0alpha1.SchemeGroupVersion.WithKind("ExportJobOptions"):
@@ -9,6 +9,7 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository/git"
 	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
@@ -73,6 +74,12 @@ func ValidateJob(job *provisioning.Job) error {
 	case provisioning.JobActionFixFolderMetadata:
 		// No required options for fix-folder-metadata; it's a no-op placeholder
 
+	case provisioning.JobActionDrift:
+		// Drift options are optional; the policy defaults to the repository settings
+		if job.Spec.Drift != nil && job.Spec.Drift.Policy != "" && !repository.IsValidDriftPolicy(job.Spec.Drift.Policy) {
+			list = append(list, field.NotSupported(field.NewPath("spec", "drift", "policy"), job.Spec.Drift.Policy, repository.DriftPolicies))
+		}
+
 	default:
 		list = append(list, field.Invalid(field.NewPath("spec", "action"), job.Spec.Action, "invalid action"))
 	}
@@ -606,6 +606,50 @@ func TestValidateJob(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid drift job without options",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-job",
+				},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionDrift,
+					Repository: "test-repo",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid drift job with policy",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-job",
+				},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionDrift,
+					Repository: "test-repo",
+					Drift:      &provisioning.DriftJobOptions{Policy: provisioning.DriftPolicyRevert},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "drift job with unknown policy",
+			job: &provisioning.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-job",
+				},
+				Spec: provisioning.JobSpec{
+					Action:     provisioning.JobActionDrift,
+					Repository: "test-repo",
+					Drift:      &provisioning.DriftJobOptions{Policy: "ignore"},
+				},
+			},
+			wantErr: true,
+			validateError: func(t *testing.T, err error) {
+				require.Contains(t, err.Error(), "spec.drift.policy")
+			},
+		},
 	}
 
 	for _, tt := range tests {
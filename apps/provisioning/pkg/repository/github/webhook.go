@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/google/go-github/v82/github"
 	"github.com/google/uuid"
@@ -18,7 +19,14 @@ import (
 	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
 )
 
-var subscribedEvents = []string{"pull_request", "push"} // same order as slices.Sort()
+var subscribedEvents = []string{"pull_request", "pull_request_review", "push"} // same order as slices.Sort()
+
+// PreviewEnvironmentLabel is the label maintainers add to pull requests from forks to deploy their preview environment.
+// Only users with triage access to the repository can label pull requests.
+const PreviewEnvironmentLabel = "grafana-preview"
+
+// maintainerAssociations are the author associations of the reviewers whose approval deploys the preview environment of a fork.
+var maintainerAssociations = []string{"COLLABORATOR", "MEMBER", "OWNER"}
 
 type WebhookRepository interface {
 	Webhook(ctx context.Context, req *http.Request) (*provisioning.WebhookResponse, error)
@@ -87,6 +95,8 @@ func (r *githubWebhookRepository) parseWebhook(messageType string, payload []byt
 		return r.parsePushEvent(event)
 	case *github.PullRequestEvent:
 		return r.parsePullRequestEvent(event)
+	case *github.PullRequestReviewEvent:
+		return r.parsePullRequestReviewEvent(event)
 	case *github.PingEvent:
 		return &provisioning.WebhookResponse{
 			Code:    http.StatusOK,
@@ -143,54 +153,127 @@ func (r *githubWebhookRepository) parsePushEvent(event *github.PushEvent) (*prov
 }
 
 func (r *githubWebhookRepository) parsePullRequestEvent(event *github.PullRequestEvent) (*provisioning.WebhookResponse, error) {
-	if event.GetRepo() == nil {
-		return nil, fmt.Errorf("missing repository in pull request event")
+	pr, ignored, err := r.validatePullRequest(event.GetRepo(), event.GetPullRequest())
+	if err != nil || ignored != nil {
+		return ignored, err
 	}
 	cfg := r.config.Spec.GitHub
-	if cfg == nil {
-		return nil, fmt.Errorf("missing GitHub config")
-	}
-
-	if event.GetRepo().GetFullName() != fmt.Sprintf("%s/%s", r.owner, r.repo) {
-		return nil, fmt.Errorf("repository mismatch")
-	}
-	pr := event.GetPullRequest()
-	if pr == nil {
-		return nil, fmt.Errorf("expected PR in event")
-	}
 
-	if pr.GetBase().GetRef() != r.config.Spec.GitHub.Branch {
+	action := event.GetAction()
+	if action == "closed" && cfg.GeneratePreviewEnvironments {
+		job := r.pullRequestJob(pr)
+		job.PullRequest.Closed = true
 		return &provisioning.WebhookResponse{
-			Code:    http.StatusOK,
-			Message: fmt.Sprintf("ignoring pull request event as %s is not  the configured branch", pr.GetBase().GetRef()),
+			Code:    http.StatusAccepted,
+			Message: fmt.Sprintf("pull request: %s", action),
+			Job:     job,
 		}, nil
 	}
 
-	action := event.GetAction()
-	if action != "opened" && action != "reopened" && action != "synchronize" {
+	// Labelling a pull request from a fork deploys its preview environment
+	labelled := action == "labeled" && event.GetLabel().GetName() == PreviewEnvironmentLabel && r.isFork(pr) && cfg.GeneratePreviewEnvironments
+	if action != "opened" && action != "reopened" && action != "synchronize" && !labelled {
 		return &provisioning.WebhookResponse{
 			Code:    http.StatusOK, // Nothing needed
 			Message: fmt.Sprintf("ignore pull request event: %s", action),
 		}, nil
 	}
 
+	job := r.pullRequestJob(pr)
+	job.PullRequest.PreviewEnvironment = cfg.GeneratePreviewEnvironments && (!r.isFork(pr) || hasLabel(pr, PreviewEnvironmentLabel))
+
 	// Queue an async job that will parse files
 	return &provisioning.WebhookResponse{
 		Code:    http.StatusAccepted, // Nothing needed
 		Message: fmt.Sprintf("pull request: %s", action),
-		Job: &provisioning.JobSpec{
-			Repository: r.config.GetName(),
-			Action:     provisioning.JobActionPullRequest,
-			PullRequest: &provisioning.PullRequestJobOptions{
-				URL:  pr.GetHTMLURL(),
-				PR:   pr.GetNumber(),
-				Ref:  pr.GetHead().GetRef(),
-				Hash: pr.GetHead().GetSHA(),
-			},
-		},
+		Job:     job,
+	}, nil
+}
+
+// parsePullRequestReviewEvent deploys the preview environment of a pull request from a fork once a maintainer approves it.
+// The other pull requests are already deployed when they are opened or updated.
+func (r *githubWebhookRepository) parsePullRequestReviewEvent(event *github.PullRequestReviewEvent) (*provisioning.WebhookResponse, error) {
+	pr, ignored, err := r.validatePullRequest(event.GetRepo(), event.GetPullRequest())
+	if err != nil || ignored != nil {
+		return ignored, err
+	}
+
+	review := event.GetReview()
+	approved := event.GetAction() == "submitted" && strings.EqualFold(review.GetState(), "approved") &&
+		slices.Contains(maintainerAssociations, review.GetAuthorAssociation())
+	if !approved || !r.isFork(pr) || !r.config.Spec.GitHub.GeneratePreviewEnvironments {
+		return &provisioning.WebhookResponse{
+			Code:    http.StatusOK, // Nothing needed
+			Message: fmt.Sprintf("ignore pull request review event: %s", event.GetAction()),
+		}, nil
+	}
+
+	job := r.pullRequestJob(pr)
+	job.PullRequest.PreviewEnvironment = true
+	return &provisioning.WebhookResponse{
+		Code:    http.StatusAccepted,
+		Message: "pull request approved",
+		Job:     job,
 	}, nil
 }
 
+// validatePullRequest checks that a pull request event targets the configured repository and branch.
+// It returns the response to send when the event must be ignored.
+func (r *githubWebhookRepository) validatePullRequest(repo *github.Repository, pr *github.PullRequest) (*github.PullRequest, *provisioning.WebhookResponse, error) {
+	if repo == nil {
+		return nil, nil, fmt.Errorf("missing repository in pull request event")
+	}
+	if r.config.Spec.GitHub == nil {
+		return nil, nil, fmt.Errorf("missing GitHub config")
+	}
+
+	if repo.GetFullName() != r.fullName() {
+		return nil, nil, fmt.Errorf("repository mismatch")
+	}
+	if pr == nil {
+		return nil, nil, fmt.Errorf("expected PR in event")
+	}
+
+	if pr.GetBase().GetRef() != r.config.Spec.GitHub.Branch {
+		return nil, &provisioning.WebhookResponse{
+			Code:    http.StatusOK,
+			Message: fmt.Sprintf("ignoring pull request event as %s is not  the configured branch", pr.GetBase().GetRef()),
+		}, nil
+	}
+
+	return pr, nil, nil
+}
+
+func (r *githubWebhookRepository) pullRequestJob(pr *github.PullRequest) *provisioning.JobSpec {
+	return &provisioning.JobSpec{
+		Repository: r.config.GetName(),
+		Action:     provisioning.JobActionPullRequest,
+		PullRequest: &provisioning.PullRequestJobOptions{
+			URL:            pr.GetHTMLURL(),
+			PR:             pr.GetNumber(),
+			Ref:            pr.GetHead().GetRef(),
+			Hash:           pr.GetHead().GetSHA(),
+			HeadRepository: pr.GetHead().GetRepo().GetFullName(),
+		},
+	}
+}
+
+func (r *githubWebhookRepository) fullName() string {
+	return fmt.Sprintf("%s/%s", r.owner, r.repo)
+}
+
+// isFork returns true when the pull request comes from another repository.
+// The repository of the head is missing when the fork was deleted, which is handled as a fork.
+func (r *githubWebhookRepository) isFork(pr *github.PullRequest) bool {
+	return pr.GetHead().GetRepo().GetFullName() != r.fullName()
+}
+
+func hasLabel(pr *github.PullRequest, name string) bool {
+	return slices.ContainsFunc(pr.Labels, func(l *github.Label) bool {
+		return l.GetName() == name
+	})
+}
+
 // CommentPullRequest adds a comment to a pull request.
 func (r *githubWebhookRepository) CommentPullRequest(ctx context.Context, prNumber int, comment string) error {
 	ctx, _ = r.logger(ctx, "")
@@ -39,10 +39,11 @@ func TestParseWebhooks(t *testing.T) {
 				Repository: "unit-test-repo",
 				Action:     provisioning.JobActionPullRequest,
 				PullRequest: &provisioning.PullRequestJobOptions{
-					Ref:  "dashboard/1733653266690",
-					Hash: "ab5446a53df9e5f8bdeed52250f51fad08e822bc",
-					PR:   12,
-					URL:  "https://github.com/grafana/git-ui-sync-demo/pull/12",
+					Ref:            "dashboard/1733653266690",
+					Hash:           "ab5446a53df9e5f8bdeed52250f51fad08e822bc",
+					PR:             12,
+					URL:            "https://github.com/grafana/git-ui-sync-demo/pull/12",
+					HeadRepository: "grafana/git-ui-sync-demo",
 				},
 			},
 		}},
@@ -619,6 +620,399 @@ func TestGitHubRepository_Webhook(t *testing.T) {
 				Message: "ignore pull request event: closed",
 			},
 		},
+		{
+			name: "pull request event - closed with preview environments",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "closed",
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"head": {
+							"ref": "feature-branch",
+							"sha": "abcdef1234567890"
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request: closed",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:    "https://github.com/grafana/grafana/pull/123",
+						PR:     123,
+						Ref:    "feature-branch",
+						Hash:   "abcdef1234567890",
+						Closed: true,
+					},
+				},
+			},
+		},
+		{
+			name: "pull request event - opened from a fork",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "opened",
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"head": {
+							"ref": "main",
+							"sha": "abcdef1234567890",
+							"repo": {
+								"full_name": "contributor/grafana"
+							}
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request: opened",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:                "https://github.com/grafana/grafana/pull/123",
+						PR:                 123,
+						Ref:                "main",
+						Hash:               "abcdef1234567890",
+						HeadRepository:     "contributor/grafana",
+						PreviewEnvironment: false,
+					},
+				},
+			},
+		},
+		{
+			name: "pull request event - opened from a labelled fork",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "synchronize",
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"labels": [{"name": "grafana-preview"}],
+						"head": {
+							"ref": "main",
+							"sha": "abcdef1234567890",
+							"repo": {
+								"full_name": "contributor/grafana"
+							}
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request: synchronize",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:                "https://github.com/grafana/grafana/pull/123",
+						PR:                 123,
+						Ref:                "main",
+						Hash:               "abcdef1234567890",
+						HeadRepository:     "contributor/grafana",
+						PreviewEnvironment: true,
+					},
+				},
+			},
+		},
+		{
+			name: "pull request event - fork labelled for preview",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "labeled",
+					"label": {
+						"name": "grafana-preview"
+					},
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"labels": [{"name": "grafana-preview"}],
+						"head": {
+							"ref": "main",
+							"sha": "abcdef1234567890",
+							"repo": {
+								"full_name": "contributor/grafana"
+							}
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request: labeled",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:                "https://github.com/grafana/grafana/pull/123",
+						PR:                 123,
+						Ref:                "main",
+						Hash:               "abcdef1234567890",
+						HeadRepository:     "contributor/grafana",
+						PreviewEnvironment: true,
+					},
+				},
+			},
+		},
+		{
+			name: "pull request review event - fork approved by a maintainer",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "submitted",
+					"review": {
+						"state": "approved",
+						"author_association": "MEMBER"
+					},
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"head": {
+							"ref": "main",
+							"sha": "abcdef1234567890",
+							"repo": {
+								"full_name": "contributor/grafana"
+							}
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request_review")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusAccepted,
+				Message: "pull request approved",
+				Job: &provisioning.JobSpec{
+					Repository: "test-repo",
+					Action:     provisioning.JobActionPullRequest,
+					PullRequest: &provisioning.PullRequestJobOptions{
+						URL:                "https://github.com/grafana/grafana/pull/123",
+						PR:                 123,
+						Ref:                "main",
+						Hash:               "abcdef1234567890",
+						HeadRepository:     "contributor/grafana",
+						PreviewEnvironment: true,
+					},
+				},
+			},
+		},
+		{
+			name: "pull request review event - fork approved by another contributor",
+			config: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repo",
+				},
+				Spec: provisioning.RepositorySpec{
+					GitHub: &provisioning.GitHubRepositoryConfig{
+						Branch:                      "main",
+						GeneratePreviewEnvironments: true,
+					},
+				},
+				Status: provisioning.RepositoryStatus{
+					Webhook: &provisioning.WebhookStatus{},
+				},
+			},
+			setupRequest: func() *http.Request {
+				payload := `{
+					"action": "submitted",
+					"review": {
+						"state": "approved",
+						"author_association": "CONTRIBUTOR"
+					},
+					"pull_request": {
+						"html_url": "https://github.com/grafana/grafana/pull/123",
+						"number": 123,
+						"head": {
+							"ref": "main",
+							"sha": "abcdef1234567890",
+							"repo": {
+								"full_name": "contributor/grafana"
+							}
+						},
+						"base": {
+							"ref": "main"
+						}
+					},
+					"repository": {
+						"full_name": "grafana/grafana"
+					}
+				}`
+				req, _ := http.NewRequest("POST", "/webhook", strings.NewReader(payload))
+				req.Header.Set("X-GitHub-Event", "pull_request_review")
+				req.Header.Set("Content-Type", "application/json")
+
+				// Create a valid signature
+				mac := hmac.New(sha256.New, []byte("webhook-secret"))
+				mac.Write([]byte(payload))
+				signature := hex.EncodeToString(mac.Sum(nil))
+				req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+
+				return req
+			},
+			expected: &provisioning.WebhookResponse{
+				Code:    http.StatusOK,
+				Message: "ignore pull request review event: submitted",
+			},
+		},
 		{
 			name: "pull request event missing repository",
 			config: &provisioning.Repository{
@@ -936,6 +1330,9 @@ func TestGitHubRepository_Webhook(t *testing.T) {
 						require.Equal(t, tt.expected.Job.PullRequest.PR, response.Job.PullRequest.PR)
 						require.Equal(t, tt.expected.Job.PullRequest.Ref, response.Job.PullRequest.Ref)
 						require.Equal(t, tt.expected.Job.PullRequest.Hash, response.Job.PullRequest.Hash)
+						require.Equal(t, tt.expected.Job.PullRequest.Closed, response.Job.PullRequest.Closed)
+						require.Equal(t, tt.expected.Job.PullRequest.HeadRepository, response.Job.PullRequest.HeadRepository)
+						require.Equal(t, tt.expected.Job.PullRequest.PreviewEnvironment, response.Job.PullRequest.PreviewEnvironment)
 					}
 				} else {
 					require.Nil(t, response.Job)
@@ -53,6 +53,10 @@ func (m *AdmissionMutator) Mutate(ctx context.Context, a admission.Attributes, o
 		r.Spec.Sync.IntervalSeconds = int64(m.minSyncInterval.Seconds())
 	}
 
+	if r.Spec.Drift != nil && r.Spec.Drift.Enabled && r.Spec.Drift.IntervalSeconds < int64(m.minSyncInterval.Seconds()) {
+		r.Spec.Drift.IntervalSeconds = int64(m.minSyncInterval.Seconds())
+	}
+
 	if r.Spec.Workflows == nil {
 		r.Spec.Workflows = []provisioning.Workflow{}
 	}
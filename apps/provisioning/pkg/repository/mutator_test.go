@@ -43,16 +43,17 @@ func TestNewAdmissionMutator(t *testing.T) {
 
 func TestAdmissionMutator_Mutate(t *testing.T) {
 	tests := []struct {
-		name            string
-		obj             runtime.Object
-		operation       admission.Operation
-		factoryErr      error
-		minSyncInterval time.Duration
-		wantFinalizers  []string
-		wantInterval    int64
-		wantWorkflows   []provisioning.Workflow
-		wantErr         bool
-		wantErrContains string
+		name              string
+		obj               runtime.Object
+		operation         admission.Operation
+		factoryErr        error
+		minSyncInterval   time.Duration
+		wantFinalizers    []string
+		wantInterval      int64
+		wantDriftInterval int64
+		wantWorkflows     []provisioning.Workflow
+		wantErr           bool
+		wantErrContains   string
 	}{
 		{
 			name: "adds finalizers on create",
@@ -135,6 +136,35 @@ func TestAdmissionMutator_Mutate(t *testing.T) {
 			wantInterval:    120,
 			wantErr:         false,
 		},
+		{
+			name: "sets drift interval to min sync interval when lower",
+			obj: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Finalizers: []string{"existing"}},
+				Spec: provisioning.RepositorySpec{
+					Sync:  provisioning.SyncOptions{IntervalSeconds: 120},
+					Drift: &provisioning.DriftOptions{Enabled: true, IntervalSeconds: 10},
+				},
+			},
+			minSyncInterval:   60 * time.Second,
+			operation:         admission.Update,
+			wantInterval:      120,
+			wantDriftInterval: 60,
+			wantErr:           false,
+		},
+		{
+			name: "preserves drift interval when greater than min sync interval",
+			obj: &provisioning.Repository{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Finalizers: []string{"existing"}},
+				Spec: provisioning.RepositorySpec{
+					Drift: &provisioning.DriftOptions{Enabled: true, IntervalSeconds: 3600},
+				},
+			},
+			minSyncInterval:   60 * time.Second,
+			operation:         admission.Update,
+			wantInterval:      60,
+			wantDriftInterval: 3600,
+			wantErr:           false,
+		},
 		{
 			name: "initializes nil workflows",
 			obj: &provisioning.Repository{
@@ -258,6 +288,10 @@ func TestAdmissionMutator_Mutate(t *testing.T) {
 			if tt.wantInterval > 0 {
 				assert.Equal(t, tt.wantInterval, repo.Spec.Sync.IntervalSeconds)
 			}
+			if tt.wantDriftInterval > 0 {
+				require.NotNil(t, repo.Spec.Drift)
+				assert.Equal(t, tt.wantDriftInterval, repo.Spec.Drift.IntervalSeconds)
+			}
 			if tt.wantWorkflows != nil {
 				assert.Equal(t, tt.wantWorkflows, repo.Spec.Workflows)
 			}
@@ -37,6 +37,18 @@ func NewValidator(allowImageRendering bool, repoFactory Factory) Validator {
 	}
 }
 
+// DriftPolicies lists the supported drift policies
+var DriftPolicies = []provisioning.DriftPolicy{
+	provisioning.DriftPolicyAlert,
+	provisioning.DriftPolicyRevert,
+	provisioning.DriftPolicyExport,
+}
+
+// IsValidDriftPolicy returns true when the drift policy is supported
+func IsValidDriftPolicy(policy provisioning.DriftPolicy) bool {
+	return slices.Contains(DriftPolicies, policy)
+}
+
 // Validate does structural validation (via Factory.Validate) and configuration checks on the repository object.
 // It does not run a health check or compare against existing repositories.
 func (v *RepositoryValidator) Validate(ctx context.Context, cfg *provisioning.Repository) field.ErrorList {
@@ -57,6 +69,21 @@ func (v *RepositoryValidator) Validate(ctx context.Context, cfg *provisioning.Re
 		}
 	}
 
+	if cfg.Spec.Drift != nil && cfg.Spec.Drift.Enabled {
+		if !cfg.Spec.Sync.Enabled {
+			list = append(list, field.Invalid(field.NewPath("spec", "drift", "enabled"),
+				cfg.Spec.Drift.Enabled, "drift detection requires sync to be enabled"))
+		}
+		if cfg.Spec.Drift.Policy != "" && !IsValidDriftPolicy(cfg.Spec.Drift.Policy) {
+			list = append(list, field.NotSupported(field.NewPath("spec", "drift", "policy"),
+				cfg.Spec.Drift.Policy, DriftPolicies))
+		}
+		if cfg.Spec.Drift.Policy == provisioning.DriftPolicyExport && !slices.Contains(cfg.Spec.Workflows, provisioning.WriteWorkflow) {
+			list = append(list, field.Invalid(field.NewPath("spec", "drift", "policy"),
+				cfg.Spec.Drift.Policy, "the export policy requires the write workflow"))
+		}
+	}
+
 	// Reserved names (for now)
 	reserved := []string{"classic", "sql", "SQL", "plugins", "legacy", "new", "job", "github", "s3", "gcs", "file", "new", "create", "update", "delete"}
 	if slices.Contains(reserved, cfg.Name) {
@@ -78,6 +105,11 @@ func (v *RepositoryValidator) Validate(ctx context.Context, cfg *provisioning.Re
 			cfg.Spec.Git, "Git config only valid when type is git"))
 	}
 
+	if cfg.Spec.Type != provisioning.BucketRepositoryType && cfg.Spec.Bucket != nil {
+		list = append(list, field.Invalid(field.NewPath("spec", "bucket"),
+			cfg.Spec.Bucket, "Bucket config only valid when type is bucket"))
+	}
+
 	for _, w := range cfg.Spec.Workflows {
 		switch w {
 		case provisioning.WriteWorkflow: // valid; no fall thru
@@ -172,6 +172,25 @@ func TestValidator_Validate(t *testing.T) {
 				require.Contains(t, errors.ToAggregate().Error(), "spec.git: Invalid value")
 			},
 		},
+		{
+			name: "mismatched bucket config",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title:  "Test Repo",
+						Type:   provisioning.LocalRepositoryType,
+						Bucket: &provisioning.BucketRepositoryConfig{URL: "s3://dashboards"},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Contains(t, errors.ToAggregate().Error(), "spec.bucket: Invalid value")
+			},
+		},
 		{
 			name: "multiple validation errors",
 			repository: func() *provisioning.Repository {
@@ -290,6 +309,88 @@ func TestValidator_Validate(t *testing.T) {
 				require.Contains(t, errors.ToAggregate().Error(), "cannot have no finalizers set on resources not marked for deletion")
 			},
 		},
+		{
+			name: "drift enabled without sync",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Drift: &provisioning.DriftOptions{Enabled: true},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Contains(t, errors.ToAggregate().Error(), "drift detection requires sync to be enabled")
+			},
+		},
+		{
+			name: "drift with unknown policy",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Sync: provisioning.SyncOptions{
+							Enabled: true,
+							Target:  provisioning.SyncTargetTypeFolder,
+						},
+						Drift: &provisioning.DriftOptions{Enabled: true, Policy: "ignore"},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Contains(t, errors.ToAggregate().Error(), "spec.drift.policy: Unsupported value")
+			},
+		},
+		{
+			name: "drift export policy without write workflow",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Sync: provisioning.SyncOptions{
+							Enabled: true,
+							Target:  provisioning.SyncTargetTypeFolder,
+						},
+						Drift: &provisioning.DriftOptions{Enabled: true, Policy: provisioning.DriftPolicyExport},
+					},
+				}
+			}(),
+			expectedErrs: 1,
+			validateError: func(t *testing.T, errors field.ErrorList) {
+				require.Contains(t, errors.ToAggregate().Error(), "the export policy requires the write workflow")
+			},
+		},
+		{
+			name: "valid drift configuration",
+			repository: func() *provisioning.Repository {
+				return &provisioning.Repository{
+					ObjectMeta: metav1.ObjectMeta{
+						Finalizers: []string{CleanFinalizer},
+					},
+					Spec: provisioning.RepositorySpec{
+						Title: "Test Repo",
+						Sync: provisioning.SyncOptions{
+							Enabled: true,
+							Target:  provisioning.SyncTargetTypeFolder,
+						},
+						Workflows: []provisioning.Workflow{provisioning.WriteWorkflow},
+						Drift:     &provisioning.DriftOptions{Enabled: true, Policy: provisioning.DriftPolicyExport},
+					},
+				}
+			}(),
+			expectedErrs: 0,
+		},
 	}
 
 	mockFactory := NewMockFactory(t)
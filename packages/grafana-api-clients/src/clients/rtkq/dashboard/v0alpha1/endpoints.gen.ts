@@ -244,10 +244,13 @@ const injectedRtkApi = api
             folder: queryArg.folder,
             facet: queryArg.facet,
             facetLimit: queryArg.facetLimit,
+            facetInterval: queryArg.facetInterval,
+            facetRange: queryArg.facetRange,
             tags: queryArg.tags,
             libraryPanel: queryArg.libraryPanel,
             panelType: queryArg.panelType,
             dataSourceType: queryArg.dataSourceType,
+            dataSourceUid: queryArg.dataSourceUid,
             permission: queryArg.permission,
             sort: queryArg.sort,
             limit: queryArg.limit,
@@ -255,6 +258,8 @@ const injectedRtkApi = api
             createdBy: queryArg.createdBy,
             explain: queryArg.explain,
             panelTitleSearch: queryArg.panelTitleSearch,
+            panelQuerySearch: queryArg.panelQuerySearch,
+            highlight: queryArg.highlight,
           },
         }),
         providesTags: ['Search'],
@@ -675,6 +680,10 @@ export type SearchDashboardsAndFoldersApiArg = {
   facet?: string[];
   /** maximum number of terms to return per facet (default 50, max 1000) */
   facetLimit?: number;
+  /** count results in date buckets for a timestamp field, in the format {field}:{interval} (eg, updated:1w). The facetLimit sets the number of buckets (default 10) */
+  facetInterval?: string[];
+  /** count results in numeric ranges, in the format {field}:{boundary},{boundary}... (eg, views_last_30_days:1,10,100) */
+  facetRange?: string[];
   /** tag query filter */
   tags?: string[];
   /** find dashboards that reference a given libraryPanel */
@@ -683,6 +692,8 @@ export type SearchDashboardsAndFoldersApiArg = {
   panelType?: string;
   /** find dashboards using datasources of a given plugin type */
   dataSourceType?: string;
+  /** find dashboards using a given datasource */
+  dataSourceUid?: string;
   /** permission needed for the resource (view, edit, admin) */
   permission?: 'view' | 'edit' | 'admin';
   /** sortable field */
@@ -697,6 +708,10 @@ export type SearchDashboardsAndFoldersApiArg = {
   explain?: boolean;
   /** [experimental] optionally include matches from panel titles */
   panelTitleSearch?: boolean;
+  /** [experimental] optionally include matches from panel queries and descriptions */
+  panelQuerySearch?: boolean;
+  /** include the matching text fragments for each field */
+  highlight?: boolean;
 };
 export type GetSortableFieldsApiResponse = /** status 200 undefined */ any;
 export type GetSortableFieldsApiArg = void;
@@ -1186,6 +1201,10 @@ export type DashboardHit = {
   field?: any;
   /** The k8s name (eg, grafana UID) for the parent folder */
   folder?: string;
+  /** The matching text fragments for each field (when highlighting is requested) */
+  highlight?: {
+    [key: string]: string[];
+  };
   managedBy?: ManagedBy;
   /** The k8s "name" (eg, grafana UID) */
   name: string;
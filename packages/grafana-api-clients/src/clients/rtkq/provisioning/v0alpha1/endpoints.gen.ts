@@ -1628,6 +1628,15 @@ export type DeleteJobOptions = {
   /** Resources to delete This option has been created because currently the frontend does not use standarized app platform APIs. For performance and API consistency reasons, the preferred option is it to use the paths. */
   resources?: ResourceRef[];
 };
+export type DriftJobOptions = {
+  /** Overrides the policy configured in the repository (eg, alert for a dry run)
+    
+    Possible enum values:
+     - `"alert"` Drift is only reported in the repository status
+     - `"export"` Resources modified in Grafana are written back to the repository
+     - `"revert"` Resources modified in Grafana are overwritten with the repository contents */
+  policy?: 'alert' | 'export' | 'revert';
+};
 export type FixFolderMetadataJobOptions = {
   /** Ref to the branch to create the commit on (uses repository's default branch if not specified) */
   ref?: string;
@@ -1647,10 +1656,16 @@ export type MoveJobOptions = {
   targetPath?: string;
 };
 export type PullRequestJobOptions = {
+  /** The pull request was closed. Its preview environment is removed. */
+  closed?: boolean;
   /** The specific commit hash that triggered this notice */
   hash?: string;
+  /** Full name of the repository the pull request comes from (eg, owner/repo). It differs from the repository for pull requests opened from forks. */
+  headRepository?: string;
   /** Pull request number (when appropriate) */
   pr?: number;
+  /** Deploy the preview environment of the pull request. Pull requests from forks are only deployed once a maintainer labels or approves them. */
+  previewEnvironment?: boolean;
   /** The branch of commit hash */
   ref?: string;
   /** URL to the originator (eg, PR URL) */
@@ -1673,15 +1688,18 @@ export type ExportJobOptions = {
 export type JobSpec = {
   /** Possible enum values:
      - `"delete"` deletes files in the remote repository
+     - `"drift"` compares the repository contents with the stored resources, and reconciles them based on the drift policy.
      - `"fixFolderMetadata"` is a placeholder job that will eventually regenerate folder metadata files. Currently a no-op to unblock frontend development.
      - `"migrate"` acts like JobActionExport, then JobActionPull. It also tries to preserve the history.
      - `"move"` moves files in the remote repository
      - `"pr"` adds additional useful information to a PR, such as comments with preview links and rendered images.
      - `"pull"` replicates the remote branch in the local copy of the repository.
      - `"push"` replicates the local copy of the repository in the remote branch. */
-  action: 'delete' | 'fixFolderMetadata' | 'migrate' | 'move' | 'pr' | 'pull' | 'push';
+  action: 'delete' | 'drift' | 'fixFolderMetadata' | 'migrate' | 'move' | 'pr' | 'pull' | 'push';
   /** Delete when the action is `delete` */
   delete?: DeleteJobOptions;
+  /** Options when the action is `drift` */
+  drift?: DriftJobOptions;
   /** Options when the action is `fix-folder-metadata` */
   fixFolderMetadata?: FixFolderMetadataJobOptions;
   /** Required when the action is `migrate` */
@@ -1780,9 +1798,30 @@ export type BitbucketRepositoryConfig = {
   /** The repository URL (e.g. `https://bitbucket.org/example/test`). */
   url?: string;
 };
+export type BucketRepositoryConfig = {
+  /** The account of the bucket credentials: the access key ID for S3, or the storage account name for Azure Blob Storage. The secret (secret access key, service account key or account key) is the repository token. */
+  account?: string;
+  /** Path is the prefix of the Grafana data in the bucket. If specified, Grafana will ignore any object outside of it. Trailing and leading slash are not required. They are always added when needed. */
+  path?: string;
+  /** The bucket URL, in the Go CDK format (e.g. `s3://my-bucket?region=us-east-1`, `gs://my-bucket` or `azblob://my-container`). The credentials are set on the repository, never read from the environment of the Grafana server. */
+  url: string;
+};
 export type ConnectionInfo = {
   name: string;
 };
+export type DriftOptions = {
+  /** Enabled must be saved as true before any drift check will run */
+  enabled: boolean;
+  /** The interval between drift checks. The system defines a default value for this field, which will overwrite the user-defined one in case the latter is zero or lower than the system-defined one. */
+  intervalSeconds?: number;
+  /** What to do with the resources that drifted. Changes made in the repository are always left to the sync, regardless of the policy. Defaults to alert.
+    
+    Possible enum values:
+     - `"alert"` Drift is only reported in the repository status
+     - `"export"` Resources modified in Grafana are written back to the repository
+     - `"revert"` Resources modified in Grafana are overwritten with the repository contents */
+  policy?: 'alert' | 'export' | 'revert';
+};
 export type GitRepositoryConfig = {
   /** The branch to use in the repository. */
   branch: string;
@@ -1800,6 +1839,8 @@ export type GitHubRepositoryConfig = {
   branch: string;
   /** Whether we should show dashboard previews for pull requests. By default, this is false (i.e. we will not create previews). */
   generateDashboardPreviews?: boolean;
+  /** Whether we should deploy a read-only preview environment for pull requests. The dashboards changed in the pull request are written to a temporary folder, which is removed when the pull request is closed or its branch is deleted. */
+  generatePreviewEnvironments?: boolean;
   /** Path is the subdirectory for the Grafana data. If specified, Grafana will ignore anything that is outside this directory in the repository. This is usually something like `grafana/`. Trailing and leading slash are not required. They are always added when needed. The path is relative to the root of the repository, regardless of the leading slash.
     
     When specifying something like `grafana-`, we will not look for `grafana-*`; we will only look for files under the directory `/grafana-/`. That means `/grafana-example.json` would not be found. */
@@ -1835,10 +1876,14 @@ export type SyncOptions = {
 export type RepositorySpec = {
   /** The repository on Bitbucket. Mutually exclusive with local | github | git. */
   bitbucket?: BitbucketRepositoryConfig;
+  /** The repository in an object storage bucket. Mutually exclusive with local | github | git. */
+  bucket?: BucketRepositoryConfig;
   /** The connection the repository references. This means the Repository is interacting with git via a Connection. */
   connection?: ConnectionInfo;
   /** Repository description */
   description?: string;
+  /** Drift detection settings -- how changes made outside of the sync are found and reconciled */
+  drift?: DriftOptions;
   /** The repository on Git. Mutually exclusive with local | github | git. */
   git?: GitRepositoryConfig;
   /** The repository on GitHub. Mutually exclusive with local | github | git. */
@@ -1855,14 +1900,49 @@ export type RepositorySpec = {
     
     Possible enum values:
      - `"bitbucket"`
+     - `"bucket"`
      - `"git"`
      - `"github"`
      - `"gitlab"`
      - `"local"` */
-  type: 'bitbucket' | 'git' | 'github' | 'gitlab' | 'local';
+  type: 'bitbucket' | 'bucket' | 'git' | 'github' | 'gitlab' | 'local';
   /** UI driven Workflow that allow changes to the contends of the repository. The order is relevant for defining the precedence of the workflows. When empty, the repository does not support any edits (eg, readonly) */
   workflows: ('branch' | 'write')[];
 };
+export type DriftedResource = {
+  /** Error reconciling or comparing the resource */
+  error?: string;
+  /** The resource identity (if known) */
+  group?: string;
+  name?: string;
+  /** Path to the file in the repository */
+  path: string;
+  /** Why the resource is reported
+    
+    Possible enum values:
+     - `"missingInGrafana"` The file exists in the repository, but the resource does not
+     - `"missingInRepository"` The resource exists, but the file was removed from the repository
+     - `"modifiedInGrafana"` The stored resource was edited without going through the repository
+     - `"modifiedInRepository"` The file changed in the repository, but was not synced yet */
+  reason: 'missingInGrafana' | 'missingInRepository' | 'modifiedInGrafana' | 'modifiedInRepository';
+  /** The drift was reconciled by the configured policy */
+  reconciled?: boolean;
+  resource?: string;
+};
+export type DriftStatus = {
+  /** When the last drift check started */
+  checked?: number;
+  /** The number of resources that drifted */
+  count: number;
+  /** The number of resources that could not be compared. They are reported as errors of the job. */
+  failed?: number;
+  /** The ID for the job that ran the last drift check */
+  job?: string;
+  /** The repository ref the resources were compared with */
+  ref?: string;
+  /** The resources that drifted. The list is truncated when too many resources drifted; use count for the total. */
+  resources?: DriftedResource[];
+};
 export type QuotaStatus = {
   /** MaxRepositories is the maximum number of repositories allowed. 0 means unlimited. */
   maxRepositories?: number;
@@ -1914,6 +1994,8 @@ export type RepositoryStatus = {
   conditions?: Condition[];
   /** Error information during repository deletion (if any) */
   deleteError?: string;
+  /** The result of the last drift check (if enabled) */
+  drift?: DriftStatus;
   /** FieldErrors are errors that occurred during validation of the repository spec. These errors are intended to help users identify and fix issues in the spec. */
   fieldErrors?: ErrorDetails[];
   /** This will get updated with the current health status (and updated periodically) */
@@ -1960,11 +2042,12 @@ export type ResourceRepositoryInfo = {
     
     Possible enum values:
      - `"bitbucket"`
+     - `"bucket"`
      - `"git"`
      - `"github"`
      - `"gitlab"`
      - `"local"` */
-  type: 'bitbucket' | 'git' | 'github' | 'gitlab' | 'local';
+  type: 'bitbucket' | 'bucket' | 'git' | 'github' | 'gitlab' | 'local';
 };
 export type Unstructured = {
   [key: string]: any;
@@ -2087,11 +2170,12 @@ export type RepositoryView = {
     
     Possible enum values:
      - `"bitbucket"`
+     - `"bucket"`
      - `"git"`
      - `"github"`
      - `"gitlab"`
      - `"local"` */
-  type: 'bitbucket' | 'git' | 'github' | 'gitlab' | 'local';
+  type: 'bitbucket' | 'bucket' | 'git' | 'github' | 'gitlab' | 'local';
   /** For git, this is the target URL */
   url?: string;
   /** The supported workflows */
@@ -2105,7 +2189,7 @@ export type RepositoryViewList = {
   /** APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources */
   apiVersion?: string;
   /** AvailableRepositoryTypes is the list of repository types supported in this instance (e.g. git, bitbucket, github, etc) */
-  availableRepositoryTypes?: ('bitbucket' | 'git' | 'github' | 'gitlab' | 'local')[];
+  availableRepositoryTypes?: ('bitbucket' | 'bucket' | 'git' | 'github' | 'gitlab' | 'local')[];
   items: RepositoryView[];
   /** Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds */
   kind?: string;
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	secretcontracts "github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 )
 
@@ -49,3 +52,46 @@ func (hs *HTTPServer) AdminRollbackSecrets(c *contextmodel.ReqContext) response.
 
 	return response.Respond(http.StatusOK, "Secrets rolled back successfully")
 }
+
+type dataKeyRotationStatus struct {
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	Total     int64  `json:"total"`
+	Processed int64  `json:"processed"`
+	Rotated   int64  `json:"rotated"`
+	Failed    int64  `json:"failed"`
+	Message   string `json:"message,omitempty"`
+	Started   int64  `json:"started"`
+	Updated   int64  `json:"updated"`
+	Finished  int64  `json:"finished,omitempty"`
+}
+
+// AdminGetDataKeyRotationStatus returns the progress of the latest data key rotation of the secrets management service.
+// The namespace of the current organization is used unless another one is given.
+func (hs *HTTPServer) AdminGetDataKeyRotationStatus(c *contextmodel.ReqContext) response.Response {
+	namespace := c.Query("namespace")
+	if namespace == "" {
+		namespace = hs.namespacer(c.GetOrgID())
+	}
+
+	rotation, err := hs.dataKeyRotationService.Status(c.Req.Context(), xkube.Namespace(namespace))
+	if err != nil {
+		if errors.Is(err, secretcontracts.ErrDataKeyRotationNotFound) {
+			return response.Error(http.StatusNotFound, "No data key rotation found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get the data key rotation status", err)
+	}
+
+	return response.JSON(http.StatusOK, dataKeyRotationStatus{
+		Namespace: rotation.Namespace,
+		Phase:     string(rotation.Phase),
+		Total:     rotation.Total,
+		Processed: rotation.Processed,
+		Rotated:   rotation.Rotated,
+		Failed:    rotation.Failed,
+		Message:   rotation.Message,
+		Started:   rotation.Started,
+		Updated:   rotation.Updated,
+		Finished:  rotation.Finished,
+	})
+}
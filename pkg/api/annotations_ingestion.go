@@ -0,0 +1,62 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/annotations/ingestion"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /annotations/ingest/{source} annotations ingestAnnotations
+//
+// Create annotations from a webhook.
+//
+// Receives the webhook of an external system configured as an annotation source in the [annotations.ingestion.<source>]
+// section and creates or updates the annotations mapped from its events. The request is authenticated with the
+// signature or the token configured for the source instead of a user.
+//
+// Responses:
+// 200: ingestAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) IngestAnnotations(c *contextmodel.ReqContext) response.Response {
+	maxBodySize := hs.Cfg.AnnotationIngestion.MaxBodySize
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxBodySize+1))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read request body", err)
+	}
+	if int64(len(body)) > maxBodySize {
+		return response.Error(http.StatusRequestEntityTooLarge, "Request body too large", nil)
+	}
+
+	result, err := hs.annotationIngestionService.Ingest(c.Req.Context(), ingestion.IngestCommand{
+		Source: web.Params(c.Req)[":source"],
+		Header: c.Req.Header,
+		Body:   body,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to ingest annotations", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters ingestAnnotations
+type IngestAnnotationsParams struct {
+	// in:path
+	// required:true
+	Source string `json:"source"`
+	// in:body
+	// required:true
+	Body any `json:"body"`
+}
+
+// swagger:response ingestAnnotationsResponse
+type IngestAnnotationsResponse struct {
+	// in:body
+	Body ingestion.IngestResult `json:"body"`
+}
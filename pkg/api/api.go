@@ -42,6 +42,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/ssoutils"
 	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn/clients"
 	"github.com/grafana/grafana/pkg/services/cloudmigration"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/correlations"
@@ -82,6 +83,18 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Post("/login", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginPost))
 	r.Get("/login/:name", quota(string(auth.QuotaTargetSrv)), hs.OAuthLogin)
 
+	// the licensed SAML integration serves its own endpoints
+	if clients.SAMLEnabled(hs.Cfg, hs.License) {
+		// the identity provider posts the response cross-site, the relay state protects it instead
+		acsPath := "/saml/acs"
+		if hs.Cfg.ServeFromSubPath {
+			acsPath = hs.Cfg.AppSubURL + acsPath
+		}
+		hs.Csrf.AddSafeEndpoint(acsPath)
+		r.Post("/saml/acs", quota(string(auth.QuotaTargetSrv)), hs.SAMLACS)
+		r.Get("/saml/metadata", routing.Wrap(hs.SAMLMetadata))
+	}
+
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
 
@@ -234,6 +247,9 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Post("/api/user/signup", quota(user.QuotaTargetSrv), quota(org.QuotaTargetSrv), routing.Wrap(hs.SignUp))
 	r.Post("/api/user/signup/step2", routing.Wrap(hs.SignUpStep2))
 
+	// annotation ingestion webhooks are authenticated with the signature or the token of their source
+	r.Post("/api/annotations/ingest/:source", routing.Wrap(hs.IngestAnnotations))
+
 	// update user email
 	if hs.Cfg.Smtp.Enabled && hs.Cfg.VerifyEmailEnabled {
 		r.Get("/user/email/update", reqSignedInNoAnonymous, routing.Wrap(hs.UpdateUserEmail))
@@ -246,6 +262,11 @@ func (hs *HTTPServer) registerRoutes() {
 		r.Post("/api/login/passwordless/authenticate", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginPasswordless))
 	}
 
+	if hs.Cfg.WebAuthn.Enabled {
+		r.Post("/api/login/webauthn/begin", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.BeginWebAuthnLogin))
+		r.Post("/api/login/webauthn", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginWebAuthn))
+	}
+
 	// invited
 	r.Get("/api/user/invite/:code", routing.Wrap(hs.GetInviteInfoByCode))
 	r.Post("/api/user/invite/complete", routing.Wrap(hs.CompleteInvite))
@@ -314,6 +335,13 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.RevokeUserAuthToken))
+
+			if hs.Cfg.WebAuthn.Enabled {
+				userRoute.Get("/webauthn/credentials", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.GetUserWebAuthnCredentials))
+				userRoute.Post("/webauthn/credentials/begin", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.BeginWebAuthnRegistration))
+				userRoute.Post("/webauthn/credentials", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.FinishWebAuthnRegistration))
+				userRoute.Delete("/webauthn/credentials/:uid", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(hs.DeleteUserWebAuthnCredential))
+			}
 		}, reqSignedInNoAnonymous)
 
 		apiRoute.Group("/users", func(usersRoute routing.RouteRegister) {
@@ -489,6 +517,9 @@ func (hs *HTTPServer) registerRoutes() {
 				dashUidRoute.Get("/versions", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardVersions))
 				dashUidRoute.Post("/restore", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.RestoreDashboardVersion))
 				dashUidRoute.Get("/versions/:id", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardVersion))
+				dashUidRoute.Get("/diff", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardVersionsDiff))
+				dashUidRoute.Get("/blame", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.GetDashboardBlame))
+				dashUidRoute.Post("/restore/elements", authorize(ac.EvalPermission(dashboards.ActionDashboardsWrite, dashUIDScope)), routing.Wrap(hs.RestoreDashboardElements))
 
 				dashUidRoute.Group("/permissions", func(dashboardPermissionRoute routing.RouteRegister) {
 					dashboardPermissionRoute.Get("/", authorize(ac.EvalPermission(dashboards.ActionDashboardsPermissionsRead, dashUIDScope)), routing.Wrap(hs.GetDashboardPermissionList))
@@ -507,6 +538,14 @@ func (hs *HTTPServer) registerRoutes() {
 		// Dashboard snapshots
 		apiRoute.Group("/dashboard/snapshots", func(dashboardRoute routing.RouteRegister) {
 			dashboardRoute.Get("/", authorize(ac.EvalPermission(dashboards.ActionSnapshotsRead)), routing.Wrap(hs.SearchDashboardSnapshots))
+			dashboardRoute.Group("/schedules", func(scheduleRoute routing.RouteRegister) {
+				scheduleRoute.Get("/", authorize(ac.EvalPermission(dashboards.ActionSnapshotsRead)), routing.Wrap(hs.ListSnapshotSchedules))
+				scheduleRoute.Post("/", authorize(ac.EvalPermission(dashboards.ActionSnapshotsCreate)), routing.Wrap(hs.CreateSnapshotSchedule))
+				scheduleRoute.Get("/:uid", authorize(ac.EvalPermission(dashboards.ActionSnapshotsRead)), routing.Wrap(hs.GetSnapshotSchedule))
+				scheduleRoute.Put("/:uid", authorize(ac.EvalPermission(dashboards.ActionSnapshotsCreate)), routing.Wrap(hs.UpdateSnapshotSchedule))
+				scheduleRoute.Delete("/:uid", authorize(ac.EvalPermission(dashboards.ActionSnapshotsDelete)), routing.Wrap(hs.DeleteSnapshotSchedule))
+				scheduleRoute.Post("/:uid/run", authorize(ac.EvalPermission(dashboards.ActionSnapshotsCreate)), routing.Wrap(hs.RunSnapshotSchedule))
+			})
 		})
 
 		// Playlist
@@ -566,6 +605,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/encryption/reencrypt-data-keys", reqGrafanaAdmin, routing.Wrap(hs.AdminReEncryptEncryptionKeys))
 		adminRoute.Post("/encryption/reencrypt-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminReEncryptSecrets))
 		adminRoute.Post("/encryption/rollback-secrets", reqGrafanaAdmin, routing.Wrap(hs.AdminRollbackSecrets))
+		adminRoute.Get("/encryption/data-key-rotation", reqGrafanaAdmin, routing.Wrap(hs.AdminGetDataKeyRotationStatus))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
@@ -589,6 +629,11 @@ func (hs *HTTPServer) registerRoutes() {
 		adminUserRoute.Post("/:id/logout", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersLogout, userIDScope)), routing.Wrap(hs.AdminLogoutUser))
 		adminUserRoute.Get("/:id/auth-tokens", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(hs.AdminGetUserAuthTokens))
 		adminUserRoute.Post("/:id/revoke-auth-token", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, userIDScope)), routing.Wrap(hs.AdminRevokeUserAuthToken))
+
+		if hs.Cfg.WebAuthn.Enabled {
+			adminUserRoute.Get("/:id/webauthn/credentials", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenList, userIDScope)), routing.Wrap(hs.AdminGetUserWebAuthnCredentials))
+			adminUserRoute.Delete("/:id/webauthn/credentials", userUIDResolver, authorizeInOrg(ac.UseGlobalOrg, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, userIDScope)), routing.Wrap(hs.AdminResetUserWebAuthnCredentials))
+		}
 	}, reqSignedIn)
 
 	// rendering
@@ -605,6 +650,7 @@ func (hs *HTTPServer) registerRoutes() {
 
 	r.Post("/api/snapshots/", reqSnapshotPublicModeOrCreate, hs.getCreatedSnapshotHandler())
 	r.Get("/api/snapshots/:key", routing.Wrap(hs.GetDashboardSnapshot))
+	r.Get("/api/snapshots/:key/export", routing.Wrap(hs.ExportDashboardSnapshot))
 	r.Delete("/api/snapshots/:key", authorize(ac.EvalPermission(dashboards.ActionSnapshotsDelete)), routing.Wrap(hs.DeleteDashboardSnapshot))
 
 	// Snapshots delete for public mode or using the deleteKey
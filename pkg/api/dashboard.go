@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/slugify"
@@ -924,6 +925,176 @@ func (hs *HTTPServer) RestoreDashboardVersion(c *contextmodel.ReqContext) respon
 	})
 }
 
+// swagger:route GET /dashboards/uid/{uid}/diff dashboards versions getDashboardVersionsDiffByUID
+//
+// Compare two versions of a dashboard panel by panel, query by query and variable by variable.
+//
+// Responses:
+// 200: dashboardVersionsDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionsDiff(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.GetDashboardVersionsDiff")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	dashUID := web.Params(c.Req)[":uid"]
+	if dashUID == "" {
+		return response.Error(http.StatusBadRequest, "uid is required", nil)
+	}
+	baseVersion, err := strconv.ParseInt(c.Query("base"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "base is invalid", err)
+	}
+	newVersion, err := strconv.ParseInt(c.Query("new"), 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "new is invalid", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.GetOrgID(), dashUID)
+	if rsp != nil {
+		return rsp
+	}
+
+	res, err := hs.dashboardVersionService.Diff(c.Req.Context(), &dashver.DiffQuery{
+		DashboardUID: dash.UID,
+		OrgID:        c.GetOrgID(),
+		BaseVersion:  baseVersion,
+		NewVersion:   newVersion,
+	})
+	if err != nil {
+		return dashboardVersionErrResponse(err, "Failed to compare dashboard versions")
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/blame dashboards versions getDashboardBlameByUID
+//
+// Get the version that last changed each panel, query and variable of a dashboard.
+//
+// Responses:
+// 200: dashboardBlameResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardBlame(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.GetDashboardBlame")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	dashUID := web.Params(c.Req)[":uid"]
+	if dashUID == "" {
+		return response.Error(http.StatusBadRequest, "uid is required", nil)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.GetOrgID(), dashUID)
+	if rsp != nil {
+		return rsp
+	}
+
+	res, err := hs.dashboardVersionService.Blame(c.Req.Context(), &dashver.BlameQuery{
+		DashboardUID: dash.UID,
+		OrgID:        c.GetOrgID(),
+	})
+	if err != nil {
+		return dashboardVersionErrResponse(err, "Failed to get dashboard blame")
+	}
+
+	loginMem := make(map[int64]string)
+	elements := make([]dashver.ElementBlameMeta, 0, len(res.Elements))
+	for _, e := range res.Elements {
+		creator := anonString
+		if e.CreatedBy > 0 {
+			login, found := loginMem[e.CreatedBy]
+			if found {
+				creator = login
+			} else {
+				creator = hs.getIdentityName(c.Req.Context(), c.GetOrgID(), e.CreatedBy)
+				loginMem[e.CreatedBy] = creator
+			}
+		}
+		elements = append(elements, dashver.ElementBlameMeta{
+			Kind:      e.Kind,
+			Key:       e.Key,
+			Title:     e.Title,
+			Version:   e.Version,
+			Created:   e.Created,
+			CreatedBy: creator,
+		})
+	}
+
+	return response.JSON(http.StatusOK, dashver.BlameResponseMeta{
+		Version:  res.Version,
+		Elements: elements,
+	})
+}
+
+// swagger:route POST /dashboards/uid/{uid}/restore/elements dashboards versions restoreDashboardElementsByUID
+//
+// Restore panels and variables of a dashboard version into the current version of the dashboard.
+//
+// Responses:
+// 200: postDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RestoreDashboardElements(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.RestoreDashboardElements")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	var apiCmd dtos.RestoreDashboardElementsCommand
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if len(apiCmd.Panels) == 0 && len(apiCmd.Variables) == 0 {
+		return response.Error(http.StatusBadRequest, "panels or variables are required", nil)
+	}
+
+	dashUID := web.Params(c.Req)[":uid"]
+	if dashUID == "" {
+		return response.Error(http.StatusBadRequest, "uid is required", nil)
+	}
+
+	res, err := hs.dashboardVersionService.RestoreElements(ctx, &dashver.RestoreElementsCommand{
+		Requester:    c.SignedInUser,
+		DashboardUID: dashUID,
+		Version:      apiCmd.Version,
+		PanelIDs:     apiCmd.Panels,
+		Variables:    apiCmd.Variables,
+	})
+	if err != nil {
+		hs.log.Error("error restoring dashboard elements", "error", err)
+		return dashboardVersionErrResponse(err, "Failed to restore dashboard elements")
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"status":    "success",
+		"slug":      res.Slug,
+		"version":   res.Version,
+		"id":        res.ID,
+		"uid":       res.UID,
+		"url":       res.GetURL(),
+		"folderUid": res.FolderUID,
+	})
+}
+
+// dashboardVersionErrResponse maps the errors of the semantic diff to bad requests.
+func dashboardVersionErrResponse(err error, fallbackMessage string) response.Response {
+	if errors.Is(err, dashdiffs.ErrElementNotFound) || errors.Is(err, dashdiffs.ErrUnsupportedSchema) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return dashboardErrResponse(err, fallbackMessage)
+}
+
 // swagger:route GET /dashboards/tags dashboards getDashboardTags
 //
 // Get all dashboards tags of an organisation.
@@ -1018,6 +1189,50 @@ type RestoreDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters restoreDashboardElementsByUID
+type RestoreDashboardElementsByUIDParams struct {
+	// in:body
+	// required:true
+	Body dtos.RestoreDashboardElementsCommand
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getDashboardVersionsDiffByUID
+type GetDashboardVersionsDiffByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// The version to compare from
+	// in:query
+	// required:true
+	Base int64 `json:"base"`
+	// The version to compare to
+	// in:query
+	// required:true
+	New int64 `json:"new"`
+}
+
+// swagger:parameters getDashboardBlameByUID
+type GetDashboardBlameByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response dashboardVersionsDiffResponse
+type DashboardVersionsDiffResponse struct {
+	// in: body
+	Body *dashdiffs.SemanticResult `json:"body"`
+}
+
+// swagger:response dashboardBlameResponse
+type DashboardBlameResponse struct {
+	// in: body
+	Body *dashver.BlameResponseMeta `json:"body"`
+}
+
 // swagger:parameters getDashboardVersionByID
 type GetDashboardVersionByIDParams struct {
 	// in:path
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/slugify"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -149,6 +151,59 @@ func (hs *HTTPServer) GetDashboardSnapshot(c *contextmodel.ReqContext) response.
 	return response.JSON(http.StatusOK, dto).SetHeader("Cache-Control", "public, max-age=3600")
 }
 
+// swagger:route GET /snapshots/{key}/export dashboards snapshots exportDashboardSnapshot
+//
+// Export the data of a snapshot.
+//
+// The json format exports the snapshot dashboard, csv a zip archive with a CSV file per panel data frame and parquet
+// a Parquet file with a row per value.
+//
+// Produces:
+// - application/json
+// - application/zip
+// - application/vnd.apache.parquet
+//
+// Responses:
+// 200: exportDashboardSnapshotResponse
+// 400: badRequestError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) ExportDashboardSnapshot(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		c.JsonApiErr(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+		return nil
+	}
+
+	key := web.Params(c.Req)[":key"]
+	if len(key) == 0 {
+		return response.Error(http.StatusBadRequest, "Empty snapshot key", nil)
+	}
+
+	snapshot, err := hs.dashboardsnapshotsService.GetDashboardSnapshot(c.Req.Context(), &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key})
+	if err != nil {
+		return response.Err(err)
+	}
+	if snapshot.Expires.Before(time.Now()) {
+		return response.Error(http.StatusNotFound, "Dashboard snapshot not found", nil)
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = dashboardsnapshots.ExportFormatJSON
+	}
+
+	var buf bytes.Buffer
+	if err := dashboardsnapshots.Export(&buf, snapshot.Dashboard, format); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to export dashboard snapshot", err)
+	}
+
+	contentType, ext := dashboardsnapshots.ExportContentType(format)
+	filename := slugify.Slugify(snapshot.Name) + "." + ext
+	return response.Respond(http.StatusOK, buf.Bytes()).
+		SetHeader("Content-Type", contentType).
+		SetHeader("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
+
 // swagger:route GET /snapshots-delete/{deleteKey} dashboards snapshots deleteDashboardSnapshotByDeleteKey
 //
 // Delete Snapshot by deleteKey.
@@ -332,6 +387,16 @@ type GetDashboardSnapshotParams struct {
 	Key string `json:"key"`
 }
 
+// swagger:parameters exportDashboardSnapshot
+type ExportDashboardSnapshotParams struct {
+	// in:path
+	Key string `json:"key"`
+	// Export format: json, csv or parquet
+	// in:query
+	// default:json
+	Format string `json:"format"`
+}
+
 // swagger:parameters deleteDashboardSnapshot
 type DeleteDashboardSnapshotParams struct {
 	// in:path
@@ -365,6 +430,12 @@ type SearchDashboardSnapshotsResponse struct {
 	Body []*dashboardsnapshots.DashboardSnapshotDTO `json:"body"`
 }
 
+// swagger:response exportDashboardSnapshotResponse
+type ExportDashboardSnapshotResponse struct {
+	// in:body
+	Body []byte `json:"body"`
+}
+
 // swagger:response getDashboardSnapshotResponse
 type GetDashboardSnapshotResponse DashboardResponse
 
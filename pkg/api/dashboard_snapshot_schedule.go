@@ -0,0 +1,298 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	snapshotschedule "github.com/grafana/grafana/pkg/services/dashboardsnapshots/schedule"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboard/snapshots/schedules snapshots listSnapshotSchedules
+//
+// List snapshot schedules.
+//
+// Responses:
+// 200: listSnapshotSchedulesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) ListSnapshotSchedules(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	schedules, err := hs.snapshotScheduleService.List(c.Req.Context(), &snapshotschedule.ListSchedulesQuery{
+		OrgID:        c.GetOrgID(),
+		DashboardUID: c.Query("dashboardUid"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list snapshot schedules", err)
+	}
+
+	// only the schedules of the dashboards the user can read are listed
+	visible := make([]*snapshotschedule.Schedule, 0, len(schedules))
+	canRead := make(map[string]bool)
+	for _, schedule := range schedules {
+		ok, checked := canRead[schedule.DashboardUID]
+		if !checked {
+			if ok, err = hs.canReadSnapshotScheduleDashboard(c.Req.Context(), c.SignedInUser, schedule.DashboardUID); err != nil {
+				return response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot schedule", err)
+			}
+			canRead[schedule.DashboardUID] = ok
+		}
+		if ok {
+			visible = append(visible, schedule)
+		}
+	}
+	return response.JSON(http.StatusOK, visible)
+}
+
+// swagger:route GET /dashboard/snapshots/schedules/{uid} snapshots getSnapshotSchedule
+//
+// Get a snapshot schedule.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetSnapshotSchedule(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	schedule, err := hs.snapshotScheduleService.Get(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+	canRead, err := hs.canReadSnapshotScheduleDashboard(c.Req.Context(), c.SignedInUser, schedule.DashboardUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot schedule", err)
+	}
+	if !canRead {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard of the snapshot schedule", nil)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route POST /dashboard/snapshots/schedules snapshots createSnapshotSchedule
+//
+// Create a snapshot schedule.
+//
+// Takes snapshots of a dashboard on a cron schedule by running the queries of its panels on the server, as the user
+// who created the schedule. Requires permission to edit the dashboard.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CreateSnapshotSchedule(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := snapshotschedule.CreateScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if rsp := hs.checkSnapshotScheduleAccess(c.Req.Context(), c.SignedInUser, cmd.DashboardUID); rsp != nil {
+		return rsp
+	}
+
+	cmd.OrgID = c.GetOrgID()
+	cmd.UserID, _ = identity.UserIdentifier(c.GetID())
+	schedule, err := hs.snapshotScheduleService.Create(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create snapshot schedule", err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route PUT /dashboard/snapshots/schedules/{uid} snapshots updateSnapshotSchedule
+//
+// Update a snapshot schedule.
+//
+// Requires permission to edit the dashboard of the schedule. The schedule then runs as the user who updated it.
+//
+// Responses:
+// 200: snapshotScheduleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateSnapshotSchedule(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := snapshotschedule.UpdateScheduleCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.UserID, _ = identity.UserIdentifier(c.GetID())
+
+	existing, err := hs.snapshotScheduleService.Get(c.Req.Context(), cmd.OrgID, cmd.UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+	for _, dashboardUID := range []string{existing.DashboardUID, cmd.DashboardUID} {
+		if rsp := hs.checkSnapshotScheduleAccess(c.Req.Context(), c.SignedInUser, dashboardUID); rsp != nil {
+			return rsp
+		}
+	}
+
+	schedule, err := hs.snapshotScheduleService.Update(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update snapshot schedule", err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+// swagger:route DELETE /dashboard/snapshots/schedules/{uid} snapshots deleteSnapshotSchedule
+//
+// Delete a snapshot schedule.
+//
+// The snapshots taken by the schedule are kept until they expire or are deleted.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteSnapshotSchedule(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	schedule, err := hs.snapshotScheduleService.Get(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+	if rsp := hs.checkSnapshotScheduleAccess(c.Req.Context(), c.SignedInUser, schedule.DashboardUID); rsp != nil {
+		return rsp
+	}
+
+	if err := hs.snapshotScheduleService.Delete(c.Req.Context(), c.GetOrgID(), uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete snapshot schedule", err)
+	}
+	return response.Success("Snapshot schedule deleted")
+}
+
+// swagger:route POST /dashboard/snapshots/schedules/{uid}/run snapshots runSnapshotSchedule
+//
+// Take a snapshot of a schedule now.
+//
+// The next run of the schedule is unchanged.
+//
+// Responses:
+// 200: runSnapshotScheduleResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RunSnapshotSchedule(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	schedule, err := hs.snapshotScheduleService.Get(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get snapshot schedule", err)
+	}
+	if rsp := hs.checkSnapshotScheduleAccess(c.Req.Context(), c.SignedInUser, schedule.DashboardUID); rsp != nil {
+		return rsp
+	}
+
+	result, err := hs.snapshotScheduleService.RunNow(c.Req.Context(), c.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to take snapshot", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// checkSnapshotScheduleAccess returns an error response unless the user can edit the dashboard. Schedules keep
+// taking snapshots of the dashboard while nobody looks at it, so managing them requires more than reading it.
+func (hs *HTTPServer) checkSnapshotScheduleAccess(ctx context.Context, user identity.Requester, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return nil
+	}
+	evaluator := ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+	canEdit, err := hs.AccessControl.Evaluate(ctx, user, evaluator)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot schedule", err)
+	}
+	if !canEdit {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard of the snapshot schedule", nil)
+	}
+	return nil
+}
+
+func (hs *HTTPServer) canReadSnapshotScheduleDashboard(ctx context.Context, user identity.Requester, dashboardUID string) (bool, error) {
+	evaluator := ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+	return hs.AccessControl.Evaluate(ctx, user, evaluator)
+}
+
+// swagger:parameters getSnapshotSchedule deleteSnapshotSchedule runSnapshotSchedule
+type SnapshotScheduleUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters listSnapshotSchedules
+type ListSnapshotSchedulesParams struct {
+	// Only return the schedules of a dashboard
+	// in:query
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:parameters createSnapshotSchedule
+type CreateSnapshotScheduleParams struct {
+	// in:body
+	// required:true
+	Body snapshotschedule.ScheduleSpec `json:"body"`
+}
+
+// swagger:parameters updateSnapshotSchedule
+type UpdateSnapshotScheduleParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body snapshotschedule.ScheduleSpec `json:"body"`
+}
+
+// swagger:response listSnapshotSchedulesResponse
+type ListSnapshotSchedulesResponse struct {
+	// in:body
+	Body []*snapshotschedule.Schedule `json:"body"`
+}
+
+// swagger:response snapshotScheduleResponse
+type SnapshotScheduleResponse struct {
+	// in:body
+	Body *snapshotschedule.Schedule `json:"body"`
+}
+
+// swagger:response runSnapshotScheduleResponse
+type RunSnapshotScheduleResponse struct {
+	// in:body
+	Body *snapshotschedule.RunResult `json:"body"`
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
@@ -1098,6 +1099,80 @@ func callPostDashboard(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
 
+func TestRestoreDashboardElementsAPIEndpoint(t *testing.T) {
+	restoredDash := dashboards.NewDashboard("Restored Dashboard")
+	restoredDash.ID = 2
+	restoredDash.UID = "uid"
+	restoredDash.Version = 3
+
+	t.Run("Should restore the panels and variables of a version", func(t *testing.T) {
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedRestoreElementsResult = restoredDash
+		cmd := dtos.RestoreDashboardElementsCommand{Version: 1, Panels: []int64{4}, Variables: []string{"env"}}
+
+		restoreDashboardElementsScenario(t, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+			callRestoreDashboardVersion(sc)
+			assert.Equal(t, http.StatusOK, sc.resp.Code)
+			require.NotNil(t, fakeDashboardVersionService.LastRestoreElementsCommand)
+			assert.Equal(t, "uid", fakeDashboardVersionService.LastRestoreElementsCommand.DashboardUID)
+			assert.Equal(t, []int64{4}, fakeDashboardVersionService.LastRestoreElementsCommand.PanelIDs)
+			assert.Equal(t, []string{"env"}, fakeDashboardVersionService.LastRestoreElementsCommand.Variables)
+		})
+	})
+
+	t.Run("Should require panels or variables", func(t *testing.T) {
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		cmd := dtos.RestoreDashboardElementsCommand{Version: 1}
+
+		restoreDashboardElementsScenario(t, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+			callRestoreDashboardVersion(sc)
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+			assert.Nil(t, fakeDashboardVersionService.LastRestoreElementsCommand)
+		})
+	})
+
+	t.Run("Should return bad request when an element is not in the version", func(t *testing.T) {
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedError = fmt.Errorf("%w: panel 4", dashdiffs.ErrElementNotFound)
+		cmd := dtos.RestoreDashboardElementsCommand{Version: 1, Panels: []int64{4}}
+
+		restoreDashboardElementsScenario(t, fakeDashboardVersionService, cmd, func(sc *scenarioContext) {
+			callRestoreDashboardVersion(sc)
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		})
+	})
+}
+
+func restoreDashboardElementsScenario(t *testing.T, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService,
+	cmd dtos.RestoreDashboardElementsCommand, fn scenarioFunc,
+) {
+	hs := HTTPServer{
+		Cfg:                     setting.NewCfg(),
+		dashboardVersionService: fakeDashboardVersionService,
+		tracer:                  tracing.InitializeTracerForTest(),
+		log:                     log.New("test"),
+	}
+
+	sc := setupScenarioContext(t, "/api/dashboards/uid/uid/restore/elements")
+	sc.dashboardVersionService = fakeDashboardVersionService
+	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+		c.Req.Body = mockRequestBody(cmd)
+		c.Req.Header.Add("Content-Type", "application/json")
+		sc.context = c
+		c.SignedInUser = &user.SignedInUser{
+			OrgID:  testOrgID,
+			UserID: testUserID,
+		}
+		c.OrgRole = org.RoleAdmin
+
+		return hs.RestoreDashboardElements(c)
+	})
+
+	sc.m.Post("/api/dashboards/uid/:uid/restore/elements", sc.defaultHandler)
+
+	fn(sc)
+}
+
 func callRestoreDashboardVersion(sc *scenarioContext) {
 	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
 }
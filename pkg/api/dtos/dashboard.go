@@ -62,3 +62,11 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int64 `json:"version" binding:"Required"`
 }
+
+type RestoreDashboardElementsCommand struct {
+	Version int64 `json:"version" binding:"Required"`
+	// IDs of the panels to restore
+	Panels []int64 `json:"panels"`
+	// Names of the variables to restore
+	Variables []string `json:"variables"`
+}
@@ -47,8 +47,10 @@ import (
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
+	secretcontracts "github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/ingestion"
 	"github.com/grafana/grafana/pkg/services/anonymous"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	grafanaapiserver "github.com/grafana/grafana/pkg/services/apiserver"
@@ -60,6 +62,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	snapshotschedule "github.com/grafana/grafana/pkg/services/dashboardsnapshots/schedule"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -113,6 +116,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/updatemanager"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/validations"
+	"github.com/grafana/grafana/pkg/services/webauthn"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -188,6 +192,8 @@ type HTTPServer struct {
 	folderService                folder.Service
 	dsGuardian                   guardian.DatasourceGuardianProvider
 	dashboardsnapshotsService    dashboardsnapshots.Service
+	snapshotScheduleService      *snapshotschedule.Service
+	dataKeyRotationService       secretcontracts.DataKeyRotationService
 	PluginSettings               pluginSettings.Service
 	AvatarCacheServer            *avatar.AvatarCacheServer
 	preferenceService            pref.Service
@@ -206,11 +212,13 @@ type HTTPServer struct {
 	userService                     user.Service
 	tempUserService                 tempUser.Service
 	loginAttemptService             loginAttempt.Service
+	webAuthnService                 webauthn.Service
 	orgService                      org.Service
 	orgDeletionService              org.DeletionService
 	TeamService                     team.Service
 	accesscontrolService            accesscontrol.Service
 	annotationsRepo                 annotations.Repository
+	annotationIngestionService      ingestion.Service
 	tagService                      tag.Service
 	oauthTokenService               oauthtoken.OAuthTokenService
 	statsService                    stats.Service
@@ -277,6 +285,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	statsService stats.Service, authnService authn.Service, pluginsCDNService *pluginscdn.Service, promGatherer prometheus.Gatherer,
 	starApi *starApi.API, promRegister prometheus.Registerer, clientConfigProvider grafanaapiserver.DirectRestConfigProvider, anonService anonymous.Service,
 	userVerifier user.Verifier, pluginPreinstall pluginchecker.Preinstall, publicDashboardsService publicdashboards.Service,
+	webAuthnService webauthn.Service, annotationIngestionService ingestion.Service, snapshotScheduleService *snapshotschedule.Service,
+	dataKeyRotationService secretcontracts.DataKeyRotationService,
 ) (*HTTPServer, error) {
 	web.Env = cfg.Env
 	m := web.New()
@@ -345,6 +355,8 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		folderService:                folderService,
 		dsGuardian:                   dsGuardian,
 		dashboardsnapshotsService:    dashboardsnapshotsService,
+		snapshotScheduleService:      snapshotScheduleService,
+		dataKeyRotationService:       dataKeyRotationService,
 		PluginSettings:               pluginSettings,
 		AvatarCacheServer:            avatarCacheServer,
 		preferenceService:            preferenceService,
@@ -360,12 +372,14 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		userService:                  userService,
 		tempUserService:              tempUserService,
 		loginAttemptService:          loginAttemptService,
+		webAuthnService:              webAuthnService,
 		orgService:                   orgService,
 		orgDeletionService:           orgDeletionService,
 		TeamService:                  teamService,
 		navTreeService:               navTreeService,
 		accesscontrolService:         accesscontrolService,
 		annotationsRepo:              annotationRepo,
+		annotationIngestionService:   annotationIngestionService,
 		tagService:                   tagService,
 		oauthTokenService:            oauthTokenService,
 		statsService:                 statsService,
@@ -45,6 +45,9 @@ func (hs *HTTPServer) OAuthLogin(reqCtx *contextmodel.ReqContext) {
 		if pkce := redirect.Extra[authn.KeyOAuthPKCE]; pkce != "" {
 			cookies.WriteCookie(reqCtx.Resp, OauthPKCECookieName, pkce, hs.Cfg.OAuthCookieMaxAge, hs.CookieOptionsFromCfg)
 		}
+		if binding := redirect.Extra[authn.KeySAMLRelayState]; binding != "" {
+			cookies.WriteCookie(reqCtx.Resp, SAMLRelayStateCookieName, binding, hs.Cfg.OAuthCookieMaxAge, hs.samlRelayStateCookieOptions)
+		}
 
 		reqCtx.Redirect(redirect.URL)
 		return
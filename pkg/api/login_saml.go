@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+// SAMLRelayStateCookieName is the cookie binding the relay state of a SAML login to the browser which started it
+const SAMLRelayStateCookieName = "saml_relay_state"
+
+// SAMLACS is the assertion consumer service of the SAML client, where the identity provider posts its responses.
+// Logins are started through /login/saml, like OAuth logins.
+func (hs *HTTPServer) SAMLACS(reqCtx *contextmodel.ReqContext) {
+	identity, err := hs.authnService.Login(reqCtx.Req.Context(), authn.ClientSAML, &authn.Request{HTTPRequest: reqCtx.Req})
+	// NOTE: always delete the cookie, the relay state can only be used once
+	cookies.DeleteCookie(reqCtx.Resp, SAMLRelayStateCookieName, hs.samlRelayStateCookieOptions)
+	if err != nil {
+		reqCtx.Redirect(hs.redirectURLWithErrorCookie(reqCtx, err))
+		return
+	}
+
+	authn.HandleLoginRedirect(reqCtx.Req, reqCtx.Resp, hs.Cfg, identity, hs.ValidateRedirectTo, hs.Features)
+}
+
+// SAMLMetadata returns the service provider metadata to register Grafana with the identity provider.
+func (hs *HTTPServer) SAMLMetadata(c *contextmodel.ReqContext) response.Response {
+	metadata, err := hs.authnService.ClientMetadata(c.Req.Context(), authn.ClientSAML)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get SAML metadata", err)
+	}
+	return response.Respond(http.StatusOK, metadata).SetHeader("Content-Type", "application/samlmetadata+xml")
+}
+
+// samlRelayStateCookieOptions returns the options of the relay state cookie. The identity provider posts the response
+// cross-site, so the cookie is only sent with SameSite=None, which browsers require to be secure.
+// It is only sent to the assertion consumer service.
+func (hs *HTTPServer) samlRelayStateCookieOptions() cookies.CookieOptions {
+	options := hs.CookieOptionsFromCfg()
+	options.Path = hs.Cfg.AppSubURL + "/saml/acs"
+	options.Secure = true
+	options.SameSiteDisabled = false
+	options.SameSiteMode = http.SameSiteNoneMode
+	return options
+}
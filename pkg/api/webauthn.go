@@ -0,0 +1,272 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route POST /login/webauthn/begin webauthn beginWebAuthnLogin
+//
+// Starts a passkey login.
+//
+// Returns the challenge to pass to navigator.credentials.get(). Any passkey the authenticator can discover is accepted.
+//
+// Responses:
+// 200: webAuthnLoginChallengeResponse
+// 500: internalServerError
+func (hs *HTTPServer) BeginWebAuthnLogin(c *contextmodel.ReqContext) response.Response {
+	challenge, err := hs.webAuthnService.BeginLogin(c.Req.Context(), webauthn.BeginLoginCommand{})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start passkey login", err)
+	}
+	return response.JSON(http.StatusOK, challenge)
+}
+
+// swagger:route POST /login/webauthn webauthn loginWebAuthn
+//
+// Completes a passkey login.
+//
+// Completes either a passkey login, or the passkey confirmation of a password login when the latter failed with `webauthn.mfa-required`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) LoginWebAuthn(c *contextmodel.ReqContext) response.Response {
+	identity, err := hs.authnService.Login(c.Req.Context(), authn.ClientWebAuthn, &authn.Request{HTTPRequest: c.Req})
+	if err != nil {
+		tokenErr := &auth.CreateTokenErr{}
+		if errors.As(err, &tokenErr) {
+			return response.Error(tokenErr.StatusCode, tokenErr.ExternalErr, tokenErr.InternalErr)
+		}
+		return response.Err(err)
+	}
+	return authn.HandleLoginResponse(c.Req, c.Resp, hs.Cfg, identity, hs.ValidateRedirectTo, hs.Features)
+}
+
+// swagger:route GET /user/webauthn/credentials signed_in_user getUserWebAuthnCredentials
+//
+// Passkeys of the actual User.
+//
+// Responses:
+// 200: getWebAuthnCredentialsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetUserWebAuthnCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := hs.webAuthnUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	return hs.getWebAuthnCredentialsInternal(c, userID)
+}
+
+// swagger:route POST /user/webauthn/credentials/begin signed_in_user beginWebAuthnRegistration
+//
+// Starts the registration of a passkey for the actual User.
+//
+// Returns the challenge to pass to navigator.credentials.create().
+//
+// Responses:
+// 200: webAuthnRegistrationChallengeResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) BeginWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	usr, errResp := hs.webAuthnUser(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	challenge, err := hs.webAuthnService.BeginRegistration(c.Req.Context(), usr)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to start passkey registration", err)
+	}
+	return response.JSON(http.StatusOK, challenge)
+}
+
+// swagger:route POST /user/webauthn/credentials signed_in_user finishWebAuthnRegistration
+//
+// Completes the registration of a passkey for the actual User.
+//
+// Responses:
+// 200: webAuthnCredentialResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (hs *HTTPServer) FinishWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	cmd := webauthn.FinishRegistrationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	usr, errResp := hs.webAuthnUser(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	cred, err := hs.webAuthnService.FinishRegistration(c.Req.Context(), usr, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register passkey", err)
+	}
+	return response.JSON(http.StatusOK, cred)
+}
+
+// swagger:route DELETE /user/webauthn/credentials/{uid} signed_in_user deleteUserWebAuthnCredential
+//
+// Deletes a passkey of the actual User.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteUserWebAuthnCredential(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := hs.webAuthnUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := hs.webAuthnService.DeleteCredential(c.Req.Context(), userID, web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete passkey", err)
+	}
+	return response.Success("Passkey deleted")
+}
+
+// swagger:route GET /admin/users/{user_id}/webauthn/credentials admin_users adminGetUserWebAuthnCredentials
+//
+// Passkeys of a user.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:read` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getWebAuthnCredentialsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetUserWebAuthnCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	return hs.getWebAuthnCredentialsInternal(c, userID)
+}
+
+// swagger:route DELETE /admin/users/{user_id}/webauthn/credentials admin_users adminResetUserWebAuthnCredentials
+//
+// Resets the passkeys of a user.
+//
+// Deletes all passkeys of the user, so that a user who lost their authenticator can log in with their password and register a new one.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users.authtoken:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResetUserWebAuthnCredentials(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.webAuthnService.DeleteUserCredentials(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset passkeys", err)
+	}
+
+	hs.log.FromContext(c.Req.Context()).Info("Passkeys reset by admin", "userID", userID, "adminID", c.GetID())
+
+	return response.Success("Passkeys reset")
+}
+
+func (hs *HTTPServer) getWebAuthnCredentialsInternal(c *contextmodel.ReqContext, userID int64) response.Response {
+	creds, err := hs.webAuthnService.ListCredentials(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list passkeys", err)
+	}
+	return response.JSON(http.StatusOK, creds)
+}
+
+func (hs *HTTPServer) webAuthnUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	if !c.IsIdentityType(claims.TypeUser) {
+		return 0, response.Error(http.StatusForbidden, "entity not allowed to manage passkeys", nil)
+	}
+
+	userID, err := c.GetInternalID()
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+	return userID, nil
+}
+
+func (hs *HTTPServer) webAuthnUser(c *contextmodel.ReqContext) (*user.User, response.Response) {
+	userID, errResp := hs.webAuthnUserID(c)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	usr, err := hs.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "Failed to get user", err)
+	}
+	return usr, nil
+}
+
+// swagger:response webAuthnLoginChallengeResponse
+type WebAuthnLoginChallengeResponse struct {
+	// in:body
+	Body webauthn.LoginChallenge `json:"body"`
+}
+
+// swagger:response webAuthnRegistrationChallengeResponse
+type WebAuthnRegistrationChallengeResponse struct {
+	// in:body
+	Body webauthn.RegistrationChallenge `json:"body"`
+}
+
+// swagger:response webAuthnCredentialResponse
+type WebAuthnCredentialResponse struct {
+	// in:body
+	Body webauthn.Credential `json:"body"`
+}
+
+// swagger:response getWebAuthnCredentialsResponse
+type GetWebAuthnCredentialsResponse struct {
+	// in:body
+	Body []*webauthn.Credential `json:"body"`
+}
+
+// swagger:parameters deleteUserWebAuthnCredential
+type DeleteUserWebAuthnCredentialParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters adminGetUserWebAuthnCredentials adminResetUserWebAuthnCredentials
+type AdminUserWebAuthnCredentialsParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
@@ -0,0 +1,146 @@
+package auditing
+
+import (
+	"encoding/json"
+	"strings"
+
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit"
+)
+
+// Backend is an audit.Backend sending the events of the apiserver to audit loggers (sinks).
+type Backend struct {
+	loggers []Logger
+	onError func(logger Logger, err error)
+}
+
+var _ audit.Backend = &Backend{}
+
+// NewBackend creates a Backend sending every event to all the loggers.
+// onError is called when a logger fails, it can be nil.
+func NewBackend(onError func(logger Logger, err error), loggers ...Logger) *Backend {
+	return &Backend{
+		loggers: loggers,
+		onError: onError,
+	}
+}
+
+func (b *Backend) ProcessEvents(events ...*auditinternal.Event) bool {
+	for _, ev := range events {
+		if ev == nil || ev.Level == auditinternal.LevelNone {
+			continue
+		}
+		// The policies only log the response complete stage, except for panics
+		if ev.Stage != auditinternal.StageResponseComplete && ev.Stage != auditinternal.StagePanic {
+			continue
+		}
+
+		entry := EventFromAudit(ev)
+		for _, l := range b.loggers {
+			if err := l.Log(entry); err != nil && b.onError != nil {
+				b.onError(l, err)
+			}
+		}
+	}
+	return true
+}
+
+// Run does nothing, the loggers handle their own background work.
+func (b *Backend) Run(<-chan struct{}) error { return nil }
+
+// Shutdown flushes and closes the loggers.
+func (b *Backend) Shutdown() {
+	for _, l := range b.loggers {
+		if err := l.Close(); err != nil && b.onError != nil {
+			b.onError(l, err)
+		}
+	}
+}
+
+func (b *Backend) String() string {
+	types := make([]string, 0, len(b.loggers))
+	for _, l := range b.loggers {
+		types = append(types, l.Type())
+	}
+	return "grafana-audit<" + strings.Join(types, ",") + ">"
+}
+
+// EventFromAudit converts an apiserver audit event.
+// The request body is only kept (with its secure fields redacted) when the policy logs it, and never for secret resources.
+func EventFromAudit(ev *auditinternal.Event) Event {
+	e := Event{
+		ObservedAt: ev.StageTimestamp.Time,
+		SubjectUID: ev.User.UID,
+		Verb:       ev.Verb,
+		Outcome:    outcome(ev),
+		Extra: map[string]string{
+			"auditID": string(ev.AuditID),
+			"stage":   string(ev.Stage),
+		},
+	}
+	if e.SubjectUID == "" {
+		e.SubjectUID = ev.User.Username
+	}
+	if ev.UserAgent != "" {
+		e.Extra["userAgent"] = ev.UserAgent
+	}
+	if len(ev.SourceIPs) > 0 {
+		e.Extra["sourceIPs"] = strings.Join(ev.SourceIPs, ",")
+	}
+
+	if ref := ev.ObjectRef; ref != nil {
+		e.Namespace = ref.Namespace
+		e.Object = ref.Name
+		e.APIGroup = ref.APIGroup
+		e.APIVersion = ref.APIVersion
+		e.Extra["resource"] = ref.Resource
+		if ref.Subresource != "" {
+			e.Extra["subresource"] = ref.Subresource
+		}
+	}
+
+	// The name and kind of created objects are only known from the response
+	if ev.ResponseObject != nil {
+		var obj struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(ev.ResponseObject.Raw, &obj); err == nil {
+			e.Kind = obj.Kind
+			if e.Object == "" {
+				e.Object = obj.Metadata.Name
+			}
+		}
+	}
+
+	// The bodies of the secret resources are never logged, even redacted
+	if ev.RequestObject != nil && (ev.ObjectRef == nil || !secretGroups[ev.ObjectRef.APIGroup]) {
+		e.RequestObject = RedactSecureFields(ev.RequestObject.Raw)
+	}
+
+	return e
+}
+
+func outcome(ev *auditinternal.Event) EventOutcome {
+	if ev.Stage == auditinternal.StagePanic {
+		return EventOutcomeFailureGeneric
+	}
+	if ev.ResponseStatus == nil {
+		return EventOutcomeUnknown
+	}
+	code := ev.ResponseStatus.Code
+	switch {
+	case code == 0:
+		return EventOutcomeUnknown
+	case code < 400:
+		return EventOutcomeSuccess
+	case code == 401 || code == 403:
+		return EventOutcomeFailureUnauthorized
+	case code == 404:
+		return EventOutcomeFailureNotFound
+	default:
+		return EventOutcomeFailureGeneric
+	}
+}
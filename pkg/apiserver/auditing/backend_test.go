@@ -0,0 +1,130 @@
+package auditing_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+)
+
+type fakeLogger struct {
+	mu      sync.Mutex
+	entries []auditing.Sinkable
+	err     error
+	closed  bool
+}
+
+func (f *fakeLogger) Type() string { return "fake" }
+
+func (f *fakeLogger) Log(entry auditing.Sinkable) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entry)
+	return f.err
+}
+
+func (f *fakeLogger) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newAuditEvent(stage auditinternal.Stage, code int32) *auditinternal.Event {
+	ev := &auditinternal.Event{
+		Level:          auditinternal.LevelRequestResponse,
+		AuditID:        "audit-id",
+		Stage:          stage,
+		Verb:           "create",
+		SourceIPs:      []string{"10.0.0.1"},
+		StageTimestamp: metav1.NewMicroTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		ObjectRef: &auditinternal.ObjectReference{
+			Resource:   "datasources",
+			Namespace:  "default",
+			APIGroup:   "prometheus.datasource.grafana.app",
+			APIVersion: "v0alpha1",
+		},
+		ResponseStatus: &metav1.Status{Code: code},
+		RequestObject:  &runtime.Unknown{Raw: []byte(`{"metadata":{"name":"ds"},"secure":{"password":{"create":"secret"}}}`)},
+		ResponseObject: &runtime.Unknown{Raw: []byte(`{"kind":"DataSource","metadata":{"name":"ds"}}`)},
+	}
+	ev.User.Username = "admin"
+	ev.User.UID = "user:abc"
+	return ev
+}
+
+func TestEventFromAudit(t *testing.T) {
+	t.Parallel()
+
+	event := auditing.EventFromAudit(newAuditEvent(auditinternal.StageResponseComplete, 201))
+
+	require.Equal(t, "default", event.Namespace)
+	require.Equal(t, "user:abc", event.SubjectUID)
+	require.Equal(t, "create", event.Verb)
+	require.Equal(t, "ds", event.Object)
+	require.Equal(t, "DataSource", event.Kind)
+	require.Equal(t, "prometheus.datasource.grafana.app", event.APIGroup)
+	require.Equal(t, auditing.EventOutcomeSuccess, event.Outcome)
+	require.Equal(t, "datasources", event.Extra["resource"])
+	require.Equal(t, "10.0.0.1", event.Extra["sourceIPs"])
+	require.NotContains(t, string(event.RequestObject), "secret")
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(event.RequestObject, &body))
+	require.Equal(t, map[string]any{"password": auditing.RedactedValue}, body["secure"])
+}
+
+func TestEventFromAudit_SecretResources(t *testing.T) {
+	t.Parallel()
+
+	ev := newAuditEvent(auditinternal.StageResponseComplete, 201)
+	ev.ObjectRef.APIGroup = "secret.grafana.app"
+	ev.ObjectRef.Resource = "securevalues"
+	ev.RequestObject = &runtime.Unknown{Raw: []byte(`{"metadata":{"name":"sv"},"spec":{"value":"secret"}}`)}
+
+	require.Nil(t, auditing.EventFromAudit(ev).RequestObject)
+}
+
+func TestEventFromAudit_Outcome(t *testing.T) {
+	t.Parallel()
+
+	for code, expected := range map[int32]auditing.EventOutcome{
+		0:   auditing.EventOutcomeUnknown,
+		200: auditing.EventOutcomeSuccess,
+		403: auditing.EventOutcomeFailureUnauthorized,
+		404: auditing.EventOutcomeFailureNotFound,
+		500: auditing.EventOutcomeFailureGeneric,
+	} {
+		require.Equal(t, expected, auditing.EventFromAudit(newAuditEvent(auditinternal.StageResponseComplete, code)).Outcome, code)
+	}
+	require.Equal(t, auditing.EventOutcomeFailureGeneric, auditing.EventFromAudit(newAuditEvent(auditinternal.StagePanic, 0)).Outcome)
+}
+
+func TestBackend(t *testing.T) {
+	t.Parallel()
+
+	logger := &fakeLogger{}
+	failing := &fakeLogger{err: errors.New("boom")}
+
+	var errs []error
+	backend := auditing.NewBackend(func(_ auditing.Logger, err error) { errs = append(errs, err) }, logger, failing)
+	require.Equal(t, "grafana-audit<fake,fake>", backend.String())
+
+	require.True(t, backend.ProcessEvents(
+		newAuditEvent(auditinternal.StageRequestReceived, 0),
+		newAuditEvent(auditinternal.StageResponseComplete, 201),
+		nil,
+	))
+	require.Len(t, logger.entries, 1)
+	require.Len(t, failing.entries, 1)
+	require.Len(t, errs, 1)
+
+	backend.Shutdown()
+	require.True(t, logger.closed)
+	require.True(t, failing.closed)
+}
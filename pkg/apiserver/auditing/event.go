@@ -31,6 +31,9 @@ type Event struct {
 
 	// Extra fields to add more context to the event.
 	Extra map[string]string `json:"extra,omitempty"`
+
+	// The request body, with its secure fields redacted. Only set when the audit policy logs it.
+	RequestObject json.RawMessage `json:"requestObject,omitempty"`
 }
 
 func (e Event) Time() time.Time {
@@ -72,6 +75,10 @@ func (e Event) KVPairs() []any {
 		args = append(args, extraArgs...)
 	}
 
+	if len(e.RequestObject) > 0 {
+		args = append(args, "requestObject", string(e.RequestObject))
+	}
+
 	return args
 }
 
@@ -0,0 +1,145 @@
+package auditing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultFileMaxSize    = 100 * 1024 * 1024 // 100MB
+	defaultFileMaxBackups = 5
+)
+
+// FileLoggerOptions configures the rotating JSON file logger
+type FileLoggerOptions struct {
+	// Path of the current log file, rotated files get a numbered suffix (audit.log.1 is the most recent)
+	Path string
+	// Rotate the file once it reaches this size, in bytes (default 100MB)
+	MaxSize int64
+	// How many rotated files are kept (default 5)
+	MaxBackups int
+}
+
+// FileLogger writes one JSON entry per line, rotating the file by size
+type FileLogger struct {
+	opts FileLoggerOptions
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+var _ Logger = &FileLogger{}
+
+func NewFileLogger(opts FileLoggerOptions) (*FileLogger, error) {
+	if opts.Path == "" {
+		return nil, errors.New("missing audit log file path")
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultFileMaxSize
+	}
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = defaultFileMaxBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+
+	l := &FileLogger{opts: opts}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *FileLogger) Type() string { return "file" }
+
+func (l *FileLogger) Log(entry Sinkable) error {
+	data, err := entry.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return os.ErrClosed
+	}
+
+	var rotateErr error
+	if l.file != nil && l.size > 0 && l.size+int64(len(data)) > l.opts.MaxSize {
+		rotateErr = l.rotate()
+	}
+	// The file is reopened on the next entry when it could not be after a rotation
+	if l.file == nil {
+		if err := l.open(); err != nil {
+			return errors.Join(rotateErr, err)
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	return errors.Join(rotateErr, err)
+}
+
+func (l *FileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *FileLogger) open() error {
+	f, err := os.OpenFile(l.opts.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts the rotated files (dropping the oldest one) and starts a new file.
+// When the files can not be shifted, the current file is reopened: the entries are still written, and the rotation
+// is tried again with the next entry.
+func (l *FileLogger) rotate() error {
+	err := l.file.Close()
+	l.file = nil
+	if err == nil {
+		err = l.shift()
+	}
+	if openErr := l.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	return err
+}
+
+func (l *FileLogger) shift() error {
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", l.opts.Path, i) }
+	if err := os.Remove(backup(l.opts.MaxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for i := l.opts.MaxBackups - 1; i > 0; i-- {
+		if err := os.Rename(backup(i), backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(l.opts.Path, backup(1))
+}
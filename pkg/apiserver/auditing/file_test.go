@@ -0,0 +1,101 @@
+package auditing_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/stretchr/testify/require"
+)
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestFileLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("requires a path", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := auditing.NewFileLogger(auditing.FileLoggerOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("writes json lines and rotates", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "audit", "audit.log")
+		entry := auditing.Event{ObservedAt: time.Now(), Verb: "create", Outcome: auditing.EventOutcomeSuccess}
+		data, err := entry.MarshalJSON()
+		require.NoError(t, err)
+
+		// two entries per file
+		logger, err := auditing.NewFileLogger(auditing.FileLoggerOptions{
+			Path:       path,
+			MaxSize:    int64(2 * (len(data) + 1)),
+			MaxBackups: 2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "file", logger.Type())
+
+		for range 7 {
+			require.NoError(t, logger.Log(entry))
+		}
+		require.NoError(t, logger.Close())
+		require.Error(t, logger.Log(entry))
+
+		require.Equal(t, 1, countLines(t, path))
+		require.Equal(t, 2, countLines(t, path+".1"))
+		require.Equal(t, 2, countLines(t, path+".2"))
+		require.NoFileExists(t, path+".3")
+	})
+
+	t.Run("keeps writing to the current file when the rotation fails", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "audit.log")
+		entry := auditing.Event{ObservedAt: time.Now(), Verb: "create", Outcome: auditing.EventOutcomeSuccess}
+		data, err := entry.MarshalJSON()
+		require.NoError(t, err)
+
+		// The oldest backup can not be removed while it is a directory that is not empty
+		require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o750))
+
+		// one entry per file
+		logger, err := auditing.NewFileLogger(auditing.FileLoggerOptions{
+			Path:       path,
+			MaxSize:    int64(len(data) + 1),
+			MaxBackups: 1,
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, logger.Log(entry))
+		require.Error(t, logger.Log(entry), "the rotation error is reported")
+		require.Equal(t, 2, countLines(t, path), "the entry is written despite the rotation error")
+
+		require.NoError(t, os.RemoveAll(path+".1"))
+		require.NoError(t, logger.Log(entry))
+		require.NoError(t, logger.Close())
+
+		require.Equal(t, 1, countLines(t, path))
+		require.Equal(t, 2, countLines(t, path+".1"))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/apiserver/auditing"
 	"github.com/stretchr/testify/require"
 	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 )
@@ -87,3 +88,91 @@ func TestDefaultGrafanaPolicyRuleEvaluator(t *testing.T) {
 		require.Equal(t, auditinternal.LevelMetadata, config.Level)
 	})
 }
+
+type staticPolicyRuleEvaluator struct {
+	level auditinternal.Level
+}
+
+func (e staticPolicyRuleEvaluator) EvaluatePolicyRule(authorizer.Attributes) audit.RequestAuditConfig {
+	return audit.RequestAuditConfig{Level: e.level}
+}
+
+func TestPolicyRuleProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := auditing.NewPolicyRuleProvider(auditing.PolicyConfig{
+		Groups:           []string{"dashboard.grafana.app", "*.datasource.grafana.app"},
+		LogRequestObject: true,
+	})
+	evaluator := provider.PolicyRuleProvider(auditing.PolicyRuleEvaluators{
+		{Group: "custom.grafana.app", Version: "v1"}: staticPolicyRuleEvaluator{level: auditinternal.LevelMetadata},
+	})
+
+	testUser := &user.DefaultInfo{Name: "test-user", Groups: []string{"test-group"}}
+	tests := []struct {
+		name     string
+		group    string
+		version  string
+		verb     string
+		user     user.Info
+		expected auditinternal.Level
+	}{
+		{
+			name:     "create in a configured group",
+			group:    "dashboard.grafana.app",
+			verb:     utils.VerbCreate,
+			expected: auditinternal.LevelRequestResponse,
+		},
+		{
+			name:     "update in a wildcard group logs the request",
+			group:    "prometheus.datasource.grafana.app",
+			verb:     utils.VerbUpdate,
+			expected: auditinternal.LevelRequest,
+		},
+		{
+			name:     "reads are not audited by default",
+			group:    "dashboard.grafana.app",
+			verb:     utils.VerbGet,
+			expected: auditinternal.LevelNone,
+		},
+		{
+			name:     "other groups are not audited",
+			group:    "playlist.grafana.app",
+			verb:     utils.VerbDelete,
+			expected: auditinternal.LevelNone,
+		},
+		{
+			name:     "privileged requests are not audited",
+			group:    "dashboard.grafana.app",
+			verb:     utils.VerbDelete,
+			user:     &user.DefaultInfo{Groups: []string{user.SystemPrivilegedGroup}},
+			expected: auditinternal.LevelNone,
+		},
+		{
+			name:     "groups with their own evaluator",
+			group:    "custom.grafana.app",
+			version:  "v1",
+			verb:     utils.VerbGet,
+			expected: auditinternal.LevelMetadata,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u := tt.user
+			if u == nil {
+				u = testUser
+			}
+			config := evaluator.EvaluatePolicyRule(authorizer.AttributesRecord{
+				ResourceRequest: true,
+				APIGroup:        tt.group,
+				APIVersion:      tt.version,
+				Verb:            tt.verb,
+				User:            u,
+			})
+			require.Equal(t, tt.expected, config.Level)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package auditing
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// RedactedValue replaces the values of secure fields in audit events.
+const RedactedValue = "[REDACTED]"
+
+// secureFields are the properties holding secrets, at any depth of an object.
+// The inline secure values of app platform resources are stored in `secure`, legacy datasources use `secureJsonData`.
+var secureFields = map[string]bool{
+	"secure":            true,
+	"securejsondata":    true,
+	"password":          true,
+	"basicauthpassword": true,
+}
+
+// secretGroups are the API groups whose request bodies are never logged, as they are made of secrets.
+var secretGroups = map[string]bool{
+	"secret.grafana.app": true,
+}
+
+// RedactSecureFields returns the JSON object with the values of its secure fields replaced by RedactedValue.
+// JSON patches are redacted by path as well: the value of an operation on a secure field is replaced.
+// Values that can not be parsed are dropped, so a secret is never logged by mistake.
+func RedactSecureFields(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var obj any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil
+	}
+
+	if ops, ok := obj.([]any); ok && isJSONPatch(ops) {
+		obj = redactJSONPatch(ops)
+	} else {
+		obj = redact(obj)
+	}
+
+	redacted, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if secureFields[strings.ToLower(k)] {
+				v[k] = redactValue(child)
+				continue
+			}
+			v[k] = redact(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = redact(child)
+		}
+	}
+	return v
+}
+
+// redactValue keeps the keys of secure objects, so the audit log tells which secrets were set
+func redactValue(v any) any {
+	if m, ok := v.(map[string]any); ok {
+		for k := range m {
+			m[k] = RedactedValue
+		}
+		return m
+	}
+	return RedactedValue
+}
+
+// isJSONPatch tells whether the array is a JSON patch (RFC 6902), a list of operations with a path.
+func isJSONPatch(ops []any) bool {
+	if len(ops) == 0 {
+		return false
+	}
+	for _, op := range ops {
+		m, ok := op.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := m["op"].(string); !ok {
+			return false
+		}
+		if _, ok := m["path"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// redactJSONPatch redacts the values set on secure fields, or on their parents (eg. `/spec` with a new `password`).
+func redactJSONPatch(ops []any) []any {
+	for _, op := range ops {
+		m := op.(map[string]any)
+		value, ok := m["value"]
+		if !ok {
+			continue
+		}
+		if isSecurePath(m["path"].(string)) {
+			m["value"] = redactValue(value)
+		} else {
+			m["value"] = redact(value)
+		}
+	}
+	return ops
+}
+
+// isSecurePath tells whether a JSON pointer (RFC 6901) goes through a secure field.
+func isSecurePath(pointer string) bool {
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if secureFields[strings.ToLower(token)] {
+			return true
+		}
+	}
+	return false
+}
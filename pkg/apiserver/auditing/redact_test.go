@@ -0,0 +1,64 @@
+package auditing_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactSecureFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("redacts secure fields at any depth", func(t *testing.T) {
+		t.Parallel()
+
+		redacted := auditing.RedactSecureFields([]byte(`{
+			"metadata": {"name": "ds"},
+			"spec": {"url": "http://localhost", "basicAuthPassword": "hunter2"},
+			"secure": {"token": {"create": "abc"}, "password": {"name": "ref"}},
+			"items": [{"secureJsonData": {"apiKey": "key"}}]
+		}`))
+
+		var obj map[string]any
+		require.NoError(t, json.Unmarshal(redacted, &obj))
+		require.Equal(t, map[string]any{
+			"metadata": map[string]any{"name": "ds"},
+			"spec":     map[string]any{"url": "http://localhost", "basicAuthPassword": auditing.RedactedValue},
+			"secure":   map[string]any{"token": auditing.RedactedValue, "password": auditing.RedactedValue},
+			"items":    []any{map[string]any{"secureJsonData": map[string]any{"apiKey": auditing.RedactedValue}}},
+		}, obj)
+	})
+
+	t.Run("redacts json patches by path", func(t *testing.T) {
+		t.Parallel()
+
+		redacted := auditing.RedactSecureFields([]byte(`[
+			{"op": "replace", "path": "/spec/url", "value": "http://localhost"},
+			{"op": "add", "path": "/secure/token", "value": {"create": "abc"}},
+			{"op": "replace", "path": "/spec/jsonData/password", "value": "hunter2"},
+			{"op": "add", "path": "/spec", "value": {"basicAuthPassword": "hunter2"}},
+			{"op": "remove", "path": "/secure/password"}
+		]`))
+		require.NotContains(t, string(redacted), "abc")
+		require.NotContains(t, string(redacted), "hunter2")
+
+		var ops []map[string]any
+		require.NoError(t, json.Unmarshal(redacted, &ops))
+		require.Equal(t, []map[string]any{
+			{"op": "replace", "path": "/spec/url", "value": "http://localhost"},
+			{"op": "add", "path": "/secure/token", "value": map[string]any{"create": auditing.RedactedValue}},
+			{"op": "replace", "path": "/spec/jsonData/password", "value": auditing.RedactedValue},
+			{"op": "add", "path": "/spec", "value": map[string]any{"basicAuthPassword": auditing.RedactedValue}},
+			{"op": "remove", "path": "/secure/password"},
+		}, ops)
+	})
+
+	t.Run("drops invalid values", func(t *testing.T) {
+		t.Parallel()
+
+		require.Nil(t, auditing.RedactSecureFields([]byte(`{"secure": "abc`)))
+		require.Nil(t, auditing.RedactSecureFields(nil))
+	})
+}
@@ -0,0 +1,167 @@
+package auditing
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// local0, see RFC 5424 section 6.2.1
+	defaultSyslogFacility = 16
+	syslogVersion         = 1
+	syslogDialTimeout     = 5 * time.Second
+
+	severityWarning = 4
+	severityInfo    = 6
+)
+
+// SyslogLoggerOptions configures the syslog logger
+type SyslogLoggerOptions struct {
+	// udp, tcp, unix or unixgram
+	Network string
+	Address string
+	// Facility code, 16 (local0) by default
+	Facility int
+	// APP-NAME of the messages, grafana by default
+	AppName string
+	// HOSTNAME of the messages, the OS hostname by default
+	Hostname string
+}
+
+// SyslogLogger sends the JSON entries as RFC 5424 messages.
+// Stream connections (tcp, unix) use the octet counting framing of RFC 6587.
+type SyslogLogger struct {
+	opts   SyslogLoggerOptions
+	procID string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+var _ Logger = &SyslogLogger{}
+
+func NewSyslogLogger(opts SyslogLoggerOptions) (*SyslogLogger, error) {
+	switch opts.Network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network: %q", opts.Network)
+	}
+	if opts.Address == "" {
+		return nil, errors.New("missing syslog address")
+	}
+	if opts.Facility < 0 || opts.Facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility: %d", opts.Facility)
+	}
+	if opts.Facility == 0 {
+		opts.Facility = defaultSyslogFacility
+	}
+	if opts.AppName == "" {
+		opts.AppName = "grafana"
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+
+	l := &SyslogLogger{
+		opts:   opts,
+		procID: strconv.Itoa(os.Getpid()),
+	}
+	if err := l.connect(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *SyslogLogger) Type() string { return "syslog" }
+
+func (l *SyslogLogger) Log(entry Sinkable) error {
+	msg, err := l.format(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Reconnect once, the syslog server may have been restarted
+	if l.conn != nil {
+		if _, err = l.conn.Write(msg); err == nil {
+			return nil
+		}
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+	if err := l.connect(); err != nil {
+		return err
+	}
+	_, err = l.conn.Write(msg)
+	return err
+}
+
+func (l *SyslogLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close()
+	l.conn = nil
+	return err
+}
+
+func (l *SyslogLogger) connect() error {
+	conn, err := net.DialTimeout(l.opts.Network, l.opts.Address, syslogDialTimeout)
+	if err != nil {
+		return fmt.Errorf("connect to syslog: %w", err)
+	}
+	l.conn = conn
+	return nil
+}
+
+// format builds the message: <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (l *SyslogLogger) format(entry Sinkable) ([]byte, error) {
+	data, err := entry.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	severity := severityInfo
+	if e, ok := entry.(Event); ok && e.Outcome != EventOutcomeSuccess {
+		severity = severityWarning
+	}
+
+	msg := fmt.Sprintf("<%d>%d %s %s %s %s audit - %s",
+		l.opts.Facility*8+severity,
+		syslogVersion,
+		entry.Time().UTC().Format(time.RFC3339Nano),
+		syslogHeaderValue(l.opts.Hostname, 255),
+		syslogHeaderValue(l.opts.AppName, 48),
+		l.procID,
+		data,
+	)
+
+	if l.opts.Network == "tcp" || l.opts.Network == "unix" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	return []byte(msg), nil
+}
+
+// syslogHeaderValue returns the value as printable ASCII without spaces, or the nil value (-)
+func syslogHeaderValue(v string, maxLen int) string {
+	out := make([]byte, 0, len(v))
+	for i := 0; i < len(v) && len(out) < maxLen; i++ {
+		if c := v[i]; c > 32 && c < 127 {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return "-"
+	}
+	return string(out)
+}
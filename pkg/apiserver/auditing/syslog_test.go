@@ -0,0 +1,60 @@
+package auditing_test
+
+import (
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("validates the options", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := auditing.NewSyslogLogger(auditing.SyslogLoggerOptions{Network: "http", Address: "localhost:514"})
+		require.Error(t, err)
+		_, err = auditing.NewSyslogLogger(auditing.SyslogLoggerOptions{Network: "udp"})
+		require.Error(t, err)
+		_, err = auditing.NewSyslogLogger(auditing.SyslogLoggerOptions{Network: "udp", Address: "localhost:514", Facility: 24})
+		require.Error(t, err)
+	})
+
+	t.Run("sends rfc 5424 messages", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		logger, err := auditing.NewSyslogLogger(auditing.SyslogLoggerOptions{
+			Network:  "udp",
+			Address:  conn.LocalAddr().String(),
+			Hostname: "grafana host",
+		})
+		require.NoError(t, err)
+		defer func() { _ = logger.Close() }()
+		require.Equal(t, "syslog", logger.Type())
+
+		observed := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, logger.Log(auditing.Event{ObservedAt: observed, Verb: "delete", Outcome: auditing.EventOutcomeSuccess}))
+		require.NoError(t, logger.Log(auditing.Event{ObservedAt: observed, Verb: "delete", Outcome: auditing.EventOutcomeFailureUnauthorized}))
+
+		buf := make([]byte, 4096)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+		// local0 (16) * 8 + info (6)
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		require.Regexp(t, regexp.MustCompile(`^<134>1 2025-01-01T12:00:00Z grafanahost grafana \d+ audit - \{.*"verb":"delete".*\}$`), string(buf[:n]))
+
+		// failures are warnings
+		n, _, err = conn.ReadFrom(buf)
+		require.NoError(t, err)
+		require.Regexp(t, `^<132>1 `, string(buf[:n]))
+	})
+}
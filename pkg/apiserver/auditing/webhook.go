@@ -0,0 +1,245 @@
+package auditing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebhookBatchSize     = 100
+	defaultWebhookBufferSize    = 10000
+	defaultWebhookFlushInterval = 5 * time.Second
+	defaultWebhookMaxRetries    = 3
+	defaultWebhookRetryBackoff  = time.Second
+	defaultWebhookTimeout       = 10 * time.Second
+	defaultWebhookCloseTimeout  = 30 * time.Second
+)
+
+// ErrBufferFull is returned when an entry is dropped because the webhook can not keep up.
+var ErrBufferFull = errors.New("audit log buffer is full")
+
+// WebhookLoggerOptions configures the webhook logger
+type WebhookLoggerOptions struct {
+	URL string
+	// Extra headers, for example for authentication
+	Headers map[string]string
+
+	// Send a batch once it has this many entries (default 100), or after the flush interval (default 5s)
+	BatchSize     int
+	FlushInterval time.Duration
+	// How many entries wait to be sent before new ones are dropped (default 10000)
+	BufferSize int
+
+	// Retries of a failed batch (default 3), waiting RetryBackoff (default 1s) doubled after each attempt
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// Client used to send the batches, with a 10s timeout by default
+	Client *http.Client
+
+	// How long Close waits for the queued entries to be sent (default 30s), the remaining ones are dropped
+	CloseTimeout time.Duration
+
+	// Called when a batch is dropped after its last retry, it can be nil
+	OnError func(err error, entries int)
+}
+
+// WebhookLogger posts the entries in batches, as a JSON array.
+// Entries are buffered and sent in the background, failed batches are retried on network errors, 429 and 5xx responses.
+type WebhookLogger struct {
+	opts WebhookLoggerOptions
+
+	entries chan json.RawMessage
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ Logger = &WebhookLogger{}
+
+func NewWebhookLogger(opts WebhookLoggerOptions) (*WebhookLogger, error) {
+	if opts.URL == "" {
+		return nil, errors.New("missing audit webhook url")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultWebhookBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultWebhookFlushInterval
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultWebhookBufferSize
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultWebhookMaxRetries
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultWebhookRetryBackoff
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	if opts.CloseTimeout <= 0 {
+		opts.CloseTimeout = defaultWebhookCloseTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &WebhookLogger{
+		opts:    opts,
+		entries: make(chan json.RawMessage, opts.BufferSize),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go l.run()
+	return l, nil
+}
+
+func (l *WebhookLogger) Type() string { return "webhook" }
+
+// Log queues the entry, it never blocks the request being audited
+func (l *WebhookLogger) Log(entry Sinkable) error {
+	data, err := entry.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return errors.New("audit webhook logger is closed")
+	}
+
+	select {
+	case l.entries <- data:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// Close sends the queued entries and stops the logger.
+// The whole drain is bounded by the close timeout, so a slow or unreachable webhook can not stall the shutdown:
+// the pending requests are then cancelled and the entries still queued are dropped.
+func (l *WebhookLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.entries)
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.opts.CloseTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-timer.C:
+		l.cancel()
+		<-l.done
+		return fmt.Errorf("audit webhook logger did not send the queued entries within %s", l.opts.CloseTimeout)
+	}
+}
+
+func (l *WebhookLogger) run() {
+	defer close(l.done)
+	defer l.cancel()
+
+	ticker := time.NewTicker(l.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]json.RawMessage, 0, l.opts.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		// Once cancelled by Close, the remaining batches are dropped without trying to send them
+		if err := l.ctx.Err(); err != nil {
+			if l.opts.OnError != nil {
+				l.opts.OnError(err, len(batch))
+			}
+			batch = batch[:0]
+			return
+		}
+		if err := l.send(batch); err != nil && l.opts.OnError != nil {
+			l.opts.OnError(err, len(batch))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-l.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= l.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send posts the batch, retrying on failures that may be temporary
+func (l *WebhookLogger) send(batch []json.RawMessage) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	backoff := l.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := l.post(body)
+		if err == nil || !retry || attempt >= l.opts.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-l.ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (l *WebhookLogger) post(body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(l.ctx, http.MethodPost, l.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range l.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := l.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, rsp.Body)
+	_ = rsp.Body.Close()
+
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("audit webhook responded with status %d", rsp.StatusCode)
+	return rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= 500, err
+}
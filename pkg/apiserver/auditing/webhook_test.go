@@ -0,0 +1,135 @@
+package auditing_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("requires a url", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := auditing.NewWebhookLogger(auditing.WebhookLoggerOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("sends batches and retries failures", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu       sync.Mutex
+			requests int
+			batches  [][]map[string]any
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			var batch []map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+			batches = append(batches, batch)
+		}))
+		defer server.Close()
+
+		logger, err := auditing.NewWebhookLogger(auditing.WebhookLoggerOptions{
+			URL:           server.URL,
+			Headers:       map[string]string{"Authorization": "Bearer token"},
+			BatchSize:     2,
+			FlushInterval: time.Hour,
+			RetryBackoff:  time.Millisecond,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "webhook", logger.Type())
+
+		for _, verb := range []string{"create", "update", "delete"} {
+			require.NoError(t, logger.Log(auditing.Event{ObservedAt: time.Now(), Verb: verb}))
+		}
+		// the last partial batch is sent when closing
+		require.NoError(t, logger.Close())
+		require.Error(t, logger.Log(auditing.Event{Verb: "get"}))
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 3, requests)
+		require.Len(t, batches, 2)
+		require.Len(t, batches[0], 2)
+		require.Equal(t, "create", batches[0][0]["verb"])
+		require.Len(t, batches[1], 1)
+		require.Equal(t, "delete", batches[1][0]["verb"])
+	})
+
+	t.Run("drops batches after the last retry", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		dropped := 0
+		logger, err := auditing.NewWebhookLogger(auditing.WebhookLoggerOptions{
+			URL:     server.URL,
+			OnError: func(_ error, entries int) { dropped += entries },
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, logger.Log(auditing.Event{Verb: "create"}))
+		require.NoError(t, logger.Close())
+		require.Equal(t, 1, dropped)
+	})
+
+	t.Run("bounds the time spent sending the queued entries when closing", func(t *testing.T) {
+		t.Parallel()
+
+		// The webhook never responds
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		var (
+			mu      sync.Mutex
+			dropped int
+		)
+		logger, err := auditing.NewWebhookLogger(auditing.WebhookLoggerOptions{
+			URL:           server.URL,
+			BatchSize:     1,
+			FlushInterval: time.Hour,
+			CloseTimeout:  50 * time.Millisecond,
+			OnError: func(_ error, entries int) {
+				mu.Lock()
+				defer mu.Unlock()
+				dropped += entries
+			},
+		})
+		require.NoError(t, err)
+
+		for _, verb := range []string{"create", "update", "delete"} {
+			require.NoError(t, logger.Log(auditing.Event{Verb: verb}))
+		}
+
+		start := time.Now()
+		require.Error(t, logger.Close())
+		require.Less(t, time.Since(start), 5*time.Second)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 3, dropped)
+	})
+}
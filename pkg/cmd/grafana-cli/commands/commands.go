@@ -74,6 +74,21 @@ func initializeRunner(ctx context.Context, cmd *utils.ContextCommandLine) (serve
 	return runner, nil
 }
 
+func runCfgCommand(command func(commandLine utils.CommandLine, cfg *setting.Cfg) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		cfg, err := cmd.Config()
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to load configuration", err)
+		}
+		if err := command(cmd, cfg); err != nil {
+			return err
+		}
+		logger.Info("\n\n")
+		return nil
+	}
+}
+
 func runPluginCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		cmd := &utils.ContextCommandLine{Context: context}
@@ -178,6 +193,106 @@ var adminCommands = []*cli.Command{
 				Usage:  "Re-encrypts all encrypted values with new data keys and deletes the old deactivated data keys. Returns ok unless there is an error. Safe to execute multiple times.",
 				Action: runRunnerCommand(secretsconsolidation.ConsolidateSecrets),
 			},
+			{
+				Name:   "rotate-data-keys",
+				Usage:  "Disables the data keys of a namespace and re-encrypts its encrypted values with a new data key. Resumes an interrupted rotation. Safe to execute multiple times.",
+				Action: runRunnerCommand(secretsconsolidation.RotateDataKeys),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "The namespace whose data keys are rotated",
+					},
+				},
+			},
+			{
+				Name:   "rotation-status",
+				Usage:  "Prints the progress of the latest data key rotation of a namespace.",
+				Action: runRunnerCommand(secretsconsolidation.DataKeyRotationStatus),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "The namespace whose data key rotation is reported",
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:  "unified-storage",
+		Usage: "Backs up and restores unified storage",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "backup",
+				Usage:  "Writes a full backup of the embedded database of the file storage type ([grafana-apiserver] storage_type = file) to a new file. Grafana must be stopped.",
+				Action: runCfgCommand(unifiedStorageBackupCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "The backup file to create",
+					},
+				},
+			},
+			{
+				Name:   "restore",
+				Usage:  "Restores a backup into an empty embedded database of the file storage type. Grafana must be stopped.",
+				Action: runCfgCommand(unifiedStorageRestoreCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "The backup file to restore",
+					},
+				},
+			},
+			{
+				Name:   "namespace-backup",
+				Usage:  "Writes the resources of a namespace, and optionally their history, to a new parquet file. Grafana can keep running, except with the file storage type.",
+				Action: runRunnerCommand(namespaceBackupCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "The namespace to back up",
+					},
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "The backup file to create",
+					},
+					&cli.BoolFlag{
+						Name:  "history",
+						Usage: "Include every version of the resources, required to restore an earlier point in time",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "Only back up these resources ({group}/{resource}), all of them by default",
+					},
+				},
+			},
+			{
+				Name:   "namespace-restore",
+				Usage:  "Replaces the resources of a namespace with their state in a backup, at its latest version, a resource version or a timestamp.",
+				Action: runRunnerCommand(namespaceRestoreCommand),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "The backup file to restore",
+					},
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "Restore into this namespace instead of the one of the backup",
+					},
+					&cli.IntFlag{
+						Name:  "resource-version",
+						Usage: "Restore the resources as they were at this resource version",
+					},
+					&cli.StringFlag{
+						Name:  "timestamp",
+						Usage: "Restore the resources as they were at this time (RFC3339)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "resource",
+						Usage: "Only restore these resources ({group}/{resource}), all of them by default",
+					},
+				},
+			},
 		},
 	},
 	{
@@ -0,0 +1,47 @@
+package secretsconsolidation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
+	"github.com/grafana/grafana/pkg/server"
+)
+
+func RotateDataKeys(c utils.CommandLine, runner server.Runner) error {
+	namespace := c.String("namespace")
+	if namespace == "" {
+		return errors.New("the namespace to rotate is required")
+	}
+
+	rotation, err := runner.DataKeyRotationService.Rotate(context.Background(), xkube.Namespace(namespace))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Data key rotation %s: processed %d/%d, re-encrypted %d, failed %d\n", rotation.Phase, rotation.Processed, rotation.Total, rotation.Rotated, rotation.Failed)
+	if rotation.Failed > 0 {
+		return fmt.Errorf("data key rotation of namespace %s finished with errors: %s", namespace, rotation.Message)
+	}
+
+	return nil
+}
+
+func DataKeyRotationStatus(c utils.CommandLine, runner server.Runner) error {
+	namespace := c.String("namespace")
+	if namespace == "" {
+		return errors.New("the namespace is required")
+	}
+
+	rotation, err := runner.DataKeyRotationService.Status(context.Background(), xkube.Namespace(namespace))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Data key rotation %s: processed %d/%d, re-encrypted %d, failed %d\n", rotation.Phase, rotation.Processed, rotation.Total, rotation.Rotated, rotation.Failed)
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	// ErrUnsupportedSchema occurs when comparing dashboards that don't use the panels array, such as v2 dashboards.
+	ErrUnsupportedSchema = errors.New("dashdiff: semantic diff is only supported for dashboards with a panels array")
+	// ErrElementNotFound occurs when restoring an element that does not exist in the version.
+	ErrElementNotFound = errors.New("dashdiff: element not found")
+)
+
+// ElementKind is the kind of dashboard element compared by the semantic diff.
+type ElementKind string
+
+const (
+	ElementDashboard  ElementKind = "dashboard"
+	ElementPanel      ElementKind = "panel"
+	ElementQuery      ElementKind = "query"
+	ElementVariable   ElementKind = "variable"
+	ElementAnnotation ElementKind = "annotation"
+)
+
+// ChangeType is how an element changed between two versions.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+	ChangeMoved    ChangeType = "moved"
+)
+
+// Element is a part of a dashboard identified by a key that is stable across versions: panels by their id,
+// queries by the id of their panel and their refId, variables and annotations by their name.
+type Element struct {
+	Kind  ElementKind
+	Key   string
+	Title string
+	// Parent is the key of the row a panel is nested in, or of the panel of a query
+	Parent string
+	// Position is the grid position of panels and the index of queries, variables and annotations
+	Position any
+	// Value is the content of the element without its position and nested elements
+	Value map[string]any
+}
+
+// SemanticChange is the change of an element between two versions.
+type SemanticChange struct {
+	Kind   ElementKind `json:"kind"`
+	Key    string      `json:"key"`
+	Title  string      `json:"title"`
+	Change ChangeType  `json:"change"`
+	// Fields are the fields of a modified element that changed
+	Fields []string `json:"fields,omitempty"`
+	// Moved is set when the element changed position, modified elements may have moved too
+	Moved bool `json:"moved,omitempty"`
+}
+
+type SemanticResult struct {
+	Changes []SemanticChange `json:"changes"`
+}
+
+// SemanticDiff compares two versions of a dashboard element by element, so panels that only moved are not reported
+// as removed and added again. Changes are sorted in the order of the new version, followed by the removed elements.
+func SemanticDiff(baseData, newData *simplejson.Json) (*SemanticResult, error) {
+	baseElements, err := Elements(baseData)
+	if err != nil {
+		return nil, err
+	}
+	newElements, err := Elements(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]Element, len(baseElements))
+	for _, e := range baseElements {
+		base[e.Key] = e
+	}
+	current := make(map[string]bool, len(newElements))
+
+	result := &SemanticResult{Changes: make([]SemanticChange, 0)}
+	for _, e := range newElements {
+		current[e.Key] = true
+		if change, ok := compareElements(base, e); ok {
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	for _, e := range baseElements {
+		if !current[e.Key] {
+			result.Changes = append(result.Changes, SemanticChange{Kind: e.Kind, Key: e.Key, Title: e.Title, Change: ChangeRemoved})
+		}
+	}
+	return result, nil
+}
+
+// compareElements returns the change of an element of the new version compared to the base version, if any.
+func compareElements(base map[string]Element, e Element) (SemanticChange, bool) {
+	change := SemanticChange{Kind: e.Kind, Key: e.Key, Title: e.Title}
+	old, ok := base[e.Key]
+	if !ok {
+		change.Change = ChangeAdded
+		return change, true
+	}
+
+	change.Fields = changedFields(old.Value, e.Value)
+	change.Moved = old.Parent != e.Parent || !reflect.DeepEqual(old.Position, e.Position)
+	switch {
+	case len(change.Fields) > 0:
+		change.Change = ChangeModified
+	case change.Moved:
+		change.Change = ChangeMoved
+		change.Moved = false
+	default:
+		return change, false
+	}
+	return change, true
+}
+
+func changedFields(base, new map[string]any) []string {
+	var fields []string
+	for k, v := range new {
+		if old, ok := base[k]; !ok || !reflect.DeepEqual(old, v) {
+			fields = append(fields, k)
+		}
+	}
+	for k := range base {
+		if _, ok := new[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Elements returns the elements of a dashboard in document order: the dashboard settings, then every panel
+// followed by its queries, the variables and the annotations.
+func Elements(data *simplejson.Json) ([]Element, error) {
+	dash, err := normalize(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dash["elements"]; ok {
+		return nil, ErrUnsupportedSchema
+	}
+
+	settings := without(dash, "panels", "templating", "annotations", "id", "uid", "version")
+	title, _ := dash["title"].(string)
+	elements := []Element{{Kind: ElementDashboard, Key: string(ElementDashboard), Title: title, Value: settings}}
+
+	var walk func(list []any, parent string)
+	walk = func(list []any, parent string) {
+		for i, obj := range list {
+			panel, ok := obj.(map[string]any)
+			if !ok {
+				continue
+			}
+			key := PanelKey(panel, i)
+			elements = append(elements, Element{
+				Kind:     ElementPanel,
+				Key:      key,
+				Title:    stringField(panel, "title"),
+				Parent:   parent,
+				Position: panel["gridPos"],
+				Value:    without(panel, "gridPos", "targets", "panels"),
+			})
+			targets, _ := panel["targets"].([]any)
+			for j, obj := range targets {
+				target, ok := obj.(map[string]any)
+				if !ok {
+					continue
+				}
+				refID := stringField(target, "refId")
+				if refID == "" {
+					refID = strconv.Itoa(j)
+				}
+				elements = append(elements, Element{
+					Kind:     ElementQuery,
+					Key:      key + "/query/" + refID,
+					Title:    refID,
+					Parent:   key,
+					Position: j,
+					Value:    target,
+				})
+			}
+			if nested, ok := panel["panels"].([]any); ok {
+				walk(nested, key)
+			}
+		}
+	}
+	panels, _ := dash["panels"].([]any)
+	walk(panels, "")
+
+	for _, kind := range []ElementKind{ElementVariable, ElementAnnotation} {
+		list, _ := listOf(dash, kind)
+		for i, obj := range list {
+			item, ok := obj.(map[string]any)
+			if !ok {
+				continue
+			}
+			name := stringField(item, "name")
+			elements = append(elements, Element{
+				Kind:     kind,
+				Key:      string(kind) + "/" + name,
+				Title:    name,
+				Position: i,
+				Value:    item,
+			})
+		}
+	}
+	return elements, nil
+}
+
+// PanelKey returns the key identifying a panel across versions. Panels without an id fall back to their index.
+func PanelKey(panel map[string]any, index int) string {
+	if id, ok := panel["id"].(float64); ok {
+		return "panel/" + strconv.FormatInt(int64(id), 10)
+	}
+	return "panel/index-" + strconv.Itoa(index)
+}
+
+// RestoreElements returns a copy of the current dashboard where the given panels and variables are replaced with
+// their content in the version. Panels keep their current position, panels that were deleted since the version are
+// added back at their position in the version.
+func RestoreElements(current, version *simplejson.Json, panelIDs []int64, variables []string) (*simplejson.Json, error) {
+	dash, err := normalize(current)
+	if err != nil {
+		return nil, err
+	}
+	old, err := normalize(version)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dash["elements"]; ok {
+		return nil, ErrUnsupportedSchema
+	}
+	if _, ok := old["elements"]; ok {
+		return nil, ErrUnsupportedSchema
+	}
+
+	for _, id := range panelIDs {
+		panel := findPanel(old["panels"], id)
+		if panel == nil {
+			return nil, fmt.Errorf("%w: panel %d", ErrElementNotFound, id)
+		}
+		if existing := findPanel(dash["panels"], id); existing != nil {
+			gridPos, hasGridPos := existing["gridPos"]
+			clear(existing)
+			for k, v := range panel {
+				existing[k] = v
+			}
+			if hasGridPos {
+				existing["gridPos"] = gridPos
+			}
+			continue
+		}
+		panels, _ := dash["panels"].([]any)
+		dash["panels"] = append(panels, panel)
+	}
+
+	for _, name := range variables {
+		oldList, _ := listOf(old, ElementVariable)
+		variable := findByName(oldList, name)
+		if variable == nil {
+			return nil, fmt.Errorf("%w: variable %q", ErrElementNotFound, name)
+		}
+		list, templating := listOf(dash, ElementVariable)
+		if existing := findByName(list, name); existing != nil {
+			clear(existing)
+			for k, v := range variable {
+				existing[k] = v
+			}
+			continue
+		}
+		templating["list"] = append(list, variable)
+		dash["templating"] = templating
+	}
+
+	return simplejson.NewFromAny(dash), nil
+}
+
+func findPanel(list any, id int64) map[string]any {
+	panels, _ := list.([]any)
+	for _, obj := range panels {
+		panel, ok := obj.(map[string]any)
+		if !ok {
+			continue
+		}
+		if panelID, ok := panel["id"].(float64); ok && int64(panelID) == id {
+			return panel
+		}
+		if nested := findPanel(panel["panels"], id); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
+func findByName(list []any, name string) map[string]any {
+	for _, obj := range list {
+		if item, ok := obj.(map[string]any); ok && stringField(item, "name") == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// listOf returns the list of variables or annotations of a dashboard and the object holding it.
+func listOf(dash map[string]any, kind ElementKind) ([]any, map[string]any) {
+	field := "templating"
+	if kind == ElementAnnotation {
+		field = "annotations"
+	}
+	holder, ok := dash[field].(map[string]any)
+	if !ok {
+		holder = map[string]any{}
+	}
+	list, _ := holder["list"].([]any)
+	return list, holder
+}
+
+// normalize returns a deep copy of the dashboard with the types encoding/json decodes to, since versions read from
+// different stores hold numbers as json.Number, int64 or float64.
+func normalize(data *simplejson.Json) (map[string]any, error) {
+	if data == nil {
+		return map[string]any{}, nil
+	}
+	raw, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	dash := make(map[string]any)
+	if err := json.Unmarshal(raw, &dash); err != nil {
+		return nil, err
+	}
+	return dash, nil
+}
+
+func without(obj map[string]any, keys ...string) map[string]any {
+	res := make(map[string]any, len(obj))
+	for k, v := range obj {
+		res[k] = v
+	}
+	for _, k := range keys {
+		delete(res, k)
+	}
+	return res
+}
+
+func stringField(obj map[string]any, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
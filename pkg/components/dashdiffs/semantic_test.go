@@ -0,0 +1,120 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func mustJSON(t *testing.T, s string) *simplejson.Json {
+	t.Helper()
+	j, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return j
+}
+
+func TestSemanticDiff(t *testing.T) {
+	base := mustJSON(t, `{
+		"title": "Service",
+		"version": 3,
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+			 "targets": [{"refId": "A", "expr": "rate(cpu[5m])"}, {"refId": "B", "expr": "up"}]},
+			{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 3, "title": "Details", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 1},
+			 "panels": [{"id": 4, "title": "Logs", "type": "logs", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}]}
+		],
+		"templating": {"list": [{"name": "env", "query": "prod,dev"}, {"name": "host", "query": "label_values(host)"}]}
+	}`)
+	updated := mustJSON(t, `{
+		"title": "Service",
+		"version": 4,
+		"panels": [
+			{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+			{"id": 1, "title": "CPU usage", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+			 "targets": [{"refId": "A", "expr": "rate(cpu[10m])"}, {"refId": "B", "expr": "up"}]},
+			{"id": 3, "title": "Details", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 1}, "panels": []},
+			{"id": 4, "title": "Logs", "type": "logs", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}},
+			{"id": 5, "title": "Disk", "type": "stat", "gridPos": {"x": 0, "y": 17, "w": 6, "h": 4}}
+		],
+		"templating": {"list": [{"name": "env", "query": "prod,dev,staging"}]}
+	}`)
+
+	result, err := SemanticDiff(base, updated)
+	require.NoError(t, err)
+	assert.Equal(t, []SemanticChange{
+		{Kind: ElementPanel, Key: "panel/2", Title: "Memory", Change: ChangeMoved},
+		{Kind: ElementPanel, Key: "panel/1", Title: "CPU usage", Change: ChangeModified, Fields: []string{"title"}, Moved: true},
+		{Kind: ElementQuery, Key: "panel/1/query/A", Title: "A", Change: ChangeModified, Fields: []string{"expr"}},
+		{Kind: ElementPanel, Key: "panel/4", Title: "Logs", Change: ChangeMoved},
+		{Kind: ElementPanel, Key: "panel/5", Title: "Disk", Change: ChangeAdded},
+		{Kind: ElementVariable, Key: "variable/env", Title: "env", Change: ChangeModified, Fields: []string{"query"}},
+		{Kind: ElementVariable, Key: "variable/host", Title: "host", Change: ChangeRemoved},
+	}, result.Changes)
+}
+
+func TestSemanticDiff_IdenticalVersions(t *testing.T) {
+	dash := `{"title": "Service", "panels": [{"id": 1, "title": "CPU", "targets": [{"refId": "A"}]}]}`
+
+	result, err := SemanticDiff(mustJSON(t, dash), simplejson.NewFromAny(mustJSON(t, dash).MustMap()))
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+}
+
+func TestSemanticDiff_UnsupportedSchema(t *testing.T) {
+	_, err := SemanticDiff(mustJSON(t, `{"elements": {}}`), mustJSON(t, `{"elements": {}}`))
+	require.ErrorIs(t, err, ErrUnsupportedSchema)
+}
+
+func TestRestoreElements(t *testing.T) {
+	version := mustJSON(t, `{
+		"panels": [
+			{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}, "targets": [{"refId": "A", "expr": "cpu"}]},
+			{"id": 2, "title": "Memory", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}}
+		],
+		"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "a,b"}]}
+	}`)
+	current := mustJSON(t, `{
+		"panels": [
+			{"id": 1, "title": "CPU (broken)", "gridPos": {"x": 0, "y": 10, "w": 24, "h": 8}, "targets": [{"refId": "A", "expr": "oops"}]},
+			{"id": 3, "title": "New", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 8}}
+		],
+		"templating": {"list": [{"name": "env", "query": "dev"}]}
+	}`)
+
+	t.Run("should restore panels and variables", func(t *testing.T) {
+		res, err := RestoreElements(current, version, []int64{1, 2}, []string{"env", "host"})
+		require.NoError(t, err)
+
+		expected := mustJSON(t, `{
+			"panels": [
+				{"id": 1, "title": "CPU", "gridPos": {"x": 0, "y": 10, "w": 24, "h": 8}, "targets": [{"refId": "A", "expr": "cpu"}]},
+				{"id": 3, "title": "New", "gridPos": {"x": 0, "y": 0, "w": 24, "h": 8}},
+				{"id": 2, "title": "Memory", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}}
+			],
+			"templating": {"list": [{"name": "env", "query": "prod"}, {"name": "host", "query": "a,b"}]}
+		}`)
+		expectedJSON, err := expected.Encode()
+		require.NoError(t, err)
+		resJSON, err := res.Encode()
+		require.NoError(t, err)
+		assert.JSONEq(t, string(expectedJSON), string(resJSON))
+	})
+
+	t.Run("should not modify the current dashboard", func(t *testing.T) {
+		_, err := RestoreElements(current, version, []int64{1}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "CPU (broken)", current.Get("panels").GetIndex(0).Get("title").MustString())
+	})
+
+	t.Run("should fail when the element is not in the version", func(t *testing.T) {
+		_, err := RestoreElements(current, version, []int64{3}, nil)
+		require.ErrorIs(t, err, ErrElementNotFound)
+
+		_, err = RestoreElements(current, version, nil, []string{"missing"})
+		require.ErrorIs(t, err, ErrElementNotFound)
+	})
+}
@@ -1,5 +1,10 @@
 package serverlock
 
+import "errors"
+
+// ErrLockLost is returned when refreshing a lock that timed out or was released.
+var ErrLockLost = errors.New("the lock was lost")
+
 type ServerLockExistsError struct {
 	actionName string
 }
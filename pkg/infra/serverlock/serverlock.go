@@ -301,6 +301,36 @@ func (sl *ServerLockService) acquireForRelease(ctx context.Context, actionName s
 	return err
 }
 
+// RefreshLock resets the timeout of a lock taken by LockExecuteAndRelease, so that functions running for longer than
+// maxInterval keep it as long as they refresh it more often than that. It must be called from within the function.
+// It returns ErrLockLost if the lock timed out or was released in the meantime, as another server may have taken it.
+func (sl *ServerLockService) RefreshLock(ctx context.Context, actionName string, maxInterval time.Duration) error {
+	ctx, span := sl.tracer.Start(ctx, "ServerLockService.RefreshLock")
+	span.SetAttributes(attribute.String("serverlock.actionName", actionName))
+	defer span.End()
+
+	now := time.Now()
+	var affected int64
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		res, err := dbSession.Exec("UPDATE server_lock SET last_execution = ? WHERE operation_uid = ? AND last_execution > ?",
+			now.Unix(), actionName, now.Add(-maxInterval).Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to refresh serverlock: %v", err))
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, actionName)
+	}
+	return nil
+}
+
 // releaseLock will delete the row at the database. This is only intended to be used within the scope of LockExecuteAndRelease
 // method, but not as to manually release a Lock
 func (sl *ServerLockService) releaseLock(ctx context.Context, actionName string) error {
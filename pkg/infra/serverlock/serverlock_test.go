@@ -107,6 +107,43 @@ func TestIntegrationLockAndRelease(t *testing.T) {
 		require.NoError(t, err3)
 	})
 
+	t.Run("refresh a lock that is held, and fail once it was released", func(t *testing.T) {
+		sl := createTestableServerLock(t)
+		duration := time.Minute * 5
+
+		err := sl.acquireForRelease(context.Background(), operationUID, duration)
+		require.NoError(t, err)
+
+		// the lock stays held for another interval
+		err = sl.RefreshLock(context.Background(), operationUID, duration)
+		require.NoError(t, err)
+
+		err = sl.releaseLock(context.Background(), operationUID)
+		require.NoError(t, err)
+
+		err = sl.RefreshLock(context.Background(), operationUID, duration)
+		require.ErrorIs(t, err, ErrLockLost)
+	})
+
+	t.Run("refresh a lock that timed out", func(t *testing.T) {
+		sl := createTestableServerLock(t)
+		lock := serverLock{
+			OperationUID:  operationUID,
+			LastExecution: time.Now().Add(-time.Hour).Unix(),
+		}
+		err := sl.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Insert(&lock)
+			return err
+		})
+		require.NoError(t, err)
+
+		err = sl.RefreshLock(context.Background(), operationUID, time.Minute*5)
+		require.ErrorIs(t, err, ErrLockLost)
+
+		err = sl.releaseLock(context.Background(), operationUID)
+		require.NoError(t, err)
+	})
+
 	t.Run("lock already exists but is timeouted", func(t *testing.T) {
 		sl := createTestableServerLock(t)
 		pastLastExec := time.Now().Add(-time.Hour).Unix()
@@ -30,6 +30,7 @@ import (
 	gitrepo "github.com/grafana/grafana/apps/provisioning/pkg/repository/git"
 	githubrepo "github.com/grafana/grafana/apps/provisioning/pkg/repository/github"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/bucket"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/controller"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks"
@@ -554,6 +555,14 @@ func (c *ControllerConfig) RepositoryExtras() ([]repository.Extra, error) {
 				return nil, fmt.Errorf("local_permitted_prefixes is required in [operator] section for local repository type")
 			}
 			extras = append(extras, local.Extra(homePath, permittedPrefixes))
+		case provisioning.BucketRepositoryType:
+			// Buckets on the local file system are only allowed in the permitted prefixes
+			homePath := operatorSec.Key("home_path").String()
+			permittedPrefixes := operatorSec.Key("local_permitted_prefixes").Strings("|")
+			extras = append(extras, bucket.Extra(decrypter, homePath, permittedPrefixes, bucket.AllowList{
+				Endpoints: provisioningSec.Key("bucket_allowed_endpoints").Strings("|"),
+				Regions:   provisioningSec.Key("bucket_allowed_regions").Strings("|"),
+			}))
 		default:
 			return nil, fmt.Errorf("unsupported repository type: %s", t)
 		}
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
 	deletepkg "github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/delete"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/drift"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/export"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/fixfoldermetadata"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/migrate"
@@ -292,6 +293,10 @@ func setupWorkers(
 	fixMetadataWorker := fixfoldermetadata.NewWorker()
 	workers = append(workers, fixMetadataWorker)
 
+	// Drift
+	driftWorker := drift.NewWorker(repositoryResources, parsers, clients, statusPatcher.Patch, stageIfPossible, metrics)
+	workers = append(workers, driftWorker)
+
 	// PullRequest
 	urlProvider, err := controllerCfg.URLProvider()
 	if err != nil {
@@ -300,7 +305,8 @@ func setupWorkers(
 	renderer := pullrequest.NewNoOpRenderer()
 	evaluator := pullrequest.NewEvaluator(renderer, parsers, urlProvider, registry)
 	commenter := pullrequest.NewCommenter(false)
-	prWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, registry)
+	previews := pullrequest.NewPreviewEnvironments(clients)
+	prWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, previews, registry)
 	workers = append(workers, prWorker)
 
 	return workers, nil
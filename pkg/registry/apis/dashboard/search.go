@@ -124,6 +124,24 @@ func (s *SearchHandler) GetAPIRoutes(defs map[string]common.OpenAPIDefinition) *
 										Schema:      spec.Int64Property(),
 									},
 								},
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "facetInterval",
+										In:          "query",
+										Description: "count results in date buckets for a timestamp field, in the format {field}:{interval} (eg, updated:1w). The facetLimit sets the number of buckets (default 10)",
+										Required:    false,
+										Schema:      spec.ArrayProperty(spec.StringProperty()),
+									},
+								},
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "facetRange",
+										In:          "query",
+										Description: "count results in numeric ranges, in the format {field}:{boundary},{boundary}... (eg, views_last_30_days:1,10,100)",
+										Required:    false,
+										Schema:      spec.ArrayProperty(spec.StringProperty()),
+									},
+								},
 								{
 									ParameterProps: spec3.ParameterProps{
 										Name:        "tags",
@@ -160,6 +178,15 @@ func (s *SearchHandler) GetAPIRoutes(defs map[string]common.OpenAPIDefinition) *
 										Schema:      spec.StringProperty(),
 									},
 								},
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "dataSourceUid",
+										In:          "query",
+										Description: "find dashboards using a given datasource",
+										Required:    false,
+										Schema:      spec.StringProperty(),
+									},
+								},
 								{
 									ParameterProps: spec3.ParameterProps{
 										Name:        "permission",
@@ -261,6 +288,24 @@ func (s *SearchHandler) GetAPIRoutes(defs map[string]common.OpenAPIDefinition) *
 										Schema:      spec.BoolProperty(),
 									},
 								},
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "panelQuerySearch",
+										In:          "query",
+										Description: "[experimental] optionally include matches from panel queries and descriptions",
+										Required:    false,
+										Schema:      spec.BoolProperty(),
+									},
+								},
+								{
+									ParameterProps: spec3.ParameterProps{
+										Name:        "highlight",
+										In:          "query",
+										Description: "include the matching text fragments for each field",
+										Required:    false,
+										Schema:      spec.BoolProperty(),
+									},
+								},
 							},
 							Responses: &spec3.Responses{
 								ResponsesProps: spec3.ResponsesProps{
@@ -374,6 +419,60 @@ func permissionFromQueryParams(queryParams url.Values) dashboardaccess.Permissio
 	}
 }
 
+// facetField returns the index field for a facet, dashboard specific fields are stored with a prefix
+func facetField(field string) string {
+	if slices.Contains(builders.DashboardFields(), field) {
+		return resource.SEARCH_FIELD_PREFIX + field
+	}
+	return field
+}
+
+// addBucketFacets adds the date histogram (facetInterval=updated:1w) and numeric range (facetRange=panel_count:5,10) facets
+func addBucketFacets(searchRequest *resourcepb.ResourceSearchRequest, queryParams url.Values) error {
+	limit := int64(0) // the search server picks the number of date buckets
+	if parsed, err := strconv.Atoi(queryParams.Get("facetLimit")); err == nil && parsed > 0 {
+		limit = int64(min(parsed, 1000))
+	}
+
+	for _, v := range queryParams["facetInterval"] {
+		field, interval, ok := strings.Cut(v, ":")
+		if !ok || field == "" || interval == "" {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid facetInterval %q, expected {field}:{interval}", v))
+		}
+		if searchRequest.Facet == nil {
+			searchRequest.Facet = make(map[string]*resourcepb.ResourceSearchRequest_Facet)
+		}
+		searchRequest.Facet[field] = &resourcepb.ResourceSearchRequest_Facet{
+			Field:    facetField(field),
+			Limit:    limit,
+			Interval: interval,
+		}
+	}
+
+	for _, v := range queryParams["facetRange"] {
+		field, boundaries, ok := strings.Cut(v, ":")
+		if !ok || field == "" || boundaries == "" {
+			return apierrors.NewBadRequest(fmt.Sprintf("invalid facetRange %q, expected {field}:{boundary},{boundary}", v))
+		}
+		ranges := []float64{}
+		for _, b := range strings.Split(boundaries, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(b), 64)
+			if err != nil {
+				return apierrors.NewBadRequest(fmt.Sprintf("invalid facetRange boundary %q", b))
+			}
+			ranges = append(ranges, f)
+		}
+		if searchRequest.Facet == nil {
+			searchRequest.Facet = make(map[string]*resourcepb.ResourceSearchRequest_Facet)
+		}
+		searchRequest.Facet[field] = &resourcepb.ResourceSearchRequest_Facet{
+			Field:  facetField(field),
+			Ranges: ranges,
+		}
+	}
+	return nil
+}
+
 func (s *SearchHandler) DoSearch(w http.ResponseWriter, r *http.Request) {
 	ctx, span := s.tracer.Start(r.Context(), "dashboard.search")
 	defer span.End()
@@ -470,6 +569,9 @@ func convertHttpSearchRequestToResourceSearchRequest(queryParams url.Values, use
 			}
 		}
 	}
+	if queryParams.Has("highlight") && queryParams.Get("highlight") != "false" {
+		fields = append(fields, resource.SEARCH_FIELD_HIGHLIGHT)
+	}
 	searchRequest.Fields = fields
 	accessPermission := permissionFromQueryParams(queryParams)
 	if accessPermission > 0 {
@@ -523,12 +625,17 @@ func convertHttpSearchRequestToResourceSearchRequest(queryParams url.Values, use
 		searchRequest.Facet = make(map[string]*resourcepb.ResourceSearchRequest_Facet)
 		for _, v := range facets {
 			searchRequest.Facet[v] = &resourcepb.ResourceSearchRequest_Facet{
-				Field: v,
+				Field: facetField(v),
 				Limit: int64(facetLimit),
 			}
 		}
 	}
 
+	// Apply date histogram and numeric range facets
+	if err := addBucketFacets(searchRequest, queryParams); err != nil {
+		return nil, err
+	}
+
 	if v, ok := queryParams["tag"]; ok {
 		searchRequest.Options.Fields = append(searchRequest.Options.Fields, &resourcepb.Requirement{
 			Key:      "tags",
@@ -553,6 +660,14 @@ func convertHttpSearchRequestToResourceSearchRequest(queryParams url.Values, use
 		})
 	}
 
+	if v, ok := queryParams["dataSourceUid"]; ok {
+		searchRequest.Options.Fields = append(searchRequest.Options.Fields, &resourcepb.Requirement{
+			Key:      resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_DS_UIDS,
+			Operator: "=",
+			Values:   v,
+		})
+	}
+
 	if v, ok := queryParams["libraryPanel"]; ok {
 		searchRequest.Options.Fields = append(searchRequest.Options.Fields, &resourcepb.Requirement{
 			Key:      builders.DASHBOARD_LIBRARY_PANEL_REFERENCE,
@@ -609,6 +724,18 @@ func convertHttpSearchRequestToResourceSearchRequest(queryParams url.Values, use
 				Boost: 5,
 			})
 		}
+
+		if queryParams.Has("panelQuerySearch") && queryParams.Get("panelQuerySearch") != "false" {
+			searchRequest.QueryFields = append(searchRequest.QueryFields, &resourcepb.ResourceSearchRequest_QueryField{
+				Name:  resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_PANEL_QUERY, // fields.panel_query
+				Type:  resourcepb.QueryFieldType_TEXT,
+				Boost: 3,
+			}, &resourcepb.ResourceSearchRequest_QueryField{
+				Name:  resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_PANEL_DESCRIPTION, // fields.panel_description
+				Type:  resourcepb.QueryFieldType_TEXT,
+				Boost: 1,
+			})
+		}
 	}
 
 	// The names filter
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"net/http/httptest"
 	"net/url"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v0alpha1"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -1002,6 +1004,23 @@ func TestConvertHttpSearchRequestToResourceSearchRequest(t *testing.T) {
 				Federated: []*resourcepb.ResourceKey{folderKey},
 			},
 		},
+		"date histogram and numeric range facets": {
+			queryString: "facetInterval=updated:1w&facetRange=views_last_30_days:1,10,100&facetLimit=12",
+			expected: &resourcepb.ResourceSearchRequest{
+				Options: &resourcepb.ListOptions{Key: dashboardKey},
+				Query:   "",
+				Limit:   50,
+				Offset:  0,
+				Page:    1,
+				Explain: false,
+				Fields:  defaultFields,
+				Facet: map[string]*resourcepb.ResourceSearchRequest_Facet{
+					"updated":            {Field: "updated", Limit: 12, Interval: "1w"},
+					"views_last_30_days": {Field: "fields.views_last_30_days", Ranges: []float64{1, 10, 100}},
+				},
+				Federated: []*resourcepb.ResourceKey{folderKey},
+			},
+		},
 		"tag filter": {
 			queryString: "tag=tag1&tag=tag2",
 			expected: &resourcepb.ResourceSearchRequest{
@@ -1178,6 +1197,35 @@ func TestConvertHttpSearchRequestToResourceSearchRequest(t *testing.T) {
 				Federated: []*resourcepb.ResourceKey{folderKey},
 			},
 		},
+		"dataSourceUid filter": {
+			queryString: "dataSourceUid=ds1&dataSourceUid=ds2",
+			expected: &resourcepb.ResourceSearchRequest{
+				Options: &resourcepb.ListOptions{
+					Key:    dashboardKey,
+					Fields: []*resourcepb.Requirement{{Key: "fields.ds_uids", Operator: "=", Values: []string{"ds1", "ds2"}}},
+				},
+				Query:     "",
+				Limit:     50,
+				Offset:    0,
+				Page:      1,
+				Explain:   false,
+				Fields:    defaultFields,
+				Federated: []*resourcepb.ResourceKey{folderKey},
+			},
+		},
+		"query with highlight": {
+			queryString: "query=http_requests_total&highlight=true",
+			expected: &resourcepb.ResourceSearchRequest{
+				Options:   &resourcepb.ListOptions{Key: dashboardKey},
+				Query:     "http_requests_total",
+				Limit:     50,
+				Offset:    0,
+				Page:      1,
+				Explain:   false,
+				Fields:    append(slices.Clone(defaultFields), resource.SEARCH_FIELD_HIGHLIGHT),
+				Federated: []*resourcepb.ResourceKey{folderKey},
+			},
+		},
 	}
 
 	for name, tt := range tests {
@@ -1212,6 +1260,53 @@ func TestConvertHttpSearchRequestToResourceSearchRequest(t *testing.T) {
 	}
 }
 
+func TestConvertHttpSearchRequestInvalidFacets(t *testing.T) {
+	testUser := &user.SignedInUser{
+		Namespace: "test-namespace",
+		OrgID:     1,
+	}
+
+	for _, q := range []string{
+		"facetInterval=updated",
+		"facetInterval=:1w",
+		"facetRange=panel_count",
+		"facetRange=panel_count:1,x",
+	} {
+		t.Run(q, func(t *testing.T) {
+			queryParams, err := url.ParseQuery(q)
+			require.NoError(t, err)
+
+			_, err = convertHttpSearchRequestToResourceSearchRequest(queryParams, testUser, nil)
+			require.Error(t, err)
+			require.True(t, apierrors.IsBadRequest(err))
+		})
+	}
+}
+
+func TestConvertHttpSearchRequestPanelQuerySearch(t *testing.T) {
+	testUser := &user.SignedInUser{
+		Namespace: "test-namespace",
+		OrgID:     1,
+	}
+
+	queryParams, err := url.ParseQuery("query=http_requests_total&panelTitleSearch=true&panelQuerySearch=true")
+	require.NoError(t, err)
+
+	result, err := convertHttpSearchRequestToResourceSearchRequest(queryParams, testUser, nil)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, f := range result.QueryFields {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"title", "title", "title_phrase", "fields.panel_title", "fields.panel_query", "fields.panel_description"}, names)
+
+	queryParams.Set("panelQuerySearch", "false")
+	result, err = convertHttpSearchRequestToResourceSearchRequest(queryParams, testUser, nil)
+	require.NoError(t, err)
+	require.Len(t, result.QueryFields, 4)
+}
+
 // MockClient implements the ResourceIndexClient interface for testing
 type MockClient struct {
 	resourcepb.ResourceIndexClient
@@ -0,0 +1,231 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"gocloud.dev/blob"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// supportedSchemes are the bucket URL schemes that can be used by a repository.
+// Buckets on the local file system must be in a permitted provisioning path.
+var supportedSchemes = []string{"s3", "gs", "azblob", "file"}
+
+type extra struct {
+	decrypter repository.Decrypter
+	resolver  *local.LocalFolderResolver
+	allowed   AllowList
+
+	// Buckets are kept open, as the repository is built for every request.
+	// They are keyed by repository, and closed once the repository is updated or deleted.
+	mu      sync.Mutex
+	buckets map[string]*cachedBucket
+}
+
+type cachedBucket struct {
+	// version of the repository configuration the bucket was opened with
+	version string
+	bucket  *blob.Bucket
+}
+
+func Extra(decrypter repository.Decrypter, homePath string, permittedPrefixes []string, allowed AllowList) repository.Extra {
+	return &extra{
+		decrypter: decrypter,
+		resolver: &local.LocalFolderResolver{
+			PermittedPrefixes: permittedPrefixes,
+			HomePath:          safepath.Clean(homePath),
+		},
+		allowed: allowed,
+		buckets: make(map[string]*cachedBucket),
+	}
+}
+
+func (e *extra) Type() provisioning.RepositoryType {
+	return provisioning.BucketRepositoryType
+}
+
+func (e *extra) Build(ctx context.Context, r *provisioning.Repository) (repository.Repository, error) {
+	if r.Spec.Bucket == nil {
+		return nil, fmt.Errorf("bucket configuration is required for bucket repository type")
+	}
+
+	bucket, err := e.open(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewRepository(r, bucket)
+	repo.release = func() { e.release(bucketKey(r)) }
+	return repo, nil
+}
+
+func bucketKey(r *provisioning.Repository) string {
+	return r.Namespace + "/" + r.Name
+}
+
+// bucketVersion changes whenever the bucket or its credentials change.
+func bucketVersion(r *provisioning.Repository) string {
+	return strings.Join([]string{
+		strconv.FormatInt(r.Generation, 10),
+		r.Spec.Bucket.URL,
+		r.Spec.Bucket.Account,
+		r.Secure.Token.Name,
+	}, "\x00")
+}
+
+func (e *extra) open(ctx context.Context, r *provisioning.Repository) (resource.CDKBucket, error) {
+	key, version := bucketKey(r), bucketVersion(r)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cached, ok := e.buckets[key]
+	if ok && cached.version == version {
+		return cached.bucket, nil
+	}
+
+	// The buckets on the local file system need no credentials
+	var secret common.RawSecureValue
+	if !strings.HasPrefix(r.Spec.Bucket.URL, "file:") {
+		var err error
+		secret, err = e.decrypter(r).Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt bucket credentials: %w", err)
+		}
+		if secret.IsZero() {
+			return nil, fmt.Errorf("bucket credentials are required")
+		}
+	}
+
+	bucket, err := openBucket(ctx, r.Spec.Bucket, secret)
+	if err != nil {
+		return nil, fmt.Errorf("open bucket: %w", err)
+	}
+
+	// The repository was updated: the bucket opened with the previous configuration is not used anymore
+	if ok {
+		closeBucket(ctx, cached.bucket)
+	}
+	e.buckets[key] = &cachedBucket{version: version, bucket: bucket}
+	return bucket, nil
+}
+
+// release closes the bucket of a deleted repository.
+func (e *extra) release(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if cached, ok := e.buckets[key]; ok {
+		closeBucket(context.Background(), cached.bucket)
+		delete(e.buckets, key)
+	}
+}
+
+func closeBucket(ctx context.Context, bucket *blob.Bucket) {
+	if err := bucket.Close(); err != nil {
+		logging.FromContext(ctx).Warn("failed to close bucket", "error", err)
+	}
+}
+
+func (e *extra) Mutate(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (e *extra) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return Validate(ctx, obj, e.resolver, e.allowed)
+}
+
+// Validate validates the bucket repository configuration.
+// The resolver is needed to validate the path of buckets on the local file system against permitted prefixes.
+// The buckets of the cloud providers need credentials set on the repository, and their endpoint and region must be allowed.
+func Validate(_ context.Context, obj runtime.Object, resolver *local.LocalFolderResolver, allowed AllowList) field.ErrorList {
+	repo, ok := obj.(*provisioning.Repository)
+	if !ok {
+		return nil
+	}
+
+	if repo.Spec.Type != provisioning.BucketRepositoryType {
+		return nil
+	}
+
+	cfg := repo.Spec.Bucket
+	if cfg == nil {
+		return field.ErrorList{
+			field.Required(field.NewPath("spec", "bucket"), "bucket configuration is required for bucket repository type"),
+		}
+	}
+
+	var list field.ErrorList
+	urlPath := field.NewPath("spec", "bucket", "url")
+	if cfg.URL == "" {
+		list = append(list, field.Required(urlPath, "must enter a bucket URL"))
+	} else if u, err := url.Parse(cfg.URL); err != nil {
+		list = append(list, field.Invalid(urlPath, cfg.URL, err.Error()))
+	} else if !slices.Contains(supportedSchemes, u.Scheme) {
+		list = append(list, field.NotSupported(urlPath, u.Scheme, supportedSchemes))
+	} else if u.Scheme == "file" {
+		if resolver != nil {
+			if _, err := resolver.LocalPath(u.Path); err != nil {
+				list = append(list, field.Invalid(urlPath, cfg.URL, err.Error()))
+			}
+		}
+	} else {
+		list = append(list, validateCloudBucket(repo, u, allowed)...)
+	}
+
+	if cfg.Path != "" {
+		if err := safepath.IsSafe(cfg.Path); err != nil {
+			list = append(list, field.Invalid(field.NewPath("spec", "bucket", "path"), cfg.Path, err.Error()))
+		}
+	}
+
+	return list
+}
+
+// validateCloudBucket validates the configuration of a bucket of a cloud provider (S3, GCS or Azure Blob Storage).
+func validateCloudBucket(repo *provisioning.Repository, u *url.URL, allowed AllowList) field.ErrorList {
+	var list field.ErrorList
+	cfg := repo.Spec.Bucket
+	urlPath := field.NewPath("spec", "bucket", "url")
+
+	if u.User != nil || !bucketName.MatchString(u.Host) {
+		list = append(list, field.Invalid(urlPath, cfg.URL, "invalid bucket name"))
+	}
+	if err := allowed.validateQuery(u.Scheme, u.Query()); err != nil {
+		list = append(list, field.Invalid(urlPath, cfg.URL, err.Error()))
+	}
+
+	accountPath := field.NewPath("spec", "bucket", "account")
+	switch u.Scheme {
+	case "s3":
+		if cfg.Account == "" {
+			list = append(list, field.Required(accountPath, "the access key ID is required for S3 buckets"))
+		}
+	case "azblob":
+		if !azureAccount.MatchString(cfg.Account) {
+			list = append(list, field.Invalid(accountPath, cfg.Account, "a valid storage account name is required for Azure Blob Storage containers"))
+		}
+	}
+
+	// The credentials of the server environment are never used
+	if repo.Secure.Token.IsZero() {
+		list = append(list, field.Required(field.NewPath("secure", "token"), "the bucket credentials are required"))
+	}
+
+	return list
+}
@@ -0,0 +1,172 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/azureblob"
+	"gocloud.dev/blob/gcsblob"
+	"gocloud.dev/blob/s3blob"
+	"gocloud.dev/gcp"
+	"golang.org/x/oauth2/google"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// gcsScope is the OAuth scope of the service account keys used to read and write Google Cloud Storage buckets.
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// awsRegion matches the names of the AWS regions (e.g. `us-east-1` or `us-gov-west-1`).
+// The region is part of the S3 host name, so anything else could send the requests (and credentials) elsewhere.
+var awsRegion = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+
+// bucketName matches the names of the buckets and containers of the cloud providers.
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,220}[a-z0-9]$`)
+
+// azureAccount matches the names of the Azure storage accounts, which are part of the host name of the containers.
+var azureAccount = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// queryParameters are the bucket URL query parameters understood for each scheme.
+// The others (e.g. the credentials profile of S3) are rejected, as they would let a repository use the environment of the server.
+var queryParameters = map[string][]string{
+	"s3":     {"region", "endpoint", "use_path_style"},
+	"gs":     {},
+	"azblob": {"domain"},
+}
+
+// AllowList restricts the bucket URLs that can be used by a repository.
+type AllowList struct {
+	// Endpoints are the hosts that can be set as the endpoint of S3 buckets or the domain of Azure Blob Storage containers.
+	// When empty, the default endpoints of the cloud providers are the only ones allowed.
+	Endpoints []string
+
+	// Regions are the AWS regions that can be set on S3 buckets. When empty, any AWS region is allowed.
+	Regions []string
+}
+
+// validateQuery checks the query parameters of a bucket URL against the parameters of its scheme and the allow list.
+func (a AllowList) validateQuery(scheme string, query url.Values) error {
+	known, ok := queryParameters[scheme]
+	if !ok {
+		return nil
+	}
+
+	for key := range query {
+		if !slices.Contains(known, key) {
+			return fmt.Errorf("the %q parameter is not supported for %s buckets", key, scheme)
+		}
+	}
+
+	if region := query.Get("region"); region != "" {
+		if !awsRegion.MatchString(region) {
+			return fmt.Errorf("invalid region %q", region)
+		}
+		if len(a.Regions) > 0 && !slices.Contains(a.Regions, region) {
+			return fmt.Errorf("the region %q is not allowed", region)
+		}
+	}
+
+	for _, key := range []string{"endpoint", "domain"} {
+		if !query.Has(key) {
+			continue
+		}
+		if err := a.validateEndpoint(query.Get(key)); err != nil {
+			return err
+		}
+	}
+
+	if v := query.Get("use_path_style"); v != "" {
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Errorf("invalid use_path_style %q", v)
+		}
+	}
+
+	return nil
+}
+
+// validateEndpoint checks an endpoint (`https://minio.example.com`) or a domain (`blob.core.example.com`) against the allow list.
+func (a AllowList) validateEndpoint(endpoint string) error {
+	host := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		if u.Scheme != "https" {
+			return fmt.Errorf("the endpoint %q must use https", endpoint)
+		}
+		host = u.Host
+	}
+
+	if host == "" || !slices.Contains(a.Endpoints, host) {
+		return fmt.Errorf("the endpoint %q is not allowed", endpoint)
+	}
+	return nil
+}
+
+// openBucket opens the bucket of a repository with the credentials set on the repository.
+// The credentials of the environment of the Grafana server (instance roles, default credentials files...) are never used.
+// The URL must have been validated before.
+func openBucket(ctx context.Context, cfg *provisioning.BucketRepositoryConfig, secret common.RawSecureValue) (*blob.Bucket, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+
+	switch u.Scheme {
+	case "s3":
+		opts := s3.Options{
+			Region:      query.Get("region"),
+			Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(cfg.Account, string(secret), "")),
+		}
+		if endpoint := query.Get("endpoint"); endpoint != "" {
+			opts.BaseEndpoint = aws.String(endpoint)
+		}
+		if v := query.Get("use_path_style"); v != "" {
+			opts.UsePathStyle, _ = strconv.ParseBool(v)
+		}
+		return s3blob.OpenBucket(ctx, s3.New(opts), u.Host, nil)
+
+	case "gs":
+		// Only service account keys are accepted: other credential types can read files or call URLs from the server
+		creds, err := google.CredentialsFromJSONWithType(ctx, []byte(secret), google.ServiceAccount, gcsScope)
+		if err != nil {
+			return nil, fmt.Errorf("read service account key: %w", err)
+		}
+		client, err := gcp.NewHTTPClient(gcp.DefaultTransport(), gcp.CredentialsTokenSource(creds))
+		if err != nil {
+			return nil, err
+		}
+		return gcsblob.OpenBucket(ctx, client, u.Host, nil)
+
+	case "azblob":
+		cred, err := azblob.NewSharedKeyCredential(cfg.Account, string(secret))
+		if err != nil {
+			return nil, fmt.Errorf("read account key: %w", err)
+		}
+		domain := query.Get("domain")
+		if domain == "" {
+			domain = "blob.core.windows.net"
+		}
+		containerURL := fmt.Sprintf("https://%s.%s/%s", cfg.Account, domain, u.Host)
+		client, err := container.NewClientWithSharedKeyCredential(containerURL, cred, nil)
+		if err != nil {
+			return nil, err
+		}
+		return azureblob.OpenBucket(ctx, client, nil)
+
+	case "file":
+		return resource.OpenBlobBucket(ctx, cfg.URL)
+	}
+
+	return nil, fmt.Errorf("unsupported bucket scheme: %s", u.Scheme)
+}
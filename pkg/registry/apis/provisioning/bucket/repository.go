@@ -0,0 +1,441 @@
+package bucket
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	"golang.org/x/sync/errgroup"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// maxConcurrentAttributes is the number of object attributes read in parallel when listing the tree.
+const maxConcurrentAttributes = 10
+
+// keepFile is the (empty) object that marks a directory, as object storage has no directories.
+const keepFile = ".keep"
+
+var (
+	_ repository.Repository = (*bucketRepository)(nil)
+	_ repository.Writer     = (*bucketRepository)(nil)
+	_ repository.Reader     = (*bucketRepository)(nil)
+	_ repository.Hooks      = (*bucketRepository)(nil)
+)
+
+// bucketRepository is a repository backed by an object storage bucket (S3, GCS, Azure Blob...).
+// Buckets have no history, so change detection relies on the ETag of the objects: it is used as the file hash,
+// and a sync only reads the objects whose ETag changed since the last one.
+type bucketRepository struct {
+	config *provisioning.Repository
+	bucket resource.CDKBucket
+
+	// prefix of the repository objects in the bucket, with a trailing slash if not empty
+	prefix string
+
+	// release closes the bucket once the repository is deleted
+	release func()
+}
+
+func NewRepository(config *provisioning.Repository, bucket resource.CDKBucket) *bucketRepository {
+	r := &bucketRepository{
+		config: config,
+		bucket: bucket,
+	}
+
+	if config.Spec.Bucket != nil {
+		r.prefix = safepath.Clean(strings.Trim(config.Spec.Bucket.Path, "/"))
+		if r.prefix != "" {
+			r.prefix += "/"
+		}
+	}
+
+	return r
+}
+
+func (r *bucketRepository) Config() *provisioning.Repository {
+	return r.config
+}
+
+// OnCreate implements repository.Hooks.
+func (r *bucketRepository) OnCreate(_ context.Context) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+// OnUpdate implements repository.Hooks.
+// The bucket opened with the previous configuration has already been closed when the repository was built.
+func (r *bucketRepository) OnUpdate(_ context.Context) ([]map[string]interface{}, error) {
+	return nil, nil
+}
+
+// OnDelete implements repository.Hooks.
+func (r *bucketRepository) OnDelete(_ context.Context) error {
+	if r.release != nil {
+		r.release()
+	}
+	return nil
+}
+
+// Test implements provisioning.Repository.
+// NOTE: Validate has been called (and passed) before this function should be called
+func (r *bucketRepository) Test(ctx context.Context) (*provisioning.TestResults, error) {
+	_, _, err := r.bucket.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: r.prefix})
+	if err != nil {
+		return repository.FromFieldError(field.Invalid(
+			field.NewPath("spec", "bucket", "url"), r.config.Spec.Bucket.URL, fmt.Sprintf("unable to list the bucket: %s", err))), nil
+	}
+
+	return &provisioning.TestResults{
+		Code:    http.StatusOK,
+		Success: true,
+	}, nil
+}
+
+func (r *bucketRepository) validateRequest(ref string) error {
+	if ref != "" {
+		return apierrors.NewBadRequest("bucket repository does not support ref")
+	}
+
+	return nil
+}
+
+func (r *bucketRepository) key(filePath string) string {
+	return r.prefix + strings.TrimPrefix(filePath, "/")
+}
+
+// Read implements provisioning.Repository.
+func (r *bucketRepository) Read(ctx context.Context, filePath string, ref string) (*repository.FileInfo, error) {
+	if err := r.validateRequest(ref); err != nil {
+		return nil, err
+	}
+
+	if safepath.IsDir(filePath) {
+		exists, err := r.dirExists(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, repository.ErrFileNotFound
+		}
+		return &repository.FileInfo{Path: filePath}, nil
+	}
+
+	key := r.key(filePath)
+	attrs, err := r.bucket.Attributes(ctx, key)
+	if err != nil {
+		return nil, mapBucketError(err, "read attributes")
+	}
+
+	data, err := r.bucket.ReadAll(ctx, key)
+	if err != nil {
+		return nil, mapBucketError(err, "read object")
+	}
+
+	return &repository.FileInfo{
+		Path: filePath,
+		Data: data,
+		Hash: etag(attrs),
+		Modified: &metav1.Time{
+			Time: attrs.ModTime,
+		},
+	}, nil
+}
+
+// ReadTree implements provisioning.Repository.
+func (r *bucketRepository) ReadTree(ctx context.Context, ref string) ([]repository.FileTreeEntry, error) {
+	if err := r.validateRequest(ref); err != nil {
+		return nil, err
+	}
+
+	files := make([]repository.FileTreeEntry, 0, 100)
+	dirs := make(map[string]struct{})
+	iter := r.bucket.List(&blob.ListOptions{Prefix: r.prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, mapBucketError(err, "list objects")
+		}
+
+		p := strings.TrimPrefix(obj.Key, r.prefix)
+		if p == "" {
+			continue
+		}
+		for dir := safepath.Dir(p); dir != ""; dir = safepath.Dir(dir) {
+			dirs[dir] = struct{}{}
+		}
+		if obj.IsDir || safepath.IsDir(p) {
+			dirs[p] = struct{}{}
+			continue
+		}
+
+		files = append(files, repository.FileTreeEntry{
+			Path: p,
+			Size: obj.Size,
+			Blob: true,
+		})
+	}
+
+	// Listing does not return ETags, they need to be read from the attributes of each object
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentAttributes)
+	for i := range files {
+		g.Go(func() error {
+			attrs, err := r.bucket.Attributes(gctx, r.key(files[i].Path))
+			if err != nil {
+				return mapBucketError(err, fmt.Sprintf("read attributes of %s", files[i].Path))
+			}
+			files[i].Hash = etag(attrs)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]repository.FileTreeEntry, 0, len(files)+len(dirs))
+	for dir := range dirs {
+		entries = append(entries, repository.FileTreeEntry{Path: dir})
+	}
+	entries = append(entries, files...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries, nil
+}
+
+func (r *bucketRepository) Create(ctx context.Context, filePath string, ref string, data []byte, comment string) error {
+	if err := r.validateRequest(ref); err != nil {
+		return err
+	}
+
+	// Create .keep file if it is a directory
+	if safepath.IsDir(filePath) {
+		if data != nil {
+			return apierrors.NewBadRequest("data cannot be provided for a directory")
+		}
+		filePath = safepath.Join(filePath, keepFile)
+		data = []byte{}
+	}
+
+	exists, err := r.fileExists(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return repository.ErrFileAlreadyExists
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) Update(ctx context.Context, filePath string, ref string, data []byte, comment string) error {
+	if err := r.validateRequest(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(filePath) {
+		return apierrors.NewBadRequest("cannot update a directory")
+	}
+
+	exists, err := r.fileExists(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrFileNotFound
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) Write(ctx context.Context, filePath string, ref string, data []byte, comment string) error {
+	if err := r.validateRequest(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(filePath) {
+		filePath = safepath.Join(filePath, keepFile)
+		data = []byte{}
+	}
+
+	return r.write(ctx, filePath, data)
+}
+
+func (r *bucketRepository) Delete(ctx context.Context, filePath string, ref string, comment string) error {
+	if err := r.validateRequest(ref); err != nil {
+		return err
+	}
+
+	// if it is a folder, delete all of its contents
+	if safepath.IsDir(filePath) {
+		keys, err := r.listKeys(ctx, filePath)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return repository.ErrFileNotFound
+		}
+		for _, key := range keys {
+			if err := r.bucket.Delete(ctx, key); err != nil {
+				return mapBucketError(err, "delete object")
+			}
+		}
+		return nil
+	}
+
+	if err := r.bucket.Delete(ctx, r.key(filePath)); err != nil {
+		return mapBucketError(err, "delete object")
+	}
+
+	return nil
+}
+
+// Move copies the objects to their new path then deletes them, as object storage has no rename.
+func (r *bucketRepository) Move(ctx context.Context, oldPath, newPath, ref, comment string) error {
+	if err := r.validateRequest(ref); err != nil {
+		return err
+	}
+
+	if safepath.IsDir(oldPath) != safepath.IsDir(newPath) {
+		return apierrors.NewBadRequest("cannot move between file and directory types")
+	}
+
+	var moves map[string]string // old key -> new key
+	if safepath.IsDir(oldPath) {
+		keys, err := r.listKeys(ctx, oldPath)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return repository.ErrFileNotFound
+		}
+		exists, err := r.dirExists(ctx, newPath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return repository.ErrFileAlreadyExists
+		}
+
+		moves = make(map[string]string, len(keys))
+		for _, key := range keys {
+			moves[key] = r.key(newPath) + strings.TrimPrefix(key, r.key(oldPath))
+		}
+	} else {
+		exists, err := r.fileExists(ctx, oldPath)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return repository.ErrFileNotFound
+		}
+		exists, err = r.fileExists(ctx, newPath)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return repository.ErrFileAlreadyExists
+		}
+
+		moves = map[string]string{r.key(oldPath): r.key(newPath)}
+	}
+
+	for oldKey, newKey := range moves {
+		data, err := r.bucket.ReadAll(ctx, oldKey)
+		if err != nil {
+			return mapBucketError(err, "read object")
+		}
+		if err := r.bucket.WriteAll(ctx, newKey, data, nil); err != nil {
+			return mapBucketError(err, "write object")
+		}
+	}
+	for oldKey := range moves {
+		if err := r.bucket.Delete(ctx, oldKey); err != nil {
+			return mapBucketError(err, "delete object")
+		}
+	}
+
+	return nil
+}
+
+func (r *bucketRepository) write(ctx context.Context, filePath string, data []byte) error {
+	if err := r.bucket.WriteAll(ctx, r.key(filePath), data, nil); err != nil {
+		return mapBucketError(err, "write object")
+	}
+	return nil
+}
+
+func (r *bucketRepository) fileExists(ctx context.Context, filePath string) (bool, error) {
+	_, err := r.bucket.Attributes(ctx, r.key(filePath))
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, mapBucketError(err, "read attributes")
+	}
+	return true, nil
+}
+
+func (r *bucketRepository) dirExists(ctx context.Context, dirPath string) (bool, error) {
+	found, _, err := r.bucket.ListPage(ctx, blob.FirstPageToken, 1, &blob.ListOptions{Prefix: r.key(dirPath)})
+	if err != nil {
+		return false, mapBucketError(err, "list objects")
+	}
+	return len(found) > 0, nil
+}
+
+// listKeys returns the keys of all the objects in a directory
+func (r *bucketRepository) listKeys(ctx context.Context, dirPath string) ([]string, error) {
+	var keys []string
+	iter := r.bucket.List(&blob.ListOptions{Prefix: r.key(dirPath)})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return keys, nil
+		}
+		if err != nil {
+			return nil, mapBucketError(err, "list objects")
+		}
+		if !obj.IsDir {
+			keys = append(keys, obj.Key)
+		}
+	}
+}
+
+// etag returns the ETag of an object without quotes, or its MD5 when the driver has no ETag
+func etag(attrs *blob.Attributes) string {
+	if tag := strings.Trim(strings.TrimPrefix(attrs.ETag, "W/"), `"`); tag != "" {
+		return tag
+	}
+	return hex.EncodeToString(attrs.MD5)
+}
+
+func mapBucketError(err error, action string) error {
+	switch gcerrors.Code(err) {
+	case gcerrors.NotFound:
+		return repository.ErrFileNotFound
+	case gcerrors.PermissionDenied:
+		return repository.ErrPermissionDenied
+	case gcerrors.Unavailable:
+		return repository.ErrServerUnavailable
+	default:
+		return fmt.Errorf("%s: %w", action, err)
+	}
+}
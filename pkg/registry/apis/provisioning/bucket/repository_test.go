@@ -0,0 +1,264 @@
+package bucket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+)
+
+func newTestRepository(t *testing.T, path string) *bucketRepository {
+	t.Helper()
+	bucket := memblob.OpenBucket(nil)
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	return NewRepository(&provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "artifacts"},
+		Spec: provisioning.RepositorySpec{
+			Type:   provisioning.BucketRepositoryType,
+			Bucket: &provisioning.BucketRepositoryConfig{URL: "mem://", Path: path},
+		},
+	}, bucket)
+}
+
+func TestBucketRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("read tree and files under the path", func(t *testing.T) {
+		repo := newTestRepository(t, "/grafana/")
+		require.NoError(t, repo.bucket.WriteAll(ctx, "grafana/a.json", []byte(`{"a":1}`), nil))
+		require.NoError(t, repo.bucket.WriteAll(ctx, "grafana/team/b.json", []byte(`{"b":1}`), nil))
+		require.NoError(t, repo.bucket.WriteAll(ctx, "other/c.json", []byte(`{"c":1}`), nil))
+
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, tree, 3)
+		require.Equal(t, "a.json", tree[0].Path)
+		require.True(t, tree[0].Blob)
+		require.NotEmpty(t, tree[0].Hash)
+		require.Equal(t, "team/", tree[1].Path)
+		require.False(t, tree[1].Blob)
+		require.Equal(t, "team/b.json", tree[2].Path)
+
+		info, err := repo.Read(ctx, "team/b.json", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"b":1}`), info.Data)
+		require.Equal(t, tree[2].Hash, info.Hash, "read and tree hashes must match for change detection")
+
+		_, err = repo.Read(ctx, "missing.json", "")
+		require.ErrorIs(t, err, repository.ErrFileNotFound)
+
+		_, err = repo.Read(ctx, "team/", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("hash changes when the object changes", func(t *testing.T) {
+		repo := newTestRepository(t, "")
+		require.NoError(t, repo.Create(ctx, "a.json", "", []byte(`{"a":1}`), "create"))
+		before, err := repo.Read(ctx, "a.json", "")
+		require.NoError(t, err)
+
+		require.NoError(t, repo.Update(ctx, "a.json", "", []byte(`{"a":22}`), "update"))
+		after, err := repo.Read(ctx, "a.json", "")
+		require.NoError(t, err)
+		require.NotEqual(t, before.Hash, after.Hash)
+	})
+
+	t.Run("create, update and write", func(t *testing.T) {
+		repo := newTestRepository(t, "grafana")
+		require.NoError(t, repo.Create(ctx, "a.json", "", []byte(`{}`), "create"))
+		require.ErrorIs(t, repo.Create(ctx, "a.json", "", []byte(`{}`), "create"), repository.ErrFileAlreadyExists)
+		require.ErrorIs(t, repo.Update(ctx, "b.json", "", []byte(`{}`), "update"), repository.ErrFileNotFound)
+		require.NoError(t, repo.Write(ctx, "b.json", "", []byte(`{}`), "write"))
+
+		// directories are kept with an empty object
+		require.NoError(t, repo.Create(ctx, "team/", "", nil, "create"))
+		data, err := repo.bucket.ReadAll(ctx, "grafana/team/.keep")
+		require.NoError(t, err)
+		require.Empty(t, data)
+
+		err = repo.Create(ctx, "x.json", "main", []byte(`{}`), "create")
+		require.True(t, apierrors.IsBadRequest(err), "refs are not supported")
+	})
+
+	t.Run("delete and move", func(t *testing.T) {
+		repo := newTestRepository(t, "")
+		require.NoError(t, repo.Write(ctx, "team/a.json", "", []byte(`{"a":1}`), "write"))
+		require.NoError(t, repo.Write(ctx, "team/b.json", "", []byte(`{"b":1}`), "write"))
+		require.NoError(t, repo.Write(ctx, "c.json", "", []byte(`{"c":1}`), "write"))
+
+		require.NoError(t, repo.Move(ctx, "c.json", "d.json", "", "move"))
+		_, err := repo.Read(ctx, "c.json", "")
+		require.ErrorIs(t, err, repository.ErrFileNotFound)
+		info, err := repo.Read(ctx, "d.json", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"c":1}`), info.Data)
+
+		require.NoError(t, repo.Move(ctx, "team/", "squad/", "", "move"))
+		info, err = repo.Read(ctx, "squad/b.json", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte(`{"b":1}`), info.Data)
+		_, err = repo.Read(ctx, "team/", "")
+		require.ErrorIs(t, err, repository.ErrFileNotFound)
+
+		err = repo.Move(ctx, "squad/", "e.json", "", "move")
+		require.True(t, apierrors.IsBadRequest(err))
+
+		require.NoError(t, repo.Delete(ctx, "squad/", "", "delete"))
+		require.NoError(t, repo.Delete(ctx, "d.json", "", "delete"))
+		require.ErrorIs(t, repo.Delete(ctx, "d.json", "", "delete"), repository.ErrFileNotFound)
+
+		tree, err := repo.ReadTree(ctx, "")
+		require.NoError(t, err)
+		require.Empty(t, tree)
+	})
+
+	t.Run("test lists the bucket", func(t *testing.T) {
+		repo := newTestRepository(t, "")
+		res, err := repo.Test(ctx)
+		require.NoError(t, err)
+		require.True(t, res.Success)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	resolver := &local.LocalFolderResolver{PermittedPrefixes: []string{"/var/lib/grafana/dashboards"}, HomePath: "/usr/share/grafana"}
+	allowed := AllowList{Endpoints: []string{"minio.example.com"}}
+	credentials := provisioning.SecureValues{Token: common.InlineSecureValue{Name: "bucket-credentials"}}
+	validateWith := func(cfg *provisioning.BucketRepositoryConfig, secure provisioning.SecureValues) field.ErrorList {
+		return Validate(context.Background(), &provisioning.Repository{
+			Spec:   provisioning.RepositorySpec{Type: provisioning.BucketRepositoryType, Bucket: cfg},
+			Secure: secure,
+		}, resolver, allowed)
+	}
+	validate := func(cfg *provisioning.BucketRepositoryConfig) field.ErrorList {
+		return validateWith(cfg, credentials)
+	}
+
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "s3://dashboards?region=us-east-1", Account: "AKIAEXAMPLE", Path: "grafana"}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "s3://dashboards?region=us-east-1&endpoint=https://minio.example.com&use_path_style=true", Account: "AKIAEXAMPLE"}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "azblob://dashboards", Account: "grafanastorage"}))
+	require.Empty(t, validate(&provisioning.BucketRepositoryConfig{URL: "gs://dashboards"}))
+	require.Empty(t, validateWith(&provisioning.BucketRepositoryConfig{URL: "file:///var/lib/grafana/dashboards/build"}, provisioning.SecureValues{}))
+
+	errs := validate(nil)
+	require.Len(t, errs, 1)
+	require.Equal(t, "spec.bucket", errs[0].Field)
+
+	errs = validate(&provisioning.BucketRepositoryConfig{})
+	require.Len(t, errs, 1)
+	require.Equal(t, field.ErrorTypeRequired, errs[0].Type)
+
+	errs = validate(&provisioning.BucketRepositoryConfig{URL: "mem://"})
+	require.Len(t, errs, 1)
+	require.Equal(t, field.ErrorTypeNotSupported, errs[0].Type)
+
+	errs = validate(&provisioning.BucketRepositoryConfig{URL: "file:///etc"})
+	require.Len(t, errs, 1)
+	require.Equal(t, "spec.bucket.url", errs[0].Field)
+
+	errs = validate(&provisioning.BucketRepositoryConfig{URL: "gs://dashboards", Path: "../other"})
+	require.Len(t, errs, 1)
+	require.Equal(t, "spec.bucket.path", errs[0].Field)
+
+	t.Run("the credentials must be set on the repository", func(t *testing.T) {
+		errs := validateWith(&provisioning.BucketRepositoryConfig{URL: "gs://dashboards"}, provisioning.SecureValues{})
+		require.Len(t, errs, 1)
+		require.Equal(t, "secure.token", errs[0].Field)
+
+		errs = validate(&provisioning.BucketRepositoryConfig{URL: "s3://dashboards?region=us-east-1"})
+		require.Len(t, errs, 1)
+		require.Equal(t, "spec.bucket.account", errs[0].Field)
+
+		errs = validate(&provisioning.BucketRepositoryConfig{URL: "azblob://dashboards", Account: "evil.example.com/"})
+		require.Len(t, errs, 1)
+		require.Equal(t, "spec.bucket.account", errs[0].Field)
+	})
+
+	t.Run("the endpoint and region overrides must be allowed", func(t *testing.T) {
+		for _, u := range []string{
+			"s3://dashboards?region=us-east-1&endpoint=https://attacker.example.com",
+			"s3://dashboards?region=us-east-1&endpoint=http://minio.example.com",
+			"s3://dashboards?region=attacker.example.com%23",
+			"s3://dashboards?region=us-east-1&profile=default",
+			"s3://dashboards?region=us-east-1&awssdk=v1",
+			"azblob://dashboards?domain=attacker.example.com",
+			"gs://dashboards?access_id=someone",
+		} {
+			errs := validate(&provisioning.BucketRepositoryConfig{URL: u, Account: "grafanastorage"})
+			require.Len(t, errs, 1, u)
+			require.Equal(t, "spec.bucket.url", errs[0].Field, u)
+		}
+
+		restricted := AllowList{Regions: []string{"eu-west-1"}}
+		errs := Validate(context.Background(), &provisioning.Repository{
+			Spec: provisioning.RepositorySpec{
+				Type:   provisioning.BucketRepositoryType,
+				Bucket: &provisioning.BucketRepositoryConfig{URL: "s3://dashboards?region=us-east-1", Account: "AKIAEXAMPLE"},
+			},
+			Secure: credentials,
+		}, resolver, restricted)
+		require.Len(t, errs, 1)
+		require.Equal(t, "spec.bucket.url", errs[0].Field)
+	})
+}
+
+func TestExtraBuckets(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	e := Extra(nil, dir, []string{dir}, AllowList{}).(*extra)
+
+	newRepo := func(name string, generation int64) *provisioning.Repository {
+		return &provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Generation: generation},
+			Spec: provisioning.RepositorySpec{
+				Type:   provisioning.BucketRepositoryType,
+				Bucket: &provisioning.BucketRepositoryConfig{URL: "file://" + dir},
+			},
+		}
+	}
+	requireClosed := func(t *testing.T, repo repository.Repository, closed bool) {
+		t.Helper()
+		_, _, err := repo.(*bucketRepository).bucket.ListPage(ctx, blob.FirstPageToken, 1, nil)
+		if closed {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	first, err := e.Build(ctx, newRepo("artifacts", 1))
+	require.NoError(t, err)
+	again, err := e.Build(ctx, newRepo("artifacts", 1))
+	require.NoError(t, err)
+	require.Same(t, first.(*bucketRepository).bucket, again.(*bucketRepository).bucket, "buckets are reused between requests")
+
+	t.Run("the bucket is closed once the repository is updated", func(t *testing.T) {
+		updated, err := e.Build(ctx, newRepo("artifacts", 2))
+		require.NoError(t, err)
+		requireClosed(t, first, true)
+		requireClosed(t, updated, false)
+	})
+
+	t.Run("the bucket is closed once the repository is deleted", func(t *testing.T) {
+		other, err := e.Build(ctx, newRepo("other", 1))
+		require.NoError(t, err)
+		deleted, err := e.Build(ctx, newRepo("artifacts", 2))
+		require.NoError(t, err)
+
+		require.NoError(t, deleted.(repository.Hooks).OnDelete(ctx))
+		requireClosed(t, deleted, true)
+		requireClosed(t, other, false)
+		require.Len(t, e.buckets, 1)
+	})
+}
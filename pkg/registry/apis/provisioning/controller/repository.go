@@ -341,6 +341,33 @@ func (rc *RepositoryController) shouldResync(ctx context.Context, obj *provision
 	return obj.Spec.Sync.Enabled && syncAge >= (syncInterval-tolerance) && !pendingForTooLong && !isRunning
 }
 
+// shouldCheckDrift returns true when drift detection is enabled and the last check is older than its interval.
+// Drift is only checked between syncs, as a running sync would be reported as drift.
+func (rc *RepositoryController) shouldCheckDrift(obj *provisioning.Repository) bool {
+	if obj.Spec.Drift == nil || !obj.Spec.Drift.Enabled || !obj.Spec.Sync.Enabled {
+		return false
+	}
+
+	// don't check drift until the first sync has finished
+	if obj.Status.Sync.Finished == 0 ||
+		obj.Status.Sync.State == provisioning.JobStatePending ||
+		obj.Status.Sync.State == provisioning.JobStateWorking {
+		return false
+	}
+
+	if obj.Status.Drift == nil || obj.Status.Drift.Checked == 0 {
+		return true
+	}
+
+	driftInterval := time.Duration(obj.Spec.Drift.IntervalSeconds) * time.Second
+	if driftInterval < rc.minSyncInterval {
+		driftInterval = rc.minSyncInterval
+	}
+	tolerance := time.Second
+
+	return time.Since(time.UnixMilli(obj.Status.Drift.Checked)) >= (driftInterval - tolerance)
+}
+
 func (rc *RepositoryController) runHooks(ctx context.Context, repo repository.Repository, obj *provisioning.Repository) ([]map[string]interface{}, error) {
 	logger := logging.FromContext(ctx)
 	hooks, _ := repo.(repository.Hooks)
@@ -489,6 +516,33 @@ func (rc *RepositoryController) addSyncJob(ctx context.Context, obj *provisionin
 	return nil
 }
 
+func (rc *RepositoryController) addDriftJob(ctx context.Context, obj *provisioning.Repository) error {
+	ctx, span := rc.tracer.Start(ctx, "provisioning.controller.add_drift_job")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("repository", obj.GetName()),
+		attribute.String("namespace", obj.Namespace),
+	)
+
+	job, err := rc.jobs.Insert(ctx, obj.Namespace, provisioning.JobSpec{
+		Repository: obj.GetName(),
+		Action:     provisioning.JobActionDrift,
+		Drift:      &provisioning.DriftJobOptions{},
+	})
+	if apierrors.IsAlreadyExists(err) {
+		logging.FromContext(ctx).Info("drift job already exists")
+		return nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error adding drift job: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("job.name", job.Name))
+	return nil
+}
+
 func (rc *RepositoryController) determineSyncStatusOps(obj *provisioning.Repository, syncOptions *provisioning.SyncJobOptions, healthStatus provisioning.HealthStatus) []map[string]interface{} {
 	const unhealthyMessage = "Repository is unhealthy"
 
@@ -579,6 +633,7 @@ func (rc *RepositoryController) process(item *queueItem) error {
 
 	shouldResync := rc.shouldResync(ctx, obj)
 	shouldCheckHealth := rc.healthChecker.ShouldCheckHealth(obj)
+	shouldCheckDrift := rc.shouldCheckDrift(obj)
 	hasSpecChanged := obj.Generation != obj.Status.ObservedGeneration
 	var patchOperations []map[string]interface{}
 
@@ -602,6 +657,8 @@ func (rc *RepositoryController) process(item *queueItem) error {
 		logger.Info("sync interval triggered", "sync_interval", time.Duration(obj.Spec.Sync.IntervalSeconds)*time.Second, "sync_status", obj.Status.Sync)
 	case shouldCheckHealth:
 		logger.Info("health is stale", "health_status", obj.Status.Health.Healthy)
+	case shouldCheckDrift:
+		logger.Info("drift check interval triggered", "drift_spec", obj.Spec.Drift)
 	case forceProcessForUnblock:
 		logger.Info("repository was blocked but now within quota, processing to unblock")
 	case shouldGenerateToken:
@@ -787,6 +844,13 @@ func (rc *RepositoryController) process(item *queueItem) error {
 		}
 	}
 
+	// Drift is checked on its own interval, and never together with a sync
+	if shouldCheckDrift && syncOptions == nil && healthStatus.Healthy && !isOverQuota {
+		if err := rc.addDriftJob(ctx, obj); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
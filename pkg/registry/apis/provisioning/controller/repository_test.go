@@ -710,6 +710,106 @@ func TestRepositoryController_shouldResync_StaleSyncStatus(t *testing.T) {
 	}
 }
 
+func TestRepositoryController_shouldCheckDrift(t *testing.T) {
+	synced := provisioning.SyncStatus{
+		State:    provisioning.JobStateSuccess,
+		Finished: time.Now().Add(-time.Hour).UnixMilli(),
+	}
+	spec := provisioning.RepositorySpec{
+		Sync:  provisioning.SyncOptions{Enabled: true, IntervalSeconds: 60},
+		Drift: &provisioning.DriftOptions{Enabled: true, IntervalSeconds: 600},
+	}
+
+	testCases := []struct {
+		name     string
+		spec     provisioning.RepositorySpec
+		status   provisioning.RepositoryStatus
+		expected bool
+	}{
+		{
+			name:     "drift not configured",
+			spec:     provisioning.RepositorySpec{Sync: spec.Sync},
+			status:   provisioning.RepositoryStatus{Sync: synced},
+			expected: false,
+		},
+		{
+			name: "drift disabled",
+			spec: provisioning.RepositorySpec{
+				Sync:  spec.Sync,
+				Drift: &provisioning.DriftOptions{Enabled: false},
+			},
+			status:   provisioning.RepositoryStatus{Sync: synced},
+			expected: false,
+		},
+		{
+			name: "sync disabled",
+			spec: provisioning.RepositorySpec{
+				Drift: spec.Drift,
+			},
+			status:   provisioning.RepositoryStatus{Sync: synced},
+			expected: false,
+		},
+		{
+			name:     "never synced",
+			spec:     spec,
+			expected: false,
+		},
+		{
+			name: "sync is running",
+			spec: spec,
+			status: provisioning.RepositoryStatus{Sync: provisioning.SyncStatus{
+				State:    provisioning.JobStateWorking,
+				Finished: synced.Finished,
+			}},
+			expected: false,
+		},
+		{
+			name:     "never checked",
+			spec:     spec,
+			status:   provisioning.RepositoryStatus{Sync: synced},
+			expected: true,
+		},
+		{
+			name: "checked recently",
+			spec: spec,
+			status: provisioning.RepositoryStatus{
+				Sync:  synced,
+				Drift: &provisioning.DriftStatus{Checked: time.Now().Add(-time.Minute).UnixMilli()},
+			},
+			expected: false,
+		},
+		{
+			name: "interval elapsed",
+			spec: spec,
+			status: provisioning.RepositoryStatus{
+				Sync:  synced,
+				Drift: &provisioning.DriftStatus{Checked: time.Now().Add(-11 * time.Minute).UnixMilli()},
+			},
+			expected: true,
+		},
+		{
+			name: "interval lower than the minimum sync interval",
+			spec: provisioning.RepositorySpec{
+				Sync:  spec.Sync,
+				Drift: &provisioning.DriftOptions{Enabled: true, IntervalSeconds: 10},
+			},
+			status: provisioning.RepositoryStatus{
+				Sync:  synced,
+				Drift: &provisioning.DriftStatus{Checked: time.Now().Add(-time.Minute).UnixMilli()},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := &RepositoryController{minSyncInterval: 5 * time.Minute}
+			repo := &provisioning.Repository{Spec: tc.spec, Status: tc.status}
+			assert.Equal(t, tc.expected, rc.shouldCheckDrift(repo))
+		})
+	}
+}
+
 // capturingStatusPatcher records all Patch calls for later inspection.
 type capturingStatusPatcher struct {
 	calls [][]map[string]interface{}
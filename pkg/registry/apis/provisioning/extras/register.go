@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository/local"
 	"github.com/grafana/grafana/apps/secret/pkg/decrypt"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/bucket"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/webhooks/pullrequest"
@@ -41,6 +42,15 @@ func ProvideProvisioningOSSRepositoryExtras(
 			ghFactory,
 			webhooksBuilder,
 		),
+		bucket.Extra(
+			decrypter,
+			cfg.HomePath,
+			cfg.PermittedProvisioningPaths,
+			bucket.AllowList{
+				Endpoints: cfg.ProvisioningBucketAllowedEndpoints,
+				Regions:   cfg.ProvisioningBucketAllowedRegions,
+			},
+		),
 	}
 }
 
@@ -14,6 +14,7 @@ import (
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/drift"
 )
 
 type JobQueueGetter interface {
@@ -137,7 +138,8 @@ func (c *jobsConnector) Connect(
 		requiresWrite := spec.Action == provisioning.JobActionDelete ||
 			spec.Action == provisioning.JobActionMove ||
 			spec.Action == provisioning.JobActionPush ||
-			spec.Action == provisioning.JobActionMigrate
+			spec.Action == provisioning.JobActionMigrate ||
+			(spec.Action == provisioning.JobActionDrift && drift.Policy(cfg, spec.Drift) == provisioning.DriftPolicyExport)
 
 		if requiresWrite {
 			var targetRef string
@@ -157,6 +159,9 @@ func (c *jobsConnector) Connect(
 			case provisioning.JobActionMigrate:
 				// Migrate operates on the default branch (no ref)
 				targetRef = ""
+			case provisioning.JobActionDrift:
+				// Exported drift is written to the default branch (no ref)
+				targetRef = ""
 			default:
 				// Read-only operations (Pull, PullRequest, FixFolderMetadata) don't reach here
 				// due to requiresWrite check, but include default for exhaustive linter
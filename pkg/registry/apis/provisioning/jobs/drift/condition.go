@@ -0,0 +1,52 @@
+package drift
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+// EvaluateDriftCondition creates a ResourcesInSync condition based on the outcome of a drift check.
+func EvaluateDriftCondition(drifted []provisioning.DriftedResource, err error) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:    provisioning.ConditionTypeResourcesInSync,
+			Status:  metav1.ConditionFalse,
+			Reason:  provisioning.ReasonFailure,
+			Message: fmt.Sprintf("Drift check failed: %s", err.Error()),
+		}
+	}
+
+	remaining := 0
+	for _, d := range drifted {
+		if !d.Reconciled {
+			remaining++
+		}
+	}
+
+	switch {
+	case len(drifted) == 0:
+		return metav1.Condition{
+			Type:    provisioning.ConditionTypeResourcesInSync,
+			Status:  metav1.ConditionTrue,
+			Reason:  provisioning.ReasonNoDrift,
+			Message: "Resources match the repository",
+		}
+	case remaining == 0:
+		return metav1.Condition{
+			Type:    provisioning.ConditionTypeResourcesInSync,
+			Status:  metav1.ConditionTrue,
+			Reason:  provisioning.ReasonDriftReconciled,
+			Message: fmt.Sprintf("%d drifted resources were reconciled", len(drifted)),
+		}
+	default:
+		return metav1.Condition{
+			Type:    provisioning.ConditionTypeResourcesInSync,
+			Status:  metav1.ConditionFalse,
+			Reason:  provisioning.ReasonDriftDetected,
+			Message: fmt.Sprintf("%d resources drifted from the repository", remaining),
+		}
+	}
+}
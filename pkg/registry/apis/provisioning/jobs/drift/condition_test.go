@@ -0,0 +1,66 @@
+package drift
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+)
+
+func TestEvaluateDriftCondition(t *testing.T) {
+	tests := []struct {
+		name           string
+		drifted        []provisioning.DriftedResource
+		err            error
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+		expectedMsg    string
+	}{
+		{
+			name:           "no drift",
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: provisioning.ReasonNoDrift,
+			expectedMsg:    "Resources match the repository",
+		},
+		{
+			name: "all drift reconciled",
+			drifted: []provisioning.DriftedResource{
+				{Path: "a.json", Reason: provisioning.DriftReasonModifiedInGrafana, Reconciled: true},
+				{Path: "b.json", Reason: provisioning.DriftReasonModifiedInGrafana, Reconciled: true},
+			},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: provisioning.ReasonDriftReconciled,
+			expectedMsg:    "2 drifted resources were reconciled",
+		},
+		{
+			name: "drift detected",
+			drifted: []provisioning.DriftedResource{
+				{Path: "a.json", Reason: provisioning.DriftReasonModifiedInGrafana, Reconciled: true},
+				{Path: "b.json", Reason: provisioning.DriftReasonModifiedInRepository},
+			},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: provisioning.ReasonDriftDetected,
+			expectedMsg:    "1 resources drifted from the repository",
+		},
+		{
+			name:           "check failed",
+			err:            errors.New("read tree"),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: provisioning.ReasonFailure,
+			expectedMsg:    "Drift check failed: read tree",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := EvaluateDriftCondition(tt.drifted, tt.err)
+			assert.Equal(t, provisioning.ConditionTypeResourcesInSync, condition.Type)
+			assert.Equal(t, tt.expectedStatus, condition.Status)
+			assert.Equal(t, tt.expectedReason, condition.Reason)
+			assert.Equal(t, tt.expectedMsg, condition.Message)
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/sync"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+// listPageSize is the number of stored resources listed per request.
+const listPageSize = 500
+
+// Result is the outcome of a drift check.
+type Result struct {
+	// Drifted are the resources that differ from the repository.
+	Drifted []provisioning.DriftedResource
+	// Failed are the resources that could not be compared, with the error.
+	Failed []provisioning.DriftedResource
+}
+
+// Detect compares the repository contents at ref with the resources managed by the repository.
+//
+// Differences in the file hashes are found the same way as a sync would, and mean the repository changed.
+// A file with the synced hash has the contents it was compared with before, so when the previous check compared
+// every resource, only the resources it reported and the ones updated in Grafana since are compared with their file.
+// Pass a nil previous check to compare every resource.
+func Detect(ctx context.Context, repo repository.Reader, repositoryResources resources.RepositoryResources, clients resources.ResourceClients, parser resources.Parser, ref string, previous *provisioning.DriftStatus) (*Result, error) {
+	target, err := repositoryResources.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing current: %w", err)
+	}
+
+	source, err := repo.ReadTree(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tree: %w", err)
+	}
+
+	changes, err := sync.Changes(source, target)
+	if err != nil {
+		return nil, fmt.Errorf("calculate changes: %w", err)
+	}
+
+	result := &Result{Drifted: make([]provisioning.DriftedResource, 0, len(changes))}
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changed[change.Path] = true
+
+		drifted := provisioning.DriftedResource{Path: change.Path}
+		if change.Existing != nil {
+			drifted.Group = change.Existing.Group
+			drifted.Resource = change.Existing.Resource
+			drifted.Name = change.Existing.Name
+		}
+
+		switch change.Action {
+		case repository.FileActionCreated:
+			drifted.Reason = provisioning.DriftReasonMissingInGrafana
+		case repository.FileActionUpdated:
+			drifted.Reason = provisioning.DriftReasonModifiedInRepository
+		case repository.FileActionDeleted:
+			drifted.Reason = provisioning.DriftReasonMissingInRepository
+		default:
+			continue
+		}
+		result.Drifted = append(result.Drifted, drifted)
+	}
+
+	// Folders have no contents to compare
+	unchanged := make([]provisioning.ResourceListItem, 0, len(target.Items))
+	for _, item := range target.Items {
+		if item.Path != "" && item.Resource != resources.FolderResource.Resource && !changed[item.Path] {
+			unchanged = append(unchanged, item)
+		}
+	}
+
+	stored, err := listStored(ctx, clients, unchanged)
+	if err != nil {
+		return nil, err
+	}
+
+	var since time.Time
+	reported := map[string]bool{}
+	if previous != nil {
+		since = time.UnixMilli(previous.Checked).Truncate(time.Second)
+		for _, r := range previous.Resources {
+			reported[r.Path] = true
+		}
+	}
+
+	for _, item := range unchanged {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		existing, ok := stored[schema.GroupResource{Group: item.Group, Resource: item.Resource}][item.Name]
+		if !ok {
+			result.Drifted = append(result.Drifted, provisioning.DriftedResource{
+				Path:     item.Path,
+				Group:    item.Group,
+				Resource: item.Resource,
+				Name:     item.Name,
+				Reason:   provisioning.DriftReasonMissingInGrafana,
+			})
+			continue
+		}
+		if previous != nil && !reported[item.Path] && !updatedSince(existing, since) {
+			continue
+		}
+
+		drifted, err := compareWithStored(ctx, repo, parser, item, existing, ref)
+		if err != nil {
+			result.Failed = append(result.Failed, provisioning.DriftedResource{
+				Path:     item.Path,
+				Group:    item.Group,
+				Resource: item.Resource,
+				Name:     item.Name,
+				Error:    err.Error(),
+			})
+			continue
+		}
+		if drifted != nil {
+			result.Drifted = append(result.Drifted, *drifted)
+		}
+	}
+
+	return result, nil
+}
+
+// listStored returns the stored resources of the items by group resource and name, listing each resource type once.
+func listStored(ctx context.Context, clients resources.ResourceClients, items []provisioning.ResourceListItem) (map[schema.GroupResource]map[string]*unstructured.Unstructured, error) {
+	stored := make(map[schema.GroupResource]map[string]*unstructured.Unstructured)
+	for _, item := range items {
+		gr := schema.GroupResource{Group: item.Group, Resource: item.Resource}
+		if _, ok := stored[gr]; ok {
+			continue
+		}
+
+		client, _, err := clients.ForResource(ctx, schema.GroupVersionResource{Group: gr.Group, Resource: gr.Resource})
+		if err != nil {
+			return nil, fmt.Errorf("get client for %s: %w", gr.String(), err)
+		}
+
+		objects := make(map[string]*unstructured.Unstructured)
+		opts := metav1.ListOptions{Limit: listPageSize}
+		for {
+			list, err := client.List(ctx, opts)
+			if err != nil {
+				return nil, fmt.Errorf("list %s: %w", gr.String(), err)
+			}
+			for i := range list.Items {
+				objects[list.Items[i].GetName()] = &list.Items[i]
+			}
+			if list.GetContinue() == "" {
+				break
+			}
+			opts.Continue = list.GetContinue()
+		}
+		stored[gr] = objects
+	}
+	return stored, nil
+}
+
+// updatedSince returns true if the stored resource was created or updated at or after the given time.
+func updatedSince(obj *unstructured.Unstructured, since time.Time) bool {
+	updated := obj.GetCreationTimestamp().Time
+	if meta, err := utils.MetaAccessor(obj); err == nil {
+		if ts, err := meta.GetUpdatedTimestamp(); err == nil && ts != nil {
+			updated = *ts
+		}
+	}
+	return !updated.Before(since)
+}
+
+// compareWithStored returns the drift between a file and its stored resource, or nil when they match.
+func compareWithStored(ctx context.Context, repo repository.Reader, parser resources.Parser, item provisioning.ResourceListItem, existing *unstructured.Unstructured, ref string) (*provisioning.DriftedResource, error) {
+	info, err := repo.Read(ctx, item.Path, ref)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	parsed, _, err := parseResource(ctx, parser, info, item.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	same, err := sameContents(parsed, existing)
+	if err != nil {
+		return nil, err
+	}
+	if same {
+		return nil, nil
+	}
+
+	return &provisioning.DriftedResource{
+		Path:     item.Path,
+		Group:    parsed.GVK.Group,
+		Resource: parsed.GVR.Resource,
+		Name:     parsed.Obj.GetName(),
+		Reason:   provisioning.DriftReasonModifiedInGrafana,
+	}, nil
+}
+
+// parseResource parses the resource with the name from a file, and returns whether the file is a rule group file,
+// which holds several alert rules.
+func parseResource(ctx context.Context, parser resources.Parser, info *repository.FileInfo, name string) (*resources.ParsedResource, bool, error) {
+	files, group, err := resources.SplitResourceFile(info)
+	if err != nil {
+		return nil, group, fmt.Errorf("parse file: %w", err)
+	}
+
+	for _, file := range files {
+		parsed, err := parser.Parse(ctx, file)
+		if err != nil {
+			return nil, group, fmt.Errorf("parse file: %w", err)
+		}
+		if !group || parsed.Obj.GetName() == name {
+			return parsed, group, nil
+		}
+	}
+	return nil, group, fmt.Errorf("resource %s not found in file %s", name, info.Path)
+}
+
+// sameContents compares the parts of a resource defined by its file: the spec, and the folder for the resources living in folders.
+func sameContents(parsed *resources.ParsedResource, existing *unstructured.Unstructured) (bool, error) {
+	if slices.Contains(resources.SupportsFolderAnnotation, parsed.GVR.GroupResource()) {
+		meta, err := utils.MetaAccessor(existing)
+		if err != nil {
+			return false, fmt.Errorf("get meta accessor: %w", err)
+		}
+		if meta.GetFolder() != parsed.Meta.GetFolder() {
+			return false, nil
+		}
+	}
+
+	fromFile, err := normalizedSpec(parsed.Obj, parsed)
+	if err != nil {
+		return false, fmt.Errorf("normalize file spec: %w", err)
+	}
+	stored, err := normalizedSpec(existing, parsed)
+	if err != nil {
+		return false, fmt.Errorf("normalize stored spec: %w", err)
+	}
+
+	return reflect.DeepEqual(fromFile, stored), nil
+}
+
+// comparableSpec returns the spec without the fields the parser removes from files.
+func comparableSpec(obj *unstructured.Unstructured, parsed *resources.ParsedResource) map[string]any {
+	spec, _ := obj.Object["spec"].(map[string]any)
+	spec = maps.Clone(spec) // shallow, only top level fields are removed
+	if parsed.GVR.GroupResource() == resources.DashboardResource.GroupResource() {
+		delete(spec, "uid")
+		delete(spec, "version")
+		delete(spec, "id")
+	}
+	return spec
+}
+
+// normalizedSpec returns the comparable spec as decoded from JSON, without the empty values.
+// Files and stored resources differ in number types, and in empty fields being omitted or defaulted,
+// both sides must go through it so these differences are not reported as drift.
+func normalizedSpec(obj *unstructured.Unstructured, parsed *resources.ParsedResource) (any, error) {
+	data, err := json.Marshal(comparableSpec(obj, parsed))
+	if err != nil {
+		return nil, err
+	}
+	var spec any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	return dropEmpty(spec), nil
+}
+
+func dropEmpty(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			child = dropEmpty(child)
+			if isEmpty(child) {
+				delete(v, key)
+			} else {
+				v[key] = child
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = dropEmpty(child)
+		}
+	}
+	return value
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+func newDashboard(t *testing.T, title, folder string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": resources.DashboardResource.GroupVersion().String(),
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name":      "dash",
+			"namespace": "default",
+		},
+		"spec": map[string]any{
+			"title":   title,
+			"uid":     "dash",
+			"version": int64(3),
+		},
+	}}
+	meta, err := utils.MetaAccessor(obj)
+	require.NoError(t, err)
+	meta.SetFolder(folder)
+	return obj
+}
+
+func newStoredClients(t *testing.T, objects ...runtime.Object) *resources.MockResourceClients {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.DashboardResource: "DashboardList",
+	}, objects...)
+
+	clients := resources.NewMockResourceClients(t)
+	clients.EXPECT().ForResource(mock.Anything, schema.GroupVersionResource{Group: "dashboard.grafana.app", Resource: "dashboards"}).
+		Return(client.Resource(resources.DashboardResource).Namespace("default"), schema.GroupVersionKind{}, nil).Maybe()
+	return clients
+}
+
+func newParsedDashboard(t *testing.T, info *repository.FileInfo, fromFile *unstructured.Unstructured) *resources.ParsedResource {
+	meta, err := utils.MetaAccessor(fromFile)
+	require.NoError(t, err)
+	return &resources.ParsedResource{
+		Info: info,
+		Obj:  fromFile,
+		Meta: meta,
+		GVK:  fromFile.GroupVersionKind(),
+		GVR:  resources.DashboardResource,
+	}
+}
+
+func newDashboardRepository(t *testing.T) (*repository.MockReader, *resources.MockRepositoryResources) {
+	repo := repository.NewMockReader(t)
+	repo.EXPECT().ReadTree(mock.Anything, "abc").Return([]repository.FileTreeEntry{
+		{Path: "dash.json", Hash: "h1", Blob: true},
+	}, nil)
+
+	repositoryResources := resources.NewMockRepositoryResources(t)
+	repositoryResources.EXPECT().List(mock.Anything).Return(&provisioning.ResourceList{
+		Items: []provisioning.ResourceListItem{
+			{Path: "dash.json", Hash: "h1", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash"},
+		},
+	}, nil)
+	return repo, repositoryResources
+}
+
+func TestDetect_Changes(t *testing.T) {
+	repo := repository.NewMockReader(t)
+	repo.EXPECT().ReadTree(mock.Anything, "abc").Return([]repository.FileTreeEntry{
+		{Path: "changed.json", Hash: "new", Blob: true},
+		{Path: "created.json", Hash: "h2", Blob: true},
+	}, nil)
+
+	repositoryResources := resources.NewMockRepositoryResources(t)
+	repositoryResources.EXPECT().List(mock.Anything).Return(&provisioning.ResourceList{
+		Items: []provisioning.ResourceListItem{
+			{Path: "changed.json", Hash: "old", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "changed"},
+			{Path: "deleted.json", Hash: "h3", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "deleted"},
+		},
+	}, nil)
+
+	result, err := Detect(context.Background(), repo, repositoryResources, resources.NewMockResourceClients(t), resources.NewMockParser(t), "abc", nil)
+	require.NoError(t, err)
+	require.Empty(t, result.Failed)
+	require.ElementsMatch(t, []provisioning.DriftedResource{
+		{Path: "changed.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "changed", Reason: provisioning.DriftReasonModifiedInRepository},
+		{Path: "created.json", Reason: provisioning.DriftReasonMissingInGrafana},
+		{Path: "deleted.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "deleted", Reason: provisioning.DriftReasonMissingInRepository},
+	}, result.Drifted)
+}
+
+func TestDetect_CompareWithStored(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   *unstructured.Unstructured
+		fromFile func(obj *unstructured.Unstructured)
+		expected []provisioning.DriftedResource
+	}{
+		{
+			name:   "same contents",
+			stored: newDashboard(t, "Dashboard", "folder-a"),
+		},
+		{
+			name: "ignores fields removed from files",
+			stored: func() *unstructured.Unstructured {
+				obj := newDashboard(t, "Dashboard", "folder-a")
+				obj.Object["spec"].(map[string]any)["version"] = int64(10)
+				return obj
+			}(),
+		},
+		{
+			name: "normalizes both sides the same way",
+			stored: func() *unstructured.Unstructured {
+				obj := newDashboard(t, "Dashboard", "folder-a")
+				spec := obj.Object["spec"].(map[string]any)
+				spec["description"] = ""
+				spec["tags"] = []any{}
+				spec["links"] = nil
+				spec["panels"] = []any{map[string]any{"id": int64(1), "options": map[string]any{}}}
+				return obj
+			}(),
+			fromFile: func(obj *unstructured.Unstructured) {
+				obj.Object["spec"].(map[string]any)["panels"] = []any{map[string]any{"id": float64(1)}}
+			},
+		},
+		{
+			name:   "spec edited in grafana",
+			stored: newDashboard(t, "Edited", "folder-a"),
+			expected: []provisioning.DriftedResource{
+				{Path: "dash.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash", Reason: provisioning.DriftReasonModifiedInGrafana},
+			},
+		},
+		{
+			name:   "moved to another folder in grafana",
+			stored: newDashboard(t, "Dashboard", "folder-b"),
+			expected: []provisioning.DriftedResource{
+				{Path: "dash.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash", Reason: provisioning.DriftReasonModifiedInGrafana},
+			},
+		},
+		{
+			name: "deleted in grafana",
+			expected: []provisioning.DriftedResource{
+				{Path: "dash.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash", Reason: provisioning.DriftReasonMissingInGrafana},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &repository.FileInfo{Path: "dash.json", Ref: "abc"}
+			repo, repositoryResources := newDashboardRepository(t)
+
+			var objects []runtime.Object
+			parser := resources.NewMockParser(t)
+			if tt.stored != nil {
+				objects = append(objects, tt.stored)
+
+				fromFile := newDashboard(t, "Dashboard", "folder-a")
+				if tt.fromFile != nil {
+					tt.fromFile(fromFile)
+				}
+				repo.EXPECT().Read(mock.Anything, "dash.json", "abc").Return(info, nil)
+				parser.EXPECT().Parse(mock.Anything, info).Return(newParsedDashboard(t, info, fromFile), nil)
+			}
+
+			result, err := Detect(context.Background(), repo, repositoryResources, newStoredClients(t, objects...), parser, "abc", nil)
+			require.NoError(t, err)
+			require.Empty(t, result.Failed)
+			require.ElementsMatch(t, tt.expected, result.Drifted)
+		})
+	}
+}
+
+func TestDetect_CompareErrors(t *testing.T) {
+	info := &repository.FileInfo{Path: "dash.json", Ref: "abc"}
+	repo, repositoryResources := newDashboardRepository(t)
+	repo.EXPECT().Read(mock.Anything, "dash.json", "abc").Return(info, nil)
+
+	parser := resources.NewMockParser(t)
+	parser.EXPECT().Parse(mock.Anything, info).Return(nil, errors.New("invalid json"))
+
+	result, err := Detect(context.Background(), repo, repositoryResources, newStoredClients(t, newDashboard(t, "Dashboard", "folder-a")), parser, "abc", nil)
+	require.NoError(t, err)
+	require.Empty(t, result.Drifted)
+	require.Equal(t, []provisioning.DriftedResource{
+		{Path: "dash.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash", Error: "parse file: invalid json"},
+	}, result.Failed)
+}
+
+func TestDetect_PreviousCheck(t *testing.T) {
+	checked := time.Now().Add(-time.Hour)
+	updated := func(at time.Time) *unstructured.Unstructured {
+		obj := newDashboard(t, "Edited", "folder-a")
+		meta, err := utils.MetaAccessor(obj)
+		require.NoError(t, err)
+		meta.SetUpdatedTimestamp(&at)
+		return obj
+	}
+	drifted := []provisioning.DriftedResource{
+		{Path: "dash.json", Group: "dashboard.grafana.app", Resource: "dashboards", Name: "dash", Reason: provisioning.DriftReasonModifiedInGrafana},
+	}
+
+	tests := []struct {
+		name     string
+		stored   *unstructured.Unstructured
+		previous *provisioning.DriftStatus
+		compared bool
+	}{
+		{
+			name:     "skips the files of resources not updated since the previous check",
+			stored:   updated(checked.Add(-time.Hour)),
+			previous: &provisioning.DriftStatus{Checked: checked.UnixMilli(), Ref: "abc"},
+		},
+		{
+			name:     "compares the resources updated since the previous check",
+			stored:   updated(checked.Add(time.Minute)),
+			previous: &provisioning.DriftStatus{Checked: checked.UnixMilli(), Ref: "abc"},
+			compared: true,
+		},
+		{
+			name:     "compares the resources reported by the previous check",
+			stored:   updated(checked.Add(-time.Hour)),
+			previous: &provisioning.DriftStatus{Checked: checked.UnixMilli(), Ref: "abc", Count: 1, Resources: drifted},
+			compared: true,
+		},
+		{
+			name:     "compares every resource without a previous check",
+			stored:   updated(checked.Add(-time.Hour)),
+			compared: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &repository.FileInfo{Path: "dash.json", Ref: "abc"}
+			repo, repositoryResources := newDashboardRepository(t)
+			parser := resources.NewMockParser(t)
+			if tt.compared {
+				repo.EXPECT().Read(mock.Anything, "dash.json", "abc").Return(info, nil)
+				parser.EXPECT().Parse(mock.Anything, info).Return(newParsedDashboard(t, info, newDashboard(t, "Dashboard", "folder-a")), nil)
+			}
+
+			result, err := Detect(context.Background(), repo, repositoryResources, newStoredClients(t, tt.stored), parser, "abc", tt.previous)
+			require.NoError(t, err)
+			require.Empty(t, result.Failed)
+			if tt.compared {
+				require.Equal(t, drifted, result.Drifted)
+			} else {
+				require.Empty(t, result.Drifted)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/controller"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/jobs/sync"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/utils"
+)
+
+// maxReportedResources caps the resources listed in the repository status.
+const maxReportedResources = 50
+
+// Worker compares the repository contents with the stored resources, and reconciles them based on the drift policy.
+// The result is saved in the repository status, both as a report and as the ResourcesInSync condition.
+type Worker struct {
+	repositoryResources resources.RepositoryResourcesFactory
+	parsers             resources.ParserFactory
+	clients             resources.ClientFactory
+	patchStatus         sync.RepositoryPatchFn
+	wrapFn              repository.WrapWithStageFn
+	metrics             jobs.JobMetrics
+}
+
+func NewWorker(
+	repositoryResources resources.RepositoryResourcesFactory,
+	parsers resources.ParserFactory,
+	clients resources.ClientFactory,
+	patchStatus sync.RepositoryPatchFn,
+	wrapFn repository.WrapWithStageFn,
+	metrics jobs.JobMetrics,
+) *Worker {
+	return &Worker{
+		repositoryResources: repositoryResources,
+		parsers:             parsers,
+		clients:             clients,
+		patchStatus:         patchStatus,
+		wrapFn:              wrapFn,
+		metrics:             metrics,
+	}
+}
+
+func (w *Worker) IsSupported(ctx context.Context, job provisioning.Job) bool {
+	return job.Spec.Action == provisioning.JobActionDrift
+}
+
+func (w *Worker) Process(ctx context.Context, repo repository.Repository, job provisioning.Job, progress jobs.JobProgressRecorder) error {
+	cfg := repo.Config()
+	logger := logging.FromContext(ctx).With("job", job.GetName(), "namespace", job.GetNamespace())
+
+	start := time.Now()
+	outcome := utils.ErrorOutcome
+	reconciled := 0
+	defer func() {
+		w.metrics.RecordJob(string(provisioning.JobActionDrift), outcome, reconciled, time.Since(start).Seconds())
+	}()
+
+	rw, ok := repo.(repository.ReaderWriter)
+	if !ok {
+		return errors.New("drift job submitted for repository that does not support read-write")
+	}
+
+	policy := Policy(cfg, job.Spec.Drift)
+
+	var ref string
+	if versioned, ok := repo.(repository.Versioned); ok {
+		var err error
+		ref, err = versioned.LatestRef(ctx)
+		if err != nil {
+			return fmt.Errorf("get latest ref: %w", err)
+		}
+	}
+
+	result, err := w.run(ctx, rw, job, policy, ref, progress)
+
+	var drifted []provisioning.DriftedResource
+	checkErr := err
+	if result != nil {
+		drifted = result.Drifted
+		if err == nil && len(result.Failed) > 0 {
+			checkErr = fmt.Errorf("%d resources could not be compared", len(result.Failed))
+		}
+	}
+
+	for _, d := range drifted {
+		if d.Reconciled {
+			reconciled++
+		}
+		w.metrics.RecordDrift(string(d.Reason))
+	}
+
+	status := &provisioning.DriftStatus{
+		JobID:   job.GetName(),
+		Checked: start.UnixMilli(),
+		Ref:     ref,
+		Count:   len(drifted),
+	}
+	if err != nil {
+		logger.Warn("failed to check drift", "error", err)
+		// Keep the last report, so the drift found before is not lost
+		if cfg.Status.Drift != nil {
+			status.Count = cfg.Status.Drift.Count
+			status.Resources = cfg.Status.Drift.Resources
+		}
+	} else {
+		outcome = utils.SuccessOutcome
+		status.Failed = len(result.Failed)
+		status.Resources = drifted[:min(len(drifted), maxReportedResources)]
+		progress.SetFinalMessage(ctx, fmt.Sprintf("%d drifted resources (%d reconciled, %d not compared)", len(drifted), reconciled, status.Failed))
+	}
+
+	patchOperations := []map[string]interface{}{
+		{
+			"op":    "replace",
+			"path":  "/status/drift",
+			"value": status,
+		},
+	}
+	if conditionOps := controller.BuildConditionPatchOpsFromExisting(cfg.Status.Conditions, cfg.GetGeneration(), EvaluateDriftCondition(drifted, checkErr)); conditionOps != nil {
+		patchOperations = append(patchOperations, conditionOps...)
+	}
+
+	if patchErr := w.patchStatus(ctx, cfg, patchOperations...); patchErr != nil {
+		logger.Error("failed to update the repository status at the end of the drift job", "error", patchErr)
+		return fmt.Errorf("update repo with drift status: %w", patchErr)
+	}
+
+	return err
+}
+
+func (w *Worker) run(ctx context.Context, rw repository.ReaderWriter, job provisioning.Job, policy provisioning.DriftPolicy, ref string, progress jobs.JobProgressRecorder) (*Result, error) {
+	repositoryResources, err := w.repositoryResources.Client(ctx, rw)
+	if err != nil {
+		return nil, fmt.Errorf("create repository resources client: %w", err)
+	}
+
+	parser, err := w.parsers.GetParser(ctx, rw)
+	if err != nil {
+		return nil, fmt.Errorf("get parser: %w", err)
+	}
+
+	clients, err := w.clients.Clients(ctx, rw.Config().Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("get clients: %w", err)
+	}
+
+	progress.SetMessage(ctx, "compare repository with stored resources")
+	result, err := Detect(ctx, rw, repositoryResources, clients, parser, ref, previousCheck(rw.Config()))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, failed := range result.Failed {
+		progress.Record(ctx, jobs.NewPathOnlyResult(failed.Path).
+			WithName(failed.Name).
+			WithGroup(failed.Group).
+			WithAction(repository.FileActionIgnored).
+			WithError(fmt.Errorf("compare resource: %s", failed.Error)).
+			Build())
+	}
+
+	if policy == provisioning.DriftPolicyAlert {
+		return result, nil
+	}
+
+	progress.SetMessage(ctx, fmt.Sprintf("reconcile drifted resources (%s)", policy))
+	return result, w.reconcile(ctx, rw, repositoryResources, job, policy, ref, result.Drifted, progress)
+}
+
+// previousCheck returns the last drift check if the next one can build upon it, which requires it to have
+// compared every resource of the current repository configuration and to list every drifted resource.
+func previousCheck(cfg *provisioning.Repository) *provisioning.DriftStatus {
+	previous := cfg.Status.Drift
+	if previous == nil || previous.Checked == 0 || previous.Failed > 0 || previous.Count > len(previous.Resources) {
+		return nil
+	}
+	condition := meta.FindStatusCondition(cfg.Status.Conditions, provisioning.ConditionTypeResourcesInSync)
+	if condition == nil || condition.Reason == provisioning.ReasonFailure || condition.ObservedGeneration != cfg.GetGeneration() {
+		return nil
+	}
+	return previous
+}
+
+// reconcile applies the policy to the resources modified in Grafana.
+// Changes in the repository are left to the sync, which applies them with the quota and ordering rules.
+func (w *Worker) reconcile(ctx context.Context, rw repository.ReaderWriter, repositoryResources resources.RepositoryResources, job provisioning.Job, policy provisioning.DriftPolicy, ref string, drifted []provisioning.DriftedResource, progress jobs.JobProgressRecorder) error {
+	var indexes []int
+	for i, d := range drifted {
+		if d.Reason == provisioning.DriftReasonModifiedInGrafana && d.Error == "" {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	progress.SetTotal(ctx, len(indexes))
+
+	switch policy {
+	case provisioning.DriftPolicyRevert:
+		for _, i := range indexes {
+			result := jobs.NewPathOnlyResult(drifted[i].Path).WithAction(repository.FileActionUpdated)
+			name, gvk, err := repositoryResources.WriteResourceFromFile(ctx, drifted[i].Path, ref)
+			result.WithName(name).WithGVK(gvk)
+			if err != nil {
+				drifted[i].Error = err.Error()
+				result.WithError(fmt.Errorf("revert resource: %w", err))
+			} else {
+				drifted[i].Reconciled = true
+			}
+			progress.Record(ctx, result.Build())
+		}
+		return nil
+
+	case provisioning.DriftPolicyExport:
+		if err := repository.IsWriteAllowed(rw.Config(), ""); err != nil {
+			return fmt.Errorf("export drifted resources: %w", err)
+		}
+
+		stageOptions := repository.StageOptions{
+			Mode:                  repository.StageModeCommitOnlyOnce,
+			CommitOnlyOnceMessage: fmt.Sprintf("Export changes made in Grafana %s", job.GetName()),
+			PushOnWrites:          false,
+			Timeout:               10 * time.Minute,
+		}
+		return w.wrapFn(ctx, rw, stageOptions, func(staged repository.Repository, _ bool) error {
+			writer, ok := staged.(repository.ReaderWriter)
+			if !ok {
+				return errors.New("export drifted resources: repository does not support read-write")
+			}
+
+			parser, err := w.parsers.GetParser(ctx, writer)
+			if err != nil {
+				return fmt.Errorf("get parser: %w", err)
+			}
+
+			for _, i := range indexes {
+				result := jobs.NewPathOnlyResult(drifted[i].Path).WithAction(repository.FileActionUpdated)
+				if err := exportResource(ctx, writer, parser, drifted[i].Path, drifted[i].Name); err != nil {
+					drifted[i].Error = err.Error()
+					result.WithError(fmt.Errorf("export resource: %w", err))
+				} else {
+					drifted[i].Reconciled = true
+				}
+				progress.Record(ctx, result.WithName(drifted[i].Name).WithGroup(drifted[i].Group).Build())
+			}
+			return nil
+		})
+
+	default:
+		return fmt.Errorf("unknown drift policy: %s", policy)
+	}
+}
+
+// exportResource writes the stored resource back to its file, keeping the file name and format.
+// Only files unchanged since the last sync are exported, so the configured branch has the compared contents.
+// In rule group files, only the alert rule with the name is exported.
+func exportResource(ctx context.Context, rw repository.ReaderWriter, parser resources.Parser, path, name string) error {
+	info, err := rw.Read(ctx, path, "")
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	parsed, group, err := parseResource(ctx, parser, info, name)
+	if err != nil {
+		return err
+	}
+
+	existing, err := parsed.Client.Get(ctx, parsed.Obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get stored resource: %w", err)
+	}
+	parsed.Obj.Object["spec"] = comparableSpec(existing, parsed)
+
+	var data []byte
+	if group {
+		data, err = resources.RuleGroupFileBytes(info, parsed.Obj)
+	} else {
+		data, err = parsed.ToSaveBytes()
+	}
+	if err != nil {
+		return fmt.Errorf("serialize resource: %w", err)
+	}
+
+	return rw.Update(ctx, path, "", data, "Export "+path+" from Grafana")
+}
+
+// Policy returns the drift policy for a job, falling back to the repository settings.
+func Policy(cfg *provisioning.Repository, options *provisioning.DriftJobOptions) provisioning.DriftPolicy {
+	if options != nil && options.Policy != "" {
+		return options.Policy
+	}
+	if cfg.Spec.Drift != nil && cfg.Spec.Drift.Policy != "" {
+		return cfg.Spec.Drift.Policy
+	}
+	return provisioning.DriftPolicyAlert
+}
@@ -64,6 +64,15 @@ func runExportTest(t *testing.T, mockItems []unstructured.Unstructured, setupPro
 		Kind:    "DashboardList",
 	})
 
+	// The other resources are exported after the dashboards, and there are none of them
+	for _, kind := range resources.SupportedProvisioningResources {
+		if kind == resources.FolderResource || kind == resources.DashboardResource {
+			continue
+		}
+		mockProgress.On("SetMessage", mock.Anything, "export "+kind.Resource).Return().Maybe()
+		resourceClients.On("ForResource", mock.Anything, kind).Return(&mockDynamicInterface{}, schema.GroupVersionKind{}, nil).Maybe()
+	}
+
 	options := provisioningV0.ExportJobOptions{
 		Path:   "grafana",
 		Branch: "feature/branch",
@@ -672,3 +681,42 @@ func TestExportResources_Dashboards_MultipleVersions(t *testing.T) {
 	err := runExportTest(t, mockItems, setupProgress, setupResources)
 	require.NoError(t, err)
 }
+
+func TestExportResources_Playlists(t *testing.T) {
+	playlist := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": resources.PlaylistResource.GroupVersion().String(),
+			"kind":       "Playlist",
+			"metadata": map[string]interface{}{
+				"name": "playlist-1",
+			},
+			"spec": map[string]interface{}{
+				"title": "On call",
+			},
+		},
+	}
+
+	setupProgress := func(progress *jobs.MockJobProgressRecorder) {
+		progress.On("SetMessage", mock.Anything, "start resource export").Return()
+		progress.On("SetMessage", mock.Anything, "export dashboards").Return()
+		progress.On("Record", mock.Anything, mock.MatchedBy(func(result jobs.JobResourceResult) bool {
+			return result.Name() == "playlist-1" && result.Action() == repository.FileActionCreated
+		})).Return()
+		progress.On("TooManyErrors").Return(nil)
+	}
+
+	setupResources := func(repoResources *resources.MockRepositoryResources, resourceClients *resources.MockResourceClients, mockClient *mockDynamicInterface, gvk schema.GroupVersionKind) {
+		resourceClients.On("ForResource", mock.Anything, resources.DashboardResource).Return(mockClient, gvk, nil)
+		resourceClients.On("ForResource", mock.Anything, resources.PlaylistResource).
+			Return(&mockDynamicInterface{items: []unstructured.Unstructured{playlist}}, resources.PlaylistKind, nil)
+
+		options := resources.WriteOptions{
+			Path: "grafana",
+			Ref:  "feature/branch",
+		}
+		repoResources.On("WriteResourceFileFromObject", mock.Anything, &playlist, options).Return("grafana/on-call.playlist.json", nil)
+	}
+
+	err := runExportTest(t, nil, setupProgress, setupResources)
+	require.NoError(t, err)
+}
@@ -784,6 +784,15 @@ func TestCountSupportedResources(t *testing.T) {
 			},
 			expected: 6,
 		},
+		{
+			name: "alerting and playlists",
+			stats: []v0alpha1.ResourceCount{
+				{Group: "rules.alerting.grafana.app", Resource: "alertrules", Count: 12},
+				{Group: "notifications.alerting.grafana.app", Resource: "receivers", Count: 3},
+				{Group: "playlist.grafana.app", Resource: "playlists", Count: 2},
+			},
+			expected: 17,
+		},
 		{
 			name: "only unsupported resources",
 			stats: []v0alpha1.ResourceCount{
@@ -15,6 +15,7 @@ type JobMetrics struct {
 	incrementalSyncPhaseDurationHist *prometheus.HistogramVec // phases of incremental sync
 	fullSyncPhaseDurationHist        *prometheus.HistogramVec // phases of full sync
 	syncDurationHist                 *prometheus.HistogramVec // total sync durations
+	driftedTotal                     *prometheus.CounterVec   // drifted resources by reason
 }
 
 type QueueMetrics struct {
@@ -109,6 +110,15 @@ func RegisterJobMetrics(registry prometheus.Registerer) JobMetrics {
 	)
 	registry.MustRegister(syncDurationHist)
 
+	driftedTotal := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grafana_provisioning_jobs_drifted_resources_total",
+			Help: "Total number of drifted resources found by drift checks",
+		},
+		[]string{"reason"},
+	)
+	registry.MustRegister(driftedTotal)
+
 	return JobMetrics{
 		registry:                         registry,
 		processedTotal:                   processedTotal,
@@ -116,6 +126,7 @@ func RegisterJobMetrics(registry prometheus.Registerer) JobMetrics {
 		incrementalSyncPhaseDurationHist: incrementalSyncPhaseDurationHist,
 		fullSyncPhaseDurationHist:        fullSyncPhaseDurationHist,
 		syncDurationHist:                 syncDurationHist,
+		driftedTotal:                     driftedTotal,
 	}
 }
 
@@ -140,6 +151,10 @@ func (m *JobMetrics) RecordSyncDuration(syncType SyncType, duration time.Duratio
 	m.syncDurationHist.WithLabelValues(syncType.String()).Observe(duration.Seconds())
 }
 
+func (m *JobMetrics) RecordDrift(reason string) {
+	m.driftedTotal.WithLabelValues(reason).Inc()
+}
+
 func recordConcurrentDriverMetric(registry prometheus.Registerer, numDrivers int) {
 	concurrentDriver := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
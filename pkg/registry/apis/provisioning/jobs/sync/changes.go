@@ -47,6 +47,8 @@ func Compare(ctx context.Context, repo repository.Reader, repositoryResources re
 
 func Changes(source []repository.FileTreeEntry, target *provisioning.ResourceList) ([]ResourceFileChange, error) {
 	lookup := make(map[string]*provisioning.ResourceListItem, len(target.Items))
+	// Rule group files hold several resources, the ones after the first are kept here
+	shared := make(map[string][]*provisioning.ResourceListItem)
 	for _, item := range target.Items {
 		if item.Path == "" {
 			if item.Group != resources.FolderResource.Group {
@@ -60,6 +62,10 @@ func Changes(source []repository.FileTreeEntry, target *provisioning.ResourceLis
 			item.Path = item.Path + "/"
 		}
 
+		if _, ok := lookup[item.Path]; ok {
+			shared[item.Path] = append(shared[item.Path], &item)
+			continue
+		}
 		lookup[item.Path] = &item
 	}
 
@@ -87,6 +93,7 @@ func Changes(source []repository.FileTreeEntry, target *provisioning.ResourceLis
 
 			if check.Resource != resources.FolderResource.Resource {
 				delete(lookup, file.Path)
+				delete(shared, file.Path)
 			}
 
 			continue
@@ -145,6 +152,15 @@ func Changes(source []repository.FileTreeEntry, target *provisioning.ResourceLis
 			Existing: v,
 		})
 	}
+	for _, items := range shared {
+		for _, v := range items {
+			changes = append(changes, ResourceFileChange{
+				Action:   repository.FileActionDeleted,
+				Path:     v.Path,
+				Existing: v,
+			})
+		}
+	}
 
 	// Deepest first (stable sort order)
 	safepath.SortByDepth(changes, func(c ResourceFileChange) string { return c.Path }, false)
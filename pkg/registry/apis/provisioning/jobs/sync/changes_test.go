@@ -219,6 +219,34 @@ func TestChanges(t *testing.T) {
 		}, changes[0])
 	})
 
+	t.Run("rule group file with several resources", func(t *testing.T) {
+		target := &provisioning.ResourceList{
+			Items: []provisioning.ResourceListItem{
+				{Path: "cpu.alertrules.json", Hash: "xyz", Group: "rules.alerting.grafana.app", Resource: "alertrules", Name: "high-cpu"},
+				{Path: "cpu.alertrules.json", Hash: "xyz", Group: "rules.alerting.grafana.app", Resource: "alertrules", Name: "low-cpu"},
+			},
+		}
+
+		changes, err := Changes([]repository.FileTreeEntry{
+			{Path: "cpu.alertrules.json", Hash: "modified", Blob: true},
+		}, target)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, repository.FileActionUpdated, changes[0].Action)
+		require.Equal(t, "cpu.alertrules.json", changes[0].Path)
+
+		changes, err = Changes([]repository.FileTreeEntry{}, target)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		deleted := make([]string, 0, len(changes))
+		for _, change := range changes {
+			require.Equal(t, repository.FileActionDeleted, change.Action)
+			require.Equal(t, "cpu.alertrules.json", change.Path)
+			deleted = append(deleted, change.Existing.Name)
+		}
+		require.ElementsMatch(t, []string{"high-cpu", "low-cpu"}, deleted)
+	})
+
 	t.Run("keep folder with hidden files", func(t *testing.T) {
 		source := []repository.FileTreeEntry{
 			{Path: "folder/.hidden.json", Hash: "xyz", Blob: true},
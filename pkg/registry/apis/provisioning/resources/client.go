@@ -10,11 +10,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	alertingnotifications "github.com/grafana/grafana/apps/alerting/notifications/pkg/apis/alertingnotifications/v0alpha1"
+	alertingrules "github.com/grafana/grafana/apps/alerting/rules/pkg/apis/alerting/v0alpha1"
+	dashboardV0 "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v0alpha1"
 	dashboardV1 "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v1beta1"
 	dashboardV2alpha1 "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v2alpha1"
 	dashboardV2beta1 "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v2beta1"
 	folders "github.com/grafana/grafana/apps/folder/pkg/apis/folder/v1beta1"
 	iam "github.com/grafana/grafana/apps/iam/pkg/apis/iam/v0alpha1"
+	playlist "github.com/grafana/grafana/apps/playlist/pkg/apis/playlist/v1"
 	"github.com/grafana/grafana/pkg/services/apiserver"
 	"github.com/grafana/grafana/pkg/services/apiserver/client"
 )
@@ -26,12 +30,36 @@ var (
 	DashboardResource         = dashboardV1.DashboardResourceInfo.GroupVersionResource()
 	DashboardResourceV2alpha1 = dashboardV2alpha1.DashboardResourceInfo.GroupVersionResource()
 	DashboardResourceV2beta1  = dashboardV2beta1.DashboardResourceInfo.GroupVersionResource()
-
-	// SupportedProvisioningResources is the list of resources that can fully managed from the UI
-	SupportedProvisioningResources = []schema.GroupVersionResource{FolderResource, DashboardResource}
+	LibraryPanelResource      = dashboardV0.LibraryPanelResourceInfo.GroupVersionResource()
+	AlertRuleResource         = alertingrules.AlertRuleKind().GroupVersionResource()
+	ReceiverResource          = alertingnotifications.ReceiverKind().GroupVersionResource()
+	TemplateGroupResource     = alertingnotifications.TemplateGroupKind().GroupVersionResource()
+	PlaylistResource          = playlist.PlaylistKind().GroupVersionResource()
+	LibraryPanelKind          = dashboardV0.LibraryPanelResourceInfo.GroupVersionKind()
+	AlertRuleKind             = alertingrules.AlertRuleKind().GroupVersionKind()
+	ReceiverKind              = alertingnotifications.ReceiverKind().GroupVersionKind()
+	TemplateGroupKind         = alertingnotifications.TemplateGroupKind().GroupVersionKind()
+	PlaylistKind              = playlist.PlaylistKind().GroupVersionKind()
+
+	// SupportedProvisioningResources is the list of resources that can fully managed from the UI.
+	// Folders must stay first, so they exist before the resources stored in them are exported or cleaned.
+	SupportedProvisioningResources = []schema.GroupVersionResource{
+		FolderResource,
+		DashboardResource,
+		LibraryPanelResource,
+		AlertRuleResource,
+		ReceiverResource,
+		TemplateGroupResource,
+		PlaylistResource,
+	}
 
 	// SupportsFolderAnnotation is the list of resources that can be saved in a folder
-	SupportsFolderAnnotation = []schema.GroupResource{FolderResource.GroupResource(), DashboardResource.GroupResource()}
+	SupportsFolderAnnotation = []schema.GroupResource{
+		FolderResource.GroupResource(),
+		DashboardResource.GroupResource(),
+		LibraryPanelResource.GroupResource(),
+		AlertRuleResource.GroupResource(),
+	}
 )
 
 // ClientFactory is a factory for creating clients for a given namespace
@@ -114,6 +114,12 @@ func ParseFolder(dirPath, repositoryName string) Folder {
 	}
 }
 
+// PreviewName returns the name of a resource copied into the preview environment of a pull request, identified by its key.
+// The name is deterministic, so that new commits on the pull request replace the same preview resources.
+func PreviewName(name, key, repositoryName string) string {
+	return appendHashSuffix("preview:"+key, repositoryName)(sanitiseKubeName(name))
+}
+
 func RootFolder(repository *provisioning.Repository) string {
 	if repository.Spec.Sync.Target == provisioning.SyncTargetTypeFolder {
 		return repository.Name // a folder with the same identifier as the repository
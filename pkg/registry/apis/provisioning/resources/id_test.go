@@ -93,3 +93,13 @@ func TestParseFolderID(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewName(t *testing.T) {
+	name := PreviewName("my-dashboard", "feature", "unit-test")
+	assert.True(t, strings.HasPrefix(name, "my-dashboard-"), "original name is kept as prefix")
+	assert.LessOrEqual(t, len(name), 40)
+	assert.Equal(t, name, PreviewName("my-dashboard", "feature", "unit-test"), "name must be deterministic")
+	assert.NotEqual(t, name, PreviewName("my-dashboard", "other", "unit-test"), "key is part of hash")
+	assert.NotEqual(t, name, PreviewName("my-dashboard", "feature", "other"), "repository is part of hash")
+	assert.NotEqual(t, name, ParseFolder("my-dashboard", "unit-test").ID, "preview names do not collide with folders")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"slices"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.yaml.in/yaml/v3"
@@ -15,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 
+	alertingrules "github.com/grafana/grafana/apps/alerting/rules/pkg/apis/alerting/v0alpha1"
 	dashboard "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v0alpha1"
 	folder "github.com/grafana/grafana/apps/folder/pkg/apis/folder/v1beta1"
 	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
@@ -191,15 +193,6 @@ func (r *parser) Parse(ctx context.Context, info *repository.FileInfo) (parsed *
 		obj.SetName(obj.GetGenerateName() + util.GenerateShortUID())
 	}
 
-	// Calculate folder identifier from the file path
-	if info.Path != "" {
-		dirPath := safepath.Dir(info.Path)
-		if dirPath != "" {
-			parsed.Meta.SetFolder(ParseFolder(dirPath, r.repo.Name).ID)
-		} else {
-			parsed.Meta.SetFolder(RootFolder(r.config))
-		}
-	}
 	obj.SetUID("")             // clear identifiers
 	obj.SetResourceVersion("") // clear identifiers
 
@@ -214,6 +207,19 @@ func (r *parser) Parse(ctx context.Context, info *repository.FileInfo) (parsed *
 		return nil, NewResourceValidationError(fmt.Errorf("get client for kind: %w", err))
 	}
 
+	// Calculate folder identifier from the file path, for the resources that live in folders.
+	// Other resources (contact points, playlists...) are global, so any folder in the file is ignored.
+	if !slices.Contains(SupportsFolderAnnotation, parsed.GVR.GroupResource()) {
+		parsed.Meta.SetFolder("")
+	} else if info.Path != "" {
+		dirPath := safepath.Dir(info.Path)
+		if dirPath != "" {
+			parsed.Meta.SetFolder(ParseFolder(dirPath, r.repo.Name).ID)
+		} else {
+			parsed.Meta.SetFolder(RootFolder(r.config))
+		}
+	}
+
 	return parsed, nil
 }
 
@@ -290,6 +296,7 @@ func (f *ParsedResource) DryRun(ctx context.Context) error {
 		f.Action = provisioning.ResourceActionUpdate
 		// on updates, clear the deprecated internal id, it will be set to the previous value by the storage layer
 		f.Meta.SetDeprecatedInternalID(0) // nolint:staticcheck
+		f.keepSecureSettings()
 		f.DryRunResponse, err = f.Client.Update(ctx, f.Obj, metav1.UpdateOptions{
 			DryRun:          []string{"All"},
 			FieldValidation: fieldValidation,
@@ -408,6 +415,7 @@ func (f *ParsedResource) Run(ctx context.Context) error {
 	// on updates, clear the deprecated internal id, it will be set to the previous value by the storage layer
 	if f.Existing != nil {
 		f.Meta.SetDeprecatedInternalID(0) // nolint:staticcheck
+		f.keepSecureSettings()
 	}
 
 	updateCtx, updateSpan := tracing.Start(actionsCtx, "provisioning.resources.run_resource.update")
@@ -435,17 +443,42 @@ func (f *ParsedResource) Run(ctx context.Context) error {
 	return err
 }
 
+// keepSecureSettings keeps the secure settings of an existing contact point, as they are not in the repository.
+func (f *ParsedResource) keepSecureSettings() {
+	if f.Existing != nil && f.GVR.GroupResource() == ReceiverResource.GroupResource() {
+		keepSecureSettings(f.Obj, f.Existing)
+	}
+}
+
 func (f *ParsedResource) ToSaveBytes() ([]byte, error) {
-	obj := f.Obj.DeepCopy().Object
+	return marshalFile(f.Info.Path, savedObject(f.Obj))
+}
+
+// savedObject returns the parts of an object written to the repository: its definition, without the status and
+// metadata set by Grafana. The secure settings of contact points are never written.
+func savedObject(o *unstructured.Unstructured) map[string]any {
+	obj := o.DeepCopy().Object
 	delete(obj, "status")
-	name := f.Obj.GetName()
+	name := o.GetName()
 	if name == "" {
 		delete(obj, "metadata")
 	} else {
-		obj["metadata"] = map[string]any{"name": name}
+		metadata := map[string]any{"name": name}
+		if labels := savedLabels(o); len(labels) > 0 {
+			metadata["labels"] = labels
+		}
+		obj["metadata"] = metadata
 	}
 
-	switch path.Ext(f.Info.Path) {
+	if o.GroupVersionKind().GroupKind() == ReceiverKind.GroupKind() {
+		removeSecureSettings(obj)
+	}
+	return obj
+}
+
+// marshalFile serializes an object in the format of the file.
+func marshalFile(filePath string, obj map[string]any) ([]byte, error) {
+	switch path.Ext(filePath) {
 	// JSON pretty print
 	case ".json":
 		return json.MarshalIndent(obj, "", "  ")
@@ -459,6 +492,23 @@ func (f *ParsedResource) ToSaveBytes() ([]byte, error) {
 	}
 }
 
+// savedLabels returns the labels of an object that are part of its definition, and must be kept in the repository.
+// For alert rules, these are the rule group and the position of the rule in it.
+func savedLabels(obj *unstructured.Unstructured) map[string]any {
+	var keys []string
+	if obj.GroupVersionKind().GroupKind() == AlertRuleKind.GroupKind() {
+		keys = []string{alertingrules.GroupLabelKey, alertingrules.GroupIndexLabelKey}
+	}
+
+	labels := make(map[string]any, len(keys))
+	for _, k := range keys {
+		if v, ok := obj.GetLabels()[k]; ok {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
 func (f *ParsedResource) AsResourceWrapper() *provisioning.ResourceWrapper {
 	info := f.Info
 	res := provisioning.ResourceObjects{
@@ -21,6 +21,8 @@ func TestParser(t *testing.T) {
 		Return(nil, dashboardV0.DashboardResourceInfo.GroupVersionResource(), nil).Maybe()
 	clients.On("ForKind", mock.Anything, dashboardV1.DashboardResourceInfo.GroupVersionKind()).
 		Return(nil, dashboardV1.DashboardResourceInfo.GroupVersionResource(), nil).Maybe()
+	clients.On("ForKind", mock.Anything, AlertRuleKind).Return(nil, AlertRuleResource, nil).Maybe()
+	clients.On("ForKind", mock.Anything, ReceiverKind).Return(nil, ReceiverResource, nil).Maybe()
 
 	parser := &parser{
 		repo: provisioning.ResourceRepositoryInfo{
@@ -147,4 +149,41 @@ spec:
 			})
 		}
 	})
+
+	t.Run("alert rules are saved in the folder of their file", func(t *testing.T) {
+		rule, err := parser.Parse(context.Background(), &repository.FileInfo{
+			Path: "team-a/node-alerts.high-cpu.alertrule.json",
+			Data: []byte(`apiVersion: rules.alerting.grafana.app/v0alpha1
+kind: AlertRule
+metadata:
+  name: high-cpu
+  labels:
+    grafana.com/group: node-alerts
+spec:
+  title: High CPU
+`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, AlertRuleResource, rule.GVR)
+		require.Equal(t, ParseFolder("team-a/", "repo").ID, rule.Meta.GetFolder())
+		require.Equal(t, "node-alerts", rule.Obj.GetLabels()["grafana.com/group"])
+	})
+
+	t.Run("contact points are not saved in folders", func(t *testing.T) {
+		receiver, err := parser.Parse(context.Background(), &repository.FileInfo{
+			Path: "team-a/oncall.contactpoint.json",
+			Data: []byte(`apiVersion: notifications.alerting.grafana.app/v0alpha1
+kind: Receiver
+metadata:
+  name: oncall
+  annotations:
+    grafana.app/folder: team-a
+spec:
+  title: On call
+`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, ReceiverResource, receiver.GVR)
+		require.Empty(t, receiver.Meta.GetFolder())
+	})
 }
@@ -0,0 +1,98 @@
+package resources
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// removeSecureSettings removes the secure settings from the integrations of a contact point. The secure fields are
+// kept, so syncing the file back keeps the values stored in Grafana.
+func removeSecureSettings(obj map[string]any) {
+	integrations, _, _ := unstructured.NestedSlice(obj, "spec", "integrations")
+	if len(integrations) == 0 {
+		return
+	}
+
+	for _, v := range integrations {
+		integration, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		secureFields, _, _ := unstructured.NestedMap(integration, "secureFields")
+		for field, secure := range secureFields {
+			if secure == true {
+				unstructured.RemoveNestedField(integration, append([]string{"settings"}, strings.Split(field, ".")...)...)
+			}
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj, integrations, "spec", "integrations")
+}
+
+// keepSecureSettings keeps the secure settings of the existing integrations of a contact point, which are never
+// written to the repository. Integrations are matched by uid, or by type when the file does not set the uid.
+// A secure setting set in the file replaces the existing value.
+func keepSecureSettings(obj, existing *unstructured.Unstructured) {
+	integrations, _, _ := unstructured.NestedSlice(obj.Object, "spec", "integrations")
+	existingIntegrations, _, _ := unstructured.NestedSlice(existing.Object, "spec", "integrations")
+	if len(integrations) == 0 || len(existingIntegrations) == 0 {
+		return
+	}
+
+	matched := make([]bool, len(existingIntegrations))
+	for _, v := range integrations {
+		integration, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		match := matchIntegration(integration, existingIntegrations, matched)
+		if match == nil {
+			continue
+		}
+
+		existingSecureFields, _, _ := unstructured.NestedMap(match, "secureFields")
+		secureFields, _, _ := unstructured.NestedMap(integration, "secureFields")
+		if secureFields == nil {
+			secureFields = make(map[string]any, len(existingSecureFields))
+		}
+		kept := false
+		for field, secure := range existingSecureFields {
+			if secure != true {
+				continue
+			}
+			if _, set, _ := unstructured.NestedFieldNoCopy(integration, append([]string{"settings"}, strings.Split(field, ".")...)...); set {
+				continue
+			}
+			secureFields[field] = true
+			kept = true
+		}
+		if !kept {
+			continue
+		}
+
+		// The existing values are only kept for the integration they belong to
+		integration["uid"] = match["uid"]
+		integration["secureFields"] = secureFields
+	}
+	_ = unstructured.SetNestedSlice(obj.Object, integrations, "spec", "integrations")
+}
+
+// matchIntegration returns the existing integration an integration of a file updates, and marks it as matched.
+func matchIntegration(integration map[string]any, existing []any, matched []bool) map[string]any {
+	uid, _ := integration["uid"].(string)
+	for i, v := range existing {
+		candidate, ok := v.(map[string]any)
+		if !ok || matched[i] {
+			continue
+		}
+		if uid != "" && candidate["uid"] != uid {
+			continue
+		}
+		if uid == "" && candidate["type"] != integration["type"] {
+			continue
+		}
+		matched[i] = true
+		return candidate
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	alertingrules "github.com/grafana/grafana/apps/alerting/rules/pkg/apis/alerting/v0alpha1"
 	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
 	"github.com/grafana/grafana/apps/provisioning/pkg/safepath"
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
@@ -198,45 +199,52 @@ func (r *ResourcesManager) WriteResourceFileFromObject(ctx context.Context, obj
 		title = name
 	}
 
-	folder := meta.GetFolder()
-	// Get the absolute path of the folder
-	rootFolder := RootFolder(r.repo.Config())
+	fileName, inFolder := resourceFileName(obj, title)
+	if inFolder {
+		folder := meta.GetFolder()
+		// Get the absolute path of the folder
+		rootFolder := RootFolder(r.repo.Config())
 
-	// If no folder is specified in the file, set it to the root to ensure everything is written under it
-	var fid Folder
-	if folder == "" {
-		fid = Folder{ID: rootFolder}
-		meta.SetFolder(rootFolder) // Set the folder in the metadata to the root folder
-	} else {
-		var ok bool
-		fid, ok = r.folders.Tree().DirPath(folder, rootFolder)
-		if !ok {
-			// HACK: this is a hack to get the folder path without the root folder
-			// TODO: should we build the tree in a different way?
-			fid, ok = r.folders.Tree().DirPath(folder, "")
+		// If no folder is specified in the file, set it to the root to ensure everything is written under it
+		var fid Folder
+		if folder == "" {
+			fid = Folder{ID: rootFolder}
+			meta.SetFolder(rootFolder) // Set the folder in the metadata to the root folder
+		} else {
+			var ok bool
+			fid, ok = r.folders.Tree().DirPath(folder, rootFolder)
 			if !ok {
-				return "", fmt.Errorf("folder %s NOT found in tree", folder)
+				// HACK: this is a hack to get the folder path without the root folder
+				// TODO: should we build the tree in a different way?
+				fid, ok = r.folders.Tree().DirPath(folder, "")
+				if !ok {
+					return "", fmt.Errorf("folder %s NOT found in tree", folder)
+				}
 			}
 		}
-	}
 
-	fileName := slugify.Slugify(title) + ".json"
-	if fid.Path != "" {
-		fileName = safepath.Join(fid.Path, fileName)
+		if fid.Path != "" {
+			fileName = safepath.Join(fid.Path, fileName)
+		}
 	}
 
 	if options.Path != "" {
 		fileName = safepath.Join(options.Path, fileName)
 	}
 
-	parsed := ParsedResource{
-		Info: &repository.FileInfo{
-			Path: fileName,
-			Ref:  options.Ref,
-		},
-		Obj: obj,
+	var body []byte
+	if obj.GroupVersionKind().GroupKind() == AlertRuleKind.GroupKind() {
+		body, err = r.ruleGroupFileBytes(ctx, fileName, options.Ref, obj)
+	} else {
+		parsed := ParsedResource{
+			Info: &repository.FileInfo{
+				Path: fileName,
+				Ref:  options.Ref,
+			},
+			Obj: obj,
+		}
+		body, err = parsed.ToSaveBytes()
 	}
-	body, err := parsed.ToSaveBytes()
 	if err != nil {
 		return "", err
 	}
@@ -249,6 +257,31 @@ func (r *ResourcesManager) WriteResourceFileFromObject(ctx context.Context, obj
 	return fileName, nil
 }
 
+// resourceFileName returns the file name of an object in a repository, and whether it is written in its folder.
+// Dashboards are named after their title; other resources also have their kind in the name, so they never collide
+// with a dashboard of the same title. Alert rules are written in the file of their rule group.
+// Resources that do not live in folders are written at the root of the repository.
+func resourceFileName(obj *unstructured.Unstructured, title string) (string, bool) {
+	name := slugify.Slugify(title)
+	switch obj.GroupVersionKind().GroupKind() {
+	case LibraryPanelKind.GroupKind():
+		return name + ".librarypanel.json", true
+	case AlertRuleKind.GroupKind():
+		if group := obj.GetLabels()[alertingrules.GroupLabelKey]; group != "" {
+			name = slugify.Slugify(group)
+		}
+		return name + ".alertrules.json", true
+	case ReceiverKind.GroupKind():
+		return name + ".contactpoint.json", false
+	case TemplateGroupKind.GroupKind():
+		return name + ".template.json", false
+	case PlaylistKind.GroupKind():
+		return name + ".playlist.json", false
+	default:
+		return name + ".json", true
+	}
+}
+
 func (r *ResourcesManager) WriteResourceFromFile(ctx context.Context, path string, ref string) (string, schema.GroupVersionKind, error) {
 	// Read the referenced file
 	readCtx, readSpan := tracing.Start(ctx, "provisioning.resources.write_resource_from_file.read_file")
@@ -260,17 +293,35 @@ func (r *ResourcesManager) WriteResourceFromFile(ctx context.Context, path strin
 	}
 	readSpan.End()
 
+	files, group, err := SplitResourceFile(fileInfo)
+	if err != nil {
+		return "", schema.GroupVersionKind{}, fmt.Errorf("failed to parse file: %w", err)
+	}
+	if group {
+		name, err := r.writeRuleGroupFromFile(ctx, path, files)
+		return name, AlertRuleKind, err
+	}
+
+	name, parsed, err := r.writeResourceFromFile(ctx, path, fileInfo)
+	if parsed == nil {
+		return name, schema.GroupVersionKind{}, err
+	}
+	return name, parsed.GVK, err
+}
+
+// writeResourceFromFile writes the resource of a file, and returns its name and the parsed resource once parsed.
+func (r *ResourcesManager) writeResourceFromFile(ctx context.Context, path string, fileInfo *repository.FileInfo) (string, *ParsedResource, error) {
 	parseCtx, parseSpan := tracing.Start(ctx, "provisioning.resources.write_resource_from_file.parse_file")
 	parsed, err := r.parser.Parse(parseCtx, fileInfo)
 	if err != nil {
 		parseSpan.RecordError(err)
 		parseSpan.End()
-		return "", schema.GroupVersionKind{}, fmt.Errorf("failed to parse file: %w", err)
+		return "", nil, fmt.Errorf("failed to parse file: %w", err)
 	}
 	parseSpan.End()
 
 	if parsed.Obj.GetName() == "" {
-		return "", schema.GroupVersionKind{}, NewResourceValidationError(ErrMissingName)
+		return "", nil, NewResourceValidationError(ErrMissingName)
 	}
 
 	// Check if the resource already exists
@@ -281,7 +332,7 @@ func (r *ResourcesManager) WriteResourceFromFile(ctx context.Context, path strin
 	}
 
 	if existing, found := r.findResource(id); found {
-		return "", parsed.GVK, NewResourceValidationError(
+		return "", parsed, NewResourceValidationError(
 			fmt.Errorf("duplicate resource name: %s, %s and %s: %w", parsed.Obj.GetName(), path, existing, ErrDuplicateName),
 		)
 	}
@@ -295,7 +346,7 @@ func (r *ResourcesManager) WriteResourceFromFile(ctx context.Context, path strin
 		if err != nil {
 			folderSpan.RecordError(err)
 			folderSpan.End()
-			return "", parsed.GVK, fmt.Errorf("failed to ensure folder path exists: %w", err)
+			return "", parsed, fmt.Errorf("failed to ensure folder path exists: %w", err)
 		}
 		parsed.Meta.SetFolder(folder)
 		folderSpan.End()
@@ -314,7 +365,7 @@ func (r *ResourcesManager) WriteResourceFromFile(ctx context.Context, path strin
 	}
 	runSpan.End()
 
-	return parsed.Obj.GetName(), parsed.GVK, err
+	return parsed.Obj.GetName(), parsed, err
 }
 
 func (r *ResourcesManager) RenameResourceFile(ctx context.Context, previousPath, previousRef, newPath, newRef string) (string, string, schema.GroupVersionKind, error) {
@@ -337,6 +388,30 @@ func (r *ResourcesManager) RemoveResourceFromFile(ctx context.Context, path stri
 		return "", "", schema.GroupVersionKind{}, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	files, group, err := SplitResourceFile(info)
+	if err != nil {
+		return "", "", schema.GroupVersionKind{}, err
+	}
+	if !group {
+		return r.removeResource(ctx, info)
+	}
+
+	// Remove every alert rule of the rule group
+	var folderName string
+	for _, file := range files {
+		_, folder, _, err := r.removeResource(ctx, file)
+		if err != nil {
+			return "", "", schema.GroupVersionKind{}, fmt.Errorf("failed to remove alert rule: %w", err)
+		}
+		if folderName == "" {
+			folderName = folder
+		}
+	}
+	return ruleGroupName(path, files), folderName, schema.GroupVersionKind{}, nil
+}
+
+// removeResource deletes the resource of a file, and returns its name and folder.
+func (r *ResourcesManager) removeResource(ctx context.Context, info *repository.FileInfo) (string, string, schema.GroupVersionKind, error) {
 	obj, gvk, _, err := ParseFileResource(ctx, info)
 	if err != nil {
 		return "", "", schema.GroupVersionKind{}, err
@@ -74,6 +74,49 @@ func TestRemoveResourceFromFile(t *testing.T) {
 		require.Equal(t, schema.GroupVersionKind{}, gvk)
 	})
 
+	t.Run("every alert rule of a rule group file is deleted", func(t *testing.T) {
+		repo := repository.NewMockReaderWriter(t)
+		clients := NewMockResourceClients(t)
+		mockClient := &MockDynamicResourceInterface{}
+
+		data := []byte(`{
+			"apiVersion": "rules.alerting.grafana.app/v0alpha1",
+			"kind": "AlertRuleList",
+			"items": [
+				{"metadata": {"name": "high-cpu", "labels": {"grafana.com/group": "Node alerts"}}, "spec": {"title": "High CPU"}},
+				{"metadata": {"name": "low-disk", "labels": {"grafana.com/group": "Node alerts"}}, "spec": {"title": "Low disk"}}
+			]
+		}`)
+		repo.On("Read", mock.Anything, "alerts/node-alerts.alertrules.json", "abc123").
+			Return(&repository.FileInfo{Data: data, Path: "alerts/node-alerts.alertrules.json"}, nil)
+
+		clients.On("ForKind", mock.Anything, AlertRuleKind).
+			Return(mockClient, AlertRuleResource, nil)
+
+		for _, name := range []string{"high-cpu", "low-disk"} {
+			grafanaObj := &unstructured.Unstructured{
+				Object: map[string]any{
+					"metadata": map[string]any{
+						"name":        name,
+						"annotations": map[string]any{utils.AnnoKeyFolder: "alerts"},
+					},
+				},
+			}
+			mockClient.On("Get", mock.Anything, name, metav1.GetOptions{}, mock.Anything).
+				Return(grafanaObj, nil).Once()
+			mockClient.On("Delete", mock.Anything, name, metav1.DeleteOptions{}, mock.Anything).
+				Return(nil).Once()
+		}
+
+		mgr := NewResourcesManager(repo, nil, nil, clients)
+		name, folderName, _, err := mgr.RemoveResourceFromFile(context.Background(), "alerts/node-alerts.alertrules.json", "abc123")
+
+		require.NoError(t, err)
+		require.Equal(t, "Node alerts", name)
+		require.Equal(t, "alerts", folderName)
+		mockClient.AssertExpectations(t)
+	})
+
 	t.Run("classic dashboard format is deleted successfully", func(t *testing.T) {
 		repo := repository.NewMockReaderWriter(t)
 		clients := NewMockResourceClients(t)
@@ -0,0 +1,273 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+)
+
+func newObject(gvk schema.GroupVersionKind, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "test"},
+		"spec":     map[string]any{"title": "High CPU"},
+	}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestResourceFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+		inFolder bool
+	}{
+		{
+			name:     "dashboard",
+			obj:      newObject(DashboardResource.GroupVersion().WithKind("Dashboard"), nil),
+			expected: "high-cpu.json",
+			inFolder: true,
+		},
+		{
+			name:     "library panel",
+			obj:      newObject(LibraryPanelKind, nil),
+			expected: "high-cpu.librarypanel.json",
+			inFolder: true,
+		},
+		{
+			name:     "alert rule in a group",
+			obj:      newObject(AlertRuleKind, map[string]string{"grafana.com/group": "Node alerts"}),
+			expected: "node-alerts.alertrules.json",
+			inFolder: true,
+		},
+		{
+			name:     "alert rule without a group",
+			obj:      newObject(AlertRuleKind, nil),
+			expected: "high-cpu.alertrules.json",
+			inFolder: true,
+		},
+		{
+			name:     "contact point",
+			obj:      newObject(ReceiverKind, nil),
+			expected: "high-cpu.contactpoint.json",
+		},
+		{
+			name:     "notification template",
+			obj:      newObject(TemplateGroupKind, nil),
+			expected: "high-cpu.template.json",
+		},
+		{
+			name:     "playlist",
+			obj:      newObject(PlaylistKind, nil),
+			expected: "high-cpu.playlist.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName, inFolder := resourceFileName(tt.obj, "High CPU")
+			require.Equal(t, tt.expected, fileName)
+			require.Equal(t, tt.inFolder, inFolder)
+		})
+	}
+}
+
+func TestToSaveBytesKeepsAlertRuleGroup(t *testing.T) {
+	obj := newObject(AlertRuleKind, map[string]string{
+		"grafana.com/group":       "node-alerts",
+		"grafana.com/group-index": "2",
+		"team":                    "infra",
+	})
+	parsed := ParsedResource{Info: &repository.FileInfo{Path: "rule.json"}, Obj: obj}
+
+	body, err := parsed.ToSaveBytes()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"apiVersion": "rules.alerting.grafana.app/v0alpha1",
+		"kind": "AlertRule",
+		"metadata": {
+			"name": "test",
+			"labels": {"grafana.com/group": "node-alerts", "grafana.com/group-index": "2"}
+		},
+		"spec": {"title": "High CPU"}
+	}`, string(body))
+
+	// Other resources only keep their name
+	parsed.Obj = newObject(PlaylistKind, map[string]string{"team": "infra"})
+	body, err = parsed.ToSaveBytes()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"apiVersion": "playlist.grafana.app/v1",
+		"kind": "Playlist",
+		"metadata": {"name": "test"},
+		"spec": {"title": "High CPU"}
+	}`, string(body))
+}
+
+func TestRuleGroupFileBytes(t *testing.T) {
+	info := &repository.FileInfo{Path: "node-alerts.alertrules.json"}
+
+	second := newObject(AlertRuleKind, map[string]string{"grafana.com/group": "node-alerts", "grafana.com/group-index": "2"})
+	second.SetName("second")
+	body, err := RuleGroupFileBytes(info, second)
+	require.NoError(t, err)
+
+	first := newObject(AlertRuleKind, map[string]string{"grafana.com/group": "node-alerts", "grafana.com/group-index": "1"})
+	first.SetName("first")
+	info.Data = body
+	body, err = RuleGroupFileBytes(info, first)
+	require.NoError(t, err)
+
+	// Replaces the rule of the same name
+	updated := second.DeepCopy()
+	updated.Object["spec"] = map[string]any{"title": "Updated"}
+	info.Data = body
+	body, err = RuleGroupFileBytes(info, updated)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"apiVersion": "rules.alerting.grafana.app/v0alpha1",
+		"kind": "AlertRuleList",
+		"items": [
+			{
+				"apiVersion": "rules.alerting.grafana.app/v0alpha1",
+				"kind": "AlertRule",
+				"metadata": {"name": "first", "labels": {"grafana.com/group": "node-alerts", "grafana.com/group-index": "1"}},
+				"spec": {"title": "High CPU"}
+			},
+			{
+				"apiVersion": "rules.alerting.grafana.app/v0alpha1",
+				"kind": "AlertRule",
+				"metadata": {"name": "second", "labels": {"grafana.com/group": "node-alerts", "grafana.com/group-index": "2"}},
+				"spec": {"title": "Updated"}
+			}
+		]
+	}`, string(body))
+
+	info.Data = body
+	files, group, err := SplitResourceFile(info)
+	require.NoError(t, err)
+	require.True(t, group)
+	require.Len(t, files, 2)
+	for i, name := range []string{"first", "second"} {
+		require.Equal(t, info.Path, files[i].Path)
+		obj, gvk, _, err := ParseFileResource(context.Background(), files[i])
+		require.NoError(t, err)
+		require.Equal(t, AlertRuleKind, *gvk)
+		require.Equal(t, name, obj.GetName())
+	}
+}
+
+func TestSplitResourceFile(t *testing.T) {
+	t.Run("single resource", func(t *testing.T) {
+		info := &repository.FileInfo{Path: "rule.json", Data: []byte(`{"apiVersion": "rules.alerting.grafana.app/v0alpha1", "kind": "AlertRule", "metadata": {"name": "test"}}`)}
+		files, group, err := SplitResourceFile(info)
+		require.NoError(t, err)
+		require.False(t, group)
+		require.Equal(t, []*repository.FileInfo{info}, files)
+	})
+
+	t.Run("rule group with another kind", func(t *testing.T) {
+		info := &repository.FileInfo{Path: "group.yaml", Data: []byte(`
+apiVersion: rules.alerting.grafana.app/v0alpha1
+kind: AlertRuleList
+items:
+- apiVersion: playlist.grafana.app/v1
+  kind: Playlist
+  metadata:
+    name: test
+`)}
+		_, _, err := SplitResourceFile(info)
+		var validationErr *ResourceValidationError
+		require.ErrorAs(t, err, &validationErr)
+	})
+}
+
+func newReceiver(integrations ...any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "oncall"},
+		"spec":     map[string]any{"title": "On call", "integrations": integrations},
+	}}
+	obj.SetGroupVersionKind(ReceiverKind)
+	return obj
+}
+
+func TestToSaveBytesRemovesSecureSettings(t *testing.T) {
+	parsed := ParsedResource{
+		Info: &repository.FileInfo{Path: "oncall.contactpoint.json"},
+		Obj: newReceiver(map[string]any{
+			"uid":          "slack-1",
+			"type":         "slack",
+			"settings":     map[string]any{"recipient": "#alerts", "token": "secret", "auth": map[string]any{"password": "secret"}},
+			"secureFields": map[string]any{"token": true, "auth.password": true, "url": false},
+		}),
+	}
+
+	body, err := parsed.ToSaveBytes()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"apiVersion": "notifications.alerting.grafana.app/v0alpha1",
+		"kind": "Receiver",
+		"metadata": {"name": "oncall"},
+		"spec": {
+			"title": "On call",
+			"integrations": [{
+				"uid": "slack-1",
+				"type": "slack",
+				"settings": {"recipient": "#alerts", "auth": {}},
+				"secureFields": {"token": true, "auth.password": true, "url": false}
+			}]
+		}
+	}`, string(body))
+	require.Equal(t, "secret", parsed.Obj.Object["spec"].(map[string]any)["integrations"].([]any)[0].(map[string]any)["settings"].(map[string]any)["token"])
+}
+
+func TestKeepSecureSettings(t *testing.T) {
+	existing := newReceiver(
+		map[string]any{
+			"uid":          "slack-1",
+			"type":         "slack",
+			"settings":     map[string]any{"recipient": "#alerts"},
+			"secureFields": map[string]any{"token": true},
+		},
+		map[string]any{
+			"uid":          "pagerduty-1",
+			"type":         "pagerduty",
+			"settings":     map[string]any{},
+			"secureFields": map[string]any{"integrationKey": true},
+		},
+		map[string]any{
+			"uid":          "webhook-1",
+			"type":         "webhook",
+			"settings":     map[string]any{"url": "http://localhost"},
+			"secureFields": map[string]any{"password": true},
+		},
+	)
+
+	obj := newReceiver(
+		// Matched by uid
+		map[string]any{"uid": "slack-1", "type": "slack", "settings": map[string]any{"recipient": "#oncall"}},
+		// Matched by type
+		map[string]any{"type": "pagerduty", "settings": map[string]any{}},
+		// The secure setting set in the file is kept
+		map[string]any{"type": "webhook", "settings": map[string]any{"url": "http://localhost", "password": "new"}},
+		// New integration
+		map[string]any{"type": "email", "settings": map[string]any{"addresses": "team@example.com"}},
+	)
+
+	keepSecureSettings(obj, existing)
+
+	integrations, _, err := unstructured.NestedSlice(obj.Object, "spec", "integrations")
+	require.NoError(t, err)
+	require.Equal(t, []any{
+		map[string]any{"uid": "slack-1", "type": "slack", "settings": map[string]any{"recipient": "#oncall"}, "secureFields": map[string]any{"token": true}},
+		map[string]any{"uid": "pagerduty-1", "type": "pagerduty", "settings": map[string]any{}, "secureFields": map[string]any{"integrationKey": true}},
+		map[string]any{"type": "webhook", "settings": map[string]any{"url": "http://localhost", "password": "new"}},
+		map[string]any{"type": "email", "settings": map[string]any{"addresses": "team@example.com"}},
+	}, integrations)
+}
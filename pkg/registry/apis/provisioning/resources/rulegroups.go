@@ -0,0 +1,185 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+
+	alertingrules "github.com/grafana/grafana/apps/alerting/rules/pkg/apis/alerting/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AlertRuleListKind is the kind of the rule group files, which hold the alert rules of a rule group.
+var AlertRuleListKind = AlertRuleKind.GroupVersion().WithKind("AlertRuleList")
+
+// SplitResourceFile returns the files of the resources held by a repository file, and whether it is a rule group file.
+// The alert rules of a rule group file are returned as files of their own, with the path and hash of the group file.
+// Any other file holds a single resource, and is returned as is.
+func SplitResourceFile(info *repository.FileInfo) ([]*repository.FileInfo, bool, error) {
+	list, ok := decodeRuleGroup(info.Data)
+	if !ok {
+		return []*repository.FileInfo{info}, false, nil
+	}
+
+	files := make([]*repository.FileInfo, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.GroupVersionKind().GroupKind() != AlertRuleKind.GroupKind() {
+			return nil, true, NewResourceValidationError(fmt.Errorf("rule group files can only hold alert rules, found %s", item.GetKind()))
+		}
+
+		data, err := json.Marshal(item.Object)
+		if err != nil {
+			return nil, true, fmt.Errorf("marshal alert rule %s: %w", item.GetName(), err)
+		}
+		file := *info
+		file.Data = data
+		files = append(files, &file)
+	}
+	return files, true, nil
+}
+
+// decodeRuleGroup decodes the alert rules of a rule group file. It returns false for any other file.
+func decodeRuleGroup(data []byte) (*unstructured.UnstructuredList, bool) {
+	obj, gvk, err := k8syaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme).
+		Decode(util.StripBOMFromBytes(data), nil, nil)
+	if err != nil || gvk == nil || gvk.GroupKind() != AlertRuleListKind.GroupKind() {
+		return nil, false
+	}
+	list, ok := obj.(*unstructured.UnstructuredList)
+	return list, ok
+}
+
+// ruleGroupFileBytes returns the rule group file in the repository with the alert rule added to it.
+func (r *ResourcesManager) ruleGroupFileBytes(ctx context.Context, filePath, ref string, rule *unstructured.Unstructured) ([]byte, error) {
+	info, err := r.repo.Read(ctx, filePath, ref)
+	switch {
+	case errors.Is(err, repository.ErrFileNotFound):
+		info = &repository.FileInfo{Path: filePath, Ref: ref}
+	case err != nil:
+		return nil, fmt.Errorf("read rule group file %s: %w", filePath, err)
+	}
+	return RuleGroupFileBytes(info, rule)
+}
+
+// RuleGroupFileBytes returns the rule group file with the alert rule added to it, or replacing the rule of the same name.
+// The rules are kept in their order in the group.
+func RuleGroupFileBytes(info *repository.FileInfo, rule *unstructured.Unstructured) ([]byte, error) {
+	var items []*unstructured.Unstructured
+	if list, ok := decodeRuleGroup(info.Data); ok {
+		for i := range list.Items {
+			if list.Items[i].GetName() != rule.GetName() {
+				items = append(items, &list.Items[i])
+			}
+		}
+	}
+	items = append(items, rule)
+
+	slices.SortStableFunc(items, func(a, b *unstructured.Unstructured) int {
+		return ruleGroupIndex(a) - ruleGroupIndex(b)
+	})
+
+	saved := make([]any, 0, len(items))
+	for _, item := range items {
+		saved = append(saved, savedObject(item))
+	}
+	return marshalFile(info.Path, map[string]any{
+		"apiVersion": AlertRuleListKind.GroupVersion().String(),
+		"kind":       AlertRuleListKind.Kind,
+		"items":      saved,
+	})
+}
+
+// ruleGroupIndex returns the position of an alert rule in its rule group.
+func ruleGroupIndex(rule *unstructured.Unstructured) int {
+	index, _ := strconv.Atoi(rule.GetLabels()[alertingrules.GroupIndexLabelKey])
+	return index
+}
+
+// writeRuleGroupFromFile writes the alert rules of a rule group file, and deletes the rules synced from the file
+// that were removed from it. It returns the name of the rule group.
+func (r *ResourcesManager) writeRuleGroupFromFile(ctx context.Context, filePath string, files []*repository.FileInfo) (string, error) {
+	var (
+		errs     []error
+		names    = make(map[string]bool, len(files))
+		complete = true
+	)
+	for _, file := range files {
+		name, _, err := r.writeResourceFromFile(ctx, filePath, file)
+		if name == "" {
+			// Without its name, the rule cannot be told apart from the removed ones
+			complete = false
+		}
+		names[name] = true
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if complete {
+		if err := r.removeRulesNotInFile(ctx, filePath, names); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ruleGroupName(filePath, files), errors.Join(errs...)
+}
+
+// ruleGroupName returns the name of the rule group of a rule group file, falling back to the file name.
+func ruleGroupName(filePath string, files []*repository.FileInfo) string {
+	for _, file := range files {
+		var rule struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(file.Data, &rule); err == nil && rule.Metadata.Labels[alertingrules.GroupLabelKey] != "" {
+			return rule.Metadata.Labels[alertingrules.GroupLabelKey]
+		}
+	}
+	return path.Base(filePath)
+}
+
+// removeRulesNotInFile deletes the alert rules synced from a rule group file that are no longer in it.
+func (r *ResourcesManager) removeRulesNotInFile(ctx context.Context, filePath string, names map[string]bool) error {
+	client, _, err := r.clients.ForResource(ctx, AlertRuleResource)
+	if err != nil {
+		return fmt.Errorf("get client for alert rules: %w", err)
+	}
+
+	var removed []string
+	repoName := r.repo.Config().GetName()
+	err = ForEach(ctx, client, func(item *unstructured.Unstructured) error {
+		if names[item.GetName()] {
+			return nil
+		}
+		meta, err := utils.MetaAccessor(item)
+		if err != nil {
+			return nil
+		}
+		manager, _ := meta.GetManagerProperties()
+		source, _ := meta.GetSourceProperties()
+		if manager.Kind == utils.ManagerKindRepo && manager.Identity == repoName && source.Path == filePath {
+			removed = append(removed, item.GetName())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("list alert rules: %w", err)
+	}
+
+	for _, name := range removed {
+		if err := client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete alert rule %s removed from %s: %w", name, filePath, err)
+		}
+	}
+	return nil
+}
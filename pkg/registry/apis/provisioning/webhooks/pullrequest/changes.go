@@ -30,6 +30,12 @@ type changeInfo struct {
 
 	// Requested image render, but it is not available
 	MissingImageRenderer bool
+
+	// The folder of the preview environment, when deployed
+	PreviewFolderURL string
+
+	// The preview environment could not be deployed
+	PreviewEnvironmentFailed bool
 }
 
 type fileChangeInfo struct {
@@ -49,6 +55,9 @@ type fileChangeInfo struct {
 	// URL where we can see a preview of this particular change
 	PreviewURL           string
 	PreviewScreenshotURL string
+
+	// URL of the resource in the preview environment
+	PreviewEnvironmentURL string
 }
 
 type evaluator struct {
@@ -13,6 +13,7 @@ type commenter struct {
 	templateDashboard     *template.Template
 	templateTable         *template.Template
 	templateRenderInfo    *template.Template
+	templatePreviewFailed *template.Template
 	showImageRendererNote bool
 }
 
@@ -21,6 +22,7 @@ func NewCommenter(showImageRendererNote bool) Commenter {
 		templateDashboard:     template.Must(template.New("dashboard").Parse(commentTemplateSingleDashboard)),
 		templateTable:         template.Must(template.New("table").Parse(commentTemplateTable)),
 		templateRenderInfo:    template.Must(template.New("setup").Parse(commentTemplateMissingImageRenderer)),
+		templatePreviewFailed: template.Must(template.New("preview").Parse(commentTemplatePreviewEnvironmentFailed)),
 		showImageRendererNote: showImageRendererNote,
 	}
 }
@@ -61,6 +63,12 @@ func (c *commenter) generateComment(_ context.Context, info changeInfo) (string,
 		}
 	}
 
+	if info.PreviewEnvironmentFailed {
+		if err := c.templatePreviewFailed.Execute(&buf, info); err != nil {
+			return "", fmt.Errorf("unable to execute template: %w", err)
+		}
+	}
+
 	return strings.TrimSpace(buf.String()), nil
 }
 
@@ -87,6 +95,10 @@ See the [original]({{.GrafanaURL}}) of {{.Title}}.
 {{- else if .PreviewURL}}
 See the [preview]({{.PreviewURL}}) of {{.Parsed.Info.Path}}.
 {{- end}}
+{{- if .PreviewEnvironmentURL}}
+
+The dashboard is deployed to a read-only [preview environment]({{.PreviewEnvironmentURL}}), which is removed when the pull request is closed.
+{{- end}}
 `
 
 const commentTemplateTable = `Hey there! 🎉
@@ -95,7 +107,11 @@ Grafana spotted some changes.
 | Action | Kind | Resource | Preview |
 |--------|------|----------|---------|
 {{- range .Changes}}
-| {{.Parsed.Action}} | {{.Kind}} | {{.ExistingLink}} | {{ if .PreviewURL}}[preview]({{.PreviewURL}}){{ end }} |
+| {{.Parsed.Action}} | {{.Kind}} | {{.ExistingLink}} | {{ if .PreviewEnvironmentURL}}[preview]({{.PreviewEnvironmentURL}}){{ else if .PreviewURL}}[preview]({{.PreviewURL}}){{ end }} |
+{{- end}}
+{{- if .PreviewFolderURL}}
+
+The dashboards are deployed to a read-only [preview folder]({{.PreviewFolderURL}}), which is removed when the pull request is closed.
 {{- end}}
 
 {{ if .SkippedFiles }}
@@ -107,6 +123,10 @@ const commentTemplateMissingImageRenderer = `
 NOTE: To enable dashboard previews in pull requests, refer to the [image rendering setup documentation](https://grafana.com/docs/grafana/latest/observability-as-code/provision-resources/git-sync-setup/#configure-webhooks-and-image-rendering).
 `
 
+const commentTemplatePreviewEnvironmentFailed = `
+WARNING: Grafana could not deploy the preview environment of this pull request. Refer to the pull request job in Grafana for details.
+`
+
 // TODO: does this have some value?
 func (f *fileChangeInfo) Kind() string {
 	if f.Parsed == nil {
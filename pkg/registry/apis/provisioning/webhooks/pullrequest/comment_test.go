@@ -126,6 +126,70 @@ func TestGenerateComment(t *testing.T) {
 				},
 			},
 		}},
+		{"update dashboard preview environment", changeInfo{
+			GrafanaBaseURL: "http://host/",
+			Changes: []fileChangeInfo{
+				{
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "file.json",
+						},
+						Action: v0alpha1.ResourceActionUpdate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:                 "Existing Dashboard",
+					GrafanaURL:            "http://grafana/d/uid",
+					PreviewURL:            "http://grafana/admin/preview",
+					PreviewEnvironmentURL: "http://grafana/d/preview-uid/existing-dashboard",
+				},
+			},
+		}},
+		{"multiple files preview environment", changeInfo{
+			GrafanaBaseURL:   "http://host/",
+			PreviewFolderURL: "http://grafana/dashboards/f/preview-folder/",
+			Changes: []fileChangeInfo{
+				{
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "aaa.json",
+						},
+						Action: v0alpha1.ResourceActionCreate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:                 "Dash A",
+					PreviewURL:            "http://grafana/admin/preview",
+					PreviewEnvironmentURL: "http://grafana/d/preview-a/dash-a",
+				},
+				{
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "bbb.json",
+						},
+						Action: v0alpha1.ResourceActionCreate,
+						GVK:    schema.GroupVersionKind{Kind: "Playlist"},
+					},
+					Title: "My Playlist",
+				},
+			},
+		}},
+		{"update dashboard preview environment failed", changeInfo{
+			GrafanaBaseURL:           "http://host/",
+			PreviewEnvironmentFailed: true,
+			Changes: []fileChangeInfo{
+				{
+					Parsed: &resources.ParsedResource{
+						Info: &repository.FileInfo{
+							Path: "file.json",
+						},
+						Action: v0alpha1.ResourceActionUpdate,
+						GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					},
+					Title:      "Existing Dashboard",
+					GrafanaURL: "http://grafana/d/uid",
+					PreviewURL: "http://grafana/admin/preview",
+				},
+			},
+		}},
 	} {
 		t.Run(tc.Name, func(t *testing.T) {
 			repo := NewMockPullRequestRepo(t)
@@ -0,0 +1,237 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	folders "github.com/grafana/grafana/apps/folder/pkg/apis/folder/v1beta1"
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+// previewLabel marks the resources of a preview environment. The value is the name of the preview folder.
+const previewLabel = provisioning.GROUP + "/preview"
+
+// previewKey identifies the preview environment of a pull request.
+// Forks can have branches with the same name, so the pull request number and the repository it comes from are used instead of the ref.
+func previewKey(opts *provisioning.PullRequestJobOptions) string {
+	return fmt.Sprintf("%d:%s", opts.PR, opts.HeadRepository)
+}
+
+// previewEnvironments deploys the dashboards changed in a branch to a temporary folder.
+//
+// The folder and its dashboards are managed by a repository that does not exist, using the folder name as identity.
+// This keeps them out of the repository sync, and any attempt to edit them is rejected, so the preview is read-only.
+type previewEnvironments struct {
+	clients resources.ClientFactory
+}
+
+func NewPreviewEnvironments(clients resources.ClientFactory) PreviewEnvironments {
+	return &previewEnvironments{clients: clients}
+}
+
+func (p *previewEnvironments) Deploy(ctx context.Context, repo *provisioning.Repository, opts *provisioning.PullRequestJobOptions, info *changeInfo) error {
+	var dashboards []*fileChangeInfo
+	for i := range info.Changes {
+		change := &info.Changes[i]
+		if change.Error == "" && change.Parsed != nil && change.Parsed.GVK.Kind == dashboardKind {
+			dashboards = append(dashboards, change)
+		}
+	}
+
+	// Nothing to preview, so remove what a previous commit may have deployed
+	if len(dashboards) == 0 {
+		return p.Remove(ctx, repo, opts)
+	}
+
+	baseURL, err := url.Parse(info.GrafanaBaseURL)
+	if err != nil {
+		return fmt.Errorf("parse base URL: %w", err)
+	}
+
+	ctx, _, err = identity.WithProvisioningIdentity(ctx, repo.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("unable to use provisioning identity: %w", err)
+	}
+
+	clients, err := p.clients.Clients(ctx, repo.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("get clients: %w", err)
+	}
+
+	folderClient, err := clients.Folder(ctx)
+	if err != nil {
+		return fmt.Errorf("get folder client: %w", err)
+	}
+
+	key := previewKey(opts)
+	folderName := resources.PreviewName("preview", key, repo.GetName())
+	if err := ensurePreviewFolder(ctx, folderClient, repo, opts, folderName); err != nil {
+		return fmt.Errorf("create preview folder: %w", err)
+	}
+	info.PreviewFolderURL = baseURL.JoinPath("dashboards/f", folderName).String() + "/"
+
+	logger := logging.FromContext(ctx).With("ref", opts.Ref, "folder", folderName)
+	deployed := make(map[string]bool, len(dashboards))
+	for _, change := range dashboards {
+		obj, err := previewObject(change.Parsed.Obj, key, repo.GetName(), folderName)
+		if err != nil {
+			return err
+		}
+
+		if err := upsert(ctx, change.Parsed.Client, obj); err != nil {
+			// The comment still links the other dashboards
+			logger.Warn("failed to deploy dashboard to preview environment", "path", change.Change.Path, "error", err)
+			continue
+		}
+
+		deployed[obj.GetName()] = true
+		change.PreviewEnvironmentURL = baseURL.JoinPath("d", obj.GetName(), slugify.Slugify(change.Title)).String()
+	}
+
+	// Dashboards that are no longer changed in the branch are removed from the preview
+	return removePreviewDashboards(ctx, clients, folderName, deployed)
+}
+
+func (p *previewEnvironments) Remove(ctx context.Context, repo *provisioning.Repository, opts *provisioning.PullRequestJobOptions) error {
+	ctx, _, err := identity.WithProvisioningIdentity(ctx, repo.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("unable to use provisioning identity: %w", err)
+	}
+
+	clients, err := p.clients.Clients(ctx, repo.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("get clients: %w", err)
+	}
+
+	folderName := resources.PreviewName("preview", previewKey(opts), repo.GetName())
+	if err := removePreviewDashboards(ctx, clients, folderName, nil); err != nil {
+		return err
+	}
+
+	folderClient, err := clients.Folder(ctx)
+	if err != nil {
+		return fmt.Errorf("get folder client: %w", err)
+	}
+
+	if err := folderClient.Delete(ctx, folderName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete preview folder: %w", err)
+	}
+
+	return nil
+}
+
+func ensurePreviewFolder(ctx context.Context, client dynamic.ResourceInterface, repo *provisioning.Repository, opts *provisioning.PullRequestJobOptions, name string) error {
+	_, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	title := repo.Spec.Title
+	if title == "" {
+		title = repo.GetName()
+	}
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"spec": map[string]any{
+				"title": fmt.Sprintf("%s preview: #%d", title, opts.PR),
+			},
+		},
+	}
+	obj.SetAPIVersion(folders.APIVERSION)
+	obj.SetKind(folders.FolderResourceInfo.GroupVersionKind().Kind)
+	obj.SetNamespace(repo.GetNamespace())
+	obj.SetName(name)
+	obj.SetLabels(map[string]string{previewLabel: name})
+
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return fmt.Errorf("create meta accessor for the object: %w", err)
+	}
+	meta.SetAnnotation(utils.AnnoKeyGrantPermissions, utils.AnnoGrantPermissionsDefault)
+	meta.SetManagerProperties(utils.ManagerProperties{
+		Kind:     utils.ManagerKindRepo,
+		Identity: name,
+	})
+
+	_, err = client.Create(ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil // created by a concurrent job for the same pull request
+	}
+	return err
+}
+
+// previewObject copies a parsed resource into the preview folder.
+func previewObject(parsed *unstructured.Unstructured, key, repoName, folderName string) (*unstructured.Unstructured, error) {
+	obj := parsed.DeepCopy()
+	obj.SetName(resources.PreviewName(parsed.GetName(), key, repoName))
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[previewLabel] = folderName
+	obj.SetLabels(labels)
+
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, fmt.Errorf("create meta accessor for the object: %w", err)
+	}
+	meta.SetFolder(folderName)
+	meta.SetDeprecatedInternalID(0) // nolint:staticcheck
+	meta.SetManagerProperties(utils.ManagerProperties{
+		Kind:     utils.ManagerKindRepo,
+		Identity: folderName,
+	})
+
+	return obj, nil
+}
+
+func upsert(ctx context.Context, client dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	existing, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	switch {
+	case err == nil:
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{FieldValidation: "Ignore"})
+	case apierrors.IsNotFound(err):
+		_, err = client.Create(ctx, obj, metav1.CreateOptions{FieldValidation: "Ignore"})
+	}
+	return err
+}
+
+// removePreviewDashboards deletes the dashboards in the preview folder, except the ones to keep.
+func removePreviewDashboards(ctx context.Context, clients resources.ResourceClients, folderName string, keep map[string]bool) error {
+	client, _, err := clients.ForResource(ctx, resources.DashboardResource)
+	if err != nil {
+		return fmt.Errorf("get dashboard client: %w", err)
+	}
+
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: previewLabel + "=" + folderName})
+	if err != nil {
+		return fmt.Errorf("list preview dashboards: %w", err)
+	}
+
+	for _, item := range list.Items {
+		if keep[item.GetName()] {
+			continue
+		}
+		if err := client.Delete(ctx, item.GetName(), metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete preview dashboard %s: %w", item.GetName(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package pullrequest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	provisioning "github.com/grafana/grafana/apps/provisioning/pkg/apis/provisioning/v0alpha1"
+	"github.com/grafana/grafana/apps/provisioning/pkg/repository"
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/registry/apis/provisioning/resources"
+)
+
+func newDashboard(name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]any{
+			"spec": map[string]any{
+				"title": name,
+			},
+		},
+	}
+	obj.SetAPIVersion("dashboard.grafana.app/v1beta1")
+	obj.SetKind("Dashboard")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestPreviewEnvironments(t *testing.T) {
+	ctx := context.Background()
+	repo := &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-repo", Namespace: "default"},
+		Spec:       provisioning.RepositorySpec{Title: "Test Repo"},
+	}
+	opts := &provisioning.PullRequestJobOptions{PR: 12, Ref: "feature", HeadRepository: "grafana/test-repo"}
+	folderName := resources.PreviewName("preview", "12:grafana/test-repo", "test-repo")
+	previewDashboard := resources.PreviewName("my-dashboard", "12:grafana/test-repo", "test-repo")
+
+	fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.DashboardResource: "DashboardList",
+		resources.FolderResource:    "FolderList",
+	})
+	dashboards := fakeClient.Resource(resources.DashboardResource).Namespace("default")
+	folders := fakeClient.Resource(resources.FolderResource).Namespace("default")
+
+	clients := resources.NewMockResourceClients(t)
+	clients.On("Folder", mock.Anything).Return(folders, nil)
+	clients.On("ForResource", mock.Anything, resources.DashboardResource).Return(dashboards, schema.GroupVersionKind{}, nil)
+	factory := resources.NewMockClientFactory(t)
+	factory.On("Clients", mock.Anything, "default").Return(clients, nil)
+
+	// A dashboard deployed by a previous commit, and one that is not part of the preview
+	_, err := dashboards.Create(ctx, newDashboard("stale", map[string]string{previewLabel: folderName}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = dashboards.Create(ctx, newDashboard("unrelated", nil), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	info := &changeInfo{
+		GrafanaBaseURL: "http://grafana/",
+		Changes: []fileChangeInfo{
+			{
+				Change: repository.VersionedFileChange{Path: "team/dashboard.json"},
+				Parsed: &resources.ParsedResource{
+					Obj:    newDashboard("my-dashboard", nil),
+					GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					Client: dashboards,
+				},
+				Title: "My Dashboard",
+			},
+			{
+				Change: repository.VersionedFileChange{Path: "invalid.json"},
+				Parsed: &resources.ParsedResource{
+					Obj:    newDashboard("invalid", nil),
+					GVK:    schema.GroupVersionKind{Kind: "Dashboard"},
+					Client: dashboards,
+				},
+				Error: "dry run failed",
+			},
+		},
+	}
+
+	previews := NewPreviewEnvironments(factory)
+	require.NoError(t, previews.Deploy(ctx, repo, opts, info))
+
+	require.Equal(t, "http://grafana/dashboards/f/"+folderName+"/", info.PreviewFolderURL)
+	require.Equal(t, "http://grafana/d/"+previewDashboard+"/my-dashboard", info.Changes[0].PreviewEnvironmentURL)
+	require.Empty(t, info.Changes[1].PreviewEnvironmentURL)
+
+	folder, err := folders.Get(ctx, folderName, metav1.GetOptions{})
+	require.NoError(t, err)
+	title, _, _ := unstructured.NestedString(folder.Object, "spec", "title")
+	require.Equal(t, "Test Repo preview: #12", title)
+
+	deployed, err := dashboards.Get(ctx, previewDashboard, metav1.GetOptions{})
+	require.NoError(t, err)
+	meta, err := utils.MetaAccessor(deployed)
+	require.NoError(t, err)
+	require.Equal(t, folderName, meta.GetFolder())
+	require.Equal(t, folderName, deployed.GetLabels()[previewLabel])
+	manager, ok := meta.GetManagerProperties()
+	require.True(t, ok)
+	require.Equal(t, utils.ManagerProperties{Kind: utils.ManagerKindRepo, Identity: folderName}, manager)
+
+	_, err = dashboards.Get(ctx, "stale", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "dashboards no longer changed are removed")
+	_, err = dashboards.Get(ctx, "invalid", metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "invalid dashboards are not deployed")
+	_, err = dashboards.Get(ctx, resources.PreviewName("invalid", "12:grafana/test-repo", "test-repo"), metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "invalid dashboards are not deployed")
+
+	// A new commit on the branch updates the same preview
+	require.NoError(t, previews.Deploy(ctx, repo, opts, info))
+
+	// A pull request from a fork with the same branch name has its own preview
+	fork := &provisioning.PullRequestJobOptions{PR: 13, Ref: "feature", HeadRepository: "contributor/test-repo"}
+	require.NoError(t, previews.Remove(ctx, repo, fork))
+	_, err = dashboards.Get(ctx, previewDashboard, metav1.GetOptions{})
+	require.NoError(t, err, "the preview of another pull request is kept")
+
+	require.NoError(t, previews.Remove(ctx, repo, opts))
+	_, err = dashboards.Get(ctx, previewDashboard, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err))
+	_, err = folders.Get(ctx, folderName, metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err))
+	_, err = dashboards.Get(ctx, "unrelated", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// Removing twice is fine, as pull requests can be closed without ever being deployed
+	require.NoError(t, previews.Remove(ctx, repo, opts))
+}
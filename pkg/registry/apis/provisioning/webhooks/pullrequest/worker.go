@@ -39,8 +39,9 @@ func ProvidePullRequestWorker(
 	screenshotRenderer := NewScreenshotRenderer(renderer, blobstore)
 	evaluator := NewEvaluator(screenshotRenderer, parsers, urlProvider, registry)
 	commenter := NewCommenter(cfg.ProvisioningAllowImageRendering)
+	previews := NewPreviewEnvironments(clients)
 
-	return NewPullRequestWorker(evaluator, commenter, registry)
+	return NewPullRequestWorker(evaluator, commenter, previews, registry)
 }
 
 //go:generate mockery --name=PullRequestRepo --structname=MockPullRequestRepo --inpackage --filename=mock_pullrequest_repo.go --with-expecter
@@ -61,17 +62,27 @@ type Commenter interface {
 	Comment(ctx context.Context, repo PullRequestRepo, pr int, changeInfo changeInfo) error
 }
 
+//go:generate mockery --name=PreviewEnvironments --structname=MockPreviewEnvironments --inpackage --filename=mock_preview_environments.go --with-expecter
+type PreviewEnvironments interface {
+	// Deploy writes the dashboards changed in a pull request to its preview environment, and links them in the change info.
+	Deploy(ctx context.Context, repo *provisioning.Repository, opts *provisioning.PullRequestJobOptions, info *changeInfo) error
+	// Remove deletes the preview environment of a pull request.
+	Remove(ctx context.Context, repo *provisioning.Repository, opts *provisioning.PullRequestJobOptions) error
+}
+
 type PullRequestWorker struct {
 	evaluator Evaluator
 	commenter Commenter
+	previews  PreviewEnvironments
 	metrics   pullRequestMetrics
 }
 
-func NewPullRequestWorker(evaluator Evaluator, commenter Commenter, registry prometheus.Registerer) *PullRequestWorker {
+func NewPullRequestWorker(evaluator Evaluator, commenter Commenter, previews PreviewEnvironments, registry prometheus.Registerer) *PullRequestWorker {
 	metrics := registerPullRequestMetrics(registry)
 	return &PullRequestWorker{
 		evaluator: evaluator,
 		commenter: commenter,
+		previews:  previews,
 		metrics:   metrics,
 	}
 }
@@ -123,6 +134,23 @@ func (c *PullRequestWorker) Process(ctx context.Context,
 		return fmt.Errorf("repository is not a pull request repository")
 	}
 
+	previewsEnabled := cfg.GitHub.GeneratePreviewEnvironments && c.previews != nil
+	if opts.Closed {
+		if !previewsEnabled {
+			progress.SetFinalMessage(ctx, "no preview environment to remove")
+			return nil
+		}
+
+		progress.SetMessage(ctx, "removing preview environment")
+		if err := c.previews.Remove(ctx, repo.Config(), opts); err != nil {
+			logger.Error("failed to remove preview environment", "error", err)
+			return fmt.Errorf("remove preview environment: %w", err)
+		}
+		outcome = utils.SuccessOutcome
+		logger.Info("preview environment removed")
+		return nil
+	}
+
 	logger.Info("process pull request")
 	defer logger.Info("pull request processed")
 
@@ -147,14 +175,29 @@ func (c *PullRequestWorker) Process(ctx context.Context,
 		return fmt.Errorf("calculate changes: %w", err)
 	}
 
+	// A failed deployment is reported in the comment, so that the author knows the preview is missing
+	var deployErr error
+	if previewsEnabled && opts.PreviewEnvironment {
+		progress.SetMessage(ctx, "deploying preview environment")
+		if err := c.previews.Deploy(ctx, repo.Config(), opts, &changeInfo); err != nil {
+			logger.Error("failed to deploy preview environment", "error", err)
+			deployErr = fmt.Errorf("deploy preview environment: %w", err)
+			changeInfo.PreviewEnvironmentFailed = true
+		}
+	}
+
 	if err := c.commenter.Comment(ctx, prRepo, opts.PR, changeInfo); err != nil {
 		c.metrics.recordCommentPosted(utils.ErrorOutcome)
-		return fmt.Errorf("comment pull request: %w", err)
+		return errors.Join(deployErr, fmt.Errorf("comment pull request: %w", err))
 	}
-	outcome = utils.SuccessOutcome
 	c.metrics.recordCommentPosted(utils.SuccessOutcome)
 	logger.Info("preview comment added")
 
+	if deployErr != nil {
+		return deployErr
+	}
+	outcome = utils.SuccessOutcome
+
 	return nil
 }
 
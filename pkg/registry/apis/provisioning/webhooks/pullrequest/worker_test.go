@@ -46,7 +46,7 @@ func TestPullRequestWorker_IsSupported(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			evaluator := NewMockEvaluator(t)
 			commenter := NewMockCommenter(t)
-			worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+			worker := NewPullRequestWorker(evaluator, commenter, nil, prometheus.NewPedanticRegistry())
 			result := worker.IsSupported(context.Background(), tt.job)
 			require.Equal(t, tt.expected, result)
 		})
@@ -70,7 +70,7 @@ func TestPullRequestWorker_Process_NotPullRequestRepository(t *testing.T) {
 		},
 	})
 
-	worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+	worker := NewPullRequestWorker(evaluator, commenter, nil, prometheus.NewPedanticRegistry())
 	job := provisioning.Job{
 		Spec: provisioning.JobSpec{
 			Action: provisioning.JobActionPullRequest,
@@ -108,7 +108,7 @@ func TestPullRequestWorker_Process_NotReaderRepository(t *testing.T) {
 		},
 	})
 
-	worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+	worker := NewPullRequestWorker(evaluator, commenter, nil, prometheus.NewPedanticRegistry())
 	job := provisioning.Job{
 		Spec: provisioning.JobSpec{
 			Action: provisioning.JobActionPullRequest,
@@ -394,7 +394,7 @@ func TestPullRequestWorker_Process(t *testing.T) {
 			progress := jobs.NewMockJobProgressRecorder(t)
 			tt.setupMocks(evaluator, commenter, &repo, progress)
 
-			worker := NewPullRequestWorker(evaluator, commenter, prometheus.NewPedanticRegistry())
+			worker := NewPullRequestWorker(evaluator, commenter, nil, prometheus.NewPedanticRegistry())
 			job := provisioning.Job{
 				Spec: provisioning.JobSpec{
 					Action:      provisioning.JobActionPullRequest,
@@ -417,6 +417,119 @@ func TestPullRequestWorker_Process(t *testing.T) {
 	}
 }
 
+func TestPullRequestWorker_Process_PreviewEnvironments(t *testing.T) {
+	config := &provisioning.Repository{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-repo",
+		},
+		Spec: provisioning.RepositorySpec{
+			Title: "test-repo",
+			GitHub: &provisioning.GitHubRepositoryConfig{
+				Branch:                      "main",
+				GeneratePreviewEnvironments: true,
+			},
+		},
+	}
+
+	newWorker := func(t *testing.T) (*PullRequestWorker, *MockEvaluator, *MockCommenter, *MockPreviewEnvironments, mockPullRequestRepo, *jobs.MockJobProgressRecorder) {
+		evaluator := NewMockEvaluator(t)
+		commenter := NewMockCommenter(t)
+		previews := NewMockPreviewEnvironments(t)
+		repo := mockPullRequestRepo{
+			MockRepository:      repository.NewMockRepository(t),
+			MockPullRequestRepo: NewMockPullRequestRepo(t),
+		}
+		progress := jobs.NewMockJobProgressRecorder(t)
+		worker := NewPullRequestWorker(evaluator, commenter, previews, prometheus.NewPedanticRegistry())
+		return worker, evaluator, commenter, previews, repo, progress
+	}
+
+	job := func(opts provisioning.PullRequestJobOptions) provisioning.Job {
+		return provisioning.Job{
+			Spec: provisioning.JobSpec{
+				Action:      provisioning.JobActionPullRequest,
+				PullRequest: &opts,
+			},
+		}
+	}
+
+	previewJob := provisioning.PullRequestJobOptions{PR: 123, Ref: "feature", HeadRepository: "grafana/grafana", PreviewEnvironment: true}
+
+	t.Run("deploys the preview before commenting", func(t *testing.T) {
+		worker, evaluator, commenter, previews, repo, progress := newWorker(t)
+		repo.MockRepository.On("Config").Return(config)
+		progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
+		progress.On("SetMessage", mock.Anything, "deploying preview environment").Return()
+		repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "feature").
+			Return([]repository.VersionedFileChange{{Path: "dashboard.json"}}, nil)
+		evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(changeInfo{}, nil)
+		previews.On("Deploy", mock.Anything, config, &previewJob, mock.Anything).
+			Run(func(args mock.Arguments) {
+				args.Get(3).(*changeInfo).PreviewFolderURL = "http://grafana/dashboards/f/preview/"
+			}).Return(nil)
+		commenter.On("Comment", mock.Anything, mock.Anything, 123, changeInfo{PreviewFolderURL: "http://grafana/dashboards/f/preview/"}).Return(nil)
+
+		err := worker.Process(context.Background(), repo, job(previewJob), progress)
+		require.NoError(t, err)
+	})
+
+	t.Run("does not deploy pull requests without preview", func(t *testing.T) {
+		worker, evaluator, commenter, _, repo, progress := newWorker(t)
+		repo.MockRepository.On("Config").Return(config)
+		progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
+		repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "feature").
+			Return([]repository.VersionedFileChange{{Path: "dashboard.json"}}, nil)
+		evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(changeInfo{}, nil)
+		commenter.On("Comment", mock.Anything, mock.Anything, 123, changeInfo{}).Return(nil)
+
+		fork := provisioning.PullRequestJobOptions{PR: 123, Ref: "feature", HeadRepository: "contributor/grafana"}
+		err := worker.Process(context.Background(), repo, job(fork), progress)
+		require.NoError(t, err)
+	})
+
+	t.Run("comments the failure when the preview cannot be deployed", func(t *testing.T) {
+		worker, evaluator, commenter, previews, repo, progress := newWorker(t)
+		repo.MockRepository.On("Config").Return(config)
+		progress.On("SetMessage", mock.Anything, "listing pull request files").Return()
+		progress.On("SetMessage", mock.Anything, "deploying preview environment").Return()
+		repo.MockPullRequestRepo.On("CompareFiles", mock.Anything, "main", "feature").
+			Return([]repository.VersionedFileChange{{Path: "dashboard.json"}}, nil)
+		evaluator.On("Evaluate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(changeInfo{}, nil)
+		previews.On("Deploy", mock.Anything, config, &previewJob, mock.Anything).Return(errors.New("forbidden"))
+		commenter.On("Comment", mock.Anything, mock.Anything, 123, changeInfo{PreviewEnvironmentFailed: true}).Return(nil)
+
+		err := worker.Process(context.Background(), repo, job(previewJob), progress)
+		require.EqualError(t, err, "deploy preview environment: forbidden")
+	})
+
+	t.Run("closed pull request removes the preview", func(t *testing.T) {
+		worker, _, _, previews, repo, progress := newWorker(t)
+		repo.MockRepository.On("Config").Return(config)
+		progress.On("SetMessage", mock.Anything, "removing preview environment").Return()
+		closed := provisioning.PullRequestJobOptions{PR: 123, Ref: "feature", HeadRepository: "grafana/grafana", Closed: true}
+		previews.On("Remove", mock.Anything, config, &closed).Return(nil)
+
+		err := worker.Process(context.Background(), repo, job(closed), progress)
+		require.NoError(t, err)
+	})
+
+	t.Run("closed pull request without preview environments", func(t *testing.T) {
+		worker, _, _, _, repo, progress := newWorker(t)
+		repo.MockRepository.On("Config").Return(&provisioning.Repository{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-repo",
+			},
+			Spec: provisioning.RepositorySpec{
+				GitHub: &provisioning.GitHubRepositoryConfig{Branch: "main"},
+			},
+		})
+		progress.On("SetFinalMessage", mock.Anything, "no preview environment to remove").Return()
+
+		err := worker.Process(context.Background(), repo, job(provisioning.PullRequestJobOptions{PR: 123, Ref: "feature", Closed: true}), progress)
+		require.NoError(t, err)
+	})
+}
+
 type mockPullRequestRepo struct {
 	*repository.MockRepository
 	*MockPullRequestRepo
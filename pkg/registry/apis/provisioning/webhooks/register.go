@@ -89,7 +89,8 @@ func ProvideWebhooksWithImages(
 
 			evaluator := pullrequest.NewEvaluator(screenshotRenderer, parsers, urlProvider, registry)
 			commenter := pullrequest.NewCommenter(cfg.ProvisioningAllowImageRendering)
-			pullRequestWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, registry)
+			previews := pullrequest.NewPreviewEnvironments(clients)
+			pullRequestWorker := pullrequest.NewPullRequestWorker(evaluator, commenter, previews, registry)
 
 			return NewWebhookExtraWithImages(
 				render,
@@ -0,0 +1,69 @@
+package contracts
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
+)
+
+var (
+	ErrDataKeyRotationNotFound   = errors.New("data key rotation not found")
+	ErrDataKeyRotationInProgress = errors.New("data key rotation already in progress for namespace")
+)
+
+type DataKeyRotationPhase string
+
+const (
+	DataKeyRotationPhaseRunning   DataKeyRotationPhase = "running"
+	DataKeyRotationPhaseCompleted DataKeyRotationPhase = "completed"
+	DataKeyRotationPhaseFailed    DataKeyRotationPhase = "failed"
+)
+
+// DataKeyRotation tracks the progress of re-encrypting every encrypted value in a namespace with a new data key.
+// It does not have a mirrored K8s resource.
+type DataKeyRotation struct {
+	Namespace string
+	Phase     DataKeyRotationPhase
+
+	// The last encrypted value that was processed. Encrypted values are visited ordered by name and version,
+	// so an interrupted rotation resumes right after this position.
+	CursorName    string
+	CursorVersion int64
+
+	// Number of encrypted values in the namespace when the rotation started.
+	Total int64
+	// Number of encrypted values visited so far.
+	Processed int64
+	// Number of encrypted values that were re-encrypted with the new data key.
+	Rotated int64
+	// Number of encrypted values that could not be re-encrypted.
+	Failed int64
+
+	Message  string
+	Started  int64
+	Updated  int64
+	Finished int64
+}
+
+// IsFinished returns whether the rotation reached a terminal phase.
+func (r *DataKeyRotation) IsFinished() bool {
+	return r.Phase == DataKeyRotationPhaseCompleted || r.Phase == DataKeyRotationPhaseFailed
+}
+
+// DataKeyRotationStorage persists the progress of data key rotations so they can be resumed.
+type DataKeyRotationStorage interface {
+	Create(ctx context.Context, rotation *DataKeyRotation) error
+	Read(ctx context.Context, namespace xkube.Namespace) (*DataKeyRotation, error)
+	Update(ctx context.Context, rotation *DataKeyRotation) error
+}
+
+// DataKeyRotationService rotates the data keys of a namespace and re-encrypts its encrypted values online.
+type DataKeyRotationService interface {
+	// Rotate disables the data keys currently in use by the namespace and re-encrypts every encrypted value with a new data key,
+	// which is itself encrypted by the current encryption provider.
+	// If a previous rotation of the namespace was interrupted, it is resumed instead of started over.
+	Rotate(ctx context.Context, namespace xkube.Namespace) (*DataKeyRotation, error)
+	// Status returns the progress of the latest rotation of the namespace.
+	Status(ctx context.Context, namespace xkube.Namespace) (*DataKeyRotation, error)
+}
@@ -41,11 +41,21 @@ type ListOpts struct {
 	Offset int64
 }
 
+// NamespacedListOpts defines keyset pagination options for listing the encrypted values of a namespace.
+// Values are ordered by name and version, and only the ones sorting after AfterName and AfterVersion are returned.
+type NamespacedListOpts struct {
+	Limit        int64
+	AfterName    string
+	AfterVersion int64
+}
+
 type EncryptedValueStorage interface {
 	Create(ctx context.Context, namespace xkube.Namespace, name string, version int64, encryptedData EncryptedPayload) (*EncryptedValue, error)
 	Update(ctx context.Context, namespace xkube.Namespace, name string, version int64, encryptedData EncryptedPayload) error
 	Get(ctx context.Context, namespace xkube.Namespace, name string, version int64) (*EncryptedValue, error)
 	Delete(ctx context.Context, namespace xkube.Namespace, name string, version int64) error
+	List(ctx context.Context, namespace xkube.Namespace, opts NamespacedListOpts) ([]*EncryptedValue, error)
+	Count(ctx context.Context, namespace xkube.Namespace) (int64, error)
 }
 
 type GlobalEncryptedValueStorage interface {
@@ -18,23 +18,60 @@ const (
 
 // ProvideOSSKMSProviders provides the ProviderConfig expected by the encryption manager in the OSS wire configuration.
 // It looks for all configured 'secret_key' sections and creates a separate provider for each, each with its own secret key, allowing users to upgrade their secret key without breaking existing secrets.
+// 'pkcs11' and 'remote_kms' sections create providers which keep the key encryption key outside of Grafana.
 func ProvideOSSKMSProviders(cfg *setting.Cfg, cipher cipher.Cipher) (encryption.ProviderConfig, error) {
 	pCfg := encryption.ProviderConfig{
 		CurrentProvider:    encryption.ProviderID(cfg.SecretsManagement.CurrentEncryptionProvider),
 		AvailableProviders: make(encryption.ProviderMap),
+		NamespaceProviders: make(map[string]encryption.ProviderID, len(cfg.SecretsManagement.NamespaceEncryptionProviders)),
 	}
 
-	// Look through the available secret_key providers and add them to the map
+	if err := validateKMSProviders(cfg); err != nil {
+		return pCfg, err
+	}
+
+	// Look through the available providers and add them to the map
 	for providerName, properties := range cfg.SecretsManagement.ConfiguredKMSProviders {
-		if strings.HasPrefix(providerName, OSSProviderType) {
+		switch {
+		case strings.HasPrefix(providerName, OSSProviderType):
 			secretKey := properties[SecretKeyKey]
 			if secretKey != "" {
 				pCfg.AvailableProviders[encryption.ProviderID(providerName)] = newSecretKeyProvider(secretKey, cipher)
 			} else {
 				return pCfg, fmt.Errorf("missing secret_key for provider %s", providerName)
 			}
+		case strings.HasPrefix(providerName, PKCS11ProviderType+"."):
+			provider, err := newPKCS11Provider(properties)
+			if err != nil {
+				return pCfg, fmt.Errorf("configuring provider %s: %w", providerName, err)
+			}
+			pCfg.AvailableProviders[encryption.ProviderID(providerName)] = provider
+		case strings.HasPrefix(providerName, RemoteKMSProviderType+"."):
+			provider, err := newRemoteKMSProvider(properties)
+			if err != nil {
+				return pCfg, fmt.Errorf("configuring provider %s: %w", providerName, err)
+			}
+			pCfg.AvailableProviders[encryption.ProviderID(providerName)] = provider
 		}
 	}
 
+	for namespace, providerName := range cfg.SecretsManagement.NamespaceEncryptionProviders {
+		pCfg.NamespaceProviders[namespace] = encryption.ProviderID(providerName)
+	}
+
 	return pCfg, nil
 }
+
+// validateKMSProviders checks the configuration of the providers before any of them is created,
+// so a provider which cannot be used by this build is reported instead of failing after the others were set up.
+func validateKMSProviders(cfg *setting.Cfg) error {
+	for providerName, properties := range cfg.SecretsManagement.ConfiguredKMSProviders {
+		if !strings.HasPrefix(providerName, PKCS11ProviderType+".") {
+			continue
+		}
+		if err := validatePKCS11Provider(properties); err != nil {
+			return fmt.Errorf("invalid provider %s: %w", providerName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package kmsproviders
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/registry/apis/secret/encryption"
+)
+
+const (
+	// PKCS11ProviderType is the identifier of the kms provider which encrypts data keys with a secret key stored in a PKCS#11 token (e.g. an HSM or SoftHSM)
+	PKCS11ProviderType = "pkcs11"
+
+	PKCS11ModulePathKey = "module_path"
+	PKCS11TokenLabelKey = "token_label"
+	PKCS11PinKey        = "pin"
+	PKCS11KeyLabelKey   = "key_label"
+
+	pkcs11GCMNonceSize = 12
+)
+
+var ErrPKCS11Unavailable = errors.New("this build of Grafana cannot load PKCS#11 modules: it must be built with cgo and the pkcs11 build tag")
+
+// PKCS11Session is the subset of a logged-in PKCS#11 session used by the provider.
+// Implementations wrap a loaded PKCS#11 module, and must be safe for concurrent use.
+type PKCS11Session interface {
+	// EncryptAESGCM encrypts the plaintext with the CKM_AES_GCM mechanism, using the secret key labeled keyLabel in the token.
+	EncryptAESGCM(keyLabel string, nonce, plaintext []byte) ([]byte, error)
+	// DecryptAESGCM decrypts the ciphertext with the CKM_AES_GCM mechanism, using the secret key labeled keyLabel in the token.
+	DecryptAESGCM(keyLabel string, nonce, ciphertext []byte) ([]byte, error)
+	Close() error
+}
+
+// PKCS11SessionOpener loads the PKCS#11 module at modulePath and logs into the token labeled tokenLabel.
+type PKCS11SessionOpener func(modulePath, tokenLabel, pin string) (PKCS11Session, error)
+
+var (
+	pkcs11OpenerMu sync.RWMutex
+	pkcs11Opener   PKCS11SessionOpener
+)
+
+// RegisterPKCS11SessionOpener sets the function used to open sessions for "pkcs11" providers.
+// Loading a PKCS#11 module requires cgo, so the binding in pkcs11_session.go is only built with the pkcs11 build tag,
+// e.g. `make build-go GO_BUILD_TAGS=pkcs11`, and registers itself.
+// Without it, configuring a "pkcs11" provider fails when the providers are validated, before any of them is created.
+func RegisterPKCS11SessionOpener(opener PKCS11SessionOpener) {
+	pkcs11OpenerMu.Lock()
+	defer pkcs11OpenerMu.Unlock()
+	pkcs11Opener = opener
+}
+
+func pkcs11Available() bool {
+	pkcs11OpenerMu.RLock()
+	defer pkcs11OpenerMu.RUnlock()
+	return pkcs11Opener != nil
+}
+
+// validatePKCS11Provider checks the properties of a "pkcs11" provider section, and that sessions can be opened by this build
+func validatePKCS11Provider(properties map[string]string) error {
+	for _, key := range []string{PKCS11ModulePathKey, PKCS11TokenLabelKey, PKCS11KeyLabelKey} {
+		if properties[key] == "" {
+			return fmt.Errorf("missing %s", key)
+		}
+	}
+	if !pkcs11Available() {
+		return ErrPKCS11Unavailable
+	}
+	return nil
+}
+
+func openPKCS11Session(modulePath, tokenLabel, pin string) (PKCS11Session, error) {
+	pkcs11OpenerMu.RLock()
+	defer pkcs11OpenerMu.RUnlock()
+
+	if pkcs11Opener == nil {
+		return nil, ErrPKCS11Unavailable
+	}
+	return pkcs11Opener(modulePath, tokenLabel, pin)
+}
+
+type pkcs11Provider struct {
+	session  PKCS11Session
+	keyLabel string
+}
+
+func newPKCS11Provider(properties map[string]string) (encryption.Provider, error) {
+	if err := validatePKCS11Provider(properties); err != nil {
+		return nil, err
+	}
+	tokenLabel := properties[PKCS11TokenLabelKey]
+	keyLabel := properties[PKCS11KeyLabelKey]
+
+	session, err := openPKCS11Session(properties[PKCS11ModulePathKey], tokenLabel, properties[PKCS11PinKey])
+	if err != nil {
+		return nil, fmt.Errorf("opening PKCS#11 session on token %s: %w", tokenLabel, err)
+	}
+
+	return &pkcs11Provider{
+		session:  session,
+		keyLabel: keyLabel,
+	}, nil
+}
+
+// Encrypt returns the nonce followed by the ciphertext, so that it can be decrypted without any other state.
+func (p *pkcs11Provider) Encrypt(_ context.Context, blob []byte) ([]byte, error) {
+	nonce := make([]byte, pkcs11GCMNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := p.session.EncryptAESGCM(p.keyLabel, nonce, blob)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting with PKCS#11 key %s: %w", p.keyLabel, err)
+	}
+
+	return append(nonce, ciphertext...), nil
+}
+
+func (p *pkcs11Provider) Decrypt(_ context.Context, blob []byte) ([]byte, error) {
+	if len(blob) <= pkcs11GCMNonceSize {
+		return nil, errors.New("unable to decrypt PKCS#11 payload: payload too short")
+	}
+
+	decrypted, err := p.session.DecryptAESGCM(p.keyLabel, blob[:pkcs11GCMNonceSize], blob[pkcs11GCMNonceSize:])
+	if err != nil {
+		return nil, fmt.Errorf("decrypting with PKCS#11 key %s: %w", p.keyLabel, err)
+	}
+
+	return decrypted, nil
+}
@@ -0,0 +1,153 @@
+package kmsproviders
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakePKCS11Session stands in for a SoftHSM token holding AES keys.
+type fakePKCS11Session struct {
+	keys map[string][]byte
+}
+
+func (s *fakePKCS11Session) gcm(keyLabel string) (cipher.AEAD, error) {
+	key, ok := s.keys[keyLabel]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", keyLabel)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fakePKCS11Session) EncryptAESGCM(keyLabel string, nonce, plaintext []byte) ([]byte, error) {
+	gcm, err := s.gcm(keyLabel)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func (s *fakePKCS11Session) DecryptAESGCM(keyLabel string, nonce, ciphertext []byte) ([]byte, error) {
+	gcm, err := s.gcm(keyLabel)
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func (s *fakePKCS11Session) Close() error { return nil }
+
+func registerFakePKCS11(t *testing.T) {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	usePKCS11SessionOpener(t, func(modulePath, tokenLabel, pin string) (PKCS11Session, error) {
+		if pin != "1234" {
+			return nil, fmt.Errorf("CKR_PIN_INCORRECT")
+		}
+		return &fakePKCS11Session{keys: map[string][]byte{"kek": key}}, nil
+	})
+}
+
+// usePKCS11SessionOpener registers the opener for the test, restoring the one registered by the build afterwards.
+func usePKCS11SessionOpener(t *testing.T, opener PKCS11SessionOpener) {
+	t.Helper()
+
+	pkcs11OpenerMu.RLock()
+	registered := pkcs11Opener
+	pkcs11OpenerMu.RUnlock()
+
+	RegisterPKCS11SessionOpener(opener)
+	t.Cleanup(func() { RegisterPKCS11SessionOpener(registered) })
+}
+
+func TestPKCS11Provider(t *testing.T) {
+	properties := map[string]string{
+		PKCS11ModulePathKey: "/usr/lib/softhsm/libsofthsm2.so",
+		PKCS11TokenLabelKey: "grafana",
+		PKCS11PinKey:        "1234",
+		PKCS11KeyLabelKey:   "kek",
+	}
+
+	t.Run("without a registered module loader the provider cannot be created", func(t *testing.T) {
+		usePKCS11SessionOpener(t, nil)
+
+		_, err := newPKCS11Provider(properties)
+		require.ErrorIs(t, err, ErrPKCS11Unavailable)
+	})
+
+	t.Run("without a registered module loader the configuration is rejected", func(t *testing.T) {
+		usePKCS11SessionOpener(t, nil)
+
+		cfg := setting.NewCfg()
+		cfg.SecretsManagement.CurrentEncryptionProvider = "secret_key.v1"
+		cfg.SecretsManagement.ConfiguredKMSProviders = map[string]map[string]string{
+			"secret_key.v1": {SecretKeyKey: "SW2YcwTIb9zpOOhoPsMm"},
+			"pkcs11.hsm":    properties,
+		}
+
+		_, err := ProvideOSSKMSProviders(cfg, nil)
+		require.ErrorIs(t, err, ErrPKCS11Unavailable)
+		require.ErrorContains(t, err, "invalid provider pkcs11.hsm")
+	})
+
+	t.Run("required properties must be set", func(t *testing.T) {
+		registerFakePKCS11(t)
+
+		for _, key := range []string{PKCS11ModulePathKey, PKCS11TokenLabelKey, PKCS11KeyLabelKey} {
+			missing := map[string]string{}
+			for k, v := range properties {
+				if k != key {
+					missing[k] = v
+				}
+			}
+			_, err := newPKCS11Provider(missing)
+			require.ErrorContains(t, err, "missing "+key)
+		}
+	})
+
+	t.Run("login errors are returned", func(t *testing.T) {
+		registerFakePKCS11(t)
+
+		_, err := newPKCS11Provider(map[string]string{
+			PKCS11ModulePathKey: "/usr/lib/softhsm/libsofthsm2.so",
+			PKCS11TokenLabelKey: "grafana",
+			PKCS11PinKey:        "0000",
+			PKCS11KeyLabelKey:   "kek",
+		})
+		require.ErrorContains(t, err, "CKR_PIN_INCORRECT")
+	})
+
+	t.Run("encrypted payloads can be decrypted", func(t *testing.T) {
+		registerFakePKCS11(t)
+
+		provider, err := newPKCS11Provider(properties)
+		require.NoError(t, err)
+
+		plaintext := []byte("data key")
+		encrypted, err := provider.Encrypt(context.Background(), plaintext)
+		require.NoError(t, err)
+		require.NotContains(t, string(encrypted), string(plaintext))
+
+		decrypted, err := provider.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+
+		_, err = provider.Decrypt(context.Background(), encrypted[:pkcs11GCMNonceSize])
+		require.Error(t, err)
+	})
+}
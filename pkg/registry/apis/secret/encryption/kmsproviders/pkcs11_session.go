@@ -0,0 +1,178 @@
+//go:build pkcs11 && cgo
+
+package kmsproviders
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+func init() {
+	RegisterPKCS11SessionOpener(openPKCS11ModuleSession)
+}
+
+// pkcs11GCMTagBits is the size of the authentication tag appended to the ciphertext, the default of crypto/cipher
+const pkcs11GCMTagBits = 128
+
+// pkcs11ModuleSession is a session logged into a token of a loaded PKCS#11 module.
+// A PKCS#11 session runs a single operation at a time, so operations are serialized.
+type pkcs11ModuleSession struct {
+	mu          sync.Mutex
+	ctx         *pkcs11.Ctx
+	session     pkcs11.SessionHandle
+	initialized bool
+	keys        map[string]pkcs11.ObjectHandle
+}
+
+func openPKCS11ModuleSession(modulePath, tokenLabel, pin string) (PKCS11Session, error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("loading PKCS#11 module %s", modulePath)
+	}
+
+	s := &pkcs11ModuleSession{ctx: ctx, keys: map[string]pkcs11.ObjectHandle{}}
+	// The module may already be initialized by the provider of another token
+	err := ctx.Initialize()
+	switch {
+	case err == nil:
+		s.initialized = true
+	case !errors.Is(err, pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED)):
+		ctx.Destroy()
+		return nil, fmt.Errorf("initializing PKCS#11 module %s: %w", modulePath, err)
+	}
+
+	slot, err := findPKCS11TokenSlot(ctx, tokenLabel)
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	s.session, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("opening session: %w", err)
+	}
+
+	// Sessions of an application share the login state of the token
+	if err := ctx.Login(s.session, pkcs11.CKU_USER, pin); err != nil && !errors.Is(err, pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)) {
+		_ = s.Close()
+		return nil, fmt.Errorf("logging in: %w", err)
+	}
+
+	return s, nil
+}
+
+// findPKCS11TokenSlot returns the slot of the token labeled tokenLabel.
+func findPKCS11TokenSlot(ctx *pkcs11.Ctx, tokenLabel string) (uint, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("listing slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, fmt.Errorf("reading token of slot %d: %w", slot, err)
+		}
+		if info.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("token %s not found", tokenLabel)
+}
+
+// findKey returns the secret key labeled keyLabel. The handles are only valid in this session, so they are cached here.
+func (s *pkcs11ModuleSession) findKey(keyLabel string) (pkcs11.ObjectHandle, error) {
+	if key, ok := s.keys[keyLabel]; ok {
+		return key, nil
+	}
+
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, fmt.Errorf("finding key: %w", err)
+	}
+	keys, _, err := s.ctx.FindObjects(s.session, 2)
+	if finalErr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("finding key: %w", err)
+	}
+
+	switch len(keys) {
+	case 0:
+		return 0, errors.New("key not found")
+	case 1:
+		s.keys[keyLabel] = keys[0]
+		return keys[0], nil
+	default:
+		return 0, errors.New("several keys have the label")
+	}
+}
+
+func (s *pkcs11ModuleSession) EncryptAESGCM(keyLabel string, nonce, plaintext []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.findKey(keyLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pkcs11.NewGCMParams(nonce, nil, pkcs11GCMTagBits)
+	defer params.Free()
+	if err := s.ctx.EncryptInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, key); err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.ctx.Encrypt(s.session, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some tokens generate their own nonce, which the provider would not store
+	if !bytes.Equal(params.IV(), nonce) {
+		return nil, errors.New("the token replaced the nonce")
+	}
+	return ciphertext, nil
+}
+
+func (s *pkcs11ModuleSession) DecryptAESGCM(keyLabel string, nonce, ciphertext []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, err := s.findKey(keyLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	params := pkcs11.NewGCMParams(nonce, nil, pkcs11GCMTagBits)
+	defer params.Free()
+	if err := s.ctx.DecryptInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, key); err != nil {
+		return nil, err
+	}
+	return s.ctx.Decrypt(s.session, ciphertext)
+}
+
+// Close closes the session, and unloads the module when it was initialized by this session.
+func (s *pkcs11ModuleSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	if s.session != 0 {
+		errs = append(errs, s.ctx.CloseSession(s.session))
+		s.session = 0
+	}
+	if s.initialized {
+		errs = append(errs, s.ctx.Finalize())
+		s.initialized = false
+	}
+	s.ctx.Destroy()
+	return errors.Join(errs...)
+}
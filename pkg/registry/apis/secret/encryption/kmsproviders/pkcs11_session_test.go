@@ -0,0 +1,158 @@
+//go:build pkcs11 && cgo
+
+package kmsproviders
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util/testutil"
+)
+
+// softHSMModulePaths are the usual install locations of the SoftHSM module, SOFTHSM2_MODULE takes precedence
+var softHSMModulePaths = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/lib/aarch64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+	"/opt/homebrew/lib/softhsm/libsofthsm2.so",
+}
+
+func TestIntegrationPKCS11Provider_SoftHSM(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	modulePath := findSoftHSMModule(t)
+	setupSoftHSMToken(t, modulePath, "grafana", "1234", "kek")
+
+	properties := map[string]string{
+		PKCS11ModulePathKey: modulePath,
+		PKCS11TokenLabelKey: "grafana",
+		PKCS11PinKey:        "1234",
+		PKCS11KeyLabelKey:   "kek",
+	}
+
+	t.Run("encrypted payloads can be decrypted", func(t *testing.T) {
+		provider, err := newPKCS11Provider(properties)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, provider.(*pkcs11Provider).session.Close()) })
+
+		plaintext := []byte("data key")
+		encrypted, err := provider.Encrypt(context.Background(), plaintext)
+		require.NoError(t, err)
+		require.NotContains(t, string(encrypted), string(plaintext))
+
+		decrypted, err := provider.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+
+		encrypted[len(encrypted)-1] ^= 1
+		_, err = provider.Decrypt(context.Background(), encrypted)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		provider, err := newPKCS11Provider(map[string]string{
+			PKCS11ModulePathKey: modulePath,
+			PKCS11TokenLabelKey: "grafana",
+			PKCS11PinKey:        "1234",
+			PKCS11KeyLabelKey:   "unknown",
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, provider.(*pkcs11Provider).session.Close()) })
+
+		_, err = provider.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "key not found")
+	})
+
+	t.Run("login errors are returned", func(t *testing.T) {
+		_, err := newPKCS11Provider(map[string]string{
+			PKCS11ModulePathKey: modulePath,
+			PKCS11TokenLabelKey: "grafana",
+			PKCS11PinKey:        "0000",
+			PKCS11KeyLabelKey:   "kek",
+		})
+		require.ErrorContains(t, err, "CKR_PIN_INCORRECT")
+	})
+
+	t.Run("unknown tokens are rejected", func(t *testing.T) {
+		_, err := newPKCS11Provider(map[string]string{
+			PKCS11ModulePathKey: modulePath,
+			PKCS11TokenLabelKey: "unknown",
+			PKCS11PinKey:        "1234",
+			PKCS11KeyLabelKey:   "kek",
+		})
+		require.ErrorContains(t, err, "token unknown not found")
+	})
+}
+
+func findSoftHSMModule(t *testing.T) string {
+	t.Helper()
+
+	paths := softHSMModulePaths
+	if path := os.Getenv("SOFTHSM2_MODULE"); path != "" {
+		paths = []string{path}
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	t.Skip("SoftHSM is not installed, set SOFTHSM2_MODULE to the path of libsofthsm2.so")
+	return ""
+}
+
+// setupSoftHSMToken initializes a token in a SoftHSM store of its own, holding an AES key for the provider.
+func setupSoftHSMToken(t *testing.T, modulePath, tokenLabel, pin, keyLabel string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	tokenDir := filepath.Join(dir, "tokens")
+	require.NoError(t, os.Mkdir(tokenDir, 0o750))
+	conf := filepath.Join(dir, "softhsm2.conf")
+	require.NoError(t, os.WriteFile(conf, []byte("directories.tokendir = "+tokenDir+"\nobjectstore.backend = file\n"), 0o600))
+	t.Setenv("SOFTHSM2_CONF", conf)
+
+	ctx := pkcs11.New(modulePath)
+	require.NotNil(t, ctx)
+	require.NoError(t, ctx.Initialize())
+	defer func() {
+		require.NoError(t, ctx.Finalize())
+		ctx.Destroy()
+	}()
+
+	// SoftHSM always has a slot with an uninitialized token
+	slots, err := ctx.GetSlotList(true)
+	require.NoError(t, err)
+	require.NotEmpty(t, slots)
+	require.NoError(t, ctx.InitToken(slots[0], "so-pin", tokenLabel))
+
+	// The initialized token is moved to a slot of its own
+	slot, err := findPKCS11TokenSlot(ctx, tokenLabel)
+	require.NoError(t, err)
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, ctx.CloseSession(session)) }()
+
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_SO, "so-pin"))
+	require.NoError(t, ctx.InitPIN(session, pin))
+	require.NoError(t, ctx.Logout(session))
+
+	require.NoError(t, ctx.Login(session, pkcs11.CKU_USER, pin))
+	_, err = ctx.GenerateKey(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_KEY_GEN, nil)}, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, 32),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyLabel),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ENCRYPT, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DECRYPT, true),
+	})
+	require.NoError(t, err)
+	require.NoError(t, ctx.Logout(session))
+}
@@ -0,0 +1,182 @@
+package kmsproviders
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/registry/apis/secret/encryption"
+)
+
+const (
+	// RemoteKMSProviderType is the identifier of the kms provider which delegates the encryption of data keys to a remote key service.
+	// The key service exposes KMIP-style Encrypt and Decrypt operations on a managed key, over HTTPS:
+	//
+	//	POST <endpoint>/keys/<key_id>/encrypt  {"plaintext": "<base64>"}  -> {"ciphertext": "<base64>"}
+	//	POST <endpoint>/keys/<key_id>/decrypt  {"ciphertext": "<base64>"} -> {"plaintext": "<base64>"}
+	RemoteKMSProviderType = "remote_kms"
+
+	RemoteKMSEndpointKey      = "endpoint"
+	RemoteKMSKeyIDKey         = "key_id"
+	RemoteKMSTokenKey         = "token"
+	RemoteKMSTLSCACertKey     = "tls_ca_cert"
+	RemoteKMSTLSClientCertKey = "tls_client_cert"
+	RemoteKMSTLSClientKeyKey  = "tls_client_key"
+	RemoteKMSTimeoutKey       = "timeout"
+
+	defaultRemoteKMSTimeout = 10 * time.Second
+)
+
+type remoteKMSProvider struct {
+	client   *http.Client
+	endpoint string
+	keyID    string
+	token    string
+}
+
+type remoteKMSEncryptRequest struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+type remoteKMSEncryptResponse struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type remoteKMSDecryptRequest struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type remoteKMSDecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+func newRemoteKMSProvider(properties map[string]string) (encryption.Provider, error) {
+	endpoint := strings.TrimSuffix(properties[RemoteKMSEndpointKey], "/")
+	if endpoint == "" {
+		return nil, fmt.Errorf("missing %s", RemoteKMSEndpointKey)
+	}
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RemoteKMSEndpointKey, err)
+	}
+
+	keyID := properties[RemoteKMSKeyIDKey]
+	if keyID == "" {
+		return nil, fmt.Errorf("missing %s", RemoteKMSKeyIDKey)
+	}
+
+	timeout := defaultRemoteKMSTimeout
+	if value := properties[RemoteKMSTimeoutKey]; value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", RemoteKMSTimeoutKey, err)
+		}
+		timeout = parsed
+	}
+
+	tlsConfig, err := remoteKMSTLSConfig(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remoteKMSProvider{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		endpoint: endpoint,
+		keyID:    keyID,
+		token:    properties[RemoteKMSTokenKey],
+	}, nil
+}
+
+// remoteKMSTLSConfig returns the TLS configuration used to reach the key service, with mutual TLS when a client certificate is configured.
+func remoteKMSTLSConfig(properties map[string]string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCertPath := properties[RemoteKMSTLSCACertKey]; caCertPath != "" {
+		// nolint:gosec
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", RemoteKMSTLSCACertKey, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", RemoteKMSTLSCACertKey)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCertPath, clientKeyPath := properties[RemoteKMSTLSClientCertKey], properties[RemoteKMSTLSClientKeyKey]
+	if clientCertPath != "" || clientKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (p *remoteKMSProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var res remoteKMSEncryptResponse
+	if err := p.do(ctx, "encrypt", remoteKMSEncryptRequest{Plaintext: blob}, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Ciphertext) == 0 {
+		return nil, errors.New("remote key service returned an empty ciphertext")
+	}
+	return res.Ciphertext, nil
+}
+
+func (p *remoteKMSProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var res remoteKMSDecryptResponse
+	if err := p.do(ctx, "decrypt", remoteKMSDecryptRequest{Ciphertext: blob}, &res); err != nil {
+		return nil, err
+	}
+	return res.Plaintext, nil
+}
+
+func (p *remoteKMSProvider) do(ctx context.Context, operation string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	reqURL := fmt.Sprintf("%s/keys/%s/%s", p.endpoint, url.PathEscape(p.keyID), operation)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling remote key service %s: %w", operation, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("remote key service %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding remote key service %s response: %w", operation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package kmsproviders
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteKMSProvider(t *testing.T) {
+	t.Run("required properties must be set", func(t *testing.T) {
+		_, err := newRemoteKMSProvider(map[string]string{RemoteKMSKeyIDKey: "kek"})
+		require.ErrorContains(t, err, "missing "+RemoteKMSEndpointKey)
+
+		_, err = newRemoteKMSProvider(map[string]string{RemoteKMSEndpointKey: "https://kms.example.com"})
+		require.ErrorContains(t, err, "missing "+RemoteKMSKeyIDKey)
+
+		_, err = newRemoteKMSProvider(map[string]string{
+			RemoteKMSEndpointKey: "https://kms.example.com",
+			RemoteKMSKeyIDKey:    "kek",
+			RemoteKMSTimeoutKey:  "soon",
+		})
+		require.ErrorContains(t, err, "invalid "+RemoteKMSTimeoutKey)
+	})
+
+	t.Run("encrypt and decrypt are delegated to the key service", func(t *testing.T) {
+		// The stub key service "encrypts" by reversing the payload.
+		reverse := func(b []byte) []byte {
+			out := make([]byte, len(b))
+			for i := range b {
+				out[len(b)-1-i] = b[i]
+			}
+			return out
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /keys/kek/encrypt", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+			var req remoteKMSEncryptRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			_ = json.NewEncoder(w).Encode(remoteKMSEncryptResponse{Ciphertext: reverse(req.Plaintext)})
+		})
+		mux.HandleFunc("POST /keys/kek/decrypt", func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+			var req remoteKMSDecryptRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			_ = json.NewEncoder(w).Encode(remoteKMSDecryptResponse{Plaintext: reverse(req.Ciphertext)})
+		})
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		provider, err := newRemoteKMSProvider(map[string]string{
+			RemoteKMSEndpointKey: server.URL + "/",
+			RemoteKMSKeyIDKey:    "kek",
+			RemoteKMSTokenKey:    "secret-token",
+		})
+		require.NoError(t, err)
+
+		plaintext := []byte("data key")
+		encrypted, err := provider.Encrypt(context.Background(), plaintext)
+		require.NoError(t, err)
+		require.Equal(t, reverse(plaintext), encrypted)
+
+		decrypted, err := provider.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("key service errors are returned", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "key disabled", http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+
+		provider, err := newRemoteKMSProvider(map[string]string{
+			RemoteKMSEndpointKey: server.URL,
+			RemoteKMSKeyIDKey:    "kek",
+		})
+		require.NoError(t, err)
+
+		_, err = provider.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "status 403: key disabled")
+	})
+}
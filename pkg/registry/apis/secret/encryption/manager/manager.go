@@ -58,6 +58,12 @@ func ProvideEncryptionManager(
 		return nil, fmt.Errorf("missing configuration for current encryption provider %s", currentProviderID)
 	}
 
+	for namespace, providerID := range providerConfig.NamespaceProviders {
+		if _, ok := providerConfig.AvailableProviders[providerID]; !ok {
+			return nil, fmt.Errorf("missing configuration for encryption provider %s of namespace %s", providerID, namespace)
+		}
+	}
+
 	// Use the configured cache encryption key, or generate a random one if not provided.
 	cacheEncryptionKey := cfg.SecretsManagement.DataKeysCacheEncryptionKey
 	if cacheEncryptionKey == "" {
@@ -134,11 +140,12 @@ func (s *EncryptionManager) Encrypt(ctx context.Context, namespace xkube.Namespa
 		}
 	}()
 
-	label := encryption.KeyLabel(s.providerConfig.CurrentProvider)
+	providerID := s.providerConfig.ProviderFor(namespace.String())
+	label := encryption.KeyLabel(providerID)
 
 	var id string
 	var dataKey []byte
-	id, dataKey, err = s.currentDataKey(ctx, namespace, label, opts.SkipCache)
+	id, dataKey, err = s.currentDataKey(ctx, namespace, providerID, label, opts.SkipCache)
 	if err != nil {
 		s.log.Error("Failed to get current data key", "error", err, "label", label)
 		return contracts.EncryptedPayload{}, err
@@ -162,7 +169,7 @@ func (s *EncryptionManager) Encrypt(ctx context.Context, namespace xkube.Namespa
 // currentDataKey looks up for current data key in cache or database by name, and decrypts it.
 // If there's no current data key in cache nor in database it generates a new random data key,
 // and stores it into both the in-memory cache and database (encrypted by the encryption provider).
-func (s *EncryptionManager) currentDataKey(ctx context.Context, namespace xkube.Namespace, label string, skipCache bool) (string, []byte, error) {
+func (s *EncryptionManager) currentDataKey(ctx context.Context, namespace xkube.Namespace, providerID encryption.ProviderID, label string, skipCache bool) (string, []byte, error) {
 	ctx, span := s.tracer.Start(ctx, "EnvelopeEncryptionManager.CurrentDataKey", trace.WithAttributes(
 		attribute.String("namespace", namespace.String()),
 		attribute.String("label", label),
@@ -182,7 +189,7 @@ func (s *EncryptionManager) currentDataKey(ctx context.Context, namespace xkube.
 
 	// If no existing data key was found, create a new one
 	if dataKey == nil {
-		id, dataKey, err = s.newDataKey(ctx, namespace.String(), label, skipCache)
+		id, dataKey, err = s.newDataKey(ctx, namespace.String(), providerID, label, skipCache)
 		if err != nil {
 			return "", nil, err
 		}
@@ -237,8 +244,8 @@ func (s *EncryptionManager) dataKeyByLabel(ctx context.Context, namespace, label
 	return dataKey.UID, decrypted, nil
 }
 
-// newDataKey creates a new random data key, encrypts it and stores it into the database.
-func (s *EncryptionManager) newDataKey(ctx context.Context, namespace string, label string, skipCache bool) (string, []byte, error) {
+// newDataKey creates a new random data key, encrypts it with the given provider and stores it into the database.
+func (s *EncryptionManager) newDataKey(ctx context.Context, namespace string, providerID encryption.ProviderID, label string, skipCache bool) (string, []byte, error) {
 	ctx, span := s.tracer.Start(ctx, "EnvelopeEncryptionManager.NewDataKey", trace.WithAttributes(
 		attribute.String("namespace", namespace),
 		attribute.String("label", label),
@@ -252,9 +259,9 @@ func (s *EncryptionManager) newDataKey(ctx context.Context, namespace string, la
 	}
 
 	// 2.1 Find the encryption provider.
-	provider, exists := s.providerConfig.AvailableProviders[s.providerConfig.CurrentProvider]
+	provider, exists := s.providerConfig.AvailableProviders[providerID]
 	if !exists {
-		return "", nil, fmt.Errorf("could not find encryption provider '%s'", s.providerConfig.CurrentProvider)
+		return "", nil, fmt.Errorf("could not find encryption provider '%s'", providerID)
 	}
 
 	// 2.2 Encrypt the data key.
@@ -270,7 +277,7 @@ func (s *EncryptionManager) newDataKey(ctx context.Context, namespace string, la
 		Active:        true,
 		UID:           id,
 		Namespace:     namespace,
-		Provider:      s.providerConfig.CurrentProvider,
+		Provider:      providerID,
 		EncryptedData: encrypted,
 		Label:         label,
 	}
@@ -662,6 +662,63 @@ func TestEncryptionService_ThirdPartyProviders(t *testing.T) {
 	require.Contains(t, encMgr.providerConfig.AvailableProviders, encryption.ProviderID("fakeProvider.v1"))
 }
 
+func TestEncryptionService_NamespaceProviders(t *testing.T) {
+	ctx := context.Background()
+	tracer := noop.NewTracerProvider().Tracer("test")
+	usageStats := &usagestats.UsageStatsMock{T: t}
+
+	testDB := sqlstore.NewTestStore(t, sqlstore.WithMigrator(migrator.New()))
+	encryptionStore, err := encryptionstorage.ProvideDataKeyStorage(database.ProvideDatabase(testDB, tracer), tracer, nil)
+	require.NoError(t, err)
+
+	enc, err := service.ProvideAESGCMCipherService(tracer, usageStats)
+	require.NoError(t, err)
+
+	cfg := &setting.Cfg{
+		SecretsManagement: setting.SecretsManagerSettings{
+			CurrentEncryptionProvider:    "secret_key.v1",
+			ConfiguredKMSProviders:       map[string]map[string]string{"secret_key.v1": {"secret_key": "SW2YcwTIb9zpOOhoPsMm"}},
+			NamespaceEncryptionProviders: map[string]string{"hsm-namespace": "fakeProvider.v1"},
+		},
+	}
+
+	ossProviders, err := osskmsproviders.ProvideOSSKMSProviders(cfg, enc)
+	require.NoError(t, err)
+
+	t.Run("a namespace provider must be configured", func(t *testing.T) {
+		_, err := ProvideEncryptionManager(tracer, encryptionStore, usageStats, enc, ossProviders, &NoopDataKeyCache{}, cfg)
+		require.ErrorContains(t, err, "fakeProvider.v1")
+	})
+
+	fake := &fakeProvider{}
+	ossProviders.AvailableProviders[encryption.ProviderID("fakeProvider.v1")] = fake
+
+	svc, err := ProvideEncryptionManager(tracer, encryptionStore, usageStats, enc, ossProviders, &NoopDataKeyCache{}, cfg)
+	require.NoError(t, err)
+
+	t.Run("other namespaces use the current provider", func(t *testing.T) {
+		_, err := svc.Encrypt(ctx, xkube.Namespace("default"), []byte("secret"), contracts.EncryptionOption{})
+		require.NoError(t, err)
+		require.False(t, fake.encryptCalled)
+
+		keys, err := encryptionStore.ListDataKeys(ctx, "default")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, encryption.ProviderID("secret_key.v1"), keys[0].Provider)
+	})
+
+	t.Run("the namespace data keys are encrypted by its own provider", func(t *testing.T) {
+		_, err := svc.Encrypt(ctx, xkube.Namespace("hsm-namespace"), []byte("secret"), contracts.EncryptionOption{})
+		require.NoError(t, err)
+		require.True(t, fake.encryptCalled)
+
+		keys, err := encryptionStore.ListDataKeys(ctx, "hsm-namespace")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, encryption.ProviderID("fakeProvider.v1"), keys[0].Provider)
+	})
+}
+
 // stubCache tracks whether cache read methods were called.
 type stubCache struct {
 	getByLabelCalled bool
@@ -12,6 +12,16 @@ const UsageInsightsPrefix = "secrets_manager"
 type ProviderConfig struct {
 	CurrentProvider    ProviderID
 	AvailableProviders ProviderMap
+	// NamespaceProviders overrides the current provider for specific namespaces, so that their data keys are encrypted by a dedicated provider (e.g. an HSM).
+	NamespaceProviders map[string]ProviderID
+}
+
+// ProviderFor returns the identifier of the provider used to encrypt new data keys for the namespace.
+func (c ProviderConfig) ProviderFor(namespace string) ProviderID {
+	if id, ok := c.NamespaceProviders[namespace]; ok {
+		return id
+	}
+	return c.CurrentProvider
 }
 
 type ProviderMap map[ProviderID]Provider
@@ -24,7 +34,7 @@ type Provider interface {
 
 type ProviderID string
 
-// Kind returns the kind of the provider, e.g. "secret_key", "aws_kms", "azure_keyvault", "google_kms", "hashicorp_vault"
+// Kind returns the kind of the provider, e.g. "secret_key", "pkcs11", "remote_kms", "aws_kms", "azure_keyvault", "google_kms", "hashicorp_vault"
 func (id ProviderID) Kind() (string, error) {
 	idStr := string(id)
 
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-app-sdk/logging"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultDataKeyRotationBatchSize = 100
+
+	// dataKeyRotationLockTimeout is how long the lock of a rotation is kept if the instance running it dies,
+	// before another instance can resume it. The lock is refreshed after every batch, so it only has to outlast one.
+	dataKeyRotationLockTimeout = 10 * time.Minute
+)
+
+// ServerLock prevents rotations of the same namespace from running on several instances at the same time.
+type ServerLock interface {
+	LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+	RefreshLock(ctx context.Context, actionName string, maxInterval time.Duration) error
+}
+
+type DataKeyRotationService struct {
+	tracer              trace.Tracer
+	serverLock          ServerLock
+	dataKeyStore        contracts.DataKeyStorage
+	encryptedValueStore contracts.EncryptedValueStorage
+	rotationStore       contracts.DataKeyRotationStorage
+	encryptionManager   contracts.EncryptionManager
+	batchSize           int64
+
+	// Namespaces with a rotation running in this process.
+	running sync.Map
+}
+
+func ProvideDataKeyRotationService(
+	tracer trace.Tracer,
+	serverLock ServerLock,
+	dataKeyStore contracts.DataKeyStorage,
+	encryptedValueStore contracts.EncryptedValueStorage,
+	rotationStore contracts.DataKeyRotationStorage,
+	encryptionManager contracts.EncryptionManager,
+	cfg *setting.Cfg,
+) contracts.DataKeyRotationService {
+	batchSize := cfg.SecretsManagement.DataKeyRotationBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultDataKeyRotationBatchSize
+	}
+
+	return &DataKeyRotationService{
+		tracer:              tracer,
+		serverLock:          serverLock,
+		dataKeyStore:        dataKeyStore,
+		encryptedValueStore: encryptedValueStore,
+		rotationStore:       rotationStore,
+		encryptionManager:   encryptionManager,
+		batchSize:           batchSize,
+	}
+}
+
+func (s *DataKeyRotationService) Status(ctx context.Context, namespace xkube.Namespace) (*contracts.DataKeyRotation, error) {
+	ctx, span := s.tracer.Start(ctx, "DataKeyRotationService.Status", trace.WithAttributes(
+		attribute.String("namespace", namespace.String()),
+	))
+	defer span.End()
+
+	return s.rotationStore.Read(ctx, namespace)
+}
+
+func (s *DataKeyRotationService) Rotate(ctx context.Context, namespace xkube.Namespace) (rotation *contracts.DataKeyRotation, err error) {
+	ctx, span := s.tracer.Start(ctx, "DataKeyRotationService.Rotate", trace.WithAttributes(
+		attribute.String("namespace", namespace.String()),
+	))
+	defer span.End()
+
+	defer func() {
+		if err != nil {
+			span.SetStatus(otelcodes.Error, err.Error())
+			span.RecordError(err)
+		}
+	}()
+
+	if _, loaded := s.running.LoadOrStore(namespace.String(), struct{}{}); loaded {
+		return nil, contracts.ErrDataKeyRotationInProgress
+	}
+	defer s.running.Delete(namespace.String())
+
+	// The lock is also taken in this process, so the rotation of a namespace only runs once across all the instances.
+	lockErr := s.serverLock.LockExecuteAndRelease(ctx, dataKeyRotationLockName(namespace), dataKeyRotationLockTimeout, func(ctx context.Context) {
+		rotation, err = s.rotate(ctx, namespace)
+	})
+	if lockErr != nil {
+		var lockExistsErr *serverlock.ServerLockExistsError
+		if errors.As(lockErr, &lockExistsErr) {
+			return nil, contracts.ErrDataKeyRotationInProgress
+		}
+		return nil, fmt.Errorf("locking data key rotation: %w", lockErr)
+	}
+
+	return rotation, err
+}
+
+func dataKeyRotationLockName(namespace xkube.Namespace) string {
+	return "secrets-data-key-rotation-" + namespace.String()
+}
+
+func (s *DataKeyRotationService) rotate(ctx context.Context, namespace xkube.Namespace) (*contracts.DataKeyRotation, error) {
+	rotation, err := s.start(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every data key that is no longer active must be replaced. This includes the keys disabled when the rotation started,
+	// as well as keys disabled by an earlier rotation or consolidation that did not finish.
+	dataKeys, err := s.dataKeyStore.ListDataKeys(ctx, namespace.String())
+	if err != nil {
+		return rotation, fmt.Errorf("listing data keys: %w", err)
+	}
+	retiredDataKeys := make(map[string]bool, len(dataKeys))
+	for _, dataKey := range dataKeys {
+		if !dataKey.Active {
+			retiredDataKeys[dataKey.UID] = true
+		}
+	}
+
+	logger := logging.FromContext(ctx).With("namespace", namespace.String())
+
+	for {
+		if err := ctx.Err(); err != nil {
+			// The progress up to the last batch was persisted, so calling Rotate again resumes from there.
+			return rotation, err
+		}
+
+		encryptedValues, err := s.encryptedValueStore.List(ctx, namespace, contracts.NamespacedListOpts{
+			Limit:        s.batchSize,
+			AfterName:    rotation.CursorName,
+			AfterVersion: rotation.CursorVersion,
+		})
+		if err != nil {
+			return rotation, fmt.Errorf("listing encrypted values: %w", err)
+		}
+
+		for _, ev := range encryptedValues {
+			rotation.Processed++
+			rotation.CursorName = ev.Name
+			rotation.CursorVersion = ev.Version
+
+			if !retiredDataKeys[ev.DataKeyID] {
+				continue
+			}
+
+			if err := s.reEncrypt(ctx, namespace, ev); err != nil {
+				logger.Error("Failed to re-encrypt value", "name", ev.Name, "version", ev.Version, "error", err)
+				rotation.Failed++
+				continue
+			}
+			rotation.Rotated++
+		}
+
+		if int64(len(encryptedValues)) < s.batchSize {
+			break
+		}
+
+		rotation.Updated = time.Now().Unix()
+		if err := s.rotationStore.Update(ctx, rotation); err != nil {
+			return rotation, fmt.Errorf("saving data key rotation progress: %w", err)
+		}
+
+		// Without the lock, another instance may resume the rotation from the saved progress at the same time.
+		if err := s.serverLock.RefreshLock(ctx, dataKeyRotationLockName(namespace), dataKeyRotationLockTimeout); err != nil {
+			return rotation, fmt.Errorf("refreshing data key rotation lock: %w", err)
+		}
+
+		logger.Info("Data key rotation progress", "processed", rotation.Processed, "total", rotation.Total, "rotated", rotation.Rotated, "failed", rotation.Failed)
+	}
+
+	rotation.Phase = contracts.DataKeyRotationPhaseCompleted
+	if rotation.Failed > 0 {
+		rotation.Phase = contracts.DataKeyRotationPhaseFailed
+		rotation.Message = fmt.Sprintf("%d encrypted values could not be re-encrypted and still use a retired data key", rotation.Failed)
+	}
+	rotation.Updated = time.Now().Unix()
+	rotation.Finished = rotation.Updated
+
+	if err := s.rotationStore.Update(ctx, rotation); err != nil {
+		return rotation, fmt.Errorf("saving data key rotation result: %w", err)
+	}
+
+	// The retired data keys are kept: values that failed to re-encrypt, or that were written with a retired key while
+	// the rotation was starting, still need them to be decrypted. The next rotation picks those values up.
+
+	logger.Info("Data key rotation finished", "phase", rotation.Phase, "processed", rotation.Processed, "rotated", rotation.Rotated, "failed", rotation.Failed)
+
+	return rotation, nil
+}
+
+// start returns the rotation to work on: the interrupted one if it exists, or a new one otherwise.
+// Starting a new rotation disables the data keys of the namespace, so that any value written from then on is encrypted with a new data key.
+func (s *DataKeyRotationService) start(ctx context.Context, namespace xkube.Namespace) (*contracts.DataKeyRotation, error) {
+	previous, err := s.rotationStore.Read(ctx, namespace)
+	if err != nil && !errors.Is(err, contracts.ErrDataKeyRotationNotFound) {
+		return nil, fmt.Errorf("reading data key rotation: %w", err)
+	}
+
+	if previous != nil && !previous.IsFinished() {
+		logging.FromContext(ctx).Info("Resuming data key rotation", "namespace", namespace.String(), "processed", previous.Processed, "total", previous.Total)
+
+		// Other processes may have cached the data keys that were disabled when the rotation started.
+		s.encryptionManager.FlushCache(namespace)
+
+		return previous, nil
+	}
+
+	if err := s.dataKeyStore.DisableDataKeys(ctx, namespace.String()); err != nil {
+		return nil, fmt.Errorf("disabling data keys: %w", err)
+	}
+	s.encryptionManager.FlushCache(namespace)
+
+	total, err := s.encryptedValueStore.Count(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("counting encrypted values: %w", err)
+	}
+
+	now := time.Now().Unix()
+	rotation := &contracts.DataKeyRotation{
+		Namespace: namespace.String(),
+		Phase:     contracts.DataKeyRotationPhaseRunning,
+		Total:     total,
+		Started:   now,
+		Updated:   now,
+	}
+
+	if previous == nil {
+		err = s.rotationStore.Create(ctx, rotation)
+	} else {
+		err = s.rotationStore.Update(ctx, rotation)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("saving data key rotation: %w", err)
+	}
+
+	return rotation, nil
+}
+
+// reEncrypt decrypts the value with its retired data key and encrypts it again with the current one.
+// The cache is skipped to avoid overloading it during the rotation.
+func (s *DataKeyRotationService) reEncrypt(ctx context.Context, namespace xkube.Namespace, ev *contracts.EncryptedValue) error {
+	decrypted, err := s.encryptionManager.Decrypt(ctx, namespace, ev.EncryptedPayload, contracts.EncryptionOption{SkipCache: true})
+	if err != nil {
+		return fmt.Errorf("decrypting value: %w", err)
+	}
+
+	reEncrypted, err := s.encryptionManager.Encrypt(ctx, namespace, decrypted, contracts.EncryptionOption{SkipCache: true})
+	if err != nil {
+		return fmt.Errorf("re-encrypting value: %w", err)
+	}
+
+	// Encrypted values are never updated in place by writers, which create a new version instead,
+	// so there is no risk of overwriting a concurrent write here.
+	if err := s.encryptedValueStore.Update(ctx, namespace, ev.Name, ev.Version, reEncrypted); err != nil {
+		return fmt.Errorf("updating encrypted value: %w", err)
+	}
+
+	return nil
+}
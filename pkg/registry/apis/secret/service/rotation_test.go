@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/authlib/authn"
+	"github.com/grafana/authlib/types"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	secretv1beta1 "github.com/grafana/grafana/apps/secret/pkg/apis/secret/v1beta1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/testutils"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
+)
+
+func TestDataKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	createAuthContext := func(ctx context.Context, namespace string) context.Context {
+		return types.WithAuthInfo(ctx, &identity.StaticRequester{
+			Type:      types.TypeAccessPolicy,
+			Namespace: namespace,
+			AccessTokenClaims: &authn.Claims[authn.AccessTokenClaims]{
+				Rest: authn.AccessTokenClaims{
+					Permissions:     []string{"secret.grafana.app/securevalues:decrypt"},
+					ServiceIdentity: "decrypter1",
+				},
+			},
+		})
+	}
+
+	createSecureValue := func(t *testing.T, sut testutils.Sut, namespace, name, value string) {
+		t.Helper()
+
+		_, err := sut.CreateSv(t.Context(), testutils.CreateSvWithSv(&secretv1beta1.SecureValue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: secretv1beta1.SecureValueSpec{
+				Description: "test description",
+				Value:       ptr.To(secretv1beta1.NewExposedSecureValue(value)),
+				Decrypters:  []string{"decrypter1"},
+			},
+		}))
+		require.NoError(t, err)
+	}
+
+	decrypt := func(t *testing.T, sut testutils.Sut, namespace, name string) string {
+		t.Helper()
+
+		decrypted, err := sut.DecryptStorage.Decrypt(createAuthContext(t.Context(), namespace), xkube.Namespace(namespace), name)
+		require.NoError(t, err)
+
+		return decrypted.DangerouslyExposeAndConsumeValue()
+	}
+
+	t.Run("rotation re-encrypts every value of the namespace with a new data key", func(t *testing.T) {
+		t.Parallel()
+		sut := testutils.Setup(t)
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+		createSecureValue(t, sut, "namespace1", "secret-2", "value-2")
+		createSecureValue(t, sut, "namespace2", "secret-3", "value-3")
+
+		before1, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace1", "secret-1", 1)
+		require.NoError(t, err)
+		before3, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace2", "secret-3", 1)
+		require.NoError(t, err)
+
+		rotation, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 2, rotation.Total)
+		require.EqualValues(t, 2, rotation.Processed)
+		require.EqualValues(t, 2, rotation.Rotated)
+		require.Zero(t, rotation.Failed)
+		require.NotZero(t, rotation.Finished)
+
+		after1, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace1", "secret-1", 1)
+		require.NoError(t, err)
+		require.NotEqual(t, before1.DataKeyID, after1.DataKeyID)
+		require.NotEqual(t, before1.EncryptedData, after1.EncryptedData)
+
+		// Other namespaces are not affected.
+		after3, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace2", "secret-3", 1)
+		require.NoError(t, err)
+		require.Equal(t, before3.DataKeyID, after3.DataKeyID)
+		require.Equal(t, before3.EncryptedData, after3.EncryptedData)
+
+		require.Equal(t, "value-1", decrypt(t, sut, "namespace1", "secret-1"))
+		require.Equal(t, "value-2", decrypt(t, sut, "namespace1", "secret-2"))
+		require.Equal(t, "value-3", decrypt(t, sut, "namespace2", "secret-3"))
+
+		status, err := sut.DataKeyRotationService.Status(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, rotation, status)
+	})
+
+	t.Run("values written after the rotation started keep decrypting", func(t *testing.T) {
+		t.Parallel()
+		sut := testutils.Setup(t)
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+
+		_, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+
+		createSecureValue(t, sut, "namespace1", "secret-2", "value-2")
+
+		// A second rotation starts over and also picks up values written in between.
+		rotation, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 2, rotation.Total)
+		require.EqualValues(t, 2, rotation.Rotated)
+
+		require.Equal(t, "value-1", decrypt(t, sut, "namespace1", "secret-1"))
+		require.Equal(t, "value-2", decrypt(t, sut, "namespace1", "secret-2"))
+	})
+
+	t.Run("an interrupted rotation resumes after the last processed value", func(t *testing.T) {
+		t.Parallel()
+		sut := testutils.Setup(t)
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+		createSecureValue(t, sut, "namespace1", "secret-2", "value-2")
+
+		// Simulate a rotation that was interrupted after processing the first value.
+		require.NoError(t, sut.DataKeyStore.DisableDataKeys(t.Context(), "namespace1"))
+		require.NoError(t, sut.DataKeyRotationStorage.Create(t.Context(), &contracts.DataKeyRotation{
+			Namespace:     "namespace1",
+			Phase:         contracts.DataKeyRotationPhaseRunning,
+			CursorName:    "secret-1",
+			CursorVersion: 1,
+			Total:         2,
+			Processed:     1,
+			Rotated:       1,
+			Started:       1,
+			Updated:       1,
+		}))
+
+		before1, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace1", "secret-1", 1)
+		require.NoError(t, err)
+
+		rotation, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 1, rotation.Started)
+		require.EqualValues(t, 2, rotation.Processed)
+		require.EqualValues(t, 2, rotation.Rotated)
+
+		// The value before the cursor was not visited again.
+		after1, err := sut.EncryptedValueStorage.Get(t.Context(), "namespace1", "secret-1", 1)
+		require.NoError(t, err)
+		require.Equal(t, before1.EncryptedData, after1.EncryptedData)
+
+		require.Equal(t, "value-1", decrypt(t, sut, "namespace1", "secret-1"))
+		require.Equal(t, "value-2", decrypt(t, sut, "namespace1", "secret-2"))
+	})
+
+	t.Run("a rotation running on another instance is not started again", func(t *testing.T) {
+		t.Parallel()
+		serverLock := &testutils.FakeServerLock{Held: true}
+		sut := testutils.Setup(t, testutils.WithServerLock(serverLock))
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+
+		_, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.ErrorIs(t, err, contracts.ErrDataKeyRotationInProgress)
+
+		// Nothing was started by this instance.
+		_, err = sut.DataKeyRotationService.Status(t.Context(), "namespace1")
+		require.ErrorIs(t, err, contracts.ErrDataKeyRotationNotFound)
+
+		serverLock.Held = false
+
+		rotation, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 1, rotation.Rotated)
+	})
+
+	t.Run("the lock is refreshed after every batch", func(t *testing.T) {
+		t.Parallel()
+		serverLock := &testutils.FakeServerLock{}
+		sut := testutils.Setup(t, testutils.WithServerLock(serverLock), testutils.WithMutateCfg(func(cfg *testutils.SetupConfig) {
+			cfg.DataKeyRotationBatchSize = 1
+		}))
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+		createSecureValue(t, sut, "namespace1", "secret-2", "value-2")
+
+		rotation, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 2, rotation.Rotated)
+		require.Equal(t, 2, serverLock.Refreshes)
+	})
+
+	t.Run("a rotation stops once its lock is lost and resumes later", func(t *testing.T) {
+		t.Parallel()
+		serverLock := &testutils.FakeServerLock{Lost: true}
+		sut := testutils.Setup(t, testutils.WithServerLock(serverLock), testutils.WithMutateCfg(func(cfg *testutils.SetupConfig) {
+			cfg.DataKeyRotationBatchSize = 1
+		}))
+
+		createSecureValue(t, sut, "namespace1", "secret-1", "value-1")
+		createSecureValue(t, sut, "namespace1", "secret-2", "value-2")
+
+		_, err := sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.ErrorIs(t, err, serverlock.ErrLockLost)
+
+		// The progress of the first batch was saved before the lock was refreshed.
+		rotation, err := sut.DataKeyRotationService.Status(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseRunning, rotation.Phase)
+		require.EqualValues(t, 1, rotation.Processed)
+
+		serverLock.Lost = false
+
+		rotation, err = sut.DataKeyRotationService.Rotate(t.Context(), "namespace1")
+		require.NoError(t, err)
+		require.Equal(t, contracts.DataKeyRotationPhaseCompleted, rotation.Phase)
+		require.EqualValues(t, 2, rotation.Processed)
+		require.EqualValues(t, 2, rotation.Rotated)
+
+		require.Equal(t, "value-1", decrypt(t, sut, "namespace1", "secret-1"))
+		require.Equal(t, "value-2", decrypt(t, sut, "namespace1", "secret-2"))
+	})
+
+	t.Run("status of a namespace that was never rotated returns not found", func(t *testing.T) {
+		t.Parallel()
+		sut := testutils.Setup(t)
+
+		_, err := sut.DataKeyRotationService.Status(t.Context(), "namespace1")
+		require.ErrorIs(t, err, contracts.ErrDataKeyRotationNotFound)
+	})
+}
@@ -17,6 +17,7 @@ import (
 	secretv1beta1 "github.com/grafana/grafana/apps/secret/pkg/apis/secret/v1beta1"
 	decryptcontracts "github.com/grafana/grafana/apps/secret/pkg/decrypt"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
 	"github.com/grafana/grafana/pkg/registry/apis/secret/decrypt"
@@ -45,10 +46,14 @@ type SetupConfig struct {
 	DataKeyMigrationExecutor contracts.EncryptedValueMigrationExecutor
 	RunSecretsDBMigrations   bool
 	RunDataKeyMigration      bool
+	ServerLock               *FakeServerLock
+	DataKeyRotationBatchSize int64
 }
 
 func defaultSetupCfg() SetupConfig {
-	return SetupConfig{}
+	return SetupConfig{
+		ServerLock: &FakeServerLock{},
+	}
 }
 
 func WithKeeperService(keeperService contracts.KeeperService) func(*SetupConfig) {
@@ -57,6 +62,12 @@ func WithKeeperService(keeperService contracts.KeeperService) func(*SetupConfig)
 	}
 }
 
+func WithServerLock(serverLock *FakeServerLock) func(*SetupConfig) {
+	return func(setupCfg *SetupConfig) {
+		setupCfg.ServerLock = serverLock
+	}
+}
+
 func WithMutateCfg(f func(*SetupConfig)) func(*SetupConfig) {
 	return func(cfg *SetupConfig) {
 		f(cfg)
@@ -100,6 +111,7 @@ func Setup(t *testing.T, opts ...func(*SetupConfig)) Sut {
 		RunDataKeyMigration:           setupCfg.RunDataKeyMigration,
 		GCWorkerMaxBatchSize:          2,
 		GCWorkerMaxConcurrentCleanups: 2,
+		DataKeyRotationBatchSize:      setupCfg.DataKeyRotationBatchSize,
 	}
 	store, err := encryptionstorage.ProvideDataKeyStorage(database, tracer, nil)
 	require.NoError(t, err)
@@ -170,6 +182,11 @@ func Setup(t *testing.T, opts ...func(*SetupConfig)) Sut {
 
 	consolidationService := service.ProvideConsolidationService(tracer, globalDataKeyStore, encryptedValueStorage, globalEncryptedValueStorage, encryptionManager)
 
+	dataKeyRotationStorage, err := encryptionstorage.ProvideDataKeyRotationStorage(database, tracer)
+	require.NoError(t, err)
+
+	dataKeyRotationService := service.ProvideDataKeyRotationService(tracer, setupCfg.ServerLock, store, encryptedValueStorage, dataKeyRotationStorage, encryptionManager, cfg)
+
 	garbageCollectionWorker := garbagecollectionworker.ProvideWorker(
 		cfg,
 		secureValueMetadataStorage,
@@ -188,6 +205,9 @@ func Setup(t *testing.T, opts ...func(*SetupConfig)) Sut {
 		Database:                        database,
 		AccessClient:                    accessClient,
 		ConsolidationService:            consolidationService,
+		DataKeyRotationService:          dataKeyRotationService,
+		DataKeyRotationStorage:          dataKeyRotationStorage,
+		DataKeyStore:                    store,
 		EncryptionManager:               encryptionManager,
 		GlobalDataKeyStore:              globalDataKeyStore,
 		GarbageCollectionWorker:         garbageCollectionWorker,
@@ -210,6 +230,9 @@ type Sut struct {
 	Database                        *database.Database
 	AccessClient                    types.AccessClient
 	ConsolidationService            contracts.ConsolidationService
+	DataKeyRotationService          contracts.DataKeyRotationService
+	DataKeyRotationStorage          contracts.DataKeyRotationStorage
+	DataKeyStore                    contracts.DataKeyStorage
 	EncryptionManager               contracts.EncryptionManager
 	GlobalDataKeyStore              contracts.GlobalDataKeyStorage
 	GarbageCollectionWorker         *garbagecollectionworker.Worker
@@ -429,6 +452,30 @@ func (c *FakeClock) AdvanceBy(duration time.Duration) {
 	c.Current = c.Current.Add(duration)
 }
 
+// FakeServerLock runs the function right away, unless the lock is held by another instance.
+// Refreshes fail once the lock is lost.
+type FakeServerLock struct {
+	Held      bool
+	Lost      bool
+	Refreshes int
+}
+
+func (l *FakeServerLock) LockExecuteAndRelease(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	if l.Held {
+		return &serverlock.ServerLockExistsError{}
+	}
+	fn(ctx)
+	return nil
+}
+
+func (l *FakeServerLock) RefreshLock(_ context.Context, actionName string, _ time.Duration) error {
+	if l.Lost {
+		return fmt.Errorf("%w: %s", serverlock.ErrLockLost, actionName)
+	}
+	l.Refreshes++
+	return nil
+}
+
 type NoopMigrationExecutor struct {
 }
 
@@ -3,7 +3,6 @@ package apiregistry
 import (
 	"github.com/google/wire"
 
-	"github.com/grafana/grafana/pkg/apiserver/auditing"
 	"github.com/grafana/grafana/pkg/registry/apis/collections"
 	dashboardinternal "github.com/grafana/grafana/pkg/registry/apis/dashboard"
 	"github.com/grafana/grafana/pkg/registry/apis/datasource"
@@ -21,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/registry/apis/secret"
 	"github.com/grafana/grafana/pkg/registry/apis/service"
 	"github.com/grafana/grafana/pkg/registry/apis/userstorage"
+	"github.com/grafana/grafana/pkg/services/apiserver/auditlog"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 )
 
@@ -41,8 +41,8 @@ var WireSetExts = wire.NewSet(
 	wire.Bind(new(externalgroupmapping.SearchHandler), new(*externalgroupmapping.NoopSearchREST)),
 
 	// Auditing Options
-	auditing.ProvideNoopBackend,
-	auditing.ProvideNoopPolicyRuleProvider,
+	auditlog.ProvideBackend,
+	auditlog.ProvidePolicyRuleProvider,
 )
 
 var provisioningExtras = wire.NewSet(
@@ -0,0 +1,74 @@
+package dashvalidator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator/sql"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/query"
+)
+
+var _ sql.QueryRunner = (*queryRunner)(nil)
+
+// queryRunner runs the schema queries of the SQL validators through the query
+// service, as the user requesting the validation.
+type queryRunner struct {
+	queryService query.Service
+}
+
+func (r *queryRunner) RunQuery(ctx context.Context, dsType, datasourceUID, rawSQL string) ([][]string, error) {
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := simplejson.NewFromAny(map[string]any{
+		"refId":      "A",
+		"datasource": map[string]any{"uid": datasourceUID, "type": dsType},
+		"rawSql":     rawSQL,
+		"format":     "table",
+	})
+
+	// schema queries do not depend on the time range, but the request requires one
+	now := time.Now()
+	res, err := r.queryService.QueryData(ctx, user, true, dtos.MetricRequest{
+		From:    strconv.FormatInt(now.Add(-time.Minute).UnixMilli(), 10),
+		To:      strconv.FormatInt(now.UnixMilli(), 10),
+		Queries: []*simplejson.Json{q},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, ok := res.Responses["A"]
+	if !ok {
+		return nil, fmt.Errorf("no response for schema query")
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var rows [][]string
+	for _, frame := range resp.Frames {
+		rowCount, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < rowCount; i++ {
+			row := make([]string, len(frame.Fields))
+			for j, field := range frame.Fields {
+				if v, ok := field.ConcreteAt(i); ok {
+					row[j] = fmt.Sprint(v)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
@@ -13,12 +13,16 @@ import (
 	validatorapp "github.com/grafana/grafana/apps/dashvalidator/pkg/app"
 	"github.com/grafana/grafana/apps/dashvalidator/pkg/cache"
 	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator/elasticsearch"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator/loki"
 	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator/prometheus"
+	"github.com/grafana/grafana/apps/dashvalidator/pkg/validator/sql"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/query"
 )
 
 var _ appsdkapiserver.AppInstaller = (*DashValidatorAppInstaller)(nil)
@@ -34,6 +38,7 @@ func RegisterAppInstaller(
 	datasourceSvc datasources.DataSourceService,
 	httpClientProvider httpclient.Provider,
 	ac accesscontrol.AccessControl,
+	queryService query.Service,
 ) (*DashValidatorAppInstaller, error) {
 	// Create MetricsCache - shared cache for all datasource types
 	metricsCache := cache.NewMetricsCache()
@@ -42,9 +47,22 @@ func RegisterAppInstaller(
 	prometheusProvider := prometheus.NewPrometheusProvider(cache.DefaultMetricsCacheTTL)
 	metricsCache.RegisterProvider(datasources.DS_PROMETHEUS, prometheusProvider)
 
+	// Create and register Loki and Elasticsearch providers
+	metricsCache.RegisterProvider(datasources.DS_LOKI, loki.NewLokiProvider(cache.DefaultMetricsCacheTTL))
+	metricsCache.RegisterProvider(datasources.DS_ES, elasticsearch.NewElasticsearchProvider(cache.DefaultMetricsCacheTTL))
+
 	// Create validators map - keyed by datasource type
 	validators := map[string]validator.DatasourceValidator{
 		datasources.DS_PROMETHEUS: prometheus.NewValidator(metricsCache),
+		datasources.DS_LOKI:       loki.NewValidator(metricsCache),
+		datasources.DS_ES:         elasticsearch.NewValidator(metricsCache),
+	}
+
+	// SQL datasources have no HTTP API, their schema is read through the query service
+	runner := &queryRunner{queryService: queryService}
+	for _, dsType := range sql.SupportedTypes() {
+		metricsCache.RegisterProvider(dsType, sql.NewSQLProvider(runner, dsType, cache.DefaultMetricsCacheTTL))
+		validators[dsType] = sql.NewValidator(metricsCache, dsType)
 	}
 
 	// Create specific config for the app with all components
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -225,7 +226,14 @@ func (s *ModuleServer) Run() error {
 		if backendService, ok := s.storageBackend.(services.Service); ok {
 			return backendService, nil
 		}
-		return services.NewIdleService(nil, nil).WithName(modules.UnifiedBackend), nil
+		// Backends over an embedded database are closed on shutdown, after the servers using them are stopped
+		var stop services.StoppingFn
+		if closer, ok := s.storageBackend.(io.Closer); ok {
+			stop = func(_ error) error {
+				return closer.Close()
+			}
+		}
+		return services.NewIdleService(nil, stop).WithName(modules.UnifiedBackend), nil
 	})
 
 	m.RegisterModule(modules.MemberlistKV, s.initMemberlistKV)
@@ -22,12 +22,14 @@ type Runner struct {
 	SecretsMigrator             secrets.Migrator
 	UserService                 user.Service
 	SecretsConsolidationService contracts.ConsolidationService
+	DataKeyRotationService      contracts.DataKeyRotationService
 }
 
 func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provider,
 	encryptionService encryption.Internal, features featuremgmt.FeatureToggles,
 	secretsService *manager.SecretsService, secretsMigrator secrets.Migrator,
 	userService user.Service, secretsConsolidationService contracts.ConsolidationService,
+	dataKeyRotationService contracts.DataKeyRotationService,
 ) Runner {
 	return Runner{
 		Cfg:                         cfg,
@@ -39,5 +41,6 @@ func NewRunner(cfg *setting.Cfg, sqlStore db.DB, settingsProvider setting.Provid
 		Features:                    features,
 		UserService:                 userService,
 		SecretsConsolidationService: secretsConsolidationService,
+		DataKeyRotationService:      dataKeyRotationService,
 	}
 }
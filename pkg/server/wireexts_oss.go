@@ -121,6 +121,7 @@ var wireExtsBasicSet = wire.NewSet(
 	secretkeeper.ProvideService,
 	wire.Bind(new(contracts.KeeperService), new(*secretkeeper.OSSKeeperService)),
 	secretService.ProvideConsolidationService,
+	secretService.ProvideDataKeyRotationService,
 	ldap.ProvideGroupsService,
 	wire.Bind(new(ldap.Groups), new(*ldap.OSSGroups)),
 	guardian.ProvideGuardian,
@@ -62,6 +62,9 @@ type Service interface {
 	SyncUserRoles(ctx context.Context, orgID int64, cmd SyncUserRolesCommand) error
 	// GetStaicRoles returns a map where key organization role and value is a static rbac role.
 	GetStaticRoles(ctx context.Context) map[string]*RoleDTO
+	// ExplainPermission traces the evaluation of a permission check for a user or service account,
+	// optionally with simulated role changes.
+	ExplainPermission(ctx context.Context, user identity.Requester, query ExplainQuery) (*Explanation, error)
 }
 
 //go:generate  mockery --name Store --structname MockStore --outpkg actest --filename store_mock.go --output ./actest/
@@ -71,6 +74,7 @@ type Store interface {
 	GetTeamsPermissions(ctx context.Context, query GetUserPermissionsQuery) (map[int64][]Permission, error)
 	SearchUsersPermissions(ctx context.Context, orgID int64, options SearchOptions) (map[int64][]Permission, error)
 	GetUsersBasicRoles(ctx context.Context, userFilter []int64, orgID int64) (map[int64][]string, error)
+	GetPermissionSources(ctx context.Context, query GetPermissionSourcesQuery) ([]SourcedPermission, error)
 	DeleteUserPermissions(ctx context.Context, orgID, userID int64) error
 	DeleteTeamPermissions(ctx context.Context, orgID, teamID int64) error
 	SaveExternalServiceRole(ctx context.Context, cmd SaveExternalServiceRoleCommand) error
@@ -17,6 +17,7 @@ import (
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/acimpl")
 
 var _ accesscontrol.AccessControl = new(AccessControl)
+var _ accesscontrol.ScopeResolver = new(AccessControl)
 
 func ProvideAccessControl(features featuremgmt.FeatureToggles) *AccessControl {
 	logger := log.New("accesscontrol")
@@ -90,6 +91,11 @@ func (a *AccessControl) RegisterScopeAttributeResolver(prefix string, resolver a
 	a.resolvers.AddScopeAttributeResolver(prefix, resolver)
 }
 
+// ResolveScope resolves a scope with the scope attribute resolvers the same way they are used during evaluation.
+func (a *AccessControl) ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error) {
+	return a.resolvers.GetScopeAttributeMutator(orgID)(ctx, scope)
+}
+
 func (a *AccessControl) WithoutResolvers() accesscontrol.AccessControl {
 	return &AccessControl{
 		features:  a.features,
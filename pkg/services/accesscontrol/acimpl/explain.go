@@ -0,0 +1,234 @@
+package acimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginaccesscontrol"
+)
+
+// ExplainPermission traces the evaluation of a permission check. It reports every permission of the user on
+// the action along with the role, team or basic role it comes from, how the scope was resolved and which
+// permission allows the check. When role changes are given, the check is evaluated as if they were applied.
+func (s *Service) ExplainPermission(ctx context.Context, user identity.Requester, query accesscontrol.ExplainQuery) (*accesscontrol.Explanation, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ExplainPermission")
+	defer span.End()
+
+	if query.Action == "" {
+		return nil, accesscontrol.ErrInvalidRequest.Errorf("action is required")
+	}
+
+	// The permissions of an action can also come from the action sets including it, e.g. folders:edit
+	actions := append([]string{query.Action}, s.actionResolver.ResolveAction(query.Action)...)
+
+	// we don't care about the error here, if this fails we get 0 and no
+	// permission assigned to user will be returned, only for basic roles.
+	userID, _ := identity.UserIdentifier(user.GetID())
+	orgID := user.GetOrgID()
+	roles := accesscontrol.GetOrgRoles(user)
+	teams := user.GetTeams()
+
+	permissions, err := s.getPermissionSources(ctx, orgID, userID, roles, teams, actions)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution := s.resolveScope(ctx, orgID, query.Scope)
+	explanation := explain(query.Action, query.Scope, resolution, roles, teams, permissions)
+	if query.Changes == nil {
+		return explanation, nil
+	}
+
+	roles, teams, err = applyAssignmentChanges(roles, teams, query.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	simulated, err := s.getPermissionSources(ctx, orgID, userID, roles, teams, actions)
+	if err != nil {
+		return nil, err
+	}
+	simulated, err = s.applyRoleChanges(ctx, orgID, simulated, actions, query.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	currentlyAllowed := explanation.Allowed
+	explanation = explain(query.Action, query.Scope, resolution, roles, teams, simulated)
+	explanation.Simulated = true
+	explanation.CurrentlyAllowed = &currentlyAllowed
+	return explanation, nil
+}
+
+func explain(action, scope string, resolution *accesscontrol.ScopeResolution, roles []string, teams []int64, permissions []accesscontrol.SourcedPermission) *accesscontrol.Explanation {
+	var resolved []string
+	if resolution != nil {
+		resolved = resolution.Resolved
+	}
+
+	explanation := &accesscontrol.Explanation{
+		Action:      action,
+		Scope:       scope,
+		BasicRoles:  roles,
+		Teams:       teams,
+		Resolution:  resolution,
+		Permissions: permissions,
+		SatisfiedBy: accesscontrol.TracePermissions(permissions, scope, resolved),
+	}
+	explanation.Allowed = explanation.SatisfiedBy != nil
+	if !explanation.Allowed {
+		explanation.Suggestions = accesscontrol.SuggestPermissions(action, scope, resolved)
+	}
+	return explanation
+}
+
+// getPermissionSources returns the permissions on the given actions granted to the basic roles, the teams and
+// the user along with their source. It reads the roles and the database directly to bypass the permission cache.
+func (s *Service) getPermissionSources(ctx context.Context, orgID, userID int64, roles []string, teams []int64, actions []string) ([]accesscontrol.SourcedPermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.getPermissionSources")
+	defer span.End()
+
+	permissions := make([]accesscontrol.SourcedPermission, 0)
+	for _, role := range roles {
+		s.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+			if _, ok := accesscontrol.BuiltInRolesWithParents(registration.Grants)[role]; !ok {
+				return true
+			}
+			for _, p := range registration.Role.Permissions {
+				if registration.Role.IsPlugin() && p.Action == pluginaccesscontrol.ActionAppAccess {
+					continue
+				}
+				if slices.Contains(actions, p.Action) {
+					permissions = append(permissions, sourcedPermission(p, accesscontrol.PermissionSource{
+						Kind:      accesscontrol.PermissionSourceBasicRole,
+						RoleName:  registration.Role.Name,
+						BasicRole: role,
+					}))
+				}
+			}
+			return true
+		})
+	}
+
+	if slices.Contains(actions, SharedWithMeFolderPermission.Action) {
+		permissions = append(permissions, sourcedPermission(SharedWithMeFolderPermission, accesscontrol.PermissionSource{
+			Kind: accesscontrol.PermissionSourceDefault,
+		}))
+	}
+
+	stored, err := s.store.GetPermissionSources(ctx, accesscontrol.GetPermissionSourcesQuery{
+		OrgID:        orgID,
+		UserID:       userID,
+		Roles:        roles,
+		TeamIDs:      teams,
+		RolePrefixes: OSSRolesPrefixes,
+		Actions:      actions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(permissions, withActionSets(stored, actions[0])...), nil
+}
+
+// resolveScope resolves the scope of the check the way the evaluator does when the scope itself is not matched.
+func (s *Service) resolveScope(ctx context.Context, orgID int64, scope string) *accesscontrol.ScopeResolution {
+	if scope == "" {
+		return nil
+	}
+
+	resolution := &accesscontrol.ScopeResolution{Scope: scope}
+	if s.scopeResolver == nil {
+		return resolution
+	}
+
+	resolved, err := s.scopeResolver.ResolveScope(ctx, orgID, scope)
+	switch {
+	case errors.Is(err, accesscontrol.ErrResolverNotFound):
+	case err != nil:
+		resolution.Resolver = true
+		resolution.Error = err.Error()
+	default:
+		resolution.Resolver = true
+		resolution.Resolved = resolved
+	}
+	return resolution
+}
+
+// applyAssignmentChanges returns the basic roles and the teams of the user after the role changes.
+func applyAssignmentChanges(roles []string, teams []int64, changes *accesscontrol.RoleChanges) ([]string, []int64, error) {
+	if changes.BasicRole != "" {
+		if !org.RoleType(changes.BasicRole).IsValid() {
+			return nil, nil, accesscontrol.ErrInvalidRoleChanges.Build(accesscontrol.ErrInvalidRoleChangesData(fmt.Sprintf("invalid basic role %s", changes.BasicRole)))
+		}
+		// The basic role of the organization comes first, followed by Grafana Admin for server admins
+		roles = append([]string{changes.BasicRole}, roles[1:]...)
+	}
+
+	simulatedTeams := make([]int64, 0, len(teams)+len(changes.AddTeams))
+	for _, team := range append(slices.Clone(teams), changes.AddTeams...) {
+		if !slices.Contains(changes.RemoveTeams, team) && !slices.Contains(simulatedTeams, team) {
+			simulatedTeams = append(simulatedTeams, team)
+		}
+	}
+	return roles, simulatedTeams, nil
+}
+
+// applyRoleChanges removes the permissions of the removed roles and adds the permissions of the added roles.
+func (s *Service) applyRoleChanges(ctx context.Context, orgID int64, permissions []accesscontrol.SourcedPermission, actions []string, changes *accesscontrol.RoleChanges) ([]accesscontrol.SourcedPermission, error) {
+	permissions = slices.DeleteFunc(permissions, func(p accesscontrol.SourcedPermission) bool {
+		return slices.Contains(changes.RemoveRoles, p.Source.RoleName)
+	})
+
+	added := make([]accesscontrol.SourcedPermission, 0)
+	for _, name := range changes.AddRoles {
+		role, err := s.GetRoleByName(ctx, orgID, name)
+		if err != nil {
+			if errors.Is(err, accesscontrol.ErrRoleNotFound) {
+				return nil, accesscontrol.ErrInvalidRoleChanges.Build(accesscontrol.ErrInvalidRoleChangesData(fmt.Sprintf("role %s not found", name)))
+			}
+			return nil, err
+		}
+		for _, p := range role.Permissions {
+			if slices.Contains(actions, p.Action) {
+				added = append(added, sourcedPermission(p, accesscontrol.PermissionSource{
+					Kind:     accesscontrol.PermissionSourceSimulated,
+					RoleName: role.Name,
+				}))
+			}
+		}
+	}
+
+	for _, p := range changes.AddPermissions {
+		if p.Scope != "" && !accesscontrol.ValidateScope(p.Scope) {
+			return nil, accesscontrol.ErrInvalidRoleChanges.Build(accesscontrol.ErrInvalidRoleChangesData(fmt.Sprintf("invalid scope %s", p.Scope)))
+		}
+		if slices.Contains(actions, p.Action) {
+			added = append(added, sourcedPermission(p, accesscontrol.PermissionSource{
+				Kind: accesscontrol.PermissionSourceSimulated,
+			}))
+		}
+	}
+
+	return append(permissions, withActionSets(added, actions[0])...), nil
+}
+
+func sourcedPermission(p accesscontrol.Permission, source accesscontrol.PermissionSource) accesscontrol.SourcedPermission {
+	return accesscontrol.SourcedPermission{Action: p.Action, Scope: p.Scope, Source: source}
+}
+
+// withActionSets reports the permissions granted through an action set as permissions on the action.
+func withActionSets(permissions []accesscontrol.SourcedPermission, action string) []accesscontrol.SourcedPermission {
+	for i := range permissions {
+		if permissions[i].Action != action {
+			permissions[i].ActionSet = permissions[i].Action
+			permissions[i].Action = action
+		}
+	}
+	return permissions
+}
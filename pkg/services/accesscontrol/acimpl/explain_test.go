@@ -0,0 +1,149 @@
+package acimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeScopeResolver map[string][]string
+
+func (f fakeScopeResolver) ResolveScope(_ context.Context, _ int64, scope string) ([]string, error) {
+	if resolved, ok := f[scope]; ok {
+		return resolved, nil
+	}
+	return nil, accesscontrol.ErrResolverNotFound
+}
+
+func setupExplainTestEnv(t *testing.T) *Service {
+	t.Helper()
+
+	s := &Service{
+		log:   log.New("accesscontrol"),
+		roles: accesscontrol.BuildBasicRoleDefinitions(),
+		store: actest.FakeStore{ExpectedPermissionSources: []accesscontrol.SourcedPermission{{
+			Action: "dashboards:edit",
+			Scope:  "folders:uid:other",
+			Source: accesscontrol.PermissionSource{Kind: accesscontrol.PermissionSourceTeam, RoleName: "managed:teams:1:permissions", TeamID: 1},
+		}}},
+		actionResolver: &resourcepermissions.FakeActionSetSvc{ExpectedActionSets: []string{"dashboards:edit"}},
+		scopeResolver:  fakeScopeResolver{"dashboards:uid:dash": {"dashboards:uid:dash", "folders:uid:parent"}},
+	}
+	s.registrations.Append(accesscontrol.RoleRegistration{
+		Role: accesscontrol.RoleDTO{
+			Name:        "fixed:dashboards:writer",
+			Permissions: []accesscontrol.Permission{{Action: "dashboards:write", Scope: "folders:uid:parent"}},
+		},
+		Grants: []string{string(org.RoleEditor)},
+	})
+	return s
+}
+
+func TestService_ExplainPermission(t *testing.T) {
+	viewer := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer, Teams: []int64{1}}
+	query := accesscontrol.ExplainQuery{Action: "dashboards:write", Scope: "dashboards:uid:dash"}
+
+	t.Run("should explain a denied check", func(t *testing.T) {
+		s := setupExplainTestEnv(t)
+
+		explanation, err := s.ExplainPermission(context.Background(), viewer, query)
+		require.NoError(t, err)
+
+		assert.False(t, explanation.Allowed)
+		assert.Nil(t, explanation.SatisfiedBy)
+		assert.Equal(t, &accesscontrol.ScopeResolution{
+			Scope:    "dashboards:uid:dash",
+			Resolved: []string{"dashboards:uid:dash", "folders:uid:parent"},
+			Resolver: true,
+		}, explanation.Resolution)
+		require.Len(t, explanation.Permissions, 1)
+		assert.Equal(t, "dashboards:write", explanation.Permissions[0].Action)
+		assert.Equal(t, "dashboards:edit", explanation.Permissions[0].ActionSet)
+		assert.Empty(t, explanation.Permissions[0].MatchedScope)
+		assert.Equal(t, []accesscontrol.Permission{
+			{Action: "dashboards:write", Scope: "dashboards:uid:dash"},
+			{Action: "dashboards:write", Scope: "folders:uid:parent"},
+		}, explanation.Suggestions)
+	})
+
+	t.Run("should explain a check allowed through a parent folder", func(t *testing.T) {
+		s := setupExplainTestEnv(t)
+		admin := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleAdmin}
+
+		explanation, err := s.ExplainPermission(context.Background(), admin, query)
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Allowed)
+		require.NotNil(t, explanation.SatisfiedBy)
+		assert.Equal(t, "folders:uid:parent", explanation.SatisfiedBy.MatchedScope)
+		assert.Equal(t, accesscontrol.PermissionSource{
+			Kind:      accesscontrol.PermissionSourceBasicRole,
+			RoleName:  "fixed:dashboards:writer",
+			BasicRole: string(org.RoleAdmin),
+		}, explanation.SatisfiedBy.Source)
+		assert.Empty(t, explanation.Suggestions)
+	})
+
+	t.Run("should simulate role changes", func(t *testing.T) {
+		s := setupExplainTestEnv(t)
+
+		simulated := query
+		simulated.Changes = &accesscontrol.RoleChanges{BasicRole: string(org.RoleEditor), RemoveTeams: []int64{1}}
+		explanation, err := s.ExplainPermission(context.Background(), viewer, simulated)
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Allowed)
+		assert.True(t, explanation.Simulated)
+		require.NotNil(t, explanation.CurrentlyAllowed)
+		assert.False(t, *explanation.CurrentlyAllowed)
+		assert.Equal(t, []string{string(org.RoleEditor)}, explanation.BasicRoles)
+		assert.Empty(t, explanation.Teams)
+		assert.Equal(t, "fixed:dashboards:writer", explanation.SatisfiedBy.Source.RoleName)
+	})
+
+	t.Run("should simulate added and removed roles", func(t *testing.T) {
+		s := setupExplainTestEnv(t)
+
+		simulated := query
+		simulated.Changes = &accesscontrol.RoleChanges{
+			AddRoles:       []string{"fixed:dashboards:writer"},
+			RemoveRoles:    []string{"managed:teams:1:permissions"},
+			AddPermissions: []accesscontrol.Permission{{Action: "dashboards:write", Scope: "dashboards:uid:dash"}},
+		}
+		explanation, err := s.ExplainPermission(context.Background(), viewer, simulated)
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Allowed)
+		require.Len(t, explanation.Permissions, 2)
+		for _, p := range explanation.Permissions {
+			assert.Equal(t, accesscontrol.PermissionSourceSimulated, p.Source.Kind)
+		}
+		// The permission on the dashboard itself is evaluated before the permissions on the resolved scopes
+		assert.Equal(t, "dashboards:uid:dash", explanation.SatisfiedBy.MatchedScope)
+		assert.Empty(t, explanation.SatisfiedBy.Source.RoleName)
+	})
+
+	t.Run("should reject invalid role changes", func(t *testing.T) {
+		s := setupExplainTestEnv(t)
+
+		for _, changes := range []*accesscontrol.RoleChanges{
+			{BasicRole: "Owner"},
+			{AddRoles: []string{"fixed:unknown"}},
+			{AddPermissions: []accesscontrol.Permission{{Action: "dashboards:write", Scope: "dashboards:*:dash"}}},
+		} {
+			simulated := query
+			simulated.Changes = changes
+			_, err := s.ExplainPermission(context.Background(), viewer, simulated)
+			require.ErrorIs(t, err, accesscontrol.ErrInvalidRoleChanges)
+		}
+	})
+}
@@ -70,6 +70,10 @@ func ProvideService(
 		lock,
 	)
 
+	if resolver, ok := accessControl.(accesscontrol.ScopeResolver); ok {
+		service.scopeResolver = resolver
+	}
+
 	api.NewAccessControlAPI(routeRegister, accessControl, service, userService).RegisterAPIEndpoints()
 	if err := accesscontrol.DeclareFixedRoles(service, cfg); err != nil {
 		return nil, err
@@ -124,6 +128,7 @@ type Service struct {
 	isInitialized  bool
 	sql            db.DB
 	serverLock     *serverlock.ServerLockService
+	scopeResolver  accesscontrol.ScopeResolver
 }
 
 func (s *Service) GetUsageStats(_ context.Context) map[string]any {
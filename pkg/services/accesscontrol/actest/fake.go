@@ -89,6 +89,7 @@ type FakeStore struct {
 	ExpectedTeamsPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersRoles            map[int64][]string
+	ExpectedPermissionSources     []accesscontrol.SourcedPermission
 	ExpectedErr                   error
 }
 
@@ -112,6 +113,10 @@ func (f FakeStore) GetUsersBasicRoles(ctx context.Context, userFilter []int64, o
 	return f.ExpectedUsersRoles, f.ExpectedErr
 }
 
+func (f FakeStore) GetPermissionSources(ctx context.Context, query accesscontrol.GetPermissionSourcesQuery) ([]accesscontrol.SourcedPermission, error) {
+	return f.ExpectedPermissionSources, f.ExpectedErr
+}
+
 func (f FakeStore) DeleteUserPermissions(ctx context.Context, orgID, userID int64) error {
 	return f.ExpectedErr
 }
@@ -99,6 +99,36 @@ func (_m *MockStore) GetBasicRolesPermissions(ctx context.Context, query accessc
 	return r0, r1
 }
 
+// GetPermissionSources provides a mock function with given fields: ctx, query
+func (_m *MockStore) GetPermissionSources(ctx context.Context, query accesscontrol.GetPermissionSourcesQuery) ([]accesscontrol.SourcedPermission, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPermissionSources")
+	}
+
+	var r0 []accesscontrol.SourcedPermission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, accesscontrol.GetPermissionSourcesQuery) ([]accesscontrol.SourcedPermission, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, accesscontrol.GetPermissionSourcesQuery) []accesscontrol.SourcedPermission); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]accesscontrol.SourcedPermission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, accesscontrol.GetPermissionSourcesQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTeamsPermissions provides a mock function with given fields: ctx, query
 func (_m *MockStore) GetTeamsPermissions(ctx context.Context, query accesscontrol.GetUserPermissionsQuery) (map[int64][]accesscontrol.Permission, error) {
 	ret := _m.Called(ctx, query)
@@ -18,6 +18,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/api")
@@ -45,6 +46,8 @@ func (api *AccessControlAPI) RegisterAPIEndpoints() {
 		rr.Get("/user/actions", middleware.ReqSignedIn, routing.Wrap(api.getUserActions))
 		rr.Get("/user/permissions", middleware.ReqSignedIn, routing.Wrap(api.getUserPermissions))
 		rr.Get("/users/permissions/search", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.searchUsersPermissions))
+		rr.Get("/user/explain", middleware.ReqSignedIn, routing.Wrap(api.explainUserPermission))
+		rr.Post("/users/explain", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.explainUsersPermission))
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
 }
 
@@ -124,6 +127,76 @@ func (api *AccessControlAPI) searchUsersPermissions(c *contextmodel.ReqContext)
 	return response.JSON(http.StatusOK, permsByAction)
 }
 
+// GET /api/access-control/user/explain
+func (api *AccessControlAPI) explainUserPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.explainUserPermission")
+	defer span.End()
+
+	explanation, err := api.Service.ExplainPermission(ctx, c.SignedInUser, ac.ExplainQuery{
+		Action: c.Query("action"),
+		Scope:  c.Query("scope"),
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not explain permission", err)
+	}
+
+	return response.JSON(http.StatusOK, explanation)
+}
+
+type explainPermissionCommand struct {
+	// NamespacedID is the typed identifier of the user or service account, e.g. user:3 or service-account:4
+	NamespacedID string          `json:"namespacedId"`
+	Action       string          `json:"action"`
+	Scope        string          `json:"scope"`
+	Changes      *ac.RoleChanges `json:"changes,omitempty"`
+}
+
+// POST /api/access-control/users/explain
+func (api *AccessControlAPI) explainUsersPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.explainUsersPermission")
+	defer span.End()
+
+	var cmd explainPermissionCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if cmd.NamespacedID == "" {
+		return response.JSON(http.StatusBadRequest, "'namespacedId' is required")
+	}
+
+	userID, err := api.ComputeUserID(ctx, cmd.NamespacedID)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.JSON(http.StatusBadRequest, err.Error())
+		}
+		return response.JSON(http.StatusInternalServerError, err.Error())
+	}
+
+	// Explain the permissions of the user in the organization of the caller only
+	usr, err := api.userSvc.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: userID, OrgID: c.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, "user not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "could not get user", err)
+	}
+	if usr.GetOrgID() != c.GetOrgID() {
+		return response.Error(http.StatusNotFound, "user not found", nil)
+	}
+
+	explanation, err := api.Service.ExplainPermission(ctx, usr, ac.ExplainQuery{
+		Action:  cmd.Action,
+		Scope:   cmd.Scope,
+		Changes: cmd.Changes,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "could not explain permission", err)
+	}
+
+	return response.JSON(http.StatusOK, explanation)
+}
+
 func (api *AccessControlAPI) ComputeUserID(ctx context.Context, typedID string) (int64, error) {
 	if typedID == "" {
 		return -1, nil
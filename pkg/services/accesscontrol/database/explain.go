@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+type sourcedPermission struct {
+	Action      string
+	Scope       string
+	RoleName    string `xorm:"role_name"`
+	TeamID      int64  `xorm:"team_id"`
+	BuiltInRole string `xorm:"built_in_role"`
+}
+
+// GetPermissionSources returns the permissions of a user, of its teams and of its basic roles stored in the
+// database along with the role and the assignment they come from.
+func (s *AccessControlStore) GetPermissionSources(ctx context.Context, query accesscontrol.GetPermissionSourcesQuery) ([]accesscontrol.SourcedPermission, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetPermissionSources")
+	defer span.End()
+
+	result := make([]sourcedPermission, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var (
+			parts  []string
+			params []any
+		)
+
+		filter, filterParams := permissionSourcesFilter(query)
+
+		if query.UserID > 0 {
+			parts = append(parts, `
+			SELECT permission.action, permission.scope, role.name AS role_name, 0 AS team_id, '' AS built_in_role
+			FROM permission
+			INNER JOIN role ON role.id = permission.role_id
+			INNER JOIN user_role AS ur ON ur.role_id = role.id
+			WHERE ur.user_id = ? AND (ur.org_id = ? OR ur.org_id = ?)`+filter)
+			params = append(params, query.UserID, query.OrgID, accesscontrol.GlobalOrgID)
+			params = append(params, filterParams...)
+		}
+
+		if len(query.TeamIDs) > 0 {
+			parts = append(parts, `
+			SELECT permission.action, permission.scope, role.name AS role_name, tr.team_id AS team_id, '' AS built_in_role
+			FROM permission
+			INNER JOIN role ON role.id = permission.role_id
+			INNER JOIN team_role AS tr ON tr.role_id = role.id
+			WHERE tr.team_id IN (?`+strings.Repeat(", ?", len(query.TeamIDs)-1)+`) AND tr.org_id = ?`+filter)
+			for _, id := range query.TeamIDs {
+				params = append(params, id)
+			}
+			params = append(params, query.OrgID)
+			params = append(params, filterParams...)
+		}
+
+		if len(query.Roles) > 0 {
+			parts = append(parts, `
+			SELECT permission.action, permission.scope, role.name AS role_name, 0 AS team_id, br.role AS built_in_role
+			FROM permission
+			INNER JOIN role ON role.id = permission.role_id
+			INNER JOIN builtin_role AS br ON br.role_id = role.id
+			WHERE br.role IN (?`+strings.Repeat(", ?", len(query.Roles)-1)+`) AND (br.org_id = ? OR br.org_id = ?)`+filter)
+			for _, role := range query.Roles {
+				params = append(params, role)
+			}
+			params = append(params, query.OrgID, accesscontrol.GlobalOrgID)
+			params = append(params, filterParams...)
+		}
+
+		if len(parts) == 0 {
+			// no permission to fetch
+			return nil
+		}
+
+		return sess.SQL(strings.Join(parts, " UNION ALL "), params...).Find(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]accesscontrol.SourcedPermission, 0, len(result))
+	for _, p := range result {
+		kind := accesscontrol.PermissionSourceUser
+		if p.TeamID != 0 {
+			kind = accesscontrol.PermissionSourceTeam
+		} else if p.BuiltInRole != "" {
+			kind = accesscontrol.PermissionSourceBasicRole
+		}
+		permissions = append(permissions, accesscontrol.SourcedPermission{
+			Action: p.Action,
+			Scope:  p.Scope,
+			Source: accesscontrol.PermissionSource{
+				Kind:      kind,
+				RoleName:  p.RoleName,
+				BasicRole: p.BuiltInRole,
+				TeamID:    p.TeamID,
+			},
+		})
+	}
+	return permissions, nil
+}
+
+func permissionSourcesFilter(query accesscontrol.GetPermissionSourcesQuery) (string, []any) {
+	var (
+		builder strings.Builder
+		params  []any
+	)
+
+	if len(query.RolePrefixes) > 0 {
+		builder.WriteString(" AND (" + strings.Repeat("role.name LIKE ? OR ", len(query.RolePrefixes)-1) + "role.name LIKE ?)")
+		for _, prefix := range query.RolePrefixes {
+			params = append(params, prefix+"%")
+		}
+	}
+
+	if len(query.Actions) > 0 {
+		builder.WriteString(" AND permission.action IN (?" + strings.Repeat(", ?", len(query.Actions)-1) + ")")
+		for _, action := range query.Actions {
+			params = append(params, action)
+		}
+	}
+
+	return builder.String(), params
+}
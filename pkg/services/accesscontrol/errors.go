@@ -10,6 +10,7 @@ import (
 const (
 	invalidBuiltInRoleMessage       = `built-in role [{{ .Public.builtInRole }}] is not valid`
 	assignmentEntityNotFoundMessage = `{{ .Public.assignment }} not found`
+	invalidRoleChangesMessage       = `invalid role changes: {{ .Public.reason }}`
 )
 
 var (
@@ -18,6 +19,8 @@ var (
 	ErrNoneRoleAssignment       = errutil.BadRequest("accesscontrol.noneRoleAssignment", errutil.WithPublicMessage("none role cannot receive permissions"))
 	ErrAssignmentEntityNotFound = errutil.BadRequest("accesscontrol.assignmentEntityNotFound").
 					MustTemplate(assignmentEntityNotFoundMessage, errutil.WithPublic(assignmentEntityNotFoundMessage))
+	ErrInvalidRoleChanges = errutil.BadRequest("accesscontrol.invalidRoleChanges").
+				MustTemplate(invalidRoleChangesMessage, errutil.WithPublic(invalidRoleChangesMessage))
 
 	// Note: these are intended to be replaced by equivalent errutil implementations.
 	// Avoid creating new errors with errors.New and prefer errutil
@@ -48,6 +51,14 @@ func ErrAssignmentEntityNotFoundData(assignment string) errutil.TemplateData {
 	}
 }
 
+func ErrInvalidRoleChangesData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{
+		Public: map[string]any{
+			"reason": reason,
+		},
+	}
+}
+
 type ErrorInvalidRole struct{}
 
 func (e *ErrorInvalidRole) Error() string {
@@ -0,0 +1,156 @@
+package accesscontrol
+
+import (
+	"context"
+	"slices"
+)
+
+// ScopeResolver resolves a scope with the scope attribute resolver registered for its prefix.
+// It returns ErrResolverNotFound if no resolver is registered for the prefix of the scope.
+type ScopeResolver interface {
+	ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error)
+}
+
+type PermissionSourceKind string
+
+const (
+	// PermissionSourceBasicRole is the source of the permissions of a role granted to a basic role
+	PermissionSourceBasicRole PermissionSourceKind = "basicRole"
+	// PermissionSourceUser is the source of the permissions of a role assigned to the user
+	PermissionSourceUser PermissionSourceKind = "user"
+	// PermissionSourceTeam is the source of the permissions of a role assigned to a team of the user
+	PermissionSourceTeam PermissionSourceKind = "team"
+	// PermissionSourceDefault is the source of the permissions every user has
+	PermissionSourceDefault PermissionSourceKind = "default"
+	// PermissionSourceSimulated is the source of the permissions added by simulated role changes
+	PermissionSourceSimulated PermissionSourceKind = "simulated"
+)
+
+// PermissionSource describes how a user or service account got a permission.
+type PermissionSource struct {
+	Kind     PermissionSourceKind `json:"kind"`
+	RoleName string               `json:"roleName,omitempty"`
+	// BasicRole is the basic role the role is granted to, for basic role and default sources
+	BasicRole string `json:"basicRole,omitempty"`
+	TeamID    int64  `json:"teamId,omitempty"`
+}
+
+// SourcedPermission is a permission along with the role it comes from.
+type SourcedPermission struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// ActionSet is the action set the permission was granted through, if any
+	ActionSet string           `json:"actionSet,omitempty"`
+	Source    PermissionSource `json:"source"`
+	// MatchedScope is the scope of the check matched by the permission, if any
+	MatchedScope string `json:"matchedScope,omitempty"`
+}
+
+type GetPermissionSourcesQuery struct {
+	OrgID        int64
+	UserID       int64
+	Roles        []string
+	TeamIDs      []int64
+	RolePrefixes []string
+	// Actions restricts the permissions to the given actions
+	Actions []string
+}
+
+// ExplainQuery describes a permission check to explain.
+type ExplainQuery struct {
+	Action string
+	Scope  string
+	// Changes are simulated before evaluating the check, nothing is persisted
+	Changes *RoleChanges
+}
+
+// RoleChanges are hypothetical changes to the roles of a user or service account.
+type RoleChanges struct {
+	// BasicRole replaces the basic role of the user in the organization
+	BasicRole   string   `json:"basicRole,omitempty"`
+	AddTeams    []int64  `json:"addTeams,omitempty"`
+	RemoveTeams []int64  `json:"removeTeams,omitempty"`
+	AddRoles    []string `json:"addRoles,omitempty"`
+	// RemoveRoles removes the permissions of the given roles, whatever their source
+	RemoveRoles    []string     `json:"removeRoles,omitempty"`
+	AddPermissions []Permission `json:"addPermissions,omitempty"`
+}
+
+// ScopeResolution describes how the scope of a check was expanded by the scope attribute resolvers.
+type ScopeResolution struct {
+	Scope string `json:"scope"`
+	// Resolved are the scopes the scope resolved to, e.g. the dashboard and its parent folders
+	Resolved []string `json:"resolved,omitempty"`
+	// Resolver is set if a resolver is registered for the prefix of the scope
+	Resolver bool   `json:"resolver"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Explanation is the trace of the evaluation of a permission check.
+type Explanation struct {
+	Action     string           `json:"action"`
+	Scope      string           `json:"scope,omitempty"`
+	Allowed    bool             `json:"allowed"`
+	BasicRoles []string         `json:"basicRoles"`
+	Teams      []int64          `json:"teams"`
+	Resolution *ScopeResolution `json:"resolution,omitempty"`
+	// Permissions are the permissions of the user on the action, whether they match the scope or not
+	Permissions []SourcedPermission `json:"permissions"`
+	// SatisfiedBy is the first permission allowing the check
+	SatisfiedBy *SourcedPermission `json:"satisfiedBy,omitempty"`
+	// Suggestions are permissions that would allow a denied check
+	Suggestions []Permission `json:"suggestions,omitempty"`
+	// Simulated is set when the check was evaluated with role changes, CurrentlyAllowed is the result without them
+	Simulated        bool  `json:"simulated"`
+	CurrentlyAllowed *bool `json:"currentlyAllowed,omitempty"`
+}
+
+// TracePermissions evaluates permissions on an action the same way as the access control evaluator:
+// the permissions are checked against the scope first, and then against the scopes it resolved to.
+// Every permission is marked with the scope it matches and the permission allowing the check is returned.
+func TracePermissions(permissions []SourcedPermission, scope string, resolved []string) *SourcedPermission {
+	if scope == "" {
+		for i := range permissions {
+			permissions[i].MatchedScope = ""
+		}
+		if len(permissions) == 0 {
+			return nil
+		}
+		return &permissions[0]
+	}
+
+	targets := append([]string{scope}, resolved...)
+	for i := range permissions {
+		permissions[i].MatchedScope = ""
+		for _, target := range targets {
+			if match(permissions[i].Scope, target) {
+				permissions[i].MatchedScope = target
+				break
+			}
+		}
+	}
+
+	var satisfiedBy *SourcedPermission
+	for i := range permissions {
+		if permissions[i].MatchedScope == scope {
+			return &permissions[i]
+		}
+		if satisfiedBy == nil && permissions[i].MatchedScope != "" {
+			satisfiedBy = &permissions[i]
+		}
+	}
+	return satisfiedBy
+}
+
+// SuggestPermissions returns the narrowest permissions that would allow a denied check:
+// the action on the scope itself or on any of the scopes it resolved to.
+func SuggestPermissions(action, scope string, resolved []string) []Permission {
+	suggestions := []Permission{{Action: action, Scope: scope}}
+	for _, s := range resolved {
+		if s == scope || slices.ContainsFunc(suggestions, func(p Permission) bool { return p.Scope == s }) {
+			continue
+		}
+		suggestions = append(suggestions, Permission{Action: action, Scope: s})
+	}
+	return suggestions
+}
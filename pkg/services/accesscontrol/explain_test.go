@@ -0,0 +1,85 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracePermissions(t *testing.T) {
+	tests := []struct {
+		name          string
+		permissions   []SourcedPermission
+		scope         string
+		resolved      []string
+		expectedIndex int
+		expectedMatch []string
+	}{
+		{
+			name:          "no permissions",
+			scope:         "dashboards:uid:1",
+			expectedIndex: -1,
+		},
+		{
+			name:          "any permission on the action allows a check without scope",
+			permissions:   []SourcedPermission{{Action: "dashboards:create", Scope: "folders:uid:1"}},
+			expectedIndex: 0,
+			expectedMatch: []string{""},
+		},
+		{
+			name: "permission on another resource does not match",
+			permissions: []SourcedPermission{
+				{Action: "dashboards:read", Scope: "dashboards:uid:2"},
+				{Action: "dashboards:read", Scope: ""},
+			},
+			scope:         "dashboards:uid:1",
+			resolved:      []string{"dashboards:uid:1", "folders:uid:a"},
+			expectedIndex: -1,
+			expectedMatch: []string{"", ""},
+		},
+		{
+			name: "permission on a parent folder matches a resolved scope",
+			permissions: []SourcedPermission{
+				{Action: "dashboards:read", Scope: "folders:uid:b"},
+				{Action: "dashboards:read", Scope: "folders:uid:a"},
+			},
+			scope:         "dashboards:uid:1",
+			resolved:      []string{"dashboards:uid:1", "folders:uid:a"},
+			expectedIndex: 1,
+			expectedMatch: []string{"", "folders:uid:a"},
+		},
+		{
+			name: "permission on the scope is preferred over resolved scopes",
+			permissions: []SourcedPermission{
+				{Action: "dashboards:read", Scope: "folders:uid:a"},
+				{Action: "dashboards:read", Scope: "dashboards:*"},
+			},
+			scope:         "dashboards:uid:1",
+			resolved:      []string{"dashboards:uid:1", "folders:uid:a"},
+			expectedIndex: 1,
+			expectedMatch: []string{"folders:uid:a", "dashboards:uid:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			satisfiedBy := TracePermissions(tt.permissions, tt.scope, tt.resolved)
+			if tt.expectedIndex < 0 {
+				assert.Nil(t, satisfiedBy)
+			} else {
+				assert.Same(t, &tt.permissions[tt.expectedIndex], satisfiedBy)
+			}
+			for i, p := range tt.permissions {
+				assert.Equal(t, tt.expectedMatch[i], p.MatchedScope)
+			}
+		})
+	}
+}
+
+func TestSuggestPermissions(t *testing.T) {
+	assert.Equal(t, []Permission{
+		{Action: "dashboards:write", Scope: "dashboards:uid:1"},
+		{Action: "dashboards:write", Scope: "folders:uid:a"},
+		{Action: "dashboards:write", Scope: "folders:uid:b"},
+	}, SuggestPermissions("dashboards:write", "dashboards:uid:1", []string{"dashboards:uid:1", "folders:uid:a", "folders:uid:b", "folders:uid:a"}))
+}
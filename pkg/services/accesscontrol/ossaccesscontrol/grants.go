@@ -0,0 +1,32 @@
+package ossaccesscontrol
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/resourcepermissions"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// ProvidePermissionGrantReaper returns the background service expiring the temporary permission
+// grants of the resource permission services.
+func ProvidePermissionGrantReaper(
+	cfg *setting.Cfg,
+	teamPermissions *TeamPermissionsService,
+	folderPermissions *FolderPermissionsService,
+	dashboardPermissions *DashboardPermissionsService,
+	serviceAccountPermissions *ServiceAccountPermissionsService,
+	receiverPermissions *ReceiverPermissionsService,
+	datasourcePermissions accesscontrol.DatasourcePermissionsService,
+) *resourcepermissions.GrantReaper {
+	services := []resourcepermissions.GrantExpirer{
+		teamPermissions.Service,
+		folderPermissions.Service,
+		dashboardPermissions.Service,
+		serviceAccountPermissions.Service,
+		receiverPermissions.Service,
+	}
+	// Data source permissions are only managed, and granted, by the licensed implementation of the service.
+	if expirer, ok := datasourcePermissions.(resourcepermissions.GrantExpirer); ok {
+		services = append(services, expirer)
+	}
+	return resourcepermissions.NewGrantReaper(cfg, services...)
+}
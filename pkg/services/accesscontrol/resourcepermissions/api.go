@@ -114,6 +114,7 @@ func (a *api) registerEndpoints() {
 		if a.service.options.Assignments.BuiltInRoles {
 			r.Post("/:resourceID/builtInRoles/:builtInRole", resourceResolver, licenseMW, auth(accesscontrol.EvalPermission(actionWrite, scope)), routing.Wrap(a.setBuiltinRolePermission))
 		}
+		a.registerGrantEndpoints(r, resourceResolver, licenseMW, accesscontrol.EvalPermission(actionRead, scope), accesscontrol.EvalPermission(actionWrite, scope), a.accessEvaluator(scope))
 	})
 }
 
@@ -0,0 +1,175 @@
+package resourcepermissions
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (a *api) registerGrantEndpoints(r routing.RouteRegister, resourceResolver, licenseMW web.Handler, readEval, writeEval, accessEval accesscontrol.Evaluator) {
+	auth := accesscontrol.Middleware(a.ac)
+
+	r.Get("/:resourceID/grants", resourceResolver, auth(readEval), routing.Wrap(a.getGrants))
+	r.Post("/:resourceID/grants", resourceResolver, licenseMW, auth(writeEval), routing.Wrap(a.grantPermission))
+	r.Post("/:resourceID/grants/:grantID/approve", resourceResolver, licenseMW, auth(writeEval), routing.Wrap(a.approveGrant))
+	r.Post("/:resourceID/grants/:grantID/reject", resourceResolver, licenseMW, auth(writeEval), routing.Wrap(a.rejectGrant))
+	r.Delete("/:resourceID/grants/:grantID", resourceResolver, licenseMW, auth(writeEval), routing.Wrap(a.revokeGrant))
+	if a.service.options.Assignments.Users {
+		// Users with access to the resource can request a higher permission for themselves, approvers need to write permissions.
+		r.Post("/:resourceID/grants/requests", resourceResolver, licenseMW, auth(accessEval), routing.Wrap(a.requestPermission))
+	}
+}
+
+// accessEvaluator matches the users with any of the permissions of the resource on the given scope.
+func (a *api) accessEvaluator(scope string) accesscontrol.Evaluator {
+	seen := map[string]bool{}
+	evaluators := make([]accesscontrol.Evaluator, 0)
+	for _, permission := range a.service.permissions {
+		for _, action := range a.service.options.PermissionsToActions[permission] {
+			if !seen[action] {
+				seen[action] = true
+				evaluators = append(evaluators, accesscontrol.EvalPermission(action, scope))
+			}
+		}
+	}
+	return accesscontrol.EvalAny(evaluators...)
+}
+
+type grantPermissionCommand struct {
+	UserID      int64  `json:"userId"`
+	TeamID      int64  `json:"teamId"`
+	BuiltInRole string `json:"builtInRole"`
+	Permission  string `json:"permission"`
+	// Duration of the grant, e.g. 4h
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+func (cmd grantPermissionCommand) toServiceCommand(resourceID string, requestedBy int64) (GrantPermissionCommand, error) {
+	duration, err := time.ParseDuration(cmd.Duration)
+	if err != nil {
+		return GrantPermissionCommand{}, ErrInvalidGrant.Build(ErrGrantData("invalid duration " + strconv.Quote(cmd.Duration)))
+	}
+	return GrantPermissionCommand{
+		ResourceID:  resourceID,
+		UserID:      cmd.UserID,
+		TeamID:      cmd.TeamID,
+		BuiltInRole: cmd.BuiltInRole,
+		Permission:  cmd.Permission,
+		Duration:    duration,
+		Reason:      cmd.Reason,
+		RequestedBy: requestedBy,
+	}, nil
+}
+
+// getGrants returns the temporary permission grants and requests of a resource.
+// The status query parameter, e.g. status=pending, filters them by status.
+func (a *api) getGrants(c *contextmodel.ReqContext) response.Response {
+	var statuses []GrantStatus
+	for _, status := range c.QueryStrings("status") {
+		for _, s := range strings.Split(status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				statuses = append(statuses, GrantStatus(s))
+			}
+		}
+	}
+
+	grants, err := a.service.GetGrants(c.Req.Context(), c.GetOrgID(), web.Params(c.Req)[":resourceID"], statuses...)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get permission grants", err)
+	}
+	return response.JSON(http.StatusOK, grants)
+}
+
+// grantPermission assigns a permission on a resource until the grant expires.
+func (a *api) grantPermission(c *contextmodel.ReqContext) response.Response {
+	resourceID := web.Params(c.Req)[":resourceID"]
+	if resp := a.validateTeamResource(c, resourceID); resp != nil {
+		return resp
+	}
+
+	var body grantPermissionCommand
+	if err := web.Bind(c.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd, err := body.toServiceCommand(resourceID, c.UserID)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	grant, err := a.service.GrantPermission(c.Req.Context(), c.GetOrgID(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to grant permission", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// requestPermission records the request of the signed in user for a temporary permission on a resource.
+func (a *api) requestPermission(c *contextmodel.ReqContext) response.Response {
+	if !c.IsIdentityType(claims.TypeUser) {
+		return response.Error(http.StatusForbidden, "Only users can request permissions", nil)
+	}
+
+	var body grantPermissionCommand
+	if err := web.Bind(c.Req, &body); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	body.UserID, body.TeamID, body.BuiltInRole = c.UserID, 0, ""
+
+	cmd, err := body.toServiceCommand(web.Params(c.Req)[":resourceID"], c.UserID)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	grant, err := a.service.RequestPermission(c.Req.Context(), c.GetOrgID(), cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to request permission", err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
+
+// approveGrant assigns the permission of a pending request.
+func (a *api) approveGrant(c *contextmodel.ReqContext) response.Response {
+	return a.decideGrant(c, a.service.ApproveGrant, "Failed to approve permission request")
+}
+
+// rejectGrant rejects a pending request.
+func (a *api) rejectGrant(c *contextmodel.ReqContext) response.Response {
+	return a.decideGrant(c, a.service.RejectGrant, "Failed to reject permission request")
+}
+
+// revokeGrant ends an active grant or cancels a pending request.
+func (a *api) revokeGrant(c *contextmodel.ReqContext) response.Response {
+	return a.decideGrant(c, a.service.RevokeGrant, "Failed to revoke permission grant")
+}
+
+type grantDecision func(ctx context.Context, orgID int64, resourceID string, grantID, decidedBy int64) (*Grant, error)
+
+func (a *api) decideGrant(c *contextmodel.ReqContext, decide grantDecision, message string) response.Response {
+	resourceID := web.Params(c.Req)[":resourceID"]
+	if resp := a.validateTeamResource(c, resourceID); resp != nil {
+		return resp
+	}
+
+	grantID, err := strconv.ParseInt(web.Params(c.Req)[":grantID"], 10, 64)
+	if err != nil {
+		return response.Err(ErrInvalidParam.Build(ErrInvalidParamData("grantID", err)))
+	}
+
+	grant, err := decide(c.Req.Context(), c.GetOrgID(), resourceID, grantID, c.UserID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+	}
+	return response.JSON(http.StatusOK, grant)
+}
@@ -9,6 +9,8 @@ const (
 	invalidAssignmentMessage = `Assignment [{{ .Public.assignment }}] is invalid for this resource type`
 	invalidParamMessage      = `Param [{{ .Public.param }}] is invalid`
 	invalidRequestBody       = `Request body is invalid: {{ .Public.reason }}`
+	invalidGrantMessage      = `Permission grant is invalid: {{ .Public.reason }}`
+	grantConflictMessage     = `Permission grant conflict: {{ .Public.reason }}`
 )
 
 var (
@@ -20,6 +22,13 @@ var (
 				MustTemplate(invalidPermissionMessage, errutil.WithPublic(invalidPermissionMessage))
 	ErrInvalidAssignment = errutil.BadRequest("resourcePermissions.invalidAssignment").
 				MustTemplate(invalidAssignmentMessage, errutil.WithPublic(invalidAssignmentMessage))
+
+	ErrInvalidGrant = errutil.BadRequest("resourcePermissions.invalidGrant").
+			MustTemplate(invalidGrantMessage, errutil.WithPublic(invalidGrantMessage))
+	ErrGrantConflict = errutil.Conflict("resourcePermissions.grantConflict").
+				MustTemplate(grantConflictMessage, errutil.WithPublic(grantConflictMessage))
+	ErrGrantNotFound     = errutil.NotFound("resourcePermissions.grantNotFound", errutil.WithPublicMessage("Permission grant not found"))
+	ErrGrantSelfApproval = errutil.Forbidden("resourcePermissions.grantSelfApproval", errutil.WithPublicMessage("Permission requests cannot be decided by their requester"))
 )
 
 func ErrInvalidParamData(param string, err error) errutil.TemplateData {
@@ -54,3 +63,11 @@ func ErrInvalidAssignmentData(assignment string) errutil.TemplateData {
 		},
 	}
 }
+
+func ErrGrantData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{
+		Public: map[string]any{
+			"reason": reason,
+		},
+	}
+}
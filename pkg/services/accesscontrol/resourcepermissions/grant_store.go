@@ -0,0 +1,113 @@
+package resourcepermissions
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type grantStore struct {
+	sql db.DB
+}
+
+func (s *grantStore) insertGrant(ctx context.Context, grant *Grant) error {
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(grant)
+		return err
+	})
+}
+
+func (s *grantStore) getGrant(ctx context.Context, orgID int64, resource, resourceID string, grantID int64) (*Grant, error) {
+	grant := &Grant{}
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("id = ? AND org_id = ? AND resource = ? AND resource_id = ?", grantID, orgID, resource, resourceID).Get(grant)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrGrantNotFound.Errorf("grant %d not found", grantID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (s *grantStore) listGrants(ctx context.Context, orgID int64, resource, resourceID string, statuses []GrantStatus) ([]*Grant, error) {
+	grants := make([]*Grant, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ? AND resource = ? AND resource_id = ?", orgID, resource, resourceID)
+		if len(statuses) > 0 {
+			q = q.In("status", statuses)
+		}
+		return q.Desc("created").Find(&grants)
+	})
+	return grants, err
+}
+
+// getActiveGrant returns the active grant of the assignee of a grant on the same resource, if any.
+func (s *grantStore) getActiveGrant(ctx context.Context, grant *Grant) (*Grant, error) {
+	active := &Grant{}
+	var has bool
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		has, err = sess.Where(
+			"org_id = ? AND resource = ? AND resource_id = ? AND user_id = ? AND team_id = ? AND built_in_role = ? AND status = ?",
+			grant.OrgID, grant.Resource, grant.ResourceID, grant.UserID, grant.TeamID, grant.BuiltInRole, GrantStatusActive,
+		).Get(active)
+		return err
+	})
+	if err != nil || !has {
+		return nil, err
+	}
+	return active, nil
+}
+
+// listExpiredGrants returns the active grants of a resource type that expired before now.
+func (s *grantStore) listExpiredGrants(ctx context.Context, resource string, now time.Time) ([]*Grant, error) {
+	grants := make([]*Grant, 0)
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("resource = ? AND status = ? AND expires_at <= ?", resource, GrantStatusActive, now).Asc("expires_at").Find(&grants)
+	})
+	return grants, err
+}
+
+// updateGrantStatus moves a grant from one status to another. It returns false if the grant
+// was not in the expected status anymore, e.g. because another instance already expired it.
+func (s *grantStore) updateGrantStatus(ctx context.Context, grant *Grant, from GrantStatus) (bool, error) {
+	var updated bool
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND status = ?", grant.ID, from).
+			Cols("status", "previous_permission", "decided_by", "updated", "expires_at").
+			Update(grant)
+		updated = affected == 1
+		return err
+	})
+	return updated, err
+}
+
+// endResourceGrants revokes the pending and active grants of a deleted resource.
+func (s *grantStore) endResourceGrants(ctx context.Context, orgID int64, resource, resourceID string, now time.Time) error {
+	return s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec(
+			"UPDATE resource_permission_grant SET status = ?, updated = ? WHERE org_id = ? AND resource = ? AND resource_id = ? AND status IN (?, ?)",
+			GrantStatusRevoked, now, orgID, resource, resourceID, GrantStatusPending, GrantStatusActive,
+		)
+		return err
+	})
+}
+
+// getManagedActions returns the actions of a managed role on a scope.
+func (s *grantStore) getManagedActions(ctx context.Context, orgID int64, roleName, scope string) ([]string, error) {
+	var actions []string
+	err := s.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(
+			"SELECT p.action FROM permission p INNER JOIN role r ON r.id = p.role_id WHERE r.org_id = ? AND r.name = ? AND p.scope = ?",
+			orgID, roleName, scope,
+		).Find(&actions)
+	})
+	return actions, err
+}
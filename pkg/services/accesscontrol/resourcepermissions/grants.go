@@ -0,0 +1,513 @@
+package resourcepermissions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// DefaultGrantMaxDuration is the longest duration of a grant when none is configured.
+const DefaultGrantMaxDuration = 24 * time.Hour
+
+type GrantStatus string
+
+const (
+	// GrantStatusPending is the status of a requested grant waiting to be approved.
+	GrantStatusPending GrantStatus = "pending"
+	// GrantStatusActive is the status of a grant whose permission is currently assigned.
+	GrantStatusActive GrantStatus = "active"
+	// GrantStatusExpired is the status of a grant whose permission was reverted at expiry.
+	GrantStatusExpired GrantStatus = "expired"
+	// GrantStatusRevoked is the status of a grant that was ended before its expiry or cancelled before approval.
+	GrantStatusRevoked GrantStatus = "revoked"
+	// GrantStatusRejected is the status of a requested grant that was rejected.
+	GrantStatusRejected GrantStatus = "rejected"
+)
+
+// Grant is a temporary permission on a resource. It is either assigned directly, or requested by a
+// user and assigned once approved. The permission the assignee had before the grant is restored when
+// the grant expires or is revoked. Grants are kept once ended as the audit trail of temporary access.
+type Grant struct {
+	ID          int64  `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID       int64  `xorm:"org_id" json:"-"`
+	Resource    string `xorm:"resource" json:"resource"`
+	ResourceID  string `xorm:"resource_id" json:"resourceId"`
+	UserID      int64  `xorm:"user_id" json:"userId,omitempty"`
+	TeamID      int64  `xorm:"team_id" json:"teamId,omitempty"`
+	BuiltInRole string `xorm:"built_in_role" json:"builtInRole,omitempty"`
+	Permission  string `xorm:"permission" json:"permission"`
+	// PreviousPermission is the managed permission of the assignee when the grant was activated
+	PreviousPermission string `xorm:"previous_permission" json:"previousPermission"`
+	// Duration is the number of seconds the grant lasts once activated
+	Duration    int64       `xorm:"duration" json:"duration"`
+	Reason      string      `xorm:"reason" json:"reason,omitempty"`
+	Status      GrantStatus `xorm:"status" json:"status"`
+	RequestedBy int64       `xorm:"requested_by" json:"requestedBy"`
+	// DecidedBy is the user who approved, rejected or revoked the grant
+	DecidedBy int64      `xorm:"decided_by" json:"decidedBy,omitempty"`
+	Created   time.Time  `xorm:"created" json:"created"`
+	Updated   time.Time  `xorm:"updated" json:"updated"`
+	ExpiresAt *time.Time `xorm:"expires_at" json:"expiresAt,omitempty"`
+}
+
+func (g *Grant) TableName() string {
+	return "resource_permission_grant"
+}
+
+func (g *Grant) roleName() string {
+	switch {
+	case g.UserID != 0:
+		return accesscontrol.ManagedUserRoleName(g.UserID)
+	case g.TeamID != 0:
+		return accesscontrol.ManagedTeamRoleName(g.TeamID)
+	default:
+		return accesscontrol.ManagedBuiltInRoleName(g.BuiltInRole)
+	}
+}
+
+func (g *Grant) logContext() []any {
+	return []any{
+		"grantID", g.ID, "orgID", g.OrgID, "resource", g.Resource, "resourceID", g.ResourceID,
+		"userID", g.UserID, "teamID", g.TeamID, "builtInRole", g.BuiltInRole,
+		"permission", g.Permission, "previousPermission", g.PreviousPermission,
+		"requestedBy", g.RequestedBy, "decidedBy", g.DecidedBy,
+	}
+}
+
+type GrantPermissionCommand struct {
+	ResourceID  string
+	UserID      int64
+	TeamID      int64
+	BuiltInRole string
+	Permission  string
+	Duration    time.Duration
+	Reason      string
+	// RequestedBy is the user granting the permission, or requesting it for themselves
+	RequestedBy int64
+}
+
+// GrantPermission assigns a permission on a resource until the duration of the grant elapses.
+func (s *Service) GrantPermission(ctx context.Context, orgID int64, cmd GrantPermissionCommand) (*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GrantPermission")
+	defer span.End()
+
+	grant, err := s.newGrant(ctx, orgID, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activateGrant(ctx, grant, cmd.RequestedBy); err != nil {
+			return err
+		}
+		return s.grants.insertGrant(ctx, grant)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.grantLog.Info("Temporary permission granted", append(grant.logContext(), "expiresAt", grant.ExpiresAt)...)
+	return grant, nil
+}
+
+// RequestPermission records the request of a user for a temporary permission on a resource.
+// The permission is only assigned once the request is approved.
+func (s *Service) RequestPermission(ctx context.Context, orgID int64, cmd GrantPermissionCommand) (*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RequestPermission")
+	defer span.End()
+
+	if cmd.UserID == 0 || cmd.UserID != cmd.RequestedBy || cmd.TeamID != 0 || cmd.BuiltInRole != "" {
+		return nil, ErrInvalidGrant.Build(ErrGrantData("permissions can only be requested by users for themselves"))
+	}
+
+	grant, err := s.newGrant(ctx, orgID, cmd)
+	if err != nil {
+		return nil, err
+	}
+	grant.Status = GrantStatusPending
+
+	if err := s.grants.insertGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	s.grantLog.Info("Temporary permission requested", grant.logContext()...)
+	return grant, nil
+}
+
+// ApproveGrant assigns the permission of a pending request for the duration of the request.
+func (s *Service) ApproveGrant(ctx context.Context, orgID int64, resourceID string, grantID, approvedBy int64) (*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.ApproveGrant")
+	defer span.End()
+
+	grant, err := s.grants.getGrant(ctx, orgID, s.options.Resource, resourceID, grantID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != GrantStatusPending {
+		return nil, ErrGrantConflict.Build(ErrGrantData(fmt.Sprintf("grant is %s", grant.Status)))
+	}
+	if grant.RequestedBy == approvedBy {
+		return nil, ErrGrantSelfApproval.Errorf("user %d cannot approve their own request", approvedBy)
+	}
+
+	// The user may have been removed from the organization since the request.
+	if err := s.validateUser(ctx, orgID, grant.UserID); err != nil {
+		return nil, err
+	}
+
+	err = s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.activateGrant(ctx, grant, approvedBy); err != nil {
+			return err
+		}
+		return s.transitionGrant(ctx, grant, GrantStatusPending)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.grantLog.Info("Temporary permission request approved", append(grant.logContext(), "expiresAt", grant.ExpiresAt)...)
+	return grant, nil
+}
+
+// RejectGrant rejects a pending request.
+func (s *Service) RejectGrant(ctx context.Context, orgID int64, resourceID string, grantID, rejectedBy int64) (*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RejectGrant")
+	defer span.End()
+
+	grant, err := s.grants.getGrant(ctx, orgID, s.options.Resource, resourceID, grantID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Status != GrantStatusPending {
+		return nil, ErrGrantConflict.Build(ErrGrantData(fmt.Sprintf("grant is %s", grant.Status)))
+	}
+	if grant.RequestedBy == rejectedBy {
+		return nil, ErrGrantSelfApproval.Errorf("user %d cannot reject their own request", rejectedBy)
+	}
+
+	grant.Status = GrantStatusRejected
+	grant.DecidedBy = rejectedBy
+	if err := s.transitionGrant(ctx, grant, GrantStatusPending); err != nil {
+		return nil, err
+	}
+
+	s.grantLog.Info("Temporary permission request rejected", grant.logContext()...)
+	return grant, nil
+}
+
+// RevokeGrant ends an active grant before its expiry and restores the previous permission of the
+// assignee, or cancels a pending request.
+func (s *Service) RevokeGrant(ctx context.Context, orgID int64, resourceID string, grantID, revokedBy int64) (*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.RevokeGrant")
+	defer span.End()
+
+	grant, err := s.grants.getGrant(ctx, orgID, s.options.Resource, resourceID, grantID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch grant.Status {
+	case GrantStatusPending:
+		grant.Status = GrantStatusRevoked
+		grant.DecidedBy = revokedBy
+		if err := s.transitionGrant(ctx, grant, GrantStatusPending); err != nil {
+			return nil, err
+		}
+	case GrantStatusActive:
+		grant.DecidedBy = revokedBy
+		ended, err := s.endGrant(ctx, grant, GrantStatusRevoked)
+		if err != nil {
+			return nil, err
+		}
+		if !ended {
+			return nil, ErrGrantConflict.Build(ErrGrantData("grant already ended"))
+		}
+	default:
+		return nil, ErrGrantConflict.Build(ErrGrantData(fmt.Sprintf("grant is %s", grant.Status)))
+	}
+
+	s.grantLog.Info("Temporary permission revoked", grant.logContext()...)
+	return grant, nil
+}
+
+// GetGrants returns the grants of a resource, most recent first. All grants are returned if no status is given.
+func (s *Service) GetGrants(ctx context.Context, orgID int64, resourceID string, statuses ...GrantStatus) ([]*Grant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.GetGrants")
+	defer span.End()
+
+	return s.grants.listGrants(ctx, orgID, s.options.Resource, resourceID, statuses)
+}
+
+// ExpireGrants ends the active grants that expired before now and restores the previous
+// permissions of their assignees. It returns the number of expired grants.
+func (s *Service) ExpireGrants(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.resourcepermissions.ExpireGrants")
+	defer span.End()
+
+	grants, err := s.grants.listExpiredGrants(ctx, s.options.Resource, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired %s grants: %w", s.options.Resource, err)
+	}
+
+	expired := 0
+	for _, grant := range grants {
+		ended, err := s.endGrant(ctx, grant, GrantStatusExpired)
+		if err != nil {
+			s.grantLog.Error("Failed to expire temporary permission", append(grant.logContext(), "error", err)...)
+			continue
+		}
+		if ended {
+			expired++
+			s.grantLog.Info("Temporary permission expired", append(grant.logContext(), "expiresAt", grant.ExpiresAt)...)
+		}
+	}
+	return expired, nil
+}
+
+// newGrant validates a command and returns the grant it describes.
+func (s *Service) newGrant(ctx context.Context, orgID int64, cmd GrantPermissionCommand) (*Grant, error) {
+	assignees := 0
+	for _, set := range []bool{cmd.UserID != 0, cmd.TeamID != 0, cmd.BuiltInRole != ""} {
+		if set {
+			assignees++
+		}
+	}
+	if assignees != 1 {
+		return nil, ErrInvalidGrant.Build(ErrGrantData("exactly one of user, team or built-in role must be set"))
+	}
+	if cmd.Permission == "" {
+		return nil, ErrInvalidGrant.Build(ErrGrantData("permission is required"))
+	}
+	if cmd.Duration < time.Minute || cmd.Duration > s.grantMaxDuration {
+		return nil, ErrInvalidGrant.Build(ErrGrantData(fmt.Sprintf("duration must be between 1m and %s", s.grantMaxDuration)))
+	}
+	if _, err := s.mapPermission(cmd.Permission); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateResource(ctx, orgID, cmd.ResourceID); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch {
+	case cmd.UserID != 0:
+		err = s.validateUser(ctx, orgID, cmd.UserID)
+	case cmd.TeamID != 0:
+		err = s.validateTeam(ctx, orgID, cmd.TeamID)
+	default:
+		err = s.validateBuiltinRole(ctx, cmd.BuiltInRole)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Grant{
+		OrgID:       orgID,
+		Resource:    s.options.Resource,
+		ResourceID:  cmd.ResourceID,
+		UserID:      cmd.UserID,
+		TeamID:      cmd.TeamID,
+		BuiltInRole: cmd.BuiltInRole,
+		Permission:  cmd.Permission,
+		Duration:    int64(cmd.Duration / time.Second),
+		Reason:      cmd.Reason,
+		RequestedBy: cmd.RequestedBy,
+	}, nil
+}
+
+// activateGrant assigns the permission of a grant, remembering the permission it replaces. It must
+// run in the transaction persisting the grant.
+func (s *Service) activateGrant(ctx context.Context, grant *Grant, decidedBy int64) error {
+	active, err := s.grants.getActiveGrant(ctx, grant)
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return ErrGrantConflict.Build(ErrGrantData(fmt.Sprintf("grant %d is already active for this assignee", active.ID)))
+	}
+
+	previous, err := s.getAssigneePermission(ctx, grant)
+	if err != nil {
+		return err
+	}
+	// A grant only ever raises the permission of its assignee, otherwise it would lower a permission
+	// that was not granted temporarily.
+	if s.includesPermission(previous, grant.Permission) {
+		return ErrGrantConflict.Build(ErrGrantData(fmt.Sprintf("assignee already has the %s permission", previous)))
+	}
+
+	if err := s.setAssigneePermission(ctx, grant, grant.Permission); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(grant.Duration) * time.Second)
+	grant.Status = GrantStatusActive
+	grant.PreviousPermission = previous
+	grant.DecidedBy = decidedBy
+	grant.Updated = now
+	grant.ExpiresAt = &expiresAt
+	return nil
+}
+
+// endGrant moves an active grant to its final status and restores the previous permission of the
+// assignee. The permission is left untouched if it was changed since the grant was activated.
+// It returns false if the grant was already ended, e.g. by another instance.
+func (s *Service) endGrant(ctx context.Context, grant *Grant, status GrantStatus) (bool, error) {
+	ended := false
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		grant.Status = status
+		grant.Updated = time.Now()
+		updated, err := s.grants.updateGrantStatus(ctx, grant, GrantStatusActive)
+		if err != nil || !updated {
+			return err
+		}
+		ended = true
+
+		current, err := s.getAssigneePermission(ctx, grant)
+		if err != nil {
+			return err
+		}
+		if current != grant.Permission {
+			s.grantLog.Warn("Permission changed during temporary grant, not restoring the previous permission", append(grant.logContext(), "currentPermission", current)...)
+			return nil
+		}
+		return s.setAssigneePermission(ctx, grant, grant.PreviousPermission)
+	})
+	return ended, err
+}
+
+func (s *Service) transitionGrant(ctx context.Context, grant *Grant, from GrantStatus) error {
+	grant.Updated = time.Now()
+	updated, err := s.grants.updateGrantStatus(ctx, grant, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrGrantConflict.Build(ErrGrantData("grant was modified concurrently"))
+	}
+	return nil
+}
+
+// getAssigneePermission returns the managed permission of the assignee of a grant on its resource.
+func (s *Service) getAssigneePermission(ctx context.Context, grant *Grant) (string, error) {
+	scope := accesscontrol.Scope(s.options.Resource, s.options.ResourceAttribute, grant.ResourceID)
+	actions, err := s.grants.getManagedActions(ctx, grant.OrgID, grant.roleName(), scope)
+	if err != nil {
+		return "", err
+	}
+	return s.permissionForActions(actions), nil
+}
+
+// setAssigneePermission sets the managed permission of the assignee of a grant, without the
+// validations already done when the grant was created.
+func (s *Service) setAssigneePermission(ctx context.Context, grant *Grant, permission string) error {
+	actions, err := s.mapPermission(permission)
+	if err != nil {
+		return err
+	}
+
+	cmd := SetResourcePermissionCommand{
+		Actions:           actions,
+		Permission:        permission,
+		Resource:          s.options.Resource,
+		ResourceID:        grant.ResourceID,
+		ResourceAttribute: s.options.ResourceAttribute,
+	}
+	switch {
+	case grant.UserID != 0:
+		_, err = s.store.SetUserResourcePermission(ctx, grant.OrgID, accesscontrol.User{ID: grant.UserID}, cmd, s.options.OnSetUser)
+	case grant.TeamID != 0:
+		_, err = s.store.SetTeamResourcePermission(ctx, grant.OrgID, grant.TeamID, cmd, s.options.OnSetTeam)
+	default:
+		_, err = s.store.SetBuiltInResourcePermission(ctx, grant.OrgID, grant.BuiltInRole, cmd, s.options.OnSetBuiltInRole)
+	}
+	return err
+}
+
+// includesPermission returns true if the actions of a permission include all the actions of another one.
+func (s *Service) includesPermission(permission, other string) bool {
+	if permission == "" {
+		return false
+	}
+	actions := make(map[string]bool, len(s.options.PermissionsToActions[permission]))
+	for _, a := range s.options.PermissionsToActions[permission] {
+		actions[a] = true
+	}
+	for _, a := range s.options.PermissionsToActions[other] {
+		if !actions[a] {
+			return false
+		}
+	}
+	return true
+}
+
+// permissionForActions maps the actions of a managed role on a resource to their permission.
+// Folders and dashboards can store their action set only.
+func (s *Service) permissionForActions(actions []string) string {
+	if len(actions) == 0 {
+		return ""
+	}
+	for _, p := range s.permissions {
+		actionSet := GetActionSetName(s.options.Resource, p)
+		for _, a := range actions {
+			if a == actionSet {
+				return p
+			}
+		}
+	}
+	return s.MapActions(accesscontrol.ResourcePermission{Actions: actions})
+}
+
+// GrantExpirer is implemented by the resource permission services supporting temporary grants.
+type GrantExpirer interface {
+	ExpireGrants(ctx context.Context, now time.Time) (int, error)
+}
+
+var _ GrantExpirer = (*Service)(nil)
+
+// GrantReaper expires the temporary permission grants of the resource permission services.
+type GrantReaper struct {
+	interval time.Duration
+	services []GrantExpirer
+	log      log.Logger
+}
+
+func NewGrantReaper(cfg *setting.Cfg, services ...GrantExpirer) *GrantReaper {
+	interval := cfg.RBAC.PermissionGrantReaperInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &GrantReaper{
+		interval: interval,
+		services: services,
+		log:      log.New("accesscontrol.grants.reaper"),
+	}
+}
+
+func (r *GrantReaper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			r.expireGrants(ctx, time.Now())
+		}
+	}
+}
+
+func (r *GrantReaper) expireGrants(ctx context.Context, now time.Time) {
+	for _, s := range r.services {
+		if _, err := s.ExpireGrants(ctx, now); err != nil {
+			r.log.Error("Failed to expire temporary permission grants", "error", err)
+		}
+	}
+}
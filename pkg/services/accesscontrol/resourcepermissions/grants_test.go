@@ -0,0 +1,243 @@
+package resourcepermissions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util/testutil"
+)
+
+func setupGrantTestEnvironment(t *testing.T) (*Service, *user.User, *user.User) {
+	t.Helper()
+
+	service, usrSvc, _ := setupTestEnvironment(t, Options{
+		Resource:          "datasources",
+		ResourceAttribute: "uid",
+		Assignments:       Assignments{Users: true, Teams: true, BuiltInRoles: true},
+		PermissionsToActions: map[string][]string{
+			"Query": {"datasources:query", "datasources:read"},
+			"Edit":  {"datasources:query", "datasources:read", "datasources:write"},
+		},
+	})
+
+	requester, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "oncall", OrgID: 1})
+	require.NoError(t, err)
+	approver, err := usrSvc.Create(context.Background(), &user.CreateUserCommand{Login: "admin", OrgID: 1})
+	require.NoError(t, err)
+
+	return service, requester, approver
+}
+
+func assertUserPermission(t *testing.T, service *Service, userID int64, expected string) {
+	t.Helper()
+
+	permission, err := service.getAssigneePermission(context.Background(), &Grant{OrgID: 1, ResourceID: "ds1", UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, expected, permission)
+}
+
+func TestIntegrationService_GrantPermission(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	t.Run("should restore the previous permission when the grant expires", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		_, err := service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: requester.ID}, "ds1", "Query")
+		require.NoError(t, err)
+
+		grant, err := service.GrantPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: 4 * time.Hour, RequestedBy: approver.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatusActive, grant.Status)
+		assert.Equal(t, "Query", grant.PreviousPermission)
+		assertUserPermission(t, service, requester.ID, "Edit")
+
+		expired, err := service.ExpireGrants(context.Background(), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, expired)
+
+		expired, err = service.ExpireGrants(context.Background(), time.Now().Add(5*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assertUserPermission(t, service, requester.ID, "Query")
+
+		grants, err := service.GetGrants(context.Background(), 1, "ds1", GrantStatusExpired)
+		require.NoError(t, err)
+		require.Len(t, grants, 1)
+		assert.Equal(t, grant.ID, grants[0].ID)
+	})
+
+	t.Run("should not restore the previous permission if it was changed during the grant", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+
+		_, err := service.GrantPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: time.Hour, RequestedBy: approver.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: requester.ID}, "ds1", "Query")
+		require.NoError(t, err)
+
+		expired, err := service.ExpireGrants(context.Background(), time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, expired)
+		assertUserPermission(t, service, requester.ID, "Query")
+	})
+
+	t.Run("should reject a second active grant for the same assignee", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		cmd := GrantPermissionCommand{ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: time.Hour, RequestedBy: approver.ID}
+
+		_, err := service.GrantPermission(context.Background(), 1, cmd)
+		require.NoError(t, err)
+		_, err = service.GrantPermission(context.Background(), 1, cmd)
+		require.ErrorIs(t, err, ErrGrantConflict)
+	})
+
+	t.Run("should reject a grant that does not raise the current permission", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		_, err := service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: requester.ID}, "ds1", "Edit")
+		require.NoError(t, err)
+
+		for _, permission := range []string{"Query", "Edit"} {
+			_, err = service.GrantPermission(context.Background(), 1, GrantPermissionCommand{
+				ResourceID: "ds1", UserID: requester.ID, Permission: permission, Duration: time.Hour, RequestedBy: approver.ID,
+			})
+			require.ErrorIs(t, err, ErrGrantConflict)
+		}
+		assertUserPermission(t, service, requester.ID, "Edit")
+	})
+
+	t.Run("should validate the grant", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+
+		invalid := []GrantPermissionCommand{
+			{ResourceID: "ds1", Permission: "Edit", Duration: time.Hour},
+			{ResourceID: "ds1", UserID: requester.ID, BuiltInRole: "Viewer", Permission: "Edit", Duration: time.Hour},
+			{ResourceID: "ds1", UserID: requester.ID, Duration: time.Hour},
+			{ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: 25 * time.Hour},
+			{ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: time.Second},
+		}
+		for _, cmd := range invalid {
+			cmd.RequestedBy = approver.ID
+			_, err := service.GrantPermission(context.Background(), 1, cmd)
+			require.ErrorIs(t, err, ErrInvalidGrant)
+		}
+
+		_, err := service.GrantPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: requester.ID, Permission: "Admin", Duration: time.Hour, RequestedBy: approver.ID,
+		})
+		require.ErrorIs(t, err, ErrInvalidPermission)
+	})
+
+	t.Run("should grant a permission to a built-in role", func(t *testing.T) {
+		service, _, approver := setupGrantTestEnvironment(t)
+
+		grant, err := service.GrantPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", BuiltInRole: "Viewer", Permission: "Edit", Duration: time.Hour, RequestedBy: approver.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = service.RevokeGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.NoError(t, err)
+
+		permission, err := service.getAssigneePermission(context.Background(), &Grant{OrgID: 1, ResourceID: "ds1", BuiltInRole: "Viewer"})
+		require.NoError(t, err)
+		assert.Empty(t, permission)
+	})
+}
+
+func TestIntegrationService_RequestPermission(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	request := func(t *testing.T, service *Service, requester *user.User) *Grant {
+		t.Helper()
+		grant, err := service.RequestPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: requester.ID, Permission: "Edit", Duration: 4 * time.Hour, Reason: "incident", RequestedBy: requester.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatusPending, grant.Status)
+		assertUserPermission(t, service, requester.ID, "")
+		return grant
+	}
+
+	t.Run("should assign the permission once approved", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		grant := request(t, service, requester)
+
+		_, err := service.ApproveGrant(context.Background(), 1, "ds1", grant.ID, requester.ID)
+		require.ErrorIs(t, err, ErrGrantSelfApproval)
+
+		approved, err := service.ApproveGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatusActive, approved.Status)
+		assert.Equal(t, approver.ID, approved.DecidedBy)
+		require.NotNil(t, approved.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(4*time.Hour), *approved.ExpiresAt, time.Minute)
+		assertUserPermission(t, service, requester.ID, "Edit")
+
+		_, err = service.RejectGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.ErrorIs(t, err, ErrGrantConflict)
+
+		revoked, err := service.RevokeGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatusRevoked, revoked.Status)
+		assertUserPermission(t, service, requester.ID, "")
+	})
+
+	t.Run("should not assign the permission once rejected", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		grant := request(t, service, requester)
+
+		rejected, err := service.RejectGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.NoError(t, err)
+		assert.Equal(t, GrantStatusRejected, rejected.Status)
+
+		_, err = service.ApproveGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.ErrorIs(t, err, ErrGrantConflict)
+		assertUserPermission(t, service, requester.ID, "")
+	})
+
+	t.Run("should only allow users to request permissions for themselves", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+
+		_, err := service.RequestPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: approver.ID, Permission: "Edit", Duration: time.Hour, RequestedBy: requester.ID,
+		})
+		require.ErrorIs(t, err, ErrInvalidGrant)
+	})
+
+	t.Run("should not lower a higher permission when approved", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		grant, err := service.RequestPermission(context.Background(), 1, GrantPermissionCommand{
+			ResourceID: "ds1", UserID: requester.ID, Permission: "Query", Duration: time.Hour, RequestedBy: requester.ID,
+		})
+		require.NoError(t, err)
+
+		_, err = service.SetUserPermission(context.Background(), 1, accesscontrol.User{ID: requester.ID}, "ds1", "Edit")
+		require.NoError(t, err)
+
+		_, err = service.ApproveGrant(context.Background(), 1, "ds1", grant.ID, approver.ID)
+		require.ErrorIs(t, err, ErrGrantConflict)
+		assertUserPermission(t, service, requester.ID, "Edit")
+
+		expired, err := service.ExpireGrants(context.Background(), time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, expired)
+		assertUserPermission(t, service, requester.ID, "Edit")
+	})
+
+	t.Run("should not find grants of other resources", func(t *testing.T) {
+		service, requester, approver := setupGrantTestEnvironment(t)
+		grant := request(t, service, requester)
+
+		_, err := service.ApproveGrant(context.Background(), 1, "ds2", grant.ID, approver.ID)
+		require.ErrorIs(t, err, ErrGrantNotFound)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
@@ -105,6 +106,13 @@ func New(cfg *setting.Cfg,
 		teamService:  teamService,
 		userService:  userService,
 		actionSetSvc: actionSetService,
+
+		grants:           &grantStore{sql: sqlStore},
+		grantMaxDuration: cfg.RBAC.PermissionGrantMaxDuration,
+		grantLog:         log.New("accesscontrol.grants"),
+	}
+	if s.grantMaxDuration <= 0 {
+		s.grantMaxDuration = DefaultGrantMaxDuration
 	}
 
 	s.api = newApi(cfg, ac, router, s, features, s.options.RestConfigProvider)
@@ -135,6 +143,10 @@ type Service struct {
 	teamService  team.Service
 	userService  user.Service
 	actionSetSvc ActionSetService
+
+	grants           *grantStore
+	grantMaxDuration time.Duration
+	grantLog         log.Logger
 }
 
 func (s *Service) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
@@ -345,6 +357,9 @@ func (s *Service) MapActions(permission accesscontrol.ResourcePermission) string
 }
 
 func (s *Service) DeleteResourcePermissions(ctx context.Context, orgID int64, resourceID string) error {
+	if err := s.grants.endResourceGrants(ctx, orgID, s.options.Resource, resourceID, time.Now()); err != nil {
+		return err
+	}
 	return s.store.DeleteResourcePermissions(ctx, orgID, &DeleteResourcePermissionsCmd{
 		Resource:          s.options.Resource,
 		ResourceAttribute: s.options.ResourceAttribute,
@@ -10,7 +10,8 @@ import (
 
 // CleanupServiceImpl is responsible for cleaning old annotations.
 type CleanupServiceImpl struct {
-	store store
+	store   store
+	archive *annotationArchive
 }
 
 func ProvideCleanupService(db db.DB, cfg *setting.Cfg) *CleanupServiceImpl {
@@ -30,23 +31,44 @@ const (
 // from the annotation_tag table. Cleanup actions are performed in batches
 // so that no query takes too long to complete.
 //
+// The retention policies are applied afterwards. Annotations matched by a
+// policy with tags are only subject to the policies with tags, which allows
+// keeping them longer than the other annotations of their source. Retention
+// only applies to the annotations stored in the database: the annotations
+// that the CompositeStore reads from other stores, such as the Loki alert
+// state history, follow the retention of their backend, and policies for
+// the alert source are rejected when it is Loki. Summaries of
+// compacted annotations are regular annotations and are returned by the
+// database store like any other annotation.
+//
 // Returns the number of annotation and annotation_tag rows deleted. If an
 // error occurs, it returns the number of rows affected so far.
 func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64, int64, error) {
+	policies, err := cs.compileRetentionPolicies(ctx, cfg.AnnotationRetentionPolicies)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	var totalCleanedAnnotations int64
-	affected, err := cs.store.CleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, alertAnnotationType)
+	affected, err := cs.store.CleanAnnotations(ctx, cfg.AlertingAnnotationCleanupSetting, policies.withoutTagged(alertAnnotationType))
+	totalCleanedAnnotations += affected
+	if err != nil {
+		return totalCleanedAnnotations, 0, err
+	}
+
+	affected, err = cs.store.CleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, policies.withoutTagged(apiAnnotationType))
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.APIAnnotationCleanupSettings, apiAnnotationType)
+	affected, err = cs.store.CleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, policies.withoutTagged(dashboardAnnotationType))
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
 
-	affected, err = cs.store.CleanAnnotations(ctx, cfg.DashboardAnnotationCleanupSettings, dashboardAnnotationType)
+	affected, err = cs.applyRetentionPolicies(ctx, cfg, policies)
 	totalCleanedAnnotations += affected
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
@@ -0,0 +1,353 @@
+package annotationsimpl
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/tag"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+)
+
+// compactedAnnotationType is stored in the legacy type column of the summaries of compacted annotations
+// so that they are not compacted again.
+const compactedAnnotationType = "compacted"
+
+var annotationSourceConditions = map[string]string{
+	setting.AnnotationSourceAlert:     alertAnnotationType,
+	setting.AnnotationSourceDashboard: dashboardAnnotationType,
+	setting.AnnotationSourceAPI:       apiAnnotationType,
+}
+
+type retentionPolicy struct {
+	setting.AnnotationRetentionPolicy
+	// condition selects the annotations of the policy in the annotation table
+	condition string
+}
+
+type retentionPolicies []retentionPolicy
+
+// compileRetentionPolicies builds the conditions selecting the annotations of each policy. Annotations matched
+// by a policy with tags are excluded from the policies without tags.
+func (cs *CleanupServiceImpl) compileRetentionPolicies(ctx context.Context, policies []setting.AnnotationRetentionPolicy) (retentionPolicies, error) {
+	compiled := make(retentionPolicies, 0, len(policies))
+	for _, p := range policies {
+		conditions := []string{annotationSourceConditions[p.Source]}
+		if len(p.Tags) > 0 {
+			tags := tag.JoinTagPairs(tag.ParseTagPairs(p.Tags))
+			slices.Sort(tags)
+			tags = slices.Compact(tags)
+
+			tagIDs, err := cs.store.GetTagIDs(ctx, tags)
+			if err != nil {
+				return nil, err
+			}
+			if len(tagIDs) < len(tags) {
+				// some tags have never been used, no annotation can match
+				conditions = append(conditions, "1 = 0")
+			} else {
+				conditions = append(conditions, fmt.Sprintf(
+					"(SELECT COUNT(*) FROM annotation_tag WHERE annotation_tag.annotation_id = annotation.id AND annotation_tag.tag_id IN (%s)) = %d",
+					joinIDs(tagIDs), len(tagIDs),
+				))
+			}
+		}
+		compiled = append(compiled, retentionPolicy{AnnotationRetentionPolicy: p, condition: and(conditions...)})
+	}
+
+	for i := range compiled {
+		if len(compiled[i].Tags) == 0 {
+			compiled[i].condition = compiled.withoutTagged(compiled[i].condition)
+		}
+	}
+	return compiled, nil
+}
+
+// withoutTagged restricts the condition to the annotations that no policy with tags applies to.
+func (p retentionPolicies) withoutTagged(condition string) string {
+	conditions := []string{condition}
+	for _, policy := range p {
+		if len(policy.Tags) > 0 {
+			conditions = append(conditions, "NOT ("+policy.condition+")")
+		}
+	}
+	return and(conditions...)
+}
+
+// applyRetentionPolicies deletes the annotations older than the max age of their policy and compacts the region
+// annotations older than the compaction age. Annotations are archived first when the policy requires it.
+func (cs *CleanupServiceImpl) applyRetentionPolicies(ctx context.Context, cfg *setting.Cfg, policies retentionPolicies) (int64, error) {
+	var archive *annotationArchive
+	if slices.ContainsFunc(policies, func(p retentionPolicy) bool { return p.Archive }) {
+		var err error
+		if archive, err = cs.openArchive(ctx, cfg); err != nil {
+			return 0, err
+		}
+	}
+
+	var totalAffected int64
+	for _, p := range policies {
+		policyArchive := archive
+		if !p.Archive {
+			policyArchive = nil
+		}
+
+		if p.MaxAge > 0 {
+			affected, err := cs.expire(ctx, p, policyArchive)
+			totalAffected += affected
+			if err != nil {
+				return totalAffected, fmt.Errorf("failed to apply the max age of retention policy %s: %w", p.Name, err)
+			}
+		}
+
+		if p.CompactAfter > 0 {
+			affected, err := cs.compact(ctx, p, policyArchive)
+			totalAffected += affected
+			if err != nil {
+				return totalAffected, fmt.Errorf("failed to compact annotations of retention policy %s: %w", p.Name, err)
+			}
+		}
+	}
+	return totalAffected, nil
+}
+
+func (cs *CleanupServiceImpl) expire(ctx context.Context, p retentionPolicy, archive *annotationArchive) (int64, error) {
+	if archive == nil {
+		return cs.store.CleanAnnotations(ctx, setting.AnnotationCleanupSettings{MaxAge: p.MaxAge}, p.condition)
+	}
+
+	cutoffDate := timeNow().Add(-p.MaxAge).UnixNano() / int64(time.Millisecond)
+	return untilDoneOrCancelled(ctx, func() (int64, error) {
+		items, err := cs.store.FindAnnotations(ctx, fmt.Sprintf(`%s AND created < %d`, p.condition, cutoffDate))
+		if err != nil {
+			return 0, err
+		}
+		if err := archive.write(ctx, p.Name, items); err != nil {
+			return 0, err
+		}
+		return cs.store.DeleteAnnotations(ctx, itemIDs(items))
+	})
+}
+
+// compact replaces the region annotations of a panel starting in the same compaction interval with a single
+// summary annotation spanning all of them. Point annotations and summaries are left untouched.
+func (cs *CleanupServiceImpl) compact(ctx context.Context, p retentionPolicy, archive *annotationArchive) (int64, error) {
+	cutoffDate := timeNow().Add(-p.CompactAfter).UnixNano() / int64(time.Millisecond)
+	interval := p.CompactInterval.Milliseconds()
+
+	var totalAffected, lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return totalAffected, ctx.Err()
+		default:
+		}
+
+		// Annotations that cannot be compacted are not deleted, so we page through the candidates by ID instead of
+		// fetching the first batch again.
+		items, err := cs.store.FindAnnotations(ctx, fmt.Sprintf(`%s AND created < %d AND epoch <> epoch_end AND type <> '%s' AND id > %d`,
+			p.condition, cutoffDate, compactedAnnotationType, lastID))
+		if err != nil || len(items) == 0 {
+			return totalAffected, err
+		}
+		lastID = items[len(items)-1].ID
+
+		groups := groupForCompaction(items, interval)
+		if len(groups) == 0 {
+			continue
+		}
+
+		if archive != nil {
+			if err := archive.write(ctx, p.Name, slices.Concat(groups...)); err != nil {
+				return totalAffected, err
+			}
+		}
+
+		for _, group := range groups {
+			affected, err := cs.store.CompactAnnotations(ctx, summarize(group), itemIDs(group))
+			totalAffected += affected
+			if err != nil {
+				return totalAffected, err
+			}
+		}
+	}
+}
+
+type compactionKey struct {
+	orgID        int64
+	dashboardID  int64
+	dashboardUID string
+	panelID      int64
+	alertID      int64
+	bucket       int64
+}
+
+// groupForCompaction groups the annotations by panel and interval, dropping the groups of a single annotation.
+func groupForCompaction(items []*annotations.Item, interval int64) [][]*annotations.Item {
+	keys := make([]compactionKey, 0)
+	groups := make(map[compactionKey][]*annotations.Item)
+	for _, item := range items {
+		key := compactionKey{
+			orgID:        item.OrgID,
+			dashboardID:  item.DashboardID, // nolint: staticcheck
+			dashboardUID: item.DashboardUID,
+			panelID:      item.PanelID,
+			alertID:      item.AlertID,
+			bucket:       item.Epoch / interval,
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+
+	result := make([][]*annotations.Item, 0, len(keys))
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			result = append(result, groups[key])
+		}
+	}
+	return result
+}
+
+// summarize returns the annotation replacing the group. It spans the regions of the group, keeps the tags they
+// share and is as old as the most recent annotation of the group so that it expires after all of them.
+func summarize(group []*annotations.Item) *annotations.Item {
+	first := group[0]
+	summary := &annotations.Item{
+		OrgID:        first.OrgID,
+		DashboardID:  first.DashboardID, // nolint: staticcheck
+		DashboardUID: first.DashboardUID,
+		PanelID:      first.PanelID,
+		AlertID:      first.AlertID,
+		Epoch:        first.Epoch,
+		EpochEnd:     first.EpochEnd,
+		Created:      first.Created,
+		Tags:         first.Tags,
+		Type:         compactedAnnotationType,
+	}
+	for _, item := range group[1:] {
+		summary.Epoch = min(summary.Epoch, item.Epoch)
+		summary.EpochEnd = max(summary.EpochEnd, item.EpochEnd)
+		summary.Created = max(summary.Created, item.Created)
+		summary.Tags = slices.DeleteFunc(slices.Clone(summary.Tags), func(t string) bool {
+			return !slices.Contains(item.Tags, t)
+		})
+	}
+	summary.Updated = timeNow().UnixNano() / int64(time.Millisecond)
+	summary.Text = fmt.Sprintf("%d annotations compacted", len(group))
+	summary.Data = simplejson.NewFromAny(map[string]any{
+		"compacted": map[string]any{
+			"count": len(group),
+			"from":  summary.Epoch,
+			"to":    summary.EpochEnd,
+		},
+	})
+	return summary
+}
+
+// annotationArchive writes annotations to an object storage bucket as gzipped newline-delimited JSON.
+type annotationArchive struct {
+	url    string
+	prefix string
+	bucket *blob.Bucket
+}
+
+func (cs *CleanupServiceImpl) openArchive(ctx context.Context, cfg *setting.Cfg) (*annotationArchive, error) {
+	if cs.archive != nil && cs.archive.url == cfg.AnnotationArchiveBucketURL {
+		cs.archive.prefix = cfg.AnnotationArchivePrefix
+		return cs.archive, nil
+	}
+	if cfg.AnnotationArchiveBucketURL == "" {
+		return nil, fmt.Errorf("annotation archive bucket is not configured")
+	}
+
+	bucket, err := resource.OpenBlobBucket(ctx, cfg.AnnotationArchiveBucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open annotation archive bucket: %w", err)
+	}
+	if cs.archive != nil {
+		_ = cs.archive.bucket.Close()
+	}
+	cs.archive = &annotationArchive{
+		url:    cfg.AnnotationArchiveBucketURL,
+		prefix: cfg.AnnotationArchivePrefix,
+		bucket: bucket,
+	}
+	return cs.archive, nil
+}
+
+func (a *annotationArchive) write(ctx context.Context, policy string, items []*annotations.Item) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+
+	key := path.Join(a.prefix, policy, fmt.Sprintf("%d-%d-%d.ndjson.gz", timeNow().UnixNano(), items[0].ID, items[len(items)-1].ID))
+
+	// cancelling the context of the writer discards the object instead of committing it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := a.bucket.NewWriter(ctx, key, &blob.WriterOptions{
+		ContentType:     "application/x-ndjson",
+		ContentEncoding: "gzip",
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+	for _, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	return gz.Close()
+}
+
+func itemIDs(items []*annotations.Item) []int64 {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func joinIDs(ids []int64) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, fmt.Sprint(id))
+	}
+	return strings.Join(values, ", ")
+}
+
+func and(conditions ...string) string {
+	parts := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		if c != "" {
+			parts = append(parts, "("+c+")")
+		}
+	}
+	if len(parts) == 0 {
+		return "1 = 1"
+	}
+	return strings.Join(parts, " AND ")
+}
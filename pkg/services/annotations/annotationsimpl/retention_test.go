@@ -0,0 +1,238 @@
+package annotationsimpl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/testutil"
+)
+
+func TestIntegrationAnnotationRetentionPolicies(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	sql := db.InitTestDB(t)
+
+	cfg := setting.NewCfg()
+	cfg.AnnotationMaximumTagsLength = 60
+	cfg.AnnotationCleanupJobBatchSize = 2
+	repo := NewXormStore(cfg, log.New("annotation.test"), sql, tagimpl.ProvideService(sql), nil)
+
+	old := time.Now().AddDate(0, 0, -10).UnixNano() / int64(time.Millisecond)
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+
+	addAnnotation := func(t *testing.T, item annotations.Item, created int64) int64 {
+		t.Helper()
+		item.OrgID = 1
+		require.NoError(t, repo.Add(context.Background(), &item))
+		err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE annotation SET created = ? WHERE id = ?", created, item.ID)
+			return err
+		})
+		require.NoError(t, err)
+		return item.ID
+	}
+
+	getAnnotations := func(t *testing.T) map[int64]*annotations.Item {
+		t.Helper()
+		items := make([]*annotations.Item, 0)
+		err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			return sess.Find(&items)
+		})
+		require.NoError(t, err)
+		result := make(map[int64]*annotations.Item, len(items))
+		for _, item := range items {
+			result[item.ID] = item
+		}
+		return result
+	}
+
+	cleanup := func(t *testing.T) {
+		t.Cleanup(func() {
+			err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+				_, deleteAnnotationErr := sess.Exec("DELETE FROM annotation WHERE 1=1")
+				_, deleteAnnotationTagErr := sess.Exec("DELETE FROM annotation_tag WHERE 1=1")
+				return errors.Join(deleteAnnotationErr, deleteAnnotationTagErr)
+			})
+			assert.NoError(t, err)
+		})
+	}
+
+	dashboardAnnotation := func(panelID, epoch, epochEnd int64, tags ...string) annotations.Item {
+		return annotations.Item{
+			DashboardID:  1,
+			DashboardUID: "dash",
+			PanelID:      panelID,
+			Epoch:        epoch,
+			EpochEnd:     epochEnd,
+			Tags:         tags,
+		}
+	}
+
+	t.Run("policies with tags should take precedence over the source settings", func(t *testing.T) {
+		cleanup(t)
+
+		oldIncident := addAnnotation(t, dashboardAnnotation(1, start, start, "incident"), old)
+		oldDeploy := addAnnotation(t, dashboardAnnotation(1, start, start, "deploy"), old)
+		newIncident := addAnnotation(t, dashboardAnnotation(1, start, start, "incident"), time.Now().UnixNano()/int64(time.Millisecond))
+
+		runCfg := &setting.Cfg{
+			DashboardAnnotationCleanupSettings: settingsFn(48*time.Hour, 0),
+			AnnotationRetentionPolicies: []setting.AnnotationRetentionPolicy{
+				{Name: "incidents", Source: setting.AnnotationSourceDashboard, Tags: []string{"incident"}, MaxAge: 30 * 24 * time.Hour},
+			},
+		}
+		cleaner := ProvideCleanupService(sql, cfg)
+		affected, _, err := cleaner.Run(context.Background(), runCfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		remaining := getAnnotations(t)
+		assert.Contains(t, remaining, oldIncident)
+		assert.NotContains(t, remaining, oldDeploy)
+		assert.Contains(t, remaining, newIncident)
+
+		runCfg.AnnotationRetentionPolicies[0].MaxAge = 7 * 24 * time.Hour
+		affected, _, err = cleaner.Run(context.Background(), runCfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+
+		remaining = getAnnotations(t)
+		assert.NotContains(t, remaining, oldIncident)
+		assert.Contains(t, remaining, newIncident)
+	})
+
+	t.Run("should compact old region annotations of a panel", func(t *testing.T) {
+		cleanup(t)
+
+		minute := time.Minute.Milliseconds()
+		first := addAnnotation(t, dashboardAnnotation(1, start, start+minute, "deploy", "canary"), old)
+		second := addAnnotation(t, dashboardAnnotation(1, start+10*minute, start+20*minute, "deploy"), old)
+		otherPanel := addAnnotation(t, dashboardAnnotation(2, start, start+minute, "deploy"), old)
+		point := addAnnotation(t, dashboardAnnotation(1, start+5*minute, start+5*minute), old)
+		nextInterval := addAnnotation(t, dashboardAnnotation(1, start+90*minute, start+95*minute), old)
+
+		runCfg := &setting.Cfg{
+			AnnotationRetentionPolicies: []setting.AnnotationRetentionPolicy{
+				{Name: "regions", Source: setting.AnnotationSourceDashboard, CompactAfter: 24 * time.Hour, CompactInterval: time.Hour},
+			},
+		}
+		cleaner := ProvideCleanupService(sql, cfg)
+		affected, _, err := cleaner.Run(context.Background(), runCfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), affected)
+
+		remaining := getAnnotations(t)
+		require.Len(t, remaining, 4)
+		assert.NotContains(t, remaining, first)
+		assert.NotContains(t, remaining, second)
+		assert.Contains(t, remaining, otherPanel)
+		assert.Contains(t, remaining, point)
+		assert.Contains(t, remaining, nextInterval)
+
+		var summary *annotations.Item
+		for _, item := range remaining {
+			if item.Type == compactedAnnotationType {
+				summary = item
+			}
+		}
+		require.NotNil(t, summary)
+		assert.Equal(t, int64(1), summary.PanelID)
+		assert.Equal(t, start, summary.Epoch)
+		assert.Equal(t, start+20*minute, summary.EpochEnd)
+		assert.Equal(t, old, summary.Created)
+		assert.Equal(t, []string{"deploy"}, summary.Tags)
+		assert.Equal(t, 2, summary.Data.GetPath("compacted", "count").MustInt())
+
+		err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			count, err := sess.SQL("SELECT COUNT(*) FROM annotation_tag WHERE annotation_id = ?", summary.ID).Count()
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+			return nil
+		})
+		require.NoError(t, err)
+
+		// summaries are not compacted again
+		affected, _, err = cleaner.Run(context.Background(), runCfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), affected)
+	})
+
+	t.Run("should archive annotations before deleting them", func(t *testing.T) {
+		cleanup(t)
+
+		ids := []int64{
+			addAnnotation(t, annotations.Item{Epoch: start, Text: "first"}, old),
+			addAnnotation(t, annotations.Item{Epoch: start, Text: "second"}, old),
+			addAnnotation(t, annotations.Item{Epoch: start, Text: "third"}, old),
+		}
+
+		bucket := memblob.OpenBucket(nil)
+		t.Cleanup(func() { _ = bucket.Close() })
+
+		runCfg := &setting.Cfg{
+			AnnotationArchiveBucketURL: "mem://",
+			AnnotationArchivePrefix:    "annotations",
+			AnnotationRetentionPolicies: []setting.AnnotationRetentionPolicy{
+				{Name: "api", Source: setting.AnnotationSourceAPI, MaxAge: 24 * time.Hour, Archive: true},
+			},
+		}
+		cleaner := ProvideCleanupService(sql, cfg)
+		cleaner.archive = &annotationArchive{url: "mem://", bucket: bucket}
+		affected, _, err := cleaner.Run(context.Background(), runCfg)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), affected)
+		assert.Empty(t, getAnnotations(t))
+
+		archived := make([]int64, 0)
+		iter := bucket.List(&blob.ListOptions{Prefix: "annotations/api/"})
+		for {
+			obj, err := iter.Next(context.Background())
+			if err != nil {
+				break
+			}
+			r, err := bucket.NewReader(context.Background(), obj.Key, nil)
+			require.NoError(t, err)
+			gz, err := gzip.NewReader(r)
+			require.NoError(t, err)
+			scanner := bufio.NewScanner(gz)
+			for scanner.Scan() {
+				var item annotations.Item
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &item))
+				archived = append(archived, item.ID)
+			}
+			require.NoError(t, scanner.Err())
+			require.NoError(t, r.Close())
+		}
+		assert.ElementsMatch(t, ids, archived)
+	})
+}
+
+func TestGroupForCompaction(t *testing.T) {
+	hour := time.Hour.Milliseconds()
+	items := []*annotations.Item{
+		{ID: 1, OrgID: 1, PanelID: 1, Epoch: 0},
+		{ID: 2, OrgID: 1, PanelID: 2, Epoch: 0},
+		{ID: 3, OrgID: 1, PanelID: 1, Epoch: hour - 1},
+		{ID: 4, OrgID: 1, PanelID: 1, Epoch: hour},
+		{ID: 5, OrgID: 2, PanelID: 1, Epoch: 0},
+	}
+
+	groups := groupForCompaction(items, hour)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []int64{1, 3}, itemIDs(groups[0]))
+}
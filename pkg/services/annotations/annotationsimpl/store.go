@@ -32,4 +32,8 @@ type writeStore interface {
 	Delete(ctx context.Context, params *annotations.DeleteParams) error
 	CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error)
 	CleanOrphanedAnnotationTags(ctx context.Context) (int64, error)
+	GetTagIDs(ctx context.Context, tags []string) ([]int64, error)
+	FindAnnotations(ctx context.Context, condition string) ([]*annotations.Item, error)
+	DeleteAnnotations(ctx context.Context, ids []int64) (int64, error)
+	CompactAnnotations(ctx context.Context, summary *annotations.Item, ids []int64) (int64, error)
 }
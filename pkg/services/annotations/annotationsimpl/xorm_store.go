@@ -713,6 +713,83 @@ func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (i
 	})
 }
 
+// GetTagIDs returns the IDs of the tags that exist among the given key:value tags.
+func (r *xormRepositoryImpl) GetTagIDs(ctx context.Context, tags []string) ([]int64, error) {
+	ids := make([]int64, 0, len(tags))
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, t := range tag.ParseTagPairs(tags) {
+			var id int64
+			sql := `SELECT id FROM tag WHERE ` + r.db.GetDialect().Quote("key") + ` = ? AND ` + r.db.GetDialect().Quote("value") + ` = ?`
+			found, err := sess.SQL(sql, t.Key, t.Value).Get(&id)
+			if err != nil {
+				return err
+			}
+			if found {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// FindAnnotations returns a batch of the annotations matching the condition, ordered by ID.
+func (r *xormRepositoryImpl) FindAnnotations(ctx context.Context, condition string) ([]*annotations.Item, error) {
+	if condition == "" {
+		return nil, fmt.Errorf("condition must be supplied; cannot fetch entire table")
+	}
+	sql := fmt.Sprintf(`SELECT * FROM annotation WHERE %s ORDER BY id ASC %s`, condition, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+	items := make([]*annotations.Item, 0)
+	err := r.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql).Find(&items)
+	})
+	return items, err
+}
+
+// DeleteAnnotations deletes the annotations with the given IDs. Their annotation_tag rows are left for
+// CleanOrphanedAnnotationTags.
+func (r *xormRepositoryImpl) DeleteAnnotations(ctx context.Context, ids []int64) (int64, error) {
+	return r.deleteByIDs(ctx, "annotation", ids)
+}
+
+// CompactAnnotations replaces the annotations with the given IDs by the summary annotation in a single
+// transaction. The summary is tagged with the tags shared by all the replaced annotations.
+func (r *xormRepositoryImpl) CompactAnnotations(ctx context.Context, summary *annotations.Item, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// The IDs are put directly into the statements as the batch size may exceed the parameter limit of SQLite.
+	values := joinIDs(ids)
+	var affected int64
+	err := r.db.InTransaction(ctx, func(ctx context.Context) error {
+		return r.db.WithDbSession(ctx, func(sess *db.Session) error {
+			tagIDs := make([]int64, 0)
+			sql := fmt.Sprintf(`SELECT tag_id FROM annotation_tag WHERE annotation_id IN (%s) GROUP BY tag_id HAVING COUNT(DISTINCT annotation_id) = %d`, values, len(ids))
+			if err := sess.SQL(sql).Find(&tagIDs); err != nil {
+				return err
+			}
+
+			if _, err := sess.Table("annotation").Insert(summary); err != nil {
+				return err
+			}
+			for _, tagID := range tagIDs {
+				if _, err := sess.Table("annotation_tag").Insert(&annotationTag{AnnotationID: summary.ID, TagID: tagID}); err != nil {
+					return err
+				}
+			}
+
+			res, err := sess.Exec(fmt.Sprintf(`DELETE FROM annotation WHERE id IN (%s)`, values))
+			if err != nil {
+				return err
+			}
+			affected, err = res.RowsAffected()
+			return err
+		})
+	})
+	return affected, err
+}
+
 func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string) ([]int64, error) {
 	sql := fmt.Sprintf(`SELECT id FROM %s`, table)
 	if condition == "" {
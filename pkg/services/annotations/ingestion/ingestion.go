@@ -0,0 +1,46 @@
+package ingestion
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrSourceNotFound   = errutil.NotFound("annotations.ingestion.source-not-found", errutil.WithPublicMessage("Annotation source not found"))
+	ErrInvalidSignature = errutil.Unauthorized("annotations.ingestion.invalid-signature", errutil.WithPublicMessage("Invalid webhook signature"))
+	ErrTooManyEvents    = errutil.BadRequest("annotations.ingestion.too-many-events", errutil.WithPublicMessage("Too many events in the webhook payload"))
+
+	ErrInvalidPayload = errutil.BadRequest("annotations.ingestion.invalid-payload").MustTemplate(
+		"invalid webhook payload: {{ .Public.reason }}",
+		errutil.WithPublic("Invalid webhook payload: {{ .Public.reason }}"),
+	)
+)
+
+func ErrInvalidPayloadData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{Public: map[string]any{"reason": reason}}
+}
+
+// Service creates annotations from the webhooks of external systems such as CI/CD pipelines, deployment tools
+// or Alertmanager.
+type Service interface {
+	// Ingest verifies the signature of a webhook request sent to a source and stores the annotations mapped
+	// from its events.
+	Ingest(ctx context.Context, cmd IngestCommand) (*IngestResult, error)
+}
+
+type IngestCommand struct {
+	Source string
+	Header http.Header
+	Body   []byte
+}
+
+type IngestResult struct {
+	// Created is the number of new annotations
+	Created int `json:"created"`
+	// Updated is the number of annotations updated because an annotation with the same dedup key exists
+	Updated int `json:"updated"`
+	// Skipped is the number of events excluded by the filter of the source
+	Skipped int `json:"skipped"`
+}
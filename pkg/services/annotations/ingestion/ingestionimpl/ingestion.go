@@ -0,0 +1,219 @@
+package ingestionimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/ingestion"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var _ ingestion.Service = (*Service)(nil)
+
+// maxSaveAttempts bounds the retries of a payload whose dedup keys are linked by concurrent requests.
+const maxSaveAttempts = 3
+
+type Service struct {
+	cfg              setting.AnnotationIngestionSettings
+	sources          map[string]*source
+	store            store
+	repo             annotations.Repository
+	dashboardService dashboards.DashboardService
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, repo annotations.Repository, dashboardService dashboards.DashboardService) (*Service, error) {
+	s := &Service{
+		cfg:              cfg.AnnotationIngestion,
+		sources:          make(map[string]*source, len(cfg.AnnotationIngestion.Sources)),
+		store:            &xormStore{db: sqlStore},
+		repo:             repo,
+		dashboardService: dashboardService,
+		log:              log.New("annotations.ingestion"),
+		now:              time.Now,
+	}
+
+	for name, sourceCfg := range cfg.AnnotationIngestion.Sources {
+		src, err := newSource(sourceCfg)
+		if err != nil {
+			return nil, fmt.Errorf("[annotations.ingestion.%s] %w", name, err)
+		}
+		s.sources[name] = src
+	}
+	return s, nil
+}
+
+// keyedEvent is an event with a dedup key, it updates the annotation created for the first event with the same key.
+type keyedEvent struct {
+	*event
+	hash string
+}
+
+func (s *Service) Ingest(ctx context.Context, cmd ingestion.IngestCommand) (*ingestion.IngestResult, error) {
+	src, ok := s.sources[cmd.Source]
+	if !ok {
+		return nil, ingestion.ErrSourceNotFound.Errorf("annotation source %s not found", cmd.Source)
+	}
+	if !src.verify(cmd.Header, cmd.Body) {
+		return nil, ingestion.ErrInvalidSignature.Errorf("invalid signature for annotation source %s", cmd.Source)
+	}
+
+	payload, err := src.events(cmd.Body)
+	if err != nil {
+		return nil, ingestion.ErrInvalidPayload.Build(ingestion.ErrInvalidPayloadData(err.Error()))
+	}
+	if len(payload) > s.cfg.MaxItems {
+		return nil, ingestion.ErrTooManyEvents.Errorf("%d events exceed the maximum of %d", len(payload), s.cfg.MaxItems)
+	}
+
+	// All events are mapped before anything is stored so that an invalid event rejects the whole payload.
+	result := &ingestion.IngestResult{}
+	now := s.now()
+	dashboardIDs := make(map[string]int64)
+	unkeyed := make([]annotations.Item, 0)
+	keyed := make([]*keyedEvent, 0)
+	keyedByHash := make(map[string]*keyedEvent)
+	for i, data := range payload {
+		e, ok, err := src.render(data, now)
+		if err != nil {
+			return nil, ingestion.ErrInvalidPayload.Build(ingestion.ErrInvalidPayloadData(fmt.Sprintf("event %d: %s", i, err)))
+		}
+		if !ok {
+			result.Skipped++
+			continue
+		}
+
+		if e.item.DashboardUID != "" {
+			if e.item.DashboardID, err = s.getDashboardID(ctx, src.OrgID, e.item.DashboardUID, dashboardIDs); err != nil { // nolint: staticcheck
+				return nil, err
+			}
+		}
+
+		if e.dedupKey == "" {
+			unkeyed = append(unkeyed, e.item)
+			continue
+		}
+
+		hash := hashKey(e.dedupKey)
+		if previous, ok := keyedByHash[hash]; ok {
+			// the last event of the payload with the key wins, the annotation still starts at the first one
+			e.item.Epoch = min(e.item.Epoch, previous.item.Epoch)
+			e.item.EpochEnd = max(e.item.EpochEnd, previous.item.EpochEnd)
+			previous.event = e
+			continue
+		}
+		k := &keyedEvent{event: e, hash: hash}
+		keyed = append(keyed, k)
+		keyedByHash[hash] = k
+	}
+
+	// The annotations and their dedup keys are stored together. When a concurrent request links one of the keys first,
+	// the transaction is rolled back and run again to update the annotation of that request instead.
+	var created, updated int
+	for attempt := 1; ; attempt++ {
+		err := s.store.InTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			created, updated, err = s.save(ctx, src, unkeyed, keyed, now)
+			return err
+		})
+		if errors.Is(err, errDuplicateKey) && attempt < maxSaveAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		break
+	}
+	result.Created += created
+	result.Updated += updated
+
+	s.log.Debug("Ingested annotations", "source", src.Name, "created", result.Created, "updated", result.Updated, "skipped", result.Skipped)
+	return result, nil
+}
+
+// save stores the annotations of the events, updating the annotations already created for their dedup key.
+func (s *Service) save(ctx context.Context, src *source, unkeyed []annotations.Item, keyed []*keyedEvent, now time.Time) (created, updated int, err error) {
+	if len(unkeyed) > 0 {
+		if err := s.repo.SaveMany(ctx, unkeyed); err != nil {
+			return 0, 0, err
+		}
+		created += len(unkeyed)
+	}
+
+	if len(keyed) == 0 {
+		return created, updated, nil
+	}
+
+	hashes := make([]string, 0, len(keyed))
+	for _, k := range keyed {
+		hashes = append(hashes, k.hash)
+	}
+	existing, err := s.store.GetAnnotationIDs(ctx, src.OrgID, src.Name, hashes)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, k := range keyed {
+		if id, ok := existing[k.hash]; ok {
+			// later events of a deployment or an alert extend the annotation until their end or their time
+			item := k.item
+			item.ID = id
+			item.EpochEnd = max(item.EpochEnd, item.Epoch)
+			item.Epoch = 0
+			if err := s.repo.Update(ctx, &item); err != nil {
+				return 0, 0, err
+			}
+			updated++
+			continue
+		}
+
+		item := k.item
+		if err := s.repo.Save(ctx, &item); err != nil {
+			return 0, 0, err
+		}
+		if err := s.store.AddKey(ctx, &dedupKey{
+			OrgID:        src.OrgID,
+			Source:       src.Name,
+			KeyHash:      k.hash,
+			AnnotationID: item.ID,
+			Created:      now,
+		}); err != nil {
+			return 0, 0, err
+		}
+		created++
+	}
+	return created, updated, nil
+}
+
+func (s *Service) getDashboardID(ctx context.Context, orgID int64, uid string, cache map[string]int64) (int64, error) {
+	if id, ok := cache[uid]; ok {
+		return id, nil
+	}
+
+	ctx, _ = identity.WithServiceIdentity(ctx, orgID)
+	dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: orgID, UID: uid})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return 0, ingestion.ErrInvalidPayload.Build(ingestion.ErrInvalidPayloadData(fmt.Sprintf("dashboard %s not found", uid)))
+		}
+		return 0, err
+	}
+	cache[uid] = dashboard.ID
+	return dashboard.ID, nil
+}
+
+// hashKey returns the hash of a dedup key stored instead of the key whose length is not bounded.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,307 @@
+package ingestionimpl
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/ingestion"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeRepository struct {
+	annotations.Repository
+	saved   []annotations.Item
+	updated []annotations.Item
+}
+
+func (f *fakeRepository) Save(_ context.Context, item *annotations.Item) error {
+	item.ID = int64(len(f.saved) + 1)
+	f.saved = append(f.saved, *item)
+	return nil
+}
+
+func (f *fakeRepository) SaveMany(_ context.Context, items []annotations.Item) error {
+	f.saved = append(f.saved, items...)
+	return nil
+}
+
+func (f *fakeRepository) Update(_ context.Context, item *annotations.Item) error {
+	f.updated = append(f.updated, *item)
+	return nil
+}
+
+type fakeStore struct {
+	keys map[string]int64
+	// concurrent links the next added keys to annotations of a concurrent request
+	concurrent map[string]int64
+}
+
+func (f *fakeStore) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeStore) GetAnnotationIDs(_ context.Context, _ int64, _ string, hashes []string) (map[string]int64, error) {
+	result := make(map[string]int64)
+	for _, h := range hashes {
+		if id, ok := f.keys[h]; ok {
+			result[h] = id
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) AddKey(_ context.Context, key *dedupKey) error {
+	if id, ok := f.concurrent[key.KeyHash]; ok {
+		delete(f.concurrent, key.KeyHash)
+		f.keys[key.KeyHash] = id
+		return errDuplicateKey
+	}
+	if _, ok := f.keys[key.KeyHash]; ok {
+		return errDuplicateKey
+	}
+	f.keys[key.KeyHash] = key.AnnotationID
+	return nil
+}
+
+var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func setupTestService(t *testing.T, sources ...setting.AnnotationIngestionSource) (*Service, *fakeRepository) {
+	s, repo, _ := setupTestServiceWithStore(t, sources...)
+	return s, repo
+}
+
+func setupTestServiceWithStore(t *testing.T, sources ...setting.AnnotationIngestionSource) (*Service, *fakeRepository, *fakeStore) {
+	t.Helper()
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{OrgID: 1, UID: "deploys"}).Return(&dashboards.Dashboard{ID: 7, UID: "deploys"}, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+
+	repo := &fakeRepository{}
+	store := &fakeStore{keys: make(map[string]int64), concurrent: make(map[string]int64)}
+	s := &Service{
+		cfg:              setting.AnnotationIngestionSettings{MaxItems: 10},
+		sources:          make(map[string]*source),
+		store:            store,
+		repo:             repo,
+		dashboardService: dashboardService,
+		log:              log.NewNopLogger(),
+		now:              func() time.Time { return now },
+	}
+	for _, cfg := range sources {
+		src, err := newSource(cfg)
+		require.NoError(t, err)
+		s.sources[cfg.Name] = src
+	}
+	return s, repo, store
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestService_Ingest(t *testing.T) {
+	github := setting.AnnotationIngestionSource{Name: "github", Preset: "github", OrgID: 1, Secret: "secret"}
+	alertmanager := setting.AnnotationIngestionSource{Name: "am", Preset: "alertmanager", OrgID: 1, Secret: "token", DashboardUID: "deploys"}
+	generic := setting.AnnotationIngestionSource{
+		Name:   "ci",
+		OrgID:  1,
+		Secret: "secret",
+		Text:   `Build {{ .build }} of {{ .pipeline }}`,
+		Tags:   `ci, pipeline:{{ .pipeline }}, branch:{{ .branch }}`,
+		Time:   `{{ .started }}`,
+	}
+
+	t.Run("should reject unknown sources and invalid signatures", func(t *testing.T) {
+		s, repo := setupTestService(t, github)
+		body := `{}`
+
+		_, err := s.Ingest(context.Background(), ingestion.IngestCommand{Source: "gitlab", Body: []byte(body)})
+		require.ErrorIs(t, err, ingestion.ErrSourceNotFound)
+
+		_, err = s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "github",
+			Header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("other", body)}},
+			Body:   []byte(body),
+		})
+		require.ErrorIs(t, err, ingestion.ErrInvalidSignature)
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("should map events with templates and enrich tags", func(t *testing.T) {
+		s, repo := setupTestService(t, generic)
+		body := `[{"build": 42, "pipeline": "api", "branch": "main", "started": 1714564800}, {"build": 43, "pipeline": "web"}]`
+
+		result, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "ci",
+			Header: http.Header{"X-Grafana-Signature": []string{sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Created: 2}, result)
+
+		require.Len(t, repo.saved, 2)
+		assert.Equal(t, "Build 42 of api", repo.saved[0].Text)
+		assert.Equal(t, []string{"ci", "pipeline:api", "branch:main", "source:ci"}, repo.saved[0].Tags)
+		assert.Equal(t, int64(1714564800000), repo.saved[0].Epoch)
+		// tags without value are dropped and events without time happen now
+		assert.Equal(t, []string{"ci", "pipeline:web", "source:ci"}, repo.saved[1].Tags)
+		assert.Equal(t, now.UnixMilli(), repo.saved[1].Epoch)
+	})
+
+	t.Run("should skip events excluded by the filter of the preset", func(t *testing.T) {
+		s, repo := setupTestService(t, github)
+		body := `{"zen": "Keep it logically awesome.", "hook_id": 1}`
+
+		result, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "github",
+			Header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Skipped: 1}, result)
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("should update the annotation of events with the same dedup key", func(t *testing.T) {
+		s, repo := setupTestService(t, github)
+		event := func(state, updatedAt string) string {
+			return `{
+				"deployment_status": {"state": "` + state + `", "updated_at": "` + updatedAt + `"},
+				"deployment": {"id": 1234567890123, "ref": "v1.2.0", "environment": "prod", "created_at": "2024-05-01T10:00:00Z"},
+				"repository": {"full_name": "grafana/app"}
+			}`
+		}
+
+		body := event("in_progress", "2024-05-01T10:00:05Z")
+		result, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "github",
+			Header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Created: 1}, result)
+		require.Len(t, repo.saved, 1)
+		assert.Equal(t, "Deployment of grafana/app v1.2.0 to prod: in_progress", repo.saved[0].Text)
+		assert.Equal(t, int64(0), repo.saved[0].EpochEnd)
+
+		body = event("success", "2024-05-01T10:05:00Z")
+		result, err = s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "github",
+			Header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Updated: 1}, result)
+		require.Len(t, repo.updated, 1)
+		assert.Equal(t, repo.saved[0].ID, repo.updated[0].ID)
+		assert.Equal(t, int64(0), repo.updated[0].Epoch)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC).UnixMilli(), repo.updated[0].EpochEnd)
+		assert.Contains(t, repo.updated[0].Tags, "status:success")
+	})
+
+	t.Run("should update the annotation of a concurrent request that linked the dedup key first", func(t *testing.T) {
+		s, repo, store := setupTestServiceWithStore(t, alertmanager)
+		body := `{
+			"status": "resolved",
+			"alerts": [
+				{"status": "resolved", "labels": {"alertname": "DiskFull"}, "annotations": {}, "startsAt": "2024-05-01T09:00:00Z", "endsAt": "2024-05-01T09:30:00Z", "fingerprint": "b"}
+			]
+		}`
+		store.concurrent[hashKey("b/2024-05-01T09:00:00Z")] = 42
+
+		result, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "am",
+			Header: http.Header{"Authorization": []string{"Bearer token"}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Updated: 1}, result)
+		require.Len(t, repo.updated, 1)
+		assert.Equal(t, int64(42), repo.updated[0].ID)
+	})
+
+	t.Run("should ingest the alerts of an Alertmanager notification in bulk", func(t *testing.T) {
+		s, repo := setupTestService(t, alertmanager)
+		body := `{
+			"status": "firing",
+			"alerts": [
+				{"status": "firing", "labels": {"alertname": "HighLatency", "severity": "critical"}, "annotations": {"summary": "p99 above 1s"}, "startsAt": "2024-05-01T11:00:00Z", "endsAt": "0001-01-01T00:00:00Z", "fingerprint": "a"},
+				{"status": "resolved", "labels": {"alertname": "DiskFull"}, "annotations": {}, "startsAt": "2024-05-01T09:00:00Z", "endsAt": "2024-05-01T09:30:00Z", "fingerprint": "b"}
+			]
+		}`
+
+		result, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "am",
+			Header: http.Header{"Authorization": []string{"Bearer token"}},
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &ingestion.IngestResult{Created: 2}, result)
+
+		require.Len(t, repo.saved, 2)
+		assert.Equal(t, "HighLatency: p99 above 1s", repo.saved[0].Text)
+		assert.Equal(t, []string{"alert", "alertname:HighLatency", "severity:critical", "status:firing", "source:am"}, repo.saved[0].Tags)
+		assert.Equal(t, int64(0), repo.saved[0].EpochEnd)
+		assert.Equal(t, int64(7), repo.saved[0].DashboardID) // nolint: staticcheck
+		assert.Equal(t, "DiskFull", repo.saved[1].Text)
+		assert.Equal(t, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC).UnixMilli(), repo.saved[1].EpochEnd)
+	})
+
+	t.Run("should reject the whole payload when an event is invalid", func(t *testing.T) {
+		s, repo := setupTestService(t, generic)
+		body := `[{"build": 1, "pipeline": "api"}, {"build": 2, "pipeline": "api", "started": "yesterday"}]`
+
+		_, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "ci",
+			Header: http.Header{"X-Grafana-Signature": []string{sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.ErrorIs(t, err, ingestion.ErrInvalidPayload)
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("should reject payloads with too many events", func(t *testing.T) {
+		s, _ := setupTestService(t, generic)
+		body := `[{}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}]`
+
+		_, err := s.Ingest(context.Background(), ingestion.IngestCommand{
+			Source: "ci",
+			Header: http.Header{"X-Grafana-Signature": []string{sign("secret", body)}},
+			Body:   []byte(body),
+		})
+		require.ErrorIs(t, err, ingestion.ErrTooManyEvents)
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"":                          0,
+		"1714564800":                1714564800000,
+		"1714564800123":             1714564800123,
+		"2024-05-01T12:00:00Z":      1714564800000,
+		"2024-05-01 14:00:00 +0200": 1714564800000,
+		"0001-01-01T00:00:00Z":      0,
+	} {
+		actual, err := parseTime(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, actual, value)
+	}
+
+	_, err := parseTime("yesterday")
+	require.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package ingestionimpl
+
+import "github.com/grafana/grafana/pkg/setting"
+
+const (
+	signatureHMAC  = "hmac"
+	signatureToken = "token"
+)
+
+// presets map the webhook payloads of common systems. Every field can be overridden in the configuration of the source.
+var presets = map[string]setting.AnnotationIngestionSource{
+	// GitHub deployment_status events
+	"github": {
+		SignatureType:   signatureHMAC,
+		SignatureHeader: "X-Hub-Signature-256",
+		SignaturePrefix: "sha256=",
+		Filter:          `{{ if .deployment_status }}true{{ end }}`,
+		Text:            `Deployment of {{ .repository.full_name }} {{ .deployment.ref }} to {{ .deployment.environment }}: {{ .deployment_status.state }}`,
+		Tags:            `deploy, repo:{{ .repository.full_name }}, env:{{ .deployment.environment }}, status:{{ .deployment_status.state }}`,
+		Time:            `{{ .deployment.created_at }}`,
+		TimeEnd:         `{{ if not (eq .deployment_status.state "pending" "queued" "in_progress") }}{{ .deployment_status.updated_at }}{{ end }}`,
+		DedupKey:        `{{ .deployment.id }}`,
+	},
+	// GitLab deployment events
+	"gitlab": {
+		SignatureType:   signatureToken,
+		SignatureHeader: "X-Gitlab-Token",
+		Filter:          `{{ if eq .object_kind "deployment" }}true{{ end }}`,
+		Text:            `Deployment of {{ .project.path_with_namespace }} {{ .ref }} to {{ .environment }}: {{ .status }}`,
+		Tags:            `deploy, repo:{{ .project.path_with_namespace }}, env:{{ .environment }}, status:{{ .status }}`,
+		Time:            `{{ .status_changed_at }}`,
+		TimeEnd:         `{{ if not (eq .status "created" "running") }}{{ .status_changed_at }}{{ end }}`,
+		DedupKey:        `{{ .deployment_id }}`,
+	},
+	// Argo CD notifications of sync operations, the webhook body of the notification template must be {{toJson .app}}
+	"argocd": {
+		SignatureType:   signatureToken,
+		SignatureHeader: "Authorization",
+		SignaturePrefix: "Bearer ",
+		Filter:          `{{ if .status.operationState }}true{{ end }}`,
+		Text:            `Sync of {{ .metadata.name }} to {{ .status.sync.revision }}: {{ .status.operationState.phase }}`,
+		Tags:            `deploy, app:{{ .metadata.name }}, namespace:{{ .spec.destination.namespace }}, status:{{ .status.operationState.phase }}`,
+		Time:            `{{ .status.operationState.startedAt }}`,
+		TimeEnd:         `{{ .status.operationState.finishedAt }}`,
+		DedupKey:        `{{ .metadata.uid }}/{{ .status.operationState.startedAt }}`,
+	},
+	// Alertmanager webhook receivers, each alert of a notification is an event
+	"alertmanager": {
+		SignatureType:   signatureToken,
+		SignatureHeader: "Authorization",
+		SignaturePrefix: "Bearer ",
+		Items:           "alerts",
+		Text:            `{{ .labels.alertname }}{{ if .annotations.summary }}: {{ .annotations.summary }}{{ end }}`,
+		Tags:            `alert, alertname:{{ .labels.alertname }}, severity:{{ .labels.severity }}, status:{{ .status }}`,
+		Time:            `{{ .startsAt }}`,
+		TimeEnd:         `{{ if eq .status "resolved" }}{{ .endsAt }}{{ end }}`,
+		DedupKey:        `{{ .fingerprint }}/{{ .startsAt }}`,
+	},
+}
+
+// withPreset fills the empty fields of the source with the fields of the preset.
+func withPreset(source, preset setting.AnnotationIngestionSource) setting.AnnotationIngestionSource {
+	for _, f := range []struct{ value, fallback *string }{
+		{&source.SignatureType, &preset.SignatureType},
+		{&source.SignatureHeader, &preset.SignatureHeader},
+		{&source.SignaturePrefix, &preset.SignaturePrefix},
+		{&source.Items, &preset.Items},
+		{&source.Filter, &preset.Filter},
+		{&source.Text, &preset.Text},
+		{&source.Tags, &preset.Tags},
+		{&source.Time, &preset.Time},
+		{&source.TimeEnd, &preset.TimeEnd},
+		{&source.DedupKey, &preset.DedupKey},
+		{&source.DashboardUID, &preset.DashboardUID},
+		{&source.PanelID, &preset.PanelID},
+	} {
+		if *f.value == "" {
+			*f.value = *f.fallback
+		}
+	}
+	return source
+}
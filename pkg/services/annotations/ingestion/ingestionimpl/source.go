@@ -0,0 +1,297 @@
+package ingestionimpl
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// timeLayouts are the layouts accepted for the times of the events, in addition to Unix timestamps.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02T15:04:05",
+}
+
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	"join": func(sep string, values []any) string {
+		s := make([]string, 0, len(values))
+		for _, v := range values {
+			s = append(s, fmt.Sprint(v))
+		}
+		return strings.Join(s, sep)
+	},
+}
+
+type source struct {
+	setting.AnnotationIngestionSource
+
+	filter       *template.Template
+	text         *template.Template
+	tags         *template.Template
+	time         *template.Template
+	timeEnd      *template.Template
+	dedupKey     *template.Template
+	dashboardUID *template.Template
+	panelID      *template.Template
+}
+
+// event is an annotation mapped from the payload of a webhook.
+type event struct {
+	item     annotations.Item
+	dedupKey string
+}
+
+func newSource(cfg setting.AnnotationIngestionSource) (*source, error) {
+	if cfg.Preset != "" {
+		preset, ok := presets[cfg.Preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %s", cfg.Preset)
+		}
+		cfg = withPreset(cfg, preset)
+	}
+
+	switch cfg.SignatureType {
+	case "", signatureHMAC:
+		cfg.SignatureType = signatureHMAC
+		if cfg.SignatureHeader == "" {
+			cfg.SignatureHeader = "X-Grafana-Signature"
+		}
+	case signatureToken:
+		if cfg.SignatureHeader == "" {
+			cfg.SignatureHeader = "Authorization"
+			cfg.SignaturePrefix = "Bearer "
+		}
+	default:
+		return nil, fmt.Errorf("unknown signature type %s", cfg.SignatureType)
+	}
+
+	if cfg.Text == "" {
+		return nil, errors.New("text template is required")
+	}
+
+	s := &source{AnnotationIngestionSource: cfg}
+	for _, t := range []struct {
+		name string
+		text string
+		tmpl **template.Template
+	}{
+		{"filter", cfg.Filter, &s.filter},
+		{"text", cfg.Text, &s.text},
+		{"tags", cfg.Tags, &s.tags},
+		{"time", cfg.Time, &s.time},
+		{"time_end", cfg.TimeEnd, &s.timeEnd},
+		{"dedup_key", cfg.DedupKey, &s.dedupKey},
+		{"dashboard_uid", cfg.DashboardUID, &s.dashboardUID},
+		{"panel_id", cfg.PanelID, &s.panelID},
+	} {
+		if t.text == "" {
+			continue
+		}
+		tmpl, err := template.New(t.name).Option("missingkey=zero").Funcs(templateFuncs).Parse(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %w", t.name, err)
+		}
+		*t.tmpl = tmpl
+	}
+	return s, nil
+}
+
+// verify checks the signature of the request body or the token sent with the request.
+func (s *source) verify(header http.Header, body []byte) bool {
+	value, ok := strings.CutPrefix(header.Get(s.SignatureHeader), s.SignaturePrefix)
+	if !ok || value == "" {
+		return false
+	}
+
+	if s.SignatureType == signatureToken {
+		return subtle.ConstantTimeCompare([]byte(value), []byte(s.Secret)) == 1
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(strings.ToLower(value)), []byte(expected))
+}
+
+// events returns the events of the payload: the elements of the array at the items path, the elements of the
+// payload if it is an array, or the payload itself.
+func (s *source) events(body []byte) ([]map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keeps large identifiers such as GitHub deployment IDs from being rendered in exponent notation
+	decoder.UseNumber()
+
+	var payload any
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+
+	if s.Items != "" {
+		for _, key := range strings.Split(s.Items, ".") {
+			object, ok := payload.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("no array of events at %s", s.Items)
+			}
+			payload = object[key]
+		}
+		if _, ok := payload.([]any); !ok {
+			return nil, fmt.Errorf("no array of events at %s", s.Items)
+		}
+	}
+
+	items, ok := payload.([]any)
+	if !ok {
+		items = []any{payload}
+	}
+
+	events := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		e, ok := item.(map[string]any)
+		if !ok {
+			return nil, errors.New("events must be JSON objects")
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// render maps an event to an annotation. It returns false if the event is excluded by the filter.
+func (s *source) render(data map[string]any, now time.Time) (*event, bool, error) {
+	if s.filter != nil {
+		// events the filter cannot be evaluated for, e.g. other event types, are excluded
+		if matched, err := execute(s.filter, data); err != nil || matched != "true" {
+			return nil, false, nil
+		}
+	}
+
+	rendered := make(map[string]string)
+	for name, tmpl := range map[string]*template.Template{
+		"text":          s.text,
+		"tags":          s.tags,
+		"time":          s.time,
+		"time_end":      s.timeEnd,
+		"dedup_key":     s.dedupKey,
+		"dashboard_uid": s.dashboardUID,
+		"panel_id":      s.panelID,
+	} {
+		if tmpl == nil {
+			continue
+		}
+		value, err := execute(tmpl, data)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		rendered[name] = value
+	}
+
+	if rendered["text"] == "" {
+		return nil, false, errors.New("text is empty")
+	}
+
+	epoch, err := parseTime(rendered["time"])
+	if err != nil {
+		return nil, false, err
+	}
+	if epoch == 0 {
+		epoch = now.UnixMilli()
+	}
+	epochEnd, err := parseTime(rendered["time_end"])
+	if err != nil {
+		return nil, false, err
+	}
+
+	var panelID int64
+	if rendered["panel_id"] != "" {
+		if panelID, err = strconv.ParseInt(rendered["panel_id"], 10, 64); err != nil {
+			return nil, false, fmt.Errorf("invalid panel ID %s", rendered["panel_id"])
+		}
+	}
+
+	return &event{
+		item: annotations.Item{
+			OrgID:        s.OrgID,
+			DashboardUID: rendered["dashboard_uid"],
+			PanelID:      panelID,
+			Epoch:        epoch,
+			EpochEnd:     epochEnd,
+			Text:         rendered["text"],
+			Tags:         s.enrichTags(rendered["tags"]),
+			Data:         simplejson.NewFromAny(map[string]any{"source": s.Name}),
+		},
+		dedupKey: rendered["dedup_key"],
+	}, true, nil
+}
+
+// enrichTags returns the rendered comma-separated tags along with a tag identifying the source. Tags without
+// a value, e.g. "env:" when the environment is missing from the event, are dropped.
+func (s *source) enrichTags(rendered string) []string {
+	tags := make([]string, 0)
+	for _, t := range strings.Split(rendered, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || strings.HasSuffix(t, ":") || slices.Contains(tags, t) {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	return append(tags, "source:"+s.Name)
+}
+
+func execute(tmpl *template.Template, data map[string]any) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	// missing keys of maps are rendered as <no value> even with missingkey=zero
+	return strings.TrimSpace(strings.ReplaceAll(buf.String(), "<no value>", "")), nil
+}
+
+// parseTime parses a time formatted with one of the supported layouts or a Unix timestamp in seconds or
+// milliseconds. It returns the time in milliseconds, 0 for an empty or zero time.
+func parseTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// timestamps in seconds stay below 10^11 until the year 5138
+		if ts < 100_000_000_000 {
+			return ts * 1000, nil
+		}
+		return ts, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if t.Unix() <= 0 {
+				// e.g. the end time of firing alerts
+				return 0, nil
+			}
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time %s", value)
+}
@@ -0,0 +1,94 @@
+package ingestionimpl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// dedupKey links the dedup key of the events of a source to the annotation created for the first event.
+type dedupKey struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	Source       string    `xorm:"source"`
+	KeyHash      string    `xorm:"key_hash"`
+	AnnotationID int64     `xorm:"annotation_id"`
+	Created      time.Time `xorm:"created"`
+}
+
+func (k *dedupKey) TableName() string {
+	return "annotation_ingestion_key"
+}
+
+// errDuplicateKey is returned when the dedup key was linked to an annotation by a concurrent request.
+var errDuplicateKey = errors.New("dedup key is already linked to an annotation")
+
+type store interface {
+	// InTransaction runs fn in a transaction shared with the annotation repository.
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// GetAnnotationIDs returns the IDs of the existing annotations of the dedup keys by key hash.
+	GetAnnotationIDs(ctx context.Context, orgID int64, source string, hashes []string) (map[string]int64, error)
+	// AddKey links a dedup key to an annotation. It returns errDuplicateKey if the key is linked to an existing annotation.
+	AddKey(ctx context.Context, key *dedupKey) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+// batchSize keeps the queries below the parameter limit of SQLite.
+const batchSize = 500
+
+func (s *xormStore) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.db.InTransaction(ctx, fn)
+}
+
+func (s *xormStore) GetAnnotationIDs(ctx context.Context, orgID int64, source string, hashes []string) (map[string]int64, error) {
+	result := make(map[string]int64, len(hashes))
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(hashes); start += batchSize {
+			batch := hashes[start:min(start+batchSize, len(hashes))]
+			params := []any{orgID, source}
+			for _, h := range batch {
+				params = append(params, h)
+			}
+
+			// keys of annotations removed since, e.g. by the annotation cleanup, are ignored
+			keys := make([]dedupKey, 0, len(batch))
+			err := sess.SQL(`SELECT k.key_hash, k.annotation_id FROM annotation_ingestion_key k
+				INNER JOIN annotation a ON a.id = k.annotation_id AND a.org_id = k.org_id
+				WHERE k.org_id = ? AND k.source = ? AND k.key_hash IN (?`+strings.Repeat(",?", len(batch)-1)+`)`, params...).Find(&keys)
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				result[k.KeyHash] = k.AnnotationID
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *xormStore) AddKey(ctx context.Context, key *dedupKey) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		// a key whose annotation was removed is linked to the new annotation
+		if _, err := sess.Exec(`DELETE FROM annotation_ingestion_key
+			WHERE org_id = ? AND source = ? AND key_hash = ?
+			AND NOT EXISTS (SELECT 1 FROM annotation a WHERE a.id = annotation_ingestion_key.annotation_id)`,
+			key.OrgID, key.Source, key.KeyHash); err != nil {
+			return err
+		}
+
+		if _, err := sess.Insert(key); err != nil {
+			if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+				return errDuplicateKey
+			}
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+// Package auditlog configures the audit logging of the apiserver from the [auditing] settings.
+package auditlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/audit"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DefaultGroups are audited by default: every change to dashboards, folders and datasources
+var DefaultGroups = []string{
+	"dashboard.grafana.app",
+	"folder.grafana.app",
+	"*.datasource.grafana.app",
+}
+
+// ProvideBackend creates the audit backend with the configured sinks, or a no-op backend when auditing is disabled
+func ProvideBackend(cfg *setting.Cfg) (audit.Backend, error) {
+	section := cfg.SectionWithEnvOverrides("auditing")
+	if !section.Key("enabled").MustBool(false) {
+		return auditing.ProvideNoopBackend(), nil
+	}
+
+	logger := log.New("auditing")
+	var loggers []auditing.Logger
+	closeAll := func() {
+		for _, l := range loggers {
+			_ = l.Close()
+		}
+	}
+
+	for _, sink := range util.SplitString(section.Key("sinks").MustString("file")) {
+		l, err := newLogger(cfg, sink, logger)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("audit sink %s: %w", sink, err)
+		}
+		loggers = append(loggers, l)
+	}
+	if len(loggers) == 0 {
+		return nil, fmt.Errorf("auditing is enabled without any sink")
+	}
+
+	return auditing.NewBackend(func(l auditing.Logger, err error) {
+		logger.Error("Failed to write audit log", "sink", l.Type(), "error", err)
+	}, loggers...), nil
+}
+
+// ProvidePolicyRuleProvider creates the audit policy, auditing the configured API groups
+// on top of the ones providing their own policy
+func ProvidePolicyRuleProvider(cfg *setting.Cfg) auditing.PolicyRuleProvider {
+	section := cfg.SectionWithEnvOverrides("auditing")
+	if !section.Key("enabled").MustBool(false) {
+		return auditing.ProvideNoopPolicyRuleProvider()
+	}
+
+	return auditing.NewPolicyRuleProvider(auditing.PolicyConfig{
+		Groups:           util.SplitString(section.Key("groups").MustString(strings.Join(DefaultGroups, ","))),
+		Verbs:            util.SplitString(section.Key("verbs").MustString("")),
+		LogRequestObject: section.Key("log_request_body").MustBool(false),
+	})
+}
+
+func newLogger(cfg *setting.Cfg, sink string, logger log.Logger) (auditing.Logger, error) {
+	section := cfg.SectionWithEnvOverrides("auditing.sinks." + sink)
+
+	switch sink {
+	case "file":
+		return auditing.NewFileLogger(auditing.FileLoggerOptions{
+			Path:       section.Key("path").MustString(filepath.Join(cfg.LogsPath, "audit.log")),
+			MaxSize:    section.Key("max_file_size_mb").MustInt64(100) * 1024 * 1024,
+			MaxBackups: section.Key("max_files").MustInt(5),
+		})
+
+	case "syslog":
+		return auditing.NewSyslogLogger(auditing.SyslogLoggerOptions{
+			Network:  section.Key("network").MustString("udp"),
+			Address:  section.Key("address").MustString("localhost:514"),
+			Facility: section.Key("facility").MustInt(16),
+			AppName:  section.Key("app_name").MustString("grafana"),
+		})
+
+	case "webhook":
+		values, err := util.SplitStringWithError(section.Key("headers").MustString(""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid headers: %w", err)
+		}
+		headers := map[string]string{}
+		for _, h := range values {
+			name, value, ok := strings.Cut(h, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid header %q, expected Name:Value", h)
+			}
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+
+		return auditing.NewWebhookLogger(auditing.WebhookLoggerOptions{
+			URL:           section.Key("url").MustString(""),
+			Headers:       headers,
+			BatchSize:     section.Key("batch_size").MustInt(100),
+			FlushInterval: section.Key("flush_interval").MustDuration(5 * time.Second),
+			BufferSize:    section.Key("buffer_size").MustInt(10000),
+			MaxRetries:    section.Key("max_retries").MustInt(3),
+			OnError: func(err error, entries int) {
+				logger.Error("Dropped audit logs after retries", "sink", sink, "entries", entries, "error", err)
+			},
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown audit sink, expected file, syslog or webhook")
+	}
+}
@@ -0,0 +1,68 @@
+package auditlog
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apiserver/auditing"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newCfg(t *testing.T, values map[string]map[string]string) *setting.Cfg {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.LogsPath = t.TempDir()
+	for section, keys := range values {
+		for k, v := range keys {
+			_, err := cfg.Raw.Section(section).NewKey(k, v)
+			require.NoError(t, err)
+		}
+	}
+	return cfg
+}
+
+func TestProvideBackend(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		backend, err := ProvideBackend(newCfg(t, nil))
+		require.NoError(t, err)
+		require.IsType(t, &auditing.NoopBackend{}, backend)
+		require.IsType(t, &auditing.NoopPolicyRuleProvider{}, ProvidePolicyRuleProvider(newCfg(t, nil)))
+	})
+
+	t.Run("file sink", func(t *testing.T) {
+		cfg := newCfg(t, map[string]map[string]string{
+			"auditing": {"enabled": "true"},
+		})
+		backend, err := ProvideBackend(cfg)
+		require.NoError(t, err)
+		require.Equal(t, "grafana-audit<file>", backend.String())
+		backend.Shutdown()
+		require.FileExists(t, filepath.Join(cfg.LogsPath, "audit.log"))
+	})
+
+	t.Run("webhook sink", func(t *testing.T) {
+		backend, err := ProvideBackend(newCfg(t, map[string]map[string]string{
+			"auditing":               {"enabled": "true", "sinks": "webhook"},
+			"auditing.sinks.webhook": {"url": "http://localhost:3030/audit", "headers": `"Authorization: Bearer abc"`},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "grafana-audit<webhook>", backend.String())
+		backend.Shutdown()
+	})
+
+	t.Run("invalid sinks", func(t *testing.T) {
+		for _, values := range []map[string]map[string]string{
+			{"auditing": {"enabled": "true", "sinks": "kafka"}},
+			{"auditing": {"enabled": "true", "sinks": "webhook"}},
+			{
+				"auditing":               {"enabled": "true", "sinks": "webhook"},
+				"auditing.sinks.webhook": {"url": "http://localhost:3030/audit", "headers": "Authorization"},
+			},
+		} {
+			_, err := ProvideBackend(newCfg(t, values))
+			require.Error(t, err, values)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"path"
 	"strings"
@@ -166,7 +167,7 @@ func ProvideService(
 		auditPolicyRuleProvider:           auditPolicyRuleProvider,
 	}
 	// This will be used when running as a dskit service
-	s.NamedService = services.NewBasicService(s.start, s.running, nil).WithName(modules.GrafanaAPIServer)
+	s.NamedService = services.NewBasicService(s.start, s.running, s.stopping).WithName(modules.GrafanaAPIServer)
 
 	// TODO: this is very hacky
 	// We need to register the routes in ProvideService to make sure
@@ -608,6 +609,14 @@ func (s *service) running(ctx context.Context) error {
 	return nil
 }
 
+// stopping closes the unified storage client, the embedded database of single node deployments is released there
+func (s *service) stopping(_ error) error {
+	if closer, ok := s.unified.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func ensureKubeConfig(restConfig *clientrest.Config, dir string) error {
 	return clientcmd.WriteToFile(
 		utils.FormatKubeConfig(restConfig),
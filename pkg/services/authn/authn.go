@@ -30,6 +30,7 @@ const (
 	ClientProxy        = "auth.client.proxy"
 	ClientSAML         = "auth.client.saml"
 	ClientPasswordless = "auth.client.passwordless"
+	ClientWebAuthn     = "auth.client.webauthn"
 	ClientLDAP         = "ldap"
 	ClientProvisioning = "auth.client.apiserver.provisioning"
 )
@@ -38,6 +39,7 @@ const (
 	MetaKeyUsername            = "username"
 	MetaKeyAuthModule          = "authModule"
 	MetaKeyIsLogin             = "isLogin"
+	MetaKeyIsBasicAuth         = "isBasicAuth"
 	defaultRedirectToCookieKey = "redirect_to"
 )
 
@@ -144,6 +146,9 @@ type Service interface {
 
 	// GetClientConfig returns the client configuration for the given client and a boolean indicating if the config was present.
 	GetClientConfig(client string) (SSOClientConfig, bool)
+
+	// ClientMetadata returns the metadata published by the client, e.g. the SAML service provider metadata.
+	ClientMetadata(ctx context.Context, client string) ([]byte, error)
 }
 
 type IdentitySynchronizer interface {
@@ -184,6 +189,14 @@ type RedirectClient interface {
 	RedirectURL(ctx context.Context, r *Request) (*Redirect, error)
 }
 
+// MetadataClient is an optional interface that auth clients can implement.
+// Clients that implements this interface publish metadata that identity providers
+// use to trust them, e.g. saml clients.
+type MetadataClient interface {
+	Client
+	Metadata(ctx context.Context) ([]byte, error)
+}
+
 // LogoutCLient is an optional interface that auth client can implement.
 // Clients that implements this interface can implement additional logic
 // that should happen during logout and supports client specific redirect URL.
@@ -246,6 +259,8 @@ func (r *Request) GetMeta(k string) string {
 const (
 	KeyOAuthPKCE  = "pkce"
 	KeyOAuthState = "state"
+	// KeySAMLRelayState is the value binding a SAML relay state to the browser which started the login
+	KeySAMLRelayState = "saml_relay_state"
 )
 
 type Redirect struct {
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn/clients"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
 	"github.com/grafana/grafana/pkg/services/notifications"
@@ -25,8 +26,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -44,6 +47,7 @@ func ProvideRegistration(
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, settingsProviderService setting.Provider,
 	tracer tracing.Tracer, tempUserService tempuser.Service, notificationService notifications.Service,
+	webauthnService webauthn.Service, ssoSettings ssosettings.Service, licensing licensing.Licensing,
 ) Registration {
 	logger := log.New("authn.registration")
 
@@ -79,6 +83,10 @@ func ProvideRegistration(
 		}
 	}
 
+	if clients.SAMLEnabled(cfg, licensing) {
+		authnSvc.RegisterClient(clients.ProvideSAML(cfg, ssoSettings, connectors.ProvideOrgRoleMapper(cfg, orgService), cache, tracer))
+	}
+
 	//nolint:staticcheck // not yet migrated to OpenFeature
 	if cfg.PasswordlessMagicLinkAuth.Enabled && features.IsEnabled(context.Background(), featuremgmt.FlagPasswordlessMagicLinkAuthentication) {
 		hasEnabledProviders := authnSvc.IsClientEnabled(authn.ClientSAML) || authnSvc.IsClientEnabled(authn.ClientLDAP)
@@ -100,6 +108,13 @@ func ProvideRegistration(
 		}
 	}
 
+	if cfg.WebAuthn.Enabled {
+		webAuthn := clients.ProvideWebAuthn(cfg, webauthnService, tracer)
+		authnSvc.RegisterClient(webAuthn)
+		// Runs once the identity of password logins is synced, before the session is created
+		authnSvc.RegisterPostAuthHook(webAuthn.MFAHook, 25)
+	}
+
 	if cfg.AuthProxy.Enabled && len(proxyClients) > 0 {
 		proxy, err := clients.ProvideProxy(cfg, cache, tracer, proxyClients...)
 		if err != nil {
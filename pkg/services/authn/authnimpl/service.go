@@ -301,6 +301,25 @@ func (s *Service) RedirectURL(ctx context.Context, client string, r *authn.Reque
 	return redirectClient.RedirectURL(ctx, r)
 }
 
+func (s *Service) ClientMetadata(ctx context.Context, client string) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "authn.ClientMetadata", trace.WithAttributes(
+		attribute.String(attributeKeyClient, client),
+	))
+	defer span.End()
+
+	c, ok := s.clients[client]
+	if !ok {
+		return nil, authn.ErrClientNotConfigured.Errorf("client not configured: %s", client)
+	}
+
+	metadataClient, ok := c.(authn.MetadataClient)
+	if !ok {
+		return nil, authn.ErrUnsupportedClient.Errorf("client does not publish metadata: %s", client)
+	}
+
+	return metadataClient.Metadata(ctx)
+}
+
 func (s *Service) RegisterPreLogoutHook(hook authn.PreLogoutHookFn, priority uint) {
 	s.preLogoutHooks.insert(hook, priority)
 }
@@ -47,6 +47,7 @@ type FakeService struct {
 	ExpectedClientConfig authn.SSOClientConfig
 	ExpectedErr          error
 	ExpectedRedirect     *authn.Redirect
+	ExpectedMetadata     []byte
 	ExpectedIdentity     *authn.Identity
 	ExpectedErrs         []error
 	ExpectedIdentities   []*authn.Identity
@@ -95,6 +96,10 @@ func (f *FakeService) GetClientConfig(name string) (authn.SSOClientConfig, bool)
 	return f.ExpectedClientConfig, true
 }
 
+func (f *FakeService) ClientMetadata(ctx context.Context, client string) ([]byte, error) {
+	return f.ExpectedMetadata, f.ExpectedErr
+}
+
 func (f *FakeService) RegisterPostAuthHook(hook authn.PostAuthHookFn, priority uint) {}
 
 func (f *FakeService) RegisterPreLogoutHook(hook authn.PreLogoutHookFn, priority uint) {}
@@ -35,6 +35,10 @@ func (m *MockService) Login(ctx context.Context, client string, r *authn.Request
 	panic("unimplemented")
 }
 
+func (m *MockService) ClientMetadata(ctx context.Context, client string) ([]byte, error) {
+	panic("unimplemented")
+}
+
 func (m *MockService) RedirectURL(ctx context.Context, client string, r *authn.Request) (*authn.Redirect, error) {
 	panic("unimplemented")
 }
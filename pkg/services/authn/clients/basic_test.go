@@ -47,6 +47,8 @@ func TestBasic_Authenticate(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.EqualValues(t, *tt.expectedIdentity, *identity)
+				// The webauthn MFA hook rejects basic auth of users with a passkey
+				assert.Equal(t, "true", tt.req.GetMeta(authn.MetaKeyIsBasicAuth))
 			}
 		})
 	}
@@ -0,0 +1,728 @@
+package clients
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/mitchellh/mapstructure"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/ssosettings"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	samlACSPath      = "saml/acs"
+	samlMetadataPath = "saml/metadata"
+
+	samlRelayStateParam = "RelayState"
+	// samlRelayStateMACSize keeps the relay state below the 80 bytes allowed by the SAML bindings.
+	samlRelayStateMACSize = 16
+	// samlRelayStateCookieName is the cookie binding the relay state to the browser which started the login
+	samlRelayStateCookieName  = "saml_relay_state"
+	samlRelayStateBindingSize = 32
+
+	// samlReplayCachePrefix prefixes the ids of the assertions and requests already consumed in the remote cache
+	samlReplayCachePrefix = "saml-replay-"
+
+	samlIDPMetadataTimeout = 30 * time.Second
+)
+
+var (
+	errSAMLDisabled           = errutil.BadRequest("auth.saml.disabled", errutil.WithPublicMessage("SAML client is disabled"))
+	errSAMLInvalidRelayState  = errutil.Unauthorized("auth.saml.relay-state.invalid", errutil.WithPublicMessage("Invalid SAML relay state"))
+	errSAMLReplayedResponse   = errutil.Unauthorized("auth.saml.response.replayed", errutil.WithPublicMessage("Invalid SAML response"))
+	errSAMLInvalidResponse    = errutil.Unauthorized("auth.saml.response.invalid", errutil.WithPublicMessage("Invalid SAML response"))
+	errSAMLMissingIdentity    = errutil.Unauthorized("auth.saml.identity.missing", errutil.WithPublicMessage("SAML assertion does not identify the user"))
+	errSAMLOrgNotAllowed      = errutil.Unauthorized("auth.saml.org.not-allowed", errutil.WithPublicMessage("User is not a member of an allowed organization"))
+	errSAMLInternal           = errutil.Internal("auth.saml.internal", errutil.WithPublicMessage("An internal error occurred in the SAML client"))
+	errSAMLInvalidConfigField = errutil.ValidationFailed("auth.saml.config.invalid")
+)
+
+var (
+	_ authn.RedirectClient         = new(SAML)
+	_ authn.SSOSettingsAwareClient = new(SAML)
+	_ authn.MetadataClient         = new(SAML)
+	_ ssosettings.Reloadable       = new(SAML)
+)
+
+// SAMLEnabled reports whether the SAML client is used, when SAML is enabled in the config file or its settings are
+// managed through the SSO settings API. A license with the saml feature provides the enterprise SAML integration instead,
+// which registers a client of the same name and serves its own endpoints.
+func SAMLEnabled(cfg *setting.Cfg, license licensing.Licensing) bool {
+	if license.FeatureEnabled(social.SAMLProviderName) {
+		return false
+	}
+	return cfg.SAMLAuthEnabled || cfg.SSOSettingsConfigurableProviders[social.SAMLProviderName]
+}
+
+func ProvideSAML(cfg *setting.Cfg, ssoSettings ssosettings.Service, orgRoleMapper *connectors.OrgRoleMapper, cache remotecache.CacheStorage, tracer tracing.Tracer) *SAML {
+	c := &SAML{
+		cfg:           cfg,
+		orgRoleMapper: orgRoleMapper,
+		cache:         cache,
+		tracer:        tracer,
+		log:           log.New("authn.saml"),
+		settings:      &samlSettings{Name: "SAML"},
+	}
+
+	ssoSettings.RegisterReloadable(social.SAMLProviderName, c)
+
+	settings, err := ssoSettings.GetForProvider(context.Background(), social.SAMLProviderName)
+	if err != nil {
+		c.log.Error("Failed to retrieve SAML settings from SSO settings service", "error", err)
+		return c
+	}
+
+	if err := c.Reload(context.Background(), *settings); err != nil {
+		c.log.Error("Failed to load SAML settings", "error", err)
+	}
+
+	return c
+}
+
+// SAML is a SAML 2.0 service provider. Logins are started with the HTTP-Redirect binding
+// and the identity provider answers on the assertion consumer service with the HTTP-POST binding.
+type SAML struct {
+	cfg           *setting.Cfg
+	orgRoleMapper *connectors.OrgRoleMapper
+	cache         remotecache.CacheStorage
+	tracer        tracing.Tracer
+	log           log.Logger
+
+	// replayMu serializes the checks of the replay cache in this instance
+	replayMu sync.Mutex
+
+	mu       sync.RWMutex
+	settings *samlSettings
+	sp       *saml.ServiceProvider
+}
+
+func (c *SAML) Name() string {
+	return authn.ClientSAML
+}
+
+func (c *SAML) IsEnabled() bool {
+	settings, sp := c.current()
+	return settings.Enabled && sp != nil
+}
+
+func (c *SAML) GetConfig() authn.SSOClientConfig {
+	settings, _ := c.current()
+	return settings
+}
+
+func (c *SAML) RedirectURL(ctx context.Context, r *authn.Request) (*authn.Redirect, error) {
+	_, span := c.tracer.Start(ctx, "authn.saml.RedirectURL")
+	defer span.End()
+
+	settings, sp := c.current()
+	if !settings.Enabled || sp == nil {
+		return nil, errSAMLDisabled.Errorf("saml client is disabled")
+	}
+
+	req, err := sp.MakeAuthenticationRequest(sp.GetSSOBindingLocation(saml.HTTPRedirectBinding), saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return nil, errSAMLInternal.Errorf("failed to create authentication request: %w", err)
+	}
+
+	// The relay state carries the request id back to us, so that only responses to our own requests are accepted.
+	// It is bound to a random value stored in a cookie, so a response can only be used by the browser which started the login.
+	binding, err := util.GetRandomString(samlRelayStateBindingSize)
+	if err != nil {
+		return nil, errSAMLInternal.Errorf("failed to generate relay state binding: %w", err)
+	}
+	redirectURL, err := req.Redirect(c.signRelayState(req.ID, binding), sp)
+	if err != nil {
+		return nil, errSAMLInternal.Errorf("failed to create redirect url: %w", err)
+	}
+
+	return &authn.Redirect{
+		URL:   redirectURL.String(),
+		Extra: map[string]string{authn.KeySAMLRelayState: binding},
+	}, nil
+}
+
+func (c *SAML) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	ctx, span := c.tracer.Start(ctx, "authn.saml.Authenticate")
+	defer span.End()
+
+	r.SetMeta(authn.MetaKeyAuthModule, login.SAMLAuthModule)
+
+	settings, sp := c.current()
+	if !settings.Enabled || sp == nil {
+		return nil, errSAMLDisabled.Errorf("saml client is disabled")
+	}
+
+	if err := r.HTTPRequest.ParseForm(); err != nil {
+		return nil, errSAMLInvalidResponse.Errorf("failed to parse form: %w", err)
+	}
+
+	var binding string
+	if cookie, err := r.HTTPRequest.Cookie(samlRelayStateCookieName); err == nil {
+		binding = cookie.Value
+	}
+
+	var possibleRequestIDs []string
+	if relayState := r.HTTPRequest.PostForm.Get(samlRelayStateParam); relayState != "" {
+		requestID, ok := c.verifyRelayState(relayState, binding)
+		if ok {
+			possibleRequestIDs = append(possibleRequestIDs, requestID)
+		} else if !settings.AllowIDPInitiated {
+			return nil, errSAMLInvalidRelayState.Errorf("relay state was not issued by this service provider to this browser")
+		}
+	}
+	if len(possibleRequestIDs) == 0 && !settings.AllowIDPInitiated {
+		return nil, errSAMLInvalidRelayState.Errorf("missing relay state")
+	}
+
+	assertion, err := sp.ParseResponse(r.HTTPRequest, possibleRequestIDs)
+	if err != nil {
+		var invalidResponse *saml.InvalidResponseError
+		if errors.As(err, &invalidResponse) {
+			return nil, errSAMLInvalidResponse.Errorf("failed to verify response: %w", invalidResponse.PrivateErr)
+		}
+		return nil, errSAMLInvalidResponse.Errorf("failed to verify response: %w", err)
+	}
+
+	// The library only knows a global issue delay, the delay of this provider is checked here
+	if assertion.IssueInstant.Add(settings.MaxIssueDelay).Before(time.Now()) {
+		return nil, errSAMLInvalidResponse.Errorf("assertion expired on %s", assertion.IssueInstant.Add(settings.MaxIssueDelay))
+	}
+
+	if err := c.consume(ctx, append(possibleRequestIDs, assertion.ID)); err != nil {
+		return nil, err
+	}
+
+	return c.identityFromAssertion(ctx, settings, assertion)
+}
+
+func (c *SAML) Metadata(ctx context.Context) ([]byte, error) {
+	_, span := c.tracer.Start(ctx, "authn.saml.Metadata")
+	defer span.End()
+
+	settings, sp := c.current()
+	if !settings.Enabled || sp == nil {
+		return nil, errSAMLDisabled.Errorf("saml client is disabled")
+	}
+
+	metadata, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, errSAMLInternal.Errorf("failed to marshal metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+func (c *SAML) Reload(ctx context.Context, settings models.SSOSettings) error {
+	parsed, err := parseSAMLSettings(settings.Settings)
+	if err != nil {
+		return err
+	}
+
+	var sp *saml.ServiceProvider
+	if parsed.Enabled {
+		sp, err = c.buildServiceProvider(ctx, parsed)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.settings = parsed
+	c.sp = sp
+
+	return nil
+}
+
+func (c *SAML) Validate(ctx context.Context, settings models.SSOSettings, _ models.SSOSettings, _ identity.Requester) error {
+	parsed, err := parseSAMLSettings(settings.Settings)
+	if err != nil {
+		return errSAMLInvalidConfigField.Errorf("invalid SAML settings: %w", err)
+	}
+
+	if !parsed.Enabled {
+		return nil
+	}
+
+	// The responses older than the global delay of the saml library are always rejected
+	if parsed.MaxIssueDelay <= 0 || parsed.MaxIssueDelay > saml.MaxIssueDelay {
+		return errSAMLInvalidConfigField.Errorf("max_issue_delay must be between 0 and %s", saml.MaxIssueDelay)
+	}
+
+	if _, err := c.buildServiceProvider(ctx, parsed); err != nil {
+		return errSAMLInvalidConfigField.Errorf("%w", err)
+	}
+
+	for _, mapping := range parsed.OrgMapping {
+		if len(strings.Split(mapping, ":")) < 2 {
+			return errSAMLInvalidConfigField.Errorf("invalid org mapping %q, expected <external org>:<org id or name>[:<role>]", mapping)
+		}
+	}
+
+	return nil
+}
+
+func (c *SAML) current() (*samlSettings, *saml.ServiceProvider) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.settings, c.sp
+}
+
+func (c *SAML) identityFromAssertion(ctx context.Context, settings *samlSettings, assertion *saml.Assertion) (*authn.Identity, error) {
+	attributes := samlAttributes(assertion)
+	first := func(name string) string {
+		if values := attributes[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var nameID string
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		nameID = assertion.Subject.NameID.Value
+	}
+
+	email := first(settings.AssertionAttributeEmail)
+	if email == "" && strings.Contains(nameID, "@") {
+		email = nameID
+	}
+	userLogin := first(settings.AssertionAttributeLogin)
+	if userLogin == "" {
+		userLogin = email
+	}
+	if userLogin == "" {
+		userLogin = nameID
+	}
+	authID := first(settings.AssertionAttributeExternalUID)
+	if authID == "" {
+		authID = nameID
+	}
+	if userLogin == "" || authID == "" {
+		return nil, errSAMLMissingIdentity.Errorf("assertion has neither a login nor a name id")
+	}
+
+	orgs := attributes[settings.AssertionAttributeOrg]
+	if len(settings.AllowedOrganizations) > 0 && !slices.ContainsFunc(orgs, func(o string) bool {
+		return slices.Contains(settings.AllowedOrganizations, o)
+	}) {
+		return nil, errSAMLOrgNotAllowed.Errorf("user %s is not a member of an allowed organization", userLogin)
+	}
+
+	id := &authn.Identity{
+		Login:           userLogin,
+		Name:            first(settings.AssertionAttributeName),
+		Email:           email,
+		AuthenticatedBy: login.SAMLAuthModule,
+		AuthID:          authID,
+		Groups:          attributes[settings.AssertionAttributeGroups],
+		SAMLSession:     &login.SAMLSession{NameID: nameID},
+		ClientParams: authn.ClientParams{
+			SyncUser:        true,
+			SyncTeams:       true,
+			FetchSyncedUser: true,
+			SyncPermissions: true,
+			AllowSignUp:     settings.AllowSignUp,
+			SyncOrgRoles:    !settings.SkipOrgRoleSync,
+			LookUpParams:    login.UserLookupParams{Email: &email, Login: &userLogin},
+		},
+	}
+	if len(assertion.AuthnStatements) > 0 {
+		id.SAMLSession.SessionIndex = assertion.AuthnStatements[0].SessionIndex
+	}
+	if email == "" {
+		id.ClientParams.LookUpParams.Email = nil
+	}
+
+	if !settings.SkipOrgRoleSync {
+		role, isGrafanaAdmin := settings.mapRole(attributes[settings.AssertionAttributeRole])
+		mappingCfg := c.orgRoleMapper.ParseOrgMappingSettings(ctx, settings.OrgMapping, false)
+		id.OrgRoles = c.orgRoleMapper.MapOrgRoles(mappingCfg, orgs, role)
+		if settings.IsAllowAssignGrafanaAdminEnabled() {
+			id.IsGrafanaAdmin = &isGrafanaAdmin
+		}
+	}
+
+	return id, nil
+}
+
+// samlAttributes indexes the attribute values of an assertion by attribute name and friendly name.
+func samlAttributes(assertion *saml.Assertion) map[string][]string {
+	result := map[string][]string{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, v := range attr.Values {
+				values = append(values, v.Value)
+			}
+			for _, name := range []string{attr.Name, attr.FriendlyName} {
+				if name != "" {
+					result[name] = append(result[name], values...)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// signRelayState authenticates the request id and the binding stored in the cookie of the browser.
+// The binding is not part of the relay state, which is sent to the identity provider.
+func (c *SAML) signRelayState(requestID, binding string) string {
+	mac := hmac.New(sha256.New, []byte(c.cfg.SecretKey))
+	_, _ = mac.Write([]byte(requestID + "." + binding))
+	return requestID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:samlRelayStateMACSize])
+}
+
+func (c *SAML) verifyRelayState(relayState, binding string) (string, bool) {
+	i := strings.LastIndexByte(relayState, '.')
+	if i <= 0 || binding == "" {
+		return "", false
+	}
+	requestID := relayState[:i]
+	return requestID, hmac.Equal([]byte(c.signRelayState(requestID, binding)), []byte(relayState))
+}
+
+// consume marks the ids of a response as used, so the same response cannot be used to log in twice.
+// They are kept as long as the response could be accepted.
+func (c *SAML) consume(ctx context.Context, ids []string) error {
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	for _, id := range ids {
+		_, err := c.cache.Get(ctx, samlReplayCachePrefix+id)
+		if err == nil {
+			return errSAMLReplayedResponse.Errorf("response %s was already used", id)
+		}
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return errSAMLInternal.Errorf("failed to read the replay cache: %w", err)
+		}
+	}
+
+	expire := saml.MaxIssueDelay + saml.MaxClockSkew
+	for _, id := range ids {
+		if err := c.cache.Set(ctx, samlReplayCachePrefix+id, []byte{1}, expire); err != nil {
+			return errSAMLInternal.Errorf("failed to write the replay cache: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *SAML) buildServiceProvider(ctx context.Context, settings *samlSettings) (*saml.ServiceProvider, error) {
+	key, err := loadSAMLPrivateKey(settings.PrivateKey, settings.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := loadSAMLCertificate(settings.Certificate, settings.CertificatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	idpMetadata, err := loadSAMLIDPMetadata(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	signatureMethod, err := samlSignatureMethod(settings.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	appURL, err := url.Parse(c.cfg.AppURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root url: %w", err)
+	}
+	acsURL := appURL.JoinPath(samlACSPath)
+	metadataURL := appURL.JoinPath(samlMetadataPath)
+
+	entityID := settings.EntityID
+	if entityID == "" {
+		entityID = metadataURL.String()
+	}
+
+	return &saml.ServiceProvider{
+		EntityID:              entityID,
+		Key:                   key,
+		Certificate:           cert,
+		MetadataURL:           *metadataURL,
+		AcsURL:                *acsURL,
+		IDPMetadata:           idpMetadata,
+		AuthnNameIDFormat:     saml.NameIDFormat(settings.NameIDFormat),
+		MetadataValidDuration: settings.MetadataValidDuration,
+		AllowIDPInitiated:     settings.AllowIDPInitiated,
+		SignatureMethod:       signatureMethod,
+	}, nil
+}
+
+// samlSettings are the settings of the SAML client, as stored by the SSO settings service.
+type samlSettings struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	Name                  string        `mapstructure:"name"`
+	AutoLogin             bool          `mapstructure:"auto_login"`
+	AllowSignUp           bool          `mapstructure:"allow_sign_up"`
+	AllowIDPInitiated     bool          `mapstructure:"allow_idp_initiated"`
+	EntityID              string        `mapstructure:"entity_id"`
+	Certificate           string        `mapstructure:"certificate"`
+	CertificatePath       string        `mapstructure:"certificate_path"`
+	PrivateKey            string        `mapstructure:"private_key"`
+	PrivateKeyPath        string        `mapstructure:"private_key_path"`
+	SignatureAlgorithm    string        `mapstructure:"signature_algorithm"`
+	IDPMetadata           string        `mapstructure:"idp_metadata"`
+	IDPMetadataPath       string        `mapstructure:"idp_metadata_path"`
+	IDPMetadataURL        string        `mapstructure:"idp_metadata_url"`
+	NameIDFormat          string        `mapstructure:"name_id_format"`
+	MaxIssueDelay         time.Duration `mapstructure:"max_issue_delay"`
+	MetadataValidDuration time.Duration `mapstructure:"metadata_valid_duration"`
+
+	AssertionAttributeLogin       string `mapstructure:"assertion_attribute_login"`
+	AssertionAttributeEmail       string `mapstructure:"assertion_attribute_email"`
+	AssertionAttributeName        string `mapstructure:"assertion_attribute_name"`
+	AssertionAttributeExternalUID string `mapstructure:"assertion_attribute_external_uid"`
+	AssertionAttributeGroups      string `mapstructure:"assertion_attribute_groups"`
+	AssertionAttributeRole        string `mapstructure:"assertion_attribute_role"`
+	AssertionAttributeOrg         string `mapstructure:"assertion_attribute_org"`
+
+	RoleValuesNone         []string `mapstructure:"role_values_none"`
+	RoleValuesViewer       []string `mapstructure:"role_values_viewer"`
+	RoleValuesEditor       []string `mapstructure:"role_values_editor"`
+	RoleValuesAdmin        []string `mapstructure:"role_values_admin"`
+	RoleValuesGrafanaAdmin []string `mapstructure:"role_values_grafana_admin"`
+	AllowedOrganizations   []string `mapstructure:"allowed_organizations"`
+	OrgMapping             []string `mapstructure:"org_mapping"`
+	SkipOrgRoleSync        bool     `mapstructure:"skip_org_role_sync"`
+}
+
+func parseSAMLSettings(kv map[string]any) (*samlSettings, error) {
+	splitStringHook := func(from, to reflect.Type, data any) (any, error) {
+		if from.Kind() == reflect.String && to.Kind() == reflect.Slice {
+			return util.SplitString(reflect.ValueOf(data).String()), nil
+		}
+		return data, nil
+	}
+
+	settings := &samlSettings{Name: "SAML", MaxIssueDelay: 90 * time.Second, MetadataValidDuration: 48 * time.Hour}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(splitStringHook, mapstructure.StringToTimeDurationHookFunc()),
+		Result:           settings,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(kv); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *samlSettings) GetDisplayName() string {
+	return s.Name
+}
+
+func (s *samlSettings) IsAutoLoginEnabled() bool {
+	return s.AutoLogin
+}
+
+// IsSingleLogoutEnabled is always false, single logout is not supported by this client.
+func (s *samlSettings) IsSingleLogoutEnabled() bool {
+	return false
+}
+
+func (s *samlSettings) IsSkipOrgRoleSyncEnabled() bool {
+	return s.SkipOrgRoleSync
+}
+
+func (s *samlSettings) IsAllowAssignGrafanaAdminEnabled() bool {
+	return len(s.RoleValuesGrafanaAdmin) > 0
+}
+
+// mapRole returns the highest role matched by the values of the role attribute,
+// and whether the user should be a Grafana server admin.
+func (s *samlSettings) mapRole(values []string) (org.RoleType, bool) {
+	matches := func(roleValues []string) bool {
+		return slices.ContainsFunc(values, func(v string) bool { return slices.Contains(roleValues, v) })
+	}
+
+	isGrafanaAdmin := matches(s.RoleValuesGrafanaAdmin)
+	switch {
+	case isGrafanaAdmin || matches(s.RoleValuesAdmin):
+		return org.RoleAdmin, isGrafanaAdmin
+	case matches(s.RoleValuesEditor):
+		return org.RoleEditor, false
+	case matches(s.RoleValuesViewer):
+		return org.RoleViewer, false
+	case matches(s.RoleValuesNone):
+		return org.RoleNone, false
+	default:
+		return "", false
+	}
+}
+
+// readSAMLValue returns the PEM or XML content of a setting, which is either inline, optionally base64 encoded, or read from a file.
+func readSAMLValue(value, path string) ([]byte, error) {
+	if value != "" {
+		trimmed := strings.TrimSpace(value)
+		if strings.HasPrefix(trimmed, "-----BEGIN") || strings.HasPrefix(trimmed, "<") {
+			return []byte(trimmed), nil
+		}
+		return base64.StdEncoding.DecodeString(trimmed)
+	}
+	if path != "" {
+		// We can ignore the gosec G304 warning on this one because `path` comes
+		// from the SSO settings, which only admins can change.
+		// nolint:gosec
+		return os.ReadFile(path)
+	}
+	return nil, nil
+}
+
+func loadSAMLPrivateKey(value, path string) (*rsa.PrivateKey, error) {
+	data, err := readSAMLValue(value, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is required and must be PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key must be an RSA key")
+	}
+	return key, nil
+}
+
+func loadSAMLCertificate(value, path string) (*x509.Certificate, error) {
+	data, err := readSAMLValue(value, path)
+	if err != nil {
+		return nil, fmt.Errorf("reading certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("certificate is required and must be PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+	return cert, nil
+}
+
+func loadSAMLIDPMetadata(ctx context.Context, settings *samlSettings) (*saml.EntityDescriptor, error) {
+	data, err := readSAMLValue(settings.IDPMetadata, settings.IDPMetadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading identity provider metadata: %w", err)
+	}
+
+	if data == nil && settings.IDPMetadataURL != "" {
+		data, err = fetchSAMLIDPMetadata(ctx, settings.IDPMetadataURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if data == nil {
+		return nil, errors.New("identity provider metadata is required")
+	}
+
+	return parseSAMLIDPMetadata(data)
+}
+
+func fetchSAMLIDPMetadata(ctx context.Context, metadataURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, samlIDPMetadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity provider metadata url: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching identity provider metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching identity provider metadata: unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseSAMLIDPMetadata accepts either the entity descriptor of the identity provider,
+// or an entities descriptor that contains it.
+func parseSAMLIDPMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	entity := &saml.EntityDescriptor{}
+	if err := xml.Unmarshal(data, entity); err == nil {
+		if len(entity.IDPSSODescriptors) == 0 {
+			return nil, errors.New("identity provider metadata has no IDPSSODescriptor")
+		}
+		return entity, nil
+	}
+
+	entities := &saml.EntitiesDescriptor{}
+	if err := xml.Unmarshal(data, entities); err != nil {
+		return nil, fmt.Errorf("parsing identity provider metadata: %w", err)
+	}
+	for i := range entities.EntityDescriptors {
+		if len(entities.EntityDescriptors[i].IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("identity provider metadata has no IDPSSODescriptor")
+}
+
+func samlSignatureMethod(algorithm string) (string, error) {
+	switch algorithm {
+	case "":
+		return "", nil
+	case "rsa-sha1":
+		return dsig.RSASHA1SignatureMethod, nil
+	case "rsa-sha256":
+		return dsig.RSASHA256SignatureMethod, nil
+	case "rsa-sha512":
+		return dsig.RSASHA512SignatureMethod, nil
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm %q, expected one of rsa-sha1, rsa-sha256, rsa-sha512", algorithm)
+	}
+}
@@ -0,0 +1,304 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/login/social/connectors"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/ssosettings/models"
+	"github.com/grafana/grafana/pkg/services/ssosettings/ssosettingstests"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestParseSAMLSettings(t *testing.T) {
+	settings, err := parseSAMLSettings(map[string]any{
+		"enabled":                   "true",
+		"name":                      "Okta",
+		"max_issue_delay":           "2m",
+		"role_values_editor":        "editor, writer",
+		"role_values_grafana_admin": []any{"root"},
+		"allowed_organizations":     "Eng Ops",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, "Okta", settings.GetDisplayName())
+	assert.Equal(t, 2*time.Minute, settings.MaxIssueDelay)
+	assert.Equal(t, 48*time.Hour, settings.MetadataValidDuration)
+	assert.Equal(t, []string{"editor", "writer"}, settings.RoleValuesEditor)
+	assert.Equal(t, []string{"Eng", "Ops"}, settings.AllowedOrganizations)
+	assert.True(t, settings.IsAllowAssignGrafanaAdminEnabled())
+}
+
+func TestSAMLSettings_MapRole(t *testing.T) {
+	settings := &samlSettings{
+		RoleValuesNone:         []string{"guest"},
+		RoleValuesViewer:       []string{"viewer"},
+		RoleValuesEditor:       []string{"editor"},
+		RoleValuesAdmin:        []string{"admin"},
+		RoleValuesGrafanaAdmin: []string{"root"},
+	}
+
+	tests := []struct {
+		values         []string
+		role           org.RoleType
+		isGrafanaAdmin bool
+	}{
+		{values: nil, role: ""},
+		{values: []string{"guest"}, role: org.RoleNone},
+		{values: []string{"viewer", "guest"}, role: org.RoleViewer},
+		{values: []string{"viewer", "editor"}, role: org.RoleEditor},
+		{values: []string{"admin"}, role: org.RoleAdmin},
+		{values: []string{"root"}, role: org.RoleAdmin, isGrafanaAdmin: true},
+	}
+
+	for _, tt := range tests {
+		role, isGrafanaAdmin := settings.mapRole(tt.values)
+		assert.Equal(t, tt.role, role, tt.values)
+		assert.Equal(t, tt.isGrafanaAdmin, isGrafanaAdmin, tt.values)
+	}
+}
+
+func TestSAML_RelayState(t *testing.T) {
+	c := setupSAMLClient(t)
+
+	relayState := c.signRelayState("id-123", "binding")
+	requestID, ok := c.verifyRelayState(relayState, "binding")
+	assert.True(t, ok)
+	assert.Equal(t, "id-123", requestID)
+
+	_, ok = c.verifyRelayState("id-456"+relayState[len("id-123"):], "binding")
+	assert.False(t, ok)
+	_, ok = c.verifyRelayState("id-123", "binding")
+	assert.False(t, ok)
+
+	// the relay state can only be used by the browser with the binding cookie
+	_, ok = c.verifyRelayState(relayState, "")
+	assert.False(t, ok)
+	_, ok = c.verifyRelayState(relayState, "another binding")
+	assert.False(t, ok)
+
+	other := setupSAMLClient(t)
+	other.cfg.SecretKey = "another secret"
+	_, ok = other.verifyRelayState(relayState, "binding")
+	assert.False(t, ok)
+}
+
+func TestSAML_Consume(t *testing.T) {
+	c := setupSAMLClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.consume(ctx, []string{"request-1", "assertion-1"}))
+	assert.ErrorIs(t, c.consume(ctx, []string{"assertion-1"}), errSAMLReplayedResponse)
+	assert.ErrorIs(t, c.consume(ctx, []string{"request-1", "assertion-2"}), errSAMLReplayedResponse)
+	require.NoError(t, c.consume(ctx, []string{"assertion-2"}))
+}
+
+func TestSAML_ReloadKeepsTheIssueDelayOfTheProvider(t *testing.T) {
+	c := setupSAMLClient(t)
+	global := saml.MaxIssueDelay
+
+	err := c.Reload(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": false, "max_issue_delay": "30s"}})
+	require.NoError(t, err)
+
+	settings, _ := c.current()
+	assert.Equal(t, 30*time.Second, settings.MaxIssueDelay)
+	assert.Equal(t, global, saml.MaxIssueDelay)
+}
+
+func TestSAML_IdentityFromAssertion(t *testing.T) {
+	type testCase struct {
+		desc        string
+		settings    *samlSettings
+		assertion   *saml.Assertion
+		expectedErr error
+		check       func(t *testing.T, id *authn.Identity)
+	}
+
+	tests := []testCase{
+		{
+			desc: "should map attributes and roles",
+			settings: &samlSettings{
+				AllowSignUp:                   true,
+				AssertionAttributeLogin:       "login",
+				AssertionAttributeEmail:       "mail",
+				AssertionAttributeName:        "displayName",
+				AssertionAttributeGroups:      "groups",
+				AssertionAttributeRole:        "role",
+				AssertionAttributeOrg:         "org",
+				AssertionAttributeExternalUID: "uid",
+				RoleValuesEditor:              []string{"editor"},
+				RoleValuesGrafanaAdmin:        []string{"root"},
+				OrgMapping:                    []string{"Eng:2:Admin"},
+			},
+			assertion: samlAssertion("transient-id", map[string][]string{
+				"login":       {"jdoe"},
+				"mail":        {"jdoe@example.org"},
+				"displayName": {"John Doe"},
+				"groups":      {"devs", "ops"},
+				"role":        {"editor"},
+				"org":         {"Eng"},
+				"uid":         {"u-1"},
+			}),
+			check: func(t *testing.T, id *authn.Identity) {
+				assert.Equal(t, "jdoe", id.Login)
+				assert.Equal(t, "jdoe@example.org", id.Email)
+				assert.Equal(t, "John Doe", id.Name)
+				assert.Equal(t, "u-1", id.AuthID)
+				assert.Equal(t, login.SAMLAuthModule, id.AuthenticatedBy)
+				assert.Equal(t, []string{"devs", "ops"}, id.Groups)
+				assert.Equal(t, "transient-id", id.SAMLSession.NameID)
+				assert.Equal(t, "session-1", id.SAMLSession.SessionIndex)
+				assert.Equal(t, map[int64]org.RoleType{2: org.RoleAdmin}, id.OrgRoles)
+				require.NotNil(t, id.IsGrafanaAdmin)
+				assert.False(t, *id.IsGrafanaAdmin)
+				assert.True(t, id.ClientParams.AllowSignUp)
+				assert.True(t, id.ClientParams.SyncOrgRoles)
+			},
+		},
+		{
+			desc:      "should fall back to the name id",
+			settings:  &samlSettings{RoleValuesViewer: []string{"viewer"}, AssertionAttributeRole: "role"},
+			assertion: samlAssertion("jdoe@example.org", map[string][]string{"role": {"viewer"}}),
+			check: func(t *testing.T, id *authn.Identity) {
+				assert.Equal(t, "jdoe@example.org", id.Login)
+				assert.Equal(t, "jdoe@example.org", id.Email)
+				assert.Equal(t, "jdoe@example.org", id.AuthID)
+				assert.Equal(t, map[int64]org.RoleType{1: org.RoleViewer}, id.OrgRoles)
+				assert.Nil(t, id.IsGrafanaAdmin)
+			},
+		},
+		{
+			desc:      "should not sync roles when org role sync is skipped",
+			settings:  &samlSettings{SkipOrgRoleSync: true, AssertionAttributeRole: "role", RoleValuesAdmin: []string{"admin"}},
+			assertion: samlAssertion("jdoe", map[string][]string{"role": {"admin"}}),
+			check: func(t *testing.T, id *authn.Identity) {
+				assert.Nil(t, id.OrgRoles)
+				assert.False(t, id.ClientParams.SyncOrgRoles)
+				assert.Nil(t, id.ClientParams.LookUpParams.Email)
+			},
+		},
+		{
+			desc:        "should fail without any identifier",
+			settings:    &samlSettings{},
+			assertion:   samlAssertion("", nil),
+			expectedErr: errSAMLMissingIdentity,
+		},
+		{
+			desc:        "should fail if the user is not a member of an allowed organization",
+			settings:    &samlSettings{AssertionAttributeOrg: "org", AllowedOrganizations: []string{"Eng"}},
+			assertion:   samlAssertion("jdoe", map[string][]string{"org": {"Sales"}}),
+			expectedErr: errSAMLOrgNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := setupSAMLClient(t)
+
+			id, err := c.identityFromAssertion(context.Background(), tt.settings, tt.assertion)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, id)
+				return
+			}
+
+			require.NoError(t, err)
+			tt.check(t, id)
+		})
+	}
+}
+
+func TestSAML_Validate(t *testing.T) {
+	c := setupSAMLClient(t)
+
+	err := c.Validate(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": false}}, models.SSOSettings{}, nil)
+	require.NoError(t, err)
+
+	err = c.Validate(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": true}}, models.SSOSettings{}, nil)
+	assert.ErrorIs(t, err, errSAMLInvalidConfigField)
+
+	err = c.Validate(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": true, "max_issue_delay": "soon"}}, models.SSOSettings{}, nil)
+	assert.ErrorIs(t, err, errSAMLInvalidConfigField)
+
+	err = c.Validate(context.Background(), models.SSOSettings{Settings: map[string]any{"enabled": true, "max_issue_delay": "10m"}}, models.SSOSettings{}, nil)
+	assert.ErrorIs(t, err, errSAMLInvalidConfigField)
+}
+
+func TestSAMLEnabled(t *testing.T) {
+	tests := []struct {
+		desc         string
+		enabled      bool
+		configurable bool
+		licensed     bool
+		expected     bool
+	}{
+		{desc: "should be disabled by default"},
+		{desc: "should be enabled in the config file", enabled: true, expected: true},
+		{desc: "should be enabled when configurable through the SSO settings API", configurable: true, expected: true},
+		{desc: "should leave SAML to the licensed integration", enabled: true, configurable: true, licensed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.SAMLAuthEnabled = tt.enabled
+			cfg.SSOSettingsConfigurableProviders = map[string]bool{"saml": tt.configurable}
+			license := licensingtest.NewFakeLicensing()
+			license.On("FeatureEnabled", "saml").Return(tt.licensed)
+
+			assert.Equal(t, tt.expected, SAMLEnabled(cfg, license))
+		})
+	}
+}
+
+func TestSAML_Disabled(t *testing.T) {
+	c := setupSAMLClient(t)
+
+	assert.False(t, c.IsEnabled())
+	_, err := c.Metadata(context.Background())
+	assert.ErrorIs(t, err, errSAMLDisabled)
+}
+
+func setupSAMLClient(t *testing.T) *SAML {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.SecretKey = "secret"
+	ssoSettings := ssosettingstests.NewFakeService()
+	ssoSettings.ExpectedSSOSetting = &models.SSOSettings{Provider: "saml", Settings: map[string]any{"enabled": false}}
+
+	orgRoleMapper := connectors.ProvideOrgRoleMapper(cfg, &orgtest.FakeOrgService{ExpectedOrgs: []*org.OrgDTO{{ID: 2, Name: "Org2"}}})
+	return ProvideSAML(cfg, ssoSettings, orgRoleMapper, remotecache.NewFakeCacheStorage(), tracing.InitializeTracerForTest())
+}
+
+func samlAssertion(nameID string, attributes map[string][]string) *saml.Assertion {
+	assertion := &saml.Assertion{
+		Subject:         &saml.Subject{NameID: &saml.NameID{Value: nameID}},
+		AuthnStatements: []saml.AuthnStatement{{SessionIndex: "session-1"}},
+	}
+
+	statement := saml.AttributeStatement{}
+	for name, values := range attributes {
+		attr := saml.Attribute{Name: name}
+		for _, v := range values {
+			attr.Values = append(attr.Values, saml.AttributeValue{Value: v})
+		}
+		statement.Attributes = append(statement.Attributes, attr)
+	}
+	assertion.AttributeStatements = []saml.AttributeStatement{statement}
+
+	return assertion
+}
@@ -0,0 +1,124 @@
+package clients
+
+import (
+	"context"
+	"strconv"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var (
+	errWebAuthnBadRequest = errutil.BadRequest("webauthn.invalid", errutil.WithPublicMessage("Bad passkey login data"))
+	// errWebAuthnMFARequired carries the passkey challenge the user must answer to complete a password login.
+	errWebAuthnMFARequired = errutil.Unauthorized("webauthn.mfa-required").MustTemplate(
+		"passkey confirmation required for user {{ .Private.userID }}",
+		errutil.WithPublic("Confirm your login with a passkey"),
+	)
+	errWebAuthnBasicAuthNotAllowed = errutil.Unauthorized(
+		"webauthn.basic-auth-not-allowed",
+		errutil.WithPublicMessage("Basic authentication is not allowed for users with a passkey, use a service account token instead"),
+	)
+)
+
+var _ authn.Client = new(WebAuthn)
+
+func ProvideWebAuthn(cfg *setting.Cfg, webauthnService webauthn.Service, tracer tracing.Tracer) *WebAuthn {
+	return &WebAuthn{cfg, webauthnService, tracer, log.New("authn.webauthn")}
+}
+
+type WebAuthn struct {
+	cfg             *setting.Cfg
+	webauthnService webauthn.Service
+	tracer          tracing.Tracer
+	log             log.Logger
+}
+
+func (c *WebAuthn) Name() string {
+	return authn.ClientWebAuthn
+}
+
+func (c *WebAuthn) IsEnabled() bool {
+	return c.cfg.WebAuthn.Enabled
+}
+
+// Authenticate implements authn.Client. It completes both passkey logins and
+// passkey confirmations of password logins, depending on the ceremony that was started.
+func (c *WebAuthn) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	ctx, span := c.tracer.Start(ctx, "authn.webauthn.Authenticate")
+	defer span.End()
+
+	var cmd webauthn.FinishLoginCommand
+	if err := web.Bind(r.HTTPRequest, &cmd); err != nil {
+		return nil, errWebAuthnBadRequest.Errorf("failed to parse request: %w", err)
+	}
+
+	result, err := c.webauthnService.FinishLogin(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	r.SetMeta(authn.MetaKeyAuthModule, login.WebAuthnAuthModule)
+
+	return &authn.Identity{
+		ID:              strconv.FormatInt(result.UserID, 10),
+		Type:            claims.TypeUser,
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: login.WebAuthnAuthModule,
+	}, nil
+}
+
+// MFAHook requires password logins of users who registered a passkey to be confirmed with it.
+// Instead of a session, the login fails with the passkey challenge, which the user answers through this client.
+// Basic auth sends the password with every request and cannot answer the challenge, so it is rejected for these users.
+func (c *WebAuthn) MFAHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	isLogin := r.GetMeta(authn.MetaKeyIsLogin) != ""
+	isBasicAuth := r.GetMeta(authn.MetaKeyIsBasicAuth) != ""
+	if !c.cfg.WebAuthn.MFAEnabled || (!isLogin && !isBasicAuth) {
+		return nil
+	}
+
+	if id.AuthenticatedBy != login.PasswordAuthModule && id.AuthenticatedBy != login.LDAPAuthModule {
+		return nil
+	}
+
+	ctx, span := c.tracer.Start(ctx, "authn.webauthn.MFAHook")
+	defer span.End()
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+
+	hasCredentials, err := c.webauthnService.HasCredentials(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !hasCredentials {
+		return nil
+	}
+
+	if !isLogin {
+		return errWebAuthnBasicAuthNotAllowed.Errorf("basic auth of user %d who registered a passkey", userID)
+	}
+
+	challenge, err := c.webauthnService.BeginLogin(ctx, webauthn.BeginLoginCommand{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	c.log.FromContext(ctx).Debug("Password login requires passkey confirmation", "userID", userID)
+
+	return errWebAuthnMFARequired.Build(errutil.TemplateData{
+		Private: map[string]any{"userID": userID},
+		Public:  map[string]any{"sessionId": challenge.SessionID, "publicKey": challenge.PublicKey},
+	})
+}
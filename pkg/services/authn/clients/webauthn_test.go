@@ -0,0 +1,194 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claims "github.com/grafana/authlib/types"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/services/webauthn/webauthntest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestWebAuthn_Authenticate(t *testing.T) {
+	type testCase struct {
+		desc        string
+		body        string
+		result      *webauthn.LoginResult
+		serviceErr  error
+		expectedErr error
+	}
+
+	tests := []testCase{
+		{
+			desc:   "should authenticate the user the passkey belongs to",
+			body:   `{"sessionId": "session", "credential": {"id": "cred"}}`,
+			result: &webauthn.LoginResult{UserID: 2},
+		},
+		{
+			desc:        "should fail on invalid request",
+			body:        `{"sessionId": 1}`,
+			expectedErr: errWebAuthnBadRequest,
+		},
+		{
+			desc:        "should fail if the passkey could not be verified",
+			body:        `{"sessionId": "session", "credential": {"id": "cred"}}`,
+			serviceErr:  webauthn.ErrVerification.Errorf("invalid signature"),
+			expectedErr: webauthn.ErrVerification,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.WebAuthn.Enabled = true
+			c := ProvideWebAuthn(cfg, &webauthntest.FakeService{ExpectedLoginResult: tt.result, ExpectedErr: tt.serviceErr}, tracing.InitializeTracerForTest())
+
+			req, err := http.NewRequest(http.MethodPost, "/api/login/webauthn", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			r := &authn.Request{OrgID: 1, HTTPRequest: req}
+
+			identity, err := c.Authenticate(context.Background(), r)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, identity)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "2", identity.ID)
+			assert.Equal(t, claims.TypeUser, identity.Type)
+			assert.Equal(t, login.WebAuthnAuthModule, identity.AuthenticatedBy)
+			assert.Equal(t, login.WebAuthnAuthModule, r.GetMeta(authn.MetaKeyAuthModule))
+		})
+	}
+}
+
+func TestWebAuthn_MFAHook(t *testing.T) {
+	type testCase struct {
+		desc            string
+		mfaEnabled      bool
+		isLogin         bool
+		isBasicAuth     bool
+		authenticatedBy string
+		credentials     []*webauthn.Credential
+		expectChallenge bool
+		expectedErr     error
+	}
+
+	creds := []*webauthn.Credential{{UID: "passkey"}}
+
+	tests := []testCase{
+		{
+			desc:            "should require a passkey for password logins of users with passkeys",
+			mfaEnabled:      true,
+			isLogin:         true,
+			authenticatedBy: login.PasswordAuthModule,
+			credentials:     creds,
+			expectChallenge: true,
+		},
+		{
+			desc:            "should require a passkey for ldap logins of users with passkeys",
+			mfaEnabled:      true,
+			isLogin:         true,
+			authenticatedBy: login.LDAPAuthModule,
+			credentials:     creds,
+			expectChallenge: true,
+		},
+		{
+			desc:            "should skip users without passkeys",
+			mfaEnabled:      true,
+			isLogin:         true,
+			authenticatedBy: login.PasswordAuthModule,
+		},
+		{
+			desc:            "should skip when mfa is disabled",
+			isLogin:         true,
+			authenticatedBy: login.PasswordAuthModule,
+			credentials:     creds,
+		},
+		{
+			desc:            "should skip requests that are not logins",
+			mfaEnabled:      true,
+			authenticatedBy: login.PasswordAuthModule,
+			credentials:     creds,
+		},
+		{
+			desc:            "should reject basic auth of users with passkeys",
+			mfaEnabled:      true,
+			isBasicAuth:     true,
+			authenticatedBy: login.PasswordAuthModule,
+			credentials:     creds,
+			expectedErr:     errWebAuthnBasicAuthNotAllowed,
+		},
+		{
+			desc:            "should reject ldap basic auth of users with passkeys",
+			mfaEnabled:      true,
+			isBasicAuth:     true,
+			authenticatedBy: login.LDAPAuthModule,
+			credentials:     creds,
+			expectedErr:     errWebAuthnBasicAuthNotAllowed,
+		},
+		{
+			desc:            "should allow basic auth of users without passkeys",
+			mfaEnabled:      true,
+			isBasicAuth:     true,
+			authenticatedBy: login.PasswordAuthModule,
+		},
+		{
+			desc:            "should skip passkey logins",
+			mfaEnabled:      true,
+			isLogin:         true,
+			authenticatedBy: login.WebAuthnAuthModule,
+			credentials:     creds,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.WebAuthn.Enabled = true
+			cfg.WebAuthn.MFAEnabled = tt.mfaEnabled
+			service := &webauthntest.FakeService{
+				ExpectedCredentials:    tt.credentials,
+				ExpectedLoginChallenge: &webauthn.LoginChallenge{SessionID: "session"},
+			}
+			c := ProvideWebAuthn(cfg, service, tracing.InitializeTracerForTest())
+
+			r := &authn.Request{}
+			if tt.isLogin {
+				r.SetMeta(authn.MetaKeyIsLogin, "true")
+			}
+			if tt.isBasicAuth {
+				r.SetMeta(authn.MetaKeyIsBasicAuth, "true")
+			}
+			id := &authn.Identity{ID: "1", Type: claims.TypeUser, AuthenticatedBy: tt.authenticatedBy}
+
+			err := c.MFAHook(context.Background(), id, r)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !tt.expectChallenge {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, errWebAuthnMFARequired)
+			var gfErr errutil.Error
+			require.True(t, errors.As(err, &gfErr))
+			assert.Equal(t, "session", gfErr.PublicPayload["sessionId"])
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -46,6 +51,11 @@ func (api *ImportDashboardAPI) RegisterAPIEndpoints(routeRegister routing.RouteR
 			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
 			routing.Wrap(api.ImportDashboard),
 		)
+		route.Post(
+			"/import/bundle",
+			authorize(accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate)),
+			routing.Wrap(api.ImportBundle),
+		)
 		//nolint:staticcheck // not yet migrated to OpenFeature
 		if api.features.IsEnabledGlobally(featuremgmt.FlagDashboardLibrary) || api.features.IsEnabledGlobally(featuremgmt.FlagSuggestedDashboards) || api.features.IsEnabledGlobally(featuremgmt.FlagDashboardTemplates) {
 			route.Post(
@@ -122,15 +132,91 @@ func (api *ImportDashboardAPI) ImportDashboard(c *contextmodel.ReqContext) respo
 	req.User = c.SignedInUser
 	resp, err := api.dashboardImportService.ImportDashboard(c.Req.Context(), &req)
 	if err != nil {
-		if errors.Is(err, utils.ErrDashboardInputMissing) {
+		return api.importErrorResponse(c, err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
+// maxBundleSize is the maximum size of a zip archive of dashboards.
+const maxBundleSize = 64 << 20
+
+// swagger:route POST /dashboards/import/bundle dashboards importDashboardBundle
+//
+// Import a bundle of dashboards.
+//
+// Imports interdependent dashboards at once, from a JSON request or from a zip archive sent with the
+// application/zip content type, where directories are imported as folders. The dependencies and permissions of all
+// dashboards are checked before anything is written. If a dashboard still fails to import, the changes already made
+// are rolled back on a best effort basis.
+//
+// Responses:
+// 200: importDashboardBundleResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 412: preconditionFailedError
+// 422: unprocessableEntityError
+// 500: internalServerError
+func (api *ImportDashboardAPI) ImportBundle(c *contextmodel.ReqContext) response.Response {
+	req := dashboardimport.ImportBundleRequest{}
+	if strings.HasPrefix(c.Req.Header.Get("Content-Type"), "application/zip") {
+		data, err := io.ReadAll(io.LimitReader(c.Req.Body, maxBundleSize+1))
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "failed to read bundle", err)
+		}
+		if len(data) > maxBundleSize {
+			return response.Error(http.StatusRequestEntityTooLarge, fmt.Sprintf("bundle is larger than %d bytes", maxBundleSize), nil)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "bundle is not a zip archive", err)
+		}
+		if req.Dashboards, err = utils.ReadBundle(archive); err != nil {
 			return response.Error(http.StatusBadRequest, err.Error(), err)
 		}
-		return apierrors.ToDashboardErrorResponse(c.Req.Context(), api.pluginStore, err)
+		req.FolderUid = c.Query("folderUid")
+		req.Overwrite = c.QueryBool("overwrite")
+		req.DryRun = c.QueryBool("dryRun")
+	} else if err := web.Bind(c.Req, &req); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if len(req.Dashboards) == 0 {
+		return response.Error(http.StatusUnprocessableEntity, "Dashboards must be set", nil)
+	}
+
+	limitReached, err := api.quotaService.QuotaReached(c, dashboards.QuotaTargetSrv)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if limitReached {
+		return response.Error(http.StatusForbidden, "Quota reached", nil)
+	}
+
+	req.User = c.SignedInUser
+	resp, err := api.dashboardImportService.ImportBundle(c.Req.Context(), &req)
+	if err != nil {
+		return api.importErrorResponse(c, err)
 	}
 
 	return response.JSON(http.StatusOK, resp)
 }
 
+func (api *ImportDashboardAPI) importErrorResponse(c *contextmodel.ReqContext, err error) response.Response {
+	if errors.Is(err, utils.ErrDashboardInputMissing) ||
+		errors.Is(err, utils.ErrInvalidBundle) ||
+		errors.Is(err, dashboardimport.ErrMissingDependencies) ||
+		errors.Is(err, dashboardimport.ErrInvalidDatasource) {
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	if errors.Is(err, dashboardimport.ErrDatasourceCreateForbidden) {
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+	return apierrors.ToDashboardErrorResponse(c.Req.Context(), api.pluginStore, err)
+}
+
 type QuotaService interface {
 	QuotaReached(c *contextmodel.ReqContext, target quota.TargetSrv) (bool, error)
 }
@@ -154,6 +240,19 @@ type ImportDashboardResponse struct {
 	Body dashboardimport.ImportDashboardResponse `json:"body"`
 }
 
+// swagger:parameters importDashboardBundle
+type ImportDashboardBundleParams struct {
+	// in:body
+	// required:true
+	Body dashboardimport.ImportBundleRequest
+}
+
+// swagger:response importDashboardBundleResponse
+type ImportDashboardBundleResponse struct {
+	// in: body
+	Body dashboardimport.ImportBundleResponse `json:"body"`
+}
+
 // swagger:response interpolateDashboardResponse
 type InterpolateDashboardResponse struct {
 	// in: body
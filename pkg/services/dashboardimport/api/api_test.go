@@ -1,6 +1,7 @@
 package api
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -451,9 +452,94 @@ func TestInterpolateDashboardAPI(t *testing.T) {
 	})
 }
 
+func TestImportBundleAPI(t *testing.T) {
+	var bundleReq *dashboardimport.ImportBundleRequest
+	service := &serviceMock{
+		importBundleFunc: func(ctx context.Context, req *dashboardimport.ImportBundleRequest) (*dashboardimport.ImportBundleResponse, error) {
+			bundleReq = req
+			if req.FolderUid == "missing-deps" {
+				return nil, dashboardimport.ErrMissingDependencies
+			}
+			return &dashboardimport.ImportBundleResponse{}, nil
+		},
+	}
+
+	importDashboardAPI := New(service, quotaServiceFunc(quotaNotReached), nil, actest.FakeAccessControl{ExpectedEvaluate: true}, featuremgmt.WithFeatures())
+	routeRegister := routing.NewRouteRegister()
+	importDashboardAPI.RegisterAPIEndpoints(routeRegister)
+	s := webtest.NewServer(t, routeRegister)
+
+	zipBundle := func(t *testing.T, files map[string]string) []byte {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, content := range files {
+			f, err := w.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	send := func(t *testing.T, target, contentType string, body []byte) int {
+		req := s.NewPostRequest(target, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		webtest.RequestWithSignedInUser(req, &user.SignedInUser{UserID: 1})
+		resp, err := s.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp.StatusCode
+	}
+
+	t.Run("should import a zip archive", func(t *testing.T) {
+		body := zipBundle(t, map[string]string{
+			"team-a/service.json": `{"uid": "service"}`,
+			"home.json":           `{"uid": "home"}`,
+		})
+		require.Equal(t, http.StatusOK, send(t, "/api/dashboards/import/bundle?folderUid=root&dryRun=true", "application/zip", body))
+		require.Len(t, bundleReq.Dashboards, 2)
+		require.Equal(t, []string{"team-a"}, bundleReq.Dashboards[1].Folders)
+		require.Equal(t, "root", bundleReq.FolderUid)
+		require.True(t, bundleReq.DryRun)
+		require.False(t, bundleReq.Overwrite)
+	})
+
+	t.Run("should import a JSON request", func(t *testing.T) {
+		body, err := json.Marshal(&dashboardimport.ImportBundleRequest{
+			Dashboards: []dashboardimport.ImportBundleDashboard{{Path: "home.json", Dashboard: simplejson.New()}},
+			Overwrite:  true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, send(t, "/api/dashboards/import/bundle", "application/json", body))
+		require.Len(t, bundleReq.Dashboards, 1)
+		require.True(t, bundleReq.Overwrite)
+	})
+
+	t.Run("should reject invalid bundles", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, send(t, "/api/dashboards/import/bundle", "application/zip", []byte("not a zip")))
+		require.Equal(t, http.StatusBadRequest, send(t, "/api/dashboards/import/bundle", "application/zip", zipBundle(t, map[string]string{"README.md": "empty"})))
+		require.Equal(t, http.StatusUnprocessableEntity, send(t, "/api/dashboards/import/bundle", "application/json", []byte(`{"dashboards": []}`)))
+	})
+
+	t.Run("should return bad request when dependencies are missing", func(t *testing.T) {
+		body := zipBundle(t, map[string]string{"home.json": `{"uid": "home"}`})
+		require.Equal(t, http.StatusBadRequest, send(t, "/api/dashboards/import/bundle?folderUid=missing-deps", "application/zip", body))
+	})
+}
+
 type serviceMock struct {
 	importDashboardFunc      func(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error)
 	interpolateDashboardFunc func(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*simplejson.Json, error)
+	importBundleFunc         func(ctx context.Context, req *dashboardimport.ImportBundleRequest) (*dashboardimport.ImportBundleResponse, error)
+}
+
+func (s *serviceMock) ImportBundle(ctx context.Context, req *dashboardimport.ImportBundleRequest) (*dashboardimport.ImportBundleResponse, error) {
+	if s.importBundleFunc != nil {
+		return s.importBundleFunc(ctx, req)
+	}
+
+	return nil, nil
 }
 
 func (s *serviceMock) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
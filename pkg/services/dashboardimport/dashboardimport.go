@@ -2,11 +2,22 @@ package dashboardimport
 
 import (
 	"context"
+	"errors"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
+// ErrMissingDependencies occurs when an import references library panels or datasources that neither exist nor can
+// be created.
+var ErrMissingDependencies = errors.New("missing dashboard import dependencies")
+
+// ErrDatasourceCreateForbidden occurs when an import creates a datasource for a user who cannot create datasources.
+var ErrDatasourceCreateForbidden = errors.New("not allowed to create the datasources of the import")
+
+// ErrInvalidDatasource occurs when the definition of a datasource created on import is invalid.
+var ErrInvalidDatasource = errors.New("invalid import datasource")
+
 // ImportDashboardInput definition of input parameters when importing a dashboard.
 type ImportDashboardInput struct {
 	Type     string `json:"type"`
@@ -25,10 +36,61 @@ type ImportDashboardRequest struct {
 	// Deprecated: use FolderUID instead
 	FolderId  int64  `json:"folderId"`
 	FolderUid string `json:"folderUid"`
+	// FolderTitle is the title of the folder created when FolderUid does not exist
+	FolderTitle string `json:"folderTitle"`
+	// DatasourceMappings resolve the datasource inputs that have no value
+	DatasourceMappings []DatasourceMapping `json:"datasourceMappings"`
+	// DryRun resolves the dependencies of the dashboard and reports the changes without importing it
+	DryRun bool `json:"dryRun"`
 
 	User identity.Requester `json:"-"`
 }
 
+// ImportDatasource definition of a datasource created on import when the org has none of its type. Creating it
+// requires the permission to create datasources.
+type ImportDatasource struct {
+	Name     string           `json:"name"`
+	URL      string           `json:"url"`
+	Access   string           `json:"access"`
+	JsonData *simplejson.Json `json:"jsonData"`
+}
+
+// DatasourceMapping maps the datasource inputs of a plugin type that have no value to an existing datasource, or to
+// a datasource created on import. Without a mapping, inputs resolve to the default datasource of their type.
+type DatasourceMapping struct {
+	PluginId string            `json:"pluginId"`
+	UID      string            `json:"uid"`
+	Create   *ImportDatasource `json:"create,omitempty"`
+}
+
+type ImportChangeKind string
+
+const (
+	ImportChangeDashboard    ImportChangeKind = "dashboard"
+	ImportChangeFolder       ImportChangeKind = "folder"
+	ImportChangeLibraryPanel ImportChangeKind = "libraryPanel"
+	ImportChangeDatasource   ImportChangeKind = "datasource"
+)
+
+type ImportAction string
+
+const (
+	ImportActionCreate  ImportAction = "create"
+	ImportActionUpdate  ImportAction = "update"
+	ImportActionUse     ImportAction = "use"
+	ImportActionMissing ImportAction = "missing"
+)
+
+// ImportChange is a change made by an import, or planned by a dry run.
+type ImportChange struct {
+	Kind   ImportChangeKind `json:"kind"`
+	UID    string           `json:"uid,omitempty"`
+	Title  string           `json:"title"`
+	Action ImportAction     `json:"action"`
+	// Path is the path of the dashboard in a bundle
+	Path string `json:"path,omitempty"`
+}
+
 // ImportDashboardResponse response object returned when importing a dashboard.
 type ImportDashboardResponse struct {
 	UID         string `json:"uid"`
@@ -47,10 +109,43 @@ type ImportDashboardResponse struct {
 	Description      string `json:"description"`
 	Path             string `json:"path"`
 	Removed          bool   `json:"removed"`
+	// Changes are the dependencies resolved for the dashboard and the changes made to import it
+	Changes []ImportChange `json:"changes,omitempty"`
+	DryRun  bool           `json:"dryRun,omitempty"`
+}
+
+// ImportBundleDashboard is a dashboard of a bundle.
+type ImportBundleDashboard struct {
+	Path string `json:"path"`
+	// Folders are the titles of the folders from the folder of the bundle to the folder of the dashboard
+	Folders   []string         `json:"folders"`
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+// ImportBundleRequest request object for importing interdependent dashboards at once. The dependencies and
+// permissions of all dashboards are checked before anything is written. The rollback when a dashboard fails is best
+// effort: the changes already made are undone one by one, and are left in place if undoing them fails too.
+type ImportBundleRequest struct {
+	Dashboards         []ImportBundleDashboard `json:"dashboards"`
+	Inputs             []ImportDashboardInput  `json:"inputs"`
+	DatasourceMappings []DatasourceMapping     `json:"datasourceMappings"`
+	FolderUid          string                  `json:"folderUid"`
+	Overwrite          bool                    `json:"overwrite"`
+	DryRun             bool                    `json:"dryRun"`
+
+	User identity.Requester `json:"-"`
+}
+
+// ImportBundleResponse response object returned when importing a bundle.
+type ImportBundleResponse struct {
+	Dashboards []ImportDashboardResponse `json:"dashboards"`
+	Changes    []ImportChange            `json:"changes"`
+	DryRun     bool                      `json:"dryRun"`
 }
 
 // Service service interface for importing dashboards.
 type Service interface {
 	ImportDashboard(ctx context.Context, req *ImportDashboardRequest) (*ImportDashboardResponse, error)
 	InterpolateDashboard(ctx context.Context, req *ImportDashboardRequest) (*simplejson.Json, error)
+	ImportBundle(ctx context.Context, req *ImportBundleRequest) (*ImportBundleResponse, error)
 }
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/datasource"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// importPlan holds the dependencies resolved for the dashboards of an import. Nothing is written until the plan is
+// applied, so a dry run can report the changes and a bundle is validated as a whole before the first write.
+type importPlan struct {
+	user      identity.Requester
+	inputs    []dashboardimport.ImportDashboardInput
+	mappings  []dashboardimport.DatasourceMapping
+	overwrite bool
+
+	changes []dashboardimport.ImportChange
+	missing []string
+
+	// datasources are the uids of the datasources resolved by plugin id
+	datasources    map[string]string
+	newDatasources []*datasources.AddDataSourceCommand
+	// folders are the uids of the folders resolved by parent uid and title
+	folders    map[string]string
+	folderIDs  map[string]int64
+	newFolders []*folder.CreateFolderCommand
+	// elements are the library panels defined by the dashboards of the import
+	libraryPanels map[string]bool
+	elements      map[string]any
+	dashboards    []*plannedDashboard
+}
+
+type plannedDashboard struct {
+	path      string
+	pluginID  string
+	folderUID string
+	dashboard *simplejson.Json
+	// previous is the dashboard replaced by the import, if any
+	previous *dashboards.Dashboard
+	// newLibraryPanels are the library panels created with the dashboard
+	newLibraryPanels []string
+}
+
+func newImportPlan(user identity.Requester, inputs []dashboardimport.ImportDashboardInput, mappings []dashboardimport.DatasourceMapping, overwrite bool) *importPlan {
+	return &importPlan{
+		user:          user,
+		inputs:        inputs,
+		mappings:      mappings,
+		overwrite:     overwrite,
+		changes:       make([]dashboardimport.ImportChange, 0),
+		datasources:   make(map[string]string),
+		folders:       make(map[string]string),
+		folderIDs:     make(map[string]int64),
+		libraryPanels: make(map[string]bool),
+		elements:      make(map[string]any),
+	}
+}
+
+func (p *importPlan) change(kind dashboardimport.ImportChangeKind, uid, title string, action dashboardimport.ImportAction, path string) {
+	p.changes = append(p.changes, dashboardimport.ImportChange{Kind: kind, UID: uid, Title: title, Action: action, Path: path})
+	if action == dashboardimport.ImportActionMissing {
+		p.missing = append(p.missing, fmt.Sprintf("%s %s", kind, title))
+	}
+}
+
+func (p *importPlan) createFolder(uid, title, parentUID string) {
+	p.newFolders = append(p.newFolders, &folder.CreateFolderCommand{
+		UID:          uid,
+		OrgID:        p.user.GetOrgID(),
+		Title:        title,
+		ParentUID:    parentUID,
+		SignedInUser: p.user,
+	})
+	p.folders[parentUID+"/"+title] = uid
+	p.change(dashboardimport.ImportChangeFolder, uid, title, dashboardimport.ImportActionCreate, "")
+}
+
+// resolveFolder returns the uid of the folder with the given title in the parent folder, planning to create it when
+// it does not exist.
+func (s *ImportDashboardService) resolveFolder(ctx context.Context, plan *importPlan, parentUID, title string) (string, error) {
+	if uid, ok := plan.folders[parentUID+"/"+title]; ok {
+		return uid, nil
+	}
+
+	children, err := s.folderService.GetChildren(ctx, &folder.GetChildrenQuery{
+		UID:          parentUID,
+		OrgID:        plan.user.GetOrgID(),
+		SignedInUser: plan.user,
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, child := range children {
+		if child.Title == title {
+			plan.folders[parentUID+"/"+title] = child.UID
+			plan.folderIDs[child.UID] = child.ID // nolint:staticcheck
+			plan.change(dashboardimport.ImportChangeFolder, child.UID, title, dashboardimport.ImportActionUse, "")
+			return child.UID, nil
+		}
+	}
+
+	uid := util.GenerateShortUID()
+	plan.createFolder(uid, title, parentUID)
+	return uid, nil
+}
+
+// planDashboard interpolates the inputs of the dashboard, resolving the datasource inputs that have no value, and
+// adds it to the plan.
+func (s *ImportDashboardService) planDashboard(ctx context.Context, plan *importPlan, template *simplejson.Json, planned *plannedDashboard) error {
+	inputs, err := s.resolveDatasourceInputs(ctx, plan, template)
+	if err != nil {
+		return err
+	}
+
+	evaluator := utils.NewDashTemplateEvaluator(template, inputs)
+	generatedDash, err := evaluator.Eval()
+	if err != nil {
+		return err
+	}
+
+	// Maintain backwards compatibility by transforming array of library elements to map
+	libraryElements := generatedDash.Get("__elements")
+	if libElementsArr, err := libraryElements.Array(); err == nil {
+		for _, el := range libElementsArr {
+			plan.elements[simplejson.NewFromAny(el).Get("uid").MustString()] = el
+		}
+	} else {
+		for uid, el := range libraryElements.MustMap() {
+			plan.elements[uid] = el
+		}
+	}
+
+	// No need to keep these in the stored dashboard JSON
+	generatedDash.Del("__elements")
+	generatedDash.Del("__inputs")
+	generatedDash.Del("__requires")
+
+	action := dashboardimport.ImportActionCreate
+	uid := generatedDash.Get("uid").MustString()
+	if uid != "" {
+		existing, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: plan.user.GetOrgID()})
+		switch {
+		case err == nil:
+			planned.previous = existing
+			action = dashboardimport.ImportActionUpdate
+		case !errors.Is(err, dashboards.ErrDashboardNotFound):
+			return err
+		}
+	}
+
+	planned.dashboard = generatedDash
+	plan.dashboards = append(plan.dashboards, planned)
+	plan.change(dashboardimport.ImportChangeDashboard, uid, generatedDash.Get("title").MustString(), action, planned.path)
+	return nil
+}
+
+// resolveDatasourceInputs returns the inputs of the request completed with the datasource inputs of the dashboard
+// that have no value.
+func (s *ImportDashboardService) resolveDatasourceInputs(ctx context.Context, plan *importPlan, template *simplejson.Json) ([]dashboardimport.ImportDashboardInput, error) {
+	inputs := slices.Clone(plan.inputs)
+	for _, inputDef := range template.Get("__inputs").MustArray() {
+		inputDefJson := simplejson.NewFromAny(inputDef)
+		inputName := inputDefJson.Get("name").MustString()
+		inputType := inputDefJson.Get("type").MustString()
+		pluginID := inputDefJson.Get("pluginId").MustString()
+		if inputType != "datasource" || pluginID == expr.DatasourceType || hasInput(plan.inputs, inputName, inputType) {
+			continue
+		}
+
+		uid, err := s.resolveDatasource(ctx, plan, pluginID)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, dashboardimport.ImportDashboardInput{
+			Type:     inputType,
+			PluginId: pluginID,
+			Name:     inputName,
+			Value:    uid,
+		})
+	}
+	return inputs, nil
+}
+
+// hasInput matches inputs the same way as the template evaluator.
+func hasInput(inputs []dashboardimport.ImportDashboardInput, name, inputType string) bool {
+	for _, input := range inputs {
+		if input.Type == inputType && (input.Name == name || input.Name == "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDatasource returns the uid of the datasource used for the inputs of a plugin type: the datasource of the
+// mapping, or else the default datasource of the type, or else a datasource created from the mapping.
+func (s *ImportDashboardService) resolveDatasource(ctx context.Context, plan *importPlan, pluginID string) (string, error) {
+	if uid, ok := plan.datasources[pluginID]; ok {
+		return uid, nil
+	}
+
+	var mapping *dashboardimport.DatasourceMapping
+	for i := range plan.mappings {
+		if plan.mappings[i].PluginId == pluginID {
+			mapping = &plan.mappings[i]
+			break
+		}
+	}
+
+	orgID := plan.user.GetOrgID()
+	if mapping != nil && mapping.UID != "" {
+		ds, err := s.datasourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: mapping.UID, OrgID: orgID})
+		if err == nil {
+			plan.datasources[pluginID] = ds.UID
+			plan.change(dashboardimport.ImportChangeDatasource, ds.UID, ds.Name, dashboardimport.ImportActionUse, "")
+			return ds.UID, nil
+		}
+		if !errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return "", err
+		}
+	} else {
+		existing, err := s.datasourceService.GetDataSourcesByType(ctx, &datasources.GetDataSourcesByTypeQuery{OrgID: orgID, Type: pluginID})
+		if err != nil {
+			return "", err
+		}
+		if len(existing) > 0 {
+			ds := existing[0]
+			for _, candidate := range existing {
+				if candidate.IsDefault {
+					ds = candidate
+					break
+				}
+			}
+			plan.datasources[pluginID] = ds.UID
+			plan.change(dashboardimport.ImportChangeDatasource, ds.UID, ds.Name, dashboardimport.ImportActionUse, "")
+			return ds.UID, nil
+		}
+	}
+
+	if mapping == nil || mapping.Create == nil {
+		plan.datasources[pluginID] = ""
+		plan.change(dashboardimport.ImportChangeDatasource, "", pluginID, dashboardimport.ImportActionMissing, "")
+		return "", nil
+	}
+
+	// The import routes only require the permission to create dashboards, so creating datasources is checked here,
+	// while planning, for the import to fail before anything is written.
+	canCreate, err := s.ac.Evaluate(ctx, plan.user, accesscontrol.EvalPermission(datasources.ActionCreate))
+	if err != nil {
+		return "", err
+	}
+	if !canCreate {
+		return "", fmt.Errorf("%w: %s", dashboardimport.ErrDatasourceCreateForbidden, pluginID)
+	}
+	if _, err := datasource.ValidateURL(pluginID, mapping.Create.URL); err != nil {
+		return "", fmt.Errorf("%w: %w", dashboardimport.ErrInvalidDatasource, err)
+	}
+
+	cmd := &datasources.AddDataSourceCommand{
+		Name:     mapping.Create.Name,
+		Type:     pluginID,
+		Access:   datasources.DsAccess(mapping.Create.Access),
+		URL:      mapping.Create.URL,
+		JsonData: mapping.Create.JsonData,
+		UID:      mapping.UID,
+		OrgID:    orgID,
+	}
+	if cmd.Name == "" {
+		cmd.Name = pluginID
+	}
+	if cmd.Access == "" {
+		cmd.Access = datasources.DS_ACCESS_PROXY
+	}
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	if id, err := identity.UserIdentifier(plan.user.GetID()); err == nil {
+		cmd.UserID = id
+	}
+	plan.newDatasources = append(plan.newDatasources, cmd)
+	plan.datasources[pluginID] = cmd.UID
+	plan.change(dashboardimport.ImportChangeDatasource, cmd.UID, cmd.Name, dashboardimport.ImportActionCreate, "")
+	return cmd.UID, nil
+}
+
+// resolveLibraryPanels checks the library panels used by the planned dashboards. Library panels that do not exist
+// are created with the first dashboard using them from the elements of any dashboard of the import.
+func (s *ImportDashboardService) resolveLibraryPanels(ctx context.Context, plan *importPlan) error {
+	for _, planned := range plan.dashboards {
+		for _, ref := range libraryPanelRefs(planned.dashboard.Get("panels").MustArray()) {
+			if plan.libraryPanels[ref.uid] {
+				continue
+			}
+			plan.libraryPanels[ref.uid] = true
+
+			element, err := s.libraryElementService.GetElement(ctx, plan.user, model.GetLibraryElementCommand{UID: ref.uid, FolderName: dashboards.RootFolderName})
+			if err == nil {
+				plan.change(dashboardimport.ImportChangeLibraryPanel, ref.uid, element.Name, dashboardimport.ImportActionUse, "")
+				continue
+			}
+			if !errors.Is(err, model.ErrLibraryElementNotFound) {
+				return err
+			}
+
+			if _, ok := simplejson.NewFromAny(plan.elements[ref.uid]).CheckGet("model"); ok {
+				planned.newLibraryPanels = append(planned.newLibraryPanels, ref.uid)
+				plan.change(dashboardimport.ImportChangeLibraryPanel, ref.uid, ref.name, dashboardimport.ImportActionCreate, "")
+				continue
+			}
+			plan.change(dashboardimport.ImportChangeLibraryPanel, ref.uid, ref.name, dashboardimport.ImportActionMissing, "")
+		}
+	}
+	return nil
+}
+
+type libraryPanelRef struct {
+	uid  string
+	name string
+}
+
+func libraryPanelRefs(panels []any) []libraryPanelRef {
+	var refs []libraryPanelRef
+	for _, panel := range panels {
+		panelAsJSON := simplejson.NewFromAny(panel)
+		if panelAsJSON.Get("type").MustString() == "row" {
+			refs = append(refs, libraryPanelRefs(panelAsJSON.Get("panels").MustArray())...)
+			continue
+		}
+		libraryPanel := panelAsJSON.Get("libraryPanel")
+		if uid := libraryPanel.Get("uid").MustString(); uid != "" {
+			refs = append(refs, libraryPanelRef{uid: uid, name: libraryPanel.Get("name").MustString()})
+		}
+	}
+	return refs
+}
+
+// applyPlan creates the dependencies and saves the dashboards of the plan. When any write fails, the changes made so
+// far are rolled back. The rollback is best effort and not transactional: concurrent readers can see the partial
+// import, and changes whose rollback fails are logged and left in place.
+func (s *ImportDashboardService) applyPlan(ctx context.Context, plan *importPlan) (saved []*dashboards.Dashboard, err error) {
+	if len(plan.missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", dashboardimport.ErrMissingDependencies, strings.Join(plan.missing, ", "))
+	}
+
+	var rollback []func(ctx context.Context) error
+	defer func() {
+		if err == nil {
+			return
+		}
+		// roll back even when the request was canceled
+		ctx := context.WithoutCancel(ctx)
+		for i := len(rollback) - 1; i >= 0; i-- {
+			if rerr := rollback[i](ctx); rerr != nil {
+				s.log.Error("Failed to roll back dashboard import", "error", rerr)
+			}
+		}
+	}()
+
+	orgID := plan.user.GetOrgID()
+	for _, cmd := range plan.newDatasources {
+		ds, err := s.datasourceService.AddDataSource(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		rollback = append(rollback, func(ctx context.Context) error {
+			return s.datasourceService.DeleteDataSource(ctx, &datasources.DeleteDataSourceCommand{UID: ds.UID, OrgID: orgID})
+		})
+	}
+
+	for _, cmd := range plan.newFolders {
+		f, err := s.folderService.Create(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		plan.folderIDs[f.UID] = f.ID // nolint:staticcheck
+		rollback = append(rollback, func(ctx context.Context) error {
+			return s.folderService.Delete(ctx, &folder.DeleteFolderCommand{UID: f.UID, OrgID: orgID, SignedInUser: plan.user})
+		})
+	}
+
+	var userID int64
+	if id, err := identity.UserIdentifier(plan.user.GetID()); err == nil {
+		userID = id
+	}
+
+	libraryPanels := simplejson.NewFromAny(plan.elements)
+	for _, planned := range plan.dashboards {
+		folderID := plan.folderIDs[planned.folderUID]
+		for _, uid := range planned.newLibraryPanels {
+			rollback = append(rollback, func(ctx context.Context) error {
+				_, err := s.libraryElementService.DeleteLibraryElement(ctx, plan.user, uid)
+				if errors.Is(err, model.ErrLibraryElementNotFound) {
+					return nil
+				}
+				return err
+			})
+		}
+		err := s.libraryPanelService.ImportLibraryPanelsForDashboard(ctx, plan.user, libraryPanels, planned.dashboard.Get("panels").MustArray(), folderID, planned.folderUID)
+		if err != nil {
+			return nil, err
+		}
+
+		saveCmd := dashboards.SaveDashboardCommand{
+			Dashboard: planned.dashboard,
+			OrgID:     orgID,
+			UserID:    userID,
+			Overwrite: plan.overwrite,
+			PluginID:  planned.pluginID,
+			FolderID:  folderID, // nolint:staticcheck
+			FolderUID: planned.folderUID,
+		}
+
+		dto := &dashboards.SaveDashboardDTO{
+			OrgID:     saveCmd.OrgID,
+			Dashboard: saveCmd.GetDashboardModel(),
+			Overwrite: saveCmd.Overwrite,
+			User:      plan.user,
+		}
+
+		savedDashboard, err := s.dashboardService.ImportDashboard(ctx, dto)
+		if err != nil {
+			return nil, err
+		}
+		previous := planned.previous
+		rollback = append(rollback, func(ctx context.Context) error {
+			if previous == nil {
+				return s.dashboardService.DeleteDashboard(ctx, savedDashboard.ID, savedDashboard.UID, orgID)
+			}
+			_, err := s.dashboardService.ImportDashboard(ctx, &dashboards.SaveDashboardDTO{
+				OrgID:     orgID,
+				Dashboard: previous,
+				Overwrite: true,
+				User:      plan.user,
+			})
+			return err
+		})
+		saved = append(saved, savedDashboard)
+	}
+
+	return saved, nil
+}
+
+func importResponse(planned *plannedDashboard, savedDashboard *dashboards.Dashboard) dashboardimport.ImportDashboardResponse {
+	if savedDashboard == nil {
+		return dashboardimport.ImportDashboardResponse{
+			UID:       planned.dashboard.Get("uid").MustString(),
+			PluginId:  planned.pluginID,
+			Title:     planned.dashboard.Get("title").MustString(),
+			Path:      planned.path,
+			FolderUID: planned.folderUID,
+		}
+	}
+
+	revision := savedDashboard.Data.Get("revision").MustInt64(0)
+	return dashboardimport.ImportDashboardResponse{
+		UID:              savedDashboard.UID,
+		PluginId:         planned.pluginID,
+		Title:            savedDashboard.Title,
+		Path:             planned.path,
+		Revision:         revision,                // only used for plugin version tracking
+		FolderId:         savedDashboard.FolderID, // nolint:staticcheck
+		FolderUID:        planned.folderUID,
+		ImportedUri:      "db/" + savedDashboard.Slug,
+		ImportedUrl:      savedDashboard.GetURL(),
+		ImportedRevision: revision,
+		Imported:         true,
+		DashboardId:      savedDashboard.ID,
+		Slug:             savedDashboard.Slug,
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func mustJSON(t *testing.T, s string) *simplejson.Json {
+	t.Helper()
+	j, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return j
+}
+
+func TestImportDashboardDryRun(t *testing.T) {
+	template := `{
+		"__inputs": [
+			{"name": "DS_PROM", "type": "datasource", "pluginId": "prometheus"},
+			{"name": "DS_LOKI", "type": "datasource", "pluginId": "loki"}
+		],
+		"__elements": {
+			"lib-new": {"uid": "lib-new", "name": "New", "model": {"type": "stat"}}
+		},
+		"uid": "dash-uid",
+		"title": "Service",
+		"panels": [
+			{"id": 1, "datasource": {"uid": "${DS_PROM}"}},
+			{"id": 2, "datasource": {"uid": "${DS_LOKI}"}},
+			{"id": 3, "type": "row", "panels": [
+				{"id": 4, "libraryPanel": {"uid": "lib-existing", "name": "Existing"}},
+				{"id": 5, "libraryPanel": {"uid": "lib-new", "name": "New"}},
+				{"id": 6, "libraryPanel": {"uid": "lib-missing", "name": "Missing"}}
+			]}
+		]
+	}`
+
+	signedInUser := &user.SignedInUser{UserID: 2, OrgRole: org.RoleAdmin, OrgID: 3}
+	libraryElementService := &libraryElementServiceMock{elements: map[string]string{"lib-existing": "Existing"}}
+	datasourceService := &fakeDatasources.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "prom-1", Name: "Prometheus", Type: "prometheus", OrgID: 3},
+		{UID: "prom-default", Name: "Prometheus default", Type: "prometheus", OrgID: 3, IsDefault: true},
+	}}
+	s := &ImportDashboardService{
+		dashboardService: &dashboardServiceMock{
+			importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+				t.Fatal("dashboard must not be saved")
+				return nil, nil
+			},
+		},
+		libraryPanelService:   &libraryPanelServiceMock{},
+		libraryElementService: libraryElementService,
+		folderService:         &foldertest.FakeService{ExpectedError: dashboards.ErrFolderNotFound},
+		datasourceService:     datasourceService,
+		ac:                    actest.FakeAccessControl{ExpectedEvaluate: true},
+		features:              featuremgmt.WithFeatures(),
+		log:                   log.NewNopLogger(),
+	}
+
+	req := &dashboardimport.ImportDashboardRequest{
+		Dashboard:   mustJSON(t, template),
+		FolderUid:   "team",
+		FolderTitle: "Team",
+		DatasourceMappings: []dashboardimport.DatasourceMapping{
+			{PluginId: "loki", UID: "loki-uid", Create: &dashboardimport.ImportDatasource{Name: "Loki", URL: "http://loki:3100"}},
+		},
+		DryRun: true,
+		User:   signedInUser,
+	}
+
+	t.Run("should report the changes without writing anything", func(t *testing.T) {
+		resp, err := s.ImportDashboard(context.Background(), req)
+		require.NoError(t, err)
+		require.True(t, resp.DryRun)
+		require.False(t, resp.Imported)
+		require.Equal(t, "dash-uid", resp.UID)
+		require.Equal(t, "team", resp.FolderUID)
+		require.Equal(t, []dashboardimport.ImportChange{
+			{Kind: dashboardimport.ImportChangeFolder, UID: "team", Title: "Team", Action: dashboardimport.ImportActionCreate},
+			{Kind: dashboardimport.ImportChangeDatasource, UID: "prom-default", Title: "Prometheus default", Action: dashboardimport.ImportActionUse},
+			{Kind: dashboardimport.ImportChangeDatasource, UID: "loki-uid", Title: "Loki", Action: dashboardimport.ImportActionCreate},
+			{Kind: dashboardimport.ImportChangeDashboard, UID: "dash-uid", Title: "Service", Action: dashboardimport.ImportActionCreate},
+			{Kind: dashboardimport.ImportChangeLibraryPanel, UID: "lib-existing", Title: "Existing", Action: dashboardimport.ImportActionUse},
+			{Kind: dashboardimport.ImportChangeLibraryPanel, UID: "lib-new", Title: "New", Action: dashboardimport.ImportActionCreate},
+			{Kind: dashboardimport.ImportChangeLibraryPanel, UID: "lib-missing", Title: "Missing", Action: dashboardimport.ImportActionMissing},
+		}, resp.Changes)
+		require.Len(t, datasourceService.DataSources, 2)
+	})
+
+	t.Run("should fail to import when a dependency is missing", func(t *testing.T) {
+		importReq := *req
+		importReq.Dashboard = mustJSON(t, template)
+		importReq.DryRun = false
+		_, err := s.ImportDashboard(context.Background(), &importReq)
+		require.ErrorIs(t, err, dashboardimport.ErrMissingDependencies)
+		require.ErrorContains(t, err, "libraryPanel Missing")
+		require.Len(t, datasourceService.DataSources, 2)
+	})
+}
+
+func TestImportBundle(t *testing.T) {
+	bundle := func(t *testing.T) []dashboardimport.ImportBundleDashboard {
+		return []dashboardimport.ImportBundleDashboard{
+			{
+				Path:    "team-a/service.json",
+				Folders: []string{"Team A"},
+				Dashboard: mustJSON(t, `{
+					"__inputs": [{"name": "DS_PROM", "type": "datasource", "pluginId": "prometheus"}],
+					"uid": "service", "title": "Service",
+					"panels": [{"id": 1, "datasource": {"uid": "${DS_PROM}"}, "libraryPanel": {"uid": "shared", "name": "Shared"}}]
+				}`),
+			},
+			{
+				Path:    "team-a/overview.json",
+				Folders: []string{"Team A"},
+				Dashboard: mustJSON(t, `{
+					"__inputs": [{"name": "DS_PROMETHEUS", "type": "datasource", "pluginId": "prometheus"}],
+					"__elements": [{"uid": "shared", "name": "Shared", "model": {"type": "stat"}}],
+					"uid": "overview", "title": "Overview",
+					"panels": [{"id": 1, "datasource": {"uid": "${DS_PROMETHEUS}"}, "libraryPanel": {"uid": "shared", "name": "Shared"}}]
+				}`),
+			},
+		}
+	}
+	mappings := []dashboardimport.DatasourceMapping{
+		{PluginId: "prometheus", Create: &dashboardimport.ImportDatasource{Name: "Prometheus", URL: "http://prometheus:9090"}},
+	}
+	signedInUser := &user.SignedInUser{UserID: 2, OrgRole: org.RoleAdmin, OrgID: 3}
+
+	t.Run("should resolve the dependencies of all dashboards before importing them", func(t *testing.T) {
+		var imported []*dashboards.SaveDashboardDTO
+		var importedLibraryPanels []string
+		folderService := &folderServiceMock{FakeService: foldertest.NewFakeService()}
+		datasourceService := &fakeDatasources.FakeDataSourceService{}
+		s := &ImportDashboardService{
+			dashboardService: &dashboardServiceMock{
+				importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+					imported = append(imported, dto)
+					return &dashboards.Dashboard{ID: int64(len(imported)), UID: dto.Dashboard.UID, Title: dto.Dashboard.Title, FolderUID: dto.Dashboard.FolderUID, Data: dto.Dashboard.Data}, nil
+				},
+			},
+			libraryPanelService: &libraryPanelServiceMock{
+				importLibraryPanelsForDashboardFunc: func(ctx context.Context, signedInUser identity.Requester, libraryPanels *simplejson.Json, panels []any, folderID int64, folderUID string) error {
+					_, ok := libraryPanels.CheckGet("shared")
+					require.True(t, ok)
+					importedLibraryPanels = append(importedLibraryPanels, folderUID)
+					return nil
+				},
+			},
+			libraryElementService: &libraryElementServiceMock{},
+			folderService:         folderService,
+			datasourceService:     datasourceService,
+			ac:                    actest.FakeAccessControl{ExpectedEvaluate: true},
+			features:              featuremgmt.WithFeatures(),
+			log:                   log.NewNopLogger(),
+		}
+
+		resp, err := s.ImportBundle(context.Background(), &dashboardimport.ImportBundleRequest{
+			Dashboards:         bundle(t),
+			DatasourceMappings: mappings,
+			User:               signedInUser,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Dashboards, 2)
+		require.Len(t, folderService.created, 1)
+		require.Equal(t, "Team A", folderService.created[0].Title)
+
+		require.Len(t, datasourceService.DataSources, 1)
+		dsUID := datasourceService.DataSources[0].UID
+		require.Len(t, imported, 2)
+		for _, dto := range imported {
+			require.Equal(t, folderService.created[0].UID, dto.Dashboard.FolderUID)
+			require.Equal(t, dsUID, dto.Dashboard.Data.Get("panels").GetIndex(0).Get("datasource").Get("uid").MustString())
+		}
+		require.Equal(t, []string{folderService.created[0].UID, folderService.created[0].UID}, importedLibraryPanels)
+		require.Equal(t, "service", resp.Dashboards[0].UID)
+		require.True(t, resp.Dashboards[0].Imported)
+	})
+
+	t.Run("should roll back the changes when a dashboard fails to import", func(t *testing.T) {
+		var deletedDashboards []string
+		folderService := &folderServiceMock{FakeService: foldertest.NewFakeService()}
+		datasourceService := &fakeDatasources.FakeDataSourceService{}
+		libraryElementService := &libraryElementServiceMock{}
+		saveErr := errors.New("save failed")
+		s := &ImportDashboardService{
+			dashboardService: &dashboardServiceMock{
+				importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+					if dto.Dashboard.UID == "overview" {
+						return nil, saveErr
+					}
+					return &dashboards.Dashboard{ID: 10, UID: dto.Dashboard.UID, Data: dto.Dashboard.Data}, nil
+				},
+				deleteDashboardFunc: func(ctx context.Context, dashboardId int64, dashboardUID string, orgId int64) error {
+					deletedDashboards = append(deletedDashboards, dashboardUID)
+					return nil
+				},
+			},
+			libraryPanelService:   &libraryPanelServiceMock{},
+			libraryElementService: libraryElementService,
+			folderService:         folderService,
+			datasourceService:     datasourceService,
+			ac:                    actest.FakeAccessControl{ExpectedEvaluate: true},
+			features:              featuremgmt.WithFeatures(),
+			log:                   log.NewNopLogger(),
+		}
+
+		_, err := s.ImportBundle(context.Background(), &dashboardimport.ImportBundleRequest{
+			Dashboards:         bundle(t),
+			DatasourceMappings: mappings,
+			User:               signedInUser,
+		})
+		require.ErrorIs(t, err, saveErr)
+		require.Equal(t, []string{"service"}, deletedDashboards)
+		require.Equal(t, []string{"shared"}, libraryElementService.deleted)
+		require.Len(t, folderService.deleted, 1)
+		require.Equal(t, folderService.created[0].UID, folderService.deleted[0])
+		require.Empty(t, datasourceService.DataSources)
+	})
+
+	t.Run("should restore the dashboards it overwrote when rolling back", func(t *testing.T) {
+		previous := &dashboards.Dashboard{ID: 7, UID: "service", Title: "Service (old)", Data: simplejson.New()}
+		var restored *dashboards.Dashboard
+		s := &ImportDashboardService{
+			dashboardService: &dashboardServiceMock{
+				getDashboardFunc: func(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+					if query.UID == "service" {
+						return previous, nil
+					}
+					return nil, dashboards.ErrDashboardNotFound
+				},
+				importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+					switch {
+					case dto.Dashboard == previous:
+						restored = dto.Dashboard
+						return previous, nil
+					case dto.Dashboard.UID == "overview":
+						return nil, errors.New("save failed")
+					}
+					return &dashboards.Dashboard{ID: 7, UID: dto.Dashboard.UID, Data: dto.Dashboard.Data}, nil
+				},
+			},
+			libraryPanelService:   &libraryPanelServiceMock{},
+			libraryElementService: &libraryElementServiceMock{elements: map[string]string{"shared": "Shared"}},
+			folderService:         &folderServiceMock{FakeService: foldertest.NewFakeService()},
+			datasourceService:     &fakeDatasources.FakeDataSourceService{},
+			ac:                    actest.FakeAccessControl{ExpectedEvaluate: true},
+			features:              featuremgmt.WithFeatures(),
+			log:                   log.NewNopLogger(),
+		}
+
+		_, err := s.ImportBundle(context.Background(), &dashboardimport.ImportBundleRequest{
+			Dashboards:         bundle(t),
+			DatasourceMappings: mappings,
+			Overwrite:          true,
+			User:               signedInUser,
+		})
+		require.Error(t, err)
+		require.Same(t, previous, restored)
+	})
+
+	t.Run("should not create datasources without the permission to create them", func(t *testing.T) {
+		folderService := &folderServiceMock{FakeService: foldertest.NewFakeService()}
+		datasourceService := &fakeDatasources.FakeDataSourceService{}
+		s := &ImportDashboardService{
+			dashboardService: &dashboardServiceMock{
+				importDashboardFunc: func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
+					t.Fatal("dashboard must not be saved")
+					return nil, nil
+				},
+			},
+			libraryPanelService:   &libraryPanelServiceMock{},
+			libraryElementService: &libraryElementServiceMock{},
+			folderService:         folderService,
+			datasourceService:     datasourceService,
+			ac:                    actest.FakeAccessControl{ExpectedEvaluate: false},
+			features:              featuremgmt.WithFeatures(),
+			log:                   log.NewNopLogger(),
+		}
+
+		_, err := s.ImportBundle(context.Background(), &dashboardimport.ImportBundleRequest{
+			Dashboards:         bundle(t),
+			DatasourceMappings: mappings,
+			User:               signedInUser,
+		})
+		require.ErrorIs(t, err, dashboardimport.ErrDatasourceCreateForbidden)
+		require.Empty(t, folderService.created)
+		require.Empty(t, datasourceService.DataSources)
+	})
+
+	t.Run("should fail without dashboards", func(t *testing.T) {
+		s := &ImportDashboardService{features: featuremgmt.WithFeatures()}
+		_, err := s.ImportBundle(context.Background(), &dashboardimport.ImportBundleRequest{User: signedInUser})
+		require.Error(t, err)
+	})
+}
+
+type folderServiceMock struct {
+	*foldertest.FakeService
+	created []*folder.CreateFolderCommand
+	deleted []string
+}
+
+func (s *folderServiceMock) Create(ctx context.Context, cmd *folder.CreateFolderCommand) (*folder.Folder, error) {
+	s.created = append(s.created, cmd)
+	return &folder.Folder{ID: int64(len(s.created)), UID: cmd.UID, Title: cmd.Title, ParentUID: cmd.ParentUID, OrgID: cmd.OrgID}, nil
+}
+
+func (s *folderServiceMock) Delete(ctx context.Context, cmd *folder.DeleteFolderCommand) error {
+	s.deleted = append(s.deleted, cmd.UID)
+	return nil
+}
+
+type libraryElementServiceMock struct {
+	libraryelements.Service
+	// elements are the names of the existing library panels by uid
+	elements map[string]string
+	deleted  []string
+}
+
+func (s *libraryElementServiceMock) GetElement(c context.Context, signedInUser identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error) {
+	name, ok := s.elements[cmd.UID]
+	if !ok {
+		return model.LibraryElementDTO{}, model.ErrLibraryElementNotFound
+	}
+	return model.LibraryElementDTO{UID: cmd.UID, Name: name}, nil
+}
+
+func (s *libraryElementServiceMock) DeleteLibraryElement(c context.Context, signedInUser identity.Requester, uid string) (int64, error) {
+	s.deleted = append(s.deleted, uid)
+	return 0, nil
+}
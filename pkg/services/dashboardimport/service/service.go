@@ -2,19 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/api/routing"
-	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboardimport"
 	"github.com/grafana/grafana/pkg/services/dashboardimport/api"
 	"github.com/grafana/grafana/pkg/services/dashboardimport/utils"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/plugindashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
@@ -26,13 +29,18 @@ func ProvideService(routeRegister routing.RouteRegister,
 	pluginDashboardService plugindashboards.Service, pluginStore pluginstore.Store,
 	libraryPanelService librarypanels.Service, dashboardService dashboards.DashboardService,
 	ac accesscontrol.AccessControl, folderService folder.Service, features featuremgmt.FeatureToggles,
+	libraryElementService libraryelements.Service, datasourceService datasources.DataSourceService,
 ) *ImportDashboardService {
 	s := &ImportDashboardService{
 		pluginDashboardService: pluginDashboardService,
 		dashboardService:       dashboardService,
 		libraryPanelService:    libraryPanelService,
+		libraryElementService:  libraryElementService,
 		folderService:          folderService,
+		datasourceService:      datasourceService,
+		ac:                     ac,
 		features:               features,
+		log:                    log.New("dashboard-import"),
 	}
 
 	dashboardImportAPI := api.New(s, quotaService, pluginStore, ac, features)
@@ -45,11 +53,31 @@ type ImportDashboardService struct {
 	pluginDashboardService plugindashboards.Service
 	dashboardService       dashboards.DashboardService
 	libraryPanelService    librarypanels.Service
+	libraryElementService  libraryelements.Service
 	folderService          folder.Service
+	datasourceService      datasources.DataSourceService
+	ac                     accesscontrol.AccessControl
 	features               featuremgmt.FeatureToggles
+	log                    log.Logger
 }
 
 func (s *ImportDashboardService) InterpolateDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*simplejson.Json, error) {
+	template, err := s.loadDashboard(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := utils.NewDashTemplateEvaluator(template, req.Inputs)
+	generatedDash, err := evaluator.Eval()
+	if err != nil {
+		return nil, err
+	}
+
+	return generatedDash, nil
+}
+
+// loadDashboard returns the dashboard of the request or of the plugin it references.
+func (s *ImportDashboardService) loadDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*simplejson.Json, error) {
 	var draftDashboard *dashboards.Dashboard
 	if req.PluginId != "" {
 		loadReq := &plugindashboards.LoadPluginDashboardRequest{
@@ -67,37 +95,16 @@ func (s *ImportDashboardService) InterpolateDashboard(ctx context.Context, req *
 		return nil, fmt.Errorf("either PluginId or Dashboard must be provided")
 	}
 
-	evaluator := utils.NewDashTemplateEvaluator(draftDashboard.Data, req.Inputs)
-	generatedDash, err := evaluator.Eval()
-	if err != nil {
-		return nil, err
-	}
-
-	return generatedDash, nil
+	return draftDashboard.Data, nil
 }
 
 func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashboardimport.ImportDashboardRequest) (*dashboardimport.ImportDashboardResponse, error) {
-	generatedDash, err := s.InterpolateDashboard(ctx, req)
+	template, err := s.loadDashboard(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Maintain backwards compatibility by transforming array of library elements to map
-	libraryElements := generatedDash.Get("__elements")
-	libElementsArr, err := libraryElements.Array()
-	if err == nil {
-		elementMap := map[string]any{}
-		for _, el := range libElementsArr {
-			libElement := simplejson.NewFromAny(el)
-			elementMap[libElement.Get("uid").MustString()] = el
-		}
-		libraryElements = simplejson.NewFromAny(elementMap)
-	}
-
-	// No need to keep these in the stored dashboard JSON
-	generatedDash.Del("__elements")
-	generatedDash.Del("__inputs")
-	generatedDash.Del("__requires")
+	plan := newImportPlan(req.User, req.Inputs, req.DatasourceMappings, req.Overwrite)
 
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.DashboardImport).Inc()
 	// here we need to get FolderId from FolderUID if it present in the request, if both exist, FolderUID would overwrite FolderID
@@ -107,11 +114,15 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 			UID:          &req.FolderUid,
 			SignedInUser: req.User,
 		})
-		if err != nil {
+		switch {
+		case err == nil:
+			// nolint:staticcheck
+			req.FolderId = folder.ID
+		case req.FolderTitle != "" && errors.Is(err, dashboards.ErrFolderNotFound):
+			plan.createFolder(req.FolderUid, req.FolderTitle, "")
+		default:
 			return nil, err
 		}
-		// nolint:staticcheck
-		req.FolderId = folder.ID
 	} else {
 		folder, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
 			ID:           &req.FolderId, // nolint:staticcheck
@@ -123,56 +134,93 @@ func (s *ImportDashboardService) ImportDashboard(ctx context.Context, req *dashb
 		}
 		req.FolderUid = folder.UID
 	}
+	plan.folderIDs[req.FolderUid] = req.FolderId // nolint:staticcheck
 
-	var userID int64
-	if id, err := identity.UserIdentifier(req.User.GetID()); err == nil {
-		userID = id
+	planned := &plannedDashboard{path: req.Path, pluginID: req.PluginId, folderUID: req.FolderUid}
+	if err := s.planDashboard(ctx, plan, template, planned); err != nil {
+		return nil, err
 	}
-
-	saveCmd := dashboards.SaveDashboardCommand{
-		Dashboard: generatedDash,
-		OrgID:     req.User.GetOrgID(),
-		UserID:    userID,
-		Overwrite: req.Overwrite,
-		PluginID:  req.PluginId,
-		FolderID:  req.FolderId, // nolint:staticcheck
-		FolderUID: req.FolderUid,
+	if err := s.resolveLibraryPanels(ctx, plan); err != nil {
+		return nil, err
 	}
 
-	dto := &dashboards.SaveDashboardDTO{
-		OrgID:     saveCmd.OrgID,
-		Dashboard: saveCmd.GetDashboardModel(),
-		Overwrite: saveCmd.Overwrite,
-		User:      req.User,
+	if req.DryRun {
+		resp := importResponse(planned, nil)
+		resp.Changes = plan.changes
+		resp.DryRun = true
+		return &resp, nil
 	}
 
-	// nolint:staticcheck
-	err = s.libraryPanelService.ImportLibraryPanelsForDashboard(ctx, req.User, libraryElements, generatedDash.Get("panels").MustArray(), req.FolderId, req.FolderUid)
+	saved, err := s.applyPlan(ctx, plan)
 	if err != nil {
 		return nil, err
 	}
+	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.DashboardImport).Inc()
 
-	savedDashboard, err := s.dashboardService.ImportDashboard(ctx, dto)
-	if err != nil {
+	resp := importResponse(planned, saved[0])
+	resp.Changes = plan.changes
+	return &resp, nil
+}
+
+// ImportBundle imports interdependent dashboards at once. The folders of the dashboards are resolved by title below
+// the folder of the bundle, and the dependencies of all dashboards are resolved before anything is written.
+func (s *ImportDashboardService) ImportBundle(ctx context.Context, req *dashboardimport.ImportBundleRequest) (*dashboardimport.ImportBundleResponse, error) {
+	if len(req.Dashboards) == 0 {
+		return nil, fmt.Errorf("%w: no dashboards", utils.ErrInvalidBundle)
+	}
+
+	plan := newImportPlan(req.User, req.Inputs, req.DatasourceMappings, req.Overwrite)
+	if req.FolderUid != "" {
+		folder, err := s.folderService.Get(ctx, &folder.GetFolderQuery{
+			OrgID:        req.User.GetOrgID(),
+			UID:          &req.FolderUid,
+			SignedInUser: req.User,
+		})
+		if err != nil {
+			return nil, err
+		}
+		plan.folderIDs[folder.UID] = folder.ID // nolint:staticcheck
+	}
+
+	for _, d := range req.Dashboards {
+		if d.Dashboard == nil {
+			return nil, fmt.Errorf("%w: %s has no dashboard", utils.ErrInvalidBundle, d.Path)
+		}
+		folderUID := req.FolderUid
+		for _, title := range d.Folders {
+			var err error
+			folderUID, err = s.resolveFolder(ctx, plan, folderUID, title)
+			if err != nil {
+				return nil, err
+			}
+		}
+		planned := &plannedDashboard{path: d.Path, folderUID: folderUID}
+		if err := s.planDashboard(ctx, plan, d.Dashboard, planned); err != nil {
+			return nil, fmt.Errorf("%s: %w", d.Path, err)
+		}
+	}
+	if err := s.resolveLibraryPanels(ctx, plan); err != nil {
 		return nil, err
 	}
-	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.DashboardImport).Inc()
 
-	revision := savedDashboard.Data.Get("revision").MustInt64(0)
-	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.DashboardImport).Inc()
-	return &dashboardimport.ImportDashboardResponse{
-		UID:              savedDashboard.UID,
-		PluginId:         req.PluginId,
-		Title:            savedDashboard.Title,
-		Path:             req.Path,
-		Revision:         revision,                // only used for plugin version tracking
-		FolderId:         savedDashboard.FolderID, // nolint:staticcheck
-		FolderUID:        req.FolderUid,
-		ImportedUri:      "db/" + savedDashboard.Slug,
-		ImportedUrl:      savedDashboard.GetURL(),
-		ImportedRevision: revision,
-		Imported:         true,
-		DashboardId:      savedDashboard.ID,
-		Slug:             savedDashboard.Slug,
-	}, nil
+	resp := &dashboardimport.ImportBundleResponse{
+		Dashboards: make([]dashboardimport.ImportDashboardResponse, 0, len(plan.dashboards)),
+		Changes:    plan.changes,
+		DryRun:     req.DryRun,
+	}
+	if req.DryRun {
+		for _, planned := range plan.dashboards {
+			resp.Dashboards = append(resp.Dashboards, importResponse(planned, nil))
+		}
+		return resp, nil
+	}
+
+	saved, err := s.applyPlan(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	for i, savedDashboard := range saved {
+		resp.Dashboards = append(resp.Dashboards, importResponse(plan.dashboards[i], savedDashboard))
+	}
+	return resp, nil
 }
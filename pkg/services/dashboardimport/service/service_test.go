@@ -347,6 +347,24 @@ func (m *pluginDashboardServiceMock) LoadPluginDashboard(ctx context.Context, re
 type dashboardServiceMock struct {
 	dashboards.DashboardService
 	importDashboardFunc func(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error)
+	getDashboardFunc    func(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error)
+	deleteDashboardFunc func(ctx context.Context, dashboardId int64, dashboardUID string, orgId int64) error
+}
+
+func (s *dashboardServiceMock) GetDashboard(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	if s.getDashboardFunc != nil {
+		return s.getDashboardFunc(ctx, query)
+	}
+
+	return nil, dashboards.ErrDashboardNotFound
+}
+
+func (s *dashboardServiceMock) DeleteDashboard(ctx context.Context, dashboardId int64, dashboardUID string, orgId int64) error {
+	if s.deleteDashboardFunc != nil {
+		return s.deleteDashboardFunc(ctx, dashboardId, dashboardUID, orgId)
+	}
+
+	return nil
 }
 
 func (s *dashboardServiceMock) ImportDashboard(ctx context.Context, dto *dashboards.SaveDashboardDTO) (*dashboards.Dashboard, error) {
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardimport"
+)
+
+const (
+	// maxBundleFileSize is the maximum size of a dashboard of a bundle.
+	maxBundleFileSize = 10 << 20
+	// maxBundleDecompressedSize is the maximum size of all the dashboards of a bundle, so a small zip archive cannot
+	// expand to an unbounded amount of memory.
+	maxBundleDecompressedSize = 100 << 20
+)
+
+var ErrInvalidBundle = errors.New("invalid dashboard bundle")
+
+// ReadBundle reads the dashboards of a bundle from a zip archive or a directory. Every JSON file is a dashboard,
+// and the directories it is in are the folders it is imported in. Hidden files and directories are ignored.
+func ReadBundle(fsys fs.FS) ([]dashboardimport.ImportBundleDashboard, error) {
+	var bundle []dashboardimport.ImportBundleDashboard
+	remaining := int64(maxBundleDecompressedSize)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "__")) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path.Ext(p) != ".json" {
+			return nil
+		}
+
+		dashboard, err := readBundleDashboard(fsys, p, &remaining)
+		if err != nil {
+			return err
+		}
+		var folders []string
+		if dir := path.Dir(p); dir != "." {
+			folders = strings.Split(dir, "/")
+		}
+		bundle = append(bundle, dashboardimport.ImportBundleDashboard{
+			Path:      p,
+			Folders:   folders,
+			Dashboard: dashboard,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(bundle) == 0 {
+		return nil, fmt.Errorf("%w: no dashboards", ErrInvalidBundle)
+	}
+
+	sort.Slice(bundle, func(i, j int) bool {
+		return bundle[i].Path < bundle[j].Path
+	})
+	return bundle, nil
+}
+
+// readBundleDashboard reads a dashboard of a bundle, counting its size against the remaining size of the bundle.
+// The sizes declared in zip archives are not trusted, only the bytes actually decompressed are counted.
+func readBundleDashboard(fsys fs.FS, p string, remaining *int64) (*simplejson.Json, error) {
+	f, err := fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	limit := min(int64(maxBundleFileSize), *remaining)
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		if limit < maxBundleFileSize {
+			return nil, fmt.Errorf("%w: bundle is larger than %d bytes once decompressed", ErrInvalidBundle, maxBundleDecompressedSize)
+		}
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrInvalidBundle, p, maxBundleFileSize)
+	}
+	*remaining -= int64(len(data))
+
+	dashboard, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidBundle, p, err)
+	}
+	// dashboards exported from the API are wrapped with their metadata
+	if inner, ok := dashboard.CheckGet("dashboard"); ok {
+		if _, hasMeta := dashboard.CheckGet("meta"); hasMeta {
+			dashboard = inner
+		}
+	}
+	if _, err := dashboard.Map(); err != nil {
+		return nil, fmt.Errorf("%w: %s is not a dashboard", ErrInvalidBundle, p)
+	}
+	return dashboard, nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBundle(t *testing.T) {
+	t.Run("should read the dashboards with the folders they are in", func(t *testing.T) {
+		bundle, err := ReadBundle(fstest.MapFS{
+			"home.json":                      {Data: []byte(`{"uid": "home", "title": "Home"}`)},
+			"team-a/service.json":            {Data: []byte(`{"dashboard": {"uid": "service"}, "meta": {"slug": "service"}}`)},
+			"team-a/databases/postgres.json": {Data: []byte(`{"uid": "postgres"}`)},
+			"team-a/README.md":               {Data: []byte(`# Team A`)},
+			".git/config.json":               {Data: []byte(`{}`)},
+			"__MACOSX/team-a/._service.json": {Data: []byte{0, 1}},
+			"team-a/.hidden-dashboard.json":  {Data: []byte(`{}`)},
+		})
+		require.NoError(t, err)
+		require.Len(t, bundle, 3)
+
+		require.Equal(t, "home.json", bundle[0].Path)
+		require.Empty(t, bundle[0].Folders)
+		require.Equal(t, "Home", bundle[0].Dashboard.Get("title").MustString())
+
+		require.Equal(t, "team-a/databases/postgres.json", bundle[1].Path)
+		require.Equal(t, []string{"team-a", "databases"}, bundle[1].Folders)
+
+		require.Equal(t, []string{"team-a"}, bundle[2].Folders)
+		require.Equal(t, "service", bundle[2].Dashboard.Get("uid").MustString())
+	})
+
+	t.Run("should fail on invalid dashboards", func(t *testing.T) {
+		_, err := ReadBundle(fstest.MapFS{"broken.json": {Data: []byte(`{`)}})
+		require.ErrorIs(t, err, ErrInvalidBundle)
+
+		_, err = ReadBundle(fstest.MapFS{"list.json": {Data: []byte(`[]`)}})
+		require.ErrorIs(t, err, ErrInvalidBundle)
+	})
+
+	t.Run("should fail without dashboards", func(t *testing.T) {
+		_, err := ReadBundle(fstest.MapFS{"README.md": {Data: []byte(`empty`)}})
+		require.ErrorIs(t, err, ErrInvalidBundle)
+	})
+
+	t.Run("should fail when the bundle is too large once decompressed", func(t *testing.T) {
+		// every file is within the file limit, but together they exceed the bundle limit
+		prefix, suffix := `{"uid": "large", "description": "`, `"}`
+		data := []byte(prefix + strings.Repeat("a", maxBundleFileSize-len(prefix)-len(suffix)) + suffix)
+		fsys := fstest.MapFS{}
+		for i := 0; i <= maxBundleDecompressedSize/maxBundleFileSize; i++ {
+			fsys[fmt.Sprintf("dashboard-%02d.json", i)] = &fstest.MapFile{Data: data}
+		}
+
+		_, err := ReadBundle(fsys)
+		require.ErrorIs(t, err, ErrInvalidBundle)
+		require.ErrorContains(t, err, "once decompressed")
+	})
+}
@@ -62,6 +62,44 @@ func TestParseResults(t *testing.T) {
 		require.Equal(t, "description", results.Hits[0].Description)
 	})
 
+	t.Run("should parse highlighted fragments", func(t *testing.T) {
+		resSearchResp := &resourcepb.ResourceSearchResponse{
+			Results: &resourcepb.ResourceTable{
+				Columns: []*resourcepb.ResourceTableColumnDefinition{
+					{
+						Name: "title",
+						Type: resourcepb.ResourceTableColumnDefinition_STRING,
+					},
+					{
+						Name: resource.SEARCH_FIELD_HIGHLIGHT,
+						Type: resourcepb.ResourceTableColumnDefinition_OBJECT,
+					},
+				},
+				Rows: []*resourcepb.ResourceTableRow{
+					{
+						Key: &resourcepb.ResourceKey{
+							Name:     "uid",
+							Resource: "dashboard",
+						},
+						Cells: [][]byte{
+							[]byte("Dashboard 1"),
+							[]byte(`{"panel_query":["sum(rate(<mark>http_requests_total</mark>[5m]))"]}`),
+						},
+					},
+				},
+			},
+			TotalHits: 1,
+		}
+
+		results, err := ParseResults(resSearchResp, 0)
+		require.NoError(t, err)
+		require.Len(t, results.Hits, 1)
+		require.Equal(t, map[string][]string{
+			builders.DASHBOARD_PANEL_QUERY: {"sum(rate(<mark>http_requests_total</mark>[5m]))"},
+		}, results.Hits[0].Highlight)
+		require.Nil(t, results.Hits[0].Field.Object[resource.SEARCH_FIELD_HIGHLIGHT])
+	})
+
 	t.Run("should return error when trying to parse results with mismatch length between Columns and row Cells", func(t *testing.T) {
 		resSearchResp := &resourcepb.ResourceSearchResponse{
 			Results: &resourcepb.ResourceTable{
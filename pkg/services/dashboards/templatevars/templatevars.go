@@ -0,0 +1,79 @@
+// Package templatevars interpolates the template variables of dashboards into the queries of their panels.
+package templatevars
+
+import (
+	"regexp"
+	"strings"
+)
+
+// variableRegex matches the $var, ${var}, ${var:format} and [[var]] variable syntaxes
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+)\]\]|\$\{(\w+)(?::(\w+))?\}`)
+
+// Interpolate returns a copy of a query model with the variables replaced by their values in all its strings.
+// Unknown variables, including the global variables resolved by the datasources, are kept.
+func Interpolate(value any, variables map[string][]string) any {
+	switch v := value.(type) {
+	case string:
+		return variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			groups := variableRegex.FindStringSubmatch(match)
+			name := groups[1] + groups[2] + groups[3]
+			values, ok := variables[name]
+			if !ok {
+				return match
+			}
+			return Format(values, groups[4])
+		})
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = Interpolate(item, variables)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = Interpolate(item, variables)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// Format formats the values of a multi-value variable, the values of single-value variables are kept as is
+// except by the regex format.
+func Format(values []string, format string) string {
+	if len(values) == 1 && format != "regex" {
+		return values[0]
+	}
+
+	switch format {
+	case "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = regexp.QuoteMeta(value)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteValues(values, "'")
+	case "doublequote":
+		return quoteValues(values, `"`)
+	default:
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
+
+func quoteValues(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote + strings.ReplaceAll(value, quote, `\`+quote) + quote
+	}
+	return strings.Join(quoted, ",")
+}
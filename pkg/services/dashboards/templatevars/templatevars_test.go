@@ -0,0 +1,27 @@
+package templatevars
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	values := []string{"a", "b.c"}
+	assert.Equal(t, "{a,b.c}", Format(values, ""))
+	assert.Equal(t, "a,b.c", Format(values, "csv"))
+	assert.Equal(t, "a|b.c", Format(values, "pipe"))
+	assert.Equal(t, `(a|b\.c)`, Format(values, "regex"))
+	assert.Equal(t, `"a","b.c"`, Format(values, "doublequote"))
+	assert.Equal(t, "a", Format([]string{"a"}, ""))
+}
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string][]string{"x": {"foo"}, "y": {"bar"}}
+	assert.Equal(t, "foo bar [[a]] $__interval", Interpolate("$x [[y]] [[a]] $__interval", variables))
+
+	query := map[string]any{"expr": "up{job=\"${x}\"}", "nested": []any{"$y", 1}}
+	assert.Equal(t, map[string]any{"expr": "up{job=\"foo\"}", "nested": []any{"bar", 1}}, Interpolate(query, variables))
+	// the query model is not modified
+	assert.Equal(t, map[string]any{"expr": "up{job=\"${x}\"}", "nested": []any{"$y", 1}}, query)
+}
@@ -4,4 +4,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 )
 
-var ErrBaseNotFound = errutil.NotFound("dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+var (
+	ErrBaseNotFound            = errutil.NotFound("dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+	ErrUnsupportedExportFormat = errutil.BadRequest("dashboardsnapshots.unsupported-export-format", errutil.WithPublicMessage("Unsupported snapshot export format"))
+)
@@ -0,0 +1,389 @@
+package dashboardsnapshots
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+)
+
+const (
+	// ExportFormatJSON exports the snapshot dashboard with the data embedded in its panels.
+	ExportFormatJSON = "json"
+	// ExportFormatCSV exports a zip archive with a CSV file per panel data frame.
+	ExportFormatCSV = "csv"
+	// ExportFormatParquet exports a Parquet file with a row per value of the panels data frames.
+	ExportFormatParquet = "parquet"
+)
+
+var ExportFormats = []string{ExportFormatJSON, ExportFormatCSV, ExportFormatParquet}
+
+// ExportContentType returns the content type and the file extension of an export format.
+func ExportContentType(format string) (string, string) {
+	switch format {
+	case ExportFormatCSV:
+		return "application/zip", "zip"
+	case ExportFormatParquet:
+		return "application/vnd.apache.parquet", "parquet"
+	default:
+		return "application/json", "json"
+	}
+}
+
+// Export writes the dashboard of a snapshot in the given format. Only the data embedded in the panels is
+// exported by the CSV and Parquet formats.
+func Export(w io.Writer, dashboard *simplejson.Json, format string) error {
+	switch format {
+	case ExportFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dashboard)
+	case ExportFormatCSV:
+		return exportCSV(w, snapshotPanels(dashboard))
+	case ExportFormatParquet:
+		return exportParquet(w, snapshotPanels(dashboard))
+	default:
+		return ErrUnsupportedExportFormat.Errorf("unsupported export format %q", format)
+	}
+}
+
+type exportPanel struct {
+	id     int64
+	title  string
+	frames []exportFrame
+}
+
+type exportFrame struct {
+	name   string
+	refID  string
+	fields []exportField
+}
+
+type exportField struct {
+	name   string
+	typ    string
+	labels map[string]string
+	values []any
+}
+
+// displayName returns the name of the field followed by its labels, as displayed in tables.
+func (f exportField) displayName() string {
+	if len(f.labels) == 0 {
+		return f.name
+	}
+	return f.name + "{" + formatLabels(f.labels) + "}"
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// timeField returns the index of the first time field of the frame, or -1.
+func (f exportFrame) timeField() int {
+	for i, field := range f.fields {
+		if field.typ == "time" {
+			return i
+		}
+	}
+	return -1
+}
+
+// snapshotPanels returns the panels of the dashboard, including the panels of collapsed rows, with the data
+// frames embedded in their snapshotData.
+func snapshotPanels(dashboard *simplejson.Json) []exportPanel {
+	panels := make([]exportPanel, 0)
+	var walk func(list []any)
+	walk = func(list []any) {
+		for _, obj := range list {
+			panel := simplejson.NewFromAny(obj)
+			if panel.Get("type").MustString() == "row" {
+				walk(panel.Get("panels").MustArray())
+				continue
+			}
+
+			p := exportPanel{
+				id:    panel.Get("id").MustInt64(),
+				title: panel.Get("title").MustString(),
+			}
+			for _, data := range panel.Get("snapshotData").MustArray() {
+				p.frames = append(p.frames, parseFrame(simplejson.NewFromAny(data)))
+			}
+			if len(p.frames) > 0 {
+				panels = append(panels, p)
+			}
+		}
+	}
+	walk(dashboard.Get("panels").MustArray())
+	return panels
+}
+
+// parseFrame parses a data frame serialized by the frontend, or the series of a legacy snapshot.
+func parseFrame(data *simplejson.Json) exportFrame {
+	if datapoints, ok := data.CheckGet("datapoints"); ok {
+		frame := exportFrame{
+			name:   data.Get("target").MustString(),
+			refID:  data.Get("refId").MustString(),
+			fields: []exportField{{name: "Time", typ: "time"}, {name: "Value", typ: "number"}},
+		}
+		for _, point := range datapoints.MustArray() {
+			pair, ok := point.([]any)
+			if !ok || len(pair) != 2 {
+				continue
+			}
+			frame.fields[0].values = append(frame.fields[0].values, pair[1])
+			frame.fields[1].values = append(frame.fields[1].values, pair[0])
+		}
+		return frame
+	}
+
+	frame := exportFrame{
+		name:  data.Get("name").MustString(),
+		refID: data.Get("refId").MustString(),
+	}
+	for _, obj := range data.Get("fields").MustArray() {
+		field := simplejson.NewFromAny(obj)
+		f := exportField{
+			name:   field.Get("name").MustString(),
+			typ:    field.Get("type").MustString("other"),
+			values: field.Get("values").MustArray(),
+		}
+		if labels := field.Get("labels").MustMap(); len(labels) > 0 {
+			f.labels = make(map[string]string, len(labels))
+			for k, v := range labels {
+				f.labels[k] = fmt.Sprint(v)
+			}
+		}
+		frame.fields = append(frame.fields, f)
+	}
+	return frame
+}
+
+func exportCSV(w io.Writer, panels []exportPanel) error {
+	archive := zip.NewWriter(w)
+	names := make(map[string]int)
+	for _, panel := range panels {
+		for i, frame := range panel.frames {
+			name := fmt.Sprintf("%d-%s", panel.id, slugify.Slugify(panel.title))
+			if len(panel.frames) > 1 {
+				name = fmt.Sprintf("%s-%d", name, i+1)
+			}
+			// panels of repeated rows share their title and ID
+			if n := names[name]; n > 0 {
+				names[name]++
+				name = fmt.Sprintf("%s-%d", name, n)
+			} else {
+				names[name] = 1
+			}
+
+			file, err := archive.Create(name + ".csv")
+			if err != nil {
+				return err
+			}
+			if err := writeFrameCSV(file, frame); err != nil {
+				return err
+			}
+		}
+	}
+	return archive.Close()
+}
+
+func writeFrameCSV(w io.Writer, frame exportFrame) error {
+	out := csv.NewWriter(w)
+	header := make([]string, 0, len(frame.fields))
+	rows := 0
+	for _, field := range frame.fields {
+		header = append(header, field.displayName())
+		rows = max(rows, len(field.values))
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(frame.fields))
+	for row := 0; row < rows; row++ {
+		for i, field := range frame.fields {
+			record[i] = ""
+			if row < len(field.values) {
+				record[i] = formatValue(field.typ, field.values[row])
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func formatValue(typ string, value any) string {
+	if value == nil {
+		return ""
+	}
+	if typ == "time" {
+		if ms, ok := toFloat(value); ok {
+			return time.UnixMilli(int64(ms)).UTC().Format(time.RFC3339Nano)
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+var parquetSchema = arrow.NewSchema([]arrow.Field{
+	{Name: "panel_id", Type: arrow.PrimitiveTypes.Int64},
+	{Name: "panel_title", Type: arrow.BinaryTypes.String},
+	{Name: "ref_id", Type: arrow.BinaryTypes.String},
+	{Name: "frame", Type: arrow.BinaryTypes.String},
+	{Name: "field", Type: arrow.BinaryTypes.String},
+	{Name: "labels", Type: arrow.BinaryTypes.String, Nullable: true},
+	{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "UTC"}, Nullable: true},
+	{Name: "number", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	{Name: "text", Type: arrow.BinaryTypes.String, Nullable: true},
+}, nil)
+
+// exportParquet writes the values of the data frames in long format: a row per value of the fields other than
+// the time field, with the time of the row when the frame has a time field.
+func exportParquet(w io.Writer, panels []exportPanel) error {
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Zstd))
+	writer, err := pqarrow.NewFileWriter(parquetSchema, w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return err
+	}
+
+	mem := memory.DefaultAllocator
+	var (
+		panelID    = array.NewInt64Builder(mem)
+		panelTitle = array.NewStringBuilder(mem)
+		refID      = array.NewStringBuilder(mem)
+		frameName  = array.NewStringBuilder(mem)
+		fieldName  = array.NewStringBuilder(mem)
+		labels     = array.NewStringBuilder(mem)
+		timestamp  = array.NewTimestampBuilder(mem, parquetSchema.Field(6).Type.(*arrow.TimestampType))
+		number     = array.NewFloat64Builder(mem)
+		text       = array.NewStringBuilder(mem)
+	)
+
+	for _, panel := range panels {
+		for _, frame := range panel.frames {
+			timeIdx := frame.timeField()
+			for i, field := range frame.fields {
+				if i == timeIdx {
+					continue
+				}
+				for row, value := range field.values {
+					panelID.Append(panel.id)
+					panelTitle.Append(panel.title)
+					refID.Append(frame.refID)
+					frameName.Append(frame.name)
+					fieldName.Append(field.name)
+					if len(field.labels) > 0 {
+						labels.Append(formatLabels(field.labels))
+					} else {
+						labels.AppendNull()
+					}
+
+					var ms float64
+					hasTime := false
+					if timeIdx >= 0 && row < len(frame.fields[timeIdx].values) {
+						ms, hasTime = toFloat(frame.fields[timeIdx].values[row])
+					}
+					if hasTime {
+						timestamp.Append(arrow.Timestamp(int64(ms)))
+					} else {
+						timestamp.AppendNull()
+					}
+
+					f, isNumber := toFloat(value)
+					switch {
+					case isNumber && field.typ != "string" && !math.IsNaN(f):
+						number.Append(f)
+						text.AppendNull()
+					case value != nil:
+						number.AppendNull()
+						text.Append(formatValue(field.typ, value))
+					default:
+						number.AppendNull()
+						text.AppendNull()
+					}
+				}
+			}
+		}
+	}
+
+	// NewArray resets the builders
+	rows := int64(panelID.Len())
+	rec := array.NewRecordBatch(parquetSchema, []arrow.Array{
+		panelID.NewArray(),
+		panelTitle.NewArray(),
+		refID.NewArray(),
+		frameName.NewArray(),
+		fieldName.NewArray(),
+		labels.NewArray(),
+		timestamp.NewArray(),
+		number.NewArray(),
+		text.NewArray(),
+	}, rows)
+	defer rec.Release()
+	if err := writer.Write(rec); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
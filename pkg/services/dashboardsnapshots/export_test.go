@@ -0,0 +1,92 @@
+package dashboardsnapshots
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const exportTestDashboard = `{
+	"title": "Report",
+	"panels": [
+		{"id": 1, "title": "Requests", "type": "timeseries", "snapshotData": [{
+			"refId": "A",
+			"name": "requests",
+			"fields": [
+				{"name": "time", "type": "time", "values": [1714521600000, 1714608000000]},
+				{"name": "value", "type": "number", "labels": {"env": "prod", "app": "api"}, "values": [1.5, null]}
+			]
+		}]},
+		{"id": 2, "type": "row", "collapsed": true, "panels": [
+			{"id": 3, "title": "Legacy graph", "type": "graph", "snapshotData": [
+				{"target": "cpu", "refId": "A", "datapoints": [[10, 1714521600000], [20, 1714608000000], [30, 1714694400000]]}
+			]}
+		]},
+		{"id": 4, "title": "Text", "type": "text"}
+	]
+}`
+
+func TestExport(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(exportTestDashboard))
+	require.NoError(t, err)
+
+	t.Run("should export a CSV file per data frame", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Export(&buf, dashboard, ExportFormatCSV))
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		files := make(map[string]string)
+		for _, f := range archive.File {
+			r, err := f.Open()
+			require.NoError(t, err)
+			content, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			files[f.Name] = string(content)
+		}
+
+		assert.Equal(t, map[string]string{
+			"1-requests.csv": "time,\"value{app=\"\"api\"\", env=\"\"prod\"\"}\"\n" +
+				"2024-05-01T00:00:00Z,1.5\n" +
+				"2024-05-02T00:00:00Z,\n",
+			"3-legacy-graph.csv": "Time,Value\n" +
+				"2024-05-01T00:00:00Z,10\n" +
+				"2024-05-02T00:00:00Z,20\n" +
+				"2024-05-03T00:00:00Z,30\n",
+		}, files)
+	})
+
+	t.Run("should export a Parquet row per value", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Export(&buf, dashboard, ExportFormatParquet))
+
+		reader, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		defer func() { _ = reader.Close() }()
+		assert.Equal(t, int64(5), reader.NumRows())
+		assert.Equal(t, 9, reader.MetaData().Schema.NumColumns())
+	})
+
+	t.Run("should export the dashboard as JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Export(&buf, dashboard, ExportFormatJSON))
+
+		exported, err := simplejson.NewJson(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, "Report", exported.Get("title").MustString())
+	})
+
+	t.Run("should reject unsupported formats", func(t *testing.T) {
+		err := Export(io.Discard, dashboard, "pdf")
+		require.ErrorIs(t, err, ErrUnsupportedExportFormat)
+	})
+}
@@ -19,6 +19,8 @@ type DashboardSnapshot struct {
 	External          bool
 	ExternalURL       string `xorm:"external_url"`
 	ExternalDeleteURL string `xorm:"external_delete_url"`
+	// ScheduleID is the ID of the schedule the snapshot was taken by, 0 for snapshots created by users
+	ScheduleID int64 `xorm:"schedule_id"`
 
 	Expires time.Time
 	Created time.Time
@@ -63,8 +65,9 @@ type CreateDashboardSnapshotCommand struct {
 	// required:false
 	DeleteKey string `json:"deleteKey"`
 
-	OrgID  int64 `json:"-"`
-	UserID int64 `json:"-"`
+	OrgID      int64 `json:"-"`
+	UserID     int64 `json:"-"`
+	ScheduleID int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
 }
@@ -0,0 +1,372 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
+)
+
+const (
+	defaultMaxDataPoints = 1000
+	// dashboardDatasourceUID is the datasource of panels reusing the results of other panels, which only the
+	// frontend can resolve
+	dashboardDatasourceUID = "-- Dashboard --"
+	mixedDatasourceUID     = "-- Mixed --"
+	// allVariableValue is the current value of variables with All selected
+	allVariableValue = "$__all"
+)
+
+// capture runs the queries of the panels of the dashboard over the time range of the schedule and returns a
+// copy of the dashboard with the results embedded in the panels, as the frontend does when sharing a snapshot.
+// The queries run as the owner of the schedule, panels whose queries fail, including the queries of datasources the
+// owner cannot query, are kept without data and their errors are returned.
+func (s *Service) capture(ctx context.Context, owner identity.Requester, schedule *Schedule, dashboard *dashboards.Dashboard, now time.Time) (*simplejson.Json, []string, error) {
+	if dashboard.Data.Get("elements").Interface() != nil {
+		return nil, nil, ErrDashboardSchema.Errorf("dashboard %s uses the v2 schema", dashboard.UID)
+	}
+
+	raw, err := dashboard.Data.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from, to, err := timeRange(schedule, snapshot, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = identity.WithRequester(ctx, owner)
+	variables := variableValues(snapshot)
+	panelErrors := make([]string, 0)
+	for _, panel := range panels(snapshot) {
+		queries := panelQueries(panel, variables, from, to)
+		if len(queries) == 0 {
+			continue
+		}
+
+		res, err := s.queryService.QueryData(ctx, owner, false, dtos.MetricRequest{
+			From:    strconv.FormatInt(from.UnixMilli(), 10),
+			To:      strconv.FormatInt(to.UnixMilli(), 10),
+			Queries: queries,
+		})
+		if err != nil {
+			panelErrors = append(panelErrors, fmt.Sprintf("panel %d: %s", panel.Get("id").MustInt64(), err))
+			continue
+		}
+
+		refIDs := make([]string, 0, len(res.Responses))
+		for refID := range res.Responses {
+			refIDs = append(refIDs, refID)
+		}
+		sort.Strings(refIDs)
+
+		frames := make([]any, 0)
+		for _, refID := range refIDs {
+			r := res.Responses[refID]
+			if r.Error != nil {
+				panelErrors = append(panelErrors, fmt.Sprintf("panel %d query %s: %s", panel.Get("id").MustInt64(), refID, r.Error))
+				continue
+			}
+			for _, frame := range r.Frames {
+				frames = append(frames, frameData(frame, refID))
+			}
+		}
+		panel.Set("snapshotData", frames)
+	}
+
+	freeze(snapshot, dashboard.UID, from, to, now)
+
+	// the frames hold values the exports cannot read until they are serialized
+	raw, err = snapshot.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot, err = simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return snapshot, panelErrors, nil
+}
+
+// timeRange returns the absolute time range of the schedule, or of the dashboard, at now.
+func timeRange(schedule *Schedule, dashboard *simplejson.Json, now time.Time) (time.Time, time.Time, error) {
+	from := schedule.TimeFrom
+	if from == "" {
+		from = dashboard.GetPath("time", "from").MustString("now-6h")
+	}
+	to := schedule.TimeTo
+	if to == "" {
+		to = dashboard.GetPath("time", "to").MustString("now")
+	}
+
+	// relative times such as now/M are rounded in the timezone of the dashboard
+	location, err := time.LoadLocation(dashboard.Get("timezone").MustString())
+	if err != nil {
+		location = time.UTC
+	}
+
+	tr := gtime.TimeRange{From: from, To: to, Now: now}
+	fromTime, err := tr.ParseFrom(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidSchedule.Build(ErrInvalidScheduleData(fmt.Sprintf("invalid time %q", from)))
+	}
+	toTime, err := tr.ParseTo(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidSchedule.Build(ErrInvalidScheduleData(fmt.Sprintf("invalid time %q", to)))
+	}
+	return fromTime, toTime, nil
+}
+
+// panels returns the panels of the dashboard including the panels of collapsed rows.
+func panels(dashboard *simplejson.Json) []*simplejson.Json {
+	result := make([]*simplejson.Json, 0)
+	var walk func(list []any)
+	walk = func(list []any) {
+		for _, obj := range list {
+			panel := simplejson.NewFromAny(obj)
+			if panel.Get("type").MustString() == "row" {
+				walk(panel.Get("panels").MustArray())
+				continue
+			}
+			result = append(result, panel)
+		}
+	}
+	walk(dashboard.Get("panels").MustArray())
+	return result
+}
+
+// variableValues returns the current values of the variables of the dashboard by name. When All is selected, the
+// values are the custom all value of the variable, or else all its options.
+func variableValues(dashboard *simplejson.Json) map[string][]string {
+	variables := make(map[string][]string)
+	for _, obj := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(obj)
+		name := variable.Get("name").MustString()
+		current, ok := variable.CheckGet("current")
+		if name == "" || !ok {
+			continue
+		}
+
+		values := stringValues(current.Get("value").Interface())
+		if slices.Contains(values, allVariableValue) {
+			values = nil
+			if allValue := variable.Get("allValue").MustString(); allValue != "" {
+				values = append(values, allValue)
+			} else {
+				for _, option := range variable.Get("options").MustArray() {
+					if value := simplejson.NewFromAny(option).Get("value").MustString(); value != allVariableValue {
+						values = append(values, value)
+					}
+				}
+			}
+		}
+		if len(values) > 0 {
+			variables[name] = values
+		}
+	}
+	return variables
+}
+
+// stringValues returns the value of a single-value variable, or the values of a multi-value variable.
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// panelQueries returns the queries of a panel to send to the query service, with the variables replaced by their
+// current values as the frontend does before querying. Hidden queries are skipped unless the panel has expressions,
+// which may use them.
+func panelQueries(panel *simplejson.Json, variables map[string][]string, from, to time.Time) []*simplejson.Json {
+	panelDatasource := templatevars.Interpolate(panel.Get("datasource").Interface(), variables)
+	if datasourceUID(panelDatasource) == dashboardDatasourceUID {
+		return nil
+	}
+
+	targets := panel.Get("targets").MustArray()
+	hasExpression := false
+	for _, target := range targets {
+		if expr.IsDataSource(datasourceUID(simplejson.NewFromAny(target).Get("datasource").Interface())) {
+			hasExpression = true
+		}
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	intervalMs := max(to.Sub(from).Milliseconds()/max(maxDataPoints, 1), 1)
+	if minInterval, err := gtime.ParseDuration(panel.Get("interval").MustString()); err == nil {
+		intervalMs = max(intervalMs, minInterval.Milliseconds())
+	}
+
+	queries := make([]*simplejson.Json, 0, len(targets))
+	for _, target := range targets {
+		t, ok := target.(map[string]any)
+		if !ok {
+			continue
+		}
+		query := simplejson.NewFromAny(templatevars.Interpolate(t, variables))
+		if !hasExpression && query.Get("hide").MustBool() {
+			continue
+		}
+
+		if query.Get("datasource").Interface() == nil {
+			if datasourceUID(panelDatasource) == mixedDatasourceUID {
+				continue
+			}
+			query.Set("datasource", panelDatasource)
+		}
+		if uid, ok := query.Get("datasource").Interface().(string); ok {
+			// datasources of old dashboards are referenced by a string
+			query.Set("datasource", map[string]any{"uid": uid})
+		}
+		if datasourceUID(query.Get("datasource").Interface()) == dashboardDatasourceUID {
+			continue
+		}
+
+		query.Set("intervalMs", intervalMs)
+		query.Set("maxDataPoints", maxDataPoints)
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func datasourceUID(datasource any) string {
+	switch ds := datasource.(type) {
+	case string:
+		return ds
+	case map[string]any:
+		uid, _ := ds["uid"].(string)
+		return uid
+	default:
+		return ""
+	}
+}
+
+// frameData serializes a data frame the way the frontend stores the data of snapshot panels.
+func frameData(frame *data.Frame, refID string) map[string]any {
+	fields := make([]any, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		values := make([]any, field.Len())
+		for i := range values {
+			if v, ok := field.ConcreteAt(i); ok {
+				values[i] = jsonValue(v)
+			}
+		}
+
+		f := map[string]any{
+			"name":   field.Name,
+			"type":   fieldType(field.Type()),
+			"config": map[string]any{},
+			"values": values,
+		}
+		if field.Config != nil {
+			f["config"] = field.Config
+		}
+		if len(field.Labels) > 0 {
+			f["labels"] = field.Labels
+		}
+		fields = append(fields, f)
+	}
+
+	result := map[string]any{
+		"refId":  refID,
+		"fields": fields,
+	}
+	if frame.Name != "" {
+		result["name"] = frame.Name
+	}
+	if frame.Meta != nil {
+		result["meta"] = frame.Meta
+	}
+	return result
+}
+
+func fieldType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	}
+	switch t.NonNullableType() {
+	case data.FieldTypeString:
+		return "string"
+	case data.FieldTypeBool:
+		return "boolean"
+	case data.FieldTypeEnum:
+		return "enum"
+	default:
+		return "other"
+	}
+}
+
+func jsonValue(v any) any {
+	switch value := v.(type) {
+	case time.Time:
+		return value.UnixMilli()
+	case float64:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			return nil
+		}
+	}
+	return v
+}
+
+// freeze makes the snapshot render the captured data only: the time range is absolute, variables keep their
+// current value and annotations are not queried.
+func freeze(snapshot *simplejson.Json, dashboardUID string, from, to, now time.Time) {
+	snapshot.Set("time", map[string]any{
+		"from": from.UTC().Format(time.RFC3339),
+		"to":   to.UTC().Format(time.RFC3339),
+	})
+	snapshot.Set("refresh", "")
+	snapshot.Set("snapshot", map[string]any{
+		"originalUrl": "/d/" + dashboardUID,
+		"timestamp":   now.UTC().Format(time.RFC3339),
+	})
+
+	for _, obj := range snapshot.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(obj)
+		variable.Set("query", "")
+		variable.Set("refresh", 0)
+		if current, ok := variable.CheckGet("current"); ok {
+			variable.Set("options", []any{current.Interface()})
+		} else {
+			variable.Set("options", []any{})
+		}
+	}
+
+	for _, obj := range snapshot.GetPath("annotations", "list").MustArray() {
+		simplejson.NewFromAny(obj).Set("snapshotData", []any{})
+	}
+}
@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestPanelQueries(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "ds", "type": "datasource", "current": {"value": "prom"}},
+			{"name": "job", "type": "query", "current": {"value": ["api", "web"]}},
+			{"name": "env", "type": "custom", "current": {"value": "$__all"}, "options": [{"value": "$__all"}, {"value": "dev"}, {"value": "prod"}]},
+			{"name": "region", "type": "query", "allValue": ".*", "current": {"value": ["$__all"]}},
+			{"name": "unset", "type": "textbox"}
+		]},
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "${ds}"}, "targets": [
+				{"refId": "A", "expr": "up{job=~\"${job:regex}\", env=~\"${env:pipe}\", region=~\"$region\", host=\"$unset\"}[$__interval]"}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	variables := variableValues(dashboard)
+	assert.Equal(t, map[string][]string{
+		"ds":     {"prom"},
+		"job":    {"api", "web"},
+		"env":    {"dev", "prod"},
+		"region": {".*"},
+	}, variables)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	panel := dashboard.Get("panels").GetIndex(0)
+	queries := panelQueries(panel, variables, from, from.Add(time.Hour))
+	require.Len(t, queries, 1)
+	assert.Equal(t, "prom", queries[0].GetPath("datasource", "uid").MustString())
+	assert.Equal(t, `up{job=~"(api|web)", env=~"dev|prod", region=~".*", host="$unset"}[$__interval]`, queries[0].Get("expr").MustString())
+
+	// the panel keeps its variables, the snapshot renders with their current values
+	assert.Equal(t, "${ds}", panel.GetPath("datasource", "uid").MustString())
+	assert.Contains(t, panel.Get("targets").GetIndex(0).Get("expr").MustString(), "${job:regex}")
+}
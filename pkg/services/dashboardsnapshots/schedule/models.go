@@ -0,0 +1,141 @@
+package schedule
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+)
+
+var (
+	ErrScheduleNotFound = errutil.NotFound("dashboardsnapshots.schedule-not-found", errutil.WithPublicMessage("Snapshot schedule not found"))
+	ErrDashboardSchema  = errutil.BadRequest("dashboardsnapshots.schedule-dashboard-schema", errutil.WithPublicMessage("Scheduled snapshots support dashboards with panels only"))
+	ErrScheduleOwner    = errutil.Forbidden("dashboardsnapshots.schedule-owner", errutil.WithPublicMessage("The owner of the snapshot schedule cannot take its snapshots"))
+
+	ErrInvalidSchedule = errutil.BadRequest("dashboardsnapshots.invalid-schedule").MustTemplate(
+		"invalid snapshot schedule: {{ .Public.reason }}",
+		errutil.WithPublic("Invalid snapshot schedule: {{ .Public.reason }}"),
+	)
+)
+
+func ErrInvalidScheduleData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{Public: map[string]any{"reason": reason}}
+}
+
+// Schedule takes snapshots of a dashboard by running the queries of its panels on the server, as the user who last
+// saved the schedule.
+type Schedule struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID   string `xorm:"uid" json:"uid"`
+	OrgID int64  `xorm:"org_id" json:"-"`
+
+	ScheduleSpec `xorm:"extends"`
+
+	CreatedBy int64      `xorm:"created_by" json:"-"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	NextRun   time.Time  `xorm:"next_run" json:"nextRun"`
+	LastRun   *time.Time `xorm:"last_run" json:"lastRun,omitempty"`
+	// LastError is the error of the last run, including the errors of the panels whose queries failed
+	LastError string `xorm:"last_error" json:"lastError,omitempty"`
+}
+
+func (s Schedule) TableName() string {
+	return "dashboard_snapshot_schedule"
+}
+
+// ScheduleSpec is the part of a schedule set by users.
+type ScheduleSpec struct {
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	// Name of the schedule and of its snapshots, defaults to the title of the dashboard
+	Name string `json:"name"`
+	// Cron is a standard cron expression or a descriptor such as @monthly, optionally prefixed with CRON_TZ=<zone>
+	Cron string `json:"cron"`
+	// TimeFrom and TimeTo are the time range of the snapshots, e.g. now-1M/M, and default to the time range of the dashboard
+	TimeFrom string `xorm:"time_from" json:"timeFrom,omitempty"`
+	TimeTo   string `xorm:"time_to" json:"timeTo,omitempty"`
+	// KeepVersions is the number of snapshots kept, older snapshots are deleted. 0 keeps all snapshots.
+	KeepVersions int `xorm:"keep_versions" json:"keepVersions"`
+	// Expires is the number of seconds after which each snapshot expires. 0 never expires.
+	Expires int64 `json:"expires"`
+	// ArchiveFormats are the formats each snapshot is exported in to the archive bucket
+	ArchiveFormats []string `xorm:"archive_formats" json:"archiveFormats,omitempty"`
+	Paused         bool     `json:"paused"`
+}
+
+func (s ScheduleSpec) validate(archiveConfigured bool) error {
+	if s.DashboardUID == "" {
+		return ErrInvalidSchedule.Build(ErrInvalidScheduleData("dashboard UID is required"))
+	}
+	if _, err := cron.ParseStandard(s.Cron); err != nil {
+		return ErrInvalidSchedule.Build(ErrInvalidScheduleData(fmt.Sprintf("invalid cron expression %q", s.Cron)))
+	}
+	for _, t := range []string{s.TimeFrom, s.TimeTo} {
+		if t == "" {
+			continue
+		}
+		tr := gtime.NewTimeRange(t, t)
+		if _, err := tr.ParseFrom(); err != nil {
+			return ErrInvalidSchedule.Build(ErrInvalidScheduleData(fmt.Sprintf("invalid time %q", t)))
+		}
+	}
+	if s.KeepVersions < 0 {
+		return ErrInvalidSchedule.Build(ErrInvalidScheduleData("keep versions cannot be negative"))
+	}
+	if s.Expires < 0 {
+		return ErrInvalidSchedule.Build(ErrInvalidScheduleData("expires cannot be negative"))
+	}
+	for _, f := range s.ArchiveFormats {
+		if !slices.Contains(dashboardsnapshots.ExportFormats, f) {
+			return ErrInvalidSchedule.Build(ErrInvalidScheduleData(fmt.Sprintf("unsupported archive format %q", f)))
+		}
+	}
+	if len(s.ArchiveFormats) > 0 && !archiveConfigured {
+		return ErrInvalidSchedule.Build(ErrInvalidScheduleData("the snapshot archive bucket is not configured"))
+	}
+	return nil
+}
+
+// nextRun returns the first time the cron expression of the schedule matches after t.
+func (s ScheduleSpec) nextRun(t time.Time) (time.Time, error) {
+	expr, err := cron.ParseStandard(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expr.Next(t).UTC(), nil
+}
+
+type CreateScheduleCommand struct {
+	ScheduleSpec
+
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+type UpdateScheduleCommand struct {
+	ScheduleSpec
+
+	UID    string `json:"-"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+}
+
+type ListSchedulesQuery struct {
+	OrgID int64
+	// DashboardUID filters the schedules of a dashboard when set
+	DashboardUID string
+}
+
+// RunResult is the outcome of a run of a schedule.
+type RunResult struct {
+	// Key of the snapshot taken
+	Key string `json:"key"`
+	URL string `json:"url"`
+	// Errors of the panels whose queries failed, the snapshot is taken without their data
+	PanelErrors []string `json:"panelErrors,omitempty"`
+}
@@ -0,0 +1,395 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gocloud.dev/blob"
+
+	snapshot "github.com/grafana/grafana/apps/dashboard/pkg/apis/dashboard/v0alpha1"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var _ registry.BackgroundService = (*Service)(nil)
+var _ registry.CanBeDisabled = (*Service)(nil)
+
+// Service manages the snapshot schedules and takes their snapshots when they are due.
+type Service struct {
+	cfg              *setting.Cfg
+	store            store
+	snapshots        dashboardsnapshots.Service
+	dashboardService dashboards.DashboardService
+	queryService     query.Service
+	userService      user.Service
+	acService        accesscontrol.Service
+	accessControl    accesscontrol.AccessControl
+	log              log.Logger
+	now              func() time.Time
+
+	bucketMu sync.Mutex
+	bucket   *blob.Bucket
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, snapshots dashboardsnapshots.Service, dashboardService dashboards.DashboardService,
+	queryService query.Service, userService user.Service, acService accesscontrol.Service, accessControl accesscontrol.AccessControl,
+) *Service {
+	return &Service{
+		cfg:              cfg,
+		store:            &xormStore{db: sqlStore},
+		snapshots:        snapshots,
+		dashboardService: dashboardService,
+		queryService:     queryService,
+		userService:      userService,
+		acService:        acService,
+		accessControl:    accessControl,
+		log:              log.New("dashboardsnapshots.schedule"),
+		now:              time.Now,
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.SnapshotEnabled
+}
+
+func (s *Service) Create(ctx context.Context, cmd *CreateScheduleCommand) (*Schedule, error) {
+	if err := cmd.validate(s.cfg.SnapshotScheduleArchiveBucketURL != ""); err != nil {
+		return nil, err
+	}
+	dashboard, err := s.getDashboard(ctx, cmd.OrgID, cmd.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	schedule := &Schedule{
+		UID:          util.GenerateShortUID(),
+		OrgID:        cmd.OrgID,
+		ScheduleSpec: cmd.ScheduleSpec,
+		CreatedBy:    cmd.UserID,
+		Created:      now,
+		Updated:      now,
+	}
+	if schedule.Name == "" {
+		schedule.Name = dashboard.Title
+	}
+	if schedule.NextRun, err = schedule.nextRun(now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd *UpdateScheduleCommand) (*Schedule, error) {
+	if err := cmd.validate(s.cfg.SnapshotScheduleArchiveBucketURL != ""); err != nil {
+		return nil, err
+	}
+	schedule, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	dashboard, err := s.getDashboard(ctx, cmd.OrgID, cmd.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	schedule.ScheduleSpec = cmd.ScheduleSpec
+	// the schedule runs as the last user who saved it, who was checked to be allowed to
+	schedule.CreatedBy = cmd.UserID
+	if schedule.Name == "" {
+		schedule.Name = dashboard.Title
+	}
+	schedule.Updated = now
+	if schedule.NextRun, err = schedule.nextRun(now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*Schedule, error) {
+	return s.store.Get(ctx, orgID, uid)
+}
+
+func (s *Service) List(ctx context.Context, query *ListSchedulesQuery) ([]*Schedule, error) {
+	return s.store.List(ctx, query)
+}
+
+// Delete deletes a schedule. The snapshots it took are kept until they expire or are deleted.
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.Delete(ctx, orgID, uid)
+}
+
+// RunNow takes a snapshot of a schedule immediately, its next run is unchanged.
+func (s *Service) RunNow(ctx context.Context, orgID int64, uid string) (*RunResult, error) {
+	schedule, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, schedule, s.now())
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.SnapshotScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.bucketMu.Lock()
+			if s.bucket != nil {
+				_ = s.bucket.Close()
+			}
+			s.bucketMu.Unlock()
+			return ctx.Err()
+		case <-ticker.C:
+			s.runDue(ctx, s.now())
+		}
+	}
+}
+
+// runDue takes the snapshots of the schedules that are due. Every instance checks the schedules, the instance
+// that moves the next run of a schedule first takes its snapshot.
+func (s *Service) runDue(ctx context.Context, now time.Time) {
+	now = now.UTC()
+	due, err := s.store.GetDue(ctx, now)
+	if err != nil {
+		s.log.Error("Failed to get the snapshot schedules due", "error", err)
+		return
+	}
+
+	for _, schedule := range due {
+		next, err := schedule.nextRun(now)
+		if err != nil {
+			s.log.Error("Invalid snapshot schedule", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+			continue
+		}
+		claimed, err := s.store.Claim(ctx, schedule.ID, now, next)
+		if err != nil {
+			s.log.Error("Failed to claim snapshot schedule run", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if _, err := s.run(ctx, schedule, now); err != nil {
+			s.log.Error("Failed to take scheduled snapshot", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+		}
+	}
+}
+
+// run takes a snapshot of the dashboard of a schedule, deletes the snapshots beyond the versions to keep and
+// exports the snapshot to the archive. The result of the run is recorded on the schedule.
+func (s *Service) run(ctx context.Context, schedule *Schedule, now time.Time) (*RunResult, error) {
+	result, err := s.takeSnapshot(ctx, schedule, now)
+
+	lastError := ""
+	switch {
+	case err != nil:
+		lastError = err.Error()
+	case len(result.PanelErrors) > 0:
+		lastError = strings.Join(result.PanelErrors, "; ")
+	}
+	if err := s.store.SetResult(ctx, schedule.ID, now.UTC(), lastError); err != nil {
+		s.log.Warn("Failed to record snapshot schedule run", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+	}
+	return result, err
+}
+
+func (s *Service) takeSnapshot(ctx context.Context, schedule *Schedule, now time.Time) (*RunResult, error) {
+	owner, err := s.owner(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+	dashboard, err := s.getDashboard(ctx, schedule.OrgID, schedule.DashboardUID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, panelErrors, err := s.capture(ctx, owner, schedule, dashboard, now)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	deleteKey, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.snapshots.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: snapshot.DashboardCreateCommand{
+			Name:      fmt.Sprintf("%s %s", schedule.Name, now.UTC().Format(time.RFC3339)),
+			Dashboard: &common.Unstructured{Object: data.MustMap()},
+			Expires:   schedule.Expires,
+		},
+		Key:        key,
+		DeleteKey:  deleteKey,
+		OrgID:      schedule.OrgID,
+		UserID:     schedule.CreatedBy,
+		ScheduleID: schedule.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	if err := s.prune(ctx, schedule); err != nil {
+		s.log.Warn("Failed to delete old scheduled snapshots", "uid", schedule.UID, "orgId", schedule.OrgID, "error", err)
+	}
+
+	result := &RunResult{
+		Key:         created.Key,
+		URL:         setting.ToAbsUrl("dashboard/snapshot/" + created.Key),
+		PanelErrors: panelErrors,
+	}
+	if err := s.archive(ctx, schedule, data, now); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// prune deletes the snapshots of the schedule beyond the number of versions to keep.
+func (s *Service) prune(ctx context.Context, schedule *Schedule) error {
+	if schedule.KeepVersions == 0 {
+		return nil
+	}
+
+	keys, err := s.store.GetSnapshotDeleteKeys(ctx, schedule.ID)
+	if err != nil {
+		return err
+	}
+	if len(keys) <= schedule.KeepVersions {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range keys[schedule.KeepVersions:] {
+		if err := s.snapshots.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: key}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// archive exports the snapshot in the archive formats of the schedule to
+// <prefix>/<org id>/<schedule uid>/<time>.<extension>.
+func (s *Service) archive(ctx context.Context, schedule *Schedule, data *simplejson.Json, now time.Time) error {
+	if len(schedule.ArchiveFormats) == 0 {
+		return nil
+	}
+
+	bucket, err := s.getBucket(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range schedule.ArchiveFormats {
+		var buf bytes.Buffer
+		if err := dashboardsnapshots.Export(&buf, data, format); err != nil {
+			return fmt.Errorf("failed to export snapshot as %s: %w", format, err)
+		}
+
+		contentType, ext := dashboardsnapshots.ExportContentType(format)
+		key := path.Join(
+			s.cfg.SnapshotScheduleArchivePrefix,
+			fmt.Sprintf("%d", schedule.OrgID),
+			schedule.UID,
+			now.UTC().Format("20060102T150405Z")+"."+ext,
+		)
+		if err := bucket.WriteAll(ctx, key, buf.Bytes(), &blob.WriterOptions{ContentType: contentType}); err != nil {
+			return fmt.Errorf("failed to archive snapshot as %s: %w", format, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) getBucket(ctx context.Context) (*blob.Bucket, error) {
+	s.bucketMu.Lock()
+	defer s.bucketMu.Unlock()
+
+	if s.bucket != nil {
+		return s.bucket, nil
+	}
+	if s.cfg.SnapshotScheduleArchiveBucketURL == "" {
+		return nil, errors.New("snapshot archive bucket is not configured")
+	}
+
+	bucket, err := resource.OpenBlobBucket(ctx, s.cfg.SnapshotScheduleArchiveBucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot archive bucket: %w", err)
+	}
+	s.bucket = bucket
+	return bucket, nil
+}
+
+// owner returns the user the schedule runs as, with the permissions they have now. Snapshots are only taken while
+// the owner can still read the dashboard, and the queries of the panels are checked against the owner's datasource
+// permissions by the query service, so a schedule never shows data its owner could not query.
+func (s *Service) owner(ctx context.Context, schedule *Schedule) (identity.Requester, error) {
+	if schedule.CreatedBy == 0 {
+		return nil, ErrScheduleOwner.Errorf("snapshot schedule %s has no owner", schedule.UID)
+	}
+	owner, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: schedule.CreatedBy, OrgID: schedule.OrgID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, ErrScheduleOwner.Errorf("owner of snapshot schedule %s not found", schedule.UID)
+		}
+		return nil, err
+	}
+	if owner.IsDisabled || owner.OrgID != schedule.OrgID {
+		return nil, ErrScheduleOwner.Errorf("owner of snapshot schedule %s is disabled or left the organization", schedule.UID)
+	}
+
+	permissions, err := s.acService.GetUserPermissions(ctx, owner, accesscontrol.Options{ReloadCache: true})
+	if err != nil {
+		return nil, err
+	}
+	owner.Permissions = map[int64]map[string][]string{
+		owner.OrgID: accesscontrol.GroupScopesByActionContext(ctx, permissions),
+	}
+
+	canRead, err := s.accessControl.Evaluate(ctx, owner, accesscontrol.EvalPermission(
+		dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(schedule.DashboardUID),
+	))
+	if err != nil {
+		return nil, err
+	}
+	if !canRead {
+		return nil, ErrScheduleOwner.Errorf("owner of snapshot schedule %s cannot read dashboard %s", schedule.UID, schedule.DashboardUID)
+	}
+	return owner, nil
+}
+
+func (s *Service) getDashboard(ctx context.Context, orgID int64, uid string) (*dashboards.Dashboard, error) {
+	ctx, _ = identity.WithServiceIdentity(ctx, orgID)
+	return s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: orgID, UID: uid})
+}
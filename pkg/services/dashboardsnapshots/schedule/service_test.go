@@ -0,0 +1,287 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/secrets/database"
+	secretsManager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/util/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+const testDashboard = `{
+	"uid": "report",
+	"title": "Monthly report",
+	"time": {"from": "now-6h", "to": "now"},
+	"templating": {"list": [{"name": "env", "query": "label_values(env)", "current": {"text": "prod", "value": "prod"}}]},
+	"panels": [
+		{"id": 1, "title": "Requests", "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [
+			{"refId": "A", "expr": "sum(rate(requests_total{env=\"$env\"}[5m]))"},
+			{"refId": "B", "expr": "up", "hide": true}
+		]},
+		{"id": 2, "type": "row", "collapsed": true, "panels": [
+			{"id": 3, "title": "Logs", "type": "logs", "datasource": {"type": "loki", "uid": "loki"}, "targets": [{"refId": "A", "expr": "{job=\"app\"}"}]}
+		]},
+		{"id": 4, "title": "Reused", "type": "stat", "datasource": {"type": "datasource", "uid": "-- Dashboard --"}, "targets": [{"panelId": 1}]}
+	]
+}`
+
+type fakeQueryService struct {
+	query.Service
+	requests []dtos.MetricRequest
+	users    []identity.Requester
+}
+
+func (f *fakeQueryService) QueryData(_ context.Context, user identity.Requester, _ bool, req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	f.requests = append(f.requests, req)
+	f.users = append(f.users, user)
+
+	res := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		refID := q.Get("refId").MustString()
+		if q.GetPath("datasource", "uid").MustString() == "loki" {
+			res.Responses[refID] = backend.DataResponse{Error: errors.New("loki is unavailable")}
+			continue
+		}
+		res.Responses[refID] = backend.DataResponse{Frames: data.Frames{
+			data.NewFrame("requests",
+				data.NewField("time", nil, []time.Time{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)}),
+				data.NewField("value", data.Labels{"env": "prod"}, []float64{1.5, 2}),
+			),
+		}}
+	}
+	return res, nil
+}
+
+func TestIntegrationSnapshotSchedules(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.SnapshotScheduleArchiveBucketURL = "mem://"
+	cfg.SnapshotScheduleArchivePrefix = "snapshots"
+
+	dashboardData, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, &dashboards.GetDashboardQuery{OrgID: 1, UID: "report"}).
+		Return(&dashboards.Dashboard{UID: "report", OrgID: 1, Title: "Monthly report", Data: dashboardData}, nil)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	snapshots := dashsnapsvc.ProvideService(dashsnapdb.ProvideStore(sqlStore, cfg), secretsService, dashboardService)
+	queryService := &fakeQueryService{}
+
+	bucket := memblob.OpenBucket(nil)
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	now := time.Date(2024, 5, 31, 23, 59, 30, 0, time.UTC)
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedSignedInUser = &user.SignedInUser{UserID: 10, OrgID: 1, OrgRole: org.RoleEditor}
+	canReadReport := actest.FakeService{ExpectedPermissions: []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("report")},
+	}}
+	s := ProvideService(cfg, sqlStore, snapshots, dashboardService, queryService, userService, canReadReport, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()))
+	s.now = func() time.Time { return now }
+	s.bucket = bucket
+	s.log = log.NewNopLogger()
+
+	ctx := context.Background()
+
+	t.Run("should reject invalid schedules", func(t *testing.T) {
+		for _, spec := range []ScheduleSpec{
+			{DashboardUID: "report", Cron: "every month"},
+			{DashboardUID: "report", Cron: "@daily", TimeFrom: "last month"},
+			{DashboardUID: "report", Cron: "@daily", KeepVersions: -1},
+			{DashboardUID: "report", Cron: "@daily", ArchiveFormats: []string{"pdf"}},
+		} {
+			_, err := s.Create(ctx, &CreateScheduleCommand{ScheduleSpec: spec, OrgID: 1})
+			require.ErrorIs(t, err, ErrInvalidSchedule)
+		}
+
+		_, err := s.Create(ctx, &CreateScheduleCommand{ScheduleSpec: ScheduleSpec{DashboardUID: "missing", Cron: "@daily"}, OrgID: 1})
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	schedule, err := s.Create(ctx, &CreateScheduleCommand{
+		ScheduleSpec: ScheduleSpec{
+			DashboardUID:   "report",
+			Cron:           "@monthly",
+			TimeFrom:       "now-1M/M",
+			TimeTo:         "now-1M/M",
+			KeepVersions:   2,
+			ArchiveFormats: []string{dashboardsnapshots.ExportFormatCSV, dashboardsnapshots.ExportFormatParquet},
+		},
+		OrgID:  1,
+		UserID: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Monthly report", schedule.Name)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), schedule.NextRun)
+
+	var firstKey string
+	t.Run("should take the snapshot of a due schedule", func(t *testing.T) {
+		s.runDue(ctx, now)
+		require.Empty(t, queryService.requests, "the schedule is not due yet")
+
+		now = time.Date(2024, 6, 1, 0, 0, 30, 0, time.UTC)
+		s.runDue(ctx, now)
+
+		// the panel reusing the results of another panel is not queried
+		require.Len(t, queryService.requests, 2)
+		for _, u := range queryService.users {
+			assert.Equal(t, "user:10", u.GetID(), "the queries run as the owner of the schedule")
+		}
+		req := queryService.requests[0]
+		assert.Equal(t, "1714521600000", req.From)
+		assert.Equal(t, "1717199999999", req.To)
+		require.Len(t, req.Queries, 1, "hidden queries are skipped")
+		assert.Equal(t, "prom", req.Queries[0].GetPath("datasource", "uid").MustString())
+		assert.Equal(t, int64(defaultMaxDataPoints), req.Queries[0].Get("maxDataPoints").MustInt64())
+		assert.Equal(t, `sum(rate(requests_total{env="prod"}[5m]))`, req.Queries[0].Get("expr").MustString(), "the variables are interpolated")
+		assert.Equal(t, "loki", queryService.requests[1].Queries[0].GetPath("datasource", "uid").MustString(), "panels of collapsed rows are queried")
+
+		updated, err := s.Get(ctx, 1, schedule.UID)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), updated.NextRun)
+		require.NotNil(t, updated.LastRun)
+		assert.Contains(t, updated.LastError, "panel 3 query A: loki is unavailable")
+
+		// another instance checking the schedules at the same time does not take the snapshot again
+		s.runDue(ctx, now)
+		require.Len(t, queryService.requests, 2)
+
+		keys, err := s.store.GetSnapshotDeleteKeys(ctx, schedule.ID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		snapshot, err := snapshots.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{DeleteKey: keys[0]})
+		require.NoError(t, err)
+		firstKey = snapshot.Key
+		assert.Equal(t, schedule.ID, snapshot.ScheduleID)
+		assert.Equal(t, int64(10), snapshot.UserID)
+
+		dashboard := snapshot.Dashboard
+		assert.Equal(t, "2024-05-01T00:00:00Z", dashboard.GetPath("time", "from").MustString())
+		assert.Equal(t, "/d/report", dashboard.GetPath("snapshot", "originalUrl").MustString())
+		assert.Equal(t, `sum(rate(requests_total{env="$env"}[5m]))`, dashboard.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+		variable := dashboard.GetPath("templating", "list").GetIndex(0)
+		assert.Empty(t, variable.Get("query").MustString())
+		assert.Equal(t, "prod", variable.Get("options").GetIndex(0).Get("value").MustString())
+
+		frame := dashboard.Get("panels").GetIndex(0).Get("snapshotData").GetIndex(0)
+		assert.Equal(t, "requests", frame.Get("name").MustString())
+		assert.Equal(t, "A", frame.Get("refId").MustString())
+		assert.Equal(t, "time", frame.Get("fields").GetIndex(0).Get("type").MustString())
+		assert.Equal(t, int64(1714521600000), frame.Get("fields").GetIndex(0).Get("values").GetIndex(0).MustInt64())
+		assert.Equal(t, "prod", frame.Get("fields").GetIndex(1).GetPath("labels", "env").MustString())
+		assert.Equal(t, 1.5, frame.Get("fields").GetIndex(1).Get("values").GetIndex(0).MustFloat64())
+
+		logs := dashboard.Get("panels").GetIndex(1).Get("panels").GetIndex(0)
+		assert.Empty(t, logs.Get("snapshotData").MustArray())
+
+		iter := bucket.List(&blob.ListOptions{Prefix: "snapshots/1/" + schedule.UID + "/"})
+		var objects []string
+		for {
+			obj, err := iter.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			objects = append(objects, obj.Key)
+		}
+		assert.ElementsMatch(t, []string{
+			"snapshots/1/" + schedule.UID + "/20240601T000030Z.zip",
+			"snapshots/1/" + schedule.UID + "/20240601T000030Z.parquet",
+		}, objects)
+	})
+
+	t.Run("should keep the latest versions", func(t *testing.T) {
+		now = time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+		second, err := s.RunNow(ctx, 1, schedule.UID)
+		require.NoError(t, err)
+		now = time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+		third, err := s.RunNow(ctx, 1, schedule.UID)
+		require.NoError(t, err)
+		assert.Len(t, third.PanelErrors, 1)
+
+		_, err = snapshots.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: firstKey})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrBaseNotFound)
+		for _, key := range []string{second.Key, third.Key} {
+			_, err = snapshots.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key})
+			require.NoError(t, err)
+		}
+
+		updated, err := s.Get(ctx, 1, schedule.UID)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), updated.NextRun, "runs on demand do not move the next run")
+	})
+
+	t.Run("should not take snapshots once the owner cannot read the dashboard", func(t *testing.T) {
+		s.acService = actest.FakeService{}
+		t.Cleanup(func() { s.acService = canReadReport })
+
+		requests := len(queryService.requests)
+		_, err := s.RunNow(ctx, 1, schedule.UID)
+		require.ErrorIs(t, err, ErrScheduleOwner)
+		assert.Len(t, queryService.requests, requests)
+
+		updated, err := s.Get(ctx, 1, schedule.UID)
+		require.NoError(t, err)
+		assert.Contains(t, updated.LastError, "cannot read dashboard report")
+	})
+
+	t.Run("should not run paused schedules", func(t *testing.T) {
+		spec := schedule.ScheduleSpec
+		spec.Paused = true
+		_, err := s.Update(ctx, &UpdateScheduleCommand{ScheduleSpec: spec, UID: schedule.UID, OrgID: 1, UserID: 10})
+		require.NoError(t, err)
+
+		requests := len(queryService.requests)
+		now = time.Date(2024, 7, 1, 0, 0, 30, 0, time.UTC)
+		s.runDue(ctx, now)
+		assert.Len(t, queryService.requests, requests)
+
+		schedules, err := s.List(ctx, &ListSchedulesQuery{OrgID: 1, DashboardUID: "report"})
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.True(t, schedules[0].Paused)
+
+		require.NoError(t, s.Delete(ctx, 1, schedule.UID))
+		_, err = s.Get(ctx, 1, schedule.UID)
+		require.ErrorIs(t, err, ErrScheduleNotFound)
+	})
+}
@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store interface {
+	Create(ctx context.Context, schedule *Schedule) error
+	Update(ctx context.Context, schedule *Schedule) error
+	Get(ctx context.Context, orgID int64, uid string) (*Schedule, error)
+	List(ctx context.Context, query *ListSchedulesQuery) ([]*Schedule, error)
+	Delete(ctx context.Context, orgID int64, uid string) error
+	// GetDue returns the schedules that are not paused and whose next run is before now.
+	GetDue(ctx context.Context, now time.Time) ([]*Schedule, error)
+	// Claim moves the next run of a schedule due at now, it returns false if another instance claimed the run first.
+	Claim(ctx context.Context, id int64, now, next time.Time) (bool, error)
+	SetResult(ctx context.Context, id int64, lastRun time.Time, lastError string) error
+	// GetSnapshotDeleteKeys returns the delete keys of the snapshots taken by a schedule, newest first.
+	GetSnapshotDeleteKeys(ctx context.Context, scheduleID int64) ([]string, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Create(ctx context.Context, schedule *Schedule) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(schedule)
+		return err
+	})
+}
+
+func (s *xormStore) Update(ctx context.Context, schedule *Schedule) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(schedule.ID).AllCols().Update(schedule)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrScheduleNotFound.Errorf("snapshot schedule %s not found", schedule.UID)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) Get(ctx context.Context, orgID int64, uid string) (*Schedule, error) {
+	schedule := &Schedule{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(schedule)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrScheduleNotFound.Errorf("snapshot schedule %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (s *xormStore) List(ctx context.Context, query *ListSchedulesQuery) ([]*Schedule, error) {
+	schedules := make([]*Schedule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		return sess.Asc("name", "id").Find(&schedules)
+	})
+	return schedules, err
+}
+
+func (s *xormStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Schedule{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrScheduleNotFound.Errorf("snapshot schedule %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) GetDue(ctx context.Context, now time.Time) ([]*Schedule, error) {
+	schedules := make([]*Schedule, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("paused = ? AND next_run <= ?", false, now).Asc("next_run").Find(&schedules)
+	})
+	return schedules, err
+}
+
+func (s *xormStore) Claim(ctx context.Context, id int64, now, next time.Time) (bool, error) {
+	var claimed bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		// the next run of the schedule is after now once an instance claimed the run
+		affected, err := sess.Where("id = ? AND next_run <= ?", id, now).Cols("next_run").Update(&Schedule{NextRun: next})
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+func (s *xormStore) SetResult(ctx context.Context, id int64, lastRun time.Time, lastError string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Cols("last_run", "last_error").Update(&Schedule{LastRun: &lastRun, LastError: lastError})
+		return err
+	})
+}
+
+func (s *xormStore) GetSnapshotDeleteKeys(ctx context.Context, scheduleID int64) ([]string, error) {
+	keys := make([]string, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard_snapshot").Where("schedule_id = ?", scheduleID).Desc("created", "id").Cols("delete_key").Find(&keys)
+	})
+	return keys, err
+}
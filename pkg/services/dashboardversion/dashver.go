@@ -3,6 +3,7 @@ package dashver
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 )
 
@@ -11,4 +12,10 @@ type Service interface {
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) (*DashboardVersionResponse, error)
 	RestoreVersion(context.Context, *RestoreVersionCommand) (*dashboards.Dashboard, error)
+	// Diff compares two versions of a dashboard panel by panel, query by query and variable by variable.
+	Diff(context.Context, *DiffQuery) (*dashdiffs.SemanticResult, error)
+	// Blame returns the version that last changed each element of the latest version of a dashboard.
+	Blame(context.Context, *BlameQuery) (*BlameResult, error)
+	// RestoreElements restores panels and variables of a version into the current version of a dashboard.
+	RestoreElements(context.Context, *RestoreElementsCommand) (*dashboards.Dashboard, error)
 }
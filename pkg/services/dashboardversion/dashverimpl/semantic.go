@@ -0,0 +1,229 @@
+package dashverimpl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+)
+
+const blameBatchSize = 100
+
+// Diff compares two versions of a dashboard.
+func (s *Service) Diff(ctx context.Context, query *dashver.DiffQuery) (*dashdiffs.SemanticResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.Diff")
+	defer span.End()
+
+	g, gctx := errgroup.WithContext(ctx)
+	var base, updated *dashver.DashboardVersionDTO
+	g.Go(func() error {
+		var err error
+		base, err = s.Get(gctx, &dashver.GetDashboardVersionQuery{
+			DashboardUID: query.DashboardUID,
+			OrgID:        query.OrgID,
+			Version:      query.BaseVersion,
+		})
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		updated, err = s.Get(gctx, &dashver.GetDashboardVersionQuery{
+			DashboardUID: query.DashboardUID,
+			OrgID:        query.OrgID,
+			Version:      query.NewVersion,
+		})
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	res, err := dashdiffs.SemanticDiff(base.Data, updated.Data)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	return res, nil
+}
+
+// Blame replays the history of a dashboard from its oldest version and attributes each element of the latest
+// version to the version that last added, modified or moved it.
+func (s *Service) Blame(ctx context.Context, query *dashver.BlameQuery) (*dashver.BlameResult, error) {
+	ctx, span := tracer.Start(ctx, "Service.Blame")
+	defer span.End()
+
+	versions := make([]*dashver.DashboardVersionDTO, 0)
+	continueToken := ""
+	for {
+		res, err := s.List(ctx, &dashver.ListDashboardVersionsQuery{
+			DashboardUID:  query.DashboardUID,
+			OrgID:         query.OrgID,
+			Limit:         blameBatchSize,
+			ContinueToken: continueToken,
+		})
+		if err != nil {
+			return nil, tracing.Error(span, err)
+		}
+		versions = append(versions, res.Versions...)
+		continueToken = res.ContinueToken
+		if continueToken == "" || len(res.Versions) == 0 {
+			break
+		}
+	}
+	if len(versions) == 0 {
+		return nil, dashver.ErrDashboardVersionNotFound
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	blameOf := func(v *dashver.DashboardVersionDTO) dashver.ElementBlame {
+		return dashver.ElementBlame{Version: v.Version, Created: v.Created, CreatedBy: v.CreatedBy}
+	}
+	blame := make(map[string]dashver.ElementBlame)
+	elements, err := dashdiffs.Elements(versions[0].Data)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	for _, e := range elements {
+		blame[e.Key] = blameOf(versions[0])
+	}
+	for i := 1; i < len(versions); i++ {
+		diff, err := dashdiffs.SemanticDiff(versions[i-1].Data, versions[i].Data)
+		if err != nil {
+			return nil, tracing.Error(span, err)
+		}
+		for _, change := range diff.Changes {
+			if change.Change == dashdiffs.ChangeRemoved {
+				delete(blame, change.Key)
+				continue
+			}
+			blame[change.Key] = blameOf(versions[i])
+		}
+	}
+
+	latest := versions[len(versions)-1]
+	elements, err = dashdiffs.Elements(latest.Data)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	res := &dashver.BlameResult{
+		Version:  latest.Version,
+		Elements: make([]dashver.ElementBlame, 0, len(elements)),
+	}
+	for _, e := range elements {
+		b := blame[e.Key]
+		b.Kind = e.Kind
+		b.Key = e.Key
+		b.Title = e.Title
+		res.Elements = append(res.Elements, b)
+	}
+	return res, nil
+}
+
+// RestoreElements restores panels and variables of a version into the current version of a dashboard and saves
+// it as a new version, leaving the rest of the dashboard untouched.
+func (s *Service) RestoreElements(ctx context.Context, cmd *dashver.RestoreElementsCommand) (*dashboards.Dashboard, error) {
+	ctx, span := tracer.Start(ctx, "Service.RestoreElements")
+	defer span.End()
+
+	// We must use separate gctx context here, because it will be canceled, once the group is done.
+	g, gctx := errgroup.WithContext(ctx)
+
+	var currentDash *dashboards.Dashboard
+	g.Go(func() error {
+		var err error
+		currentDash, err = s.dashSvc.GetDashboard(gctx, &dashboards.GetDashboardQuery{
+			UID:   cmd.DashboardUID,
+			OrgID: cmd.Requester.GetOrgID(),
+		})
+		if err != nil {
+			s.log.Debug("error getting dashboard", "error", err)
+		}
+		return err
+	})
+
+	var versionData *dashver.DashboardVersionDTO
+	g.Go(func() error {
+		var err error
+		versionData, err = s.Get(gctx, &dashver.GetDashboardVersionQuery{
+			DashboardUID: cmd.DashboardUID,
+			OrgID:        cmd.Requester.GetOrgID(),
+			Version:      cmd.Version,
+		})
+		if err != nil {
+			s.log.Debug("error getting dashboard version", "error", err)
+		}
+		return err
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	data, err := dashdiffs.RestoreElements(currentDash.Data, versionData.Data, cmd.PanelIDs, cmd.Variables)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	diff, err := dashdiffs.SemanticDiff(currentDash.Data, data)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if len(diff.Changes) == 0 {
+		return nil, dashboards.ErrDashboardRestoreIdenticalVersion
+	}
+
+	userID, err := identity.UserIdentifier(cmd.Requester.GetID())
+	if err != nil {
+		s.log.Debug("error getting user identifier", "error", err)
+		return nil, tracing.Error(span, err)
+	}
+
+	saveCmd := dashboards.SaveDashboardCommand{
+		OrgID:     cmd.Requester.GetOrgID(),
+		UserID:    userID,
+		Dashboard: data,
+		FolderUID: currentDash.FolderUID,
+	}
+	saveCmd.Dashboard.Set("version", currentDash.Version)
+	saveCmd.Dashboard.Set("uid", currentDash.UID)
+	dashItem := &dashboards.SaveDashboardDTO{
+		User:      cmd.Requester,
+		OrgID:     cmd.Requester.GetOrgID(),
+		UpdatedAt: time.Now(),
+		Message:   elementsRestoreMessage(cmd.PanelIDs, cmd.Variables, versionData.Version),
+		Overwrite: false,
+		Dashboard: saveCmd.GetDashboardModel(),
+	}
+
+	res, err := s.dashSvc.SaveDashboard(ctx, dashItem, true)
+	if err != nil {
+		s.log.Debug("error saving dashboard", "error", err)
+		return nil, tracing.Error(span, err)
+	}
+	return res, nil
+}
+
+func elementsRestoreMessage(panelIDs []int64, variables []string, version int) string {
+	parts := make([]string, 0, 2)
+	if len(panelIDs) > 0 {
+		ids := make([]string, len(panelIDs))
+		for i, id := range panelIDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		parts = append(parts, "panels "+strings.Join(ids, ", "))
+	}
+	if len(variables) > 0 {
+		parts = append(parts, "variables "+strings.Join(variables, ", "))
+	}
+	return fmt.Sprintf("Restored %s from version %d", strings.Join(parts, " and "), version)
+}
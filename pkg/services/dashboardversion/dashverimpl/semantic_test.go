@@ -0,0 +1,82 @@
+package dashverimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/services/apiserver/client"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func versionObject(generation int64, createdBy string, spec map[string]any) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{
+			"name":       "uid",
+			"generation": generation,
+			"annotations": map[string]any{
+				utils.AnnoKeyCreatedBy: createdBy,
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+func TestBlame(t *testing.T) {
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardVersionService := Service{dashSvc: dashboardService, features: featuremgmt.WithFeatures()}
+	mockCli := new(client.MockK8sHandler)
+	dashboardVersionService.k8sclient = mockCli
+
+	cpu := map[string]any{"id": int64(1), "title": "CPU", "gridPos": map[string]any{"x": int64(0), "y": int64(0)}}
+	memory := map[string]any{"id": int64(2), "title": "Memory", "gridPos": map[string]any{"x": int64(12), "y": int64(0)}}
+	movedCPU := map[string]any{"id": int64(1), "title": "CPU", "gridPos": map[string]any{"x": int64(0), "y": int64(8)}}
+	renamedMemory := map[string]any{"id": int64(2), "title": "Memory usage", "gridPos": map[string]any{"x": int64(12), "y": int64(0)}}
+
+	mockCli.On("GetUsersFromMeta", mock.Anything, mock.Anything).Return(map[string]*user.User{
+		"user:1": {ID: 1},
+		"user:2": {ID: 2},
+		"user:3": {ID: 3},
+	}, nil)
+	// versions are listed from the newest
+	mockCli.On("List", mock.Anything, mock.Anything, mock.Anything).Return(&unstructured.UnstructuredList{
+		Items: []unstructured.Unstructured{
+			versionObject(3, "user:3", map[string]any{"title": "Service", "panels": []any{movedCPU, renamedMemory}}),
+			versionObject(2, "user:2", map[string]any{"title": "Service", "panels": []any{cpu, renamedMemory}}),
+			versionObject(1, "user:1", map[string]any{"title": "Service", "panels": []any{cpu, memory}}),
+		},
+	}, nil).Once()
+
+	res, err := dashboardVersionService.Blame(context.Background(), &dashver.BlameQuery{DashboardUID: "uid", OrgID: 1})
+	require.NoError(t, err)
+	require.Equal(t, 3, res.Version)
+
+	blame := make(map[string]dashver.ElementBlame, len(res.Elements))
+	for _, e := range res.Elements {
+		blame[e.Key] = e
+	}
+	require.Len(t, blame, 3)
+	require.Equal(t, 1, blame["dashboard"].Version)
+	require.Equal(t, int64(1), blame["dashboard"].CreatedBy)
+	require.Equal(t, dashdiffs.ElementPanel, blame["panel/1"].Kind)
+	require.Equal(t, 3, blame["panel/1"].Version)
+	require.Equal(t, int64(3), blame["panel/1"].CreatedBy)
+	require.Equal(t, "Memory usage", blame["panel/2"].Title)
+	require.Equal(t, 2, blame["panel/2"].Version)
+	require.Equal(t, int64(2), blame["panel/2"].CreatedBy)
+}
+
+func TestElementsRestoreMessage(t *testing.T) {
+	require.Equal(t, "Restored panels 1, 4 from version 3", elementsRestoreMessage([]int64{1, 4}, nil, 3))
+	require.Equal(t, "Restored variables env from version 3", elementsRestoreMessage(nil, []string{"env"}, 3))
+	require.Equal(t, "Restored panels 2 and variables env, host from version 7",
+		elementsRestoreMessage([]int64{2}, []string{"env", "host"}, 7))
+}
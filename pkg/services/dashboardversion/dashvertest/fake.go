@@ -3,6 +3,7 @@ package dashvertest
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 )
@@ -18,6 +19,11 @@ type FakeDashboardVersionService struct {
 	ExpectedRestoreResult *dashboards.Dashboard
 	RestoreVersionCalled  bool
 	LastRestoreCommand    *dashver.RestoreVersionCommand
+	// Fields for semantic diff, blame and selective restore testing
+	ExpectedDiff                  *dashdiffs.SemanticResult
+	ExpectedBlame                 *dashver.BlameResult
+	LastRestoreElementsCommand    *dashver.RestoreElementsCommand
+	ExpectedRestoreElementsResult *dashboards.Dashboard
 }
 
 func NewDashboardVersionServiceFake() *FakeDashboardVersionService {
@@ -48,3 +54,16 @@ func (f *FakeDashboardVersionService) RestoreVersion(ctx context.Context, cmd *d
 	f.LastRestoreCommand = cmd
 	return f.ExpectedRestoreResult, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) Diff(ctx context.Context, query *dashver.DiffQuery) (*dashdiffs.SemanticResult, error) {
+	return f.ExpectedDiff, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) Blame(ctx context.Context, query *dashver.BlameQuery) (*dashver.BlameResult, error) {
+	return f.ExpectedBlame, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) RestoreElements(ctx context.Context, cmd *dashver.RestoreElementsCommand) (*dashboards.Dashboard, error) {
+	f.LastRestoreElementsCommand = cmd
+	return f.ExpectedRestoreElementsResult, f.ExpectedError
+}
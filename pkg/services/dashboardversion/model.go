@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -70,6 +71,31 @@ type RestoreVersionCommand struct {
 	Version      int64
 }
 
+// RestoreElementsCommand is used to restore panels and variables of a
+// dashboard version into the current version of the dashboard.
+type RestoreElementsCommand struct {
+	Requester    identity.Requester
+	DashboardUID string
+	Version      int64
+	PanelIDs     []int64
+	Variables    []string
+}
+
+// DiffQuery is used to compare two versions of a dashboard.
+type DiffQuery struct {
+	DashboardUID string
+	OrgID        int64
+	BaseVersion  int64
+	NewVersion   int64
+}
+
+// BlameQuery is used to get the version that last changed each element of
+// a dashboard.
+type BlameQuery struct {
+	DashboardUID string
+	OrgID        int64
+}
+
 type ListDashboardVersionsQuery struct {
 	DashboardID   int64
 	DashboardUID  string
@@ -117,3 +143,38 @@ type DashboardVersionResponseMeta struct {
 	ContinueToken string                 `json:"continueToken"`
 	Versions      []DashboardVersionMeta `json:"versions"`
 }
+
+// ElementBlame is the version that last added, modified or moved an element
+// of a dashboard.
+type ElementBlame struct {
+	Kind      dashdiffs.ElementKind `json:"kind"`
+	Key       string                `json:"key"`
+	Title     string                `json:"title"`
+	Version   int                   `json:"version"`
+	Created   time.Time             `json:"created"`
+	CreatedBy int64                 `json:"createdBy"`
+}
+
+// BlameResult lists the elements of the latest version of a dashboard. The
+// history only goes back to the oldest version that was kept, elements that
+// did not change since then are attributed to it.
+type BlameResult struct {
+	Version  int            `json:"version"`
+	Elements []ElementBlame `json:"elements"`
+}
+
+// ElementBlameMeta extends the ElementBlame with the name associated with
+// the user ID, overriding the field with the same name.
+type ElementBlameMeta struct {
+	Kind      dashdiffs.ElementKind `json:"kind"`
+	Key       string                `json:"key"`
+	Title     string                `json:"title"`
+	Version   int                   `json:"version"`
+	Created   time.Time             `json:"created"`
+	CreatedBy string                `json:"createdBy"`
+}
+
+type BlameResponseMeta struct {
+	Version  int                `json:"version"`
+	Elements []ElementBlameMeta `json:"elements"`
+}
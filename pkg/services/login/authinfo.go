@@ -29,6 +29,7 @@ const (
 	// modules
 	PasswordAuthModule     = "password"
 	PasswordlessAuthModule = "passwordless"
+	WebAuthnAuthModule     = "webauthn"
 	APIKeyAuthModule       = "apikey"
 	SAMLAuthModule         = "auth.saml"
 	LDAPAuthModule         = "ldap"
@@ -208,6 +208,8 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM star WHERE org_id = ?",
 			"DELETE FROM playlist_item WHERE playlist_id IN (SELECT id FROM playlist WHERE org_id = ?)",
 			"DELETE FROM playlist WHERE org_id = ?",
+			"DELETE FROM dashboard_snapshot_schedule WHERE org_id = ?",
+			"DELETE FROM dashboard_public_usage WHERE org_id = ?",
 			"DELETE FROM dashboard_tag WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
@@ -224,12 +226,14 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM alert_rule_version WHERE rule_org_id = ?",
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
+			"DELETE FROM annotation_ingestion_key WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
 			"DELETE FROM team WHERE org_id = ?",
 			"DELETE FROM team_member WHERE org_id = ?",
 			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM user_role WHERE org_id = ?",
 			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM resource_permission_grant WHERE org_id = ?",
 		}
 
 		// Add registered deletes
@@ -817,6 +821,7 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM team_member WHERE user_id = ?",
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
+		"DELETE FROM user_webauthn_credential WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
 	}
 	return deletes
@@ -99,6 +99,16 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// Rotate the signing key of a signed public dashboard
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/signing-key",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RotatePublicDashboardSigningKey))
+
+	// Get the usage of a public dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/usage",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(api.GetPublicDashboardUsage))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboards dashboard_public listPublicDashboards
@@ -296,6 +306,73 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.Empty(http.StatusOK)
 }
 
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/signing-key dashboards dashboard_public rotatePublicDashboardSigningKey
+//
+//	Rotate the signing key of a signed public dashboard
+//
+// The key is only returned once. Viewer tokens signed with the previous key are rejected.
+//
+// Responses:
+// 200: rotatePublicDashboardSigningKeyResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RotatePublicDashboardSigningKey(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("RotatePublicDashboardSigningKey: invalid Uid %s", uid))
+	}
+
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("RotatePublicDashboardSigningKey: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	signingKey, err := api.PublicDashboardService.RotateSigningKey(c.Req.Context(), c.SignedInUser, uid, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, SigningKeyResponse{SigningKey: signingKey})
+}
+
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/usage dashboards dashboard_public getPublicDashboardUsage
+//
+//	Get the daily views, queries and errors of a public dashboard
+//
+// Responses:
+// 200: getPublicDashboardUsageResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardUsage(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardUsage: invalid Uid %s", uid))
+	}
+
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardUsage: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	usage, err := api.PublicDashboardService.FindUsage(c.Req.Context(), &UsageQuery{
+		OrgId:              c.GetOrgID(),
+		PublicDashboardUid: uid,
+		From:               c.Query("from"),
+		To:                 c.Query("to"),
+	}, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, usage)
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(ctx context.Context, features featuremgmt.FeatureToggles, qdr *backend.QueryDataResponse) response.Response {
 	statusCode := http.StatusOK
@@ -371,3 +448,46 @@ type DeletePublicDashboardParams struct {
 	// required:true
 	Uid string `json:"uid"`
 }
+
+type SigningKeyResponse struct {
+	// SigningKey is the key viewer tokens are signed with using HS256
+	SigningKey string `json:"signingKey"`
+}
+
+// swagger:parameters rotatePublicDashboardSigningKey
+type RotatePublicDashboardSigningKeyParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response rotatePublicDashboardSigningKeyResponse
+type RotatePublicDashboardSigningKeyResponse struct {
+	// in: body
+	Body SigningKeyResponse `json:"body"`
+}
+
+// swagger:parameters getPublicDashboardUsage
+type GetPublicDashboardUsageParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// First day of the usage, formatted as 2006-01-02. Defaults to 29 days before today.
+	// in:query
+	From string `json:"from"`
+	// Last day of the usage, formatted as 2006-01-02. Defaults to today.
+	// in:query
+	To string `json:"to"`
+}
+
+// swagger:response getPublicDashboardUsageResponse
+type GetPublicDashboardUsageResponse struct {
+	// in: body
+	Body UsageResponse `json:"body"`
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -31,7 +33,7 @@ func (api *Api) ViewPublicDashboard(c *contextmodel.ReqContext) response.Respons
 		return response.Err(ErrInvalidAccessToken.Errorf("ViewPublicDashboard: invalid access token"))
 	}
 
-	dto, err := api.PublicDashboardService.GetPublicDashboardForView(c.Req.Context(), accessToken)
+	dto, err := api.PublicDashboardService.GetPublicDashboardForView(viewerContext(c), accessToken)
 	if err != nil {
 		return response.Err(err)
 	}
@@ -67,7 +69,7 @@ func (api *Api) QueryPublicDashboard(c *contextmodel.ReqContext) response.Respon
 		return response.Err(ErrBadRequest.Errorf("QueryPublicDashboard: error parsing request: %v", err))
 	}
 
-	resp, err := api.PublicDashboardService.GetQueryDataResponse(c.Req.Context(), c.SkipDSCache, reqDTO, panelId, accessToken)
+	resp, err := api.PublicDashboardService.GetQueryDataResponse(viewerContext(c), c.SkipDSCache, reqDTO, panelId, accessToken)
 	if err != nil {
 		return response.Err(err)
 	}
@@ -97,7 +99,7 @@ func (api *Api) GetPublicAnnotations(c *contextmodel.ReqContext) response.Respon
 		To:   c.QueryInt64("to"),
 	}
 
-	annotations, err := api.PublicDashboardService.FindAnnotations(c.Req.Context(), reqDTO, accessToken)
+	annotations, err := api.PublicDashboardService.FindAnnotations(viewerContext(c), reqDTO, accessToken)
 	if err != nil {
 		return response.Err(err)
 	}
@@ -105,6 +107,18 @@ func (api *Api) GetPublicAnnotations(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, annotations)
 }
 
+// viewerContext returns the request context carrying the viewer token of signed public dashboards, sent as a bearer
+// token in the Authorization header. Embedding applications pass the token in the URL fragment of the iframe, e.g.
+// /public-dashboards/<access token>#viewerToken=<viewer token>, and the frontend sends it with its public dashboard
+// API requests. Tokens are never read from the query string, which ends up in access logs and Referer headers.
+func viewerContext(c *contextmodel.ReqContext) context.Context {
+	token, ok := strings.CutPrefix(c.Req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = ""
+	}
+	return WithViewerToken(c.Req.Context(), token)
+}
+
 // swagger:response viewPublicDashboardResponse
 type ViewPublicDashboardResponse struct {
 	// in: body
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -298,3 +300,26 @@ func TestAPIGetAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestViewerContext(t *testing.T) {
+	newContext := func(target string, header http.Header) *contextmodel.ReqContext {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header = header
+		return &contextmodel.ReqContext{Context: &web.Context{Req: req}}
+	}
+
+	t.Run("reads the bearer token of the Authorization header", func(t *testing.T) {
+		c := newContext("/api/public/dashboards/abc", http.Header{"Authorization": []string{"Bearer viewer-token"}})
+		assert.Equal(t, "viewer-token", ViewerTokenFromContext(viewerContext(c)))
+	})
+
+	t.Run("ignores other authorization schemes", func(t *testing.T) {
+		c := newContext("/api/public/dashboards/abc", http.Header{"Authorization": []string{"Basic dXNlcjpwYXNz"}})
+		assert.Empty(t, ViewerTokenFromContext(viewerContext(c)))
+	})
+
+	t.Run("ignores tokens in the URL", func(t *testing.T) {
+		c := newContext("/api/public/dashboards/abc?viewerToken=viewer-token", http.Header{})
+		assert.Empty(t, ViewerTokenFromContext(viewerContext(c)))
+	})
+}
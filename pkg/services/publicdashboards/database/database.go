@@ -235,12 +235,14 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, rate_limit = ?, query_cache_ttl = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			cmd.PublicDashboard.RateLimit,
+			cmd.PublicDashboard.QueryCacheTTL,
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC(),
 			cmd.PublicDashboard.Uid)
@@ -261,10 +263,30 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
 
+		_, err = sess.Exec("DELETE FROM dashboard_public_usage WHERE public_dashboard_uid = ?", uid)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// SetSigningKey replaces the encrypted signing key of a public dashboard
+func (d *PublicDashboardStoreImpl) SetSigningKey(ctx context.Context, uid string, signingKey []byte) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET signing_key = ? WHERE uid = ?", signingKey, uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
 		return err
 	})
 
@@ -278,23 +300,72 @@ func (d *PublicDashboardStoreImpl) DeleteByDashboardUIDs(ctx context.Context, or
 	}
 
 	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		inClause := fmt.Sprintf("dashboard_uid IN (%s)", strings.Repeat("?,", len(dashboardUIDs)-1)+"?")
+		params := make([]any, 0, len(dashboardUIDs)+1)
+		params = append(params, orgId)
+		for _, dashboardUID := range dashboardUIDs {
+			params = append(params, dashboardUID)
+		}
+
+		usageSQL := "DELETE FROM dashboard_public_usage WHERE public_dashboard_uid IN (SELECT uid FROM dashboard_public WHERE org_id = ? AND " + inClause + ")"
+		if _, err := sess.Exec(append([]any{usageSQL}, params...)...); err != nil {
+			return err
+		}
+
 		s := strings.Builder{}
 		s.WriteString("DELETE FROM dashboard_public WHERE org_id = ? AND ")
-		s.WriteString(fmt.Sprintf("dashboard_uid IN (%s)", strings.Repeat("?,", len(dashboardUIDs)-1)+"?"))
-		sql := s.String()
-		args := make([]any, 0, len(dashboardUIDs)+2)
-		args = append(args, sql)
-		args = append(args, orgId)
-		for _, dashboardUID := range dashboardUIDs {
-			args = append(args, dashboardUID)
+		s.WriteString(inClause)
+		_, err := sess.Exec(append([]any{s.String()}, params...)...)
+
+		return err
+	})
+}
+
+// IncrementUsage adds counts to the usage of a public dashboard on a day
+func (d *PublicDashboardStoreImpl) IncrementUsage(ctx context.Context, orgId int64, uid string, day string, counts UsageCounts) error {
+	increment := func(sess *db.Session) (int64, error) {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public_usage SET views = views + ?, queries = queries + ?, errors = errors + ?, rate_limited = rate_limited + ?, cache_hits = cache_hits + ? WHERE public_dashboard_uid = ? AND day = ?",
+			counts.Views, counts.Queries, counts.Errors, counts.RateLimited, counts.CacheHits, uid, day)
+		if err != nil {
+			return 0, err
+		}
+		return sqlResult.RowsAffected()
+	}
+
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affectedRows, err := increment(sess)
+		if err != nil || affectedRows > 0 {
+			return err
 		}
 
-		_, err := sess.Exec(args...)
+		_, err = sess.Insert(&Usage{OrgId: orgId, PublicDashboardUid: uid, Day: day, UsageCounts: counts})
+		if err == nil {
+			return nil
+		}
 
+		// another instance inserted the usage of the day first
+		if affectedRows, retryErr := increment(sess); retryErr == nil && affectedRows > 0 {
+			return nil
+		}
 		return err
 	})
 }
 
+// FindUsage returns the daily usage of a public dashboard, ordered by day
+func (d *PublicDashboardStoreImpl) FindUsage(ctx context.Context, query *UsageQuery) ([]*Usage, error) {
+	usage := make([]*Usage, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND public_dashboard_uid = ? AND day >= ? AND day <= ?", query.OrgId, query.PublicDashboardUid, query.From, query.To).
+			Asc("day").
+			Find(&usage)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
 func (d *PublicDashboardStoreImpl) GetMetrics(ctx context.Context) (*Metrics, error) {
 	metrics := &Metrics{
 		TotalPublicDashboards: []*TotalPublicDashboard{},
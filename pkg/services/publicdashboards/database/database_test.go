@@ -787,6 +787,59 @@ func TestIntegrationGetMetrics(t *testing.T) {
 }
 
 // helper function to insert a dashboard
+func TestIntegrationUsage(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore))
+	require.NoError(t, err)
+	publicdashboardStore := ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+	savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+	savedPublicDashboard := insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, SignedShareType)
+	ctx := context.Background()
+
+	t.Run("increments the usage of a day", func(t *testing.T) {
+		require.NoError(t, publicdashboardStore.IncrementUsage(ctx, 1, savedPublicDashboard.Uid, "2024-05-01", UsageCounts{Views: 1, Queries: 3}))
+		require.NoError(t, publicdashboardStore.IncrementUsage(ctx, 1, savedPublicDashboard.Uid, "2024-05-01", UsageCounts{Queries: 2, Errors: 1, CacheHits: 1}))
+		require.NoError(t, publicdashboardStore.IncrementUsage(ctx, 1, savedPublicDashboard.Uid, "2024-05-02", UsageCounts{RateLimited: 4}))
+
+		usage, err := publicdashboardStore.FindUsage(ctx, &UsageQuery{OrgId: 1, PublicDashboardUid: savedPublicDashboard.Uid, From: "2024-05-01", To: "2024-05-31"})
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+		assert.Equal(t, "2024-05-01", usage[0].Day)
+		assert.Equal(t, UsageCounts{Views: 1, Queries: 5, Errors: 1, CacheHits: 1}, usage[0].UsageCounts)
+		assert.Equal(t, "2024-05-02", usage[1].Day)
+		assert.Equal(t, UsageCounts{RateLimited: 4}, usage[1].UsageCounts)
+	})
+
+	t.Run("finds the usage of the days of the range", func(t *testing.T) {
+		usage, err := publicdashboardStore.FindUsage(ctx, &UsageQuery{OrgId: 1, PublicDashboardUid: savedPublicDashboard.Uid, From: "2024-05-02", To: "2024-05-02"})
+		require.NoError(t, err)
+		require.Len(t, usage, 1)
+		assert.Equal(t, "2024-05-02", usage[0].Day)
+	})
+
+	t.Run("sets the signing key", func(t *testing.T) {
+		affectedRows, err := publicdashboardStore.SetSigningKey(ctx, savedPublicDashboard.Uid, []byte("key"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		pubdash, err := publicdashboardStore.Find(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("key"), pubdash.SigningKey)
+		assert.True(t, pubdash.HasSigningKey)
+	})
+
+	t.Run("deletes the usage with the public dashboard", func(t *testing.T) {
+		_, err := publicdashboardStore.Delete(ctx, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		usage, err := publicdashboardStore.FindUsage(ctx, &UsageQuery{OrgId: 1, PublicDashboardUid: savedPublicDashboard.Uid, From: "2024-05-01", To: "2024-05-31"})
+		require.NoError(t, err)
+		assert.Empty(t, usage)
+	})
+}
+
 func insertTestDashboard(t *testing.T, dashboardStore dashboards.Store, title string, orgID int64,
 	folderUID string, isFolder bool, tags ...any) *dashboards.Dashboard {
 	t.Helper()
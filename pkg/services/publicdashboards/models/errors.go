@@ -23,6 +23,15 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Dashboard Access Token already exists"))
+	ErrInvalidRateLimit                    = errutil.BadRequest("publicdashboards.invalidRateLimit", errutil.WithPublicMessage("Rate limit cannot be negative"))
+	ErrInvalidQueryCacheTTL                = errutil.BadRequest("publicdashboards.invalidQueryCacheTtl", errutil.WithPublicMessage("Query cache TTL cannot be negative"))
+	ErrInvalidUsageRange                   = errutil.BadRequest("publicdashboards.invalidUsageRange", errutil.WithPublicMessage("Invalid usage range"))
+	ErrNotSignedShareType                  = errutil.BadRequest("publicdashboards.notSignedShareType", errutil.WithPublicMessage("Signing keys are only used by signed public dashboards"))
+
+	ErrViewerTokenRequired = errutil.Unauthorized("publicdashboards.viewerTokenRequired", errutil.WithPublicMessage("Viewer token required"))
+	ErrInvalidViewerToken  = errutil.Unauthorized("publicdashboards.invalidViewerToken", errutil.WithPublicMessage("Invalid viewer token"))
+	ErrVariableNotAllowed  = errutil.Forbidden("publicdashboards.variableNotAllowed", errutil.WithPublicMessage("Variable value not allowed"))
+	ErrRateLimited         = errutil.TooManyRequests("publicdashboards.rateLimited", errutil.WithPublicMessage("Too many requests"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Dashboard paused"))
 )
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -28,12 +29,13 @@ const (
 	QueryFailure                                  = "failure"
 	EmailShareType                      ShareType = "email"
 	PublicShareType                     ShareType = "public"
+	SignedShareType                     ShareType = "signed"
 	FeaturePublicDashboardsEmailSharing           = "publicDashboardsEmailSharing"
 )
 
 var (
 	QueryResultStatuses = []string{QuerySuccess, QueryFailure}
-	ValidShareTypes     = []ShareType{EmailShareType, PublicShareType}
+	ValidShareTypes     = []ShareType{EmailShareType, PublicShareType, SignedShareType}
 )
 
 type ShareType string
@@ -54,6 +56,13 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// RateLimit is the number of panel queries per minute allowed on the public dashboard, 0 is unlimited
+	RateLimit int64 `json:"rateLimit" xorm:"rate_limit"`
+	// QueryCacheTTL is the number of seconds panel query results are cached for, 0 disables the cache
+	QueryCacheTTL int64 `json:"queryCacheTtl" xorm:"query_cache_ttl"`
+	// SigningKey is the encrypted key viewer tokens of signed public dashboards are signed with
+	SigningKey    []byte `json:"-" xorm:"signing_key"`
+	HasSigningKey bool   `json:"hasSigningKey" xorm:"-"`
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +72,8 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	RateLimit            *int64    `json:"rateLimit"`
+	QueryCacheTTL        *int64    `json:"queryCacheTtl"`
 }
 
 type EmailDTO struct {
@@ -97,6 +108,11 @@ func (pd PublicDashboard) TableName() string {
 	return "dashboard_public"
 }
 
+// AfterLoad is called by xorm after the public dashboard is loaded from the database.
+func (pd *PublicDashboard) AfterLoad() {
+	pd.HasSigningKey = len(pd.SigningKey) > 0
+}
+
 type PublicDashboardListQuery struct {
 	OrgID  int64
 	Query  string
@@ -155,6 +171,9 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables are the values selected by the viewer of a signed public dashboard, they must be allowed by the
+	// viewer token
+	Variables map[string][]string `json:"variables,omitempty"`
 }
 
 type AnnotationsQueryDTO struct {
@@ -162,6 +181,83 @@ type AnnotationsQueryDTO struct {
 	To   int64
 }
 
+// ViewerClaims are the claims of the viewer tokens of signed public dashboards. The tokens are JWTs signed with
+// HS256 by the application embedding the public dashboard, using the signing key of the public dashboard.
+type ViewerClaims struct {
+	// Viewer is the subject of the token, it identifies the viewer in logs
+	Viewer string `json:"-"`
+	// Variables are the values of the template variables the viewer is allowed to query, they replace the
+	// variables in the panel queries
+	Variables map[string][]string `json:"vars,omitempty"`
+	// TimeRange limits the time range the viewer is allowed to query
+	TimeRange *ViewerTimeRange `json:"timeRange,omitempty"`
+}
+
+type ViewerTimeRange struct {
+	// From is the earliest time the viewer can query, e.g. now-30d
+	From string `json:"from,omitempty"`
+	// To is the latest time the viewer can query, e.g. now
+	To string `json:"to,omitempty"`
+	// MaxDuration is the longest time range the viewer can query, e.g. 7d
+	MaxDuration string `json:"maxDuration,omitempty"`
+}
+
+type viewerTokenKey struct{}
+
+// WithViewerToken returns a context carrying the viewer token of a request to a public dashboard.
+func WithViewerToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, viewerTokenKey{}, token)
+}
+
+// ViewerTokenFromContext returns the viewer token of a request to a public dashboard, or an empty string.
+func ViewerTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(viewerTokenKey{}).(string)
+	return token
+}
+
+// Usage is the number of views, queries and errors of a public dashboard on a day.
+type Usage struct {
+	Id                 int64  `json:"-" xorm:"pk autoincr 'id'"`
+	OrgId              int64  `json:"-" xorm:"org_id"`
+	PublicDashboardUid string `json:"-" xorm:"public_dashboard_uid"`
+	// Day is the UTC day of the usage formatted as 2006-01-02
+	Day         string `json:"day" xorm:"day"`
+	UsageCounts `xorm:"extends"`
+}
+
+func (u Usage) TableName() string {
+	return "dashboard_public_usage"
+}
+
+type UsageCounts struct {
+	Views       int64 `json:"views" xorm:"views"`
+	Queries     int64 `json:"queries" xorm:"queries"`
+	Errors      int64 `json:"errors" xorm:"errors"`
+	RateLimited int64 `json:"rateLimited" xorm:"rate_limited"`
+	CacheHits   int64 `json:"cacheHits" xorm:"cache_hits"`
+}
+
+func (c *UsageCounts) Add(other UsageCounts) {
+	c.Views += other.Views
+	c.Queries += other.Queries
+	c.Errors += other.Errors
+	c.RateLimited += other.RateLimited
+	c.CacheHits += other.CacheHits
+}
+
+type UsageQuery struct {
+	OrgId              int64
+	PublicDashboardUid string
+	// From and To are the first and last days of the usage, formatted as 2006-01-02
+	From string
+	To   string
+}
+
+type UsageResponse struct {
+	Days  []*Usage    `json:"days"`
+	Total UsageCounts `json:"total"`
+}
+
 //
 // COMMANDS
 //
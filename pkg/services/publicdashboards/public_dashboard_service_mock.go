@@ -384,6 +384,36 @@ func (_m *FakePublicDashboardService) FindPublicDashboardAndDashboardByAccessTok
 	return r0, r1, r2
 }
 
+// FindUsage provides a mock function with given fields: ctx, query, dashboardUid
+func (_m *FakePublicDashboardService) FindUsage(ctx context.Context, query *models.UsageQuery, dashboardUid string) (*models.UsageResponse, error) {
+	ret := _m.Called(ctx, query, dashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindUsage")
+	}
+
+	var r0 *models.UsageResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UsageQuery, string) (*models.UsageResponse, error)); ok {
+		return rf(ctx, query, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UsageQuery, string) *models.UsageResponse); ok {
+		r0 = rf(ctx, query, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UsageResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UsageQuery, string) error); ok {
+		r1 = rf(ctx, query, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetricRequest provides a mock function with given fields: ctx, dashboard, publicDashboard, panelId, reqDTO
 func (_m *FakePublicDashboardService) GetMetricRequest(ctx context.Context, dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	ret := _m.Called(ctx, dashboard, publicDashboard, panelId, reqDTO)
@@ -556,6 +586,34 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RotateSigningKey provides a mock function with given fields: ctx, u, uid, dashboardUid
+func (_m *FakePublicDashboardService) RotateSigningKey(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (string, error) {
+	ret := _m.Called(ctx, u, uid, dashboardUid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateSigningKey")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) (string, error)); ok {
+		return rf(ctx, u, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, string, string) string); ok {
+		r0 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, string, string) error); ok {
+		r1 = rf(ctx, u, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -264,6 +264,36 @@ func (_m *FakePublicDashboardStore) FindByDashboardUid(ctx context.Context, orgI
 	return r0, r1
 }
 
+// FindUsage provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardStore) FindUsage(ctx context.Context, query *models.UsageQuery) ([]*models.Usage, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindUsage")
+	}
+
+	var r0 []*models.Usage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UsageQuery) ([]*models.Usage, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UsageQuery) []*models.Usage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Usage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.UsageQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetrics provides a mock function with given fields: ctx
 func (_m *FakePublicDashboardStore) GetMetrics(ctx context.Context) (*models.Metrics, error) {
 	ret := _m.Called(ctx)
@@ -322,6 +352,52 @@ func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, a
 	return r0, r1
 }
 
+// IncrementUsage provides a mock function with given fields: ctx, orgId, uid, day, counts
+func (_m *FakePublicDashboardStore) IncrementUsage(ctx context.Context, orgId int64, uid string, day string, counts models.UsageCounts) error {
+	ret := _m.Called(ctx, orgId, uid, day, counts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementUsage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, models.UsageCounts) error); ok {
+		r0 = rf(ctx, orgId, uid, day, counts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetSigningKey provides a mock function with given fields: ctx, uid, signingKey
+func (_m *FakePublicDashboardStore) SetSigningKey(ctx context.Context, uid string, signingKey []byte) (int64, error) {
+	ret := _m.Called(ctx, uid, signingKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSigningKey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) (int64, error)); ok {
+		return rf(ctx, uid, signingKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) int64); ok {
+		r0 = rf(ctx, uid, signingKey)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte) error); ok {
+		r1 = rf(ctx, uid, signingKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
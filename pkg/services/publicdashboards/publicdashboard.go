@@ -41,6 +41,9 @@ type Service interface {
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 
 	GetSQLSchemas(ctx context.Context, user identity.Requester, reqDTO dtos.MetricRequest) (queryV0.SQLSchemas, error)
+
+	RotateSigningKey(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (string, error)
+	FindUsage(ctx context.Context, query *UsageQuery, dashboardUid string) (*UsageResponse, error)
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -62,11 +65,15 @@ type Store interface {
 	Update(ctx context.Context, cmd SavePublicDashboardCommand) (int64, error)
 	Delete(ctx context.Context, uid string) (int64, error)
 	DeleteByDashboardUIDs(ctx context.Context, orgId int64, dashboardUIDs []string) error
+	SetSigningKey(ctx context.Context, uid string, signingKey []byte) (int64, error)
 
 	GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error)
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	IncrementUsage(ctx context.Context, orgId int64, uid string, day string, counts UsageCounts) error
+	FindUsage(ctx context.Context, query *UsageQuery) ([]*Usage, error)
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"time"
 
@@ -25,6 +29,11 @@ func (pd *PublicDashboardServiceImpl) FindAnnotations(ctx context.Context, reqDT
 		return nil, err
 	}
 
+	claims, err := pd.authorizeViewer(ctx, pub)
+	if err != nil {
+		return nil, err
+	}
+
 	if !pub.AnnotationsEnabled {
 		return []models.AnnotationEvent{}, nil
 	}
@@ -36,6 +45,12 @@ func (pd *PublicDashboardServiceImpl) FindAnnotations(ctx context.Context, reqDT
 
 	// Use dashboard time range if time selection is disabled, otherwise use request time range
 	from, to := getAnnotationsTimeRange(dash, reqDTO, pub.TimeSelectionEnabled)
+	if claims != nil && claims.TimeRange != nil {
+		from, to, err = clampTimeRange(from, to, claims.TimeRange, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// We don't have a signed in user for public dashboards. We are using Grafana's Identity to query the annotations.
 	svcCtx, svcIdent := identity.WithServiceIdentity(ctx, dash.OrgID)
@@ -135,6 +150,16 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, err
 	}
 
+	claims, err := pd.authorizeViewer(ctx, publicDashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pd.allowQuery(publicDashboard) {
+		pd.recordUsage(publicDashboard, models.UsageCounts{RateLimited: 1})
+		return nil, models.ErrRateLimited.Errorf("GetQueryDataResponse: rate limit of public dashboard %s exceeded", publicDashboard.Uid)
+	}
+
 	metricReq, err := pd.GetMetricRequest(ctx, dashboard, publicDashboard, panelId, queryDto)
 	if err != nil {
 		return nil, err
@@ -144,6 +169,24 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 		return nil, models.ErrPanelQueriesNotFound.Errorf("GetQueryDataResponse: failed to extract queries from panel")
 	}
 
+	if claims != nil {
+		if err := applyViewerClaims(&metricReq, claims, queryDto.Variables, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheKey := ""
+	if publicDashboard.QueryCacheTTL > 0 && pd.queryCache != nil {
+		cacheKey, err = queryCacheKey(publicDashboard, panelId, metricReq)
+		if err != nil {
+			return nil, models.ErrInternalServerError.Errorf("GetQueryDataResponse: failed to build query cache key: %w", err)
+		}
+		if cached, ok := pd.queryCache.Get(cacheKey); ok && !skipDSCache {
+			pd.recordUsage(publicDashboard, models.UsageCounts{Queries: 1, CacheHits: 1})
+			return cached.(*backend.QueryDataResponse), nil
+		}
+	}
+
 	// We don't have a signed in user for public dashboards. We are using Grafana's Identity to query the datasource.
 	svcCtx, svcIdent := identity.WithServiceIdentity(ctx, dashboard.OrgID)
 	res, err := pd.QueryDataService.QueryData(svcCtx, svcIdent, skipDSCache, metricReq)
@@ -151,15 +194,46 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 	reqDatasources := metricReq.GetUniqueDatasourceTypes()
 	if err != nil {
 		LogQueryFailure(reqDatasources, pd.log, err)
+		pd.recordUsage(publicDashboard, models.UsageCounts{Queries: 1, Errors: 1})
 		return nil, err
 	}
 	LogQuerySuccess(reqDatasources, pd.log)
 
 	sanitizeMetadataFromQueryData(res)
 
+	if queryDataHasErrors(res) {
+		pd.recordUsage(publicDashboard, models.UsageCounts{Queries: 1, Errors: 1})
+		return res, nil
+	}
+
+	pd.recordUsage(publicDashboard, models.UsageCounts{Queries: 1})
+	if cacheKey != "" {
+		pd.queryCache.Set(cacheKey, res, time.Duration(publicDashboard.QueryCacheTTL)*time.Second)
+	}
+
 	return res, nil
 }
 
+// queryCacheKey identifies the result of a panel query. The metric request includes the time range and the variables
+// of the viewer, so viewers of signed public dashboards never share results they are not allowed to see.
+func queryCacheKey(publicDashboard *models.PublicDashboard, panelId int64, metricReq dtos.MetricRequest) (string, error) {
+	b, err := json.Marshal(metricReq)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("publicdashboards-query-%s-%d-%s", publicDashboard.Uid, panelId, hex.EncodeToString(sum[:])), nil
+}
+
+func queryDataHasErrors(res *backend.QueryDataResponse) bool {
+	for _, resp := range res.Responses {
+		if resp.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // buildMetricRequest merges public dashboard parameters with dashboard and returns a metrics request to be sent to query backend
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelID int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
 	isV2 := dashboard.Data.Get("elements").Interface() != nil
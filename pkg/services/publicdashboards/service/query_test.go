@@ -364,6 +364,83 @@ func TestIntegrationGetQueryDataResponse(t *testing.T) {
 	})
 }
 
+func TestIntegrationGetQueryDataResponseLimits(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+
+	fakeDashboardService := &dashboards.FakeDashboardService{}
+	service, sqlStore, _ := newPublicDashboardServiceImpl(t, nil, nil, nil, fakeDashboardService, nil)
+	fakeQueryService := &query.FakeQueryService{}
+	fakeQueryService.On("QueryData", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&backend.QueryDataResponse{}, nil)
+	service.QueryDataService = fakeQueryService
+
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, service.cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore))
+	require.NoError(t, err)
+
+	customPanels := []interface{}{
+		map[string]interface{}{
+			"id": 1,
+			"datasource": map[string]interface{}{
+				"uid": "ds1",
+			},
+			"targets": []interface{}{
+				map[string]interface{}{
+					"datasource": map[string]interface{}{
+						"type": "mysql",
+						"uid":  "ds1",
+					},
+					"rawSql": "SELECT * FROM metrics",
+					"refId":  "A",
+				},
+			},
+		}}
+	dashboard := insertTestDashboard(t, dashboardStore, "testDashWithLimits", 1, 0, "", true, []map[string]interface{}{}, customPanels)
+	fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
+
+	isEnabled := true
+	rateLimit := int64(2)
+	queryCacheTTL := int64(60)
+	pubdash, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+		DashboardUid: dashboard.UID,
+		UserId:       7,
+		OrgID:        dashboard.OrgID,
+		PublicDashboard: &PublicDashboardDTO{
+			IsEnabled:     &isEnabled,
+			RateLimit:     &rateLimit,
+			QueryCacheTTL: &queryCacheTTL,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rateLimit, pubdash.RateLimit)
+	assert.Equal(t, queryCacheTTL, pubdash.QueryCacheTTL)
+
+	publicDashboardQueryDTO := PublicDashboardQueryDTO{
+		IntervalMs:    int64(1),
+		MaxDataPoints: int64(1),
+	}
+
+	t.Run("Returns cached query data", func(t *testing.T) {
+		_, err := service.GetQueryDataResponse(context.Background(), false, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+		_, err = service.GetQueryDataResponse(context.Background(), false, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.NoError(t, err)
+
+		fakeQueryService.AssertNumberOfCalls(t, "QueryData", 1)
+	})
+
+	t.Run("Rejects queries over the rate limit", func(t *testing.T) {
+		_, err := service.GetQueryDataResponse(context.Background(), false, publicDashboardQueryDTO, 1, pubdash.AccessToken)
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("Records the usage", func(t *testing.T) {
+		service.flushUsage(context.Background())
+
+		usage, err := service.FindUsage(context.Background(), &UsageQuery{OrgId: dashboard.OrgID, PublicDashboardUid: pubdash.Uid}, dashboard.UID)
+		require.NoError(t, err)
+		assert.Equal(t, UsageCounts{Queries: 2, CacheHits: 1, RateLimited: 1}, usage.Total)
+	})
+}
+
 func TestIntegrationFindAnnotations(t *testing.T) {
 	testutil.SkipIntegrationTestInShortMode(t)
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,8 +16,10 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	queryV0 "github.com/grafana/grafana/pkg/apis/datasource/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -28,6 +31,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -48,6 +52,11 @@ type PublicDashboardServiceImpl struct {
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
 	license            licensing.Licensing
+	secretsService     secrets.Service
+	// rateLimiters holds a rate limiter per public dashboard uid
+	rateLimiters sync.Map
+	queryCache   *localcache.CacheService
+	usage        *usageRecorder
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -56,6 +65,7 @@ var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/publicdashboar
 // Gives us compile time error if the service does not adhere to the contract of
 // the interface
 var _ publicdashboards.Service = (*PublicDashboardServiceImpl)(nil)
+var _ registry.BackgroundService = (*PublicDashboardServiceImpl)(nil)
 
 // ProvideService Factory for method used by wire to inject dependencies.
 // builds the service, and api, and configures routes
@@ -69,6 +79,7 @@ func ProvideService(
 	serviceWrapper publicdashboards.ServiceWrapper,
 	dashboardService dashboards.DashboardService,
 	license licensing.Licensing,
+	secretsService secrets.Service,
 ) *PublicDashboardServiceImpl {
 	return &PublicDashboardServiceImpl{
 		log:                log.New(LogPrefix),
@@ -82,6 +93,9 @@ func ProvideService(
 		serviceWrapper:     serviceWrapper,
 		dashboardService:   dashboardService,
 		license:            license,
+		secretsService:     secretsService,
+		queryCache:         localcache.New(5*time.Minute, 10*time.Minute),
+		usage:              newUsageRecorder(),
 	}
 }
 
@@ -94,6 +108,11 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 		return nil, err
 	}
 
+	if _, err := pd.authorizeViewer(ctx, pubdash); err != nil {
+		return nil, err
+	}
+	pd.recordUsage(pubdash, UsageCounts{Views: 1})
+
 	metrics.MFolderIDsServiceCount.WithLabelValues(metrics.PublicDashboards).Inc()
 	meta := dtos.DashboardMeta{
 		Slug:                   dash.Slug,
@@ -577,6 +596,9 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		share = PublicShareType
 	}
 
+	rateLimit := returnInt64OrDefault(dto.PublicDashboard.RateLimit, 0)
+	queryCacheTTL := returnInt64OrDefault(dto.PublicDashboard.QueryCacheTTL, 0)
+
 	now := time.Now()
 
 	return &PublicDashboard{
@@ -588,6 +610,8 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
 		Share:                share,
+		RateLimit:            rateLimit,
+		QueryCacheTTL:        queryCacheTTL,
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
@@ -607,6 +631,9 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	rateLimit := returnInt64OrDefault(pubdashDTO.RateLimit, pd.RateLimit)
+	queryCacheTTL := returnInt64OrDefault(pubdashDTO.QueryCacheTTL, pd.QueryCacheTTL)
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
@@ -614,6 +641,8 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		RateLimit:            rateLimit,
+		QueryCacheTTL:        queryCacheTTL,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
@@ -626,3 +655,11 @@ func returnValueOrDefault(value *bool, defaultValue bool) bool {
 
 	return defaultValue
 }
+
+func returnInt64OrDefault(value *int64, defaultValue int64) int64 {
+	if value != nil {
+		return *value
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+const (
+	usageDayLayout     = "2006-01-02"
+	usageFlushInterval = time.Minute
+	// maxUsageRange is the longest range of days the usage can be queried for
+	maxUsageRange = 366 * 24 * time.Hour
+)
+
+type usageKey struct {
+	orgId int64
+	uid   string
+	day   string
+}
+
+// usageRecorder counts the usage of public dashboards in memory until it is flushed to the database, so views and
+// queries do not write to the database
+type usageRecorder struct {
+	mu      sync.Mutex
+	pending map[usageKey]*UsageCounts
+}
+
+func newUsageRecorder() *usageRecorder {
+	return &usageRecorder{pending: make(map[usageKey]*UsageCounts)}
+}
+
+func (r *usageRecorder) record(pubdash *PublicDashboard, counts UsageCounts, now time.Time) {
+	key := usageKey{orgId: pubdash.OrgId, uid: pubdash.Uid, day: now.UTC().Format(usageDayLayout)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending[key] == nil {
+		r.pending[key] = &UsageCounts{}
+	}
+	r.pending[key].Add(counts)
+}
+
+func (r *usageRecorder) take() map[usageKey]*UsageCounts {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending := r.pending
+	r.pending = make(map[usageKey]*UsageCounts)
+	return pending
+}
+
+// Run flushes the usage of public dashboards to the database until Grafana shuts down
+func (pd *PublicDashboardServiceImpl) Run(ctx context.Context) error {
+	ticker := time.NewTicker(usageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pd.flushUsage(ctx)
+		case <-ctx.Done():
+			// flush the usage counted since the last tick before shutting down
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			pd.flushUsage(flushCtx)
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+func (pd *PublicDashboardServiceImpl) IsDisabled() bool {
+	return !pd.cfg.PublicDashboardsEnabled
+}
+
+func (pd *PublicDashboardServiceImpl) flushUsage(ctx context.Context) {
+	for key, counts := range pd.usage.take() {
+		if err := pd.store.IncrementUsage(ctx, key.orgId, key.uid, key.day, *counts); err != nil {
+			pd.log.Warn("Failed to save public dashboard usage", "publicDashboardUid", key.uid, "day", key.day, "error", err)
+		}
+	}
+}
+
+func (pd *PublicDashboardServiceImpl) recordUsage(pubdash *PublicDashboard, counts UsageCounts) {
+	pd.usage.record(pubdash, counts, time.Now())
+}
+
+// FindUsage returns the daily usage of a public dashboard. The usage of the last minute may not be included yet.
+func (pd *PublicDashboardServiceImpl) FindUsage(ctx context.Context, query *UsageQuery, dashboardUid string) (*UsageResponse, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.FindUsage")
+	defer span.End()
+
+	existingPubdash, err := pd.store.Find(ctx, query.PublicDashboardUid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindUsage: failed to find public dashboard by uid: %s: %w", query.PublicDashboardUid, err)
+	}
+	if existingPubdash == nil || existingPubdash.OrgId != query.OrgId {
+		return nil, ErrPublicDashboardNotFound.Errorf("FindUsage: public dashboard not found by uid: %s", query.PublicDashboardUid)
+	}
+	if existingPubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("FindUsage: the public dashboard does not belong to the dashboard")
+	}
+
+	now := time.Now().UTC()
+	if query.To == "" {
+		query.To = now.Format(usageDayLayout)
+	}
+	if query.From == "" {
+		query.From = now.AddDate(0, 0, -29).Format(usageDayLayout)
+	}
+	from, err := time.Parse(usageDayLayout, query.From)
+	if err != nil {
+		return nil, ErrInvalidUsageRange.Errorf("FindUsage: invalid from %q: %w", query.From, err)
+	}
+	to, err := time.Parse(usageDayLayout, query.To)
+	if err != nil {
+		return nil, ErrInvalidUsageRange.Errorf("FindUsage: invalid to %q: %w", query.To, err)
+	}
+	if to.Before(from) || to.Sub(from) > maxUsageRange {
+		return nil, ErrInvalidUsageRange.Errorf("FindUsage: invalid range from %s to %s", query.From, query.To)
+	}
+
+	days, err := pd.store.FindUsage(ctx, query)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindUsage: failed to find usage: %w", err)
+	}
+
+	resp := &UsageResponse{Days: days}
+	for _, day := range days {
+		resp.Total.Add(day.UsageCounts)
+	}
+	return resp, nil
+}
+
+type rateLimiter struct {
+	limit   int64
+	limiter *rate.Limiter
+}
+
+// allowQuery applies the rate limit of a public dashboard to its panel queries. Limits are enforced per Grafana
+// instance.
+func (pd *PublicDashboardServiceImpl) allowQuery(pubdash *PublicDashboard) bool {
+	if pubdash.RateLimit <= 0 {
+		pd.rateLimiters.Delete(pubdash.Uid)
+		return true
+	}
+
+	v, ok := pd.rateLimiters.Load(pubdash.Uid)
+	if ok && v.(*rateLimiter).limit == pubdash.RateLimit {
+		return v.(*rateLimiter).limiter.Allow()
+	}
+
+	// the limit is per minute and allows bursts of a minute of queries
+	limiter := &rateLimiter{
+		limit:   pubdash.RateLimit,
+		limiter: rate.NewLimiter(rate.Limit(float64(pubdash.RateLimit)/60), int(pubdash.RateLimit)),
+	}
+	if ok {
+		// the rate limit of the public dashboard was updated
+		pd.rateLimiters.Store(pubdash.Uid, limiter)
+	} else if v, loaded := pd.rateLimiters.LoadOrStore(pubdash.Uid, limiter); loaded {
+		limiter = v.(*rateLimiter)
+	}
+	return limiter.limiter.Allow()
+}
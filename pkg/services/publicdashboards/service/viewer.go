@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// viewerTokenClaims are the JWT claims of a viewer token
+type viewerTokenClaims struct {
+	jwt.RegisteredClaims
+	ViewerClaims
+}
+
+// RotateSigningKey generates a new signing key for a signed public dashboard. Viewer tokens signed with the previous
+// key are rejected from then on.
+func (pd *PublicDashboardServiceImpl) RotateSigningKey(ctx context.Context, u *user.SignedInUser, uid string, dashboardUid string) (string, error) {
+	ctx, span := tracer.Start(ctx, "publicdashboards.RotateSigningKey")
+	defer span.End()
+
+	existingPubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return "", ErrInternalServerError.Errorf("RotateSigningKey: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+	if existingPubdash == nil {
+		return "", ErrPublicDashboardNotFound.Errorf("RotateSigningKey: public dashboard not found by uid: %s", uid)
+	}
+	if existingPubdash.DashboardUid != dashboardUid {
+		return "", ErrInvalidUid.Errorf("RotateSigningKey: the public dashboard does not belong to the dashboard")
+	}
+	if existingPubdash.Share != SignedShareType {
+		return "", ErrNotSignedShareType.Errorf("RotateSigningKey: public dashboard %s is shared with %s", uid, existingPubdash.Share)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", ErrInternalServerError.Errorf("RotateSigningKey: failed to generate signing key: %w", err)
+	}
+	signingKey := hex.EncodeToString(b)
+
+	encrypted, err := pd.secretsService.Encrypt(ctx, []byte(signingKey), secrets.WithoutScope())
+	if err != nil {
+		return "", ErrInternalServerError.Errorf("RotateSigningKey: failed to encrypt signing key: %w", err)
+	}
+
+	affectedRows, err := pd.store.SetSigningKey(ctx, uid, encrypted)
+	if err != nil {
+		return "", ErrInternalServerError.Errorf("RotateSigningKey: failed to save signing key: %w", err)
+	}
+	if affectedRows == 0 {
+		return "", ErrPublicDashboardNotFound.Errorf("RotateSigningKey: public dashboard not found by uid: %s", uid)
+	}
+
+	pd.log.Info("Public dashboard signing key rotated", "publicDashboardUid", uid, "dashboardUid", dashboardUid, "user", u.Login)
+
+	return signingKey, nil
+}
+
+// authorizeViewer verifies the viewer token of a request to a signed public dashboard and returns its claims. It
+// returns nil claims for the other share types.
+func (pd *PublicDashboardServiceImpl) authorizeViewer(ctx context.Context, pubdash *PublicDashboard) (*ViewerClaims, error) {
+	if pubdash.Share != SignedShareType {
+		return nil, nil
+	}
+
+	token := ViewerTokenFromContext(ctx)
+	if token == "" {
+		return nil, ErrViewerTokenRequired.Errorf("authorizeViewer: no viewer token for public dashboard %s", pubdash.Uid)
+	}
+	if len(pubdash.SigningKey) == 0 {
+		return nil, ErrInvalidViewerToken.Errorf("authorizeViewer: public dashboard %s has no signing key", pubdash.Uid)
+	}
+
+	signingKey, err := pd.secretsService.Decrypt(ctx, pubdash.SigningKey)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("authorizeViewer: failed to decrypt signing key: %w", err)
+	}
+
+	claims := &viewerTokenClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, ErrInvalidViewerToken.Errorf("authorizeViewer: invalid viewer token for public dashboard %s: %w", pubdash.Uid, err)
+	}
+	// viewer tokens are handed out to browsers, so they must expire
+	if claims.ExpiresAt == nil {
+		return nil, ErrInvalidViewerToken.Errorf("authorizeViewer: viewer token for public dashboard %s has no expiry", pubdash.Uid)
+	}
+
+	claims.Viewer = claims.Subject
+	return &claims.ViewerClaims, nil
+}
+
+// applyViewerClaims restricts a metric request to the variables and time range allowed by the viewer claims
+func applyViewerClaims(metricReq *dtos.MetricRequest, claims *ViewerClaims, selected map[string][]string, now time.Time) error {
+	variables, err := resolveViewerVariables(claims.Variables, selected)
+	if err != nil {
+		return err
+	}
+	if len(variables) > 0 {
+		for i, query := range metricReq.Queries {
+			metricReq.Queries[i] = simplejson.NewFromAny(templatevars.Interpolate(query.Interface(), variables))
+		}
+	}
+
+	if claims.TimeRange == nil {
+		return nil
+	}
+
+	from, err := strconv.ParseInt(metricReq.From, 10, 64)
+	if err != nil {
+		return ErrBadRequest.Errorf("applyViewerClaims: invalid from: %w", err)
+	}
+	to, err := strconv.ParseInt(metricReq.To, 10, 64)
+	if err != nil {
+		return ErrBadRequest.Errorf("applyViewerClaims: invalid to: %w", err)
+	}
+
+	from, to, err = clampTimeRange(from, to, claims.TimeRange, now)
+	if err != nil {
+		return err
+	}
+
+	metricReq.From = strconv.FormatInt(from, 10)
+	metricReq.To = strconv.FormatInt(to, 10)
+	return nil
+}
+
+// resolveViewerVariables returns the values of the variables of the claims, narrowed to the values selected by the
+// viewer. Viewers can only select values allowed by their claims.
+func resolveViewerVariables(allowed map[string][]string, selected map[string][]string) (map[string][]string, error) {
+	variables := make(map[string][]string, len(allowed))
+	for name, values := range allowed {
+		variables[name] = values
+	}
+
+	for name, values := range selected {
+		allowedValues, ok := allowed[name]
+		if !ok {
+			return nil, ErrVariableNotAllowed.Errorf("resolveViewerVariables: variable %s is not allowed", name)
+		}
+		for _, value := range values {
+			if !slices.Contains(allowedValues, value) {
+				return nil, ErrVariableNotAllowed.Errorf("resolveViewerVariables: value %q of variable %s is not allowed", value, name)
+			}
+		}
+		if len(values) > 0 {
+			variables[name] = values
+		}
+	}
+
+	return variables, nil
+}
+
+// clampTimeRange limits a time range in epoch milliseconds to the time range of the viewer claims
+func clampTimeRange(from, to int64, limits *ViewerTimeRange, now time.Time) (int64, int64, error) {
+	if limits.From != "" {
+		tr := gtime.TimeRange{From: limits.From, To: "now", Now: now}
+		minFrom, err := tr.ParseFrom()
+		if err != nil {
+			return 0, 0, ErrInvalidViewerToken.Errorf("clampTimeRange: invalid time range from %q: %w", limits.From, err)
+		}
+		from = max(from, minFrom.UnixMilli())
+	}
+
+	if limits.To != "" {
+		tr := gtime.TimeRange{From: "now", To: limits.To, Now: now}
+		maxTo, err := tr.ParseTo()
+		if err != nil {
+			return 0, 0, ErrInvalidViewerToken.Errorf("clampTimeRange: invalid time range to %q: %w", limits.To, err)
+		}
+		to = min(to, maxTo.UnixMilli())
+	}
+
+	if limits.MaxDuration != "" {
+		maxDuration, err := gtime.ParseDuration(limits.MaxDuration)
+		if err != nil {
+			return 0, 0, ErrInvalidViewerToken.Errorf("clampTimeRange: invalid max duration %q: %w", limits.MaxDuration, err)
+		}
+		from = max(from, to-maxDuration.Milliseconds())
+	}
+
+	if from > to {
+		from = to
+	}
+
+	return from, to, nil
+}
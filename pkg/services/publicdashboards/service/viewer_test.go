@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+)
+
+func signViewerToken(t *testing.T, key string, claims jwt.Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthorizeViewer(t *testing.T) {
+	pd := &PublicDashboardServiceImpl{secretsService: fakes.NewFakeSecretsService()}
+	// the fake secrets service does not encrypt
+	pubdash := &PublicDashboard{Uid: "pubdash", Share: SignedShareType, SigningKey: []byte("signing-key")}
+
+	validClaims := jwt.MapClaims{
+		"sub":       "customer-1",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"vars":      map[string]any{"customer": []string{"acme"}},
+		"timeRange": map[string]any{"maxDuration": "7d"},
+	}
+
+	t.Run("returns the claims of a valid token", func(t *testing.T) {
+		ctx := WithViewerToken(context.Background(), signViewerToken(t, "signing-key", validClaims))
+		claims, err := pd.authorizeViewer(ctx, pubdash)
+		require.NoError(t, err)
+		assert.Equal(t, &ViewerClaims{
+			Viewer:    "customer-1",
+			Variables: map[string][]string{"customer": {"acme"}},
+			TimeRange: &ViewerTimeRange{MaxDuration: "7d"},
+		}, claims)
+	})
+
+	t.Run("does not require a token for public share type", func(t *testing.T) {
+		claims, err := pd.authorizeViewer(context.Background(), &PublicDashboard{Share: PublicShareType})
+		require.NoError(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		_, err := pd.authorizeViewer(context.Background(), pubdash)
+		require.ErrorIs(t, err, ErrViewerTokenRequired)
+	})
+
+	t.Run("rejects a token signed with another key", func(t *testing.T) {
+		ctx := WithViewerToken(context.Background(), signViewerToken(t, "other-key", validClaims))
+		_, err := pd.authorizeViewer(ctx, pubdash)
+		require.ErrorIs(t, err, ErrInvalidViewerToken)
+	})
+
+	t.Run("rejects an expired token", func(t *testing.T) {
+		ctx := WithViewerToken(context.Background(), signViewerToken(t, "signing-key", jwt.MapClaims{
+			"sub": "customer-1",
+			"exp": time.Now().Add(-time.Minute).Unix(),
+		}))
+		_, err := pd.authorizeViewer(ctx, pubdash)
+		require.ErrorIs(t, err, ErrInvalidViewerToken)
+	})
+
+	t.Run("rejects a token without expiry", func(t *testing.T) {
+		ctx := WithViewerToken(context.Background(), signViewerToken(t, "signing-key", jwt.MapClaims{"sub": "customer-1"}))
+		_, err := pd.authorizeViewer(ctx, pubdash)
+		require.ErrorIs(t, err, ErrInvalidViewerToken)
+	})
+
+	t.Run("rejects tokens when the public dashboard has no signing key", func(t *testing.T) {
+		ctx := WithViewerToken(context.Background(), signViewerToken(t, "signing-key", validClaims))
+		_, err := pd.authorizeViewer(ctx, &PublicDashboard{Share: SignedShareType})
+		require.ErrorIs(t, err, ErrInvalidViewerToken)
+	})
+}
+
+func TestApplyViewerClaims(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	newMetricRequest := func(from, to time.Time) dtos.MetricRequest {
+		query := simplejson.New()
+		query.Set("rawSql", "SELECT * FROM sales WHERE customer IN (${customer:singlequote}) AND region = '$region'")
+		query.Set("expr", `up{customer=~"${customer:regex}"}`)
+		return dtos.MetricRequest{
+			From:    strconv.FormatInt(from.UnixMilli(), 10),
+			To:      strconv.FormatInt(to.UnixMilli(), 10),
+			Queries: []*simplejson.Json{query},
+		}
+	}
+	claims := &ViewerClaims{
+		Variables: map[string][]string{"customer": {"acme", "globex"}, "region": {"eu"}},
+		TimeRange: &ViewerTimeRange{From: "now-30d", To: "now", MaxDuration: "7d"},
+	}
+
+	t.Run("interpolates the variables of the claims", func(t *testing.T) {
+		metricReq := newMetricRequest(now.Add(-time.Hour), now)
+		require.NoError(t, applyViewerClaims(&metricReq, claims, nil, now))
+
+		assert.Equal(t, "SELECT * FROM sales WHERE customer IN ('acme','globex') AND region = 'eu'", metricReq.Queries[0].Get("rawSql").MustString())
+		assert.Equal(t, `up{customer=~"(acme|globex)"}`, metricReq.Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("narrows the variables to the values selected by the viewer", func(t *testing.T) {
+		metricReq := newMetricRequest(now.Add(-time.Hour), now)
+		require.NoError(t, applyViewerClaims(&metricReq, claims, map[string][]string{"customer": {"globex"}}, now))
+
+		assert.Equal(t, "SELECT * FROM sales WHERE customer IN ('globex') AND region = 'eu'", metricReq.Queries[0].Get("rawSql").MustString())
+	})
+
+	t.Run("rejects values not allowed by the claims", func(t *testing.T) {
+		metricReq := newMetricRequest(now.Add(-time.Hour), now)
+		err := applyViewerClaims(&metricReq, claims, map[string][]string{"customer": {"initech"}}, now)
+		require.ErrorIs(t, err, ErrVariableNotAllowed)
+
+		err = applyViewerClaims(&metricReq, claims, map[string][]string{"env": {"prod"}}, now)
+		require.ErrorIs(t, err, ErrVariableNotAllowed)
+	})
+
+	t.Run("clamps the time range to the max duration", func(t *testing.T) {
+		metricReq := newMetricRequest(now.AddDate(0, 0, -20), now)
+		require.NoError(t, applyViewerClaims(&metricReq, claims, nil, now))
+
+		assert.Equal(t, strconv.FormatInt(now.AddDate(0, 0, -7).UnixMilli(), 10), metricReq.From)
+		assert.Equal(t, strconv.FormatInt(now.UnixMilli(), 10), metricReq.To)
+	})
+
+	t.Run("clamps the time range to the allowed range", func(t *testing.T) {
+		metricReq := newMetricRequest(now.AddDate(0, 0, -60), now.AddDate(0, 0, 1))
+		require.NoError(t, applyViewerClaims(&metricReq, &ViewerClaims{TimeRange: &ViewerTimeRange{From: "now-30d", To: "now"}}, nil, now))
+
+		assert.Equal(t, strconv.FormatInt(now.AddDate(0, 0, -30).UnixMilli(), 10), metricReq.From)
+		assert.Equal(t, strconv.FormatInt(now.UnixMilli(), 10), metricReq.To)
+	})
+}
+
+func TestAllowQuery(t *testing.T) {
+	pd := &PublicDashboardServiceImpl{}
+	pubdash := &PublicDashboard{Uid: "pubdash", RateLimit: 2}
+
+	assert.True(t, pd.allowQuery(pubdash))
+	assert.True(t, pd.allowQuery(pubdash))
+	assert.False(t, pd.allowQuery(pubdash))
+
+	// updating the limit resets the limiter
+	pubdash.RateLimit = 3
+	assert.True(t, pd.allowQuery(pubdash))
+
+	pubdash.RateLimit = 0
+	for i := 0; i < 10; i++ {
+		assert.True(t, pd.allowQuery(pubdash))
+	}
+}
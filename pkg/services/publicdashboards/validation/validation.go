@@ -13,6 +13,14 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if dto.PublicDashboard.RateLimit != nil && *dto.PublicDashboard.RateLimit < 0 {
+		return ErrInvalidRateLimit.Errorf("ValidateSavePublicDashboard: rate limit cannot be negative")
+	}
+
+	if dto.PublicDashboard.QueryCacheTTL != nil && *dto.PublicDashboard.QueryCacheTTL < 0 {
+		return ErrInvalidQueryCacheTTL.Errorf("ValidateSavePublicDashboard: query cache TTL cannot be negative")
+	}
+
 	return nil
 }
 
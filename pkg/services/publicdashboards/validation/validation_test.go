@@ -22,6 +22,15 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when rate limit or query cache TTL is negative", func(t *testing.T) {
+		negative := int64(-1)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{Share: SignedShareType, RateLimit: &negative}}
+		require.ErrorIs(t, ValidatePublicDashboard(dto), ErrInvalidRateLimit)
+
+		dto = &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{Share: SignedShareType, QueryCacheTTL: &negative}}
+		require.ErrorIs(t, ValidatePublicDashboard(dto), ErrInvalidQueryCacheTTL)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
@@ -0,0 +1,427 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	claims "github.com/grafana/authlib/types"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/scimutil"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const basePath = "/api/scim/v2"
+
+// API is a SCIM 2.0 service provider (RFC 7643, RFC 7644) for the users and teams of the organization
+// of the calling service account. Users are deprovisioned as soon as the identity provider deactivates them.
+type API struct {
+	cfg                    *setting.Cfg
+	accessControl          accesscontrol.AccessControl
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	authInfoService        login.AuthInfoService
+	sessionService         auth.UserTokenService
+	scimUtil               *scimutil.SCIMUtil
+	log                    log.Logger
+}
+
+func ProvideAPI(
+	cfg *setting.Cfg,
+	routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl,
+	features featuremgmt.FeatureToggles,
+	userService user.Service,
+	orgService org.Service,
+	teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService,
+	authInfoService login.AuthInfoService,
+	sessionService auth.UserTokenService,
+) *API {
+	api := &API{
+		cfg:                    cfg,
+		accessControl:          accessControl,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		authInfoService:        authInfoService,
+		sessionService:         sessionService,
+		// Pass nil for k8sClient - dynamic SCIM settings fall back to the static configuration
+		scimUtil: scimutil.NewSCIMUtil(nil),
+		log:      log.New("scim.api"),
+	}
+
+	//nolint:staticcheck // not yet migrated to OpenFeature
+	if features.IsEnabledGlobally(featuremgmt.FlagEnableSCIM) {
+		api.registerRoutes(routeRegister, accessControl)
+	}
+
+	return api
+}
+
+func (api *API) registerRoutes(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+
+	readUsers := accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRead)
+	createUsers := accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersAdd)
+	writeUsers := accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersWrite)
+	removeUsers := accesscontrol.EvalPermission(accesscontrol.ActionOrgUsersRemove)
+	readGroups := accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead)
+	createGroups := accesscontrol.EvalPermission(accesscontrol.ActionTeamsCreate)
+	writeGroups := accesscontrol.EvalAll(
+		accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite, accesscontrol.ScopeTeamsAll),
+		accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite, accesscontrol.ScopeTeamsAll),
+	)
+	deleteGroups := accesscontrol.EvalPermission(accesscontrol.ActionTeamsDelete, accesscontrol.ScopeTeamsAll)
+
+	router.Group(basePath, func(scimRoute routing.RouteRegister) {
+		scimRoute.Get("/ServiceProviderConfig", routing.Wrap(api.getServiceProviderConfig))
+		scimRoute.Get("/ResourceTypes", routing.Wrap(api.getResourceTypes))
+
+		scimRoute.Get("/Users", authorize(readUsers), routing.Wrap(api.listUsersHandler))
+		scimRoute.Post("/Users", authorize(createUsers), routing.Wrap(api.createUserHandler))
+		scimRoute.Get("/Users/:id", authorize(readUsers), routing.Wrap(api.getUserHandler))
+		scimRoute.Put("/Users/:id", authorize(writeUsers), routing.Wrap(api.replaceUserHandler))
+		scimRoute.Patch("/Users/:id", authorize(writeUsers), routing.Wrap(api.patchUserHandler))
+		scimRoute.Delete("/Users/:id", authorize(removeUsers), routing.Wrap(api.deleteUserHandler))
+
+		scimRoute.Get("/Groups", authorize(readGroups), routing.Wrap(api.listGroupsHandler))
+		scimRoute.Post("/Groups", authorize(createGroups), routing.Wrap(api.createGroupHandler))
+		scimRoute.Get("/Groups/:id", authorize(readGroups), routing.Wrap(api.getGroupHandler))
+		scimRoute.Put("/Groups/:id", authorize(writeGroups), routing.Wrap(api.replaceGroupHandler))
+		scimRoute.Patch("/Groups/:id", authorize(writeGroups), routing.Wrap(api.patchGroupHandler))
+		scimRoute.Delete("/Groups/:id", authorize(deleteGroups), routing.Wrap(api.deleteGroupHandler))
+
+		scimRoute.Post("/Bulk", authorize(accesscontrol.EvalAll(
+			createUsers, writeUsers, removeUsers, createGroups, writeGroups, deleteGroups,
+		)), routing.Wrap(api.bulkHandler))
+	}, reqServiceAccount, requestmeta.SetOwner(requestmeta.TeamAuth))
+}
+
+// reqServiceAccount only lets service account tokens through, the identity provider being the only expected client.
+func reqServiceAccount(c *contextmodel.ReqContext) {
+	if !c.IsSignedIn {
+		errorResponse(newError(http.StatusUnauthorized, "", "authentication required")).WriteTo(c)
+		return
+	}
+	if !c.IsIdentityType(claims.TypeServiceAccount) {
+		errorResponse(newError(http.StatusForbidden, "", "SCIM requests must be authenticated with a service account token")).WriteTo(c)
+	}
+}
+
+func (api *API) getServiceProviderConfig(c *contextmodel.ReqContext) response.Response {
+	supported := func(v bool) map[string]any { return map[string]any{"supported": v} }
+	return scimResponse(http.StatusOK, map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": true, "maxOperations": MaxBulkOperations, "maxPayloadSize": MaxBulkPayloadSize},
+		"filter":         map[string]any{"supported": true, "maxResults": MaxPageSize},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with a Grafana service account token",
+			"primary":     true,
+		}},
+		"meta": &Meta{ResourceType: "ServiceProviderConfig", Location: api.location("ServiceProviderConfig", "")},
+	})
+}
+
+func (api *API) getResourceTypes(c *contextmodel.ReqContext) response.Response {
+	resourceType := func(name, endpoint, schema string) map[string]any {
+		return map[string]any{
+			"schemas":  []string{SchemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta":     &Meta{ResourceType: "ResourceType", Location: api.location("ResourceTypes", name)},
+		}
+	}
+	resources := []any{
+		resourceType("User", "/Users", SchemaUser),
+		resourceType("Group", "/Groups", SchemaGroup),
+	}
+	return scimResponse(http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (api *API) listUsersHandler(c *contextmodel.ReqContext) response.Response {
+	query, err := parseListQuery(c.Req)
+	if err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.listUsers(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) createUserHandler(c *contextmodel.ReqContext) response.Response {
+	in := &User{}
+	if err := bindSCIM(c.Req, in); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.createUser(c.Req.Context(), c.SignedInUser, in)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusCreated, result).SetHeader("Location", result.Meta.Location)
+}
+
+func (api *API) getUserHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := api.getUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"])
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) replaceUserHandler(c *contextmodel.ReqContext) response.Response {
+	in := &User{}
+	if err := bindSCIM(c.Req, in); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.replaceUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], in)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) patchUserHandler(c *contextmodel.ReqContext) response.Response {
+	patch := &PatchRequest{}
+	if err := bindSCIM(c.Req, patch); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.patchUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], patch)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) deleteUserHandler(c *contextmodel.ReqContext) response.Response {
+	if err := api.deleteUser(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"]); err != nil {
+		return api.errorResponse(c, err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (api *API) listGroupsHandler(c *contextmodel.ReqContext) response.Response {
+	query, err := parseListQuery(c.Req)
+	if err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.listGroups(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) createGroupHandler(c *contextmodel.ReqContext) response.Response {
+	in := &Group{}
+	if err := bindSCIM(c.Req, in); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.createGroup(c.Req.Context(), c.SignedInUser, in)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusCreated, result).SetHeader("Location", result.Meta.Location)
+}
+
+func (api *API) getGroupHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := api.getGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], !excludesMembers(c.Req))
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) replaceGroupHandler(c *contextmodel.ReqContext) response.Response {
+	in := &Group{}
+	if err := bindSCIM(c.Req, in); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.replaceGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], in)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) patchGroupHandler(c *contextmodel.ReqContext) response.Response {
+	patch := &PatchRequest{}
+	if err := bindSCIM(c.Req, patch); err != nil {
+		return errorResponse(err)
+	}
+	result, err := api.patchGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"], patch)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+func (api *API) deleteGroupHandler(c *contextmodel.ReqContext) response.Response {
+	if err := api.deleteGroup(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":id"]); err != nil {
+		return api.errorResponse(c, err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (api *API) bulkHandler(c *contextmodel.ReqContext) response.Response {
+	c.Req.Body = http.MaxBytesReader(c.Resp, c.Req.Body, MaxBulkPayloadSize)
+	bulk := &BulkRequest{}
+	if err := bindSCIM(c.Req, bulk); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return errorResponse(newError(http.StatusRequestEntityTooLarge, "", "bulk request exceeds %d bytes", MaxBulkPayloadSize))
+		}
+		return errorResponse(err)
+	}
+	result, err := api.bulk(c.Req.Context(), c.SignedInUser, bulk)
+	if err != nil {
+		return api.errorResponse(c, err)
+	}
+	return scimResponse(http.StatusOK, result)
+}
+
+// listQuery holds the parameters of list requests, see RFC 7644, section 3.4.2.
+type listQuery struct {
+	filter     filterExpr
+	rawFilter  string
+	startIndex int
+	count      int
+	members    bool
+}
+
+func parseListQuery(req *http.Request) (*listQuery, error) {
+	q := req.URL.Query()
+	query := &listQuery{startIndex: 1, count: DefaultPageSize, members: !excludesMembers(req), rawFilter: q.Get("filter")}
+
+	if query.rawFilter != "" {
+		filter, err := parseFilter(query.rawFilter)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, ErrTypeInvalidFilter, "invalid filter: %s", err)
+		}
+		query.filter = filter
+	}
+
+	if v := q.Get("startIndex"); v != "" {
+		startIndex, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, ErrTypeInvalidValue, "invalid startIndex %q", v)
+		}
+		// A startIndex below 1 is interpreted as 1.
+		query.startIndex = max(startIndex, 1)
+	}
+
+	if v := q.Get("count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, ErrTypeInvalidValue, "invalid count %q", v)
+		}
+		query.count = min(max(count, 0), MaxPageSize)
+	}
+
+	return query, nil
+}
+
+func excludesMembers(req *http.Request) bool {
+	for _, attr := range strings.Split(req.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(stripSchemaURN(strings.TrimSpace(attr)), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+func bindSCIM(req *http.Request, target any) error {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "failed to read request body")
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "invalid request body: %s", err)
+	}
+	return nil
+}
+
+func scimResponse(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", "application/scim+json")
+}
+
+func errorResponse(err error) *response.NormalResponse {
+	var scimErr *Error
+	if !errors.As(err, &scimErr) {
+		scimErr = errInternal("internal error", err)
+	}
+	return scimResponse(scimErr.status, scimErr)
+}
+
+// errorResponse logs the unexpected errors before answering with a SCIM error.
+func (api *API) errorResponse(c *contextmodel.ReqContext, err error) response.Response {
+	var scimErr *Error
+	if !errors.As(err, &scimErr) || scimErr.status >= http.StatusInternalServerError {
+		api.log.FromContext(c.Req.Context()).Error("SCIM request failed", "path", c.Req.URL.Path, "error", err)
+	}
+	return errorResponse(err)
+}
+
+func (api *API) location(resource, id string) string {
+	location := api.cfg.AppURL + basePath[1:] + "/" + resource
+	if id != "" {
+		location += "/" + id
+	}
+	return location
+}
+
+func (api *API) requireUserSync(ctx context.Context, orgID int64) error {
+	static := api.cfg.Raw.Section("auth.scim").Key("user_sync_enabled").MustBool(false)
+	if !api.scimUtil.IsUserSyncEnabled(ctx, orgID, static) {
+		return newError(http.StatusForbidden, "", "SCIM user sync is disabled")
+	}
+	return nil
+}
+
+func (api *API) requireGroupSync(ctx context.Context, orgID int64) error {
+	static := api.cfg.Raw.Section("auth.scim").Key("group_sync_enabled").MustBool(false)
+	if !api.scimUtil.IsGroupSyncEnabled(ctx, orgID, static) {
+		return newError(http.StatusForbidden, "", "SCIM group sync is disabled")
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+// bulk runs the operations of a bulk request in order, see RFC 7644, section 3.7.
+// Operations can reference the resources created by the previous ones with bulkId:<id>.
+func (api *API) bulk(ctx context.Context, requester identity.Requester, req *BulkRequest) (*BulkResponse, error) {
+	if len(req.Operations) > MaxBulkOperations {
+		return nil, newError(http.StatusRequestEntityTooLarge, ErrTypeTooMany, "bulk request exceeds %d operations", MaxBulkOperations)
+	}
+
+	result := &BulkResponse{
+		Schemas:    []string{SchemaBulkResponse},
+		Operations: make([]BulkOperationResult, 0, len(req.Operations)),
+	}
+	createdIDs := map[string]string{}
+	failures := 0
+
+	for _, op := range req.Operations {
+		if req.FailOnErrors > 0 && failures >= req.FailOnErrors {
+			break
+		}
+
+		opResult := BulkOperationResult{Method: strings.ToUpper(op.Method), BulkID: op.BulkID}
+		status, location, err := api.bulkOperation(ctx, requester, resolveBulkIDs(op, createdIDs))
+		if err != nil {
+			failures++
+			scimErr := &Error{}
+			if !errors.As(err, &scimErr) {
+				scimErr = errInternal("bulk operation failed", err)
+			}
+			if scimErr.status >= http.StatusInternalServerError {
+				api.log.Error("SCIM bulk operation failed", "method", op.Method, "path", op.Path, "error", err)
+			}
+			opResult.Status = scimErr.Status
+			opResult.Response = scimErr
+		} else {
+			opResult.Status = strconv.Itoa(status)
+			opResult.Location = location
+			if op.BulkID != "" && location != "" {
+				createdIDs[op.BulkID] = location[strings.LastIndexByte(location, '/')+1:]
+			}
+		}
+		result.Operations = append(result.Operations, opResult)
+	}
+
+	return result, nil
+}
+
+// bulkOperation runs a single operation and returns its status and the location of the resource.
+func (api *API) bulkOperation(ctx context.Context, requester identity.Requester, op BulkOperation) (int, string, error) {
+	method := strings.ToUpper(op.Method)
+	resource, id, _ := strings.Cut(strings.Trim(op.Path, "/"), "/")
+	if method == http.MethodPost && op.BulkID == "" {
+		return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "bulkId is required for POST operations")
+	}
+	if (method == http.MethodPost) != (id == "") {
+		return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidPath, "invalid path %q for %s", op.Path, method)
+	}
+	if strings.Contains(id, "bulkId:") {
+		return 0, "", newError(http.StatusConflict, ErrTypeInvalidValue, "unresolved reference %q", id)
+	}
+
+	switch resource {
+	case "Users":
+		switch method {
+		case http.MethodPost, http.MethodPut:
+			in := &User{}
+			if err := json.Unmarshal(op.Data, in); err != nil {
+				return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "invalid user: %s", err)
+			}
+			if method == http.MethodPost {
+				u, err := api.createUser(ctx, requester, in)
+				if err != nil {
+					return 0, "", err
+				}
+				return http.StatusCreated, u.Meta.Location, nil
+			}
+			u, err := api.replaceUser(ctx, requester, id, in)
+			if err != nil {
+				return 0, "", err
+			}
+			return http.StatusOK, u.Meta.Location, nil
+		case http.MethodPatch:
+			patch := &PatchRequest{}
+			if err := json.Unmarshal(op.Data, patch); err != nil {
+				return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "invalid patch: %s", err)
+			}
+			u, err := api.patchUser(ctx, requester, id, patch)
+			if err != nil {
+				return 0, "", err
+			}
+			return http.StatusOK, u.Meta.Location, nil
+		case http.MethodDelete:
+			return http.StatusNoContent, "", api.deleteUser(ctx, requester, id)
+		}
+	case "Groups":
+		switch method {
+		case http.MethodPost, http.MethodPut:
+			in := &Group{}
+			if err := json.Unmarshal(op.Data, in); err != nil {
+				return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "invalid group: %s", err)
+			}
+			if method == http.MethodPost {
+				g, err := api.createGroup(ctx, requester, in)
+				if err != nil {
+					return 0, "", err
+				}
+				return http.StatusCreated, g.Meta.Location, nil
+			}
+			g, err := api.replaceGroup(ctx, requester, id, in)
+			if err != nil {
+				return 0, "", err
+			}
+			return http.StatusOK, g.Meta.Location, nil
+		case http.MethodPatch:
+			patch := &PatchRequest{}
+			if err := json.Unmarshal(op.Data, patch); err != nil {
+				return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "invalid patch: %s", err)
+			}
+			g, err := api.patchGroup(ctx, requester, id, patch)
+			if err != nil {
+				return 0, "", err
+			}
+			return http.StatusOK, g.Meta.Location, nil
+		case http.MethodDelete:
+			return http.StatusNoContent, "", api.deleteGroup(ctx, requester, id)
+		}
+	default:
+		return 0, "", newError(http.StatusBadRequest, ErrTypeInvalidPath, "unsupported resource %q", op.Path)
+	}
+
+	return 0, "", newError(http.StatusMethodNotAllowed, "", "unsupported method %q", op.Method)
+}
+
+// resolveBulkIDs replaces the bulkId:<id> references of an operation with the ids of the resources
+// created by the previous operations.
+func resolveBulkIDs(op BulkOperation, createdIDs map[string]string) BulkOperation {
+	if len(createdIDs) == 0 {
+		return op
+	}
+	for bulkID, id := range createdIDs {
+		ref := "bulkId:" + bulkID
+		op.Path = strings.ReplaceAll(op.Path, ref, id)
+		op.Data = json.RawMessage(strings.ReplaceAll(string(op.Data), strconv.Quote(ref), strconv.Quote(id)))
+	}
+	return op
+}
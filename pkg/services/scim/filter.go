@@ -0,0 +1,477 @@
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filterExpr is a parsed SCIM filter (RFC 7644, section 3.4.2.2), evaluated against
+// the JSON representation of a resource.
+type filterExpr interface {
+	matches(resource map[string]any) bool
+}
+
+type logicalExpr struct {
+	op          string
+	left, right filterExpr
+}
+
+func (e *logicalExpr) matches(resource map[string]any) bool {
+	if e.op == "and" {
+		return e.left.matches(resource) && e.right.matches(resource)
+	}
+	return e.left.matches(resource) || e.right.matches(resource)
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (e *notExpr) matches(resource map[string]any) bool {
+	return !e.expr.matches(resource)
+}
+
+type compareExpr struct {
+	path  string
+	op    string
+	value any
+}
+
+func (e *compareExpr) matches(resource map[string]any) bool {
+	values := lookupAttribute(resource, e.path)
+	if e.op == "pr" {
+		for _, v := range values {
+			if !isEmptyValue(v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, v := range values {
+		if compareValues(v, e.op, e.value) {
+			return true
+		}
+	}
+	// ne matches resources where the attribute is absent as well.
+	return e.op == "ne" && len(values) == 0
+}
+
+// valuePathExpr filters the values of a complex multi-valued attribute, e.g. emails[type eq "work"].
+type valuePathExpr struct {
+	path string
+	expr filterExpr
+}
+
+func (e *valuePathExpr) matches(resource map[string]any) bool {
+	for _, v := range lookupAttribute(resource, e.path) {
+		if m, ok := v.(map[string]any); ok && e.expr.matches(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFilter(filter string) (filterExpr, error) {
+	tokens, err := tokenizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected token %q", p.peek().value)
+	}
+	return expr, nil
+}
+
+// attributePath is the target of a PATCH operation, e.g. members[value eq "abc"] or emails[type eq "work"].value.
+type attributePath struct {
+	attr   string
+	filter filterExpr
+	sub    string
+}
+
+func parseAttributePath(path string) (*attributePath, error) {
+	open := strings.IndexByte(path, '[')
+	if open < 0 {
+		return &attributePath{attr: path}, nil
+	}
+
+	end := strings.LastIndexByte(path, ']')
+	if end < open {
+		return nil, fmt.Errorf("unterminated value filter in path %q", path)
+	}
+
+	filter, err := parseFilter(path[open+1 : end])
+	if err != nil {
+		return nil, err
+	}
+
+	result := &attributePath{attr: path[:open], filter: filter}
+	if rest := path[end+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") {
+			return nil, fmt.Errorf("invalid sub-attribute in path %q", path)
+		}
+		result.sub = rest[1:]
+	}
+	return result, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	value string
+}
+
+func tokenizeFilter(filter string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(filter); {
+		switch c := filter[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpenParen, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokenCloseParen, value: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, filterToken{kind: tokenOpenBracket, value: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, filterToken{kind: tokenCloseBracket, value: "]"})
+			i++
+		case c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(filter) && filter[j] != '"'; j++ {
+				if filter[j] == '\\' && j+1 < len(filter) {
+					j++
+				}
+				sb.WriteByte(filter[j])
+			}
+			if j >= len(filter) {
+				return nil, fmt.Errorf("unterminated string in filter")
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, value: sb.String()})
+			i = j + 1
+		default:
+			j := i
+			for j < len(filter) && !strings.ContainsRune(" \t()[]\"", rune(filter[j])) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, value: filter[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.done() {
+		return filterToken{}, fmt.Errorf("unexpected end of filter")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) expect(kind filterTokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return fmt.Errorf("expected %q, got %q", value, t.value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.isKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	}
+
+	if p.peek().kind == tokenOpenParen {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	return p.parseAttribute()
+}
+
+func (p *filterParser) parseAttribute() (filterExpr, error) {
+	attr, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if attr.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute, got %q", attr.value)
+	}
+	path := stripSchemaURN(attr.value)
+
+	if p.peek().kind == tokenOpenBracket {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &valuePathExpr{path: path, expr: expr}, nil
+	}
+
+	opToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	op := strings.ToLower(opToken.value)
+	switch op {
+	case "pr":
+		return &compareExpr{path: path, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", opToken.value)
+	}
+
+	valueToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value, err := parseFilterValue(valueToken)
+	if err != nil {
+		return nil, err
+	}
+	return &compareExpr{path: path, op: op, value: value}, nil
+}
+
+func parseFilterValue(t filterToken) (any, error) {
+	if t.kind == tokenString {
+		return t.value, nil
+	}
+	if t.kind != tokenWord {
+		return nil, fmt.Errorf("expected value, got %q", t.value)
+	}
+
+	switch strings.ToLower(t.value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if f, err := strconv.ParseFloat(t.value, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid value %q", t.value)
+}
+
+// stripSchemaURN removes the schema prefix of fully qualified attributes,
+// e.g. urn:ietf:params:scim:schemas:core:2.0:User:userName.
+func stripSchemaURN(path string) string {
+	if !strings.HasPrefix(strings.ToLower(path), "urn:") {
+		return path
+	}
+	if i := strings.LastIndexByte(path, ':'); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// lookupAttribute returns the values of a dotted, case-insensitive attribute path.
+// Multi-valued attributes are flattened.
+func lookupAttribute(resource map[string]any, path string) []any {
+	current := []any{resource}
+	for _, part := range strings.Split(path, ".") {
+		var nextValues []any
+		for _, c := range current {
+			m, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			for k, v := range m {
+				if !strings.EqualFold(k, part) {
+					continue
+				}
+				if list, ok := v.([]any); ok {
+					nextValues = append(nextValues, list...)
+				} else if v != nil {
+					nextValues = append(nextValues, v)
+				}
+			}
+		}
+		current = nextValues
+	}
+	return current
+}
+
+func isEmptyValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case map[string]any:
+		return len(val) == 0
+	}
+	return false
+}
+
+func compareValues(actual any, op string, expected any) bool {
+	switch exp := expected.(type) {
+	case nil:
+		if op == "eq" {
+			return actual == nil
+		}
+		return op == "ne" && actual != nil
+	case bool:
+		b, ok := actual.(bool)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return b == exp
+		case "ne":
+			return b != exp
+		}
+		return false
+	case float64:
+		f, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return f == exp
+		case "ne":
+			return f != exp
+		case "gt":
+			return f > exp
+		case "ge":
+			return f >= exp
+		case "lt":
+			return f < exp
+		case "le":
+			return f <= exp
+		}
+		return false
+	case string:
+		s, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		// None of the supported attributes is case exact.
+		s, exp = strings.ToLower(s), strings.ToLower(exp)
+		switch op {
+		case "eq":
+			return s == exp
+		case "ne":
+			return s != exp
+		case "co":
+			return strings.Contains(s, exp)
+		case "sw":
+			return strings.HasPrefix(s, exp)
+		case "ew":
+			return strings.HasSuffix(s, exp)
+		case "gt":
+			return s > exp
+		case "ge":
+			return s >= exp
+		case "lt":
+			return s < exp
+		case "le":
+			return s <= exp
+		}
+	}
+	return false
+}
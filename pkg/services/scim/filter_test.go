@@ -0,0 +1,112 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"userName":    "Alice",
+		"displayName": "Alice Doe",
+		"active":      true,
+		"emails": []any{
+			map[string]any{"value": "alice@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "alice@home.example", "type": "home"},
+		},
+		"meta": map[string]any{"resourceType": "User"},
+	}
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: `userName eq "alice"`, matches: true},
+		{filter: `userName eq "bob"`, matches: false},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, matches: true},
+		{filter: `userName ne "bob"`, matches: true},
+		{filter: `displayName co "doe"`, matches: true},
+		{filter: `displayName sw "ali"`, matches: true},
+		{filter: `displayName ew "doe"`, matches: true},
+		{filter: `active eq true`, matches: true},
+		{filter: `active eq false`, matches: false},
+		{filter: `title pr`, matches: false},
+		{filter: `title ne "x"`, matches: true},
+		{filter: `emails pr`, matches: true},
+		{filter: `emails.value eq "alice@home.example"`, matches: true},
+		{filter: `meta.resourceType eq "User"`, matches: true},
+		{filter: `emails[type eq "work" and value co "example.com"]`, matches: true},
+		{filter: `emails[type eq "other"]`, matches: false},
+		{filter: `userName eq "bob" or active eq true`, matches: true},
+		{filter: `userName eq "alice" and active eq false`, matches: false},
+		{filter: `not (userName eq "bob")`, matches: true},
+		{filter: `(userName eq "bob" or userName eq "alice") and displayName pr`, matches: true},
+		{filter: `userName gt "a" and userName lt "b"`, matches: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			expr, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, expr.matches(resource))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName regex "a"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`(userName eq "alice"`,
+		`emails[type eq "work"`,
+		`userName eq "alice" and`,
+		`not userName eq "alice"`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := parseFilter(filter)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseAttributePath(t *testing.T) {
+	path, err := parseAttributePath("displayName")
+	require.NoError(t, err)
+	assert.Equal(t, "displayName", path.attr)
+	assert.Nil(t, path.filter)
+
+	path, err = parseAttributePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, "emails", path.attr)
+	assert.Equal(t, "value", path.sub)
+	assert.True(t, path.filter.matches(map[string]any{"type": "work"}))
+
+	_, err = parseAttributePath(`members[value eq "1"`)
+	require.Error(t, err)
+
+	_, err = parseAttributePath(`members[value eq "1"]value`)
+	require.Error(t, err)
+}
+
+func TestEqualityValue(t *testing.T) {
+	expr, err := parseFilter(`userName eq "alice"`)
+	require.NoError(t, err)
+
+	value, ok := equalityValue(expr, "userName")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", value)
+
+	_, ok = equalityValue(expr, "displayName")
+	assert.False(t, ok)
+
+	expr, err = parseFilter(`userName eq "alice" or userName eq "bob"`)
+	require.NoError(t, err)
+	_, ok = equalityValue(expr, "userName")
+	assert.False(t, ok)
+}
@@ -0,0 +1,441 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+func (api *API) listGroups(ctx context.Context, requester identity.Requester, query *listQuery) (*ListResponse, error) {
+	orgID := requester.GetOrgID()
+	if err := api.requireGroupSync(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	if query.filter == nil && query.count > 0 && (query.startIndex-1)%query.count == 0 {
+		result, err := api.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+			OrgID:        orgID,
+			Page:         (query.startIndex-1)/query.count + 1,
+			Limit:        query.count,
+			SignedInUser: requester,
+		})
+		if err != nil {
+			return nil, errInternal("failed to list groups", err)
+		}
+
+		resources := make([]any, 0, len(result.Teams))
+		for _, t := range result.Teams {
+			g, err := api.toSCIMGroup(ctx, requester, t, query.members)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, g)
+		}
+		return newListResponse(int(result.TotalCount), query.startIndex, resources), nil
+	}
+
+	// The identity providers look up groups by displayName before creating them.
+	name, _ := equalityValue(query.filter, "displayName")
+	var matched []any
+	for page := 1; ; page++ {
+		result, err := api.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+			OrgID:        orgID,
+			Query:        name,
+			Page:         page,
+			Limit:        MaxPageSize,
+			SignedInUser: requester,
+		})
+		if err != nil {
+			return nil, errInternal("failed to list groups", err)
+		}
+
+		for _, t := range result.Teams {
+			// Members are only loaded for the groups matching the filter, unless it filters on them.
+			g, err := api.toSCIMGroup(ctx, requester, t, filterReferences(query.rawFilter, "members"))
+			if err != nil {
+				return nil, err
+			}
+			ok, err := matchesFilter(query.filter, g)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if query.members && g.Members == nil {
+				if g, err = api.toSCIMGroup(ctx, requester, t, true); err != nil {
+					return nil, err
+				}
+			}
+			if !query.members {
+				g.Members = nil
+			}
+			matched = append(matched, g)
+		}
+
+		if len(result.Teams) < MaxPageSize {
+			break
+		}
+	}
+
+	return paginate(matched, query), nil
+}
+
+func (api *API) getGroup(ctx context.Context, requester identity.Requester, id string, members bool) (*Group, error) {
+	if err := api.requireGroupSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	t, err := api.orgTeam(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	return api.toSCIMGroup(ctx, requester, t, members)
+}
+
+func (api *API) createGroup(ctx context.Context, requester identity.Requester, in *Group) (*Group, error) {
+	orgID := requester.GetOrgID()
+	if err := api.requireGroupSync(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := validateGroup(in); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := api.resolveMembers(ctx, orgID, in.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := api.teamService.CreateTeam(ctx, &team.CreateTeamCommand{
+		Name:          in.DisplayName,
+		ExternalUID:   in.ExternalID,
+		IsProvisioned: true,
+		OrgID:         orgID,
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return nil, newError(http.StatusConflict, ErrTypeUniqueness, "group %s already exists", in.DisplayName)
+		}
+		return nil, errInternal("failed to create group", err)
+	}
+
+	if err := api.setMembers(ctx, orgID, created.ID, nil, memberIDs); err != nil {
+		return nil, err
+	}
+
+	t, err := api.orgTeam(ctx, requester, created.UID)
+	if err != nil {
+		return nil, err
+	}
+	return api.toSCIMGroup(ctx, requester, t, true)
+}
+
+func (api *API) replaceGroup(ctx context.Context, requester identity.Requester, id string, in *Group) (*Group, error) {
+	if err := api.requireGroupSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	t, err := api.orgTeam(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := api.toSCIMGroup(ctx, requester, t, true)
+	if err != nil {
+		return nil, err
+	}
+	return api.updateGroup(ctx, requester, t, current, in)
+}
+
+func (api *API) patchGroup(ctx context.Context, requester identity.Requester, id string, patch *PatchRequest) (*Group, error) {
+	if err := api.requireGroupSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	t, err := api.orgTeam(ctx, requester, id)
+	if err != nil {
+		return nil, err
+	}
+	current, err := api.toSCIMGroup(ctx, requester, t, true)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := *current
+	desired.Members = append([]MultiValue(nil), current.Members...)
+	for _, op := range patch.Operations {
+		if err := applyGroupPatch(&desired, op); err != nil {
+			return nil, err
+		}
+	}
+	return api.updateGroup(ctx, requester, t, current, &desired)
+}
+
+func (api *API) deleteGroup(ctx context.Context, requester identity.Requester, id string) error {
+	orgID := requester.GetOrgID()
+	if err := api.requireGroupSync(ctx, orgID); err != nil {
+		return err
+	}
+
+	t, err := api.orgTeam(ctx, requester, id)
+	if err != nil {
+		return err
+	}
+
+	if err := api.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: orgID, ID: t.ID}); err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return errNotFound("Group", id)
+		}
+		return errInternal("failed to delete group", err)
+	}
+	return nil
+}
+
+func (api *API) updateGroup(ctx context.Context, requester identity.Requester, t *team.TeamDTO, current, desired *Group) (*Group, error) {
+	orgID := requester.GetOrgID()
+	if err := validateGroup(desired); err != nil {
+		return nil, err
+	}
+
+	currentIDs, err := api.resolveMembers(ctx, orgID, current.Members)
+	if err != nil {
+		return nil, err
+	}
+	desiredIDs, err := api.resolveMembers(ctx, orgID, desired.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if desired.DisplayName != t.Name || desired.ExternalID != t.ExternalUID {
+		err := api.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{
+			ID:          t.ID,
+			Name:        desired.DisplayName,
+			Email:       t.Email,
+			ExternalUID: desired.ExternalID,
+			OrgID:       orgID,
+		})
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				return nil, newError(http.StatusConflict, ErrTypeUniqueness, "group %s already exists", desired.DisplayName)
+			}
+			return nil, errInternal("failed to update group", err)
+		}
+	}
+
+	if err := api.setMembers(ctx, orgID, t.ID, currentIDs, desiredIDs); err != nil {
+		return nil, err
+	}
+
+	updated, err := api.orgTeam(ctx, requester, t.UID)
+	if err != nil {
+		return nil, err
+	}
+	return api.toSCIMGroup(ctx, requester, updated, true)
+}
+
+// setMembers adds and removes team members to go from the current to the desired members.
+func (api *API) setMembers(ctx context.Context, orgID, teamID int64, current, desired map[int64]bool) error {
+	teamIDString := strconv.FormatInt(teamID, 10)
+	for userID := range desired {
+		if current[userID] {
+			continue
+		}
+		if _, err := api.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, teamIDString, team.PermissionTypeMember.String()); err != nil {
+			return errInternal("failed to add group member", err)
+		}
+	}
+	for userID := range current {
+		if desired[userID] {
+			continue
+		}
+		if _, err := api.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, teamIDString, ""); err != nil {
+			return errInternal("failed to remove group member", err)
+		}
+	}
+	return nil
+}
+
+// resolveMembers returns the ids of the users referenced by the members of a group.
+// Only users of the organization can be members.
+func (api *API) resolveMembers(ctx context.Context, orgID int64, members []MultiValue) (map[int64]bool, error) {
+	ids := make(map[int64]bool, len(members))
+	for _, m := range members {
+		usr, err := api.orgUser(ctx, orgID, m.Value)
+		if err != nil {
+			var scimErr *Error
+			if errors.As(err, &scimErr) && scimErr.status == http.StatusNotFound {
+				return nil, newError(http.StatusBadRequest, ErrTypeInvalidValue, "member %s is not a user of the organization", m.Value)
+			}
+			return nil, err
+		}
+		ids[usr.ID] = true
+	}
+	return ids, nil
+}
+
+func (api *API) orgTeam(ctx context.Context, requester identity.Requester, uid string) (*team.TeamDTO, error) {
+	t, err := api.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: requester.GetOrgID(), UID: uid, SignedInUser: requester})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, errNotFound("Group", uid)
+		}
+		return nil, errInternal("failed to get group", err)
+	}
+	return t, nil
+}
+
+func (api *API) toSCIMGroup(ctx context.Context, requester identity.Requester, t *team.TeamDTO, members bool) (*Group, error) {
+	result := &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          t.UID,
+		ExternalID:  t.ExternalUID,
+		DisplayName: t.Name,
+		Meta:        &Meta{ResourceType: "Group", Location: api.location("Groups", t.UID)},
+	}
+	if !members {
+		return result, nil
+	}
+
+	teamMembers, err := api.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: t.OrgID, TeamID: t.ID, SignedInUser: requester})
+	if err != nil {
+		return nil, errInternal("failed to get group members", err)
+	}
+	result.Members = make([]MultiValue, 0, len(teamMembers))
+	for _, m := range teamMembers {
+		result.Members = append(result.Members, MultiValue{
+			Value:   m.UserUID,
+			Display: m.Login,
+			Ref:     api.location("Users", m.UserUID),
+		})
+	}
+	return result, nil
+}
+
+func validateGroup(in *Group) error {
+	if strings.TrimSpace(in.DisplayName) == "" {
+		return newError(http.StatusBadRequest, ErrTypeInvalidValue, "displayName is required")
+	}
+	return nil
+}
+
+// applyGroupPatch applies a PATCH operation to a group, see RFC 7644, section 3.5.2.
+func applyGroupPatch(g *Group, op PatchOperation) error {
+	operation := strings.ToLower(op.Op)
+	if operation != "add" && operation != "replace" && operation != "remove" {
+		return newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "unsupported patch operation %q", op.Op)
+	}
+
+	if op.Path == "" {
+		if operation == "remove" {
+			return newError(http.StatusBadRequest, ErrTypeNoTarget, "remove operations require a path")
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return newError(http.StatusBadRequest, ErrTypeInvalidValue, "patch value must be an object without path")
+		}
+		for attr, value := range attrs {
+			// The id is sent back by some identity providers along with the attributes to replace.
+			if strings.EqualFold(attr, "id") {
+				continue
+			}
+			if err := applyGroupPatch(g, PatchOperation{Op: op.Op, Path: attr, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parseAttributePath(stripSchemaURN(op.Path))
+	if err != nil {
+		return newError(http.StatusBadRequest, ErrTypeInvalidPath, "invalid path %q: %s", op.Path, err)
+	}
+
+	switch strings.ToLower(path.attr) {
+	case "displayname":
+		if operation == "remove" {
+			return newError(http.StatusBadRequest, ErrTypeMutability, "displayName is required")
+		}
+		return decodePatchValue(op.Value, &g.DisplayName)
+	case "externalid":
+		g.ExternalID = ""
+		if operation == "remove" {
+			return nil
+		}
+		return decodePatchValue(op.Value, &g.ExternalID)
+	case "members":
+		return applyMembersPatch(g, operation, path, op.Value)
+	}
+
+	return newError(http.StatusBadRequest, ErrTypeInvalidPath, "unsupported attribute %q", op.Path)
+}
+
+func applyMembersPatch(g *Group, operation string, path *attributePath, value json.RawMessage) error {
+	var members []MultiValue
+	if len(value) > 0 {
+		if err := decodePatchValue(value, &members); err != nil {
+			var member MultiValue
+			if err := decodePatchValue(value, &member); err != nil {
+				return err
+			}
+			members = []MultiValue{member}
+		}
+	}
+
+	switch operation {
+	case "add":
+		for _, m := range members {
+			if !hasMember(g.Members, m.Value) {
+				g.Members = append(g.Members, MultiValue{Value: m.Value})
+			}
+		}
+	case "replace":
+		g.Members = nil
+		for _, m := range members {
+			if !hasMember(g.Members, m.Value) {
+				g.Members = append(g.Members, MultiValue{Value: m.Value})
+			}
+		}
+	case "remove":
+		remove := func(m MultiValue) bool {
+			// members[value eq "id"] selects the members to remove, a value lists them,
+			// and a bare members path removes all of them.
+			if path.filter != nil {
+				ok, _ := matchesFilter(path.filter, m)
+				return ok
+			}
+			if len(members) > 0 {
+				return hasMember(members, m.Value)
+			}
+			return true
+		}
+		kept := g.Members[:0]
+		for _, m := range g.Members {
+			if !remove(m) {
+				kept = append(kept, m)
+			}
+		}
+		g.Members = kept
+	}
+	return nil
+}
+
+func hasMember(members []MultiValue, id string) bool {
+	for _, m := range members {
+		if m.Value == id {
+			return true
+		}
+	}
+	return false
+}
+
+// filterReferences returns whether a raw filter references the given attribute.
+func filterReferences(rawFilter, attr string) bool {
+	return strings.Contains(strings.ToLower(rawFilter), strings.ToLower(attr))
+}
@@ -0,0 +1,216 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaBulkRequest           = "urn:ietf:params:scim:api:messages:2.0:BulkRequest"
+	SchemaBulkResponse          = "urn:ietf:params:scim:api:messages:2.0:BulkResponse"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// MaxBulkOperations is the number of operations accepted in a single bulk request.
+	MaxBulkOperations = 1000
+	// MaxBulkPayloadSize is the size in bytes of the largest bulk request accepted.
+	MaxBulkPayloadSize = 1 << 20
+	// DefaultPageSize is the number of resources returned by list requests without count.
+	DefaultPageSize = 100
+	// MaxPageSize is the number of resources returned at most by list requests.
+	MaxPageSize = 1000
+)
+
+// SCIM error types, see RFC 7644, section 3.12.
+const (
+	ErrTypeInvalidFilter = "invalidFilter"
+	ErrTypeTooMany       = "tooMany"
+	ErrTypeUniqueness    = "uniqueness"
+	ErrTypeMutability    = "mutability"
+	ErrTypeInvalidSyntax = "invalidSyntax"
+	ErrTypeInvalidPath   = "invalidPath"
+	ErrTypeNoTarget      = "noTarget"
+	ErrTypeInvalidValue  = "invalidValue"
+)
+
+// Error is a SCIM error response. It is returned by the operations of the API so that the
+// handlers and the bulk endpoint can report it as is.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+
+	status int
+	err    error
+}
+
+func (e *Error) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("scim: %s: %s", e.Detail, e.err)
+	}
+	return "scim: " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+func newError(status int, scimType string, format string, args ...any) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+		status:   status,
+	}
+}
+
+func errNotFound(resourceType, id string) *Error {
+	return newError(http.StatusNotFound, "", "%s %s not found", resourceType, id)
+}
+
+func errInternal(detail string, err error) *Error {
+	e := newError(http.StatusInternalServerError, "", "%s", detail)
+	e.err = err
+	return e
+}
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// String returns the full name, as stored in Grafana.
+func (n *Name) String() string {
+	if n == nil {
+		return ""
+	}
+	if n.Formatted != "" {
+		return n.Formatted
+	}
+	if n.GivenName != "" && n.FamilyName != "" {
+		return n.GivenName + " " + n.FamilyName
+	}
+	return n.GivenName + n.FamilyName
+}
+
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is a SCIM user, backed by a Grafana user member of the organization of the caller.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []MultiValue `json:"groups,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// PrimaryEmail returns the primary email of the user, or the first one if none is flagged as primary.
+func (u *User) PrimaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+// FullName returns the name of the user as stored in Grafana.
+func (u *User) FullName() string {
+	if name := u.Name.String(); name != "" {
+		return name
+	}
+	return u.DisplayName
+}
+
+// IsActive returns false only if the identity provider deactivated the user.
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Group is a SCIM group, backed by a Grafana team.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type BulkRequest struct {
+	Schemas      []string        `json:"schemas"`
+	FailOnErrors int             `json:"failOnErrors,omitempty"`
+	Operations   []BulkOperation `json:"Operations"`
+}
+
+type BulkOperation struct {
+	Method  string          `json:"method"`
+	BulkID  string          `json:"bulkId,omitempty"`
+	Version string          `json:"version,omitempty"`
+	Path    string          `json:"path"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type BulkResponse struct {
+	Schemas    []string              `json:"schemas"`
+	Operations []BulkOperationResult `json:"Operations"`
+}
+
+type BulkOperationResult struct {
+	Method   string `json:"method"`
+	BulkID   string `json:"bulkId,omitempty"`
+	Location string `json:"location,omitempty"`
+	Status   string `json:"status"`
+	Response any    `json:"response,omitempty"`
+}
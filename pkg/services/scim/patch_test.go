@@ -0,0 +1,199 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyUserPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       PatchOperation
+		expected func(u *User)
+		errType  string
+	}{
+		{
+			name: "deactivates the user",
+			op:   PatchOperation{Op: "Replace", Path: "active", Value: json.RawMessage(`false`)},
+			expected: func(u *User) {
+				u.Active = boolPtr(false)
+			},
+		},
+		{
+			name: "accepts booleans as strings",
+			op:   PatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"False"`)},
+			expected: func(u *User) {
+				u.Active = boolPtr(false)
+			},
+		},
+		{
+			name: "replaces attributes without path",
+			op:   PatchOperation{Op: "replace", Value: json.RawMessage(`{"userName":"bob","active":false}`)},
+			expected: func(u *User) {
+				u.UserName = "bob"
+				u.Active = boolPtr(false)
+			},
+		},
+		{
+			name: "replaces the primary email through a value filter",
+			op:   PatchOperation{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"bob@example.com"`)},
+			expected: func(u *User) {
+				u.Emails = []MultiValue{{Value: "bob@example.com", Type: "work", Primary: true}}
+			},
+		},
+		{
+			name: "rebuilds the name from its parts",
+			op:   PatchOperation{Op: "replace", Path: "name.familyName", Value: json.RawMessage(`"Smith"`)},
+			expected: func(u *User) {
+				u.Name = &Name{GivenName: "Alice", FamilyName: "Smith"}
+			},
+		},
+		{
+			name: "removes the external id",
+			op:   PatchOperation{Op: "remove", Path: "externalId"},
+			expected: func(u *User) {
+				u.ExternalID = ""
+			},
+		},
+		{
+			name:    "rejects removing the user name",
+			op:      PatchOperation{Op: "remove", Path: "userName"},
+			errType: ErrTypeMutability,
+		},
+		{
+			name:    "rejects unknown operations",
+			op:      PatchOperation{Op: "move", Path: "userName", Value: json.RawMessage(`"bob"`)},
+			errType: ErrTypeInvalidSyntax,
+		},
+		{
+			name:    "rejects invalid values",
+			op:      PatchOperation{Op: "replace", Path: "active", Value: json.RawMessage(`"maybe"`)},
+			errType: ErrTypeInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{
+				UserName:   "alice",
+				ExternalID: "ext-alice",
+				Name:       &Name{Formatted: "Alice Doe", GivenName: "Alice", FamilyName: "Doe"},
+				Emails:     []MultiValue{{Value: "alice@example.com", Primary: true}},
+			}
+			err := applyUserPatch(u, tt.op)
+			if tt.errType != "" {
+				var scimErr *Error
+				require.True(t, errors.As(err, &scimErr))
+				assert.Equal(t, tt.errType, scimErr.ScimType)
+				assert.Equal(t, http.StatusBadRequest, scimErr.status)
+				return
+			}
+			require.NoError(t, err)
+
+			expected := &User{
+				UserName:   "alice",
+				ExternalID: "ext-alice",
+				Name:       &Name{Formatted: "Alice Doe", GivenName: "Alice", FamilyName: "Doe"},
+				Emails:     []MultiValue{{Value: "alice@example.com", Primary: true}},
+			}
+			tt.expected(expected)
+			assert.Equal(t, expected, u)
+		})
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      PatchOperation
+		members []string
+		display string
+		errType string
+	}{
+		{
+			name:    "adds members",
+			op:      PatchOperation{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"c"},{"value":"a"}]`)},
+			members: []string{"a", "b", "c"},
+		},
+		{
+			name:    "removes a member through a value filter",
+			op:      PatchOperation{Op: "remove", Path: `members[value eq "a"]`},
+			members: []string{"b"},
+		},
+		{
+			name:    "removes the listed members",
+			op:      PatchOperation{Op: "remove", Path: "members", Value: json.RawMessage(`[{"value":"b"}]`)},
+			members: []string{"a"},
+		},
+		{
+			name:    "removes all members",
+			op:      PatchOperation{Op: "remove", Path: "members"},
+			members: []string{},
+		},
+		{
+			name:    "replaces members",
+			op:      PatchOperation{Op: "replace", Path: "members", Value: json.RawMessage(`[{"value":"d"}]`)},
+			members: []string{"d"},
+		},
+		{
+			name:    "renames the group without path",
+			op:      PatchOperation{Op: "replace", Value: json.RawMessage(`{"id":"abc","displayName":"Platform"}`)},
+			members: []string{"a", "b"},
+			display: "Platform",
+		},
+		{
+			name:    "rejects removing the display name",
+			op:      PatchOperation{Op: "remove", Path: "displayName"},
+			errType: ErrTypeMutability,
+		},
+		{
+			name:    "rejects unknown attributes",
+			op:      PatchOperation{Op: "replace", Path: "owner", Value: json.RawMessage(`"x"`)},
+			errType: ErrTypeInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Group{DisplayName: "Engineering", Members: []MultiValue{{Value: "a"}, {Value: "b"}}}
+			err := applyGroupPatch(g, tt.op)
+			if tt.errType != "" {
+				var scimErr *Error
+				require.True(t, errors.As(err, &scimErr))
+				assert.Equal(t, tt.errType, scimErr.ScimType)
+				return
+			}
+			require.NoError(t, err)
+
+			members := []string{}
+			for _, m := range g.Members {
+				members = append(members, m.Value)
+			}
+			assert.Equal(t, tt.members, members)
+			if tt.display != "" {
+				assert.Equal(t, tt.display, g.DisplayName)
+			}
+		})
+	}
+}
+
+func TestResolveBulkIDs(t *testing.T) {
+	op := BulkOperation{
+		Method: "PATCH",
+		Path:   "/Groups/bulkId:group",
+		Data:   json.RawMessage(`{"Operations":[{"op":"add","path":"members","value":[{"value":"bulkId:user"}]}]}`),
+	}
+
+	resolved := resolveBulkIDs(op, map[string]string{"group": "g1", "user": "u1"})
+	assert.Equal(t, "/Groups/g1", resolved.Path)
+	assert.JSONEq(t, `{"Operations":[{"op":"add","path":"members","value":[{"value":"u1"}]}]}`, string(resolved.Data))
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
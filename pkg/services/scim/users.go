@@ -0,0 +1,629 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// SCIM users are linked to their SAML identity, the externalId being the external UID
+// checked by the user sync when provisioned users log in.
+const externalIDAuthModule = login.SAMLAuthModule
+
+func (api *API) listUsers(ctx context.Context, requester identity.Requester, query *listQuery) (*ListResponse, error) {
+	orgID := requester.GetOrgID()
+	if err := api.requireUserSync(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	// Unfiltered requests are paginated by the database when the requested page is aligned on the page size.
+	if query.filter == nil && query.count > 0 && (query.startIndex-1)%query.count == 0 {
+		result, err := api.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+			OrgID: orgID,
+			Page:  (query.startIndex-1)/query.count + 1,
+			Limit: query.count,
+			User:  requester,
+		})
+		if err != nil {
+			return nil, errInternal("failed to list users", err)
+		}
+
+		resources := make([]any, 0, len(result.OrgUsers))
+		for _, ou := range result.OrgUsers {
+			u, err := api.toSCIMUser(ctx, orgUserToUser(ou))
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, u)
+		}
+		return newListResponse(int(result.TotalCount), query.startIndex, resources), nil
+	}
+
+	// The identity providers look up users by userName or email before creating them, which narrows down the search.
+	search, _ := equalityValue(query.filter, "userName", "emails.value", "emails")
+	var matched []any
+	for page := 1; ; page++ {
+		result, err := api.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+			OrgID: orgID,
+			Query: search,
+			Page:  page,
+			Limit: MaxPageSize,
+			User:  requester,
+		})
+		if err != nil {
+			return nil, errInternal("failed to list users", err)
+		}
+
+		for _, ou := range result.OrgUsers {
+			u, err := api.toSCIMUser(ctx, orgUserToUser(ou))
+			if err != nil {
+				return nil, err
+			}
+			ok, err := matchesFilter(query.filter, u)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, u)
+			}
+		}
+
+		if len(result.OrgUsers) < MaxPageSize {
+			break
+		}
+	}
+
+	return paginate(matched, query), nil
+}
+
+func (api *API) getUser(ctx context.Context, requester identity.Requester, id string) (*User, error) {
+	if err := api.requireUserSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	usr, err := api.orgUser(ctx, requester.GetOrgID(), id)
+	if err != nil {
+		return nil, err
+	}
+	return api.toSCIMUser(ctx, usr)
+}
+
+func (api *API) createUser(ctx context.Context, requester identity.Requester, in *User) (*User, error) {
+	orgID := requester.GetOrgID()
+	if err := api.requireUserSync(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := validateUser(in); err != nil {
+		return nil, err
+	}
+
+	if err := api.checkUserConflict(ctx, 0, in.UserName, in.PrimaryEmail()); err != nil {
+		return nil, err
+	}
+
+	// Provisioned users are created in the organization of the requester.
+	ctx = identity.WithRequester(ctx, requester)
+	usr, err := api.userService.Create(ctx, &user.CreateUserCommand{
+		Login:         in.UserName,
+		Email:         in.PrimaryEmail(),
+		Name:          in.FullName(),
+		IsDisabled:    !in.IsActive(),
+		EmailVerified: true,
+		IsProvisioned: true,
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUserAlreadyExists) {
+			return nil, newError(http.StatusConflict, ErrTypeUniqueness, "user %s already exists", in.UserName)
+		}
+		return nil, errInternal("failed to create user", err)
+	}
+
+	if err := api.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{
+		LoginOrEmail: usr.Login,
+		Role:         org.RoleType(api.cfg.AutoAssignOrgRole),
+		OrgID:        orgID,
+		UserID:       usr.ID,
+	}); err != nil {
+		return nil, errInternal("failed to add user to organization", err)
+	}
+
+	if err := api.setExternalID(ctx, usr, in.ExternalID); err != nil {
+		return nil, err
+	}
+
+	api.log.FromContext(ctx).Info("User provisioned", "userID", usr.ID, "orgID", orgID)
+	return api.toSCIMUser(ctx, usr)
+}
+
+func (api *API) replaceUser(ctx context.Context, requester identity.Requester, id string, in *User) (*User, error) {
+	if err := api.requireUserSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	usr, err := api.orgUser(ctx, requester.GetOrgID(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.checkUserMutable(ctx, requester, usr); err != nil {
+		return nil, err
+	}
+	return api.updateUser(ctx, usr, in)
+}
+
+func (api *API) patchUser(ctx context.Context, requester identity.Requester, id string, patch *PatchRequest) (*User, error) {
+	if err := api.requireUserSync(ctx, requester.GetOrgID()); err != nil {
+		return nil, err
+	}
+
+	usr, err := api.orgUser(ctx, requester.GetOrgID(), id)
+	if err != nil {
+		return nil, err
+	}
+	if err := api.checkUserMutable(ctx, requester, usr); err != nil {
+		return nil, err
+	}
+	current, err := api.toSCIMUser(ctx, usr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range patch.Operations {
+		if err := applyUserPatch(current, op); err != nil {
+			return nil, err
+		}
+	}
+	return api.updateUser(ctx, usr, current)
+}
+
+func (api *API) deleteUser(ctx context.Context, requester identity.Requester, id string) error {
+	orgID := requester.GetOrgID()
+	if err := api.requireUserSync(ctx, orgID); err != nil {
+		return err
+	}
+
+	usr, err := api.orgUser(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := api.checkUserMutable(ctx, requester, usr); err != nil {
+		return err
+	}
+
+	if err := api.sessionService.RevokeAllUserTokens(ctx, usr.ID); err != nil {
+		return errInternal("failed to revoke user sessions", err)
+	}
+
+	// Users created through SCIM are deleted with their last organization.
+	cmd := &org.RemoveOrgUserCommand{UserID: usr.ID, OrgID: orgID, ShouldDeleteOrphanedUser: true}
+	if err := api.orgService.RemoveOrgUser(ctx, cmd); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return newError(http.StatusConflict, ErrTypeMutability, "cannot remove the last admin of the organization")
+		}
+		return errInternal("failed to remove user", err)
+	}
+
+	api.log.FromContext(ctx).Info("User deprovisioned", "userID", usr.ID, "orgID", orgID, "deleted", cmd.UserWasDeleted)
+	return nil
+}
+
+// checkUserMutable only lets the identity provider change the users it provisioned, otherwise a SCIM token
+// could take over any account of the organization by replacing its login and email. Server admins can only be
+// changed by service accounts allowed to write all the users of the instance.
+func (api *API) checkUserMutable(ctx context.Context, requester identity.Requester, usr *user.User) error {
+	if !usr.IsProvisioned {
+		return newError(http.StatusForbidden, ErrTypeMutability, "user %s was not provisioned through SCIM", usr.UID)
+	}
+
+	// Provisioned users linked to another login method belong to it.
+	authInfo, err := api.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: usr.ID})
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return errInternal("failed to get user auth info", err)
+	}
+	if authInfo != nil && authInfo.AuthModule != externalIDAuthModule {
+		return newError(http.StatusForbidden, ErrTypeMutability, "user %s is managed by %s", usr.UID, authInfo.AuthModule)
+	}
+
+	if usr.IsAdmin {
+		ok, err := api.accessControl.Evaluate(ctx, requester, accesscontrol.EvalPermission(accesscontrol.ActionUsersWrite, accesscontrol.ScopeGlobalUsersAll))
+		if err != nil {
+			return errInternal("failed to evaluate permissions", err)
+		}
+		if !ok {
+			return newError(http.StatusForbidden, ErrTypeMutability, "server admin %s cannot be changed through SCIM", usr.UID)
+		}
+	}
+	return nil
+}
+
+// updateUser replaces the attributes of a user. Users deactivated by the identity provider are disabled
+// and logged out, which is how users who stop being assigned to Grafana lose access.
+func (api *API) updateUser(ctx context.Context, usr *user.User, in *User) (*User, error) {
+	if err := validateUser(in); err != nil {
+		return nil, err
+	}
+	if err := api.checkUserConflict(ctx, usr.ID, in.UserName, in.PrimaryEmail()); err != nil {
+		return nil, err
+	}
+
+	disabled := !in.IsActive()
+	cmd := &user.UpdateUserCommand{
+		UserID:     usr.ID,
+		Login:      in.UserName,
+		Email:      in.PrimaryEmail(),
+		Name:       in.FullName(),
+		IsDisabled: &disabled,
+	}
+	if err := api.userService.Update(ctx, cmd); err != nil {
+		return nil, errInternal("failed to update user", err)
+	}
+
+	if disabled && !usr.IsDisabled {
+		if err := api.sessionService.RevokeAllUserTokens(ctx, usr.ID); err != nil {
+			return nil, errInternal("failed to revoke user sessions", err)
+		}
+		api.log.FromContext(ctx).Info("User deactivated", "userID", usr.ID)
+	}
+
+	if err := api.setExternalID(ctx, usr, in.ExternalID); err != nil {
+		return nil, err
+	}
+
+	updated, err := api.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: usr.ID})
+	if err != nil {
+		return nil, errInternal("failed to get user", err)
+	}
+	return api.toSCIMUser(ctx, updated)
+}
+
+func (api *API) checkUserConflict(ctx context.Context, userID int64, login, email string) error {
+	for _, lookup := range []func() (*user.User, error){
+		func() (*user.User, error) {
+			return api.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+		},
+		func() (*user.User, error) {
+			if email == "" {
+				return nil, user.ErrUserNotFound
+			}
+			return api.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: email})
+		},
+	} {
+		existing, err := lookup()
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				continue
+			}
+			return errInternal("failed to look up user", err)
+		}
+		if existing.ID != userID {
+			return newError(http.StatusConflict, ErrTypeUniqueness, "user %s already exists", login)
+		}
+	}
+	return nil
+}
+
+func (api *API) setExternalID(ctx context.Context, usr *user.User, externalID string) error {
+	if externalID == "" {
+		return nil
+	}
+
+	authInfo, err := api.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: usr.ID, AuthModule: externalIDAuthModule})
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		return errInternal("failed to get user auth info", err)
+	}
+
+	if authInfo == nil {
+		err = api.authInfoService.SetAuthInfo(ctx, &login.SetAuthInfoCommand{
+			AuthModule:  externalIDAuthModule,
+			UserId:      usr.ID,
+			UserUID:     usr.UID,
+			ExternalUID: externalID,
+		})
+	} else if authInfo.ExternalUID != externalID {
+		err = api.authInfoService.UpdateAuthInfo(ctx, &login.UpdateAuthInfoCommand{
+			AuthModule:  externalIDAuthModule,
+			AuthId:      authInfo.AuthId,
+			UserId:      usr.ID,
+			ExternalUID: externalID,
+		})
+	}
+	if err != nil {
+		return errInternal("failed to set user external id", err)
+	}
+	return nil
+}
+
+// orgUser returns the user with the given UID if it is a member of the organization.
+func (api *API) orgUser(ctx context.Context, orgID int64, uid string) (*user.User, error) {
+	usr, err := api.userService.GetByUID(ctx, &user.GetUserByUIDQuery{UID: uid})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, errNotFound("User", uid)
+		}
+		return nil, errInternal("failed to get user", err)
+	}
+	if usr.IsServiceAccount {
+		return nil, errNotFound("User", uid)
+	}
+
+	orgs, err := api.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: usr.ID})
+	if err != nil {
+		return nil, errInternal("failed to get user organizations", err)
+	}
+	if !slices.ContainsFunc(orgs, func(o *org.UserOrgDTO) bool { return o.OrgID == orgID }) {
+		return nil, errNotFound("User", uid)
+	}
+	return usr, nil
+}
+
+func (api *API) toSCIMUser(ctx context.Context, usr *user.User) (*User, error) {
+	active := !usr.IsDisabled
+	created, updated := usr.Created, usr.Updated
+	result := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          usr.UID,
+		UserName:    usr.Login,
+		DisplayName: usr.Name,
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     api.location("Users", usr.UID),
+		},
+	}
+	if usr.Name != "" {
+		result.Name = &Name{Formatted: usr.Name}
+	}
+	if usr.Email != "" {
+		result.Emails = []MultiValue{{Value: usr.Email, Type: "work", Primary: true}}
+	}
+
+	if usr.IsProvisioned {
+		authInfo, err := api.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: usr.ID, AuthModule: externalIDAuthModule})
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			return nil, errInternal("failed to get user auth info", err)
+		}
+		if authInfo != nil {
+			result.ExternalID = authInfo.ExternalUID
+		}
+	}
+
+	return result, nil
+}
+
+func orgUserToUser(ou *org.OrgUserDTO) *user.User {
+	return &user.User{
+		ID:            ou.UserID,
+		UID:           ou.UID,
+		Login:         ou.Login,
+		Email:         ou.Email,
+		Name:          ou.Name,
+		IsDisabled:    ou.IsDisabled,
+		IsProvisioned: ou.IsProvisioned,
+		Created:       ou.Created,
+		Updated:       ou.Updated,
+	}
+}
+
+func validateUser(in *User) error {
+	if strings.TrimSpace(in.UserName) == "" {
+		return newError(http.StatusBadRequest, ErrTypeInvalidValue, "userName is required")
+	}
+	return nil
+}
+
+// applyUserPatch applies a PATCH operation to a user, see RFC 7644, section 3.5.2.
+func applyUserPatch(u *User, op PatchOperation) error {
+	operation := strings.ToLower(op.Op)
+	if operation != "add" && operation != "replace" && operation != "remove" {
+		return newError(http.StatusBadRequest, ErrTypeInvalidSyntax, "unsupported patch operation %q", op.Op)
+	}
+
+	// Without path, the value holds the attributes to set.
+	if op.Path == "" {
+		if operation == "remove" {
+			return newError(http.StatusBadRequest, ErrTypeNoTarget, "remove operations require a path")
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return newError(http.StatusBadRequest, ErrTypeInvalidValue, "patch value must be an object without path")
+		}
+		for attr, value := range attrs {
+			if err := applyUserPatch(u, PatchOperation{Op: op.Op, Path: attr, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parseAttributePath(stripSchemaURN(op.Path))
+	if err != nil {
+		return newError(http.StatusBadRequest, ErrTypeInvalidPath, "invalid path %q: %s", op.Path, err)
+	}
+
+	attr := strings.ToLower(path.attr)
+	if path.sub != "" && path.filter == nil {
+		attr += "." + strings.ToLower(path.sub)
+	}
+
+	switch attr {
+	case "username":
+		if operation == "remove" {
+			return newError(http.StatusBadRequest, ErrTypeMutability, "userName is required")
+		}
+		return decodePatchValue(op.Value, &u.UserName)
+	case "displayname":
+		u.DisplayName = ""
+		if operation == "remove" {
+			return nil
+		}
+		if err := decodePatchValue(op.Value, &u.DisplayName); err != nil {
+			return err
+		}
+		// Grafana only stores one name.
+		u.Name = nil
+		return nil
+	case "externalid":
+		u.ExternalID = ""
+		if operation == "remove" {
+			return nil
+		}
+		return decodePatchValue(op.Value, &u.ExternalID)
+	case "active":
+		if operation == "remove" {
+			u.Active = nil
+			return nil
+		}
+		var active bool
+		if err := decodePatchValue(op.Value, &active); err != nil {
+			return err
+		}
+		u.Active = &active
+		return nil
+	case "name":
+		u.Name = nil
+		if operation == "remove" {
+			return nil
+		}
+		u.Name = &Name{}
+		return decodePatchValue(op.Value, u.Name)
+	case "name.formatted", "name.givenname", "name.familyname":
+		if u.Name == nil {
+			u.Name = &Name{}
+		}
+		target := &u.Name.Formatted
+		if attr != "name.formatted" {
+			// The stored name is only known formatted, so it is rebuilt from the given parts.
+			u.Name.Formatted = ""
+			target = &u.Name.GivenName
+			if attr == "name.familyname" {
+				target = &u.Name.FamilyName
+			}
+		}
+		*target = ""
+		if operation == "remove" {
+			return nil
+		}
+		return decodePatchValue(op.Value, target)
+	case "emails":
+		if operation == "remove" {
+			u.Emails = nil
+			return nil
+		}
+		// emails[type eq "work"].value, as sent by most identity providers, sets the primary email.
+		if path.filter != nil {
+			var email string
+			if err := decodePatchValue(op.Value, &email); err != nil {
+				return err
+			}
+			u.Emails = []MultiValue{{Value: email, Type: "work", Primary: true}}
+			return nil
+		}
+		var emails []MultiValue
+		if err := decodePatchValue(op.Value, &emails); err != nil {
+			return err
+		}
+		u.Emails = emails
+		return nil
+	case "emails.value":
+		if operation == "remove" {
+			u.Emails = nil
+			return nil
+		}
+		var email string
+		if err := decodePatchValue(op.Value, &email); err != nil {
+			return err
+		}
+		u.Emails = []MultiValue{{Value: email, Type: "work", Primary: true}}
+		return nil
+	}
+
+	return newError(http.StatusBadRequest, ErrTypeInvalidPath, "unsupported attribute %q", op.Path)
+}
+
+func decodePatchValue(value json.RawMessage, target any) error {
+	if len(value) == 0 {
+		return newError(http.StatusBadRequest, ErrTypeInvalidValue, "patch value is required")
+	}
+	if err := json.Unmarshal(value, target); err == nil {
+		return nil
+	}
+
+	// Some identity providers send single values wrapped in a list, or booleans as strings.
+	var list []json.RawMessage
+	if err := json.Unmarshal(value, &list); err == nil && len(list) == 1 {
+		if err := json.Unmarshal(list[0], target); err == nil {
+			return nil
+		}
+	}
+	if b, ok := target.(*bool); ok {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil && (strings.EqualFold(s, "true") || strings.EqualFold(s, "false")) {
+			*b = strings.EqualFold(s, "true")
+			return nil
+		}
+	}
+	return newError(http.StatusBadRequest, ErrTypeInvalidValue, "invalid patch value %s", string(value))
+}
+
+// matchesFilter evaluates a filter against the JSON representation of a resource.
+func matchesFilter(filter filterExpr, resource any) (bool, error) {
+	if filter == nil {
+		return true, nil
+	}
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return false, errInternal("failed to evaluate filter", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false, errInternal("failed to evaluate filter", err)
+	}
+	return filter.matches(m), nil
+}
+
+// equalityValue returns the value of filters in the form `<attr> eq "value"` for one of the given attributes.
+func equalityValue(filter filterExpr, attrs ...string) (string, bool) {
+	cmp, ok := filter.(*compareExpr)
+	if !ok || cmp.op != "eq" {
+		return "", false
+	}
+	value, ok := cmp.value.(string)
+	if !ok {
+		return "", false
+	}
+	for _, attr := range attrs {
+		if strings.EqualFold(cmp.path, attr) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func newListResponse(total, startIndex int, resources []any) *ListResponse {
+	if resources == nil {
+		resources = []any{}
+	}
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+func paginate(resources []any, query *listQuery) *ListResponse {
+	start := min(query.startIndex-1, len(resources))
+	end := min(start+query.count, len(resources))
+	return newListResponse(len(resources), query.startIndex, resources[start:end])
+}
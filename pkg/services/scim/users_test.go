@@ -0,0 +1,108 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/scimutil"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestUserMutations(t *testing.T) {
+	requester := &user.SignedInUser{OrgID: 1, UserID: 10, UserUID: "scim-sa", IsServiceAccount: true}
+
+	setup := func(t *testing.T, usr *user.User, authInfo *login.UserAuth, canWriteUsers bool) (*API, *bool) {
+		t.Helper()
+		cfg := setting.NewCfg()
+		cfg.Raw.Section("auth.scim").Key("user_sync_enabled").SetValue("true")
+
+		updated := false
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedUser = usr
+		userService.UpdateFn = func(ctx context.Context, cmd *user.UpdateUserCommand) error {
+			updated = true
+			return nil
+		}
+		authInfoService := &authinfotest.FakeService{ExpectedUserAuth: authInfo}
+		if authInfo == nil {
+			authInfoService.ExpectedError = user.ErrUserNotFound
+		}
+
+		return &API{
+			cfg:             cfg,
+			accessControl:   actest.FakeAccessControl{ExpectedEvaluate: canWriteUsers},
+			userService:     userService,
+			orgService:      &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}}},
+			authInfoService: authInfoService,
+			sessionService:  authtest.NewFakeUserAuthTokenService(),
+			scimUtil:        scimutil.NewSCIMUtil(nil),
+			log:             log.NewNopLogger(),
+		}, &updated
+	}
+
+	replacement := &User{UserName: "attacker", Emails: []MultiValue{{Value: "attacker@example.com", Primary: true}}}
+
+	requireForbidden := func(t *testing.T, err error) {
+		t.Helper()
+		var scimErr *Error
+		require.True(t, errors.As(err, &scimErr), "expected a SCIM error, got %v", err)
+		require.Equal(t, http.StatusForbidden, scimErr.status)
+	}
+
+	t.Run("users not provisioned through SCIM cannot be changed", func(t *testing.T) {
+		api, updated := setup(t, &user.User{ID: 2, UID: "local", Login: "local"}, nil, true)
+
+		_, err := api.replaceUser(context.Background(), requester, "local", replacement)
+		requireForbidden(t, err)
+		_, err = api.patchUser(context.Background(), requester, "local", &PatchRequest{Operations: []PatchOperation{
+			{Op: "replace", Path: "userName", Value: []byte(`"attacker"`)},
+		}})
+		requireForbidden(t, err)
+		requireForbidden(t, api.deleteUser(context.Background(), requester, "local"))
+		require.False(t, *updated)
+	})
+
+	t.Run("provisioned users linked to another login method cannot be changed", func(t *testing.T) {
+		api, updated := setup(t, &user.User{ID: 2, UID: "ldap", Login: "ldap", IsProvisioned: true}, &login.UserAuth{AuthModule: login.LDAPAuthModule}, true)
+
+		_, err := api.replaceUser(context.Background(), requester, "ldap", replacement)
+		requireForbidden(t, err)
+		require.False(t, *updated)
+	})
+
+	t.Run("server admins cannot be changed without writing all users", func(t *testing.T) {
+		admin := &user.User{ID: 2, UID: "admin", Login: "admin", IsProvisioned: true, IsAdmin: true}
+		api, updated := setup(t, admin, nil, false)
+
+		_, err := api.replaceUser(context.Background(), requester, "admin", replacement)
+		requireForbidden(t, err)
+		requireForbidden(t, api.deleteUser(context.Background(), requester, "admin"))
+		require.False(t, *updated)
+
+		api, updated = setup(t, admin, nil, true)
+		_, err = api.replaceUser(context.Background(), requester, "admin", &User{UserName: "admin"})
+		require.NoError(t, err)
+		require.True(t, *updated)
+	})
+
+	t.Run("provisioned users can be changed", func(t *testing.T) {
+		api, updated := setup(t, &user.User{ID: 2, UID: "provisioned", Login: "provisioned", IsProvisioned: true}, &login.UserAuth{AuthModule: login.SAMLAuthModule}, false)
+
+		_, err := api.replaceUser(context.Background(), requester, "provisioned", &User{UserName: "provisioned"})
+		require.NoError(t, err)
+		require.True(t, *updated)
+	})
+}
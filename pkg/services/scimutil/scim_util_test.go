@@ -213,6 +213,75 @@ func TestSCIMUtil_IsUserSyncEnabled(t *testing.T) {
 	}
 }
 
+func TestSCIMUtil_IsGroupSyncEnabled(t *testing.T) {
+	ctx := context.Background()
+	orgID := int64(1)
+
+	tests := []struct {
+		name           string
+		k8sClient      client.K8sHandler
+		staticEnabled  bool
+		expectedResult bool
+		setupMock      func(*MockK8sHandler)
+	}{
+		{
+			name:           "k8s client nil - returns static config",
+			k8sClient:      nil,
+			staticEnabled:  true,
+			expectedResult: true,
+		},
+		{
+			name:          "k8s client error - falls back to static config",
+			k8sClient:     &MockK8sHandler{},
+			staticEnabled: true,
+			setupMock: func(mockHandler *MockK8sHandler) {
+				mockHandler.On("Get", ctx, "default", orgID, metav1.GetOptions{}, mock.Anything).
+					Return(nil, errors.New("k8s error"))
+			},
+			expectedResult: true,
+		},
+		{
+			name:          "dynamic config group sync enabled",
+			k8sClient:     &MockK8sHandler{},
+			staticEnabled: false,
+			setupMock: func(mockHandler *MockK8sHandler) {
+				obj := createMockSCIMConfig(false, true)
+				mockHandler.On("Get", ctx, "default", orgID, metav1.GetOptions{}, mock.Anything).
+					Return(obj, nil)
+			},
+			expectedResult: true,
+		},
+		{
+			name:          "dynamic config group sync disabled",
+			k8sClient:     &MockK8sHandler{},
+			staticEnabled: true,
+			setupMock: func(mockHandler *MockK8sHandler) {
+				obj := createMockSCIMConfig(true, false)
+				mockHandler.On("Get", ctx, "default", orgID, metav1.GetOptions{}, mock.Anything).
+					Return(obj, nil)
+			},
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock(tt.k8sClient.(*MockK8sHandler))
+			}
+
+			util := NewSCIMUtil(tt.k8sClient)
+			result := util.IsGroupSyncEnabled(ctx, orgID, tt.staticEnabled)
+
+			assert.Equal(t, tt.expectedResult, result)
+
+			if tt.k8sClient != nil {
+				tt.k8sClient.(*MockK8sHandler).AssertExpectations(t)
+			}
+		})
+	}
+}
+
 func TestSCIMUtil_AreNonProvisionedUsersRejected(t *testing.T) {
 	ctx := context.Background()
 	orgID := int64(1)
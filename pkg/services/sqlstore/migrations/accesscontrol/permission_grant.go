@@ -0,0 +1,37 @@
+package accesscontrol
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddPermissionGrantMigrations creates the table of the temporary permission grants and requests.
+func AddPermissionGrantMigrations(mg *migrator.Migrator) {
+	grantV1 := migrator.Table{
+		Name: "resource_permission_grant",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "resource", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_id", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "team_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "built_in_role", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"},
+			{Name: "permission", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "previous_permission", Type: migrator.DB_NVarchar, Length: 190, Nullable: false, Default: "''"},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "requested_by", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "decided_by", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "expires_at", Type: migrator.DB_DateTime, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "resource", "resource_id"}},
+			{Cols: []string{"resource", "status", "expires_at"}},
+		},
+	}
+
+	mg.AddMigration("create resource_permission_grant table", migrator.NewAddTableMigration(grantV1))
+	mg.AddMigration("add index resource_permission_grant.org_id_resource_resource_id", migrator.NewAddIndexMigration(grantV1, grantV1.Indices[0]))
+	mg.AddMigration("add index resource_permission_grant.resource_status_expires_at", migrator.NewAddIndexMigration(grantV1, grantV1.Indices[1]))
+}
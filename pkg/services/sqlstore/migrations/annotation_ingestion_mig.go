@@ -0,0 +1,25 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAnnotationIngestionMigrations(mg *Migrator) {
+	annotationIngestionKeyV1 := Table{
+		Name: "annotation_ingestion_key",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "source", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "key_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "annotation_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "source", "key_hash"}, Type: UniqueIndex},
+			{Cols: []string{"annotation_id"}, Type: IndexType},
+		},
+	}
+
+	mg.AddMigration("create annotation_ingestion_key table", NewAddTableMigration(annotationIngestionKeyV1))
+	mg.AddMigration("add unique index annotation_ingestion_key.org_id_source_key_hash", NewAddIndexMigration(annotationIngestionKeyV1, annotationIngestionKeyV1.Indices[0]))
+	mg.AddMigration("add index annotation_ingestion_key.annotation_id", NewAddIndexMigration(annotationIngestionKeyV1, annotationIngestionKeyV1.Indices[1]))
+}
@@ -96,4 +96,46 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add rate_limit column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "rate_limit",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add query_cache_ttl column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "query_cache_ttl",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add signing_key column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "signing_key",
+		Type:     DB_Blob,
+		Nullable: true,
+	}))
+
+	dashboardPublicUsageV1 := Table{
+		Name: "dashboard_public_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "queries", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "errors", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "rate_limited", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "cache_hits", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "day"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public usage table v1", NewAddTableMigration(dashboardPublicUsageV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicUsageV1)
 }
@@ -71,4 +71,43 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add column schedule_id to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "schedule_id", Type: DB_BigInt, Nullable: true,
+	}))
+	mg.AddMigration("Add index for schedule_id in dashboard_snapshot table", NewAddIndexMigration(snapshotV5, &Index{
+		Cols: []string{"schedule_id"},
+	}))
+
+	scheduleV1 := Table{
+		Name: "dashboard_snapshot_schedule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "cron", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "time_to", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "keep_versions", Type: DB_Int, Nullable: false},
+			{Name: "expires", Type: DB_BigInt, Nullable: false},
+			{Name: "archive_formats", Type: DB_Text, Nullable: true},
+			{Name: "paused", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "next_run", Type: DB_DateTime, Nullable: false},
+			{Name: "last_run", Type: DB_DateTime, Nullable: true},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+			{Cols: []string{"next_run"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_snapshot_schedule table", NewAddTableMigration(scheduleV1))
+	addTableIndicesMigrations(mg, "v1", scheduleV1)
 }
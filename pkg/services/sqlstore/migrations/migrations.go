@@ -174,4 +174,10 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddScopedReceiverTestingPermissions(mg)
 
 	ualert.AddAlertRuleFolderFullpath(mg)
+
+	addWebAuthnCredentialMigrations(mg)
+
+	accesscontrol.AddPermissionGrantMigrations(mg)
+
+	addAnnotationIngestionMigrations(mg)
 }
@@ -0,0 +1,34 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addWebAuthnCredentialMigrations(mg *Migrator) {
+	webAuthnCredentialV1 := Table{
+		Name: "user_webauthn_credential",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "credential_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "public_key", Type: DB_Blob, Nullable: false},
+			{Name: "sign_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "aaguid", Type: DB_NVarchar, Length: 36, Nullable: false},
+			{Name: "transports", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "backup_eligible", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "backed_up", Type: DB_Bool, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "last_used", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"user_id", "name"}, Type: UniqueIndex},
+			{Cols: []string{"credential_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_webauthn_credential table", NewAddTableMigration(webAuthnCredentialV1))
+	mg.AddMigration("add unique index user_webauthn_credential.uid", NewAddIndexMigration(webAuthnCredentialV1, webAuthnCredentialV1.Indices[0]))
+	mg.AddMigration("add unique index user_webauthn_credential.user_id_name", NewAddIndexMigration(webAuthnCredentialV1, webAuthnCredentialV1.Indices[1]))
+	mg.AddMigration("add unique index user_webauthn_credential.credential_id", NewAddIndexMigration(webAuthnCredentialV1, webAuthnCredentialV1.Indices[2]))
+}
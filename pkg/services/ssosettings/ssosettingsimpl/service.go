@@ -67,7 +67,8 @@ func ProvideService(cfg *setting.Cfg, sqlStore db.DB, ac ac.AccessControl,
 	providersList = append(providersList, social.LDAPProviderName)
 	configurableProviders[social.LDAPProviderName] = true
 
-	if licensing.FeatureEnabled(social.SAMLProviderName) {
+	// SAML is provided by the licensed client, or by the OSS client when it is enabled or made configurable.
+	if licensing.FeatureEnabled(social.SAMLProviderName) || cfg.SAMLAuthEnabled || configurableProviders[social.SAMLProviderName] {
 		fbStrategies = append(fbStrategies, strategies.NewSAMLStrategy(settingsProvider))
 		providersList = append(providersList, social.SAMLProviderName)
 		configurableProviders[social.SAMLProviderName] = true
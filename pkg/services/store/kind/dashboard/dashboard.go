@@ -543,11 +543,12 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup, jsonPath st
 	}
 
 	targets := newTargetInfo(lookup)
+	var panelDatasource *DataSourceRef
 
 	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
 		if iter.WhatIsNext() == jsoniter.NilValue {
 			if field == "datasource" {
-				targets.addDatasource(iter, jsonPath+".datasource", lc)
+				panelDatasource = targets.addDatasource(iter, jsonPath+".datasource", lc)
 				continue
 			}
 
@@ -609,7 +610,7 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup, jsonPath st
 			}
 
 		case "datasource":
-			targets.addDatasource(iter, jsonPath+".datasource", lc)
+			panelDatasource = targets.addDatasource(iter, jsonPath+".datasource", lc)
 
 		case "targets":
 			if !checkAndSkipUnexpectedElement(iter, jsonPath+".targets", lc, jsoniter.ArrayValue, jsoniter.ObjectValue) {
@@ -674,6 +675,7 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup, jsonPath st
 	}
 
 	panel.Datasource = targets.GetDatasourceInfo()
+	panel.Queries = targets.GetQueries(panelDatasource)
 
 	return panel, true
 }
@@ -774,6 +776,7 @@ func readV2PanelSpec(iter *jsoniter.Iterator, lookup DatasourceLookup, jsonPath
 										panel.Datasource = append(panel.Datasource, DataSourceRef{UID: uid, Type: typ})
 									}
 								}
+								panel.Queries = append(panel.Queries, v2QueryText(m)...)
 							}
 						}
 					}
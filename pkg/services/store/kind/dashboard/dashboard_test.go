@@ -77,6 +77,7 @@ func TestReadDashboard(t *testing.T) {
 		"k8s-wrapper-tags-string",
 		"k8s-wrapper-with-parsing-errors",
 		"v2-elements",
+		"panel-queries",
 	}
 
 	devdash := "../../../../../devenv/dev-dashboards/"
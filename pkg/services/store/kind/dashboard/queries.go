@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	"slices"
+	"strings"
+)
+
+// queryTextFields lists the target properties holding the query text, by datasource type.
+// Types without a query language map to no field, unknown types use defaultQueryTextFields.
+var queryTextFields = map[string][]string{
+	"prometheus":                    {"expr"},
+	"loki":                          {"expr"},
+	"graphite":                      {"target"},
+	"influxdb":                      {"query"},
+	"elasticsearch":                 {"query"},
+	"grafana-opensearch-datasource": {"query"},
+	"tempo":                         {"query"},
+	"mysql":                         {"rawSql"},
+	"postgres":                      {"rawSql"},
+	"grafana-postgresql-datasource": {"rawSql"},
+	"mssql":                         {"rawSql"},
+	"cloudwatch":                    {"expression"},
+	"testdata":                      {},
+	"grafana-testdata-datasource":   {},
+	"datasource":                    {},
+	"grafana":                       {},
+}
+
+var defaultQueryTextFields = []string{"expr", "query", "rawSql", "target", "expression"}
+
+// isQueryTextField returns true for the properties that may hold query text for any datasource type
+func isQueryTextField(name string) bool {
+	return slices.Contains(defaultQueryTextFields, name)
+}
+
+// queryText returns the query text from the candidate properties of a target
+func queryText(dsType string, candidates map[string]string) []string {
+	fields, ok := queryTextFields[dsType]
+	if !ok {
+		fields = defaultQueryTextFields
+	}
+
+	var text []string
+	for _, f := range fields {
+		if v := strings.TrimSpace(candidates[f]); v != "" {
+			text = append(text, v)
+		}
+	}
+	return text
+}
+
+// v2QueryText returns the query text of a v2 PanelQuery, where the datasource type is the query group (or kind)
+func v2QueryText(panelQuery map[string]any) []string {
+	spec, _ := panelQuery["spec"].(map[string]any)
+	query, _ := spec["query"].(map[string]any)
+	if query == nil {
+		return nil
+	}
+
+	dsType, _ := query["group"].(string)
+	if dsType == "" {
+		if kind, _ := query["kind"].(string); kind != "DataQuery" {
+			dsType = kind
+		}
+	}
+
+	querySpec, _ := query["spec"].(map[string]any)
+	candidates := make(map[string]string, len(querySpec))
+	for k, v := range querySpec {
+		if str, ok := v.(string); ok && isQueryTextField(k) {
+			candidates[k] = str
+		}
+	}
+	return queryText(dsType, candidates)
+}
@@ -5,8 +5,15 @@ import (
 )
 
 type targetInfo struct {
-	lookup DatasourceLookup
-	uids   map[string]*DataSourceRef
+	lookup  DatasourceLookup
+	uids    map[string]*DataSourceRef
+	queries []targetQuery
+}
+
+// targetQuery keeps the query text of a target until the panel datasource is known
+type targetQuery struct {
+	datasource *DataSourceRef
+	candidates map[string]string
 }
 
 func newTargetInfo(lookup DatasourceLookup) targetInfo {
@@ -27,9 +34,10 @@ func (s *targetInfo) GetDatasourceInfo() []DataSourceRef {
 }
 
 // the node will either be string (name|uid) OR ref
-func (s *targetInfo) addDatasource(iter *jsoniter.Iterator, jsonPath string, lc map[string]any) {
+// returns the resolved reference, if any
+func (s *targetInfo) addDatasource(iter *jsoniter.Iterator, jsonPath string, lc map[string]any) *DataSourceRef {
 	if !checkAndSkipUnexpectedElement(iter, jsonPath, lc, jsoniter.StringValue, jsoniter.NilValue, jsoniter.ObjectValue) {
-		return
+		return nil
 	}
 
 	switch iter.WhatIsNext() {
@@ -40,26 +48,32 @@ func (s *targetInfo) addDatasource(iter *jsoniter.Iterator, jsonPath string, lc
 		if !isVariableRef(dsRef.UID) && !isSpecialDatasource(dsRef.UID) {
 			ds := s.lookup.ByRef(dsRef)
 			s.addRef(ds)
-		} else {
-			s.addRef(dsRef)
+			return ds
 		}
+		s.addRef(dsRef)
+		return dsRef
 
 	case jsoniter.NilValue:
-		s.addRef(s.lookup.ByRef(nil))
+		ds := s.lookup.ByRef(nil)
+		s.addRef(ds)
 		iter.Skip()
+		return ds
 
 	case jsoniter.ObjectValue:
 		ref := &DataSourceRef{}
 		iter.ReadVal(ref)
 
 		if !isVariableRef(ref.UID) && !isSpecialDatasource(ref.UID) {
-			s.addRef(s.lookup.ByRef(ref))
-		} else {
-			s.addRef(ref)
+			ds := s.lookup.ByRef(ref)
+			s.addRef(ds)
+			return ds
 		}
+		s.addRef(ref)
+		return ref
 
 	default:
 		iter.Skip()
+		return nil
 	}
 }
 
@@ -74,18 +88,52 @@ func (s *targetInfo) addTarget(iter *jsoniter.Iterator, jsonPath string, lc map[
 		return
 	}
 
+	query := targetQuery{}
 	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
 		switch f {
 		case "datasource":
-			s.addDatasource(iter, jsonPath+".datasource", lc)
+			query.datasource = s.addDatasource(iter, jsonPath+".datasource", lc)
 
 		case "refId":
 			iter.Skip()
 
 		default:
+			if isQueryTextField(f) && iter.WhatIsNext() == jsoniter.StringValue {
+				if query.candidates == nil {
+					query.candidates = make(map[string]string)
+				}
+				query.candidates[f] = iter.ReadString()
+				continue
+			}
 			iter.Skip()
 		}
 	}
+
+	if len(query.candidates) > 0 {
+		s.queries = append(s.queries, query)
+	}
+}
+
+// GetQueries returns the query text of the targets, extracted based on the datasource type.
+// Targets without a datasource use the panel one.
+func (s *targetInfo) GetQueries(panelDatasource *DataSourceRef) []string {
+	var text []string
+	for _, q := range s.queries {
+		ds := q.datasource
+		if ds == nil || ds.Type == "" {
+			ds = panelDatasource
+		}
+		if ds == nil {
+			ds = s.lookup.ByRef(nil)
+		}
+
+		dsType := ""
+		if ds != nil {
+			dsType = ds.Type
+		}
+		text = append(text, queryText(dsType, q.candidates)...)
+	}
+	return text
 }
 
 func (s *targetInfo) addPanel(panel PanelSummaryInfo) {
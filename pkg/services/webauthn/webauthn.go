@@ -0,0 +1,98 @@
+package webauthn
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	ErrCredentialNotFound = errutil.NotFound("webauthn.credential-not-found", errutil.WithPublicMessage("Passkey not found"))
+	ErrSessionNotFound    = errutil.BadRequest("webauthn.session-not-found", errutil.WithPublicMessage("The passkey ceremony expired, please try again"))
+	ErrInvalidResponse    = errutil.BadRequest("webauthn.invalid-response", errutil.WithPublicMessage("Invalid passkey response"))
+	ErrVerification       = errutil.Unauthorized("webauthn.verification-failed", errutil.WithPublicMessage("Passkey verification failed"))
+	ErrDuplicateName      = errutil.Conflict("webauthn.duplicate-name", errutil.WithPublicMessage("A passkey with this name already exists"))
+)
+
+type Service interface {
+	// BeginRegistration starts the registration of a new passkey for the user.
+	BeginRegistration(ctx context.Context, usr *user.User) (*RegistrationChallenge, error)
+	// FinishRegistration verifies the attestation returned by the authenticator and stores the new passkey.
+	FinishRegistration(ctx context.Context, usr *user.User, cmd FinishRegistrationCommand) (*Credential, error)
+	// BeginLogin starts a login ceremony. Without a user, any passkey the authenticator can discover is accepted.
+	BeginLogin(ctx context.Context, cmd BeginLoginCommand) (*LoginChallenge, error)
+	// FinishLogin verifies the assertion returned by the authenticator and returns the user it belongs to.
+	FinishLogin(ctx context.Context, cmd FinishLoginCommand) (*LoginResult, error)
+
+	// ListCredentials returns the passkeys registered by the user.
+	ListCredentials(ctx context.Context, userID int64) ([]*Credential, error)
+	// HasCredentials returns true if the user registered at least one passkey.
+	HasCredentials(ctx context.Context, userID int64) (bool, error)
+	// DeleteCredential removes a passkey of the user.
+	DeleteCredential(ctx context.Context, userID int64, uid string) error
+	// DeleteUserCredentials removes all passkeys of the user, allowing them to log in with their password again.
+	DeleteUserCredentials(ctx context.Context, userID int64) error
+}
+
+// Credential is a passkey registered by a user.
+type Credential struct {
+	ID     int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID    string `xorm:"uid" json:"uid"`
+	UserID int64  `xorm:"user_id" json:"userId"`
+	Name   string `xorm:"name" json:"name"`
+	// CredentialID is the base64url encoded identifier chosen by the authenticator
+	CredentialID string `xorm:"credential_id" json:"-"`
+	// PublicKey is the COSE encoded public key of the credential
+	PublicKey      []byte    `xorm:"public_key" json:"-"`
+	SignCount      int64     `xorm:"sign_count" json:"-"`
+	AAGUID         string    `xorm:"aaguid" json:"aaguid"`
+	Transports     string    `xorm:"transports" json:"transports"`
+	BackupEligible bool      `xorm:"backup_eligible" json:"backupEligible"`
+	BackedUp       bool      `xorm:"backed_up" json:"backedUp"`
+	Created        time.Time `xorm:"created" json:"created"`
+	LastUsed       time.Time `xorm:"last_used" json:"lastUsed"`
+}
+
+func (c *Credential) TableName() string {
+	return "user_webauthn_credential"
+}
+
+type BeginLoginCommand struct {
+	// UserID restricts the ceremony to the passkeys of a user whose password was already verified
+	UserID int64
+}
+
+type FinishRegistrationCommand struct {
+	SessionID string `json:"sessionId" binding:"Required"`
+	Name      string `json:"name"`
+	// Response is the credential returned by navigator.credentials.create(), serialized with PublicKeyCredential.toJSON()
+	Response json.RawMessage `json:"credential"`
+}
+
+type FinishLoginCommand struct {
+	SessionID string `json:"sessionId" binding:"Required"`
+	// Response is the credential returned by navigator.credentials.get(), serialized with PublicKeyCredential.toJSON()
+	Response json.RawMessage `json:"credential"`
+}
+
+type LoginResult struct {
+	UserID     int64
+	Credential *Credential
+	// MFA is true when the passkey was used as a second factor after a password login
+	MFA bool
+}
+
+type RegistrationChallenge struct {
+	SessionID string                                      `json:"sessionId"`
+	PublicKey protocol.PublicKeyCredentialCreationOptions `json:"publicKey"`
+}
+
+type LoginChallenge struct {
+	SessionID string                                     `json:"sessionId"`
+	PublicKey protocol.PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
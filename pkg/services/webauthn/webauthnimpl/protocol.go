@@ -0,0 +1,136 @@
+package webauthnimpl
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	gowebauthn "github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxCredentialIDLength is the length of the longest base64url encoded credential id that can be stored.
+const maxCredentialIDLength = 190
+
+// newRelyingParty returns the relying party verifying the ceremonies. The WebAuthn protocol itself
+// (client data, authenticator data, attestation statements and signatures) is handled by go-webauthn.
+func newRelyingParty(cfg setting.AuthWebAuthnSettings) (*gowebauthn.WebAuthn, error) {
+	return gowebauthn.New(&gowebauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		// Registration requests "none" attestation, the trust path of the authenticators is not checked.
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			// Passkeys must be discoverable to be used without typing a username.
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.UserVerificationRequirement(cfg.UserVerification),
+		},
+		Timeouts: gowebauthn.TimeoutsConfig{
+			Login:        gowebauthn.TimeoutConfig{Enforce: true, Timeout: cfg.Timeout, TimeoutUVD: cfg.Timeout},
+			Registration: gowebauthn.TimeoutConfig{Enforce: true, Timeout: cfg.Timeout, TimeoutUVD: cfg.Timeout},
+		},
+	})
+}
+
+// webAuthnUser exposes a user and their passkeys to go-webauthn.
+type webAuthnUser struct {
+	usr   *user.User
+	creds []*webauthn.Credential
+}
+
+var _ gowebauthn.User = (*webAuthnUser)(nil)
+
+// WebAuthnID returns the user handle. It must not contain personal information, the UID is opaque.
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(u.usr.UID)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.usr.Login
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	if u.usr.Name != "" {
+		return u.usr.Name
+	}
+	return u.usr.Login
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []gowebauthn.Credential {
+	result := make([]gowebauthn.Credential, 0, len(u.creds))
+	for _, c := range u.creds {
+		cred, err := toLibraryCredential(c)
+		if err != nil {
+			continue
+		}
+		result = append(result, cred)
+	}
+	return result
+}
+
+// find returns the stored passkey of a credential verified by go-webauthn.
+func (u *webAuthnUser) find(id []byte) *webauthn.Credential {
+	credentialID := base64.RawURLEncoding.EncodeToString(id)
+	for _, c := range u.creds {
+		if c.CredentialID == credentialID {
+			return c
+		}
+	}
+	return nil
+}
+
+func (u *webAuthnUser) descriptors() []protocol.CredentialDescriptor {
+	creds := u.WebAuthnCredentials()
+	result := make([]protocol.CredentialDescriptor, 0, len(creds))
+	for _, c := range creds {
+		result = append(result, c.Descriptor())
+	}
+	return result
+}
+
+func toLibraryCredential(c *webauthn.Credential) (gowebauthn.Credential, error) {
+	id, err := base64.RawURLEncoding.DecodeString(c.CredentialID)
+	if err != nil {
+		return gowebauthn.Credential{}, err
+	}
+
+	var aaguid []byte
+	if parsed, err := uuid.Parse(c.AAGUID); err == nil {
+		aaguid = parsed[:]
+	}
+
+	var transports []protocol.AuthenticatorTransport
+	if c.Transports != "" {
+		for _, t := range strings.Split(c.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+
+	return gowebauthn.Credential{
+		ID:        id,
+		PublicKey: c.PublicKey,
+		Transport: transports,
+		Flags: gowebauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackedUp,
+		},
+		Authenticator: gowebauthn.Authenticator{
+			AAGUID:    aaguid,
+			SignCount: uint32(c.SignCount),
+		},
+	}, nil
+}
+
+func formatAAGUID(aaguid []byte) string {
+	parsed, err := uuid.FromBytes(aaguid)
+	if err != nil {
+		return ""
+	}
+	return parsed.String()
+}
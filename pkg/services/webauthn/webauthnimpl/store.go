@@ -0,0 +1,91 @@
+package webauthnimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+type store interface {
+	Insert(ctx context.Context, cred *webauthn.Credential) error
+	List(ctx context.Context, userID int64) ([]*webauthn.Credential, error)
+	Count(ctx context.Context, userID int64) (int64, error)
+	UpdateUsage(ctx context.Context, cred *webauthn.Credential) error
+	Delete(ctx context.Context, userID int64, uid string) error
+	DeleteByUser(ctx context.Context, userID int64) error
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, cred *webauthn.Credential) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("user_id = ? AND name = ?", cred.UserID, cred.Name).Exist(&webauthn.Credential{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return webauthn.ErrDuplicateName.Errorf("user %d already has a passkey named %s", cred.UserID, cred.Name)
+		}
+
+		if _, err := sess.Insert(cred); err != nil {
+			// A passkey belongs to a single user, credential ids are unique across users
+			if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+				return webauthn.ErrInvalidResponse.Errorf("passkey is already registered: %w", err)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) List(ctx context.Context, userID int64) ([]*webauthn.Credential, error) {
+	creds := make([]*webauthn.Credential, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("created").Find(&creds)
+	})
+	return creds, err
+}
+
+func (s *xormStore) Count(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("user_id = ?", userID).Count(&webauthn.Credential{})
+		return err
+	})
+	return count, err
+}
+
+func (s *xormStore) UpdateUsage(ctx context.Context, cred *webauthn.Credential) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(cred.ID).Cols("sign_count", "backed_up", "last_used").Update(cred)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, userID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_webauthn_credential WHERE user_id = ? AND uid = ?", userID, uid)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return webauthn.ErrCredentialNotFound.Errorf("passkey %s not found", uid)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) DeleteByUser(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_webauthn_credential WHERE user_id = ?", userID)
+		return err
+	})
+}
@@ -0,0 +1,337 @@
+package webauthnimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	gowebauthn "github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	sessionKeyPrefix      = "webauthn-session-%s"
+	defaultCredentialName = "Passkey"
+)
+
+type ceremony string
+
+const (
+	ceremonyRegistration ceremony = "registration"
+	ceremonyLogin        ceremony = "login"
+)
+
+// session is the state of a ceremony, kept server side between its begin and finish steps.
+type session struct {
+	Ceremony ceremony               `json:"ceremony"`
+	UserID   int64                  `json:"userId"`
+	Data     gowebauthn.SessionData `json:"data"`
+}
+
+var _ webauthn.Service = (*Service)(nil)
+
+func ProvideService(cfg *setting.Cfg, db db.DB, cache remotecache.CacheStorage, userService user.Service) *Service {
+	return &Service{
+		cfg:         cfg,
+		store:       &xormStore{db: db},
+		cache:       cache,
+		userService: userService,
+		log:         log.New("webauthn"),
+		now:         time.Now,
+	}
+}
+
+type Service struct {
+	cfg         *setting.Cfg
+	store       store
+	cache       remotecache.CacheStorage
+	userService user.Service
+	log         log.Logger
+	now         func() time.Time
+}
+
+func (s *Service) BeginRegistration(ctx context.Context, usr *user.User) (*webauthn.RegistrationChallenge, error) {
+	rp, err := newRelyingParty(s.cfg.WebAuthn)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := s.store.List(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+	wu := &webAuthnUser{usr: usr, creds: creds}
+
+	creation, data, err := rp.BeginRegistration(wu, gowebauthn.WithExclusions(wu.descriptors()))
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := s.startSession(ctx, session{Ceremony: ceremonyRegistration, UserID: usr.ID, Data: *data})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.RegistrationChallenge{SessionID: sessionID, PublicKey: creation.Response}, nil
+}
+
+func (s *Service) FinishRegistration(ctx context.Context, usr *user.User, cmd webauthn.FinishRegistrationCommand) (*webauthn.Credential, error) {
+	sess, err := s.takeSession(ctx, cmd.SessionID, ceremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	if sess.UserID != usr.ID {
+		return nil, webauthn.ErrSessionNotFound.Errorf("registration session belongs to another user")
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(cmd.Response)
+	if err != nil {
+		return nil, webauthn.ErrInvalidResponse.Errorf("parsing registration response: %w", err)
+	}
+
+	rp, err := newRelyingParty(s.cfg.WebAuthn)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := s.store.List(ctx, usr.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := rp.CreateCredential(&webAuthnUser{usr: usr, creds: creds}, sess.Data, parsed)
+	if err != nil {
+		return nil, webauthn.ErrVerification.Errorf("verifying registration: %w", err)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	if len(credentialID) > maxCredentialIDLength {
+		return nil, webauthn.ErrInvalidResponse.Errorf("credential id is longer than %d characters", maxCredentialIDLength)
+	}
+	for _, c := range creds {
+		if c.CredentialID == credentialID {
+			return nil, webauthn.ErrInvalidResponse.Errorf("passkey is already registered")
+		}
+	}
+
+	name := strings.TrimSpace(cmd.Name)
+	if name == "" {
+		name = fmt.Sprintf("%s %d", defaultCredentialName, len(creds)+1)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	now := s.now()
+	cred := &webauthn.Credential{
+		UID:            util.GenerateShortUID(),
+		UserID:         usr.ID,
+		Name:           name,
+		CredentialID:   credentialID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      int64(credential.Authenticator.SignCount),
+		AAGUID:         formatAAGUID(credential.Authenticator.AAGUID),
+		Transports:     strings.Join(transports, ","),
+		BackupEligible: credential.Flags.BackupEligible,
+		BackedUp:       credential.Flags.BackupState,
+		Created:        now,
+		LastUsed:       now,
+	}
+	if err := s.store.Insert(ctx, cred); err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("Registered passkey", "userID", usr.ID, "uid", cred.UID)
+
+	return cred, nil
+}
+
+func (s *Service) BeginLogin(ctx context.Context, cmd webauthn.BeginLoginCommand) (*webauthn.LoginChallenge, error) {
+	rp, err := newRelyingParty(s.cfg.WebAuthn)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		assertion *protocol.CredentialAssertion
+		data      *gowebauthn.SessionData
+	)
+	if cmd.UserID != 0 {
+		wu, err := s.webAuthnUser(ctx, cmd.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if len(wu.creds) == 0 {
+			return nil, webauthn.ErrCredentialNotFound.Errorf("user %d has no passkey", cmd.UserID)
+		}
+		assertion, data, err = rp.BeginLogin(wu, gowebauthn.WithUserVerification(protocol.UserVerificationRequirement(s.cfg.WebAuthn.UserVerification)))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// A passkey used on its own must verify the user, as it is the only factor.
+		assertion, data, err = rp.BeginDiscoverableLogin(gowebauthn.WithUserVerification(protocol.VerificationRequired))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sessionID, err := s.startSession(ctx, session{Ceremony: ceremonyLogin, UserID: cmd.UserID, Data: *data})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.LoginChallenge{SessionID: sessionID, PublicKey: assertion.Response}, nil
+}
+
+func (s *Service) FinishLogin(ctx context.Context, cmd webauthn.FinishLoginCommand) (*webauthn.LoginResult, error) {
+	sess, err := s.takeSession(ctx, cmd.SessionID, ceremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(cmd.Response)
+	if err != nil {
+		return nil, webauthn.ErrInvalidResponse.Errorf("parsing login response: %w", err)
+	}
+
+	rp, err := newRelyingParty(s.cfg.WebAuthn)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wu         *webAuthnUser
+		credential *gowebauthn.Credential
+	)
+	if sess.UserID != 0 {
+		if wu, err = s.webAuthnUser(ctx, sess.UserID); err != nil {
+			return nil, err
+		}
+		credential, err = rp.ValidateLogin(wu, sess.Data, parsed)
+	} else {
+		// Discoverable login, the authenticator tells which user the passkey belongs to.
+		credential, err = rp.ValidateDiscoverableLogin(func(_, userHandle []byte) (gowebauthn.User, error) {
+			usr, err := s.userService.GetByUID(ctx, &user.GetUserByUIDQuery{UID: string(userHandle)})
+			if err != nil {
+				return nil, err
+			}
+			creds, err := s.store.List(ctx, usr.ID)
+			if err != nil {
+				return nil, err
+			}
+			wu = &webAuthnUser{usr: usr, creds: creds}
+			return wu, nil
+		}, sess.Data, parsed)
+	}
+	if err != nil {
+		return nil, webauthn.ErrVerification.Errorf("verifying login: %w", err)
+	}
+
+	cred := wu.find(credential.ID)
+	if cred == nil {
+		return nil, webauthn.ErrVerification.Errorf("passkey is not registered for user %d", wu.usr.ID)
+	}
+
+	// Authenticators that keep a signature counter must increase it, otherwise the passkey may have been cloned.
+	if credential.Authenticator.CloneWarning {
+		s.log.FromContext(ctx).Warn("Passkey signature counter did not increase, the authenticator may have been cloned", "userID", wu.usr.ID, "uid", cred.UID)
+		return nil, webauthn.ErrVerification.Errorf("signature counter did not increase")
+	}
+
+	cred.SignCount = int64(credential.Authenticator.SignCount)
+	cred.BackedUp = credential.Flags.BackupState
+	cred.LastUsed = s.now()
+	if err := s.store.UpdateUsage(ctx, cred); err != nil {
+		return nil, err
+	}
+
+	return &webauthn.LoginResult{UserID: wu.usr.ID, Credential: cred, MFA: sess.UserID != 0}, nil
+}
+
+func (s *Service) ListCredentials(ctx context.Context, userID int64) ([]*webauthn.Credential, error) {
+	return s.store.List(ctx, userID)
+}
+
+func (s *Service) HasCredentials(ctx context.Context, userID int64) (bool, error) {
+	count, err := s.store.Count(ctx, userID)
+	return count > 0, err
+}
+
+func (s *Service) DeleteCredential(ctx context.Context, userID int64, uid string) error {
+	return s.store.Delete(ctx, userID, uid)
+}
+
+func (s *Service) DeleteUserCredentials(ctx context.Context, userID int64) error {
+	return s.store.DeleteByUser(ctx, userID)
+}
+
+func (s *Service) webAuthnUser(ctx context.Context, userID int64) (*webAuthnUser, error) {
+	usr, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+	creds, err := s.store.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{usr: usr, creds: creds}, nil
+}
+
+func (s *Service) startSession(ctx context.Context, sess session) (string, error) {
+	sessionID, err := util.GetRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := json.Marshal(sess)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.cache.Set(ctx, fmt.Sprintf(sessionKeyPrefix, sessionID), value, s.cfg.WebAuthn.Timeout); err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+// takeSession returns the state of a ceremony and removes it, so that a challenge can only be answered once.
+func (s *Service) takeSession(ctx context.Context, sessionID string, c ceremony) (*session, error) {
+	key := fmt.Sprintf(sessionKeyPrefix, sessionID)
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, webauthn.ErrSessionNotFound.Errorf("session not found")
+		}
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	var sess session
+	if err := json.Unmarshal(value, &sess); err != nil {
+		return nil, err
+	}
+	if sess.Ceremony != c {
+		return nil, webauthn.ErrSessionNotFound.Errorf("expected a %s session, got %s", c, sess.Ceremony)
+	}
+
+	return &sess, nil
+}
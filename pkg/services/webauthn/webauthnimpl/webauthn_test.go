@@ -0,0 +1,423 @@
+package webauthnimpl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	testRPID   = "grafana.example.com"
+	testOrigin = "https://grafana.example.com"
+
+	flagUserPresent            byte = 0x01
+	flagUserVerified           byte = 0x04
+	flagAttestedCredentialData byte = 0x40
+)
+
+func TestService_Registration(t *testing.T) {
+	s, _ := setupTestService(t)
+	usr := &user.User{ID: 1, UID: "user-uid", Login: "admin"}
+	auth := newSoftwareAuthenticator(t)
+
+	challenge, err := s.BeginRegistration(context.Background(), usr)
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, challenge.PublicKey.RelyingParty.ID)
+	assert.Equal(t, protocol.URLEncodedBase64(usr.UID), challenge.PublicKey.User.ID)
+	assert.Empty(t, challenge.PublicKey.CredentialExcludeList)
+
+	cred, err := s.FinishRegistration(context.Background(), usr, webauthn.FinishRegistrationCommand{
+		SessionID: challenge.SessionID,
+		Response:  auth.create(t, challenge.PublicKey.Challenge, testOrigin),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Passkey 1", cred.Name)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(auth.credentialID), cred.CredentialID)
+
+	t.Run("session can only be used once", func(t *testing.T) {
+		_, err := s.FinishRegistration(context.Background(), usr, webauthn.FinishRegistrationCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.create(t, challenge.PublicKey.Challenge, testOrigin),
+		})
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("registered passkeys are excluded", func(t *testing.T) {
+		challenge, err := s.BeginRegistration(context.Background(), usr)
+		require.NoError(t, err)
+		require.Len(t, challenge.PublicKey.CredentialExcludeList, 1)
+		assert.Equal(t, auth.credentialID, []byte(challenge.PublicKey.CredentialExcludeList[0].CredentialID))
+	})
+
+	t.Run("should reject an unknown origin", func(t *testing.T) {
+		challenge, err := s.BeginRegistration(context.Background(), usr)
+		require.NoError(t, err)
+		_, err = s.FinishRegistration(context.Background(), usr, webauthn.FinishRegistrationCommand{
+			SessionID: challenge.SessionID,
+			Response:  newSoftwareAuthenticator(t).create(t, challenge.PublicKey.Challenge, "https://evil.example.com"),
+		})
+		require.ErrorIs(t, err, webauthn.ErrVerification)
+	})
+
+	t.Run("should reject a session of another user", func(t *testing.T) {
+		challenge, err := s.BeginRegistration(context.Background(), usr)
+		require.NoError(t, err)
+		_, err = s.FinishRegistration(context.Background(), &user.User{ID: 2}, webauthn.FinishRegistrationCommand{
+			SessionID: challenge.SessionID,
+			Response:  newSoftwareAuthenticator(t).create(t, challenge.PublicKey.Challenge, testOrigin),
+		})
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+
+	t.Run("should reject a passkey registered by another user", func(t *testing.T) {
+		other := &user.User{ID: 2, UID: "other-uid", Login: "other"}
+		challenge, err := s.BeginRegistration(context.Background(), other)
+		require.NoError(t, err)
+		_, err = s.FinishRegistration(context.Background(), other, webauthn.FinishRegistrationCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.create(t, challenge.PublicKey.Challenge, testOrigin),
+		})
+		require.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+	})
+}
+
+func TestService_Login(t *testing.T) {
+	usr := &user.User{ID: 1, UID: "user-uid", Login: "admin"}
+
+	register := func(t *testing.T, s *Service, auth *softwareAuthenticator) {
+		t.Helper()
+		challenge, err := s.BeginRegistration(context.Background(), usr)
+		require.NoError(t, err)
+		_, err = s.FinishRegistration(context.Background(), usr, webauthn.FinishRegistrationCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.create(t, challenge.PublicKey.Challenge, testOrigin),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("should log in with a discoverable passkey", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		auth := newSoftwareAuthenticator(t)
+		register(t, s, auth)
+
+		challenge, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{})
+		require.NoError(t, err)
+		assert.Empty(t, challenge.PublicKey.AllowedCredentials)
+		assert.Equal(t, protocol.VerificationRequired, challenge.PublicKey.UserVerification)
+
+		result, err := s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.get(t, challenge.PublicKey.Challenge, testOrigin, true),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, usr.ID, result.UserID)
+		assert.False(t, result.MFA)
+	})
+
+	t.Run("should confirm a password login", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		auth := newSoftwareAuthenticator(t)
+		register(t, s, auth)
+
+		challenge, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{UserID: usr.ID})
+		require.NoError(t, err)
+		require.Len(t, challenge.PublicKey.AllowedCredentials, 1)
+
+		result, err := s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.get(t, challenge.PublicKey.Challenge, testOrigin, false),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, usr.ID, result.UserID)
+		assert.True(t, result.MFA)
+	})
+
+	t.Run("should fail to confirm a password login without passkey", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		_, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{UserID: usr.ID})
+		require.ErrorIs(t, err, webauthn.ErrCredentialNotFound)
+	})
+
+	t.Run("should require user verification for passkey logins", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		auth := newSoftwareAuthenticator(t)
+		register(t, s, auth)
+
+		auth.userVerified = false
+		challenge, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{})
+		require.NoError(t, err)
+		_, err = s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.get(t, challenge.PublicKey.Challenge, testOrigin, true),
+		})
+		require.ErrorIs(t, err, webauthn.ErrVerification)
+	})
+
+	t.Run("should reject a signature counter that did not increase", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		auth := newSoftwareAuthenticator(t)
+		register(t, s, auth)
+
+		login := func() error {
+			challenge, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{})
+			require.NoError(t, err)
+			_, err = s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+				SessionID: challenge.SessionID,
+				Response:  auth.get(t, challenge.PublicKey.Challenge, testOrigin, true),
+			})
+			return err
+		}
+
+		require.NoError(t, login())
+		auth.signCount--
+		require.ErrorIs(t, login(), webauthn.ErrVerification)
+	})
+
+	t.Run("should reject a signature of another key", func(t *testing.T) {
+		s, userService := setupTestService(t)
+		userService.ExpectedUser = usr
+		auth := newSoftwareAuthenticator(t)
+		register(t, s, auth)
+
+		other := newSoftwareAuthenticator(t)
+		other.credentialID = auth.credentialID
+		challenge, err := s.BeginLogin(context.Background(), webauthn.BeginLoginCommand{})
+		require.NoError(t, err)
+		_, err = s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+			SessionID: challenge.SessionID,
+			Response:  other.get(t, challenge.PublicKey.Challenge, testOrigin, true),
+		})
+		require.ErrorIs(t, err, webauthn.ErrVerification)
+	})
+
+	t.Run("should not accept a registration session", func(t *testing.T) {
+		s, _ := setupTestService(t)
+		auth := newSoftwareAuthenticator(t)
+		challenge, err := s.BeginRegistration(context.Background(), usr)
+		require.NoError(t, err)
+		_, err = s.FinishLogin(context.Background(), webauthn.FinishLoginCommand{
+			SessionID: challenge.SessionID,
+			Response:  auth.get(t, challenge.PublicKey.Challenge, testOrigin, true),
+		})
+		require.ErrorIs(t, err, webauthn.ErrSessionNotFound)
+	})
+}
+
+func setupTestService(t *testing.T) (*Service, *usertest.FakeUserService) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.WebAuthn = setting.AuthWebAuthnSettings{
+		Enabled:          true,
+		RPID:             testRPID,
+		RPDisplayName:    "Grafana",
+		RPOrigins:        []string{testOrigin},
+		MFAEnabled:       true,
+		UserVerification: "preferred",
+		Timeout:          5 * time.Minute,
+	}
+
+	userService := usertest.NewUserServiceFake()
+	return &Service{
+		cfg:         cfg,
+		store:       newFakeStore(),
+		cache:       remotecache.NewFakeCacheStorage(),
+		userService: userService,
+		log:         log.NewNopLogger(),
+		now:         time.Now,
+	}, userService
+}
+
+// softwareAuthenticator emulates a platform authenticator holding a single ES256 passkey.
+type softwareAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	userVerified bool
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softwareAuthenticator{key: key, credentialID: credentialID, userHandle: []byte("user-uid"), userVerified: true}
+}
+
+// create returns the response of navigator.credentials.create(), serialized with PublicKeyCredential.toJSON().
+func (a *softwareAuthenticator) create(t *testing.T, challenge []byte, origin string) json.RawMessage {
+	t.Helper()
+
+	clientDataJSON := a.clientData(t, "webauthn.create", challenge, origin)
+
+	pub, err := a.key.PublicKey.Bytes()
+	require.NoError(t, err)
+	// COSE EC2 key (kty 2) of the ES256 algorithm (-7) on the P-256 curve (1)
+	coseKey, err := cbor.Marshal(map[int64]any{1: 2, 3: -7, -1: 1, -2: pub[1:33], -3: pub[33:]})
+	require.NoError(t, err)
+
+	attested := make([]byte, 18)
+	binary.BigEndian.PutUint16(attested[16:], uint16(len(a.credentialID)))
+	attested = append(append(attested, a.credentialID...), coseKey...)
+	authData := append(a.authenticatorData(flagAttestedCredentialData), attested...)
+
+	attestationObject, err := cbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	require.NoError(t, err)
+
+	return a.credential(t, map[string]any{
+		"clientDataJSON":    encode(clientDataJSON),
+		"attestationObject": encode(attestationObject),
+		"transports":        []string{"internal"},
+	})
+}
+
+// get returns the response of navigator.credentials.get(), serialized with PublicKeyCredential.toJSON().
+func (a *softwareAuthenticator) get(t *testing.T, challenge []byte, origin string, discoverable bool) json.RawMessage {
+	t.Helper()
+
+	a.signCount++
+	clientDataJSON := a.clientData(t, "webauthn.get", challenge, origin)
+	authData := a.authenticatorData(0)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	res := map[string]any{
+		"clientDataJSON":    encode(clientDataJSON),
+		"authenticatorData": encode(authData),
+		"signature":         encode(sig),
+	}
+	if discoverable {
+		res["userHandle"] = encode(a.userHandle)
+	}
+	return a.credential(t, res)
+}
+
+func (a *softwareAuthenticator) credential(t *testing.T, response map[string]any) json.RawMessage {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":                     encode(a.credentialID),
+		"rawId":                  encode(a.credentialID),
+		"type":                   "public-key",
+		"response":               response,
+		"clientExtensionResults": map[string]any{},
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *softwareAuthenticator) clientData(t *testing.T, ceremonyType string, challenge []byte, origin string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"type": ceremonyType, "challenge": encode(challenge), "origin": origin})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *softwareAuthenticator) authenticatorData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	flags |= flagUserPresent
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+	data := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.signCount)
+	return data
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+type fakeStore struct {
+	creds map[string]*webauthn.Credential
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{creds: map[string]*webauthn.Credential{}}
+}
+
+func (f *fakeStore) Insert(_ context.Context, cred *webauthn.Credential) error {
+	for _, c := range f.creds {
+		if c.UserID == cred.UserID && c.Name == cred.Name {
+			return webauthn.ErrDuplicateName.Errorf("duplicate name")
+		}
+		if c.CredentialID == cred.CredentialID {
+			return webauthn.ErrInvalidResponse.Errorf("duplicate credential id")
+		}
+	}
+	cred.ID = int64(len(f.creds) + 1)
+	f.creds[cred.UID] = cred
+	return nil
+}
+
+func (f *fakeStore) List(_ context.Context, userID int64) ([]*webauthn.Credential, error) {
+	result := make([]*webauthn.Credential, 0)
+	for _, c := range f.creds {
+		if c.UserID == userID {
+			copied := *c
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStore) Count(ctx context.Context, userID int64) (int64, error) {
+	creds, err := f.List(ctx, userID)
+	return int64(len(creds)), err
+}
+
+func (f *fakeStore) UpdateUsage(_ context.Context, cred *webauthn.Credential) error {
+	copied := *cred
+	f.creds[cred.UID] = &copied
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, userID int64, uid string) error {
+	c, ok := f.creds[uid]
+	if !ok || c.UserID != userID {
+		return webauthn.ErrCredentialNotFound.Errorf("not found")
+	}
+	delete(f.creds, uid)
+	return nil
+}
+
+func (f *fakeStore) DeleteByUser(_ context.Context, userID int64) error {
+	for uid, c := range f.creds {
+		if c.UserID == userID {
+			delete(f.creds, uid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package webauthntest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/webauthn"
+)
+
+var _ webauthn.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedRegistrationChallenge *webauthn.RegistrationChallenge
+	ExpectedLoginChallenge        *webauthn.LoginChallenge
+	ExpectedLoginResult           *webauthn.LoginResult
+	ExpectedCredential            *webauthn.Credential
+	ExpectedCredentials           []*webauthn.Credential
+	ExpectedErr                   error
+}
+
+func (f *FakeService) BeginRegistration(ctx context.Context, usr *user.User) (*webauthn.RegistrationChallenge, error) {
+	return f.ExpectedRegistrationChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) FinishRegistration(ctx context.Context, usr *user.User, cmd webauthn.FinishRegistrationCommand) (*webauthn.Credential, error) {
+	return f.ExpectedCredential, f.ExpectedErr
+}
+
+func (f *FakeService) BeginLogin(ctx context.Context, cmd webauthn.BeginLoginCommand) (*webauthn.LoginChallenge, error) {
+	return f.ExpectedLoginChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) FinishLogin(ctx context.Context, cmd webauthn.FinishLoginCommand) (*webauthn.LoginResult, error) {
+	return f.ExpectedLoginResult, f.ExpectedErr
+}
+
+func (f *FakeService) ListCredentials(ctx context.Context, userID int64) ([]*webauthn.Credential, error) {
+	return f.ExpectedCredentials, f.ExpectedErr
+}
+
+func (f *FakeService) HasCredentials(ctx context.Context, userID int64) (bool, error) {
+	return len(f.ExpectedCredentials) > 0, f.ExpectedErr
+}
+
+func (f *FakeService) DeleteCredential(ctx context.Context, userID int64, uid string) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) DeleteUserCredentials(ctx context.Context, userID int64) error {
+	return f.ExpectedErr
+}
@@ -144,6 +144,8 @@ type Cfg struct {
 	ProvisioningAllowImageRendering       bool
 	ProvisioningMinSyncInterval           time.Duration
 	ProvisioningRepositoryTypes           []string
+	ProvisioningBucketAllowedEndpoints    []string
+	ProvisioningBucketAllowedRegions      []string
 	ProvisioningLokiURL                   string
 	ProvisioningLokiUser                  string
 	ProvisioningLokiPassword              string
@@ -312,6 +314,8 @@ type Cfg struct {
 
 	PasswordlessMagicLinkAuth AuthPasswordlessMagicLinkSettings
 
+	WebAuthn AuthWebAuthnSettings
+
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
 	SSOSettingsConfigurableProviders map[string]bool
@@ -364,6 +368,10 @@ type Cfg struct {
 	AlertingAnnotationCleanupSetting   AnnotationCleanupSettings
 	DashboardAnnotationCleanupSettings AnnotationCleanupSettings
 	APIAnnotationCleanupSettings       AnnotationCleanupSettings
+	AnnotationRetentionPolicies        []AnnotationRetentionPolicy
+	AnnotationArchiveBucketURL         string
+	AnnotationArchivePrefix            string
+	AnnotationIngestion                AnnotationIngestionSettings
 	KubernetesAnnotationsAppEnabled    bool
 
 	// GrafanaJavascriptAgent config
@@ -399,6 +407,11 @@ type Cfg struct {
 	// Only used in https://snapshots.raintank.io/
 	SnapshotPublicMode bool
 
+	// Scheduled snapshots
+	SnapshotScheduleInterval         time.Duration
+	SnapshotScheduleArchiveBucketURL string
+	SnapshotScheduleArchivePrefix    string
+
 	ErrTemplateName string
 
 	StackID string
@@ -656,6 +669,12 @@ type Cfg struct {
 	GarbageCollectionBatchSize                 int
 	GarbageCollectionMaxAge                    time.Duration
 	DashboardsGarbageCollectionMaxAge          time.Duration
+	HistoryArchiveEnabled                      bool
+	HistoryArchiveBucketURL                    string
+	HistoryArchivePrefix                       string
+	HistoryArchiveInterval                     time.Duration
+	HistoryArchiveBatchSize                    int
+	HistoryArchiveMaxAge                       time.Duration
 
 	EventRetentionPeriod time.Duration
 	EventPruningInterval time.Duration
@@ -899,9 +918,73 @@ func (cfg *Cfg) readAnnotationSettings() error {
 	cfg.DashboardAnnotationCleanupSettings = newAnnotationCleanupSettings(dashboardAnnotation, "max_age")
 	cfg.APIAnnotationCleanupSettings = newAnnotationCleanupSettings(apiIAnnotation, "max_age")
 
+	if err := cfg.readAnnotationRetentionSettings(); err != nil {
+		return err
+	}
+
+	return cfg.readAnnotationIngestionSettings()
+}
+
+func (cfg *Cfg) readAnnotationRetentionSettings() error {
+	retention := cfg.Raw.Section("annotations.retention")
+	cfg.AnnotationArchiveBucketURL = retention.Key("archive_bucket_url").MustString("")
+	cfg.AnnotationArchivePrefix = strings.Trim(retention.Key("archive_prefix").MustString("annotations"), "/")
+
+	cfg.AnnotationRetentionPolicies = nil
+	for _, section := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), "annotations.retention.")
+		if !ok || name == "" {
+			continue
+		}
+
+		policy := AnnotationRetentionPolicy{
+			Name:    name,
+			Source:  section.Key("source").MustString(""),
+			Tags:    util.SplitString(section.Key("tags").MustString("")),
+			Archive: section.Key("archive").MustBool(false),
+		}
+		if !slices.Contains([]string{"", AnnotationSourceAlert, AnnotationSourceDashboard, AnnotationSourceAPI}, policy.Source) {
+			return fmt.Errorf("[annotations.retention.%s] invalid source %q: must be one of alert, dashboard or api", name, policy.Source)
+		}
+		if policy.Source == AnnotationSourceAlert && cfg.alertAnnotationsInLoki() {
+			// the state history is read from Loki instead of the database, retention cannot delete it there
+			return fmt.Errorf("[annotations.retention.%s] alert annotations are stored in Loki by [unified_alerting.state_history], configure their retention in Loki", name)
+		}
+
+		var err error
+		for key, value := range map[string]*time.Duration{
+			"max_age":          &policy.MaxAge,
+			"compact_after":    &policy.CompactAfter,
+			"compact_interval": &policy.CompactInterval,
+		} {
+			if *value, err = gtime.ParseDuration(section.Key(key).MustString("0")); err != nil {
+				return fmt.Errorf("[annotations.retention.%s] invalid %s: %w", name, key, err)
+			}
+		}
+
+		if policy.MaxAge == 0 && policy.CompactAfter == 0 {
+			return fmt.Errorf("[annotations.retention.%s] either max_age or compact_after must be set", name)
+		}
+		if policy.CompactAfter > 0 && policy.CompactInterval == 0 {
+			policy.CompactInterval = time.Hour
+		}
+		if policy.Archive && cfg.AnnotationArchiveBucketURL == "" {
+			return fmt.Errorf("[annotations.retention.%s] archiving requires [annotations.retention] archive_bucket_url", name)
+		}
+
+		cfg.AnnotationRetentionPolicies = append(cfg.AnnotationRetentionPolicies, policy)
+	}
+
 	return nil
 }
 
+// alertAnnotationsInLoki returns whether the annotations of alert state changes are stored in Loki only,
+// the annotations API then reads them from Loki.
+func (cfg *Cfg) alertAnnotationsInLoki() bool {
+	stateHistory := cfg.UnifiedAlerting.StateHistory
+	return stateHistory.Enabled && strings.EqualFold(strings.TrimSpace(stateHistory.Backend), "loki")
+}
+
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
@@ -916,6 +999,30 @@ type AnnotationCleanupSettings struct {
 	MaxCount int64
 }
 
+const (
+	AnnotationSourceAlert     = "alert"
+	AnnotationSourceDashboard = "dashboard"
+	AnnotationSourceAPI       = "api"
+)
+
+// AnnotationRetentionPolicy configures the retention of the annotations of a source, optionally restricted to
+// the annotations having all the given tags. Policies with tags take precedence over policies without tags and
+// over the cleanup settings of the source.
+type AnnotationRetentionPolicy struct {
+	Name string
+	// Source is one of alert, dashboard or api. An empty source matches the annotations of all sources.
+	Source string
+	Tags   []string
+	// MaxAge is how long the annotations are kept, 0 keeps them forever.
+	MaxAge time.Duration
+	// CompactAfter is the age after which region annotations are compacted into one summary annotation
+	// per panel and CompactInterval, 0 disables compaction.
+	CompactAfter    time.Duration
+	CompactInterval time.Duration
+	// Archive exports the annotations to the archive bucket before they are deleted or compacted.
+	Archive bool
+}
+
 func EnvKey(sectionName string, keyName string) string {
 	sN := strings.ToUpper(strings.ReplaceAll(sectionName, ".", "_"))
 	sN = strings.ReplaceAll(sN, "-", "_")
@@ -1422,6 +1529,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	cfg.readPasswordlessMagicLinkSettings()
+	cfg.readWebAuthnSettings()
 	if err := cfg.readSmtpSettings(); err != nil {
 		return err
 	}
@@ -1990,6 +2098,13 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
 
+	cfg.SnapshotScheduleInterval = snapshots.Key("schedule_check_interval").MustDuration(time.Minute)
+	if cfg.SnapshotScheduleInterval <= 0 {
+		return fmt.Errorf("[snapshots] schedule_check_interval must be positive")
+	}
+	cfg.SnapshotScheduleArchiveBucketURL = valueAsString(snapshots, "schedule_archive_bucket_url", "")
+	cfg.SnapshotScheduleArchivePrefix = valueAsString(snapshots, "schedule_archive_prefix", "snapshots")
+
 	return nil
 }
 
@@ -2237,6 +2352,8 @@ func (cfg *Cfg) readProvisioningSettings(iniFile *ini.File) error {
 	}
 	cfg.ProvisioningAllowImageRendering = iniFile.Section("provisioning").Key("allow_image_rendering").MustBool(true)
 	cfg.ProvisioningMinSyncInterval = iniFile.Section("provisioning").Key("min_sync_interval").MustDuration(10 * time.Second)
+	cfg.ProvisioningBucketAllowedEndpoints = iniFile.Section("provisioning").Key("bucket_allowed_endpoints").Strings("|")
+	cfg.ProvisioningBucketAllowedRegions = iniFile.Section("provisioning").Key("bucket_allowed_regions").Strings("|")
 	cfg.ProvisioningMaxResourcesPerRepository = iniFile.Section("provisioning").Key("max_resources_per_repository").MustInt64(0)
 	cfg.ProvisioningMaxRepositories = iniFile.Section("provisioning").Key("max_repositories").MustInt64(10)
 
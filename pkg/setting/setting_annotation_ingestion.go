@@ -0,0 +1,88 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnnotationIngestionSource configures a webhook receiver creating annotations from the events of an external
+// system. The fields left empty are taken from the preset when one is set.
+type AnnotationIngestionSource struct {
+	// Name identifies the source in the webhook URL /api/annotations/ingest/<name>
+	Name string
+	// Preset is one of github, gitlab, argocd or alertmanager
+	Preset string
+	// OrgID is the organization the annotations are created in
+	OrgID int64
+	// Secret is the key of the HMAC-SHA256 signature of the request body or the expected token
+	Secret string
+	// SignatureType is either "hmac" or "token"
+	SignatureType   string
+	SignatureHeader string
+	// SignaturePrefix is stripped from the header value before it is verified, e.g. "sha256=" or "Bearer "
+	SignaturePrefix string
+	// Items is the dot-separated path of the array of events in the payload, the payload is a single event if empty
+	Items string
+	// The following fields are Go templates rendered with an event
+	Filter       string
+	Text         string
+	Tags         string
+	Time         string
+	TimeEnd      string
+	DedupKey     string
+	DashboardUID string
+	PanelID      string
+}
+
+type AnnotationIngestionSettings struct {
+	// MaxBodySize is the maximum size in bytes of a webhook request body
+	MaxBodySize int64
+	// MaxItems is the maximum number of events in a webhook request
+	MaxItems int
+	Sources  map[string]AnnotationIngestionSource
+}
+
+func (cfg *Cfg) readAnnotationIngestionSettings() error {
+	section := cfg.Raw.Section("annotations.ingestion")
+
+	settings := AnnotationIngestionSettings{
+		MaxBodySize: section.Key("max_body_size").MustInt64(1 << 20),
+		MaxItems:    section.Key("max_items").MustInt(1000),
+		Sources:     make(map[string]AnnotationIngestionSource),
+	}
+
+	for _, raw := range cfg.Raw.Sections() {
+		name, ok := strings.CutPrefix(raw.Name(), "annotations.ingestion.")
+		if !ok || name == "" {
+			continue
+		}
+		// allows the secret to be set with an environment variable
+		s := cfg.SectionWithEnvOverrides(raw.Name())
+
+		source := AnnotationIngestionSource{
+			Name:            name,
+			Preset:          s.Key("preset").MustString(""),
+			OrgID:           s.Key("org_id").MustInt64(1),
+			Secret:          s.Key("secret").MustString(""),
+			SignatureType:   s.Key("signature_type").MustString(""),
+			SignatureHeader: s.Key("signature_header").MustString(""),
+			SignaturePrefix: s.Key("signature_prefix").MustString(""),
+			Items:           s.Key("items").MustString(""),
+			Filter:          s.Key("filter").MustString(""),
+			Text:            s.Key("text").MustString(""),
+			Tags:            s.Key("tags").MustString(""),
+			Time:            s.Key("time").MustString(""),
+			TimeEnd:         s.Key("time_end").MustString(""),
+			DedupKey:        s.Key("dedup_key").MustString(""),
+			DashboardUID:    s.Key("dashboard_uid").MustString(""),
+			PanelID:         s.Key("panel_id").MustString(""),
+		}
+		if source.Secret == "" {
+			return fmt.Errorf("[annotations.ingestion.%s] secret is required", name)
+		}
+		settings.Sources[name] = source
+	}
+
+	cfg.AnnotationIngestion = settings
+	return nil
+}
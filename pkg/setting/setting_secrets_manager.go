@@ -6,8 +6,9 @@ import (
 )
 
 const (
-	ProviderPrefix        = "secrets_manager.encryption."
-	MisconfiguredProvider = "misconfigured"
+	ProviderPrefix                      = "secrets_manager.encryption."
+	NamespaceEncryptionProvidersSection = "secrets_manager.namespace_encryption_providers"
+	MisconfiguredProvider               = "misconfigured"
 )
 
 type SecretsManagerSettings struct {
@@ -24,10 +25,14 @@ type SecretsManagerSettings struct {
 	// If empty, a random key will be generated for each Grafana process at startup.
 	// If running in HA mode (i.e. with Redis cache enabled), this value must be set to the same value for all Grafana processes.
 	DataKeysCacheEncryptionKey string
+	// Max number of encrypted values re-encrypted between two checkpoints of a data key rotation.
+	DataKeyRotationBatchSize int64
 
 	// ConfiguredKMSProviders is a map of KMS providers found in the config file. The keys are in the format of <provider>.<keyName>, and the values are a map of the properties in that section
-	// In OSS, the provider type can only be "secret_key". In Enterprise, it can additionally be one of: "aws_kms", "azure_keyvault", "google_kms", "hashicorp_vault"
+	// In OSS, the provider type can be one of: "secret_key", "pkcs11", "remote_kms". In Enterprise, it can additionally be one of: "aws_kms", "azure_keyvault", "google_kms", "hashicorp_vault"
 	ConfiguredKMSProviders map[string]map[string]string
+	// NamespaceEncryptionProviders maps namespaces to the provider (in the format of <provider>.<keyName>) used to encrypt their new data keys instead of CurrentEncryptionProvider.
+	NamespaceEncryptionProviders map[string]string
 
 	GrpcClientEnable        bool   // Whether to enable the gRPC client. If disabled, it will use the in-process services implementations.
 	GrpcClientLoadBalancing bool   // Whether to enable gRPC client-side load balancing
@@ -91,6 +96,8 @@ func (cfg *Cfg) readSecretsManagerSettings() {
 	// If empty, a random key will be generated at startup for encrypting cached data keys.
 	cfg.SecretsManagement.DataKeysCacheEncryptionKey = secretsMgmt.Key("data_keys_cache_encryption_key").MustString("")
 
+	cfg.SecretsManagement.DataKeyRotationBatchSize = secretsMgmt.Key("data_key_rotation_batch_size").MustInt64(100)
+
 	if cfg.SecretsManagement.DataKeysCacheUseRedis && cfg.SecretsManagement.DataKeysCacheEncryptionKey == "" {
 		cfg.Logger.Error("DataKeysCacheEncryptionKey must be set when using Redis cache for data keys. Falling back to the OSS cache.")
 		cfg.SecretsManagement.DataKeysCacheUseRedis = false
@@ -109,4 +116,7 @@ func (cfg *Cfg) readSecretsManagerSettings() {
 		}
 	}
 	cfg.SecretsManagement.ConfiguredKMSProviders = providers
+
+	// Namespaces are keys of the section, and the values are provider identifiers, e.g. `stacks-123 = pkcs11.hsm`
+	cfg.SecretsManagement.NamespaceEncryptionProviders = cfg.Raw.Section(NamespaceEncryptionProvidersSection).KeysHash()
 }
@@ -236,4 +236,24 @@ encryption_provider = aws_kms
 
 		assert.True(t, cfg.SecretsManagement.RunDataKeyMigration)
 	})
+
+	t.Run("should parse namespace encryption providers", func(t *testing.T) {
+		iniContent := `
+[secrets_manager]
+encryption_provider = secret_key.v1
+
+[secrets_manager.encryption.pkcs11.hsm]
+module_path = /usr/lib/softhsm/libsofthsm2.so
+token_label = grafana
+key_label = kek
+
+[secrets_manager.namespace_encryption_providers]
+stacks-123 = pkcs11.hsm
+`
+		cfg, err := NewCfgFromBytes([]byte(iniContent))
+		require.NoError(t, err)
+
+		assert.Len(t, cfg.SecretsManagement.ConfiguredKMSProviders, 1)
+		assert.Equal(t, map[string]string{"stacks-123": "pkcs11.hsm"}, cfg.SecretsManagement.NamespaceEncryptionProviders)
+	})
 }
@@ -632,6 +632,50 @@ app_mode = production
 domain = test.com
 `
 
+func TestAnnotationRetentionSettings(t *testing.T) {
+	readPolicies := func(t *testing.T, raw string, stateHistory UnifiedAlertingStateHistorySettings) ([]AnnotationRetentionPolicy, error) {
+		t.Helper()
+		f, err := ini.Load([]byte(raw))
+		require.NoError(t, err)
+		cfg := NewCfg()
+		cfg.Raw = f
+		cfg.UnifiedAlerting.StateHistory = stateHistory
+		err = cfg.readAnnotationRetentionSettings()
+		return cfg.AnnotationRetentionPolicies, err
+	}
+
+	const alertPolicy = `
+[annotations.retention.alerts]
+source = alert
+max_age = 30d
+`
+	const allSourcesPolicy = `
+[annotations.retention.all]
+max_age = 1y
+`
+
+	t.Run("alert policies apply to the annotations of the state history in the database", func(t *testing.T) {
+		policies, err := readPolicies(t, alertPolicy, UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "annotations"})
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, 30*24*time.Hour, policies[0].MaxAge)
+	})
+
+	t.Run("alert policies are rejected when the state history is stored in Loki", func(t *testing.T) {
+		_, err := readPolicies(t, alertPolicy, UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "loki"})
+		require.ErrorContains(t, err, "[annotations.retention.alerts] alert annotations are stored in Loki")
+
+		policies, err := readPolicies(t, allSourcesPolicy, UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "loki"})
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+	})
+
+	t.Run("alert policies are accepted when the state history is disabled", func(t *testing.T) {
+		_, err := readPolicies(t, alertPolicy, UnifiedAlertingStateHistorySettings{Enabled: false, Backend: "loki"})
+		require.NoError(t, err)
+	})
+}
+
 func TestNewCfgFromBytes(t *testing.T) {
 	cfg, err := NewCfgFromBytes([]byte(iniString))
 	require.NoError(t, err)
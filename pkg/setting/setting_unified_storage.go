@@ -137,6 +137,14 @@ func (cfg *Cfg) setUnifiedStorageConfig() {
 	cfg.GarbageCollectionMaxAge = section.Key("garbage_collection_max_age").MustDuration(24 * time.Hour)
 	cfg.DashboardsGarbageCollectionMaxAge = section.Key("dashboards_garbage_collection_max_age").MustDuration(365 * 24 * time.Hour)
 
+	// history archive
+	cfg.HistoryArchiveEnabled = section.Key("history_archive_enabled").MustBool(false)
+	cfg.HistoryArchiveBucketURL = section.Key("history_archive_bucket_url").String()
+	cfg.HistoryArchivePrefix = strings.Trim(section.Key("history_archive_prefix").MustString("resource_history"), "/")
+	cfg.HistoryArchiveInterval = section.Key("history_archive_interval").MustDuration(time.Hour)
+	cfg.HistoryArchiveBatchSize = section.Key("history_archive_batch_size").MustInt(1000)
+	cfg.HistoryArchiveMaxAge = section.Key("history_archive_max_age").MustDuration(90 * 24 * time.Hour)
+
 	cfg.EventRetentionPeriod = section.Key("event_retention_period").MustDuration(1 * time.Hour)
 	cfg.EventPruningInterval = section.Key("event_pruning_interval").MustDuration(5 * time.Minute)
 
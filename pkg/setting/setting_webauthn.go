@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuthWebAuthnSettings struct {
+	// Enabled allows users to register passkeys and to log in with them
+	Enabled bool
+	// RPID is the relying party identifier, the effective domain the passkeys are bound to
+	RPID string
+	// RPDisplayName is the relying party name shown by the authenticators
+	RPDisplayName string
+	// RPOrigins are the origins allowed to perform WebAuthn ceremonies
+	RPOrigins []string
+	// MFAEnabled requires users who registered a passkey to confirm password logins with it
+	MFAEnabled bool
+	// UserVerification is the user verification requirement: "required", "preferred" or "discouraged"
+	UserVerification string
+	// Timeout is the time given to the user to complete a ceremony
+	Timeout time.Duration
+}
+
+func (cfg *Cfg) readWebAuthnSettings() {
+	section := cfg.SectionWithEnvOverrides("auth.webauthn")
+
+	settings := AuthWebAuthnSettings{}
+	settings.Enabled = section.Key("enabled").MustBool(false)
+	settings.RPID = section.Key("rp_id").MustString(cfg.Domain)
+	settings.RPDisplayName = section.Key("rp_display_name").MustString("Grafana")
+	settings.RPOrigins = util.SplitString(section.Key("rp_origins").MustString(""))
+	if len(settings.RPOrigins) == 0 {
+		settings.RPOrigins = []string{strings.TrimSuffix(cfg.AppURL, "/")}
+	}
+	settings.MFAEnabled = section.Key("mfa_enabled").MustBool(false)
+	settings.UserVerification = section.Key("user_verification").In("preferred", []string{"required", "preferred", "discouraged"})
+	settings.Timeout = section.Key("timeout").MustDuration(5 * time.Minute)
+
+	cfg.WebAuthn = settings
+}
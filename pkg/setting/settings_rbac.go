@@ -1,6 +1,8 @@
 package setting
 
 import (
+	"time"
+
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -13,6 +15,10 @@ type RBACSettings struct {
 	ResetBasicRoles bool
 	// RBAC single organization. This configuration option is subject to change.
 	SingleOrganization bool
+	// Longest duration of a temporary permission grant
+	PermissionGrantMaxDuration time.Duration
+	// How often expired temporary permission grants are reverted
+	PermissionGrantReaperInterval time.Duration
 	// set of resources that should generate managed permissions when created
 	resourcesWithPermissionsOnCreation map[string]struct{}
 
@@ -28,6 +34,8 @@ func (cfg *Cfg) readRBACSettings() {
 	s.PermissionValidationEnabled = rbac.Key("permission_validation_enabled").MustBool(false)
 	s.ResetBasicRoles = rbac.Key("reset_basic_roles").MustBool(false)
 	s.SingleOrganization = rbac.Key("single_organization").MustBool(false)
+	s.PermissionGrantMaxDuration = rbac.Key("permission_grant_max_duration").MustDuration(24 * time.Hour)
+	s.PermissionGrantReaperInterval = rbac.Key("permission_grant_reaper_interval").MustDuration(time.Minute)
 
 	// List of resources to generate managed permissions for upon resource creation (dashboard, folder, service-account, datasource)
 	resources := util.SplitString(rbac.Key("resources_with_managed_permissions_on_creation").MustString("dashboard, folder, service-account, datasource"))
@@ -0,0 +1,27 @@
+INSERT INTO {{ .Ident "secret_data_key_rotation" }} (
+  {{ .Ident "namespace" }},
+  {{ .Ident "phase" }},
+  {{ .Ident "cursor_name" }},
+  {{ .Ident "cursor_version" }},
+  {{ .Ident "total" }},
+  {{ .Ident "processed" }},
+  {{ .Ident "rotated" }},
+  {{ .Ident "failed" }},
+  {{ .Ident "message" }},
+  {{ .Ident "started" }},
+  {{ .Ident "updated" }},
+  {{ .Ident "finished" }}
+) VALUES (
+  {{ .Arg .Row.Namespace }},
+  {{ .Arg .Row.Phase }},
+  {{ .Arg .Row.CursorName }},
+  {{ .Arg .Row.CursorVersion }},
+  {{ .Arg .Row.Total }},
+  {{ .Arg .Row.Processed }},
+  {{ .Arg .Row.Rotated }},
+  {{ .Arg .Row.Failed }},
+  {{ .Arg .Row.Message }},
+  {{ .Arg .Row.Started }},
+  {{ .Arg .Row.Updated }},
+  {{ .Arg .Row.Finished }}
+);
@@ -0,0 +1,17 @@
+SELECT
+  {{ .Ident "namespace" }},
+  {{ .Ident "phase" }},
+  {{ .Ident "cursor_name" }},
+  {{ .Ident "cursor_version" }},
+  {{ .Ident "total" }},
+  {{ .Ident "processed" }},
+  {{ .Ident "rotated" }},
+  {{ .Ident "failed" }},
+  {{ .Ident "message" }},
+  {{ .Ident "started" }},
+  {{ .Ident "updated" }},
+  {{ .Ident "finished" }}
+FROM
+  {{ .Ident "secret_data_key_rotation" }}
+WHERE {{ .Ident "namespace" }} = {{ .Arg .Namespace }}
+;
@@ -0,0 +1,16 @@
+UPDATE
+  {{ .Ident "secret_data_key_rotation" }}
+SET
+  {{ .Ident "phase" }} = {{ .Arg .Row.Phase }},
+  {{ .Ident "cursor_name" }} = {{ .Arg .Row.CursorName }},
+  {{ .Ident "cursor_version" }} = {{ .Arg .Row.CursorVersion }},
+  {{ .Ident "total" }} = {{ .Arg .Row.Total }},
+  {{ .Ident "processed" }} = {{ .Arg .Row.Processed }},
+  {{ .Ident "rotated" }} = {{ .Arg .Row.Rotated }},
+  {{ .Ident "failed" }} = {{ .Arg .Row.Failed }},
+  {{ .Ident "message" }} = {{ .Arg .Row.Message }},
+  {{ .Ident "started" }} = {{ .Arg .Row.Started }},
+  {{ .Ident "updated" }} = {{ .Arg .Row.Updated }},
+  {{ .Ident "finished" }} = {{ .Arg .Row.Finished }}
+WHERE {{ .Ident "namespace" }} = {{ .Arg .Row.Namespace }}
+;
@@ -0,0 +1,5 @@
+SELECT COUNT(*) AS count
+FROM
+  {{ .Ident "secret_encrypted_value" }}
+WHERE {{ .Ident "namespace" }} = {{ .Arg .Namespace }}
+;
@@ -0,0 +1,20 @@
+SELECT
+  {{ .Ident "namespace" }},
+  {{ .Ident "name" }},
+  {{ .Ident "version" }},
+  {{ .Ident "encrypted_data" }},
+  {{ .Ident "data_key_id" }},
+  {{ .Ident "created" }},
+  {{ .Ident "updated" }}
+FROM
+  {{ .Ident "secret_encrypted_value" }}
+WHERE {{ .Ident "namespace" }} = {{ .Arg .Namespace }} AND
+  (
+    {{ .Ident "name" }} > {{ .Arg .AfterName }} OR
+    ({{ .Ident "name" }} = {{ .Arg .AfterName }} AND {{ .Ident "version" }} > {{ .Arg .AfterVersion }})
+  )
+ORDER BY {{ .Ident "name" }} ASC, {{ .Ident "version" }} ASC
+{{ if (gt .Limit 0) }}
+LIMIT {{ .Arg .Limit }}
+{{ end }}
+;
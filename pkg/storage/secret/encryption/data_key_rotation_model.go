@@ -0,0 +1,59 @@
+package encryption
+
+import (
+	"github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
+	"github.com/grafana/grafana/pkg/storage/secret/migrator"
+)
+
+type DataKeyRotation struct {
+	Namespace     string
+	Phase         string
+	CursorName    string
+	CursorVersion int64
+	Total         int64
+	Processed     int64
+	Rotated       int64
+	Failed        int64
+	Message       string
+	Started       int64
+	Updated       int64
+	Finished      int64
+}
+
+func (*DataKeyRotation) TableName() string {
+	return migrator.TableNameDataKeyRotation
+}
+
+func newDataKeyRotationRow(r *contracts.DataKeyRotation) *DataKeyRotation {
+	return &DataKeyRotation{
+		Namespace:     r.Namespace,
+		Phase:         string(r.Phase),
+		CursorName:    r.CursorName,
+		CursorVersion: r.CursorVersion,
+		Total:         r.Total,
+		Processed:     r.Processed,
+		Rotated:       r.Rotated,
+		Failed:        r.Failed,
+		Message:       r.Message,
+		Started:       r.Started,
+		Updated:       r.Updated,
+		Finished:      r.Finished,
+	}
+}
+
+func (r *DataKeyRotation) toContract() *contracts.DataKeyRotation {
+	return &contracts.DataKeyRotation{
+		Namespace:     r.Namespace,
+		Phase:         contracts.DataKeyRotationPhase(r.Phase),
+		CursorName:    r.CursorName,
+		CursorVersion: r.CursorVersion,
+		Total:         r.Total,
+		Processed:     r.Processed,
+		Rotated:       r.Rotated,
+		Failed:        r.Failed,
+		Message:       r.Message,
+		Started:       r.Started,
+		Updated:       r.Updated,
+		Finished:      r.Finished,
+	}
+}
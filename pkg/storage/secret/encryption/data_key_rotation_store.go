@@ -0,0 +1,140 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/registry/apis/secret/contracts"
+	"github.com/grafana/grafana/pkg/registry/apis/secret/xkube"
+	"github.com/grafana/grafana/pkg/storage/unified/sql"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+func ProvideDataKeyRotationStorage(
+	db contracts.Database,
+	tracer trace.Tracer,
+) (contracts.DataKeyRotationStorage, error) {
+	return &dataKeyRotationStorage{
+		db:      db,
+		dialect: sqltemplate.DialectForDriver(db.DriverName()),
+		tracer:  tracer,
+	}, nil
+}
+
+type dataKeyRotationStorage struct {
+	db      contracts.Database
+	dialect sqltemplate.Dialect
+	tracer  trace.Tracer
+}
+
+func (s *dataKeyRotationStorage) Create(ctx context.Context, rotation *contracts.DataKeyRotation) error {
+	ctx, span := s.tracer.Start(ctx, "DataKeyRotationStorage.Create", trace.WithAttributes(
+		attribute.String("namespace", rotation.Namespace),
+	))
+	defer span.End()
+
+	req := createDataKeyRotation{
+		SQLTemplate: sqltemplate.New(s.dialect),
+		Row:         newDataKeyRotationRow(rotation),
+	}
+
+	query, err := sqltemplate.Execute(sqlDataKeyRotationCreate, req)
+	if err != nil {
+		return fmt.Errorf("execute template %q: %w", sqlDataKeyRotationCreate.Name(), err)
+	}
+
+	res, err := s.db.ExecContext(ctx, query, req.GetArgs()...)
+	if err != nil {
+		if sql.IsRowAlreadyExistsError(err) {
+			return contracts.ErrDataKeyRotationInProgress
+		}
+		return fmt.Errorf("inserting row: %w", err)
+	}
+
+	if rowsAffected, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("getting rows affected: %w", err)
+	} else if rowsAffected != 1 {
+		return fmt.Errorf("expected 1 row affected, got %d", rowsAffected)
+	}
+
+	return nil
+}
+
+func (s *dataKeyRotationStorage) Read(ctx context.Context, namespace xkube.Namespace) (*contracts.DataKeyRotation, error) {
+	ctx, span := s.tracer.Start(ctx, "DataKeyRotationStorage.Read", trace.WithAttributes(
+		attribute.String("namespace", namespace.String()),
+	))
+	defer span.End()
+
+	req := readDataKeyRotation{
+		SQLTemplate: sqltemplate.New(s.dialect),
+		Namespace:   namespace.String(),
+	}
+
+	query, err := sqltemplate.Execute(sqlDataKeyRotationRead, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlDataKeyRotationRead.Name(), err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, req.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("getting row: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		return nil, contracts.ErrDataKeyRotationNotFound
+	}
+
+	var row DataKeyRotation
+	err = rows.Scan(
+		&row.Namespace,
+		&row.Phase,
+		&row.CursorName,
+		&row.CursorVersion,
+		&row.Total,
+		&row.Processed,
+		&row.Rotated,
+		&row.Failed,
+		&row.Message,
+		&row.Started,
+		&row.Updated,
+		&row.Finished,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan data key rotation row: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read rows error: %w", err)
+	}
+
+	return row.toContract(), nil
+}
+
+func (s *dataKeyRotationStorage) Update(ctx context.Context, rotation *contracts.DataKeyRotation) error {
+	ctx, span := s.tracer.Start(ctx, "DataKeyRotationStorage.Update", trace.WithAttributes(
+		attribute.String("namespace", rotation.Namespace),
+		attribute.String("phase", string(rotation.Phase)),
+	))
+	defer span.End()
+
+	req := updateDataKeyRotation{
+		SQLTemplate: sqltemplate.New(s.dialect),
+		Row:         newDataKeyRotationRow(rotation),
+	}
+
+	query, err := sqltemplate.Execute(sqlDataKeyRotationUpdate, req)
+	if err != nil {
+		return fmt.Errorf("execute template %q: %w", sqlDataKeyRotationUpdate.Name(), err)
+	}
+
+	// The number of affected rows is not checked: MySQL reports 0 when a progress update doesn't change any column.
+	if _, err := s.db.ExecContext(ctx, query, req.GetArgs()...); err != nil {
+		return fmt.Errorf("updating row: %w", err)
+	}
+
+	return nil
+}
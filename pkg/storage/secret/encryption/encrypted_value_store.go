@@ -218,6 +218,106 @@ func (s *encryptedValStorage) Delete(ctx context.Context, namespace xkube.Namesp
 	return nil
 }
 
+func (s *encryptedValStorage) List(ctx context.Context, namespace xkube.Namespace, opts contracts.NamespacedListOpts) ([]*contracts.EncryptedValue, error) {
+	ctx, span := s.tracer.Start(ctx, "EncryptedValueStorage.List", trace.WithAttributes(
+		attribute.String("namespace", namespace.String()),
+		attribute.Int64("limit", opts.Limit),
+		attribute.String("afterName", opts.AfterName),
+		attribute.Int64("afterVersion", opts.AfterVersion),
+	))
+	defer span.End()
+
+	req := listEncryptedValues{
+		SQLTemplate:  sqltemplate.New(s.dialect),
+		Namespace:    namespace.String(),
+		AfterName:    opts.AfterName,
+		AfterVersion: opts.AfterVersion,
+		Limit:        opts.Limit,
+	}
+
+	query, err := sqltemplate.Execute(sqlEncryptedValueList, req)
+	if err != nil {
+		return nil, fmt.Errorf("execute template %q: %w", sqlEncryptedValueList.Name(), err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, req.GetArgs()...)
+	if err != nil {
+		return nil, fmt.Errorf("listing encrypted values %q: %w", sqlEncryptedValueList.Name(), err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	encryptedValues := make([]*contracts.EncryptedValue, 0)
+	for rows.Next() {
+		var row EncryptedValue
+		err = rows.Scan(
+			&row.Namespace,
+			&row.Name,
+			&row.Version,
+			&row.EncryptedData,
+			&row.DataKeyID,
+			&row.Created,
+			&row.Updated,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error reading encrypted value row: %w", err)
+		}
+
+		encryptedValues = append(encryptedValues, &contracts.EncryptedValue{
+			Namespace: row.Namespace,
+			Name:      row.Name,
+			Version:   row.Version,
+			EncryptedPayload: contracts.EncryptedPayload{
+				DataKeyID:     row.DataKeyID,
+				EncryptedData: row.EncryptedData,
+			},
+			Created: row.Created,
+			Updated: row.Updated,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read rows error: %w", err)
+	}
+
+	return encryptedValues, nil
+}
+
+func (s *encryptedValStorage) Count(ctx context.Context, namespace xkube.Namespace) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "EncryptedValueStorage.Count", trace.WithAttributes(
+		attribute.String("namespace", namespace.String()),
+	))
+	defer span.End()
+
+	req := countEncryptedValues{
+		SQLTemplate: sqltemplate.New(s.dialect),
+		Namespace:   namespace.String(),
+	}
+
+	query, err := sqltemplate.Execute(sqlEncryptedValueCount, req)
+	if err != nil {
+		return 0, fmt.Errorf("execute template %q: %w", sqlEncryptedValueCount.Name(), err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, req.GetArgs()...)
+	if err != nil {
+		return 0, fmt.Errorf("getting row: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		return 0, fmt.Errorf("no rows returned when counting encrypted values")
+	}
+
+	var count int64
+	if err := rows.Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to scan encrypted value count: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("read rows error: %w", err)
+	}
+
+	return count, nil
+}
+
 type globalEncryptedValStorage struct {
 	db      contracts.Database
 	dialect sqltemplate.Dialect
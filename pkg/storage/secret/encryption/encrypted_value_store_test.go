@@ -241,6 +241,48 @@ func TestEncryptedValueStoreImpl(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(0), count)
 	})
+
+	t.Run("listing and counting the encrypted values of a namespace", func(t *testing.T) {
+		t.Parallel()
+
+		sut := testutils.Setup(t)
+		for _, ev := range []struct {
+			namespace string
+			name      string
+			version   int64
+		}{
+			{"test-namespace-a", "b", 1},
+			{"test-namespace-a", "a", 2},
+			{"test-namespace-a", "a", 1},
+			{"test-namespace-b", "a", 1},
+		} {
+			_, err := sut.EncryptedValueStorage.Create(t.Context(), xkube.Namespace(ev.namespace), ev.name, ev.version, contracts.EncryptedPayload{
+				DataKeyID:     "test-data-key-id",
+				EncryptedData: []byte("test-data"),
+			})
+			require.NoError(t, err)
+		}
+
+		count, err := sut.EncryptedValueStorage.Count(t.Context(), "test-namespace-a")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), count)
+
+		// Values are ordered by name and version
+		page, err := sut.EncryptedValueStorage.List(t.Context(), "test-namespace-a", contracts.NamespacedListOpts{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		require.Equal(t, "a", page[0].Name)
+		require.Equal(t, int64(1), page[0].Version)
+		require.Equal(t, "a", page[1].Name)
+		require.Equal(t, int64(2), page[1].Version)
+
+		// The next page starts after the last value of the previous one
+		page, err = sut.EncryptedValueStorage.List(t.Context(), "test-namespace-a", contracts.NamespacedListOpts{Limit: 2, AfterName: "a", AfterVersion: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		require.Equal(t, "b", page[0].Name)
+		require.Equal(t, "test-namespace-a", page[0].Namespace)
+	})
 }
 
 func TestEncryptedValueMigration(t *testing.T) {
@@ -23,6 +23,8 @@ var (
 	sqlEncryptedValueDelete   = mustTemplate("encrypted_value_delete.sql")
 	sqlEncryptedValueListAll  = mustTemplate("encrypted_value_list_all.sql")
 	sqlEncryptedValueCountAll = mustTemplate("encrypted_value_count_all.sql")
+	sqlEncryptedValueList     = mustTemplate("encrypted_value_list.sql")
+	sqlEncryptedValueCount    = mustTemplate("encrypted_value_count.sql")
 
 	sqlDataKeyCreate      = mustTemplate("data_key_create.sql")
 	sqlDataKeyRead        = mustTemplate("data_key_read.sql")
@@ -31,6 +33,10 @@ var (
 	sqlDataKeyDisable     = mustTemplate("data_key_disable.sql")
 	sqlDataKeyDelete      = mustTemplate("data_key_delete.sql")
 	sqlDataKeyDisableAll  = mustTemplate("data_key_disable_all.sql")
+
+	sqlDataKeyRotationCreate = mustTemplate("data_key_rotation_create.sql")
+	sqlDataKeyRotationRead   = mustTemplate("data_key_rotation_read.sql")
+	sqlDataKeyRotationUpdate = mustTemplate("data_key_rotation_update.sql")
 )
 
 // TODO: Move this to a common place so that all stores can use
@@ -114,6 +120,23 @@ type countAllEncryptedValues struct {
 
 func (r countAllEncryptedValues) Validate() error { return nil }
 
+type listEncryptedValues struct {
+	sqltemplate.SQLTemplate
+	Namespace    string
+	AfterName    string
+	AfterVersion int64
+	Limit        int64
+}
+
+func (r listEncryptedValues) Validate() error { return nil }
+
+type countEncryptedValues struct {
+	sqltemplate.SQLTemplate
+	Namespace string
+}
+
+func (r countEncryptedValues) Validate() error { return nil }
+
 /*************************************/
 /**-- Data Key Queries --**/
 /*************************************/
@@ -169,3 +192,27 @@ type disableAllDataKeys struct {
 }
 
 func (r disableAllDataKeys) Validate() error { return nil }
+
+/*************************************/
+/**-- Data Key Rotation Queries --**/
+/*************************************/
+type createDataKeyRotation struct {
+	sqltemplate.SQLTemplate
+	Row *DataKeyRotation
+}
+
+func (r createDataKeyRotation) Validate() error { return nil }
+
+type readDataKeyRotation struct {
+	sqltemplate.SQLTemplate
+	Namespace string
+}
+
+func (r readDataKeyRotation) Validate() error { return nil }
+
+type updateDataKeyRotation struct {
+	sqltemplate.SQLTemplate
+	Row *DataKeyRotation
+}
+
+func (r updateDataKeyRotation) Validate() error { return nil }
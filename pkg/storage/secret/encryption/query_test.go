@@ -124,6 +124,35 @@ func TestEncryptedValueQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlEncryptedValueList: {
+				{
+					Name: "list_first_page",
+					Data: &listEncryptedValues{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Namespace:   "ns",
+						Limit:       10,
+					},
+				},
+				{
+					Name: "list_after_cursor",
+					Data: &listEncryptedValues{
+						SQLTemplate:  mocks.NewTestingSQLTemplate(),
+						Namespace:    "ns",
+						AfterName:    "n1",
+						AfterVersion: 2,
+						Limit:        10,
+					},
+				},
+			},
+			sqlEncryptedValueCount: {
+				{
+					Name: "count",
+					Data: &countEncryptedValues{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Namespace:   "ns",
+					},
+				},
+			},
 		},
 	})
 }
@@ -231,3 +260,53 @@ func TestDataKeyQueries(t *testing.T) {
 		},
 	})
 }
+
+func TestDataKeyRotationQueries(t *testing.T) {
+	row := &DataKeyRotation{
+		Namespace:     "ns",
+		Phase:         string(contracts.DataKeyRotationPhaseRunning),
+		CursorName:    "n1",
+		CursorVersion: 2,
+		Total:         10,
+		Processed:     4,
+		Rotated:       3,
+		Failed:        1,
+		Message:       "",
+		Started:       1234,
+		Updated:       5678,
+		Finished:      0,
+	}
+
+	mocks.CheckQuerySnapshots(t, mocks.TemplateTestSetup{
+		RootDir: "testdata",
+		Templates: map[*template.Template][]mocks.TemplateTestCase{
+			sqlDataKeyRotationCreate: {
+				{
+					Name: "create",
+					Data: &createDataKeyRotation{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Row:         row,
+					},
+				},
+			},
+			sqlDataKeyRotationRead: {
+				{
+					Name: "read",
+					Data: &readDataKeyRotation{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Namespace:   "ns",
+					},
+				},
+			},
+			sqlDataKeyRotationUpdate: {
+				{
+					Name: "update",
+					Data: &updateDataKeyRotation{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Row:         row,
+					},
+				},
+			},
+		},
+	})
+}
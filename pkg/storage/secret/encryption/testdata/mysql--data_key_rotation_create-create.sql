@@ -0,0 +1,27 @@
+INSERT INTO `secret_data_key_rotation` (
+  `namespace`,
+  `phase`,
+  `cursor_name`,
+  `cursor_version`,
+  `total`,
+  `processed`,
+  `rotated`,
+  `failed`,
+  `message`,
+  `started`,
+  `updated`,
+  `finished`
+) VALUES (
+  'ns',
+  'running',
+  'n1',
+  2,
+  10,
+  4,
+  3,
+  1,
+  '',
+  1234,
+  5678,
+  0
+);
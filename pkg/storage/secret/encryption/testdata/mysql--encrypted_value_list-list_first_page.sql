@@ -0,0 +1,18 @@
+SELECT
+  `namespace`,
+  `name`,
+  `version`,
+  `encrypted_data`,
+  `data_key_id`,
+  `created`,
+  `updated`
+FROM
+  `secret_encrypted_value`
+WHERE `namespace` = 'ns' AND
+  (
+    `name` > '' OR
+    (`name` = '' AND `version` > 0)
+  )
+ORDER BY `name` ASC, `version` ASC
+LIMIT 10
+;
@@ -0,0 +1,17 @@
+SELECT
+  "namespace",
+  "phase",
+  "cursor_name",
+  "cursor_version",
+  "total",
+  "processed",
+  "rotated",
+  "failed",
+  "message",
+  "started",
+  "updated",
+  "finished"
+FROM
+  "secret_data_key_rotation"
+WHERE "namespace" = 'ns'
+;
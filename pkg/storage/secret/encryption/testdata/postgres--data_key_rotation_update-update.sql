@@ -0,0 +1,16 @@
+UPDATE
+  "secret_data_key_rotation"
+SET
+  "phase" = 'running',
+  "cursor_name" = 'n1',
+  "cursor_version" = 2,
+  "total" = 10,
+  "processed" = 4,
+  "rotated" = 3,
+  "failed" = 1,
+  "message" = '',
+  "started" = 1234,
+  "updated" = 5678,
+  "finished" = 0
+WHERE "namespace" = 'ns'
+;
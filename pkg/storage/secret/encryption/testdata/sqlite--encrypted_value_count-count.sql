@@ -0,0 +1,5 @@
+SELECT COUNT(*) AS count
+FROM
+  "secret_encrypted_value"
+WHERE "namespace" = 'ns'
+;
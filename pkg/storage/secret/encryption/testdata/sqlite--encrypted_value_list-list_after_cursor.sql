@@ -0,0 +1,18 @@
+SELECT
+  "namespace",
+  "name",
+  "version",
+  "encrypted_data",
+  "data_key_id",
+  "created",
+  "updated"
+FROM
+  "secret_encrypted_value"
+WHERE "namespace" = 'ns' AND
+  (
+    "name" > 'n1' OR
+    ("name" = 'n1' AND "version" > 2)
+  )
+ORDER BY "name" ASC, "version" ASC
+LIMIT 10
+;
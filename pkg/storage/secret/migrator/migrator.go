@@ -12,10 +12,11 @@ import (
 )
 
 const (
-	TableNameKeeper         = "secret_keeper"
-	TableNameSecureValue    = "secret_secure_value"
-	TableNameDataKey        = "secret_data_key"
-	TableNameEncryptedValue = "secret_encrypted_value"
+	TableNameKeeper          = "secret_keeper"
+	TableNameSecureValue     = "secret_secure_value"
+	TableNameDataKey         = "secret_data_key"
+	TableNameEncryptedValue  = "secret_encrypted_value"
+	TableNameDataKeyRotation = "secret_data_key_rotation"
 )
 
 type SecretDB struct {
@@ -243,4 +244,24 @@ func (*SecretDB) AddMigration(mg *migrator.Migrator) {
 		},
 	}
 	migrator.ConvertUniqueKeyToPrimaryKey(mg, encryptedValueTableUniqueKey, updatedEncryptedValueTable)
+
+	dataKeyRotationTable := migrator.Table{
+		Name: TableNameDataKeyRotation,
+		Columns: []*migrator.Column{
+			{Name: "namespace", Type: migrator.DB_NVarchar, Length: 253, Nullable: false, IsPrimaryKey: true}, // Limit enforced by K8s.
+			{Name: "phase", Type: migrator.DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "cursor_name", Type: migrator.DB_NVarchar, Length: 253, Nullable: false}, // Name of the last processed encrypted value.
+			{Name: "cursor_version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "total", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "processed", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rotated", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "failed", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "message", Type: migrator.DB_Text, Nullable: false},
+			{Name: "started", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "finished", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		PrimaryKeys: []string{"namespace"},
+	}
+	mg.AddMigration("create table "+TableNameDataKeyRotation, migrator.NewAddTableMigration(dataKeyRotationTable))
 }
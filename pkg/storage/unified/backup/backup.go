@@ -0,0 +1,236 @@
+// Package backup snapshots and restores the resources of a namespace in unified storage.
+//
+// Backups are parquet archives (see storage/unified/parquet) holding one row per resource version,
+// with the resource version set in the metadata of each value. They work with any storage backend.
+package backup
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/parquet"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+// Options configures a namespace backup
+type Options struct {
+	Namespace string
+
+	// Resources to include, all the resources of the namespace when empty
+	Resources []schema.GroupResource
+
+	// Include every version (and deletion) of the resources, not only the latest one.
+	// This is required to restore the namespace as it was at an earlier point in time.
+	History bool
+}
+
+// Backup streams the resources of a namespace into a parquet archive
+func Backup(ctx context.Context, backend resource.StorageBackend, opts Options, w io.Writer) (*resourcepb.BulkResponse, error) {
+	if opts.Namespace == "" {
+		return nil, errors.New("missing namespace")
+	}
+
+	resources, err := namespaceResources(ctx, backend, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every resource is listed at the same resource version, so the backup is a consistent snapshot
+	// even when the namespace is written to while it runs
+	rv, err := snapshotResourceVersion(ctx, backend, resources)
+	if err != nil {
+		return nil, err
+	}
+	manifest := Manifest{
+		Namespace:       opts.Namespace,
+		ResourceVersion: rv,
+		History:         opts.History,
+	}
+
+	writer, err := parquet.NewParquetWriterWithMetadata(w, manifest.metadata())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nsr := range resources {
+		if err := backupResource(ctx, backend, nsr, manifest, writer.Write); err != nil {
+			_ = writer.Close()
+			return nil, fmt.Errorf("backup %s: %w", nsr.String(), err)
+		}
+	}
+	return writer.CloseWithResults()
+}
+
+// namespaceResources returns the requested resources, or every resource with data in the namespace
+func namespaceResources(ctx context.Context, backend resource.StorageBackend, opts Options) ([]resource.NamespacedResource, error) {
+	resources := make([]resource.NamespacedResource, 0, len(opts.Resources))
+	for _, gr := range opts.Resources {
+		resources = append(resources, resource.NamespacedResource{
+			Namespace: opts.Namespace,
+			Group:     gr.Group,
+			Resource:  gr.Resource,
+		})
+	}
+
+	if len(resources) == 0 {
+		stats, err := backend.GetResourceStats(ctx, resource.NamespacedResource{Namespace: opts.Namespace}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("list namespace resources: %w", err)
+		}
+		for _, s := range stats {
+			resources = append(resources, s.NamespacedResource)
+		}
+	}
+
+	slices.SortFunc(resources, func(a, b resource.NamespacedResource) int {
+		return cmp.Or(cmp.Compare(a.Group, b.Group), cmp.Compare(a.Resource, b.Resource))
+	})
+	return resources, nil
+}
+
+// snapshotResourceVersion returns the resource version the resources are listed at: the latest one of all the resources
+func snapshotResourceVersion(ctx context.Context, backend resource.StorageBackend, resources []resource.NamespacedResource) (int64, error) {
+	var latest int64
+	for _, nsr := range resources {
+		rv, err := backend.ListIterator(ctx, &resourcepb.ListRequest{
+			Limit: 1,
+			Options: &resourcepb.ListOptions{
+				Key: &resourcepb.ResourceKey{
+					Namespace: nsr.Namespace,
+					Group:     nsr.Group,
+					Resource:  nsr.Resource,
+				},
+			},
+		}, func(resource.ListIterator) error { return nil })
+		if err != nil {
+			return 0, fmt.Errorf("read the resource version of %s: %w", nsr.String(), err)
+		}
+		latest = max(latest, rv)
+	}
+	return latest, nil
+}
+
+type writeFunc = func(ctx context.Context, key *resourcepb.ResourceKey, value []byte) error
+
+func backupResource(ctx context.Context, backend resource.StorageBackend, nsr resource.NamespacedResource, manifest Manifest, write writeFunc) error {
+	req := &resourcepb.ListRequest{
+		Source:          resourcepb.ListRequest_STORE,
+		ResourceVersion: manifest.ResourceVersion,
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{
+				Namespace: nsr.Namespace,
+				Group:     nsr.Group,
+				Resource:  nsr.Resource,
+			},
+		},
+	}
+	list := backend.ListIterator
+	if manifest.History {
+		// Asking for everything newer than the first version returns the whole history in ascending order,
+		// including the versions written before a resource was deleted and recreated
+		req.Source = resourcepb.ListRequest_HISTORY
+		req.ResourceVersion = 1
+		req.VersionMatchV2 = resourcepb.ResourceVersionMatchV2_NotOlderThan
+		list = backend.ListHistory
+	}
+
+	_, err := list(ctx, req, func(iter resource.ListIterator) error {
+		for iter.Next() {
+			if err := iter.Error(); err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// the history has no upper bound, the versions written after the snapshot are skipped
+			if iter.ResourceVersion() > manifest.ResourceVersion {
+				continue
+			}
+
+			key := &resourcepb.ResourceKey{
+				Namespace: nsr.Namespace,
+				Group:     nsr.Group,
+				Resource:  nsr.Resource,
+				Name:      iter.Name(),
+			}
+			value, err := withResourceVersion(iter.Value(), iter.ResourceVersion())
+			if err != nil {
+				return fmt.Errorf("read %s: %w", key.Name, err)
+			}
+			if err := write(ctx, key, value); err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	})
+	return err
+}
+
+// withResourceVersion sets the version in the stored value, so it is kept in the archive
+func withResourceVersion(value []byte, rv int64) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(value); err != nil {
+		return nil, err
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	meta.SetResourceVersionInt64(rv)
+	return obj.MarshalJSON()
+}
+
+// Manifest describes a backup, it is stored in the metadata of the archive
+type Manifest struct {
+	Namespace string
+
+	// The resource version every resource was listed at
+	ResourceVersion int64
+
+	// The backup holds every version of the resources, not only the latest one
+	History bool
+}
+
+const (
+	manifestNamespace       = "grafana.backup.namespace"
+	manifestResourceVersion = "grafana.backup.resource_version"
+	manifestHistory         = "grafana.backup.history"
+)
+
+func (m Manifest) metadata() map[string]string {
+	return map[string]string{
+		manifestNamespace:       m.Namespace,
+		manifestResourceVersion: strconv.FormatInt(m.ResourceVersion, 10),
+		manifestHistory:         strconv.FormatBool(m.History),
+	}
+}
+
+// ReadManifest reads the manifest of a backup
+func ReadManifest(path string) (Manifest, error) {
+	metadata, err := parquet.ReadParquetMetadata(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("open backup: %w", err)
+	}
+
+	manifest := Manifest{Namespace: metadata[manifestNamespace]}
+	if manifest.Namespace == "" {
+		return manifest, errors.New("the backup has no manifest")
+	}
+	if manifest.ResourceVersion, err = strconv.ParseInt(metadata[manifestResourceVersion], 10, 64); err != nil {
+		return manifest, fmt.Errorf("invalid backup resource version: %w", err)
+	}
+	if manifest.History, err = strconv.ParseBool(metadata[manifestHistory]); err != nil {
+		return manifest, fmt.Errorf("invalid backup history flag: %w", err)
+	}
+	return manifest, nil
+}
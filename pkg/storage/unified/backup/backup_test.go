@@ -0,0 +1,312 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+var (
+	dashboards = resource.NamespacedResource{Namespace: "stacks-1", Group: "dashboard.grafana.app", Resource: "dashboards"}
+	baseTime   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type row struct {
+	name    string
+	rv      int64
+	title   string
+	updated time.Duration // after baseTime
+	deleted bool
+}
+
+func (r row) value(t *testing.T) []byte {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "dashboard.grafana.app/v1",
+		"kind":       "Dashboard",
+		"metadata": map[string]any{
+			"name":      r.name,
+			"namespace": dashboards.Namespace,
+			"uid":       "uid-" + r.name,
+		},
+		"spec": map[string]any{"title": r.title},
+	}}
+	meta, err := utils.MetaAccessor(obj)
+	require.NoError(t, err)
+	obj.SetCreationTimestamp(metav1.NewTime(baseTime))
+	updated := baseTime.Add(r.updated)
+	meta.SetUpdatedTimestamp(&updated)
+	meta.SetFolder("folder")
+	obj.SetGeneration(r.rv)
+	if r.deleted {
+		obj.SetGeneration(utils.DeletedGeneration)
+	}
+	value, err := obj.MarshalJSON()
+	require.NoError(t, err)
+	return value
+}
+
+// history of the dashboards:
+//   - a: created, then edited
+//   - b: created, then deleted
+//   - c: only the deletion is left after the history was pruned
+var history = []row{
+	{name: "a", rv: 1, title: "A1", updated: 1 * time.Hour},
+	{name: "b", rv: 2, title: "B1", updated: 2 * time.Hour},
+	{name: "a", rv: 3, title: "A2", updated: 3 * time.Hour},
+	{name: "b", rv: 4, title: "B1", updated: 4 * time.Hour, deleted: true},
+	{name: "c", rv: 5, title: "C1", updated: 5 * time.Hour, deleted: true},
+}
+
+// fakeBackend lists the history rows, its latest resource version is 10
+type fakeBackend struct {
+	resource.StorageBackend
+	t    *testing.T
+	rows []row
+
+	listedAt []int64 // the resource versions of the list requests
+}
+
+func (f *fakeBackend) GetResourceStats(_ context.Context, nsr resource.NamespacedResource, _ int) ([]resource.ResourceStats, error) {
+	require.Equal(f.t, dashboards.Namespace, nsr.Namespace)
+	return []resource.ResourceStats{{NamespacedResource: dashboards, Count: 1}}, nil
+}
+
+func (f *fakeBackend) ListIterator(_ context.Context, req *resourcepb.ListRequest, fn func(resource.ListIterator) error) (int64, error) {
+	f.listedAt = append(f.listedAt, req.ResourceVersion)
+	latest := map[string]row{}
+	for _, r := range f.rows {
+		latest[r.name] = r
+	}
+	var rows []row
+	for _, r := range f.rows {
+		if l := latest[r.name]; l.rv == r.rv && !l.deleted {
+			rows = append(rows, r)
+		}
+	}
+	return 10, fn(&fakeIterator{t: f.t, rows: rows, index: -1})
+}
+
+func (f *fakeBackend) ListHistory(_ context.Context, req *resourcepb.ListRequest, fn func(resource.ListIterator) error) (int64, error) {
+	require.Equal(f.t, resourcepb.ResourceVersionMatchV2_NotOlderThan, req.VersionMatchV2)
+	require.Empty(f.t, req.Options.Key.Name)
+	return 10, fn(&fakeIterator{t: f.t, rows: f.rows, index: -1})
+}
+
+type fakeIterator struct {
+	t     *testing.T
+	rows  []row
+	index int
+}
+
+func (f *fakeIterator) Next() bool             { f.index++; return f.index < len(f.rows) }
+func (f *fakeIterator) Error() error           { return nil }
+func (f *fakeIterator) ContinueToken() string  { return "" }
+func (f *fakeIterator) ResourceVersion() int64 { return f.rows[f.index].rv }
+func (f *fakeIterator) Namespace() string      { return dashboards.Namespace }
+func (f *fakeIterator) Name() string           { return f.rows[f.index].name }
+func (f *fakeIterator) Folder() string         { return "folder" }
+func (f *fakeIterator) Value() []byte          { return f.rows[f.index].value(f.t) }
+
+// fakeBulk records the restored resources
+type fakeBulk struct {
+	collections []*resourcepb.ResourceKey
+	restored    map[string]*unstructured.Unstructured
+}
+
+func (f *fakeBulk) ProcessBulk(_ context.Context, settings resource.BulkSettings, iter resource.BulkRequestIterator) *resourcepb.BulkResponse {
+	f.collections = append(f.collections, settings.Collection...)
+	f.restored = map[string]*unstructured.Unstructured{}
+	rsp := &resourcepb.BulkResponse{}
+	for iter.Next() {
+		req := iter.Request()
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(req.Value); err != nil {
+			rsp.Error = resource.AsErrorResult(err)
+			return rsp
+		}
+		f.restored[req.Key.Namespace+"/"+req.Key.Name] = obj
+		rsp.Processed++
+	}
+	return rsp
+}
+
+func writeBackup(t *testing.T, opts Options) string {
+	return writeBackupFrom(t, &fakeBackend{t: t, rows: history}, opts)
+}
+
+func writeBackupFrom(t *testing.T, backend *fakeBackend, opts Options) string {
+	var buf bytes.Buffer
+	rsp, err := Backup(context.Background(), backend, opts, &buf)
+	require.NoError(t, err)
+	require.Nil(t, rsp.Error)
+
+	path := filepath.Join(t.TempDir(), "backup.parquet")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+	return path
+}
+
+func titles(restored map[string]*unstructured.Unstructured) map[string]string {
+	out := map[string]string{}
+	for k, obj := range restored {
+		title, _, _ := unstructured.NestedString(obj.Object, "spec", "title")
+		out[k] = title
+	}
+	return out
+}
+
+func TestBackup_RequiresNamespace(t *testing.T) {
+	_, err := Backup(context.Background(), &fakeBackend{t: t}, Options{}, &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestRestoreOptions_Validate(t *testing.T) {
+	require.NoError(t, RestoreOptions{}.Validate())
+	require.NoError(t, RestoreOptions{ResourceVersion: 3}.Validate())
+	require.Error(t, RestoreOptions{ResourceVersion: -1}.Validate())
+	require.Error(t, RestoreOptions{ResourceVersion: 3, Timestamp: baseTime}.Validate())
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	path := writeBackup(t, Options{Namespace: dashboards.Namespace, History: true})
+
+	tests := []struct {
+		name     string
+		opts     RestoreOptions
+		expected map[string]string
+	}{
+		{
+			name:     "latest",
+			expected: map[string]string{"stacks-1/a": "A2"},
+		},
+		{
+			name:     "at resource version",
+			opts:     RestoreOptions{ResourceVersion: 2},
+			expected: map[string]string{"stacks-1/a": "A1", "stacks-1/b": "B1"},
+		},
+		{
+			name:     "before any change",
+			opts:     RestoreOptions{Timestamp: baseTime.Add(-time.Minute)},
+			expected: map[string]string{},
+		},
+		{
+			name: "at timestamp before the deletions",
+			opts: RestoreOptions{Timestamp: baseTime.Add(3*time.Hour + time.Minute)},
+			expected: map[string]string{
+				"stacks-1/a": "A2",
+				"stacks-1/b": "B1",
+				"stacks-1/c": "C1", // from the deletion, the earlier history was pruned
+			},
+		},
+		{
+			name: "into another namespace",
+			opts: RestoreOptions{Namespace: "stacks-2", ResourceVersion: 3},
+			expected: map[string]string{
+				"stacks-2/a": "A2",
+				"stacks-2/b": "B1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bulk := &fakeBulk{}
+			rsp, err := Restore(ctx, bulk, tt.opts, path)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.expected)), rsp.Processed)
+			require.Equal(t, tt.expected, titles(bulk.restored))
+
+			// the whole collection is replaced
+			namespace := dashboards.Namespace
+			if tt.opts.Namespace != "" {
+				namespace = tt.opts.Namespace
+			}
+			require.Equal(t, []*resourcepb.ResourceKey{{
+				Namespace: namespace,
+				Group:     dashboards.Group,
+				Resource:  dashboards.Resource,
+			}}, bulk.collections)
+
+			for _, obj := range bulk.restored {
+				require.Equal(t, namespace, obj.GetNamespace())
+				require.Empty(t, obj.GetResourceVersion())
+				require.Nil(t, obj.GetDeletionTimestamp())
+				require.Positive(t, obj.GetGeneration())
+				if namespace != dashboards.Namespace {
+					require.Empty(t, obj.GetUID())
+				}
+			}
+		})
+	}
+}
+
+func TestBackup_LatestOnly(t *testing.T) {
+	path := writeBackup(t, Options{Namespace: dashboards.Namespace})
+
+	bulk := &fakeBulk{}
+	_, err := Restore(context.Background(), bulk, RestoreOptions{}, path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"stacks-1/a": "A2"}, titles(bulk.restored))
+}
+
+func TestRestore_FilterResources(t *testing.T) {
+	path := writeBackup(t, Options{Namespace: dashboards.Namespace, History: true})
+
+	bulk := &fakeBulk{}
+	rsp, err := Restore(context.Background(), bulk, RestoreOptions{
+		Resources: []schema.GroupResource{{Group: "folder.grafana.app", Resource: "folders"}},
+	}, path)
+	require.NoError(t, err)
+	require.Zero(t, rsp.Processed)
+	require.Empty(t, bulk.collections)
+}
+
+func TestBackup_Manifest(t *testing.T) {
+	backend := &fakeBackend{t: t, rows: history}
+	path := writeBackupFrom(t, backend, Options{Namespace: dashboards.Namespace})
+
+	manifest, err := ReadManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, Manifest{Namespace: dashboards.Namespace, ResourceVersion: 10}, manifest)
+
+	// the resource version is read first, then the resources are listed at it
+	require.Equal(t, []int64{0, 10}, backend.listedAt)
+}
+
+func TestBackup_SkipsVersionsAfterTheSnapshot(t *testing.T) {
+	// written while the backup runs, after the resource version of the snapshot
+	rows := append(slices.Clone(history), row{name: "a", rv: 12, title: "A3", updated: 6 * time.Hour})
+	path := writeBackupFrom(t, &fakeBackend{t: t, rows: rows}, Options{Namespace: dashboards.Namespace, History: true})
+
+	bulk := &fakeBulk{}
+	_, err := Restore(context.Background(), bulk, RestoreOptions{}, path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"stacks-1/a": "A2"}, titles(bulk.restored))
+}
+
+func TestRestore_PointInTimeRequiresHistory(t *testing.T) {
+	path := writeBackup(t, Options{Namespace: dashboards.Namespace})
+
+	for _, opts := range []RestoreOptions{
+		{Timestamp: baseTime.Add(2 * time.Hour)},
+		{ResourceVersion: 2},
+	} {
+		bulk := &fakeBulk{}
+		_, err := Restore(context.Background(), bulk, opts, path)
+		require.ErrorContains(t, err, "requires a backup taken with the history")
+		require.Empty(t, bulk.collections)
+	}
+}
@@ -0,0 +1,339 @@
+package backup
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/storage/unified/parquet"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const readBatchSize = 100
+
+// RestoreOptions configures a restore.
+// Without a resource version or timestamp, the latest version of each resource in the backup is restored.
+type RestoreOptions struct {
+	// Namespace to restore into, defaults to the namespace of the backup
+	Namespace string
+
+	// Restore the resources as they were at this resource version (included)
+	ResourceVersion int64
+
+	// Restore the resources as they were at this time, based on their updated timestamp
+	Timestamp time.Time
+
+	// Resources to restore, everything in the backup when empty
+	Resources []schema.GroupResource
+}
+
+func (o RestoreOptions) Validate() error {
+	if o.ResourceVersion < 0 {
+		return errors.New("invalid resource version")
+	}
+	if o.ResourceVersion > 0 && !o.Timestamp.IsZero() {
+		return errors.New("restore at either a resource version or a timestamp, not both")
+	}
+	return nil
+}
+
+// pointInTime is true when the resources are restored as they were before the latest version in the backup
+func (o RestoreOptions) pointInTime() bool {
+	return o.ResourceVersion > 0 || !o.Timestamp.IsZero()
+}
+
+// Restore replaces the restored resources of the target namespace with their state from the backup.
+// Each resource type is restored with a bulk import, so its existing resources and history are removed first.
+func Restore(ctx context.Context, backend resource.BulkProcessingBackend, opts RestoreOptions, path string) (*resourcepb.BulkResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	// Without the history, the backup only has the latest versions: the resources modified or deleted
+	// after the restore point would be restored as they were when the backup was taken
+	if opts.pointInTime() && !manifest.History {
+		return nil, errors.New("restoring at a resource version or timestamp requires a backup taken with the history")
+	}
+
+	iter, err := parquet.NewParquetReader(path, readBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("open backup: %w", err)
+	}
+
+	snapshot := newSnapshot(opts)
+	for iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := snapshot.add(iter.Request()); err != nil {
+			return nil, err
+		}
+	}
+	if iter.RollbackRequested() {
+		return nil, errors.New("failed to read backup")
+	}
+
+	collections, err := snapshot.requests()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &resourcepb.BulkResponse{}
+	for _, c := range collections {
+		result := backend.ProcessBulk(ctx, resource.BulkSettings{
+			Collection: []*resourcepb.ResourceKey{c.key},
+		}, &sliceIterator{items: c.items})
+		if result.Error != nil {
+			return rsp, fmt.Errorf("restore %s/%s: %s", c.key.Group, c.key.Resource, result.Error.Message)
+		}
+		rsp.Processed += result.Processed
+		rsp.Summary = append(rsp.Summary, result.Summary...)
+		rsp.Rejected = append(rsp.Rejected, result.Rejected...)
+	}
+	return rsp, nil
+}
+
+// version of a resource read from the backup
+type version struct {
+	req     *resourcepb.BulkRequest
+	rv      int64
+	updated time.Time
+	created time.Time
+	deleted bool
+}
+
+// candidates keeps the versions around the restore point, so every other version can be dropped while reading
+type candidates struct {
+	at   *version // latest version at the restore point
+	next *version // earliest version after it
+}
+
+type snapshot struct {
+	opts       RestoreOptions
+	namespace  string // of the backup
+	resources  map[string][]string
+	candidates map[string]*candidates
+}
+
+func newSnapshot(opts RestoreOptions) *snapshot {
+	return &snapshot{
+		opts:       opts,
+		resources:  make(map[string][]string),
+		candidates: make(map[string]*candidates),
+	}
+}
+
+func (s *snapshot) includes(key *resourcepb.ResourceKey) bool {
+	if len(s.opts.Resources) == 0 {
+		return true
+	}
+	return slices.Contains(s.opts.Resources, schema.GroupResource{Group: key.Group, Resource: key.Resource})
+}
+
+// after is true when the version was written after the restore point
+func (s *snapshot) after(v *version) bool {
+	switch {
+	case s.opts.ResourceVersion > 0:
+		return v.rv > s.opts.ResourceVersion
+	case !s.opts.Timestamp.IsZero():
+		return v.updated.After(s.opts.Timestamp)
+	default:
+		return false
+	}
+}
+
+func (s *snapshot) add(req *resourcepb.BulkRequest) error {
+	if req == nil || req.Key == nil {
+		return errors.New("invalid backup row")
+	}
+	if s.namespace == "" {
+		s.namespace = req.Key.Namespace
+	} else if s.namespace != req.Key.Namespace {
+		return fmt.Errorf("the backup contains more than one namespace (%s, %s)", s.namespace, req.Key.Namespace)
+	}
+	if !s.includes(req.Key) {
+		return nil
+	}
+
+	v, err := readVersion(req)
+	if err != nil {
+		return fmt.Errorf("read %s/%s/%s: %w", req.Key.Group, req.Key.Resource, req.Key.Name, err)
+	}
+
+	id := resource.NSGR(req.Key) + "/" + req.Key.Name
+	c, ok := s.candidates[id]
+	if !ok {
+		c = &candidates{}
+		s.candidates[id] = c
+		gr := resource.NSGR(req.Key)
+		s.resources[gr] = append(s.resources[gr], id)
+	}
+
+	if s.after(v) {
+		if c.next == nil || v.rv < c.next.rv {
+			c.next = v
+		}
+	} else if c.at == nil || v.rv > c.at.rv {
+		c.at = v
+	}
+	return nil
+}
+
+type collection struct {
+	key   *resourcepb.ResourceKey
+	items []*resourcepb.BulkRequest
+}
+
+// requests returns the resources to import for each resource type, in the target namespace
+func (s *snapshot) requests() ([]collection, error) {
+	namespace := cmp.Or(s.opts.Namespace, s.namespace)
+
+	var collections []collection
+	for _, ids := range s.resources {
+		slices.Sort(ids)
+		var c collection
+		for _, id := range ids {
+			v := s.restored(s.candidates[id])
+			if v == nil {
+				continue
+			}
+			req, err := v.restore(namespace)
+			if err != nil {
+				return nil, err
+			}
+			c.items = append(c.items, req)
+		}
+
+		// The whole type is replaced, even when none of its resources existed at the restore point
+		first := s.candidates[ids[0]]
+		key := cmp.Or(first.at, first.next).req.Key
+		c.key = &resourcepb.ResourceKey{
+			Namespace: namespace,
+			Group:     key.Group,
+			Resource:  key.Resource,
+		}
+		collections = append(collections, c)
+	}
+
+	slices.SortFunc(collections, func(a, b collection) int {
+		return cmp.Or(cmp.Compare(a.key.Group, b.key.Group), cmp.Compare(a.key.Resource, b.key.Resource))
+	})
+	return collections, nil
+}
+
+// restored returns the version to restore, or nil when the resource did not exist at the restore point
+func (s *snapshot) restored(c *candidates) *version {
+	if c.at != nil {
+		if c.at.deleted {
+			return nil
+		}
+		return c.at
+	}
+
+	// The history may be pruned, so the versions before a deletion can be missing.
+	// A deletion keeps the last value of the resource, which is restored when the resource was created before the restore time.
+	if c.next != nil && c.next.deleted && !s.opts.Timestamp.IsZero() && !c.next.created.After(s.opts.Timestamp) {
+		return c.next
+	}
+	return nil
+}
+
+func readVersion(req *resourcepb.BulkRequest) (*version, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.Value); err != nil {
+		return nil, err
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := meta.GetResourceVersionInt64()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &version{
+		req:     req,
+		rv:      rv,
+		created: obj.GetCreationTimestamp().Time,
+		deleted: req.Action == resourcepb.BulkRequest_DELETED,
+	}
+	v.updated = v.created
+	if updated, err := meta.GetUpdatedTimestamp(); err == nil && updated != nil {
+		v.updated = *updated
+	}
+	return v, nil
+}
+
+// restore prepares the version to be imported in the namespace
+func (v *version) restore(namespace string) (*resourcepb.BulkRequest, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(v.req.Value); err != nil {
+		return nil, err
+	}
+	meta, err := utils.MetaAccessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new UID is set when restoring into another namespace
+	if obj.GetNamespace() != namespace {
+		obj.SetUID("")
+	}
+	obj.SetNamespace(namespace)
+	obj.SetResourceVersion("")
+	if v.deleted {
+		obj.SetDeletionTimestamp(nil)
+		obj.SetGeneration(1)
+	}
+
+	value, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &resourcepb.BulkRequest{
+		Key: &resourcepb.ResourceKey{
+			Namespace: namespace,
+			Group:     v.req.Key.Group,
+			Resource:  v.req.Key.Resource,
+			Name:      v.req.Key.Name,
+		},
+		Action: resourcepb.BulkRequest_ADDED,
+		Value:  value,
+		Folder: meta.GetFolder(),
+	}, nil
+}
+
+// sliceIterator implements resource.BulkRequestIterator over the restored resources
+type sliceIterator struct {
+	items []*resourcepb.BulkRequest
+	index int
+}
+
+func (s *sliceIterator) Next() bool {
+	if s.index >= len(s.items) {
+		return false
+	}
+	s.index++
+	return true
+}
+
+func (s *sliceIterator) Request() *resourcepb.BulkRequest {
+	return s.items[s.index-1]
+}
+
+func (s *sliceIterator) RollbackRequested() bool {
+	return false
+}
@@ -2,7 +2,9 @@ package unified
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"time"
 
@@ -93,6 +95,27 @@ func ProvideUnifiedStorageClient(opts *Options,
 	return client, err
 }
 
+// fileClient is the client of the embedded database of the file storage type. The database stays locked until
+// the backend is closed, so the client is closed when the API server stops.
+type fileClient struct {
+	resource.ResourceClient
+	server  resource.ResourceServer
+	backend resource.StorageBackend
+}
+
+var _ io.Closer = &fileClient{}
+
+func (c *fileClient) Close() error {
+	return errors.Join(c.server.Stop(context.Background()), closeBackend(c.backend))
+}
+
+func closeBackend(backend resource.StorageBackend) error {
+	if closer, ok := backend.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func newClient(opts options.StorageOptions,
 	cfg *setting.Cfg,
 	features featuremgmt.FeatureToggles,
@@ -121,9 +144,13 @@ func newClient(opts options.StorageOptions,
 			},
 		})
 		if err != nil {
-			return nil, err
+			return nil, errors.Join(err, closeBackend(backend))
 		}
-		return resource.NewLocalResourceClient(server), nil
+		return &fileClient{
+			ResourceClient: resource.NewLocalResourceClient(server),
+			server:         server,
+			backend:        backend,
+		}, nil
 
 	case options.StorageTypeUnifiedGrpc:
 		if opts.Address == "" {
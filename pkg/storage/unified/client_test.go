@@ -2,6 +2,7 @@ package unified
 
 import (
 	"context"
+	"io"
 	"net"
 	"strings"
 	"sync"
@@ -15,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/federated"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 )
@@ -99,6 +101,39 @@ func TestUnifiedStorageClient(t *testing.T) {
 	})
 }
 
+func TestFileStorageClient(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+
+	newFileClient := func() resource.ResourceClient {
+		client, err := newClient(
+			options.StorageOptions{StorageType: options.StorageTypeFile},
+			cfg,
+			featuremgmt.WithFeatures(),
+			nil,
+			nil,
+			nil,
+			authlib.FixedAccessClient(true),
+			nil,
+			nil,
+			nil,
+			nil,
+		)
+		require.NoError(t, err)
+		return client
+	}
+
+	client := federated.NewFederatedClient(newFileClient(), nil, false, false)
+	closer, ok := client.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+
+	// Closing released the embedded database, it can be opened again
+	closer, ok = newFileClient().(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+}
+
 func TestNewSearchClient(t *testing.T) {
 	t.Run("new search client fails when address is empty", func(t *testing.T) {
 		cfg := setting.NewCfg()
@@ -2,6 +2,7 @@ package federated
 
 import (
 	"context"
+	"io"
 
 	"google.golang.org/grpc"
 
@@ -28,6 +29,14 @@ type federatedClient struct {
 	stats *LegacyStatsGetter
 }
 
+// Close closes the base client when it holds resources of its own
+func (s *federatedClient) Close() error {
+	if closer, ok := s.ResourceClient.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // Get the resource stats
 func (s *federatedClient) GetStats(ctx context.Context, in *resourcepb.ResourceStatsRequest, opts ...grpc.CallOption) (*resourcepb.ResourceStatsResponse, error) {
 	rsp, err := s.ResourceClient.GetStats(ctx, in, opts...)
@@ -0,0 +1,469 @@
+package parquet
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	archiveExtension        = ".parquet"
+	archiveClusterNamespace = "__cluster__"
+)
+
+// HistoryRecord is a version of a resource stored in the history archive
+type HistoryRecord struct {
+	Key             *resourcepb.ResourceKey
+	ResourceVersion int64
+	Action          resourcepb.WatchEvent_Type
+	Folder          string
+	Value           []byte
+}
+
+// HistoryArchive stores the resource history as parquet files in an object storage bucket.
+// Every file holds consecutive versions of a single resource, at:
+//
+//	<prefix>/<group>/<resource>/<namespace>/<name>/<first rv>-<last rv>.parquet
+//
+// The resource versions are zero padded so the files of a resource are listed in order.
+type HistoryArchive struct {
+	bucket resource.CDKBucket
+	prefix string
+}
+
+func NewHistoryArchive(bucket resource.CDKBucket, prefix string) *HistoryArchive {
+	return &HistoryArchive{
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// Write stores versions of a single resource, sorted by resource version, and returns the path of the file
+func (a *HistoryArchive) Write(ctx context.Context, records []HistoryRecord) (string, error) {
+	if len(records) == 0 {
+		return "", fmt.Errorf("nothing to archive")
+	}
+
+	key := records[0].Key
+	for i, r := range records {
+		if r.Key.Namespace != key.Namespace || r.Key.Group != key.Group || r.Key.Resource != key.Resource || r.Key.Name != key.Name {
+			return "", fmt.Errorf("all the archived versions must be of the same resource")
+		}
+		if i > 0 && r.ResourceVersion <= records[i-1].ResourceVersion {
+			return "", fmt.Errorf("archived versions must be sorted by resource version")
+		}
+	}
+
+	if key.Name == "" {
+		return "", fmt.Errorf("missing name")
+	}
+	dir, err := a.dir(key)
+	if err != nil {
+		return "", err
+	}
+
+	buf := &bytes.Buffer{}
+	writer, err := NewParquetWriter(buf)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range records {
+		if err := writer.WriteVersion(r.Key, r.ResourceVersion, r.Action, r.Folder, r.Value); err != nil {
+			_ = writer.Close()
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	p := path.Join(dir, key.Name, fmt.Sprintf("%020d-%020d%s", records[0].ResourceVersion, records[len(records)-1].ResourceVersion, archiveExtension))
+	return p, a.bucket.WriteAll(ctx, p, buf.Bytes(), &blob.WriterOptions{
+		ContentType: "application/vnd.apache.parquet",
+	})
+}
+
+// Delete removes archive files, used when the archived versions could not be removed from the database
+func (a *HistoryArchive) Delete(ctx context.Context, paths ...string) error {
+	var errs []error
+	for _, p := range paths {
+		errs = append(errs, a.bucket.Delete(ctx, p))
+	}
+	return errors.Join(errs...)
+}
+
+// DeleteAll removes the archived history of the resources matching the key (the name is optional)
+func (a *HistoryArchive) DeleteAll(ctx context.Context, key *resourcepb.ResourceKey) error {
+	prefix, err := a.keyPrefix(key)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	iter := a.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !obj.IsDir {
+			paths = append(paths, obj.Key)
+		}
+	}
+	return a.Delete(ctx, paths...)
+}
+
+// History returns an iterator over the archived versions of the resources matching the key, in resource version order.
+// The name is optional, and the versions outside of the [minRV, maxRV] range are skipped (zero values mean no limit).
+// The files are read when the iteration reaches them, so a paginated history only reads the files of its page.
+func (a *HistoryArchive) History(ctx context.Context, key *resourcepb.ResourceKey, minRV, maxRV int64, ascending bool) (*HistoryIterator, error) {
+	prefix, err := a.keyPrefix(key)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	files = slices.DeleteFunc(files, func(f archiveFile) bool {
+		return (minRV > 0 && f.last < minRV) || (maxRV > 0 && f.first > maxRV)
+	})
+
+	it := &HistoryIterator{
+		ctx:       ctx,
+		bucket:    a.bucket,
+		files:     files,
+		minRV:     minRV,
+		maxRV:     maxRV,
+		ascending: ascending,
+	}
+	slices.SortFunc(files, func(a, b archiveFile) int {
+		return it.compare(it.bound(a), it.bound(b))
+	})
+	return it, nil
+}
+
+// LatestDeletion returns the resource version of the latest archived deletion of the named resource, or zero.
+// The files are read from the newest one, until a deletion is found.
+func (a *HistoryArchive) LatestDeletion(ctx context.Context, key *resourcepb.ResourceKey) (int64, error) {
+	if key.Name == "" {
+		return 0, fmt.Errorf("missing name")
+	}
+	it, err := a.History(ctx, key, 0, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	for it.Next() {
+		if r := it.Record(); r.Action == resourcepb.WatchEvent_DELETED {
+			return r.ResourceVersion, nil
+		}
+	}
+	return 0, it.Err()
+}
+
+// AtRevision returns an iterator over the archived versions of the resources matching the key at a resource version:
+// the latest version of every resource, unless it was deleted, sorted by namespace and name.
+// Without namespace, the resources of every namespace are returned. Only one file is read per resource.
+func (a *HistoryArchive) AtRevision(ctx context.Context, key *resourcepb.ResourceKey, rv int64) (*RevisionIterator, error) {
+	prefix, err := a.keyPrefix(key)
+	if err != nil {
+		return nil, err
+	}
+	if key.Namespace == "" {
+		dir, err := a.dir(key)
+		if err != nil {
+			return nil, err
+		}
+		prefix = path.Dir(dir) + "/"
+	}
+
+	files, err := a.list(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the file with the latest versions before the revision is read: the files of a resource do not overlap
+	latest := map[string]archiveFile{}
+	for _, f := range files {
+		if f.first > rv || (key.Name != "" && f.name != key.Name) {
+			continue
+		}
+		id := f.namespace + "/" + f.name
+		if current, ok := latest[id]; !ok || f.first > current.first {
+			latest[id] = f
+		}
+	}
+
+	it := &RevisionIterator{
+		ctx:    ctx,
+		bucket: a.bucket,
+		rv:     rv,
+		files:  slices.Collect(maps.Values(latest)),
+	}
+	slices.SortFunc(it.files, func(a, b archiveFile) int {
+		return cmp.Or(strings.Compare(a.namespace, b.namespace), strings.Compare(a.name, b.name))
+	})
+	return it, nil
+}
+
+// archiveFile is an archive file, with the resource and the range of resource versions parsed from its path
+type archiveFile struct {
+	path        string
+	namespace   string
+	name        string
+	first, last int64
+}
+
+// list returns the archive files under a prefix
+func (a *HistoryArchive) list(ctx context.Context, prefix string) ([]archiveFile, error) {
+	var files []archiveFile
+	iter := a.bucket.List(&blob.ListOptions{Prefix: prefix})
+	for {
+		obj, err := iter.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if obj.IsDir {
+			continue
+		}
+		if f, ok := parseArchiveFile(obj.Key); ok {
+			files = append(files, f)
+		}
+	}
+}
+
+// HistoryIterator iterates over archived versions in resource version order.
+// The files of several resources can hold interleaved versions, so the next file is read before returning a version
+// that comes after its first one.
+type HistoryIterator struct {
+	ctx          context.Context
+	bucket       resource.CDKBucket
+	files        []archiveFile // the files not read yet, in the iteration order
+	minRV, maxRV int64
+	ascending    bool
+
+	buffered []HistoryRecord // the versions read but not returned yet, in the iteration order
+	record   HistoryRecord
+	err      error
+}
+
+// Next moves to the next version, it returns false at the end or after an error
+func (it *HistoryIterator) Next() bool {
+	for it.err == nil {
+		if len(it.files) > 0 && (len(it.buffered) == 0 || it.compare(it.bound(it.files[0]), it.buffered[0].ResourceVersion) <= 0) {
+			it.read(it.files[0])
+			it.files = it.files[1:]
+			continue
+		}
+		if len(it.buffered) == 0 {
+			return false
+		}
+		it.record = it.buffered[0]
+		it.buffered = it.buffered[1:]
+		return true
+	}
+	return false
+}
+
+// Record returns the current version
+func (it *HistoryIterator) Record() HistoryRecord {
+	return it.record
+}
+
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+func (it *HistoryIterator) read(f archiveFile) {
+	records, err := readArchiveFile(it.ctx, it.bucket, f.path, func(rv int64) bool {
+		return (it.minRV == 0 || rv >= it.minRV) && (it.maxRV == 0 || rv <= it.maxRV)
+	})
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.buffered = append(it.buffered, records...)
+	slices.SortStableFunc(it.buffered, func(a, b HistoryRecord) int {
+		return it.compare(a.ResourceVersion, b.ResourceVersion)
+	})
+}
+
+// bound is the first resource version of a file in the iteration order
+func (it *HistoryIterator) bound(f archiveFile) int64 {
+	if it.ascending {
+		return f.first
+	}
+	return f.last
+}
+
+// compare resource versions in the iteration order
+func (it *HistoryIterator) compare(a, b int64) int {
+	if it.ascending {
+		return cmp.Compare(a, b)
+	}
+	return cmp.Compare(b, a)
+}
+
+// RevisionIterator iterates over the archived versions of resources at a resource version, reading a file per resource
+type RevisionIterator struct {
+	ctx    context.Context
+	bucket resource.CDKBucket
+	rv     int64
+	files  []archiveFile // the files not read yet, one per resource
+
+	record HistoryRecord
+	err    error
+}
+
+// Next moves to the next resource, it returns false at the end or after an error
+func (it *RevisionIterator) Next() bool {
+	for it.err == nil && len(it.files) > 0 {
+		f := it.files[0]
+		it.files = it.files[1:]
+
+		records, err := readArchiveFile(it.ctx, it.bucket, f.path, func(rv int64) bool { return rv <= it.rv })
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(records) == 0 {
+			continue
+		}
+		latest := slices.MaxFunc(records, func(a, b HistoryRecord) int {
+			return cmp.Compare(a.ResourceVersion, b.ResourceVersion)
+		})
+		// the resource did not exist at the revision
+		if latest.Action == resourcepb.WatchEvent_DELETED {
+			continue
+		}
+		it.record = latest
+		return true
+	}
+	return false
+}
+
+// Record returns the version of the current resource
+func (it *RevisionIterator) Record() HistoryRecord {
+	return it.record
+}
+
+func (it *RevisionIterator) Err() error {
+	return it.err
+}
+
+func (a *HistoryArchive) dir(key *resourcepb.ResourceKey) (string, error) {
+	if key.Group == "" {
+		return "", fmt.Errorf("missing group")
+	}
+	if key.Resource == "" {
+		return "", fmt.Errorf("missing resource")
+	}
+	namespace := key.Namespace
+	if namespace == "" {
+		namespace = archiveClusterNamespace
+	}
+	return path.Join(a.prefix, key.Group, key.Resource, namespace), nil
+}
+
+// keyPrefix returns the prefix of the files holding the history of the resources matching the key
+func (a *HistoryArchive) keyPrefix(key *resourcepb.ResourceKey) (string, error) {
+	dir, err := a.dir(key)
+	if err != nil {
+		return "", err
+	}
+	if key.Name != "" {
+		return path.Join(dir, key.Name) + "/", nil
+	}
+	return dir + "/", nil
+}
+
+// parseArchiveFile parses the resource and the resource versions from the path of an archive file
+func parseArchiveFile(p string) (archiveFile, bool) {
+	base, ok := strings.CutSuffix(path.Base(p), archiveExtension)
+	if !ok {
+		return archiveFile{}, false
+	}
+	first, last, ok := strings.Cut(base, "-")
+	if !ok {
+		return archiveFile{}, false
+	}
+	firstRV, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return archiveFile{}, false
+	}
+	lastRV, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return archiveFile{}, false
+	}
+
+	dir := path.Dir(p)
+	namespace := path.Base(path.Dir(dir))
+	if namespace == archiveClusterNamespace {
+		namespace = ""
+	}
+	return archiveFile{
+		path:      p,
+		namespace: namespace,
+		name:      path.Base(dir),
+		first:     firstRV,
+		last:      lastRV,
+	}, true
+}
+
+// readArchiveFile reads the versions of an archive file accepted by the filter.
+// The values of the other versions are not copied.
+func readArchiveFile(ctx context.Context, bucket resource.CDKBucket, p string, filter func(rv int64) bool) ([]HistoryRecord, error) {
+	data, err := bucket.ReadAll(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	records, err := readHistory(data, filter)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", p, err)
+	}
+	return records, nil
+}
+
+func readHistory(data []byte, filter func(rv int64) bool) ([]HistoryRecord, error) {
+	reader, err := newBufferReader(data, 100)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []HistoryRecord
+	for reader.Next() {
+		if !filter(reader.ResourceVersion()) {
+			continue
+		}
+		req := reader.Request()
+		records = append(records, HistoryRecord{
+			Key:             req.Key,
+			ResourceVersion: reader.ResourceVersion(),
+			Action:          resourcepb.WatchEvent_Type(req.Action),
+			Folder:          req.Folder,
+			// the reader buffers are reused by the next batch
+			Value: bytes.Clone(req.Value),
+		})
+	}
+	return records, reader.Err()
+}
@@ -0,0 +1,166 @@
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+func TestHistoryArchive(t *testing.T) {
+	ctx := context.Background()
+	bucket := memblob.OpenBucket(nil)
+	t.Cleanup(func() { _ = bucket.Close() })
+	archive := NewHistoryArchive(bucket, "/history/")
+
+	versions := func(name string, rvs ...int64) []HistoryRecord {
+		records := make([]HistoryRecord, 0, len(rvs))
+		for i, rv := range rvs {
+			action := resourcepb.WatchEvent_MODIFIED
+			if i == 0 {
+				action = resourcepb.WatchEvent_ADDED
+			}
+			records = append(records, HistoryRecord{
+				Key:             &resourcepb.ResourceKey{Namespace: "ns", Group: "ggg", Resource: "rrr", Name: name},
+				ResourceVersion: rv,
+				Action:          action,
+				Folder:          "folder",
+				Value:           fmt.Appendf(nil, `{"metadata":{"name":%q},"spec":{"rv":%d}}`, name, rv),
+			})
+		}
+		return records
+	}
+
+	p, err := archive.Write(ctx, versions("aaa", 10, 20))
+	require.NoError(t, err)
+	require.Equal(t, "history/ggg/rrr/ns/aaa/00000000000000000010-00000000000000000020.parquet", p)
+
+	_, err = archive.Write(ctx, versions("aaa", 30, 40))
+	require.NoError(t, err)
+	_, err = archive.Write(ctx, versions("aab", 15, 35))
+	require.NoError(t, err)
+
+	history := func(t *testing.T, key *resourcepb.ResourceKey, minRV, maxRV int64, ascending bool) ([]string, []HistoryRecord) {
+		t.Helper()
+		it, err := archive.History(ctx, key, minRV, maxRV, ascending)
+		require.NoError(t, err)
+
+		var found []string
+		var records []HistoryRecord
+		for it.Next() {
+			r := it.Record()
+			found = append(found, fmt.Sprintf("%s@%d", r.Key.Name, r.ResourceVersion))
+			records = append(records, r)
+		}
+		require.NoError(t, it.Err())
+		return found, records
+	}
+	resourceKey := func(namespace, name string) *resourcepb.ResourceKey {
+		return &resourcepb.ResourceKey{Namespace: namespace, Group: "ggg", Resource: "rrr", Name: name}
+	}
+
+	t.Run("read a resource", func(t *testing.T) {
+		found, records := history(t, resourceKey("ns", "aaa"), 0, 0, true)
+		require.Equal(t, []string{"aaa@10", "aaa@20", "aaa@30", "aaa@40"}, found)
+		require.Equal(t, resourcepb.WatchEvent_ADDED, records[0].Action)
+		require.Equal(t, resourcepb.WatchEvent_MODIFIED, records[1].Action)
+		require.Equal(t, "folder", records[0].Folder)
+		require.JSONEq(t, `{"metadata":{"name":"aaa"},"spec":{"rv":10}}`, string(records[0].Value))
+	})
+
+	t.Run("read all resources", func(t *testing.T) {
+		found, _ := history(t, resourceKey("ns", ""), 0, 0, true)
+		require.Equal(t, []string{"aaa@10", "aab@15", "aaa@20", "aaa@30", "aab@35", "aaa@40"}, found)
+
+		found, _ = history(t, resourceKey("ns", ""), 0, 0, false)
+		require.Equal(t, []string{"aaa@40", "aab@35", "aaa@30", "aaa@20", "aab@15", "aaa@10"}, found)
+	})
+
+	t.Run("read the files when the iteration reaches them", func(t *testing.T) {
+		it, err := archive.History(ctx, resourceKey("ns", "aaa"), 0, 0, false)
+		require.NoError(t, err)
+		require.True(t, it.Next())
+		require.Equal(t, int64(40), it.Record().ResourceVersion)
+		require.Len(t, it.files, 1, "the oldest file is not read yet")
+	})
+
+	t.Run("read the latest deletion", func(t *testing.T) {
+		deleted := versions("ccc", 50, 60, 70)
+		deleted[1].Action = resourcepb.WatchEvent_DELETED
+		_, err := archive.Write(ctx, deleted)
+		require.NoError(t, err)
+
+		rv, err := archive.LatestDeletion(ctx, resourceKey("ns", "ccc"))
+		require.NoError(t, err)
+		require.Equal(t, int64(60), rv)
+
+		rv, err = archive.LatestDeletion(ctx, resourceKey("ns", "aaa"))
+		require.NoError(t, err)
+		require.Zero(t, rv)
+	})
+
+	t.Run("read the resources at a revision", func(t *testing.T) {
+		atRevision := func(key *resourcepb.ResourceKey, rv int64) []string {
+			it, err := archive.AtRevision(ctx, key, rv)
+			require.NoError(t, err)
+			var found []string
+			for it.Next() {
+				r := it.Record()
+				found = append(found, fmt.Sprintf("%s/%s@%d", r.Key.Namespace, r.Key.Name, r.ResourceVersion))
+			}
+			require.NoError(t, it.Err())
+			return found
+		}
+
+		require.Equal(t, []string{"ns/aaa@20", "ns/aab@15"}, atRevision(resourceKey("ns", ""), 25))
+		require.Equal(t, []string{"ns/aaa@40", "ns/aab@35", "ns/ccc@50"}, atRevision(resourceKey("ns", ""), 55))
+		require.Equal(t, []string{"ns/aaa@40", "ns/aab@35"}, atRevision(resourceKey("ns", ""), 65), "deleted resources are skipped")
+		require.Equal(t, []string{"ns/aaa@40", "ns/aab@35", "ns/ccc@70"}, atRevision(resourceKey("", ""), 100))
+		require.Equal(t, []string{"ns/aab@35"}, atRevision(resourceKey("ns", "aab"), 100))
+		require.Empty(t, atRevision(resourceKey("ns", ""), 5))
+		require.NoError(t, archive.DeleteAll(ctx, resourceKey("ns", "ccc")))
+	})
+
+	t.Run("skip files out of range", func(t *testing.T) {
+		found, _ := history(t, resourceKey("ns", "aaa"), 25, 0, true)
+		require.Equal(t, []string{"aaa@30", "aaa@40"}, found)
+
+		found, _ = history(t, resourceKey("ns", "aaa"), 0, 25, true)
+		require.Equal(t, []string{"aaa@10", "aaa@20"}, found)
+
+		found, _ = history(t, resourceKey("ns", "aaa"), 20, 30, true)
+		require.Equal(t, []string{"aaa@20", "aaa@30"}, found)
+	})
+
+	t.Run("other namespace", func(t *testing.T) {
+		found, _ := history(t, resourceKey("other", "aaa"), 0, 0, true)
+		require.Empty(t, found)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, archive.Delete(ctx, p))
+		found, _ := history(t, resourceKey("ns", "aaa"), 0, 0, true)
+		require.Equal(t, []string{"aaa@30", "aaa@40"}, found)
+	})
+
+	t.Run("delete all", func(t *testing.T) {
+		require.NoError(t, archive.DeleteAll(ctx, resourceKey("ns", "")))
+		found, _ := history(t, resourceKey("ns", ""), 0, 0, true)
+		require.Empty(t, found)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := archive.Write(ctx, nil)
+		require.Error(t, err)
+
+		_, err = archive.Write(ctx, append(versions("aaa", 50), versions("bbb", 60)...))
+		require.Error(t, err)
+
+		_, err = archive.Write(ctx, append(versions("aaa", 70), versions("aaa", 60)...))
+		require.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package parquet
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/apache/arrow-go/v18/parquet"
@@ -18,9 +19,27 @@ func NewParquetReader(inputPath string, batchSize int64) (resource.BulkRequestIt
 	return newResourceReader(inputPath, batchSize)
 }
 
+// ReadParquetMetadata reads the key-value metadata of a parquet file, without reading its rows
+func ReadParquetMetadata(inputPath string) (map[string]string, error) {
+	rdr, err := file.OpenParquetFile(inputPath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rdr.Close() }()
+
+	kv := rdr.MetaData().KeyValueMetadata()
+	keys, values := kv.Keys(), kv.Values()
+	metadata := make(map[string]string, len(keys))
+	for i, key := range keys {
+		metadata[key] = values[i]
+	}
+	return metadata, nil
+}
+
 type parquetReader struct {
 	reader *file.Reader
 
+	rv        *int64Column
 	namespace *stringColumn
 	group     *stringColumn
 	resource  *stringColumn
@@ -91,6 +110,19 @@ func (r *parquetReader) Request() *resourcepb.BulkRequest {
 	return r.req
 }
 
+// ResourceVersion returns the resource version of the current request (zero when it was not known when writing)
+func (r *parquetReader) ResourceVersion() int64 {
+	if r.req == nil || r.rv.index < 0 {
+		return 0
+	}
+	return r.rv.buffer[r.bufferIndex-1]
+}
+
+// Err returns the error that stopped the iteration
+func (r *parquetReader) Err() error {
+	return r.err
+}
+
 // RollbackRequested implements resource.BulkRequestIterator.
 func (r *parquetReader) RollbackRequested() bool {
 	return r.err != nil
@@ -101,7 +133,20 @@ func newResourceReader(inputPath string, batchSize int64) (*parquetReader, error
 	if err != nil {
 		return nil, err
 	}
+	return newReader(rdr, batchSize)
+}
+
+// newBufferReader reads a parquet file loaded in memory
+func newBufferReader(data []byte, batchSize int64) (*parquetReader, error) {
+	rdr, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return newReader(rdr, batchSize)
+}
 
+func newReader(rdr *file.Reader, batchSize int64) (*parquetReader, error) {
+	var err error
 	schema := rdr.MetaData().Schema
 	makeColumn := func(name string) *stringColumn {
 		index := schema.ColumnIndexByName(name)
@@ -128,6 +173,11 @@ func newResourceReader(inputPath string, batchSize int64) (*parquetReader, error
 			index:  schema.ColumnIndexByName("action"),
 			buffer: make([]int32, batchSize),
 		},
+		// files written before the column was read may not have it
+		rv: &int64Column{
+			index:  schema.ColumnIndexByName("resource_version"),
+			buffer: make([]int64, batchSize),
+		},
 
 		batchSize: batchSize,
 		defLevels: make([]int16, batchSize),
@@ -148,6 +198,9 @@ func newResourceReader(inputPath string, batchSize int64) (*parquetReader, error
 		reader.action,
 		reader.value,
 	}
+	if reader.rv.index >= 0 {
+		reader.columns = append(reader.columns, reader.rv)
+	}
 
 	// Empty file, close and return
 	if rdr.NumRowGroups() < 1 {
@@ -259,6 +312,32 @@ func (c *int32Column) batch(batchSize int64, defLevels []int16, repLevels []int1
 	return count, err
 }
 
+type int64Column struct {
+	index  int // within the schema
+	reader *file.Int64ColumnChunkReader
+	buffer []int64
+	count  int // the active count
+}
+
+func (c *int64Column) open(rgr *file.RowGroupReader) error {
+	tmp, err := rgr.Column(c.index)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	c.reader, ok = tmp.(*file.Int64ColumnChunkReader)
+	if !ok {
+		return fmt.Errorf("expected resource versions")
+	}
+	return nil
+}
+
+func (c *int64Column) batch(batchSize int64, defLevels []int16, repLevels []int16) (int, error) {
+	_, count, err := c.reader.ReadBatch(batchSize, c.buffer, defLevels, repLevels)
+	c.count = count
+	return count, err
+}
+
 //-------------------------------
 // Column support
 //-------------------------------
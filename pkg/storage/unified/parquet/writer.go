@@ -25,9 +25,20 @@ var (
 
 // Write resources into a parquet file
 func NewParquetWriter(f io.Writer) (*parquetWriter, error) {
+	return NewParquetWriterWithMetadata(f, nil)
+}
+
+// NewParquetWriterWithMetadata writes resources into a parquet file, with key-value metadata stored in the file footer
+func NewParquetWriterWithMetadata(f io.Writer, metadata map[string]string) (*parquetWriter, error) {
+	var schemaMetadata *arrow.Metadata
+	if len(metadata) > 0 {
+		m := arrow.MetadataFrom(metadata)
+		schemaMetadata = &m
+	}
+
 	w := &parquetWriter{
 		pool:    memory.DefaultAllocator,
-		schema:  newSchema(nil),
+		schema:  newSchema(schemaMetadata),
 		buffer:  1024 * 10 * 100 * 10, // 10MB
 		logger:  logging.DefaultLogger.With("logger", "parquet.writer"),
 		rsp:     &resourcepb.BulkResponse{},
@@ -163,14 +174,6 @@ func (w *parquetWriter) Write(ctx context.Context, key *resourcepb.ResourceKey,
 	}
 	rv, _ := meta.GetResourceVersionInt64() // it can be empty
 
-	w.rv.Append(rv)
-	w.namespace.Append(key.Namespace)
-	w.group.Append(key.Group)
-	w.resource.Append(key.Resource)
-	w.name.Append(key.Name)
-	w.folder.Append(meta.GetFolder())
-	w.value.Append(string(value))
-
 	var action resourcepb.WatchEvent_Type
 	switch meta.GetGeneration() {
 	case 0, 1:
@@ -180,13 +183,24 @@ func (w *parquetWriter) Write(ctx context.Context, key *resourcepb.ResourceKey,
 	default:
 		action = resourcepb.WatchEvent_MODIFIED
 	}
-	w.action.Append(int8(action))
+	return w.append(key, rv, action, meta.GetFolder(), value)
+}
 
-	w.wrote = w.wrote + len(value)
-	if w.wrote > w.buffer {
-		w.logger.Info("buffer full", "buffer", w.wrote, "max", w.buffer)
-		return w.flush()
-	}
+// WriteVersion writes a version of a resource, without reading the resource version and action from the value
+func (w *parquetWriter) WriteVersion(key *resourcepb.ResourceKey, rv int64, action resourcepb.WatchEvent_Type, folder string, value []byte) error {
+	w.rsp.Processed++
+	return w.append(key, rv, action, folder, value)
+}
+
+func (w *parquetWriter) append(key *resourcepb.ResourceKey, rv int64, action resourcepb.WatchEvent_Type, folder string, value []byte) error {
+	w.rv.Append(rv)
+	w.namespace.Append(key.Namespace)
+	w.group.Append(key.Group)
+	w.resource.Append(key.Resource)
+	w.name.Append(key.Name)
+	w.folder.Append(folder)
+	w.action.Append(int8(action))
+	w.value.Append(string(value))
 
 	summary := w.summary[resource.NSGR(key)]
 	if summary == nil {
@@ -199,6 +213,12 @@ func (w *parquetWriter) Write(ctx context.Context, key *resourcepb.ResourceKey,
 		w.rsp.Summary = append(w.rsp.Summary, summary)
 	}
 	summary.Count++
+
+	w.wrote = w.wrote + len(value)
+	if w.wrote > w.buffer {
+		w.logger.Info("buffer full", "buffer", w.wrote, "max", w.buffer)
+		return w.flush()
+	}
 	return nil
 }
 
@@ -357,6 +357,7 @@ const (
 	SEARCH_FIELD_SOURCE_TIME        = "source.timestampMillis"
 	SEARCH_FIELD_SCORE              = "_score"            // the match score
 	SEARCH_FIELD_EXPLAIN            = "_explain"          // score explanation as JSON object
+	SEARCH_FIELD_HIGHLIGHT          = "_highlight"        // matching fragments by field as JSON object
 	SEARCH_SELECTABLE_FIELDS_PREFIX = "selectableFields." // Prefix for searching selectable fields.
 )
 
@@ -446,6 +447,11 @@ func StandardSearchFields() SearchableDocumentFields {
 				Type:        resourcepb.ResourceTableColumnDefinition_OBJECT,
 				Description: "Explain why this result matches (depends on the engine)",
 			},
+			{
+				Name:        SEARCH_FIELD_HIGHLIGHT,
+				Type:        resourcepb.ResourceTableColumnDefinition_OBJECT,
+				Description: "The matching text fragments for each field (depends on the engine)",
+			},
 			{
 				Name:        SEARCH_FIELD_SCORE,
 				Type:        resourcepb.ResourceTableColumnDefinition_DOUBLE,
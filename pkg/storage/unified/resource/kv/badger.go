@@ -0,0 +1,148 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+const (
+	defaultValueLogGCInterval     = 10 * time.Minute
+	defaultValueLogGCDiscardRatio = 0.5
+
+	// number of pending writes while loading a backup
+	restoreMaxPendingWrites = 256
+)
+
+// BadgerOptions configures the embedded BadgerDB store
+type BadgerOptions struct {
+	Path     string // directory of the database, ignored when InMemory is set
+	InMemory bool   // keep everything in memory, nothing is persisted
+
+	// How often the value log is garbage collected (default 10m, negative disables it)
+	ValueLogGCInterval time.Duration
+	// Rewrite a value log file when at least this ratio of it can be discarded (default 0.5)
+	ValueLogGCDiscardRatio float64
+}
+
+// BadgerStore is the embedded KV store for single node deployments.
+// It owns the database and garbage collects the value log in the background until closed.
+type BadgerStore struct {
+	*badgerKV
+
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ KV = &BadgerStore{}
+
+// OpenBadger opens (or creates) the BadgerDB database configured by opts
+func OpenBadger(opts BadgerOptions) (*BadgerStore, error) {
+	if !opts.InMemory && opts.Path == "" {
+		return nil, errors.New("missing badger database path")
+	}
+	if opts.ValueLogGCInterval == 0 {
+		opts.ValueLogGCInterval = defaultValueLogGCInterval
+	}
+	if opts.ValueLogGCDiscardRatio <= 0 || opts.ValueLogGCDiscardRatio >= 1 {
+		opts.ValueLogGCDiscardRatio = defaultValueLogGCDiscardRatio
+	}
+
+	badgerOpts := badger.DefaultOptions(opts.Path).WithLogger(nil)
+	if opts.InMemory {
+		badgerOpts = badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	}
+	db, err := badger.Open(badgerOpts)
+	if err != nil {
+		return nil, fmt.Errorf("open badger database: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &BadgerStore{
+		badgerKV: NewBadgerKV(db),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	// The value log of an in-memory database can not be garbage collected
+	if opts.InMemory || opts.ValueLogGCInterval < 0 {
+		close(s.done)
+		return s, nil
+	}
+	go s.runValueLogGC(ctx, opts.ValueLogGCInterval, opts.ValueLogGCDiscardRatio)
+	return s, nil
+}
+
+func (s *BadgerStore) runValueLogGC(ctx context.Context, interval time.Duration, discardRatio float64) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Each successful run rewrites a single file, keep going until there is nothing left to reclaim
+			for ctx.Err() == nil {
+				if err := s.db.RunValueLogGC(discardRatio); err != nil {
+					break // badger.ErrNoRewrite when nothing could be reclaimed
+				}
+			}
+		}
+	}
+}
+
+// Close stops the background garbage collection and closes the database
+func (s *BadgerStore) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.cancel()
+		<-s.done
+		err = s.db.Close()
+	})
+	return err
+}
+
+// Backup writes a full, consistent snapshot of the store to w.
+// The database is locked by the process that opened it, so Grafana must be stopped to back it up from the CLI.
+// It returns the version of the snapshot.
+func (s *BadgerStore) Backup(ctx context.Context, w io.Writer) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	stream := s.db.NewStream()
+	stream.LogPrefix = "unified storage backup"
+	return stream.Backup(w, 0)
+}
+
+// Restore loads a snapshot written by Backup.
+// The store must be empty, so a restore never mixes the backup with existing data.
+func (s *BadgerStore) Restore(ctx context.Context, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	empty := true
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return errors.New("can not restore into a database that is not empty")
+	}
+	return s.db.Load(r, restoreMaxPendingWrites)
+}
@@ -0,0 +1,72 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readValue(t *testing.T, store KV, section, key string) string {
+	t.Helper()
+	r, err := store.Get(context.Background(), section, key)
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	value, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(value)
+}
+
+func TestOpenBadger(t *testing.T) {
+	t.Run("requires a path", func(t *testing.T) {
+		_, err := OpenBadger(BadgerOptions{})
+		require.Error(t, err)
+	})
+
+	t.Run("persists data", func(t *testing.T) {
+		opts := BadgerOptions{Path: filepath.Join(t.TempDir(), "badger")}
+
+		store, err := OpenBadger(opts)
+		require.NoError(t, err)
+		saveKVHelper(t, store, context.Background(), "unified/data", "a", strings.NewReader("hello"))
+		require.NoError(t, store.Close())
+		require.NoError(t, store.Close()) // closing twice is safe
+
+		store, err = OpenBadger(opts)
+		require.NoError(t, err)
+		defer func() { _ = store.Close() }()
+		require.Equal(t, "hello", readValue(t, store, "unified/data", "a"))
+	})
+}
+
+func TestBadgerStore_BackupRestore(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := OpenBadger(BadgerOptions{Path: filepath.Join(t.TempDir(), "badger")})
+	require.NoError(t, err)
+	defer func() { _ = source.Close() }()
+
+	saveKVHelper(t, source, ctx, "unified/data", "a", strings.NewReader("hello"))
+	saveKVHelper(t, source, ctx, "unified/events", "b", strings.NewReader("world"))
+
+	var backup bytes.Buffer
+	version, err := source.Backup(ctx, &backup)
+	require.NoError(t, err)
+	require.Greater(t, version, uint64(0))
+
+	target, err := OpenBadger(BadgerOptions{InMemory: true})
+	require.NoError(t, err)
+	defer func() { _ = target.Close() }()
+
+	require.NoError(t, target.Restore(ctx, bytes.NewReader(backup.Bytes())))
+	require.Equal(t, "hello", readValue(t, target, "unified/data", "a"))
+	require.Equal(t, "world", readValue(t, target, "unified/events", "b"))
+
+	// Restoring again would mix the backup with existing data
+	err = target.Restore(ctx, bytes.NewReader(backup.Bytes()))
+	require.ErrorContains(t, err, "not empty")
+}
@@ -121,7 +121,7 @@ type KV interface {
 var _ KV = &badgerKV{}
 
 // Reference implementation of the KV interface using BadgerDB
-// This is only used for testing purposes, and will not work HA
+// This is used by the embedded single node store (see OpenBadger) and in tests, it will not work HA
 type badgerKV struct {
 	db *badger.DB
 }
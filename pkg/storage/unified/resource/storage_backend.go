@@ -68,6 +68,7 @@ func convertEmptyToClusterNamespace(namespace string, withExperimentalClusterSco
 
 // kvStorageBackend Unified storage backend based on KV storage.
 type kvStorageBackend struct {
+	cancel                       context.CancelFunc // stops the background jobs
 	snowflake                    *snowflake.Node
 	kv                           KV
 	bulkLock                     *BulkLock
@@ -96,7 +97,10 @@ type kvStorageBackend struct {
 	tenantWatcher *TenantWatcher
 }
 
-var _ KVBackend = &kvStorageBackend{}
+var (
+	_ KVBackend = &kvStorageBackend{}
+	_ io.Closer = &kvStorageBackend{}
+)
 
 type KVBackend interface {
 	StorageBackend
@@ -168,7 +172,10 @@ func NewKVStorageBackend(opts KVBackendOptions) (KVBackend, error) {
 		eventPruningInterval = defaultEventPruningInterval
 	}
 
+	// The background jobs run until the backend is closed
+	ctx, cancel := context.WithCancel(ctx)
 	backend := &kvStorageBackend{
+		cancel:                       cancel,
 		kv:                           kv,
 		bulkLock:                     NewBulkLock(),
 		dataStore:                    newDataStore(kv),
@@ -187,10 +194,12 @@ func NewKVStorageBackend(opts KVBackendOptions) (KVBackend, error) {
 	}
 	err = backend.initPruner(ctx)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to initialize pruner: %w", err)
 	}
 	if backend.garbageCollection.Enabled {
 		if err := backend.initGarbageCollection(ctx); err != nil {
+			cancel()
 			return nil, fmt.Errorf("failed to initialize garbage collection: %w", err)
 		}
 	}
@@ -201,6 +210,7 @@ func NewKVStorageBackend(opts KVBackendOptions) (KVBackend, error) {
 			return backend.WriteEvent(ctx, *event)
 		}, *opts.TenantWatcherConfig)
 		if err != nil {
+			cancel()
 			return nil, fmt.Errorf("failed to start tenant watcher: %w", err)
 		}
 		backend.tenantWatcher = tw
@@ -214,6 +224,18 @@ func NewKVStorageBackend(opts KVBackendOptions) (KVBackend, error) {
 	return backend, nil
 }
 
+// Close stops the background jobs, and closes the KV store when it holds resources of its own, like the embedded
+// database of single node deployments. Stores sharing a connection with the rest of Grafana are left open.
+func (k *kvStorageBackend) Close() error {
+	if k.cancel != nil {
+		k.cancel()
+	}
+	if closer, ok := k.kv.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (k *kvStorageBackend) IsHealthy(ctx context.Context, _ *resourcepb.HealthCheckRequest) (*resourcepb.HealthCheckResponse, error) {
 	type pinger interface {
 		Ping(context.Context) error
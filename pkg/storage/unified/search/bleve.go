@@ -327,8 +327,9 @@ func newBleveIndex(path string, mapper mapping.IndexMapping, buildTime time.Time
 	}
 
 	bi := buildInfo{
-		BuildTime:    buildTime.Unix(),
-		BuildVersion: buildVersion,
+		BuildTime:      buildTime.Unix(),
+		BuildVersion:   buildVersion,
+		MappingVersion: indexMappingVersion,
 	}
 
 	biBytes, err := json.Marshal(bi)
@@ -345,8 +346,9 @@ func newBleveIndex(path string, mapper mapping.IndexMapping, buildTime time.Time
 }
 
 type buildInfo struct {
-	BuildTime    int64  `json:"build_time"`    // Unix seconds timestamp of time when the index was built
-	BuildVersion string `json:"build_version"` // Grafana version used when building the index
+	BuildTime      int64  `json:"build_time"`                // Unix seconds timestamp of time when the index was built
+	BuildVersion   string `json:"build_version"`             // Grafana version used when building the index
+	MappingVersion int    `json:"mapping_version,omitempty"` // Version of the mappings used when building the index
 }
 
 // BuildIndex builds an index from scratch or retrieves it from the filesystem.
@@ -436,21 +438,18 @@ func (b *bleveBackend) BuildIndex(
 				return nil, findErr
 			}
 
-			// Check if we need to rebuild based on lastImportTime
-			if index != nil && !lastImportTime.IsZero() {
+			// Check if we need to rebuild based on the mappings or lastImportTime
+			if index != nil {
 				bi, err := getBuildInfo(index)
 				if err != nil {
 					logWithDetails.Warn("failed to get build info from existing index", "error", err)
 					// Continue with existing index despite error
-				} else if bi.BuildTime > 0 {
-					indexBuildTime := time.Unix(bi.BuildTime, 0)
-					if indexBuildTime.Before(lastImportTime) {
-						logWithDetails.Info("File-based index needs rebuild before opening", "buildTime", indexBuildTime, "lastImportTime", lastImportTime)
-						// Close the index and rebuild from scratch
-						_ = index.Close()
-						index = nil
-						fileIndexName = ""
-					}
+				} else if needsRebuild, reason := indexNeedsRebuild(bi, lastImportTime); needsRebuild {
+					logWithDetails.Info("File-based index needs rebuild before opening", "reason", reason, "buildTime", time.Unix(bi.BuildTime, 0), "mappingVersion", bi.MappingVersion, "lastImportTime", lastImportTime)
+					// Close the index and rebuild from scratch
+					_ = index.Close()
+					index = nil
+					fileIndexName = ""
 				}
 			}
 		}
@@ -864,6 +863,18 @@ func getRV(index bleve.Index) (int64, error) {
 	return int64(binary.BigEndian.Uint64(raw)), nil
 }
 
+// indexNeedsRebuild returns whether an existing index must be rebuilt, because it was built with other mappings
+// or before the last import.
+func indexNeedsRebuild(bi buildInfo, lastImportTime time.Time) (bool, string) {
+	if bi.MappingVersion != indexMappingVersion {
+		return true, "mappings changed"
+	}
+	if !lastImportTime.IsZero() && bi.BuildTime > 0 && time.Unix(bi.BuildTime, 0).Before(lastImportTime) {
+		return true, "built before last import"
+	}
+	return false, ""
+}
+
 func getBuildInfo(index bleve.Index) (buildInfo, error) {
 	raw, err := index.GetInternal([]byte(internalBuildInfoKey))
 	if err != nil {
@@ -1126,7 +1137,7 @@ func (b *bleveIndex) Search(
 
 	// parse the facet fields
 	for k, v := range res.Facets {
-		f := newResponseFacet(v)
+		f := newResponseFacet(v, searchrequest.Facets[k])
 		if response.Facet == nil {
 			response.Facet = make(map[string]*resourcepb.ResourceSearchResponse_Facet)
 		}
@@ -1214,9 +1225,14 @@ func (b *bleveIndex) toBleveSearchRequest(ctx context.Context, req *resourcepb.R
 	ctx, span := tracer.Start(ctx, "search.bleveIndex.toBleveSearchRequest") //nolint:staticcheck,ineffassign // SA4006: ctx intentionally kept so future code added to this function inherits the traced span
 	defer span.End()
 
+	now := time.Now()
 	facets := bleve.FacetsRequest{}
 	for _, f := range req.Facet {
-		facets[f.Field] = bleve.NewFacetRequest(f.Field, int(f.Limit))
+		facet, err := newFacetRequest(f, now)
+		if err != nil {
+			return nil, resource.NewBadRequestError(err.Error())
+		}
+		facets[f.Field] = facet
 	}
 
 	// Convert resource-specific fields to bleve fields.
@@ -1333,6 +1349,16 @@ func (b *bleveIndex) toBleveSearchRequest(ctx context.Context, req *resourcepb.R
 					disjoin.AddQuery(q)
 				}
 			}
+
+			// Highlighting is opt-in, it requires the stored text and term vectors of the matching fields
+			if slices.Contains(req.Fields, resource.SEARCH_FIELD_HIGHLIGHT) {
+				searchrequest.Highlight = bleve.NewHighlight()
+				for _, field := range queryFields {
+					if field.Type != resourcepb.QueryFieldType_KEYWORD {
+						searchrequest.Highlight.AddField(field.Name)
+					}
+				}
+			}
 		}
 	}
 
@@ -1373,7 +1399,11 @@ func (b *bleveIndex) toBleveSearchRequest(ctx context.Context, req *resourcepb.R
 		if searchrequest.Facets == nil {
 			searchrequest.Facets = make(bleve.FacetsRequest)
 		}
-		searchrequest.Facets[k] = bleve.NewFacetRequest(v.Field, int(v.Limit))
+		facet, err := newFacetRequest(v, now)
+		if err != nil {
+			return nil, resource.NewBadRequestError(err.Error())
+		}
+		searchrequest.Facets[k] = facet
 	}
 
 	// Add the sort fields
@@ -1841,6 +1871,14 @@ func (b *bleveIndex) hitsToTable(ctx context.Context, selectFields []string, hit
 				if match.Expl != nil {
 					row.Cells[i], err = json.Marshal(match.Expl)
 				}
+			case resource.SEARCH_FIELD_HIGHLIGHT:
+				if len(match.Fragments) > 0 {
+					fragments := make(map[string][]string, len(match.Fragments))
+					for k, v := range match.Fragments {
+						fragments[strings.TrimPrefix(k, resource.SEARCH_FIELD_PREFIX)] = v
+					}
+					row.Cells[i], err = json.Marshal(fragments)
+				}
 			case resource.SEARCH_FIELD_LEGACY_ID:
 				v := match.Fields[resource.SEARCH_FIELD_LABELS+"."+resource.SEARCH_FIELD_LEGACY_ID]
 				if v != nil {
@@ -1902,23 +1940,6 @@ func getAllFields(standard resource.SearchableDocumentFields, custom resource.Se
 	return fields, nil
 }
 
-func newResponseFacet(v *search.FacetResult) *resourcepb.ResourceSearchResponse_Facet {
-	f := &resourcepb.ResourceSearchResponse_Facet{
-		Field:   v.Field,
-		Total:   int64(v.Total),
-		Missing: int64(v.Missing),
-	}
-	if v.Terms != nil {
-		for _, t := range v.Terms.Terms() {
-			f.Terms = append(f.Terms, &resourcepb.ResourceSearchResponse_TermFacet{
-				Term:  t.Term,
-				Count: int64(t.Count),
-			})
-		}
-	}
-	return f
-}
-
 type permissionScopedQuery struct {
 	query.Query
 	access    authlib.AccessClient
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+)
+
+const (
+	defaultHistogramBuckets = 10
+	maxFacetBuckets         = 1000
+)
+
+// newFacetRequest converts a facet from the search request.
+// Without an interval or ranges, this counts the top terms of the field.
+//
+// Date histograms bucket timestamp fields (in milliseconds, like created and updated) by interval,
+// the limit sets how many buckets are returned, ending with the one including now.
+// Numeric ranges bucket any numeric field between the requested boundaries.
+func newFacetRequest(f *resourcepb.ResourceSearchRequest_Facet, now time.Time) (*bleve.FacetRequest, error) {
+	if f.Interval != "" && len(f.Ranges) > 0 {
+		return nil, fmt.Errorf("facet %q can not have both an interval and ranges", f.Field)
+	}
+
+	switch {
+	case f.Interval != "":
+		interval, err := gtime.ParseDuration(f.Interval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid interval for facet %q: %s", f.Field, f.Interval)
+		}
+
+		buckets := int(f.Limit)
+		if buckets <= 0 {
+			buckets = defaultHistogramBuckets
+		}
+		buckets = min(buckets, maxFacetBuckets)
+
+		req := bleve.NewFacetRequest(f.Field, buckets)
+		end := now.UTC().Truncate(interval).Add(interval)
+		for i := buckets; i > 0; i-- {
+			start := end.Add(-time.Duration(i) * interval)
+			from := float64(start.UnixMilli())
+			to := float64(start.Add(interval).UnixMilli())
+			req.AddNumericRange(start.Format(time.RFC3339), &from, &to)
+		}
+		return req, nil
+
+	case len(f.Ranges) > 0:
+		if len(f.Ranges) >= maxFacetBuckets {
+			return nil, fmt.Errorf("too many ranges for facet %q (max %d)", f.Field, maxFacetBuckets-1)
+		}
+		if !slices.IsSorted(f.Ranges) {
+			return nil, fmt.Errorf("ranges for facet %q must be in ascending order", f.Field)
+		}
+
+		req := bleve.NewFacetRequest(f.Field, len(f.Ranges)+1)
+		for i := 0; i <= len(f.Ranges); i++ {
+			var from, to *float64
+			if i > 0 {
+				from = &f.Ranges[i-1]
+			}
+			if i < len(f.Ranges) {
+				to = &f.Ranges[i]
+			}
+			req.AddNumericRange(rangeName(from, to), from, to)
+		}
+		return req, nil
+
+	default:
+		return bleve.NewFacetRequest(f.Field, int(f.Limit)), nil
+	}
+}
+
+// rangeName formats the bucket boundaries, using * for open ends (eg, *-10, 10-20, 20-*)
+func rangeName(from, to *float64) string {
+	format := func(v *float64) string {
+		if v == nil {
+			return "*"
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	}
+	return format(from) + "-" + format(to)
+}
+
+// newResponseFacet converts the facet results.
+// Range buckets are returned as terms, in the requested order and including the empty ones.
+func newResponseFacet(v *search.FacetResult, req *bleve.FacetRequest) *resourcepb.ResourceSearchResponse_Facet {
+	f := &resourcepb.ResourceSearchResponse_Facet{
+		Field:   v.Field,
+		Total:   int64(v.Total),
+		Missing: int64(v.Missing),
+	}
+	if v.Terms != nil {
+		for _, t := range v.Terms.Terms() {
+			f.Terms = append(f.Terms, &resourcepb.ResourceSearchResponse_TermFacet{
+				Term:  t.Term,
+				Count: int64(t.Count),
+			})
+		}
+	}
+	if req != nil && len(req.NumericRanges) > 0 {
+		counts := make(map[string]int, len(v.NumericRanges))
+		for _, r := range v.NumericRanges {
+			counts[r.Name] = r.Count
+		}
+		for _, r := range req.NumericRanges {
+			f.Terms = append(f.Terms, &resourcepb.ResourceSearchResponse_TermFacet{
+				Term:  r.Name,
+				Count: int64(counts[r.Name]),
+			})
+		}
+	}
+	return f
+}
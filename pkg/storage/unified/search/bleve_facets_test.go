@@ -0,0 +1,168 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/search/builders"
+)
+
+func TestNewFacetRequest(t *testing.T) {
+	now := time.Date(2024, 10, 30, 18, 30, 54, 0, time.UTC)
+
+	t.Run("terms", func(t *testing.T) {
+		req, err := newFacetRequest(&resourcepb.ResourceSearchRequest_Facet{Field: "tags", Limit: 50}, now)
+		require.NoError(t, err)
+		require.Equal(t, "tags", req.Field)
+		require.Equal(t, 50, req.Size)
+		require.Empty(t, req.NumericRanges)
+	})
+
+	t.Run("date histogram", func(t *testing.T) {
+		req, err := newFacetRequest(&resourcepb.ResourceSearchRequest_Facet{Field: "updated", Limit: 3, Interval: "1d"}, now)
+		require.NoError(t, err)
+		require.Len(t, req.NumericRanges, 3)
+
+		names := []string{}
+		for _, r := range req.NumericRanges {
+			names = append(names, r.Name)
+			require.Equal(t, float64(24*time.Hour/time.Millisecond), *r.Max-*r.Min)
+		}
+		require.Equal(t, []string{"2024-10-28T00:00:00Z", "2024-10-29T00:00:00Z", "2024-10-30T00:00:00Z"}, names)
+		require.Equal(t, float64(time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC).UnixMilli()), *req.NumericRanges[2].Max)
+	})
+
+	t.Run("date histogram default buckets", func(t *testing.T) {
+		req, err := newFacetRequest(&resourcepb.ResourceSearchRequest_Facet{Field: "created", Interval: "1w"}, now)
+		require.NoError(t, err)
+		require.Len(t, req.NumericRanges, defaultHistogramBuckets)
+	})
+
+	t.Run("numeric ranges", func(t *testing.T) {
+		req, err := newFacetRequest(&resourcepb.ResourceSearchRequest_Facet{Field: "fields.panel_count", Ranges: []float64{5, 10.5}}, now)
+		require.NoError(t, err)
+		require.Len(t, req.NumericRanges, 3)
+		require.Equal(t, "*-5", req.NumericRanges[0].Name)
+		require.Nil(t, req.NumericRanges[0].Min)
+		require.Equal(t, "5-10.5", req.NumericRanges[1].Name)
+		require.Equal(t, "10.5-*", req.NumericRanges[2].Name)
+		require.Nil(t, req.NumericRanges[2].Max)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, f := range []*resourcepb.ResourceSearchRequest_Facet{
+			{Field: "updated", Interval: "soon"},
+			{Field: "updated", Interval: "-1d"},
+			{Field: "updated", Interval: "1d", Ranges: []float64{1}},
+			{Field: "fields.panel_count", Ranges: []float64{10, 5}},
+		} {
+			_, err := newFacetRequest(f, now)
+			require.Error(t, err, f.String())
+		}
+	})
+}
+
+func TestSearchBucketFacets(t *testing.T) {
+	ns := resource.NamespacedResource{
+		Namespace: "test",
+		Group:     "dashboard.grafana.app",
+		Resource:  "dashboards",
+	}
+
+	info, err := builders.DashboardBuilder(nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	docs := []struct {
+		name    string
+		updated time.Time
+		panels  int
+	}{
+		{name: "aaa", updated: now.Add(-time.Minute), panels: 1},
+		{name: "bbb", updated: now.Add(-2 * time.Minute), panels: 5},
+		{name: "ccc", updated: now.Add(-365 * 24 * time.Hour), panels: 20},
+	}
+
+	be, _ := setupBleveBackend(t, withFileThreshold(100))
+	index, err := be.BuildIndex(t.Context(), ns, int64(len(docs)), info.Fields, "test", func(index resource.ResourceIndex) (int64, error) {
+		items := []*resource.BulkIndexItem{}
+		for _, d := range docs {
+			items = append(items, &resource.BulkIndexItem{
+				Action: resource.ActionIndex,
+				Doc: &resource.IndexableDocument{
+					Key:     &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource, Name: d.name},
+					Title:   d.name,
+					Updated: d.updated.UnixMilli(),
+					Fields: map[string]any{
+						builders.DASHBOARD_PANEL_COUNT: d.panels,
+					},
+				},
+			})
+		}
+		return 1, index.BulkIndex(&resource.BulkIndexRequest{Items: items})
+	}, nil, false, time.Time{})
+	require.NoError(t, err)
+
+	resp, err := index.Search(t.Context(), nil, &resourcepb.ResourceSearchRequest{
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource},
+		},
+		Limit: 10,
+		Facet: map[string]*resourcepb.ResourceSearchRequest_Facet{
+			"updated": {
+				Field:    resource.SEARCH_FIELD_UPDATED,
+				Limit:    4,
+				Interval: "1w",
+			},
+			"panel_count": {
+				Field:  resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_PANEL_COUNT,
+				Ranges: []float64{2, 10},
+			},
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+
+	// Only the last 4 weeks are counted, oldest first
+	updated := resp.Facet["updated"]
+	require.NotNil(t, updated)
+	require.Len(t, updated.Terms, 4)
+	total := int64(0)
+	for i, term := range updated.Terms {
+		if i > 0 {
+			require.Less(t, updated.Terms[i-1].Term, term.Term)
+		}
+		total += term.Count
+	}
+	require.Equal(t, int64(2), total)
+
+	panels := resp.Facet["panel_count"]
+	require.NotNil(t, panels)
+	counts := map[string]int64{}
+	for _, term := range panels.Terms {
+		counts[term.Term] = term.Count
+	}
+	require.Equal(t, map[string]int64{"*-2": 1, "2-10": 1, "10-*": 1}, counts)
+
+	// Empty buckets are included
+	resp, err = index.Search(t.Context(), nil, &resourcepb.ResourceSearchRequest{
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource},
+		},
+		Limit: 10,
+		Facet: map[string]*resourcepb.ResourceSearchRequest_Facet{
+			"panel_count": {
+				Field:  resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_PANEL_COUNT,
+				Ranges: []float64{100, 200},
+			},
+		},
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Facet["panel_count"].Terms, 3)
+	require.Equal(t, int64(3), resp.Facet["panel_count"].Terms[0].Count)
+	require.Equal(t, int64(0), resp.Facet["panel_count"].Terms[1].Count)
+}
@@ -13,6 +13,12 @@ import (
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 )
 
+// indexMappingVersion is recorded in the build info of the indexes. It must be bumped whenever the mappings
+// or the fields of the documents change, so that the file-based indexes built before are rebuilt instead of reused.
+//
+// Version 1 added the panel descriptions, queries and datasources of the dashboards.
+const indexMappingVersion = 1
+
 func GetBleveMappings(fields resource.SearchableDocumentFields, selectableFields []string) (mapping.IndexMapping, error) {
 	mapper := bleve.NewIndexMapping()
 	mapper.ScoringModel = index.BM25Scoring
@@ -182,6 +188,16 @@ func getBleveDocMappings(fields resource.SearchableDocumentFields, selectableFie
 
 				fieldMapper.AddFieldMappingsAt(def.Name, keywordMapping)
 			}
+
+			// Free text should be stored with term vectors, so matches can be highlighted
+			if def.Properties != nil && def.Properties.FreeText && def.Type == resourcepb.ResourceTableColumnDefinition_STRING {
+				textMapping := bleve.NewTextFieldMapping()
+				textMapping.Analyzer = standard.Name
+				textMapping.Store = true
+				textMapping.IncludeTermVectors = true
+
+				fieldMapper.AddFieldMappingsAt(def.Name, textMapping)
+			}
 			// For all other fields, we do nothing.
 			// Bleve will see them at index time and dynamically map them as
 			// numeric, datetime, boolean, or standard text based on their content.
@@ -1615,9 +1615,43 @@ func TestIndexBuildInfo(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, buildInfo)
 	require.Equal(t, buildVersion, buildInfo.BuildVersion)
+	require.Equal(t, indexMappingVersion, buildInfo.MappingVersion)
 	require.InDelta(t, float64(time.Now().Unix()), buildInfo.BuildTime, 30) // allow 30 seconds of drift
 }
 
+func TestBuildIndexRebuildsWhenMappingsChange(t *testing.T) {
+	ns := resource.NamespacedResource{
+		Namespace: "test",
+		Group:     "group",
+		Resource:  "resource",
+	}
+	tmpDir := t.TempDir()
+
+	{
+		backend, _ := setupBleveBackend(t, withFileThreshold(5), withRootDir(tmpDir))
+		idx, err := backend.BuildIndex(t.Context(), ns, 10, nil, "test", indexTestDocs(ns, 10, 100), nil, false, time.Time{})
+		require.NoError(t, err)
+
+		// Simulate an index built by a version with older mappings
+		bi, err := json.Marshal(buildInfo{BuildTime: time.Now().Unix(), BuildVersion: buildVersion, MappingVersion: indexMappingVersion - 1})
+		require.NoError(t, err)
+		require.NoError(t, idx.(*bleveIndex).index.SetInternal([]byte(internalBuildInfoKey), bi))
+		backend.Stop()
+	}
+
+	newBackend, _ := setupBleveBackend(t, withFileThreshold(5), withRootDir(tmpDir))
+	idx, err := newBackend.BuildIndex(t.Context(), ns, 1000, nil, "test", indexTestDocs(ns, 1000, 100), nil, false, time.Time{})
+	require.NoError(t, err)
+
+	cnt, err := idx.DocCount(t.Context(), "", nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), cnt, "Index has not been rebuilt")
+
+	bi, err := getBuildInfo(idx.(*bleveIndex).index)
+	require.NoError(t, err)
+	require.Equal(t, indexMappingVersion, bi.MappingVersion)
+}
+
 func TestInvalidBuildVersion(t *testing.T) {
 	opts := BleveOptions{
 		Root:         t.TempDir(),
@@ -1627,6 +1661,74 @@ func TestInvalidBuildVersion(t *testing.T) {
 	require.ErrorContains(t, err, "cannot parse build version")
 }
 
+func TestSearchPanelQueriesWithHighlight(t *testing.T) {
+	ns := resource.NamespacedResource{
+		Namespace: "test",
+		Group:     "dashboard.grafana.app",
+		Resource:  "dashboards",
+	}
+
+	info, err := builders.DashboardBuilder(nil)
+	require.NoError(t, err)
+
+	be, _ := setupBleveBackend(t, withFileThreshold(100))
+	index, err := be.BuildIndex(t.Context(), ns, 2, info.Fields, "test", func(index resource.ResourceIndex) (int64, error) {
+		err := index.BulkIndex(&resource.BulkIndexRequest{Items: []*resource.BulkIndexItem{
+			{
+				Action: resource.ActionIndex,
+				Doc: &resource.IndexableDocument{
+					Key:   &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource, Name: "aaa"},
+					Title: "API",
+					Fields: map[string]any{
+						builders.DASHBOARD_PANEL_QUERY: []string{"sum(rate(http_requests_total[5m]))"},
+					},
+				},
+			},
+			{
+				Action: resource.ActionIndex,
+				Doc: &resource.IndexableDocument{
+					Key:   &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource, Name: "bbb"},
+					Title: "Database",
+					Fields: map[string]any{
+						builders.DASHBOARD_PANEL_QUERY: []string{"SELECT count(*) FROM sessions"},
+					},
+				},
+			},
+		}})
+		return 1, err
+	}, nil, false, time.Time{})
+	require.NoError(t, err)
+
+	resp, err := index.Search(t.Context(), nil, &resourcepb.ResourceSearchRequest{
+		Options: &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{Namespace: ns.Namespace, Group: ns.Group, Resource: ns.Resource},
+		},
+		Fields: []string{resource.SEARCH_FIELD_TITLE, resource.SEARCH_FIELD_HIGHLIGHT},
+		Query:  "http_requests_total",
+		QueryFields: []*resourcepb.ResourceSearchRequest_QueryField{
+			{Name: resource.SEARCH_FIELD_PREFIX + builders.DASHBOARD_PANEL_QUERY, Type: resourcepb.QueryFieldType_TEXT},
+		},
+		Limit: 10,
+	}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	require.Equal(t, int64(1), resp.TotalHits)
+	require.Equal(t, "aaa", resp.Results.Rows[0].Key.Name)
+
+	highlightIDX := -1
+	for i, col := range resp.Results.Columns {
+		if col.Name == resource.SEARCH_FIELD_HIGHLIGHT {
+			highlightIDX = i
+		}
+	}
+	require.GreaterOrEqual(t, highlightIDX, 0)
+
+	fragments := map[string][]string{}
+	require.NoError(t, json.Unmarshal(resp.Results.Rows[0].Cells[highlightIDX], &fragments))
+	require.Len(t, fragments[builders.DASHBOARD_PANEL_QUERY], 1)
+	require.Contains(t, fragments[builders.DASHBOARD_PANEL_QUERY][0], "<mark>http_requests_total</mark>")
+}
+
 func searchTitle(t *testing.T, idx resource.ResourceIndex, query string, limit int, ns resource.NamespacedResource) *resourcepb.ResourceSearchResponse {
 	resp, err := idx.Search(t.Context(), nil, &resourcepb.ResourceSearchRequest{
 		Options: &resourcepb.ListOptions{
@@ -19,8 +19,12 @@ import (
 const DASHBOARD_SCHEMA_VERSION = "schema_version"
 const DASHBOARD_LINK_COUNT = "link_count"
 const DASHBOARD_PANEL_TYPES = "panel_types"
+const DASHBOARD_PANEL_COUNT = "panel_count"
 const DASHBOARD_PANEL_TITLE = "panel_title"
+const DASHBOARD_PANEL_DESCRIPTION = "panel_description"
+const DASHBOARD_PANEL_QUERY = "panel_query"
 const DASHBOARD_DS_TYPES = "ds_types"
+const DASHBOARD_DS_UIDS = "ds_uids"
 const DASHBOARD_TRANSFORMATIONS = "transformation"
 const DASHBOARD_LIBRARY_PANEL_REFERENCE = "reference.LibraryPanel"
 
@@ -65,6 +69,35 @@ func DashboardBuilder(namespaced resource.NamespacedDocumentSupplier) (resource.
 				FreeText:   true,
 			},
 		},
+		{
+			Name:        DASHBOARD_PANEL_DESCRIPTION,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "The panel description text",
+			Properties: &resourcepb.ResourceTableColumnDefinition_Properties{
+				Filterable: false, // full text
+				FreeText:   true,
+			},
+		},
+		{
+			Name:        DASHBOARD_PANEL_QUERY,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "The panel query text, extracted based on the datasource type",
+			Properties: &resourcepb.ResourceTableColumnDefinition_Properties{
+				Filterable: false, // full text
+				FreeText:   true,
+			},
+		},
+		{
+			Name:        DASHBOARD_DS_UIDS,
+			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
+			IsArray:     true,
+			Description: "The datasources used in this dashboard",
+			Properties: &resourcepb.ResourceTableColumnDefinition_Properties{
+				Filterable: true,
+			},
+		},
 		{
 			Name:        DASHBOARD_PANEL_TYPES,
 			Type:        resourcepb.ResourceTableColumnDefinition_STRING,
@@ -74,6 +107,11 @@ func DashboardBuilder(namespaced resource.NamespacedDocumentSupplier) (resource.
 				Filterable: true,
 			},
 		},
+		{
+			Name:        DASHBOARD_PANEL_COUNT,
+			Type:        resourcepb.ResourceTableColumnDefinition_INT32,
+			Description: "How many panels appear on the dashboard (rows are not counted)",
+		},
 		{
 			Name:        DASHBOARD_ERRORS_TODAY,
 			Type:        resourcepb.ResourceTableColumnDefinition_INT64,
@@ -283,9 +321,13 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 	doc.Tags = summary.Tags
 
 	panelTitles := []string{}
+	panelDescriptions := []string{}
+	panelQueries := []string{}
 	panelTypes := []string{}
 	transformations := []string{}
 	dsTypes := []string{}
+	dsUIDs := []string{}
+	panelCount := 0
 
 	for p := range summary.PanelIterator() {
 		switch p.Type {
@@ -294,10 +336,19 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 		default:
 			panelTypes = append(panelTypes, p.Type)
 		}
+		if p.Type != "row" {
+			panelCount++
+		}
 
 		if len(p.Title) > 0 {
 			panelTitles = append(panelTitles, p.Title)
 		}
+		if len(p.Description) > 0 {
+			panelDescriptions = append(panelDescriptions, p.Description)
+		}
+		if len(p.Queries) > 0 {
+			panelQueries = append(panelQueries, p.Queries...)
+		}
 		if len(p.Transformer) > 0 {
 			transformations = append(transformations, p.Transformer...)
 		}
@@ -313,6 +364,7 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 
 	for _, ds := range summary.Datasource {
 		dsTypes = append(dsTypes, ds.Type)
+		dsUIDs = append(dsUIDs, ds.UID)
 		doc.References = append(doc.References, resource.ResourceReference{
 			Group:    ds.Type,
 			Kind:     "DataSource",
@@ -327,12 +379,19 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 	doc.Fields = map[string]any{
 		DASHBOARD_SCHEMA_VERSION:        summary.SchemaVersion,
 		DASHBOARD_LINK_COUNT:            summary.LinkCount,
+		DASHBOARD_PANEL_COUNT:           panelCount,
 		resource.SEARCH_FIELD_LEGACY_ID: summary.ID,
 	}
 
 	if len(panelTitles) > 0 {
 		doc.Fields[DASHBOARD_PANEL_TITLE] = panelTitles
 	}
+	if len(panelDescriptions) > 0 {
+		doc.Fields[DASHBOARD_PANEL_DESCRIPTION] = panelDescriptions
+	}
+	if len(panelQueries) > 0 {
+		doc.Fields[DASHBOARD_PANEL_QUERY] = panelQueries
+	}
 	if len(panelTypes) > 0 {
 		sort.Strings(panelTypes)
 		doc.Fields[DASHBOARD_PANEL_TYPES] = slices.Compact(panelTypes) // distinct values
@@ -341,6 +400,10 @@ func (s *DashboardDocumentBuilder) BuildDocument(ctx context.Context, key *resou
 		sort.Strings(dsTypes)
 		doc.Fields[DASHBOARD_DS_TYPES] = slices.Compact(dsTypes) // distinct values
 	}
+	if len(dsUIDs) > 0 {
+		sort.Strings(dsUIDs)
+		doc.Fields[DASHBOARD_DS_UIDS] = slices.Compact(dsUIDs) // distinct values
+	}
 	if len(transformations) > 0 {
 		sort.Strings(transformations)
 		doc.Fields[DASHBOARD_TRANSFORMATIONS] = slices.Compact(transformations) // distinct values
@@ -358,7 +421,9 @@ func DashboardFields() []string {
 		DASHBOARD_SCHEMA_VERSION,
 		DASHBOARD_LINK_COUNT,
 		DASHBOARD_PANEL_TYPES,
+		DASHBOARD_PANEL_COUNT,
 		DASHBOARD_DS_TYPES,
+		DASHBOARD_DS_UIDS,
 		DASHBOARD_TRANSFORMATIONS,
 	}
 
@@ -127,6 +127,7 @@ func TestDashboardDocumentBuilder(t *testing.T) {
 
 	doSnapshotTests(t, builder, "dashboard", key, []string{
 		"aaa",
+		"bbb",
 	})
 
 	builder = resource.StandardDocumentBuilder(nil)
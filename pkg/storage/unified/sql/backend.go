@@ -12,7 +12,6 @@ import (
 	"sync"
 	"time"
 
-	badger "github.com/dgraph-io/badger/v4"
 	"github.com/go-sql-driver/mysql"
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/grafana/pkg/services/apiserver/options"
@@ -30,7 +29,10 @@ import (
 	"github.com/grafana/grafana-app-sdk/logging"
 	infraDB "github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/parquet"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
 	"github.com/grafana/grafana/pkg/storage/unified/resource/kv"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
@@ -101,6 +103,14 @@ func NewStorageBackend(
 		cfg.SectionWithEnvOverrides("resource_api"))
 
 	if !cfg.EnableSQLKVBackend {
+		historyArchive, err := historyArchiveConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if db != nil {
+			// Without the grafana database, the archive can only run on a single instance
+			historyArchive.Lock = serverlock.ProvideService(db, serverLockTracer(tracer))
+		}
 		return NewBackend(BackendOptions{
 			DBProvider:           eDB,
 			Reg:                  reg,
@@ -114,6 +124,7 @@ func NewStorageBackend(
 				MaxAge:           cfg.GarbageCollectionMaxAge,
 				DashboardsMaxAge: cfg.DashboardsGarbageCollectionMaxAge,
 			},
+			HistoryArchive:          historyArchive,
 			SimulatedNetworkLatency: cfg.SimulatedNetworkLatency,
 			MigrationParquetBuffer:  cfg.MigrationParquetBuffer,
 			DisableStorageServices:  disableStorageServices,
@@ -171,21 +182,82 @@ func NewStorageBackend(
 	return resource.NewKVStorageBackend(kvBackendOpts)
 }
 
-func NewFileBackend(cfg *setting.Cfg) (resource.StorageBackend, error) {
+// historyArchiveConfig opens the bucket of the history archive, when configured
+func historyArchiveConfig(cfg *setting.Cfg) (HistoryArchiveConfig, error) {
+	archive := HistoryArchiveConfig{
+		Prefix:    cfg.HistoryArchivePrefix,
+		Enabled:   cfg.HistoryArchiveEnabled,
+		Interval:  cfg.HistoryArchiveInterval,
+		BatchSize: cfg.HistoryArchiveBatchSize,
+		MaxAge:    cfg.HistoryArchiveMaxAge,
+	}
+	if cfg.HistoryArchiveBucketURL == "" {
+		if archive.Enabled {
+			return archive, fmt.Errorf("history_archive_enabled requires history_archive_bucket_url")
+		}
+		return archive, nil
+	}
+
+	bucket, err := resource.OpenBlobBucket(context.Background(), cfg.HistoryArchiveBucketURL)
+	if err != nil {
+		return archive, fmt.Errorf("failed to open history archive bucket: %w", err)
+	}
+	archive.Bucket = bucket
+	return archive, nil
+}
+
+// serverLockTracer returns the tracer of the server lock, which requires the tracing service
+func serverLockTracer(tracer trace.Tracer) tracing.Tracer {
+	if t, ok := tracer.(tracing.Tracer); ok {
+		return t
+	}
+	return tracing.NewNoopTracerService()
+}
+
+// FileStorageOptions returns the options of the embedded database used by the file storage type.
+// It is stored in [grafana-apiserver] storage_path (default: <data>/grafana-apiserver) + /badger
+func FileStorageOptions(cfg *setting.Cfg) kv.BadgerOptions {
 	apiserverCfg := cfg.SectionWithEnvOverrides("grafana-apiserver")
 	dataPath := apiserverCfg.Key("storage_path").
 		MustString(filepath.Join(cfg.DataPath, "grafana-apiserver"))
-	db, err := badger.Open(badger.DefaultOptions(filepath.Join(dataPath, "badger")).
-		WithLogger(nil))
+	return kv.BadgerOptions{
+		Path:               filepath.Join(dataPath, "badger"),
+		ValueLogGCInterval: apiserverCfg.Key("storage_value_log_gc_interval").MustDuration(0),
+	}
+}
+
+// NewFileBackend creates a KV backend over the embedded database, for single node deployments without a SQL server.
+// Like the SQL KV backend, it runs the garbage collection and event pruning configured in [unified_storage].
+func NewFileBackend(cfg *setting.Cfg) (resource.StorageBackend, error) {
+	store, err := kv.OpenBadger(FileStorageOptions(cfg))
 	if err != nil {
 		return nil, err
 	}
 
-	kvStore := resource.NewBadgerKV(db)
-	return resource.NewKVStorageBackend(resource.KVBackendOptions{
-		KvStore: kvStore,
-		Log:     log.New("storage-backend"),
+	backend, err := resource.NewKVStorageBackend(resource.KVBackendOptions{
+		KvStore:              store,
+		Tracer:               tracer,
+		UseChannelNotifier:   true, // the embedded database can only be opened by a single process
+		Log:                  log.New("storage-backend"),
+		DBKeepAlive:          store,
+		LastImportTimeMaxAge: cfg.MaxFileIndexAge,
+		GarbageCollection: resource.GarbageCollectionConfig{
+			Enabled:          cfg.EnableGarbageCollection,
+			DryRun:           cfg.GarbageCollectionDryRun,
+			Interval:         cfg.GarbageCollectionInterval,
+			BatchSize:        cfg.GarbageCollectionBatchSize,
+			MaxAge:           cfg.GarbageCollectionMaxAge,
+			DashboardsMaxAge: cfg.DashboardsGarbageCollectionMaxAge,
+		},
+		EventRetentionPeriod: cfg.EventRetentionPeriod,
+		EventPruningInterval: cfg.EventPruningInterval,
 	})
+	if err != nil {
+		// the embedded database stays locked until it is closed
+		_ = store.Close()
+		return nil, err
+	}
+	return backend, nil
 }
 
 type BackendOptions struct {
@@ -207,6 +279,9 @@ type BackendOptions struct {
 
 	// If not zero, the backend will regularly remove times from resource_last_import_time table older than this.
 	LastImportTimeMaxAge time.Duration
+
+	// Moves the old history to parquet files in object storage
+	HistoryArchive HistoryArchiveConfig
 }
 
 func NewBackend(opts BackendOptions) (Backend, error) {
@@ -237,6 +312,10 @@ func NewBackend(opts BackendOptions) (Backend, error) {
 		migrationParquetBuffer:  opts.MigrationParquetBuffer,
 		lastImportTimeMaxAge:    opts.LastImportTimeMaxAge,
 		garbageCollection:       opts.GarbageCollection,
+		historyArchive:          opts.HistoryArchive,
+	}
+	if opts.HistoryArchive.Bucket != nil {
+		backend.archive = parquet.NewHistoryArchive(opts.HistoryArchive.Bucket, opts.HistoryArchive.Prefix)
 	}
 	if err := backend.Init(ctx); err != nil {
 		return nil, err
@@ -290,6 +369,10 @@ type backend struct {
 
 	garbageCollection GarbageCollectionConfig
 
+	// archived history, nil when no archive is configured
+	historyArchive HistoryArchiveConfig
+	archive        *parquet.HistoryArchive
+
 	// When true, bulk migrations buffer data through a temporary Parquet file
 	migrationParquetBuffer bool
 
@@ -363,6 +446,11 @@ func (b *backend) initLocked(ctx context.Context) error {
 			return fmt.Errorf("failed to initialize garbage collection: %w", err)
 		}
 	}
+	if b.historyArchive.Enabled {
+		if err := b.initHistoryArchive(ctx); err != nil {
+			return fmt.Errorf("failed to initialize history archive: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -519,10 +607,11 @@ func (b *backend) garbageCollectBatch(ctx context.Context, group, resourceName s
 	span.SetAttributes(attribute.String("group", group), attribute.String("resource", resourceName), attribute.Int64("cutoffTimestamp", cutoffTimestamp), attribute.Int("batchSize", batchSize))
 	defer span.End()
 
-	var rowsAffected int64
-	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+	var candidates []gcCandidateName
+	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
 		// query will return at most batchSize candidates
-		candidates, err := dbutil.Query(ctx, tx, sqlResourceHistoryGarbageGetCandidates, &sqlGarbageCollectCandidatesRequest{
+		var err error
+		candidates, err = dbutil.Query(ctx, tx, sqlResourceHistoryGarbageGetCandidates, &sqlGarbageCollectCandidatesRequest{
 			SQLTemplate:     sqltemplate.New(b.dialect),
 			Group:           group,
 			Resource:        resourceName,
@@ -530,13 +619,24 @@ func (b *backend) garbageCollectBatch(ctx context.Context, group, resourceName s
 			BatchSize:       batchSize,
 			Response:        new(gcCandidateName),
 		})
-		if err != nil {
-			return err
-		}
+		return err
+	})
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+	span.AddEvent("candidates", trace.WithAttributes(attribute.Int("candidates", len(candidates))))
+
+	// The older versions of the collected resources may have been archived, they are removed first
+	// so a resource created later with the same name does not get them back in its history
+	if b.archive != nil {
+		candidates = b.clearArchivedResources(ctx, group, resourceName, candidates)
 		if len(candidates) == 0 {
-			return nil
+			return 0, nil
 		}
-		span.AddEvent("candidates", trace.WithAttributes(attribute.Int("candidates", len(candidates))))
+	}
+
+	var rowsAffected int64
+	err = b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
 		res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryGCDeleteByNames, &sqlGarbageCollectDeleteByNamesRequest{
 			SQLTemplate: sqltemplate.New(b.dialect),
 			Group:       group,
@@ -1054,6 +1154,8 @@ func (b *backend) listAtRevision(ctx context.Context, req *resourcepb.ListReques
 	if iter.listRV < 1 {
 		return 0, apierrors.NewBadRequest("expecting an explicit resource version query")
 	}
+	// The versions older than the archive cutoff may have been moved out of the resource_history table
+	readArchive := b.archive != nil && iter.listRV < time.Now().Add(-b.historyArchive.MaxAge).UnixMicro()
 
 	// The query below has the potential to be EXTREMELY slow if the resource_history table is big. May be helpful to know
 	// which stack is calling this.
@@ -1061,7 +1163,7 @@ func (b *backend) listAtRevision(ctx context.Context, req *resourcepb.ListReques
 
 	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
 		limit := int64(0) // ignore limit
-		if iter.offset > 0 {
+		if iter.offset > 0 && !readArchive {
 			limit = math.MaxInt64 // a limit is required for offset
 		}
 		listReq := sqlResourceHistoryListRequest{
@@ -1073,6 +1175,10 @@ func (b *backend) listAtRevision(ctx context.Context, req *resourcepb.ListReques
 				Options:         req.Options,
 			},
 		}
+		if readArchive {
+			// the offset is applied to the versions from both tiers
+			listReq.Request.Offset = 0
+		}
 
 		rows, err := dbutil.QueryRows(ctx, tx, sqlResourceHistoryList, listReq)
 		if rows != nil {
@@ -1087,6 +1193,13 @@ func (b *backend) listAtRevision(ctx context.Context, req *resourcepb.ListReques
 		}
 
 		iter.rows = rows
+		if readArchive {
+			archived, err := b.listArchivedRevision(ctx, tx, req.Options.Key, iter)
+			if err != nil {
+				return err
+			}
+			return cb(archived)
+		}
 		return cb(iter)
 	})
 	return iter.listRV, err
@@ -1094,7 +1207,7 @@ func (b *backend) listAtRevision(ctx context.Context, req *resourcepb.ListReques
 
 // readHistory fetches the resource history from the resource_history table.
 func (b *backend) readHistory(ctx context.Context, key *resourcepb.ResourceKey, rv int64) *resource.BackendReadResponse {
-	_, span := tracer.Start(ctx, "sql.backend.readHistory")
+	ctx, span := tracer.Start(ctx, "sql.backend.readHistory")
 	defer span.End()
 
 	readReq := &sqlResourceHistoryReadRequest{
@@ -1114,6 +1227,10 @@ func (b *backend) readHistory(ctx context.Context, key *resourcepb.ResourceKey,
 	})
 
 	if errors.Is(err, sql.ErrNoRows) {
+		// the latest version of a resource is never archived, older ones may be
+		if b.archive != nil {
+			return b.readArchivedVersion(ctx, key, rv)
+		}
 		return &resource.BackendReadResponse{Error: resource.NewNotFoundError(key)}
 	}
 	if err != nil {
@@ -1167,6 +1284,17 @@ func (b *backend) getHistory(ctx context.Context, req *resourcepb.ListRequest, c
 	// Ignore last deleted history record when listing the trash, using exact matching or not older than matching with a specific RV
 	useLatestDeletionAsMinRV := listReq.MinRV == 0 && !listReq.Trash && req.VersionMatchV2 != resourcepb.ResourceVersionMatchV2_Exact
 
+	// The trash only lists the latest versions, which are never archived
+	readArchive := b.archive != nil && !listReq.Trash
+	archivedDeletedRV := int64(0)
+	if readArchive && useLatestDeletionAsMinRV && req.Options.Key.Name != "" {
+		var err error
+		archivedDeletedRV, err = b.archive.LatestDeletion(ctx, req.Options.Key)
+		if err != nil {
+			return 0, fmt.Errorf("read history archive: %w", err)
+		}
+	}
+
 	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
 		var err error
 		iter.listRV, err = b.fetchLatestRV(ctx, tx, b.dialect, req.Options.Key.Group, req.Options.Key.Resource)
@@ -1179,7 +1307,7 @@ func (b *backend) getHistory(ctx context.Context, req *resourcepb.ListRequest, c
 			if err != nil {
 				return err
 			}
-			listReq.MinRV = latestDeletedRV + 1
+			listReq.MinRV = max(latestDeletedRV, archivedDeletedRV) + 1
 		}
 
 		var rows db.Rows
@@ -1202,6 +1330,13 @@ func (b *backend) getHistory(ctx context.Context, req *resourcepb.ListRequest, c
 		}
 
 		iter.rows = rows
+		if readArchive {
+			archived, err := b.readArchivedHistory(ctx, &listReq)
+			if err != nil {
+				return err
+			}
+			return cb(newHistoryIter(iter, archived))
+		}
 		return cb(iter)
 	})
 	return iter.listRV, err
@@ -0,0 +1,596 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/storage/unified/parquet"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/dbutil"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/sqltemplate"
+)
+
+const historyArchiveLockName = "unified-storage-history-archive"
+
+// errHistoryArchiveConflict is returned when versions of a batch were removed from the table while it was archived
+var errHistoryArchiveConflict = errors.New("history archive conflict: versions were removed while they were archived")
+
+// HistoryArchiveConfig configures the tiering of the resource_history table to parquet files in object storage.
+// The versions older than MaxAge are moved to the archive, except the latest version of every resource,
+// and the history is read from both tiers.
+type HistoryArchiveConfig struct {
+	// The archived history is read whenever a bucket is set, even when archiving is disabled
+	Bucket resource.CDKBucket
+	Prefix string
+
+	Enabled   bool
+	Interval  time.Duration // how often the process runs
+	BatchSize int           // max number of versions archived in a transaction
+	MaxAge    time.Duration // versions older than this are archived
+
+	// Lock runs the archive on a single instance at a time. It is required in high availability setups.
+	Lock ServerLock
+}
+
+// ServerLock runs a function on a single instance at a time, see serverlock.ServerLockService
+type ServerLock interface {
+	LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+func (b *backend) initHistoryArchive(ctx context.Context) error {
+	if b.archive == nil {
+		return fmt.Errorf("history archive is enabled without a bucket")
+	}
+	if b.historyArchive.Lock == nil && b.isHA {
+		// the archive files are named by the resource versions of their batch, concurrent runs would write overlapping files
+		return fmt.Errorf("history archive requires a server lock in high availability setups")
+	}
+	b.log.Info("starting history archive loop")
+
+	go func() {
+		// delay the first run by a random amount between 0 and the interval to avoid thundering herd
+		if b.historyArchive.Interval > 0 {
+			jitter := time.Duration(rand.Int63n(b.historyArchive.Interval.Nanoseconds()))
+			select {
+			case <-b.done:
+				return
+			case <-time.After(jitter):
+			}
+		}
+
+		ticker := time.NewTicker(b.historyArchive.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.done:
+				return
+			case <-ticker.C:
+				b.runLockedHistoryArchive(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// runLockedHistoryArchive runs the history archive while holding the server lock, skipping the run when another
+// instance holds it. The lock expires after an interval, so the run stops archiving new batches after half of it.
+func (b *backend) runLockedHistoryArchive(ctx context.Context) {
+	run := func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, b.historyArchive.Interval/2)
+		defer cancel()
+		_ = b.runHistoryArchive(ctx, time.Now().Add(-b.historyArchive.MaxAge).UnixMicro())
+	}
+	if b.historyArchive.Lock == nil {
+		run(ctx)
+		return
+	}
+
+	err := b.historyArchive.Lock.LockExecuteAndRelease(ctx, historyArchiveLockName, b.historyArchive.Interval, run)
+	var lockExists *serverlock.ServerLockExistsError
+	switch {
+	case errors.As(err, &lockExists):
+		b.log.Debug("history archive is run by another instance")
+	case err != nil:
+		b.log.Error("failed to lock the history archive", "error", err)
+	}
+}
+
+// runHistoryArchive moves the history older than the cutoff to the archive, and returns the number of
+// archived versions by group/resource. It stops once the context is done, a started batch is always completed
+// so that its files and rows stay consistent.
+func (b *backend) runHistoryArchive(ctx context.Context, cutoffTimestamp int64) map[string]int64 {
+	ctx, span := tracer.Start(ctx, "sql.backend.runHistoryArchive")
+	defer span.End()
+	start := time.Now()
+
+	archivedByKey := map[string]int64{}
+
+	groupResources, err := b.listLatestRVs(ctx)
+	if err != nil {
+		b.log.Error("failed to list group resources for history archive", "error", err)
+		return archivedByKey
+	}
+
+	for group, resources := range groupResources {
+		for resourceName := range resources {
+			totalArchived := int64(0)
+			for ctx.Err() == nil {
+				archived, err := b.archiveHistoryBatch(context.WithoutCancel(ctx), group, resourceName, cutoffTimestamp, b.historyArchive.BatchSize)
+				if err != nil {
+					b.log.Error("history archive failed",
+						"group", group,
+						"resource", resourceName,
+						"error", err)
+					break
+				}
+				totalArchived += archived
+				if archived < int64(b.historyArchive.BatchSize) {
+					break
+				}
+				select {
+				case <-b.done:
+					return archivedByKey
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+			if totalArchived > 0 {
+				b.log.Info("history archive moved history",
+					"group", group,
+					"resource", resourceName,
+					"rows", totalArchived,
+					"seconds", time.Since(start).Seconds(),
+				)
+				archivedByKey[group+"/"+resourceName] += totalArchived
+			}
+		}
+	}
+
+	return archivedByKey
+}
+
+// archiveHistoryBatch writes a batch of old versions to the archive, one file per resource,
+// and removes them from the resource_history table.
+func (b *backend) archiveHistoryBatch(ctx context.Context, group, resourceName string, cutoffTimestamp int64, batchSize int) (int64, error) {
+	ctx, span := tracer.Start(ctx, "sql.backend.archiveHistoryBatch")
+	span.SetAttributes(attribute.String("group", group), attribute.String("resource", resourceName), attribute.Int64("cutoffTimestamp", cutoffTimestamp), attribute.Int("batchSize", batchSize))
+	defer span.End()
+
+	candidates, err := b.queryArchiveCandidates(ctx, group, resourceName, cutoffTimestamp, batchSize)
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+	span.AddEvent("candidates", trace.WithAttributes(attribute.Int("candidates", len(candidates))))
+
+	rowsAffected, err := b.archiveCandidates(ctx, group, resourceName, candidates)
+	if err != nil {
+		return 0, err
+	}
+	span.AddEvent("rows archived", trace.WithAttributes(attribute.Int64("rowsArchived", rowsAffected)))
+	return rowsAffected, nil
+}
+
+// queryArchiveCandidates returns at most batchSize versions older than the cutoff, sorted by resource
+func (b *backend) queryArchiveCandidates(ctx context.Context, group, resourceName string, cutoffTimestamp int64, batchSize int) ([]archiveCandidate, error) {
+	var candidates []archiveCandidate
+	err := b.db.WithTx(ctx, ReadCommittedRO, func(ctx context.Context, tx db.Tx) error {
+		var err error
+		candidates, err = dbutil.Query(ctx, tx, sqlResourceHistoryArchiveCandidates, &sqlHistoryArchiveCandidatesRequest{
+			SQLTemplate:     sqltemplate.New(b.dialect),
+			Group:           group,
+			Resource:        resourceName,
+			CutoffTimestamp: cutoffTimestamp,
+			BatchSize:       batchSize,
+			Response:        new(archiveCandidate),
+		})
+		return err
+	})
+	return candidates, err
+}
+
+// archiveCandidates writes the versions to the archive, then removes them from the resource_history table.
+// The files are written before the transaction deleting the rows, so it is not held open while writing to the bucket.
+// When some of the versions were already removed, the transaction is rolled back and the files of this batch are
+// removed: the versions are archived again by the next run, and the removed ones are not archived twice.
+func (b *backend) archiveCandidates(ctx context.Context, group, resourceName string, candidates []archiveCandidate) (int64, error) {
+	guids := make([]string, 0, len(candidates))
+	var written []string
+	var records []parquet.HistoryRecord
+	for i, c := range candidates {
+		guids = append(guids, c.GUID)
+		records = append(records, parquet.HistoryRecord{
+			Key: &resourcepb.ResourceKey{
+				Namespace: c.Namespace,
+				Group:     group,
+				Resource:  resourceName,
+				Name:      c.Name,
+			},
+			ResourceVersion: c.ResourceVersion,
+			Action:          resourcepb.WatchEvent_Type(c.Action),
+			Folder:          c.Folder,
+			Value:           c.Value,
+		})
+
+		if i == len(candidates)-1 || candidates[i+1].Namespace != c.Namespace || candidates[i+1].Name != c.Name {
+			p, err := b.archive.Write(ctx, records)
+			if err != nil {
+				err = fmt.Errorf("archive %s/%s: %w", c.Namespace, c.Name, err)
+				b.removeArchiveFiles(ctx, written)
+				return 0, err
+			}
+			written = append(written, p)
+			records = nil
+		}
+	}
+
+	var rowsAffected int64
+	err := b.db.WithTx(ctx, ReadCommitted, func(ctx context.Context, tx db.Tx) error {
+		res, err := dbutil.Exec(ctx, tx, sqlResourceHistoryArchiveDelete, &sqlHistoryArchiveDeleteRequest{
+			SQLTemplate: sqltemplate.New(b.dialect),
+			Group:       group,
+			Resource:    resourceName,
+			GUIDs:       guids,
+		})
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		if err == nil && rowsAffected < int64(len(guids)) {
+			err = fmt.Errorf("%w: %d of %d versions of %s/%s", errHistoryArchiveConflict, len(guids)-int(rowsAffected), len(guids), group, resourceName)
+		}
+		return err
+	})
+	if err != nil {
+		// the versions are still in the table, remove the files so they are not read twice
+		b.removeArchiveFiles(ctx, written)
+		return 0, err
+	}
+	return rowsAffected, nil
+}
+
+func (b *backend) removeArchiveFiles(ctx context.Context, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	if err := b.archive.Delete(context.WithoutCancel(ctx), paths...); err != nil {
+		b.log.Error("failed to remove history archive files", "files", paths, "error", err)
+	}
+}
+
+// clearArchivedResources removes the archived history of resources collected by the garbage collection.
+// It returns the resources whose archive could not be cleared: their rows must be kept, so they are collected again
+// by the next run instead of leaving archived versions behind.
+func (b *backend) clearArchivedResources(ctx context.Context, group, resourceName string, candidates []gcCandidateName) []gcCandidateName {
+	cleared := make([]gcCandidateName, 0, len(candidates))
+	for _, c := range candidates {
+		err := b.archive.DeleteAll(ctx, &resourcepb.ResourceKey{
+			Namespace: c.Namespace,
+			Group:     group,
+			Resource:  resourceName,
+			Name:      c.Name,
+		})
+		if err != nil {
+			b.log.Error("failed to clear the archived history of a deleted resource",
+				"namespace", c.Namespace,
+				"group", group,
+				"resource", resourceName,
+				"name", c.Name,
+				"error", err)
+			continue
+		}
+		cleared = append(cleared, c)
+	}
+	return cleared
+}
+
+// readArchivedHistory returns an iterator over the archived versions that may match a history request
+func (b *backend) readArchivedHistory(ctx context.Context, req *sqlGetHistoryRequest) (*parquet.HistoryIterator, error) {
+	minRV, maxRV := req.MinRV, int64(0)
+	switch {
+	case req.ExactRV > 0:
+		minRV, maxRV = req.ExactRV, req.ExactRV
+	case req.StartRV > 0 && req.SortAscending:
+		minRV = max(minRV, req.StartRV+1)
+	case req.StartRV > 0:
+		maxRV = req.StartRV - 1
+	}
+
+	it, err := b.archive.History(ctx, req.Key, minRV, maxRV, req.SortAscending)
+	if err != nil {
+		return nil, fmt.Errorf("read history archive: %w", err)
+	}
+	return it, nil
+}
+
+// readArchivedVersion reads the latest archived version of a resource at a resource version
+func (b *backend) readArchivedVersion(ctx context.Context, key *resourcepb.ResourceKey, rv int64) *resource.BackendReadResponse {
+	// newest first, only the file holding the version is read
+	it, err := b.archive.History(ctx, key, 0, rv, false)
+	if err != nil {
+		return &resource.BackendReadResponse{Error: resource.AsErrorResult(err)}
+	}
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return &resource.BackendReadResponse{Error: resource.AsErrorResult(err)}
+		}
+		return &resource.BackendReadResponse{Error: resource.NewNotFoundError(key)}
+	}
+
+	r := it.Record()
+	return &resource.BackendReadResponse{
+		Key: &resourcepb.ResourceKey{
+			Namespace: r.Key.Namespace,
+			Group:     r.Key.Group,
+			Resource:  r.Key.Resource,
+			Name:      r.Key.Name,
+		},
+		Folder:          r.Folder,
+		ResourceVersion: r.ResourceVersion,
+		Value:           r.Value,
+	}
+}
+
+// listArchivedRevision adds the archived resources to a list at a resource version. The resources with versions
+// in the table at the revision are listed from it, the archived versions of the others are read from the archive.
+func (b *backend) listArchivedRevision(ctx context.Context, tx db.Tx, key *resourcepb.ResourceKey, rows *listIter) (*revisionIter, error) {
+	names, err := dbutil.Query(ctx, tx, sqlResourceHistoryArchiveNames, &sqlHistoryArchiveNamesRequest{
+		SQLTemplate:     sqltemplate.New(b.dialect),
+		Key:             key,
+		ResourceVersion: rows.listRV,
+		Response:        new(gcCandidateName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := b.archive.AtRevision(ctx, key, rows.listRV)
+	if err != nil {
+		return nil, fmt.Errorf("read history archive: %w", err)
+	}
+
+	inTable := make(map[gcCandidateName]struct{}, len(names))
+	for _, n := range names {
+		inTable[n] = struct{}{}
+	}
+	return &revisionIter{
+		rows:     rows,
+		archived: archived,
+		inTable:  inTable,
+		skip:     rows.offset,
+		offset:   rows.offset,
+	}, nil
+}
+
+// clearArchivedHistory removes the archived history of the collections replaced by a bulk import
+func (b *backend) clearArchivedHistory(ctx context.Context, collection []*resourcepb.ResourceKey) {
+	for _, key := range collection {
+		if err := b.archive.DeleteAll(ctx, key); err != nil {
+			b.log.Error("failed to clear the archived history",
+				"namespace", key.Namespace,
+				"group", key.Group,
+				"resource", key.Resource,
+				"error", err)
+		}
+	}
+}
+
+var _ resource.ListIterator = (*historyIter)(nil)
+
+// historyIter merges the versions from the resource_history table with the archived versions,
+// in the resource version order of the request
+type historyIter struct {
+	rows     *listIter
+	archived *parquet.HistoryIterator
+	sortAsc  bool
+
+	started      bool
+	rowsNext     bool // the rows are positioned on a version that was not returned yet
+	archivedNext bool // the archive is positioned on a version that was not returned yet
+	fromRows     bool // the current version is from the rows
+}
+
+func newHistoryIter(rows *listIter, archived *parquet.HistoryIterator) *historyIter {
+	return &historyIter{
+		rows:     rows,
+		archived: archived,
+		sortAsc:  rows.sortAsc,
+	}
+}
+
+// Next implements resource.ListIterator.
+func (h *historyIter) Next() bool {
+	if !h.started || h.fromRows {
+		h.rowsNext = h.rows.Next()
+		if h.rows.err != nil {
+			h.started = true
+			h.fromRows = true
+			return true
+		}
+	}
+	if !h.started || !h.fromRows {
+		h.archivedNext = h.archived.Next()
+	}
+	h.started = true
+
+	if h.rowsNext && (!h.archivedNext || h.before(h.rows.rv, h.archived.Record().ResourceVersion)) {
+		h.fromRows = true
+		return true
+	}
+
+	h.fromRows = false
+	return h.archivedNext
+}
+
+func (h *historyIter) before(rv, other int64) bool {
+	if h.sortAsc {
+		return rv < other
+	}
+	return rv > other
+}
+
+// ContinueToken implements resource.ListIterator.
+func (h *historyIter) ContinueToken() string {
+	return ContinueToken{ResourceVersion: h.ResourceVersion(), SortAscending: h.sortAsc}.String()
+}
+
+// Error implements resource.ListIterator.
+func (h *historyIter) Error() error {
+	if err := h.rows.Error(); err != nil {
+		return err
+	}
+	return h.archived.Err()
+}
+
+// ResourceVersion implements resource.ListIterator.
+func (h *historyIter) ResourceVersion() int64 {
+	if h.fromRows {
+		return h.rows.ResourceVersion()
+	}
+	return h.archived.Record().ResourceVersion
+}
+
+// Namespace implements resource.ListIterator.
+func (h *historyIter) Namespace() string {
+	if h.fromRows {
+		return h.rows.Namespace()
+	}
+	return h.archived.Record().Key.Namespace
+}
+
+// Name implements resource.ListIterator.
+func (h *historyIter) Name() string {
+	if h.fromRows {
+		return h.rows.Name()
+	}
+	return h.archived.Record().Key.Name
+}
+
+// Folder implements resource.ListIterator.
+func (h *historyIter) Folder() string {
+	if h.fromRows {
+		return h.rows.Folder()
+	}
+	return h.archived.Record().Folder
+}
+
+// Value implements resource.ListIterator.
+func (h *historyIter) Value() []byte {
+	if h.fromRows {
+		return h.rows.Value()
+	}
+	return h.archived.Record().Value
+}
+
+var _ resource.ListIterator = (*revisionIter)(nil)
+
+// revisionIter lists the resources at a resource version from the resource_history table, then the archived
+// resources without versions in the table at the revision. The offset of the continue token counts both.
+type revisionIter struct {
+	rows     *listIter
+	archived *parquet.RevisionIterator
+	inTable  map[gcCandidateName]struct{}
+
+	skip     int64 // the resources returned by the previous pages
+	offset   int64
+	fromRows bool
+}
+
+// Next implements resource.ListIterator.
+func (r *revisionIter) Next() bool {
+	for {
+		if !r.next() {
+			return false
+		}
+		if r.skip > 0 {
+			r.skip--
+			continue
+		}
+		r.offset++
+		return true
+	}
+}
+
+func (r *revisionIter) next() bool {
+	if r.rows.rows.Next() {
+		r.fromRows = true
+		r.rows.err = r.rows.rows.Scan(&r.rows.guid, &r.rows.rv, &r.rows.namespace, &r.rows.group, &r.rows.resource, &r.rows.name, &r.rows.folder, &r.rows.value)
+		return r.rows.err == nil
+	}
+	if err := r.rows.rows.Err(); err != nil {
+		r.rows.err = err
+		return false
+	}
+
+	r.fromRows = false
+	for r.archived.Next() {
+		rec := r.archived.Record()
+		if _, ok := r.inTable[gcCandidateName{Namespace: rec.Key.Namespace, Name: rec.Key.Name}]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ContinueToken implements resource.ListIterator.
+func (r *revisionIter) ContinueToken() string {
+	return ContinueToken{ResourceVersion: r.rows.listRV, StartOffset: r.offset}.String()
+}
+
+// Error implements resource.ListIterator.
+func (r *revisionIter) Error() error {
+	if err := r.rows.Error(); err != nil {
+		return err
+	}
+	return r.archived.Err()
+}
+
+// ResourceVersion implements resource.ListIterator.
+func (r *revisionIter) ResourceVersion() int64 {
+	if r.fromRows {
+		return r.rows.ResourceVersion()
+	}
+	return r.archived.Record().ResourceVersion
+}
+
+// Namespace implements resource.ListIterator.
+func (r *revisionIter) Namespace() string {
+	if r.fromRows {
+		return r.rows.Namespace()
+	}
+	return r.archived.Record().Key.Namespace
+}
+
+// Name implements resource.ListIterator.
+func (r *revisionIter) Name() string {
+	if r.fromRows {
+		return r.rows.Name()
+	}
+	return r.archived.Record().Key.Name
+}
+
+// Folder implements resource.ListIterator.
+func (r *revisionIter) Folder() string {
+	if r.fromRows {
+		return r.rows.Folder()
+	}
+	return r.archived.Record().Folder
+}
+
+// Value implements resource.ListIterator.
+func (r *revisionIter) Value() []byte {
+	if r.fromRows {
+		return r.rows.Value()
+	}
+	return r.archived.Record().Value
+}
@@ -0,0 +1,340 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
+	"github.com/grafana/grafana/pkg/storage/unified/sql/db/dbimpl"
+	test "github.com/grafana/grafana/pkg/storage/unified/testing"
+	"github.com/grafana/grafana/pkg/util/testutil"
+)
+
+func TestIntegrationHistoryArchive(t *testing.T) {
+	testutil.SkipIntegrationTestInShortMode(t)
+	t.Cleanup(db.CleanupTestDB)
+
+	ctx := testutil.NewTestContext(t, time.Now().Add(time.Minute))
+
+	bucket := memblob.OpenBucket(nil)
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	dbstore := db.InitTestDB(t)
+	eDB, err := dbimpl.ProvideResourceDB(dbstore, setting.NewCfg(), nil)
+	require.NoError(t, err)
+	storageBackend, err := NewBackend(BackendOptions{
+		DBProvider: eDB,
+		HistoryArchive: HistoryArchiveConfig{
+			Bucket:    bucket,
+			Prefix:    "history",
+			BatchSize: 2,
+		},
+	})
+	require.NoError(t, err)
+	svc, ok := storageBackend.(services.Service)
+	require.True(t, ok)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, svc))
+	b := storageBackend.(*backend)
+
+	server, err := resource.NewResourceServer(resource.ResourceServerOptions{
+		Backend: storageBackend,
+	})
+	require.NoError(t, err)
+
+	rv1, err := test.WriteEvent(ctx, storageBackend, "resource1", resourcepb.WatchEvent_ADDED)
+	require.NoError(t, err)
+	rv2, err := test.WriteEvent(ctx, storageBackend, "resource1", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv1))
+	require.NoError(t, err)
+	rv3, err := test.WriteEvent(ctx, storageBackend, "resource1", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv2))
+	require.NoError(t, err)
+	rv4, err := test.WriteEvent(ctx, storageBackend, "resource2", resourcepb.WatchEvent_ADDED)
+	require.NoError(t, err)
+
+	// Everything is older than the cutoff, but the latest version of each resource is kept
+	cutoffTimestamp := time.Now().Add(time.Hour).UnixMicro()
+	results := b.runHistoryArchive(ctx, cutoffTimestamp)
+	require.Equal(t, int64(2), results["group/resource"])
+	require.Empty(t, b.runHistoryArchive(ctx, cutoffTimestamp))
+
+	history := func(t *testing.T, req *resourcepb.ListRequest) []int64 {
+		t.Helper()
+		req.Source = resourcepb.ListRequest_HISTORY
+		req.Options = &resourcepb.ListOptions{
+			Key: &resourcepb.ResourceKey{
+				Namespace: "namespace",
+				Group:     "group",
+				Resource:  "resource",
+				Name:      "resource1",
+			},
+		}
+
+		var rvs []int64
+		for {
+			rsp, err := server.List(ctx, req)
+			require.NoError(t, err)
+			require.Nil(t, rsp.Error)
+			for _, item := range rsp.Items {
+				rvs = append(rvs, item.ResourceVersion)
+			}
+			if rsp.NextPageToken == "" {
+				return rvs
+			}
+			req.NextPageToken = rsp.NextPageToken
+		}
+	}
+
+	t.Run("history is read from both tiers", func(t *testing.T) {
+		require.Equal(t, []int64{rv3, rv2, rv1}, history(t, &resourcepb.ListRequest{}))
+		require.Equal(t, []int64{rv3, rv2, rv1}, history(t, &resourcepb.ListRequest{Limit: 1}))
+		require.Equal(t, []int64{rv1, rv2, rv3}, history(t, &resourcepb.ListRequest{
+			Limit:           2,
+			ResourceVersion: 1,
+			VersionMatchV2:  resourcepb.ResourceVersionMatchV2_NotOlderThan,
+		}))
+		require.Equal(t, []int64{rv2}, history(t, &resourcepb.ListRequest{
+			ResourceVersion: rv2,
+			VersionMatchV2:  resourcepb.ResourceVersionMatchV2_Exact,
+		}))
+	})
+
+	t.Run("archived versions can be read", func(t *testing.T) {
+		rsp := storageBackend.ReadResource(ctx, &resourcepb.ReadRequest{
+			Key: &resourcepb.ResourceKey{
+				Namespace: "namespace",
+				Group:     "group",
+				Resource:  "resource",
+				Name:      "resource1",
+			},
+			ResourceVersion: rv2,
+		})
+		require.Nil(t, rsp.Error)
+		require.Equal(t, rv2, rsp.ResourceVersion)
+		require.Contains(t, string(rsp.Value), "resource1 MODIFIED")
+
+		rsp = storageBackend.ReadResource(ctx, &resourcepb.ReadRequest{
+			Key: &resourcepb.ResourceKey{
+				Namespace: "namespace",
+				Group:     "group",
+				Resource:  "resource",
+				Name:      "resource2",
+			},
+			ResourceVersion: rv4,
+		})
+		require.Nil(t, rsp.Error)
+		require.Equal(t, rv4, rsp.ResourceVersion)
+	})
+
+	t.Run("resources at an archived revision are listed", func(t *testing.T) {
+		list := func(t *testing.T, rv int64) []int64 {
+			t.Helper()
+			req := &resourcepb.ListRequest{
+				ResourceVersion: rv,
+				Limit:           1,
+				Options: &resourcepb.ListOptions{
+					Key: &resourcepb.ResourceKey{
+						Namespace: "namespace",
+						Group:     "group",
+						Resource:  "resource",
+					},
+				},
+			}
+			var rvs []int64
+			for {
+				rsp, err := server.List(ctx, req)
+				require.NoError(t, err)
+				require.Nil(t, rsp.Error)
+				for _, item := range rsp.Items {
+					rvs = append(rvs, item.ResourceVersion)
+				}
+				if rsp.NextPageToken == "" {
+					return rvs
+				}
+				req.ResourceVersion = 0
+				req.NextPageToken = rsp.NextPageToken
+			}
+		}
+
+		require.Equal(t, []int64{rv2}, list(t, rv2))
+		require.ElementsMatch(t, []int64{rv3, rv4}, list(t, rv4))
+	})
+
+	t.Run("history starts after the archived deletion", func(t *testing.T) {
+		rv5, err := test.WriteEvent(ctx, storageBackend, "resource2", resourcepb.WatchEvent_DELETED, test.WithNamespaceAndRV("namespace", rv4))
+		require.NoError(t, err)
+		rv6, err := test.WriteEvent(ctx, storageBackend, "resource2", resourcepb.WatchEvent_ADDED)
+		require.NoError(t, err)
+
+		results := b.runHistoryArchive(ctx, time.Now().Add(time.Hour).UnixMicro())
+		require.Equal(t, int64(2), results["group/resource"])
+
+		rsp, err := server.List(ctx, &resourcepb.ListRequest{
+			Source: resourcepb.ListRequest_HISTORY,
+			Options: &resourcepb.ListOptions{
+				Key: &resourcepb.ResourceKey{
+					Namespace: "namespace",
+					Group:     "group",
+					Resource:  "resource",
+					Name:      "resource2",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Items, 1)
+		require.Equal(t, rv6, rsp.Items[0].ResourceVersion)
+		require.Less(t, rv5, rv6)
+	})
+
+	t.Run("garbage collection removes the archived history", func(t *testing.T) {
+		key := &resourcepb.ResourceKey{
+			Namespace: "namespace",
+			Group:     "group",
+			Resource:  "resource",
+			Name:      "resource3",
+		}
+		rv1, err := test.WriteEvent(ctx, storageBackend, "resource3", resourcepb.WatchEvent_ADDED)
+		require.NoError(t, err)
+		rv2, err := test.WriteEvent(ctx, storageBackend, "resource3", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv1))
+		require.NoError(t, err)
+		_, err = test.WriteEvent(ctx, storageBackend, "resource3", resourcepb.WatchEvent_DELETED, test.WithNamespaceAndRV("namespace", rv2))
+		require.NoError(t, err)
+
+		cutoffTimestamp := time.Now().Add(time.Hour).UnixMicro()
+		results := b.runHistoryArchive(ctx, cutoffTimestamp)
+		require.Equal(t, int64(2), results["group/resource"])
+
+		deleted, err := b.garbageCollectBatch(ctx, "group", "resource", cutoffTimestamp, 100)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		it, err := b.archive.History(ctx, key, 0, 0, true)
+		require.NoError(t, err)
+		require.False(t, it.Next())
+		require.NoError(t, it.Err())
+
+		// a resource created again with the same name does not get the old history
+		rv4, err := test.WriteEvent(ctx, storageBackend, "resource3", resourcepb.WatchEvent_ADDED)
+		require.NoError(t, err)
+		rsp, err := server.List(ctx, &resourcepb.ListRequest{
+			Source:  resourcepb.ListRequest_HISTORY,
+			Options: &resourcepb.ListOptions{Key: key},
+		})
+		require.NoError(t, err)
+		require.Nil(t, rsp.Error)
+		require.Len(t, rsp.Items, 1)
+		require.Equal(t, rv4, rsp.Items[0].ResourceVersion)
+	})
+
+	t.Run("versions removed while they are archived are not archived twice", func(t *testing.T) {
+		key := &resourcepb.ResourceKey{
+			Namespace: "namespace",
+			Group:     "group",
+			Resource:  "resource",
+			Name:      "resource4",
+		}
+		rv1, err := test.WriteEvent(ctx, storageBackend, "resource4", resourcepb.WatchEvent_ADDED)
+		require.NoError(t, err)
+		rv2, err := test.WriteEvent(ctx, storageBackend, "resource4", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv1))
+		require.NoError(t, err)
+		_, err = test.WriteEvent(ctx, storageBackend, "resource4", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv2))
+		require.NoError(t, err)
+
+		cutoffTimestamp := time.Now().Add(time.Hour).UnixMicro()
+		var candidates []archiveCandidate
+		all, err := b.queryArchiveCandidates(ctx, "group", "resource", cutoffTimestamp, 100)
+		require.NoError(t, err)
+		for _, c := range all {
+			if c.Name == key.Name {
+				candidates = append(candidates, c)
+			}
+		}
+		require.Len(t, candidates, 2)
+
+		// another run archives the first version in the meantime
+		archived, err := b.archiveCandidates(ctx, "group", "resource", candidates[:1])
+		require.NoError(t, err)
+		require.Equal(t, int64(1), archived)
+
+		_, err = b.archiveCandidates(ctx, "group", "resource", candidates)
+		require.ErrorIs(t, err, errHistoryArchiveConflict)
+
+		// the files of the conflicting batch are removed, and its versions are kept in the table
+		archivedRVs := func() []int64 {
+			it, err := b.archive.History(ctx, key, 0, 0, true)
+			require.NoError(t, err)
+			var rvs []int64
+			for it.Next() {
+				rvs = append(rvs, it.Record().ResourceVersion)
+			}
+			require.NoError(t, it.Err())
+			return rvs
+		}
+		require.Equal(t, []int64{rv1}, archivedRVs())
+
+		b.runHistoryArchive(ctx, cutoffTimestamp)
+		require.Equal(t, []int64{rv1, rv2}, archivedRVs())
+	})
+
+	t.Run("the archive is not run while another instance holds the lock", func(t *testing.T) {
+		rv1, err := test.WriteEvent(ctx, storageBackend, "resource5", resourcepb.WatchEvent_ADDED)
+		require.NoError(t, err)
+		_, err = test.WriteEvent(ctx, storageBackend, "resource5", resourcepb.WatchEvent_MODIFIED, test.WithNamespaceAndRV("namespace", rv1))
+		require.NoError(t, err)
+
+		lock := &fakeServerLock{locked: true}
+		b.historyArchive.Lock = lock
+		b.historyArchive.Interval = time.Minute
+		b.historyArchive.MaxAge = -time.Hour
+		t.Cleanup(func() { b.historyArchive.Lock = nil })
+
+		candidates := func() int {
+			all, err := b.queryArchiveCandidates(ctx, "group", "resource", time.Now().Add(time.Hour).UnixMicro(), 100)
+			require.NoError(t, err)
+			count := 0
+			for _, c := range all {
+				if c.Name == "resource5" {
+					count++
+				}
+			}
+			return count
+		}
+
+		b.runLockedHistoryArchive(ctx)
+		require.Equal(t, 1, lock.calls)
+		require.Equal(t, historyArchiveLockName, lock.actionName)
+		require.Equal(t, time.Minute, lock.maxInterval)
+		require.Equal(t, 1, candidates())
+
+		lock.locked = false
+		b.runLockedHistoryArchive(ctx)
+		require.Equal(t, 2, lock.calls)
+		require.Equal(t, 0, candidates())
+	})
+}
+
+type fakeServerLock struct {
+	locked      bool
+	calls       int
+	actionName  string
+	maxInterval time.Duration
+}
+
+func (l *fakeServerLock) LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error {
+	l.calls++
+	l.actionName = actionName
+	l.maxInterval = maxInterval
+	if l.locked {
+		return &serverlock.ServerLockExistsError{}
+	}
+	fn(ctx)
+	return nil
+}
@@ -214,6 +214,9 @@ func (b *backend) processBulk(ctx context.Context, setting resource.BulkSettings
 	})
 	if err != nil {
 		rsp.Error = resource.AsErrorResult(err)
+	} else if b.archive != nil && rsp.Error == nil {
+		// the history of the collection was replaced
+		b.clearArchivedHistory(ctx, setting.Collection)
 	}
 	return rsp
 }
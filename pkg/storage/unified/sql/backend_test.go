@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -13,7 +14,9 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/grafana/grafana/pkg/apimachinery/utils"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/storage/unified/resource"
+	"github.com/grafana/grafana/pkg/storage/unified/resource/kv"
 	"github.com/grafana/grafana/pkg/storage/unified/resourcepb"
 	"github.com/grafana/grafana/pkg/storage/unified/sql/db/dbimpl"
 	"github.com/grafana/grafana/pkg/storage/unified/sql/test"
@@ -134,6 +137,24 @@ func TestNewBackend(t *testing.T) {
 	})
 }
 
+func TestNewFileBackend(t *testing.T) {
+	t.Parallel()
+
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+
+	b, err := NewFileBackend(cfg)
+	require.NoError(t, err)
+	closer, ok := b.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+
+	// Closing released the database, it can be opened again
+	store, err := kv.OpenBadger(FileStorageOptions(cfg))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
 func TestBackend_Init(t *testing.T) {
 	t.Parallel()
 
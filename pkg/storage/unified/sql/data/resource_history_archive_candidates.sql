@@ -0,0 +1,23 @@
+{{/* Find the history older than the cutoff, always keeping the latest version of every resource. */}}
+SELECT
+    h.{{ .Ident "guid" | .Into .Response.GUID }},
+    h.{{ .Ident "resource_version" | .Into .Response.ResourceVersion }},
+    h.{{ .Ident "namespace" | .Into .Response.Namespace }},
+    h.{{ .Ident "name" | .Into .Response.Name }},
+    h.{{ .Ident "folder" | .Into .Response.Folder }},
+    h.{{ .Ident "action" | .Into .Response.Action }},
+    h.{{ .Ident "value" | .Into .Response.Value }}
+FROM {{ .Ident "resource_history" }} h
+WHERE h.{{ .Ident "group" }} = {{ .Arg .Group }}
+  AND h.{{ .Ident "resource" }} = {{ .Arg .Resource }}
+  AND h.{{ .Ident "resource_version" }} < {{ .Arg .CutoffTimestamp }}
+  AND EXISTS (
+    SELECT 1 FROM {{ .Ident "resource_history" }} n
+    WHERE n.{{ .Ident "namespace" }} = h.{{ .Ident "namespace" }}
+      AND n.{{ .Ident "group" }} = h.{{ .Ident "group" }}
+      AND n.{{ .Ident "resource" }} = h.{{ .Ident "resource" }}
+      AND n.{{ .Ident "name" }} = h.{{ .Ident "name" }}
+      AND n.{{ .Ident "resource_version" }} > h.{{ .Ident "resource_version" }}
+  )
+ORDER BY h.{{ .Ident "namespace" }}, h.{{ .Ident "name" }}, h.{{ .Ident "resource_version" }}
+LIMIT {{ .Arg .BatchSize }};
@@ -0,0 +1,9 @@
+DELETE FROM {{ .Ident "resource_history" }}
+WHERE {{ .Ident "group" }} = {{ .Arg .Group }}
+  AND {{ .Ident "resource" }} = {{ .Arg .Resource }}
+  AND {{ .Ident "guid" }} IN (
+    {{- range $i, $guid := .GUIDs -}}
+    {{- if $i }}, {{ end -}}
+    {{ $.Arg $guid }}
+    {{- end -}}
+  );
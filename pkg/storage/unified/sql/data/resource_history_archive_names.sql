@@ -0,0 +1,13 @@
+{{/* Find the resources with versions in the table at a resource version, the older versions of the others may be archived. */}}
+SELECT DISTINCT {{ .Ident "namespace" | .Into .Response.Namespace }},
+                {{ .Ident "name" | .Into .Response.Name }}
+FROM {{ .Ident "resource_history" }}
+WHERE {{ .Ident "group" }} = {{ .Arg .Key.Group }}
+  AND {{ .Ident "resource" }} = {{ .Arg .Key.Resource }}
+  {{ if .Key.Namespace }}
+  AND {{ .Ident "namespace" }} = {{ .Arg .Key.Namespace }}
+  {{ end }}
+  {{ if .Key.Name }}
+  AND {{ .Ident "name" }} = {{ .Arg .Key.Name }}
+  {{ end }}
+  AND {{ .Ident "resource_version" }} <= {{ .Arg .ResourceVersion }};
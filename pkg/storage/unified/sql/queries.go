@@ -47,6 +47,9 @@ var (
 	sqlResourceHistoryPrune                = mustTemplate("resource_history_prune.sql")
 	sqlResourceHistoryGarbageGetCandidates = mustTemplate("resource_history_gc_get_candidates.sql")
 	sqlResourceHistoryGCDeleteByNames      = mustTemplate("resource_history_gc_delete_by_names.sql")
+	sqlResourceHistoryArchiveCandidates    = mustTemplate("resource_history_archive_candidates.sql")
+	sqlResourceHistoryArchiveDelete        = mustTemplate("resource_history_archive_delete.sql")
+	sqlResourceHistoryArchiveNames         = mustTemplate("resource_history_archive_names.sql")
 	sqlResourceTrash                       = mustTemplate("resource_trash.sql")
 	sqlResourceInsertFromHistory           = mustTemplate("resource_insert_from_history.sql")
 
@@ -391,6 +394,91 @@ func (r *sqlGarbageCollectDeleteByNamesRequest) Validate() error {
 	return nil
 }
 
+type archiveCandidate struct {
+	GUID            string
+	ResourceVersion int64
+	Namespace       string
+	Name            string
+	Folder          string
+	Action          int
+	Value           []byte
+}
+
+type sqlHistoryArchiveCandidatesRequest struct {
+	sqltemplate.SQLTemplate
+	Group           string
+	Resource        string
+	CutoffTimestamp int64
+	BatchSize       int
+	Response        *archiveCandidate
+}
+
+func (r *sqlHistoryArchiveCandidatesRequest) Validate() error {
+	if r.Group == "" {
+		return fmt.Errorf("missing group")
+	}
+	if r.Resource == "" {
+		return fmt.Errorf("missing resource")
+	}
+	if r.CutoffTimestamp <= 0 {
+		return fmt.Errorf("invalid cutoff timestamp")
+	}
+	if r.BatchSize <= 0 {
+		return fmt.Errorf("invalid batch size")
+	}
+	return nil
+}
+
+func (r *sqlHistoryArchiveCandidatesRequest) Results() (archiveCandidate, error) {
+	x := *r.Response
+	return x, nil
+}
+
+type sqlHistoryArchiveDeleteRequest struct {
+	sqltemplate.SQLTemplate
+	Group    string
+	Resource string
+	GUIDs    []string
+}
+
+func (r *sqlHistoryArchiveDeleteRequest) Validate() error {
+	if r.Group == "" {
+		return fmt.Errorf("missing group")
+	}
+	if r.Resource == "" {
+		return fmt.Errorf("missing resource")
+	}
+	if len(r.GUIDs) == 0 {
+		return fmt.Errorf("missing guids")
+	}
+	return nil
+}
+
+type sqlHistoryArchiveNamesRequest struct {
+	sqltemplate.SQLTemplate
+	Key             *resourcepb.ResourceKey
+	ResourceVersion int64
+	Response        *gcCandidateName
+}
+
+func (r *sqlHistoryArchiveNamesRequest) Validate() error {
+	if r.Key == nil || r.Key.Group == "" {
+		return fmt.Errorf("missing group")
+	}
+	if r.Key.Resource == "" {
+		return fmt.Errorf("missing resource")
+	}
+	if r.ResourceVersion <= 0 {
+		return fmt.Errorf("invalid resource version")
+	}
+	return nil
+}
+
+func (r *sqlHistoryArchiveNamesRequest) Results() (gcCandidateName, error) {
+	x := *r.Response
+	return x, nil
+}
+
 type sqlResourceBlobInsertRequest struct {
 	sqltemplate.SQLTemplate
 	Now         time.Time
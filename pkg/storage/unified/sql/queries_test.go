@@ -176,6 +176,45 @@ func TestUnifiedStorageQueries(t *testing.T) {
 					},
 				},
 			},
+			sqlResourceHistoryArchiveCandidates: {
+				{
+					Name: "single path",
+					Data: &sqlHistoryArchiveCandidatesRequest{
+						SQLTemplate:     mocks.NewTestingSQLTemplate(),
+						Group:           "group",
+						Resource:        "res",
+						CutoffTimestamp: 123456,
+						BatchSize:       100,
+						Response:        new(archiveCandidate),
+					},
+				},
+			},
+			sqlResourceHistoryArchiveDelete: {
+				{
+					Name: "single path",
+					Data: &sqlHistoryArchiveDeleteRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Group:       "group",
+						Resource:    "res",
+						GUIDs:       []string{"guid1", "guid2"},
+					},
+				},
+			},
+			sqlResourceHistoryArchiveNames: {
+				{
+					Name: "single path",
+					Data: &sqlHistoryArchiveNamesRequest{
+						SQLTemplate: mocks.NewTestingSQLTemplate(),
+						Key: &resourcepb.ResourceKey{
+							Namespace: "ns",
+							Group:     "group",
+							Resource:  "res",
+						},
+						ResourceVersion: 123456,
+						Response:        new(gcCandidateName),
+					},
+				},
+			},
 			sqlResourceHistoryPoll: {
 				{
 					Name: "single path",
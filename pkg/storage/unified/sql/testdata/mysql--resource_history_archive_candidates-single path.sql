@@ -0,0 +1,22 @@
+SELECT
+    h.`guid`,
+    h.`resource_version`,
+    h.`namespace`,
+    h.`name`,
+    h.`folder`,
+    h.`action`,
+    h.`value`
+FROM `resource_history` h
+WHERE h.`group` = 'group'
+  AND h.`resource` = 'res'
+  AND h.`resource_version` < 123456
+  AND EXISTS (
+    SELECT 1 FROM `resource_history` n
+    WHERE n.`namespace` = h.`namespace`
+      AND n.`group` = h.`group`
+      AND n.`resource` = h.`resource`
+      AND n.`name` = h.`name`
+      AND n.`resource_version` > h.`resource_version`
+  )
+ORDER BY h.`namespace`, h.`name`, h.`resource_version`
+LIMIT 100;
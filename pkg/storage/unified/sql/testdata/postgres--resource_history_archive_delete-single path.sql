@@ -0,0 +1,4 @@
+DELETE FROM "resource_history"
+WHERE "group" = 'group'
+  AND "resource" = 'res'
+  AND "guid" IN ('guid1', 'guid2');
@@ -0,0 +1,7 @@
+SELECT DISTINCT "namespace",
+                "name"
+FROM "resource_history"
+WHERE "group" = 'group'
+  AND "resource" = 'res'
+  AND "namespace" = 'ns'
+  AND "resource_version" <= 123456;
@@ -32,6 +32,7 @@ import { appEvents } from 'app/core/app_events';
 import { getConfig } from 'app/core/config';
 import { getSessionExpiry, hasSessionExpiry } from 'app/core/utils/auth';
 import { loadUrlToken } from 'app/core/utils/urlToken';
+import { isPublicDashboardsApiUrl, loadViewerToken } from 'app/core/utils/viewerToken';
 import { getDashboardAPI } from 'app/features/dashboard/api/dashboard_api';
 import { DashboardSearchItem } from 'app/features/search/types';
 import { TokenRevokedModal } from 'app/features/users/TokenRevokedModal';
@@ -265,6 +266,13 @@ export class BackendSrv implements BackendService {
       options.headers['X-Grafana-Device-Id'] = `${this.deviceID}`;
     }
 
+    // Signed public dashboards only accept the viewer token in the Authorization header
+    const viewerToken = loadViewerToken();
+    if (viewerToken !== null && isPublicDashboardsApiUrl(options.url)) {
+      options.headers = options.headers ?? {};
+      options.headers.Authorization = `Bearer ${viewerToken}`;
+    }
+
     return parseUrlFromOptions(options).pipe(
       this.getFromFetchStream<T>(options),
       this.handleStreamResponse<T>(options),
@@ -1131,4 +1131,33 @@ describe('backendSrv', () => {
       });
     });
   });
+
+  describe('when a signed public dashboard is embedded with a viewer token', () => {
+    beforeEach(() => {
+      window.location.hash = '#viewerToken=signed.viewer.token';
+    });
+
+    afterEach(() => {
+      window.location.hash = '';
+    });
+
+    it('then the viewer token should be sent to the public dashboard API in the Authorization header', async () => {
+      const { backendSrv, fromFetchMock } = getTestContext();
+
+      await backendSrv.get('/api/public/dashboards/abc/annotations', { from: 1 });
+
+      const [url, init] = fromFetchMock.mock.calls[0];
+      expect(url).not.toContain('signed.viewer.token');
+      expect(init.headers.get('Authorization')).toBe('Bearer signed.viewer.token');
+    });
+
+    it('then the viewer token should not be sent to other APIs', async () => {
+      const { backendSrv, fromFetchMock } = getTestContext();
+
+      await backendSrv.get('/api/dashboards/uid/abc');
+
+      const [, init] = fromFetchMock.mock.calls[0];
+      expect(init.headers.get('Authorization')).toBeNull();
+    });
+  });
 });
@@ -0,0 +1,36 @@
+import { isPublicDashboardsApiUrl, loadViewerToken } from './viewerToken';
+
+describe('loadViewerToken', () => {
+  afterAll(() => {
+    window.location.hash = '';
+  });
+
+  // The token is kept once read, so the cases run in order
+  it('should return null when the dashboard is not embedded with a viewer token', () => {
+    window.location.hash = '#orgId=1';
+    expect(loadViewerToken()).toBeNull();
+  });
+
+  it('should read the viewer token from the URL fragment', () => {
+    window.location.hash = '#viewerToken=signed.viewer.token';
+    expect(loadViewerToken()).toBe('signed.viewer.token');
+  });
+
+  it('should keep the viewer token when the fragment changes', () => {
+    window.location.hash = '';
+    expect(loadViewerToken()).toBe('signed.viewer.token');
+  });
+});
+
+describe('isPublicDashboardsApiUrl', () => {
+  it.each`
+    url                                                | expected
+    ${'/api/public/dashboards/abc'}                    | ${true}
+    ${'api/public/dashboards/abc/panels/1/query'}      | ${true}
+    ${'api/public/dashboards/abc/annotations?from=1'}  | ${true}
+    ${'api/dashboards/uid/abc'}                        | ${false}
+    ${'https://example.com/api/public/dashboards/abc'} | ${false}
+  `("should return '$expected' for '$url'", ({ url, expected }) => {
+    expect(isPublicDashboardsApiUrl(url)).toBe(expected);
+  });
+});
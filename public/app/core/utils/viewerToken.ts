@@ -0,0 +1,28 @@
+const VIEWER_TOKEN_PARAM = 'viewerToken';
+const PUBLIC_DASHBOARDS_API_PREFIX = 'api/public/dashboards/';
+
+let cachedToken: string | null = null;
+
+/**
+ * Returns the viewer token of a signed public dashboard. Applications embedding the dashboard pass it in the URL
+ * fragment of the iframe, e.g. `/public-dashboards/<access token>#viewerToken=<viewer token>`. Browsers never send the
+ * fragment to the server, so the token stays out of access logs and Referer headers. It is kept once read, as the
+ * fragment may change while the dashboard is viewed.
+ */
+export const loadViewerToken = (): string | null => {
+  const params = new URLSearchParams(window.location.hash.substring(1));
+
+  const token = params.get(VIEWER_TOKEN_PARAM);
+  if (token !== null && token !== '') {
+    cachedToken = token;
+  }
+
+  return cachedToken;
+};
+
+/**
+ * Returns whether the viewer token is sent with a request, only the public dashboard API reads it.
+ */
+export const isPublicDashboardsApiUrl = (url: string): boolean => {
+  return url.replace(/^\//, '').startsWith(PUBLIC_DASHBOARDS_API_PREFIX);
+};